@@ -0,0 +1,43 @@
+package main
+
+import (
+	"backend/config"
+	infrastructure_todo "backend/internal/infrastructure/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	"flag"
+	"os"
+)
+
+// Todoのイベントを再生し、読み取りモデル(todosテーブル)を再構築するコマンド
+// 読み取りモデルの状態はスナップショットとイベントのみから作り直し、イベントに記録されていない変更は破棄する。
+func main() {
+	noSnapshots := flag.Bool("no-snapshots", false, "スナップショットを使わずに最初のイベントから再生し、スナップショットを作り直す")
+	flag.Parse()
+
+	// 環境変数の読み込み
+	appConfig := config.NewAppConfig()
+	appConfig.SetUpEnv()
+
+	// ログ設定
+	logger := pkg_logger.NewAppLogger()
+	logger.SetUpLogger()
+
+	// Supabaseの接続
+	supabaseClient := pkg_supabase.NewSupabaseClient()
+	err := supabaseClient.InitSupabase(logger)
+	if err != nil {
+		logger.ErrorLog.Fatalf("Failed to initialize Supabase: %v", err)
+	}
+
+	// 読み取りモデルを再構築
+	eventStore := infrastructure_todo.NewTodoEventStore(logger, supabaseClient, appConfig.TodoSnapshotInterval)
+	count, err := eventStore.RebuildReadModel(!*noSnapshots)
+	supabaseClient.ClosePool(logger)
+	if err != nil {
+		logger.ErrorLog.Printf("Failed to rebuild read model after %d todos: %v", count, err)
+		os.Exit(1)
+	}
+
+	logger.InfoLog.Printf("Rebuilt read model of %d todos", count)
+}
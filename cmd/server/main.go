@@ -2,27 +2,59 @@ package main
 
 import (
 	"backend/config"
+	infrastructure_attachment "backend/internal/infrastructure/attachment"
+	infrastructure_audit "backend/internal/infrastructure/audit"
 	infrastructure_auth "backend/internal/infrastructure/auth"
+	infrastructure_calendar "backend/internal/infrastructure/calendar"
+	infrastructure_comment "backend/internal/infrastructure/comment"
+	infrastructure_notification "backend/internal/infrastructure/notification"
+	infrastructure_recurrence "backend/internal/infrastructure/recurrence"
+	infrastructure_search "backend/internal/infrastructure/search"
+	infrastructure_share "backend/internal/infrastructure/share"
+	infrastructure_storage "backend/internal/infrastructure/storage"
+	infrastructure_tag "backend/internal/infrastructure/tag"
 	infrastructure_todo "backend/internal/infrastructure/todo"
+	infrastructure_todolist "backend/internal/infrastructure/todolist"
 	infrastructure_user "backend/internal/infrastructure/user"
+	infrastructure_webhook "backend/internal/infrastructure/webhook"
+	interfaces_attachment "backend/internal/interfaces/attachment"
 	interfaces_auth "backend/internal/interfaces/auth"
+	interfaces_calendar "backend/internal/interfaces/calendar"
 	interfaces_graphql "backend/internal/interfaces/graphql"
+	interfaces_transfer "backend/internal/interfaces/transfer"
+	"backend/internal/job"
+	"backend/internal/middleware"
 	pkg_logger "backend/internal/pkg/logger"
+	pkg_secretbox "backend/internal/pkg/secretbox"
 	pkg_supabase "backend/internal/pkg/supabase"
 	"backend/internal/router"
+	usecase_attachment "backend/internal/usecase/attachment"
+	usecase_audit "backend/internal/usecase/audit"
 	usecase_auth "backend/internal/usecase/auth"
+	usecase_calendar "backend/internal/usecase/calendar"
+	usecase_comment "backend/internal/usecase/comment"
+	usecase_recurrence "backend/internal/usecase/recurrence"
+	usecase_reminder "backend/internal/usecase/reminder"
+	usecase_search "backend/internal/usecase/search"
+	usecase_share "backend/internal/usecase/share"
+	usecase_tag "backend/internal/usecase/tag"
 	usecase_todo "backend/internal/usecase/todo"
+	usecase_todolist "backend/internal/usecase/todolist"
+	usecase_transfer "backend/internal/usecase/transfer"
 	usecase_user "backend/internal/usecase/user"
+	usecase_webhook "backend/internal/usecase/webhook"
+	"context"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
 // main関数のセットアップ
-func setUp(e *echo.Echo, ac *config.AppConfig, l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) {
+func setUp(ctx context.Context, e *echo.Echo, ac *config.AppConfig, l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) {
 	// Supabaseの接続
 	err := sc.InitSupabase(l)
 	if err != nil {
@@ -38,18 +70,114 @@ func setUp(e *echo.Echo, ac *config.AppConfig, l *pkg_logger.AppLogger, sc *pkg_
 	// repository
 	userRepository := infrastructure_user.NewUserRepository(l, sc)
 	todoRepository := infrastructure_todo.NewTodoRepository(l, sc)
+	todoWriter := infrastructure_todo.NewTodoTxWriter(l, sc)
+	// イベントソーシングが有効な場合は、Todoの書き込み(他のリポジトリからの書き込みを含む)をイベントとして記録する
+	if ac.TodoEventSourcing {
+		todoRepository = infrastructure_todo.NewEventSourcedTodoRepository(l, sc, ac.TodoSnapshotInterval)
+		todoWriter = infrastructure_todo.NewEventSourcedTodoTxWriter(l, sc, ac.TodoSnapshotInterval)
+	}
 	authRepository := infrastructure_auth.NewAuthRepository(l, sc)
+	loginAttemptRepository := infrastructure_auth.NewLoginAttemptRepository(l, sc)
+	attachmentRepository := infrastructure_attachment.NewAttachmentRepository(l, sc)
+	auditLogRepository := infrastructure_audit.NewAuditLogRepository(l, sc)
+	tagRepository := infrastructure_tag.NewTagRepository(l, sc)
+	todoListRepository := infrastructure_todolist.NewTodoListRepository(l, sc, todoWriter)
+	recurrenceRepository := infrastructure_recurrence.NewRecurrenceRepository(l, sc, todoWriter)
+	shareRepository := infrastructure_share.NewTodoShareRepository(l, sc)
+	commentRepository := infrastructure_comment.NewCommentRepository(l, sc)
+	searchRepository := infrastructure_search.NewTodoSearchRepository(l, sc, todoWriter)
+	calendarFeedRepository := infrastructure_calendar.NewCalendarFeedRepository(l, sc)
+	// Webhookのシークレットは鍵が設定されている場合のみ暗号化して保存する
+	var webhookSecretBox *pkg_secretbox.SecretBox
+	if ac.WebhookSecretKey != "" {
+		webhookSecretBox, err = pkg_secretbox.NewSecretBox(ac.WebhookSecretKey)
+		if err != nil {
+			l.ErrorLog.Fatalf("Failed to initialize webhook secret key: %v", err)
+		}
+	} else {
+		l.WarnLog.Println("WEBHOOK_SECRET_KEY is not set, webhook secrets are stored in plaintext")
+	}
+	webhookRepository := infrastructure_webhook.NewWebhookRepository(l, sc, webhookSecretBox)
+	// storage
+	blobStorage := infrastructure_storage.NewLocalBlobStorage(l, ac.AttachmentStorageDir)
+	// notification
+	reminderNotifier := infrastructure_notification.NewLogReminderNotifier(l)
+	// webhook
+	webhookSender := infrastructure_webhook.NewHTTPWebhookSender(l, time.Duration(ac.WebhookTimeoutSeconds)*time.Second, ac.WebhookAllowPrivateNetworks)
 	// usecase
 	userUsecase := usecase_user.NewUserUsecase(l, userRepository)
-	todoUsecase := usecase_todo.NewTodoUsecase(l, todoRepository)
-	authUsecase := usecase_auth.NewAuthUsecase(l, authRepository)
+	todoUsecase := usecase_todo.NewTodoUsecase(l, todoRepository, blobStorage, shareRepository)
+	authUsecase := usecase_auth.NewAuthUsecase(l, authRepository, loginAttemptRepository, usecase_auth.LoginPolicy{
+		FailureWindow:      time.Duration(ac.LoginFailureWindowMinutes) * time.Minute,
+		MaxAccountFailures: ac.LoginMaxAccountFailures,
+		MaxIPFailures:      ac.LoginMaxIPFailures,
+		LockoutDuration:    time.Duration(ac.LoginLockoutMinutes) * time.Minute,
+		BackoffThreshold:   ac.LoginBackoffThreshold,
+		BackoffBase:        time.Duration(ac.LoginBackoffBaseSeconds) * time.Second,
+		BackoffMax:         time.Duration(ac.LoginBackoffMaxSeconds) * time.Second,
+	})
+	attachmentUsecase := usecase_attachment.NewAttachmentUsecase(l, attachmentRepository, todoUsecase, blobStorage, ac.AttachmentMaxSize, ac.AttachmentAllowedTypes)
+	auditLogUsecase := usecase_audit.NewAuditLogUsecase(l, auditLogRepository)
+	tagUsecase := usecase_tag.NewTagUsecase(l, tagRepository, todoRepository)
+	todoListUsecase := usecase_todolist.NewTodoListUsecase(l, todoListRepository, todoRepository)
+	recurrenceUsecase := usecase_recurrence.NewRecurrenceUsecase(l, recurrenceRepository, todoRepository, ac.RecurrenceDefaultTimezone)
+	shareUsecase := usecase_share.NewTodoShareUsecase(l, shareRepository, todoRepository, userRepository)
+	commentUsecase := usecase_comment.NewCommentUsecase(l, commentRepository)
+	reminderUsecase := usecase_reminder.NewReminderUsecase(l, todoRepository, reminderNotifier)
+	searchUsecase := usecase_search.NewTodoSearchUsecase(l, searchRepository)
+	transferUsecase := usecase_transfer.NewTodoTransferUsecase(l, todoRepository, todoListRepository)
+	calendarFeedUsecase := usecase_calendar.NewCalendarFeedUsecase(l, calendarFeedRepository, todoRepository, ac.CalendarFeedBaseURL, ac.CalendarFeedPastDays, time.Duration(ac.CalendarFeedRefreshMinutes)*time.Minute)
+	webhookUsecase := usecase_webhook.NewWebhookUsecase(l, webhookRepository, webhookSender, usecase_webhook.DeliveryPolicy{
+		MaxAttempts: ac.WebhookMaxAttempts,
+		BackoffBase: time.Duration(ac.WebhookBackoffBaseSeconds) * time.Second,
+		BackoffMax:  time.Duration(ac.WebhookBackoffMaxSeconds) * time.Second,
+		Timeout:     time.Duration(ac.WebhookTimeoutSeconds) * time.Second,
+	})
+
+	// 全文検索の言語を設定(変更した場合は索引を作り直す)
+	err = searchUsecase.ConfigureLanguage(ac.TodoSearchLanguage)
+	if err != nil {
+		l.ErrorLog.Fatalf("Failed to configure search language: %v", err)
+	}
+
+	// 平文のまま保存されているWebhookのシークレットを暗号化
+	_, err = webhookUsecase.EncryptStoredSecrets()
+	if err != nil {
+		l.ErrorLog.Fatalf("Failed to encrypt webhook secrets: %v", err)
+	}
+
 	// handler
 	authHandler := interfaces_auth.NewAuthHandler(ac, l)
+	transferHandler := interfaces_transfer.NewTodoTransferHandler(l, authHandler, transferUsecase)
+	attachmentHandler := interfaces_attachment.NewAttachmentHandler(l, authHandler, attachmentUsecase)
+	calendarFeedHandler := interfaces_calendar.NewCalendarFeedHandler(l, calendarFeedUsecase, time.Duration(ac.CalendarFeedRefreshMinutes)*time.Minute)
 	// graphql
-	graphqlHandler := interfaces_graphql.NewGraphQLHandler(l, userUsecase, todoUsecase, authUsecase, authHandler)
+	graphqlHandler := interfaces_graphql.NewGraphQLHandler(l, userUsecase, todoUsecase, authUsecase, authHandler, attachmentUsecase, auditLogUsecase, tagUsecase, todoListUsecase, recurrenceUsecase, shareUsecase, commentUsecase, searchUsecase, transferUsecase, calendarFeedUsecase, webhookUsecase)
+	queryLimiter := interfaces_graphql.NewQueryLimiter(l, ac.GraphQLMaxDepth, ac.GraphQLMaxAliases, ac.GraphQLMaxCost, ac.GraphQLIntrospection)
+	persistedQueryStore := interfaces_graphql.NewPersistedQueryStore(l, ac.GraphQLAPQCacheSize)
+	// 許可リストモードの場合、許可リストを読み込む
+	if ac.GraphQLAllowlistOnly {
+		err = persistedQueryStore.LoadAllowlist(ac.GraphQLAllowlistFile)
+		if err != nil {
+			l.ErrorLog.Fatalf("Failed to load operation allowlist: %v", err)
+		}
+	}
+
+	// middleware
+	rateLimiter := middleware.NewGraphQLRateLimiter(l, ac)
+
+	// job
+	trashPurgeJob := job.NewTrashPurgeJob(l, todoUsecase, time.Duration(ac.TrashPurgeIntervalMinutes)*time.Minute, time.Duration(ac.TrashRetentionDays)*24*time.Hour)
+	trashPurgeJob.Start(ctx)
+	reminderJob := job.NewReminderJob(l, reminderUsecase, time.Duration(ac.ReminderIntervalSeconds)*time.Second)
+	reminderJob.Start(ctx)
+	positionRebalanceJob := job.NewPositionRebalanceJob(l, todoUsecase, time.Duration(ac.TodoPositionRebalanceIntervalMinutes)*time.Minute, ac.TodoPositionMaxLength)
+	positionRebalanceJob.Start(ctx)
+	webhookDeliveryJob := job.NewWebhookDeliveryJob(l, webhookUsecase, time.Duration(ac.WebhookDeliveryIntervalSeconds)*time.Second)
+	webhookDeliveryJob.Start(ctx)
 
 	// router
-	router.SetUpRouter(e, l, ac, graphqlHandler, authHandler)
+	router.SetUpRouter(e, l, ac, graphqlHandler, authHandler, queryLimiter, persistedQueryStore, rateLimiter, transferHandler, calendarFeedHandler, attachmentHandler)
 }
 
 // アプリケーションのメイン関数
@@ -67,9 +195,19 @@ func main() {
 
 	// Echoの設定
 	e := echo.New()
+	// クライアントのIPアドレスの取得方法(プロキシ配下の場合のみX-Forwarded-Forを信用する)
+	if appConfig.TrustProxyHeaders {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	} else {
+		e.IPExtractor = echo.ExtractIPDirect()
+	}
+
+	// バックグラウンドジョブのコンテキスト(シャットダウン時にキャンセルする)
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
 
 	// セットアップ
-	setUp(e, appConfig, logger, supabaseClient)
+	setUp(jobCtx, e, appConfig, logger, supabaseClient)
 
 	// シグナルハンドラーの設定
 	quit := make(chan os.Signal, 1)
@@ -80,6 +218,9 @@ func main() {
 		<-quit
 		logger.InfoLog.Println("Shutting down server...")
 
+		// バックグラウンドジョブの停止
+		cancelJobs()
+
 		// Echoサーバーのシャットダウン
 		if err := e.Close(); err != nil {
 			logger.ErrorLog.Printf("Echo shutdown failed: %v", err)
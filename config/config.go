@@ -4,16 +4,110 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 // アプリケーションの設定
 type AppConfig struct {
+	// 実行環境(development / production、未設定の場合はproduction)
+	AppEnv    string
 	TestAPI   string
 	UserID    string
 	UserRole  string
 	JWTSecret string
+	// プロキシのX-Forwarded-Forヘッダーを信用するかどうか
+	TrustProxyHeaders bool
+	// 管理者のユーザーID
+	AdminUserIDs []string
+	// GraphQLクエリの最大深さ
+	GraphQLMaxDepth int
+	// GraphQLクエリの最大エイリアス数
+	GraphQLMaxAliases int
+	// GraphQLクエリの最大コスト
+	GraphQLMaxCost int
+	// イントロスペクションを許可するかどうか
+	GraphQLIntrospection bool
+	// GraphQLのリクエストボディの最大サイズ(byte、multipartを除く)
+	GraphQLMaxBodyBytes int64
+	// バッチリクエストの最大オペレーション数(0以下の場合は無制限)
+	GraphQLMaxBatchSize int
+	// APQキャッシュの最大件数
+	GraphQLAPQCacheSize int
+	// 許可リストに登録されたオペレーションのみ受け付けるかどうか
+	GraphQLAllowlistOnly bool
+	// 許可リストファイルのパス
+	GraphQLAllowlistFile string
+	// クエリのレート制限(1分あたり)
+	RateLimitQueryPerMinute int
+	// クエリのレート制限(バースト)
+	RateLimitQueryBurst int
+	// ミューテーションのレート制限(1分あたり)
+	RateLimitMutationPerMinute int
+	// ミューテーションのレート制限(バースト)
+	RateLimitMutationBurst int
+	// ゴミ箱のTodoの保持期間(日)
+	TrashRetentionDays int
+	// ゴミ箱の自動削除の実行間隔(分、0以下の場合は無効)
+	TrashPurgeIntervalMinutes int
+	// リマインドの確認間隔(秒、0以下の場合は無効)
+	ReminderIntervalSeconds int
+	// 繰り返しのタイムゾーンを指定しなかった場合のタイムゾーン(IANA名)
+	RecurrenceDefaultTimezone string
+	// Todoの書き込みをイベントソーシングで行うかどうか
+	TodoEventSourcing bool
+	// Todoのスナップショットを作成するイベントの間隔(0以下の場合は作成しない)
+	TodoSnapshotInterval int
+	// Todoの並び順のキーの最大長(これより長いキーを持つユーザーのキーを振り直す)
+	TodoPositionMaxLength int
+	// Todoの並び順のキーの振り直しの実行間隔(分、0以下の場合は無効)
+	TodoPositionRebalanceIntervalMinutes int
+	// Todoの全文検索の言語(PostgreSQLのテキスト検索設定名、日本語などは言語に関わらずbigramで検索する)
+	TodoSearchLanguage string
+	// カレンダーの購読フィードのURLの先頭(例: https://api.example.com、空の場合はパスのみ返す)
+	CalendarFeedBaseURL string
+	// カレンダーの購読フィードに含めるTodoの期限の過去の日数
+	CalendarFeedPastDays int
+	// カレンダーの購読フィードの更新間隔(分、クライアントのキャッシュ時間にも使う)
+	CalendarFeedRefreshMinutes int
+	// Webhookの配信の確認間隔(秒、0以下の場合は無効)
+	WebhookDeliveryIntervalSeconds int
+	// Webhookの送信のタイムアウト(秒)
+	WebhookTimeoutSeconds int
+	// Webhookの最大の送信回数(初回を含む)
+	WebhookMaxAttempts int
+	// Webhookの再送までの初期待ち時間(秒、失敗ごとに2倍になる)
+	WebhookBackoffBaseSeconds int
+	// Webhookの再送までの最大待ち時間(秒)
+	WebhookBackoffMaxSeconds int
+	// Webhookをループバック・プライベートなどの内部のアドレスに送信することを許可するか(ローカルでの動作確認用)
+	WebhookAllowPrivateNetworks bool
+	// Webhookのシークレットを暗号化する鍵(base64で符号化した32バイト、空の場合は平文のまま保存する)
+	WebhookSecretKey string
+	// ログイン失敗の集計期間(分)
+	LoginFailureWindowMinutes int
+	// アカウントをロックするログイン失敗回数
+	LoginMaxAccountFailures int
+	// IPアドレスをロックするログイン失敗回数
+	LoginMaxIPFailures int
+	// ロック期間(分)
+	LoginLockoutMinutes int
+	// バックオフを開始するログイン失敗回数
+	LoginBackoffThreshold int
+	// バックオフの初期待ち時間(秒)
+	LoginBackoffBaseSeconds int
+	// バックオフの最大待ち時間(秒)
+	LoginBackoffMaxSeconds int
+	// 添付ファイルの保存先ディレクトリ
+	AttachmentStorageDir string
+	// 添付ファイルの最大サイズ(byte)
+	AttachmentMaxSize int64
+	// 1リクエストで添付できるファイルの最大数
+	AttachmentMaxFiles int
+	// 添付ファイルとして許可するMIMEタイプ
+	AttachmentAllowedTypes []string
 }
 
 // アプリケーションの設定のインスタンス化
@@ -62,8 +156,119 @@ func (c *AppConfig) SetUpEnv() {
 		log.Println("No " + absPath + " file found")
 	}
 
+	// 未設定の場合は本番環境として扱い、開発用の機能(GraphiQL・イントロスペクション)は明示的に有効にする
+	c.AppEnv = os.Getenv("APP_ENV")
+	if c.AppEnv == "" {
+		log.Println("APP_ENV is not set, defaulting to production")
+		c.AppEnv = "production"
+	}
 	c.TestAPI = os.Getenv("TEST_API")
 	c.UserID = os.Getenv("USER_ID")
 	c.UserRole = os.Getenv("ROLE_USER")
 	c.JWTSecret = os.Getenv("JWT_SECRET")
+	c.TrustProxyHeaders = c.getEnvBool("TRUST_PROXY_HEADERS", false)
+	c.AdminUserIDs = c.getEnvList("ADMIN_USER_IDS", []string{})
+	c.GraphQLMaxDepth = c.getEnvInt("GRAPHQL_MAX_DEPTH", 8)
+	c.GraphQLMaxAliases = c.getEnvInt("GRAPHQL_MAX_ALIASES", 15)
+	c.GraphQLMaxCost = c.getEnvInt("GRAPHQL_MAX_COST", 1000)
+	c.GraphQLIntrospection = c.getEnvBool("GRAPHQL_INTROSPECTION", c.IsDevelopment())
+	c.GraphQLMaxBodyBytes = int64(c.getEnvInt("GRAPHQL_MAX_BODY_BYTES", 1024*1024))
+	c.GraphQLMaxBatchSize = c.getEnvInt("GRAPHQL_MAX_BATCH_SIZE", 10)
+	c.GraphQLAPQCacheSize = c.getEnvInt("GRAPHQL_APQ_CACHE_SIZE", 1000)
+	c.GraphQLAllowlistOnly = os.Getenv("GRAPHQL_ALLOWLIST_ONLY") == "true"
+	c.GraphQLAllowlistFile = os.Getenv("GRAPHQL_ALLOWLIST_FILE")
+	c.RateLimitQueryPerMinute = c.getEnvInt("RATE_LIMIT_QUERY_PER_MINUTE", 120)
+	c.RateLimitQueryBurst = c.getEnvInt("RATE_LIMIT_QUERY_BURST", 60)
+	c.RateLimitMutationPerMinute = c.getEnvInt("RATE_LIMIT_MUTATION_PER_MINUTE", 30)
+	c.RateLimitMutationBurst = c.getEnvInt("RATE_LIMIT_MUTATION_BURST", 10)
+	c.TrashRetentionDays = c.getEnvInt("TRASH_RETENTION_DAYS", 30)
+	c.TrashPurgeIntervalMinutes = c.getEnvInt("TRASH_PURGE_INTERVAL_MINUTES", 60)
+	c.ReminderIntervalSeconds = c.getEnvInt("REMINDER_INTERVAL_SECONDS", 60)
+	c.RecurrenceDefaultTimezone = os.Getenv("RECURRENCE_DEFAULT_TIMEZONE")
+	if c.RecurrenceDefaultTimezone == "" {
+		c.RecurrenceDefaultTimezone = "Asia/Tokyo"
+	}
+	c.TodoEventSourcing = c.getEnvBool("TODO_EVENT_SOURCING", false)
+	c.TodoSnapshotInterval = c.getEnvInt("TODO_SNAPSHOT_INTERVAL", 50)
+	c.TodoPositionMaxLength = c.getEnvInt("TODO_POSITION_MAX_LENGTH", 8)
+	c.TodoPositionRebalanceIntervalMinutes = c.getEnvInt("TODO_POSITION_REBALANCE_INTERVAL_MINUTES", 60)
+	c.TodoSearchLanguage = os.Getenv("TODO_SEARCH_LANGUAGE")
+	if c.TodoSearchLanguage == "" {
+		c.TodoSearchLanguage = "simple"
+	}
+	c.CalendarFeedBaseURL = os.Getenv("CALENDAR_FEED_BASE_URL")
+	c.CalendarFeedPastDays = c.getEnvInt("CALENDAR_FEED_PAST_DAYS", 30)
+	c.CalendarFeedRefreshMinutes = c.getEnvInt("CALENDAR_FEED_REFRESH_MINUTES", 15)
+	c.WebhookDeliveryIntervalSeconds = c.getEnvInt("WEBHOOK_DELIVERY_INTERVAL_SECONDS", 10)
+	c.WebhookTimeoutSeconds = c.getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10)
+	c.WebhookMaxAttempts = c.getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8)
+	c.WebhookBackoffBaseSeconds = c.getEnvInt("WEBHOOK_BACKOFF_BASE_SECONDS", 30)
+	c.WebhookBackoffMaxSeconds = c.getEnvInt("WEBHOOK_BACKOFF_MAX_SECONDS", 3600)
+	c.WebhookAllowPrivateNetworks = c.getEnvBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	c.WebhookSecretKey = os.Getenv("WEBHOOK_SECRET_KEY")
+	c.LoginFailureWindowMinutes = c.getEnvInt("LOGIN_FAILURE_WINDOW_MINUTES", 15)
+	c.LoginMaxAccountFailures = c.getEnvInt("LOGIN_MAX_ACCOUNT_FAILURES", 10)
+	c.LoginMaxIPFailures = c.getEnvInt("LOGIN_MAX_IP_FAILURES", 50)
+	c.LoginLockoutMinutes = c.getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)
+	c.LoginBackoffThreshold = c.getEnvInt("LOGIN_BACKOFF_THRESHOLD", 3)
+	c.LoginBackoffBaseSeconds = c.getEnvInt("LOGIN_BACKOFF_BASE_SECONDS", 1)
+	c.LoginBackoffMaxSeconds = c.getEnvInt("LOGIN_BACKOFF_MAX_SECONDS", 60)
+	c.AttachmentStorageDir = os.Getenv("ATTACHMENT_STORAGE_DIR")
+	if c.AttachmentStorageDir == "" {
+		c.AttachmentStorageDir = filepath.Join(projectRoot, "storage")
+	}
+	c.AttachmentMaxSize = int64(c.getEnvInt("ATTACHMENT_MAX_SIZE", 10*1024*1024))
+	c.AttachmentMaxFiles = c.getEnvInt("ATTACHMENT_MAX_FILES", 5)
+	c.AttachmentAllowedTypes = c.getEnvList("ATTACHMENT_ALLOWED_TYPES", []string{"image/png", "image/jpeg", "image/gif", "application/pdf", "text/plain"})
+}
+
+// カンマ区切りの環境変数を取得(未設定の場合はデフォルト値)
+func (c *AppConfig) getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	list := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// 開発環境かどうか
+func (c *AppConfig) IsDevelopment() bool {
+	return c.AppEnv == "development"
+}
+
+// 真偽値の環境変数を取得(未設定・不正な値の場合はデフォルト値)
+func (c *AppConfig) getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid value for %s: %s", key, value)
+		return defaultValue
+	}
+	return b
+}
+
+// 数値の環境変数を取得(未設定・不正な値の場合はデフォルト値)
+func (c *AppConfig) getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value for %s: %s", key, value)
+		return defaultValue
+	}
+	return n
 }
@@ -0,0 +1,15 @@
+package domain_attachment
+
+import "time"
+
+// 添付ファイル情報
+type Attachment struct {
+	ID          string    `json:"id"           db:"id"`           // UUID型
+	TodoId      string    `json:"todo_id"      db:"todo_id"`      // TodoID
+	UserId      string    `json:"user_id"      db:"user_id"`      // アップロードしたユーザーID
+	FileName    string    `json:"file_name"    db:"file_name"`    // ファイル名
+	ContentType string    `json:"content_type" db:"content_type"` // MIMEタイプ
+	Size        int64     `json:"size"         db:"size"`         // ファイルサイズ(byte)
+	StorageKey  string    `json:"storage_key"  db:"storage_key"`  // ストレージ上のキー
+	CreatedAt   time.Time `json:"created_at"   db:"created_at"`   // タイムスタンプ
+}
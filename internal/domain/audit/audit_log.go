@@ -0,0 +1,56 @@
+package domain_audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 監査ログの操作
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// 監査ログの対象エンティティ
+const (
+	AuditEntityTodo = "todo"
+)
+
+// ジョブなどシステムによる操作の操作者ID
+const SystemActorId = "system"
+
+// 監査ログ情報
+type AuditLog struct {
+	ID         string          `json:"id"          db:"id"`          // UUID型
+	ActorId    string          `json:"actor_id"    db:"actor_id"`    // 操作したユーザーID
+	Action     string          `json:"action"      db:"action"`      // 操作(create/update/delete)
+	EntityType string          `json:"entity_type" db:"entity_type"` // 対象エンティティの種類
+	EntityId   string          `json:"entity_id"   db:"entity_id"`   // 対象エンティティのID
+	Before     json.RawMessage `json:"before"      db:"before"`      // 変更前の値(作成時はnull)
+	After      json.RawMessage `json:"after"       db:"after"`       // 変更後の値(削除時はnull)
+	RequestId  string          `json:"request_id"  db:"request_id"`  // リクエストID
+	IPAddress  string          `json:"ip_address"  db:"ip_address"`  // クライアントのIPアドレス
+	CreatedAt  time.Time       `json:"created_at"  db:"created_at"`  // タイムスタンプ
+}
+
+// 操作者の情報(監査ログに記録する)
+type AuditActor struct {
+	UserId    string // 操作したユーザーID
+	RequestId string // リクエストID
+	IPAddress string // クライアントのIPアドレス
+}
+
+// 監査ログの検索条件(空の項目は条件に含めない)
+type AuditLogFilter struct {
+	ActorId    string
+	Action     string
+	EntityType string
+	EntityId   string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
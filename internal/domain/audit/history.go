@@ -0,0 +1,30 @@
+package domain_audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 変更された項目
+type FieldChange struct {
+	Field  string          // 項目名(JSONのキー)
+	Before json.RawMessage // 変更前の値(作成時はnil)
+	After  json.RawMessage // 変更後の値(完全削除時はnil)
+}
+
+// 変更履歴(監査ログから変更された項目の差分を求めたもの)
+type HistoryEntry struct {
+	ID        string        // 監査ログのID
+	EntityId  string        // 対象エンティティのID
+	ActorId   string        // 操作したユーザーID
+	Action    string        // 操作
+	Changes   []FieldChange // 変更された項目(項目名順)
+	CreatedAt time.Time     // 操作日時
+}
+
+// エンティティごとの変更履歴(ページネーション)
+type HistoryPage struct {
+	EntityId   string         // 対象エンティティのID
+	Items      []HistoryEntry // 新しい順の変更履歴
+	TotalCount int            // 変更履歴の総数
+}
@@ -0,0 +1,48 @@
+package domain_auth
+
+import "time"
+
+// ログイン試行の結果
+const (
+	LoginResultSuccess   = "success"   // 成功
+	LoginResultFailure   = "failure"   // 認証失敗
+	LoginResultLocked    = "locked"    // ロック中のため拒否
+	LoginResultThrottled = "throttled" // バックオフ中のため拒否
+	LoginResultUnlocked  = "unlocked"  // 管理者によるロック解除
+)
+
+// ロックの対象
+const (
+	LockoutScopeAccount = "account" // アカウント(メールアドレス)
+	LockoutScopeIP      = "ip"      // IPアドレス
+)
+
+// ログイン試行情報
+type LoginAttempt struct {
+	ID          string    `json:"id"           db:"id"`           // UUID型
+	Email       string    `json:"email"        db:"email"`        // メールアドレス
+	IPAddress   string    `json:"ip_address"   db:"ip_address"`   // IPアドレス
+	Result      string    `json:"result"       db:"result"`       // 結果
+	AttemptedAt time.Time `json:"attempted_at" db:"attempted_at"` // タイムスタンプ
+}
+
+// ログイン試行の予約結果
+type LoginAttemptReservation struct {
+	Attempts  int  // 予約した試行を含む試行回数
+	Throttled bool // バックオフ中のため予約できなかったかどうか
+}
+
+// ログイン試行のバックオフ
+type LoginBackoff struct {
+	Threshold int           // バックオフを開始する試行回数
+	Base      time.Duration // 初期待ち時間(試行ごとに2倍になる)
+	Max       time.Duration // 最大待ち時間
+}
+
+// ロック情報
+type LoginLockout struct {
+	Scope       string    `json:"scope"        db:"scope"`        // ロックの対象
+	Subject     string    `json:"subject"      db:"subject"`      // メールアドレスまたはIPアドレス
+	LockedUntil time.Time `json:"locked_until" db:"locked_until"` // ロック期限
+	CreatedAt   time.Time `json:"created_at"   db:"created_at"`   // タイムスタンプ
+}
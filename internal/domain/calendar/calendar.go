@@ -0,0 +1,90 @@
+package domain_calendar
+
+import (
+	domain_todo "backend/internal/domain/todo"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// フィードに含めるコンポーネントの種類
+const (
+	CalendarFeedTypeEvent = "event" // VEVENT(期限の日時の予定)
+	CalendarFeedTypeTodo  = "todo"  // VTODO(タスク)
+	CalendarFeedTypeBoth  = "both"  // VEVENTとVTODOの両方
+)
+
+// フィードの種類が有効な値かどうか
+func IsValidFeedType(feedType string) bool {
+	switch feedType {
+	case CalendarFeedTypeEvent, CalendarFeedTypeTodo, CalendarFeedTypeBoth:
+		return true
+	}
+	return false
+}
+
+// フィードに含めるTodoの最大件数
+const MaxCalendarFeedTodos = 1000
+
+// トークンのバイト数(base64url化して43文字)
+const calendarFeedTokenBytes = 32
+
+// カレンダーの購読フィード情報
+type CalendarFeed struct {
+	UserId         string     `json:"user_id"          db:"user_id"`          // ユーザーID
+	CreatedAt      time.Time  `json:"created_at"       db:"created_at"`       // トークンを発行した日時
+	LastAccessedAt *time.Time `json:"last_accessed_at" db:"last_accessed_at"` // 最後にフィードを取得した日時
+}
+
+// 発行したトークン(トークンとURLは発行時のみ返す)
+type CalendarFeedToken struct {
+	Token string       // 秘密のトークン
+	URL   string       // フィードのURL
+	Feed  CalendarFeed // フィード情報
+}
+
+// 秘密のトークンを生成
+func GenerateCalendarFeedToken() (string, error) {
+	b := make([]byte, calendarFeedTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// トークンのハッシュ(保存・照合用)
+func HashCalendarFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// 優先度をiCalendarのPRIORITY(1が最高、9が最低)に変換
+func ToICalPriority(priority string) int {
+	switch priority {
+	case domain_todo.TodoPriorityUrgent:
+		return 1
+	case domain_todo.TodoPriorityHigh:
+		return 3
+	case domain_todo.TodoPriorityLow:
+		return 9
+	default:
+		return 5
+	}
+}
+
+// iCalendarのPRIORITYを優先度に変換(0は未指定としてmedium)
+func PriorityFromICal(value int) (string, bool) {
+	switch {
+	case value == 0 || value == 5:
+		return domain_todo.TodoPriorityMedium, true
+	case value >= 1 && value <= 2:
+		return domain_todo.TodoPriorityUrgent, true
+	case value >= 3 && value <= 4:
+		return domain_todo.TodoPriorityHigh, true
+	case value >= 6 && value <= 9:
+		return domain_todo.TodoPriorityLow, true
+	}
+	return "", false
+}
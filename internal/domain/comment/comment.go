@@ -0,0 +1,23 @@
+package domain_comment
+
+import "time"
+
+// コメント本文の最大文字数
+const MaxCommentBodyLength = 2000
+
+// コメント情報
+type Comment struct {
+	ID        string     `json:"id"         db:"id"`         // UUID型
+	TodoId    string     `json:"todo_id"    db:"todo_id"`    // コメント先のTodoID
+	AuthorId  string     `json:"author_id"  db:"author_id"`  // 投稿したユーザーID
+	Body      string     `json:"body"       db:"body"`       // 本文
+	CreatedAt time.Time  `json:"created_at" db:"created_at"` // タイムスタンプ
+	EditedAt  *time.Time `json:"edited_at"  db:"edited_at"`  // 最終編集日時(未編集の場合はnil)
+}
+
+// Todoごとのコメントの一覧(ページネーション)
+type CommentPage struct {
+	TodoId     string    // TodoID
+	Items      []Comment // 投稿順のコメント
+	TotalCount int       // コメントの総数
+}
@@ -0,0 +1,21 @@
+package domain_recurrence
+
+import "time"
+
+// Todoの繰り返し設定
+// 繰り返しは常に未完了の1件のTodoが持ち、完了すると次の発生日を期限とするTodoに引き継がれる。
+type Recurrence struct {
+	TodoId    string      `json:"todo_id"    db:"todo_id"`    // 現在の発生分のTodoID
+	Rule      string      `json:"rule"       db:"rule"`       // RFC 5545のRRULE
+	Timezone  string      `json:"timezone"   db:"timezone"`   // 展開に使うタイムゾーン(IANA名)
+	StartAt   time.Time   `json:"start_at"   db:"start_at"`   // 最初の発生日時(DTSTART)
+	ExDates   []time.Time `json:"exdates"    db:"exdates"`    // スキップする発生日時(EXDATE)
+	CreatedAt time.Time   `json:"created_at" db:"created_at"` // タイムスタンプ
+	UpdatedAt time.Time   `json:"updated_at" db:"updated_at"` // タイムスタンプ
+}
+
+// 繰り返しの発生
+type Occurrence struct {
+	TodoId string    `json:"todo_id"` // 繰り返しを持つTodoID
+	At     time.Time `json:"at"`      // 発生日時
+}
@@ -0,0 +1,193 @@
+package domain_search
+
+import (
+	domain_todo "backend/internal/domain/todo"
+	"encoding/base64"
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 検索語の最大文字数
+const MaxTodoSearchQueryLength = 200
+
+// 抜粋内で一致した箇所を囲むタグ
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// ts_headlineで一致した箇所を囲む区切り文字(私用領域の文字、抜粋の生成時にタグに置き換える)
+const (
+	SnippetStartSel = "\ue000"
+	SnippetStopSel  = "\ue001"
+)
+
+// 検索語(分かち書きされない日本語などの部分と、それ以外の部分に分けたもの)
+type TodoSearchQuery struct {
+	Text string   // 日本語など以外の部分(言語の設定に従って単語に分割する)
+	Runs []string // ひらがな・カタカナ・漢字の連続した部分(bigramに分割して検索する)
+}
+
+// 検索結果の1件
+type TodoSearchResult struct {
+	Todo    domain_todo.Todo // 一致したTodo
+	Rank    float32          // 関連度(大きいほど関連が高い)
+	Snippet string           // 説明の抜粋(HTMLエスケープ済み、一致した箇所を<mark>で囲む)
+}
+
+// 検索結果の一覧(カーソルによるページネーション)
+type TodoSearchPage struct {
+	Results     []TodoSearchResult // 関連度の高い順の検索結果
+	HasNextPage bool               // 次のページがあるかどうか
+	EndCursor   string             // 最後の検索結果のカーソル(結果が無い場合は空)
+}
+
+// 検索結果の位置(関連度とIDの組)
+type TodoSearchCursor struct {
+	Rank float32
+	ID   string
+}
+
+// 検索語を分割
+func ParseTodoSearchQuery(q string) TodoSearchQuery {
+	var text strings.Builder
+	var run strings.Builder
+	query := TodoSearchQuery{Runs: []string{}}
+	flush := func() {
+		if run.Len() > 0 {
+			query.Runs = append(query.Runs, run.String())
+			run.Reset()
+		}
+	}
+	for _, r := range q {
+		if IsBigramRune(r) {
+			run.WriteRune(r)
+			continue
+		}
+		if run.Len() > 0 {
+			flush()
+			text.WriteRune(' ')
+		}
+		text.WriteRune(r)
+	}
+	flush()
+	query.Text = strings.TrimSpace(text.String())
+	return query
+}
+
+// bigramに分割して検索する文字(ひらがな・カタカナ・漢字)かどうか
+// マイグレーションのtodo_search_bigramsの文字の範囲と揃えること。
+func IsBigramRune(r rune) bool {
+	switch {
+	case r >= 0x3041 && r <= 0x3096: // ひらがな
+		return true
+	case r >= 0x30A1 && r <= 0x30FA: // カタカナ
+		return true
+	case r == 0x30FC || r == 0x3005 || r == 0x3006: // 長音符・々・〆
+		return true
+	case r >= 0x3400 && r <= 0x4DBF: // CJK統合漢字拡張A
+		return true
+	case r >= 0x4E00 && r <= 0x9FFF: // CJK統合漢字
+		return true
+	case r >= 0xFF66 && r <= 0xFF9D: // 半角カタカナ
+		return true
+	}
+	return false
+}
+
+// 文字列をbigramに分割(1文字の場合はそのまま返す)
+func Bigrams(run string) []string {
+	runes := []rune(run)
+	if len(runes) <= 1 {
+		return []string{run}
+	}
+	bigrams := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		bigrams = append(bigrams, string(runes[i:i+2]))
+	}
+	return bigrams
+}
+
+// ts_headlineで生成した抜粋から、レスポンス用の抜粋を生成
+// 日本語などの部分はts_headlineでは強調されないため、検索語と一致する箇所も強調し、強調箇所以外をHTMLエスケープする。
+func BuildSnippet(headline string, runs []string) string {
+	// 区切り文字を取り除き、強調する位置(バイト単位)を記録する
+	var plain strings.Builder
+	marked := []bool{}
+	highlighted := false
+	for _, r := range headline {
+		switch string(r) {
+		case SnippetStartSel:
+			highlighted = true
+			continue
+		case SnippetStopSel:
+			highlighted = false
+			continue
+		}
+		n, _ := plain.WriteRune(r)
+		for i := 0; i < n; i++ {
+			marked = append(marked, highlighted)
+		}
+	}
+	text := plain.String()
+
+	// 検索語と一致する箇所(重なりを含む)を強調する
+	for _, run := range runs {
+		if run == "" {
+			continue
+		}
+		for offset := 0; offset < len(text); {
+			i := strings.Index(text[offset:], run)
+			if i < 0 {
+				break
+			}
+			for j := offset + i; j < offset+i+len(run); j++ {
+				marked[j] = true
+			}
+			_, size := utf8.DecodeRuneInString(text[offset+i:])
+			offset += i + size
+		}
+	}
+
+	// 強調箇所ごとにHTMLエスケープし、タグで囲む
+	var b strings.Builder
+	for start := 0; start < len(text); {
+		end := start
+		for end < len(text) && marked[end] == marked[start] {
+			end++
+		}
+		segment := html.EscapeString(text[start:end])
+		if marked[start] {
+			segment = HighlightStart + segment + HighlightEnd
+		}
+		b.WriteString(segment)
+		start = end
+	}
+	return b.String()
+}
+
+// カーソルを文字列に変換
+func EncodeTodoSearchCursor(cursor TodoSearchCursor) string {
+	raw := strconv.FormatFloat(float64(cursor.Rank), 'g', -1, 32) + ":" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// 文字列からカーソルを復元
+func DecodeTodoSearchCursor(s string) (TodoSearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return TodoSearchCursor{}, errors.New("invalid cursor")
+	}
+	rank, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return TodoSearchCursor{}, errors.New("invalid cursor")
+	}
+	r, err := strconv.ParseFloat(rank, 32)
+	if err != nil {
+		return TodoSearchCursor{}, errors.New("invalid cursor")
+	}
+	return TodoSearchCursor{Rank: float32(r), ID: id}, nil
+}
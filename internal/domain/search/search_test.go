@@ -0,0 +1,103 @@
+package domain_search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTodoSearchQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  TodoSearchQuery
+	}{
+		{name: "latin only", query: "buy milk", want: TodoSearchQuery{Text: "buy milk", Runs: []string{}}},
+		{name: "japanese only", query: "牛乳を買う", want: TodoSearchQuery{Text: "", Runs: []string{"牛乳を買う"}}},
+		{name: "mixed", query: "会議 review 資料", want: TodoSearchQuery{Text: "review", Runs: []string{"会議", "資料"}}},
+		{name: "adjacent", query: "PR確認", want: TodoSearchQuery{Text: "PR", Runs: []string{"確認"}}},
+		{name: "katakana with long vowel", query: "レビュー", want: TodoSearchQuery{Text: "", Runs: []string{"レビュー"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTodoSearchQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTodoSearchQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBigrams(t *testing.T) {
+	tests := []struct {
+		run  string
+		want []string
+	}{
+		{run: "会", want: []string{"会"}},
+		{run: "会議", want: []string{"会議"}},
+		{run: "会議室", want: []string{"会議", "議室"}},
+	}
+
+	for _, tt := range tests {
+		if got := Bigrams(tt.run); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Bigrams(%q) = %v, want %v", tt.run, got, tt.want)
+		}
+	}
+}
+
+func TestBuildSnippet(t *testing.T) {
+	tests := []struct {
+		name     string
+		headline string
+		runs     []string
+		want     string
+	}{
+		{
+			name:     "headline selection",
+			headline: "buy " + SnippetStartSel + "milk" + SnippetStopSel + " today",
+			want:     "buy <mark>milk</mark> today",
+		},
+		{
+			name:     "japanese run",
+			headline: "明日の会議の資料",
+			runs:     []string{"会議"},
+			want:     "明日の<mark>会議</mark>の資料",
+		},
+		{
+			name:     "overlapping runs are merged",
+			headline: "会議室の予約",
+			runs:     []string{"会議", "議室"},
+			want:     "<mark>会議室</mark>の予約",
+		},
+		{
+			name:     "html is escaped",
+			headline: "<b>" + SnippetStartSel + "fix" + SnippetStopSel + "</b> & 確認",
+			runs:     []string{"確認"},
+			want:     "&lt;b&gt;<mark>fix</mark>&lt;/b&gt; &amp; <mark>確認</mark>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildSnippet(tt.headline, tt.runs); got != tt.want {
+				t.Errorf("BuildSnippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoSearchCursor(t *testing.T) {
+	cursor := TodoSearchCursor{Rank: 0.0607927, ID: "7b0c5a6e-9f1e-4c1d-8a57-2f0e1d3c4b5a"}
+	got, err := DecodeTodoSearchCursor(EncodeTodoSearchCursor(cursor))
+	if err != nil {
+		t.Fatalf("DecodeTodoSearchCursor() unexpected error: %v", err)
+	}
+	if got != cursor {
+		t.Errorf("DecodeTodoSearchCursor() = %+v, want %+v", got, cursor)
+	}
+
+	for _, invalid := range []string{"!!", "bm9jb2xvbg", "MC41Og", "eDppZA"} {
+		if _, err := DecodeTodoSearchCursor(invalid); err == nil {
+			t.Errorf("DecodeTodoSearchCursor(%q) accepted an invalid cursor", invalid)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package domain_share
+
+import "time"
+
+// 共有の権限
+const (
+	ShareRoleViewer = "viewer" // 閲覧のみ
+	ShareRoleEditor = "editor" // 閲覧・編集
+	ShareRoleOwner  = "owner"  // 閲覧・編集・削除・共有の管理
+)
+
+// 共有の状態
+const (
+	ShareStatusPending  = "pending"  // 招待中
+	ShareStatusAccepted = "accepted" // 承諾済み
+)
+
+// 権限の強さ(大きいほど強い)
+var shareRoleRanks = map[string]int{
+	ShareRoleViewer: 1,
+	ShareRoleEditor: 2,
+	ShareRoleOwner:  3,
+}
+
+// 権限が有効な値かどうか
+func IsValidRole(role string) bool {
+	_, ok := shareRoleRanks[role]
+	return ok
+}
+
+// roleがrequired以上の権限かどうか
+func RoleAtLeast(role string, required string) bool {
+	return shareRoleRanks[role] >= shareRoleRanks[required]
+}
+
+// 共有情報
+// TodoIdがnilの場合は、所有者の全てのTodoを共有する
+type TodoShare struct {
+	ID         string     `json:"id"          db:"id"`          // UUID型
+	OwnerId    string     `json:"owner_id"    db:"owner_id"`    // 共有するTodoの所有者のユーザーID
+	TodoId     *string    `json:"todo_id"     db:"todo_id"`     // 共有するTodoのID(nilは全てのTodo)
+	MemberId   string     `json:"member_id"   db:"member_id"`   // 共有先のユーザーID
+	Role       string     `json:"role"        db:"role"`        // 権限
+	Status     string     `json:"status"      db:"status"`      // 状態
+	InvitedBy  string     `json:"invited_by"  db:"invited_by"`  // 招待したユーザーID
+	CreatedAt  time.Time  `json:"created_at"  db:"created_at"`  // タイムスタンプ
+	AcceptedAt *time.Time `json:"accepted_at" db:"accepted_at"` // 承諾日時
+}
+
+// 権限のうち最も強いものを返す(権限がない場合は空文字)
+func HighestRole(roles []string) string {
+	highest := ""
+	for _, role := range roles {
+		if shareRoleRanks[role] > shareRoleRanks[highest] {
+			highest = role
+		}
+	}
+	return highest
+}
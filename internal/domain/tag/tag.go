@@ -0,0 +1,20 @@
+package domain_tag
+
+import "time"
+
+// タグ名の最大文字数
+const MaxTagNameLength = 50
+
+// タグ情報
+type Tag struct {
+	ID        string    `json:"id"         db:"id"`         // UUID型
+	UserId    string    `json:"user_id"    db:"user_id"`    // 所有者のユーザーID
+	Name      string    `json:"name"       db:"name"`       // タグ名
+	CreatedAt time.Time `json:"created_at" db:"created_at"` // タイムスタンプ
+}
+
+// Todoに付与されたタグ
+type TodoTag struct {
+	TodoId string `json:"todo_id" db:"todo_id"` // TodoID
+	Tag    Tag    `json:"tag"`                  // タグ
+}
@@ -2,12 +2,59 @@ package domain_todo
 
 import "time"
 
+// Todoの優先度
+const (
+	TodoPriorityLow    = "low"
+	TodoPriorityMedium = "medium"
+	TodoPriorityHigh   = "high"
+	TodoPriorityUrgent = "urgent"
+)
+
+// 優先度が有効な値かどうか
+func IsValidPriority(priority string) bool {
+	switch priority {
+	case TodoPriorityLow, TodoPriorityMedium, TodoPriorityHigh, TodoPriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// サブタスクを含めたTodoの階層の最大の深さ(最上位のTodoを1とする)
+const MaxTodoDepth = 5
+
 // Todo情報
 type Todo struct {
-	ID          string    `json:"id"          db:"id"`          // UUID型
-	Description string    `json:"description" db:"description"` // タスクの説明
-	Completed   bool      `json:"completed"   db:"completed"`   // タスクが完了しているかどうか
-	UserId      string    `json:"user_id"     db:"user_id"`     // ユーザーID
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`   // タイムスタンプ
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`   // タイムスタンプ
+	ID          string     `json:"id"          db:"id"`          // UUID型
+	Description string     `json:"description" db:"description"` // タスクの説明
+	Completed   bool       `json:"completed"   db:"completed"`   // タスクが完了しているかどうか
+	UserId      string     `json:"user_id"     db:"user_id"`     // ユーザーID
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`   // タイムスタンプ
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`   // タイムスタンプ
+	DeletedAt   *time.Time `json:"deleted_at" db:"deleted_at"`   // 削除日時(ゴミ箱にある場合のみ)
+	DueAt       *time.Time `json:"due_at" db:"due_at"`           // 期限
+	Priority    string     `json:"priority" db:"priority"`       // 優先度(low / medium / high / urgent)
+	RemindAt    *time.Time `json:"remind_at" db:"remind_at"`     // リマインド日時
+	RemindedAt  *time.Time `json:"reminded_at" db:"reminded_at"` // リマインドを通知した日時
+	ListId      *string    `json:"list_id" db:"list_id"`         // TodoリストID(インボックスの場合はnil)
+	ParentId    *string    `json:"parent_id" db:"parent_id"`     // 親TodoのID(最上位の場合はnil)
+	Position    *string    `json:"position" db:"position"`       // 並び順のキー(昇順、未設定の場合はnil)
+}
+
+// サブタスクの進捗(子孫のTodoを集計する)
+type TodoProgress struct {
+	TodoId    string `json:"todo_id"`   // 親TodoのID
+	Completed int    `json:"completed"` // 完了したサブタスクの件数
+	Total     int    `json:"total"`     // サブタスクの件数
+}
+
+// 一括操作で一度に指定できるTodoの最大件数
+const MaxBulkTodoIds = 100
+
+// 一括操作の1件ごとの結果
+type TodoBulkResult struct {
+	ID      string `json:"id"`      // TodoのID
+	Success bool   `json:"success"` // 操作に成功したかどうか
+	Message string `json:"message"` // 失敗した理由(成功した場合は空)
+	Changed bool   `json:"changed"` // 状態が変化したかどうか(既に指定の状態だった場合はfalse)
+	Todo    *Todo  `json:"todo"`    // 操作後のTodo(失敗した場合はnil)
 }
@@ -0,0 +1,309 @@
+package domain_todo
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Todoのドメインイベントの種類
+const (
+	TodoEventCreated     = "TodoCreated"     // 作成
+	TodoEventRenamed     = "TodoRenamed"     // 説明の変更
+	TodoEventCompleted   = "TodoCompleted"   // 完了
+	TodoEventReopened    = "TodoReopened"    // 未完了に戻す
+	TodoEventRescheduled = "TodoRescheduled" // 期限・優先度・リマインド日時の変更
+	TodoEventMoved       = "TodoMoved"       // 所属リストの変更
+	TodoEventReparented  = "TodoReparented"  // 親Todoの変更
+	TodoEventDeleted     = "TodoDeleted"     // ゴミ箱に移動
+	TodoEventRestored    = "TodoRestored"    // ゴミ箱から復元
+	TodoEventPurged      = "TodoPurged"      // 完全に削除
+	TodoEventReordered   = "TodoReordered"   // 並び順のキーの変更
+	TodoEventReminded    = "TodoReminded"    // リマインドの通知
+)
+
+// Todoのドメインイベント(イベントストアに追記のみ行う)
+type TodoEvent struct {
+	Seq         int64           `json:"seq"`          // イベントストア全体での連番
+	AggregateId string          `json:"aggregate_id"` // TodoのID
+	Version     int             `json:"version"`      // Todoごとのバージョン(1から始まる連番)
+	Type        string          `json:"type"`         // イベントの種類
+	Payload     json.RawMessage `json:"payload"`      // イベントの内容
+	ActorId     string          `json:"actor_id"`     // 操作したユーザーID
+	OccurredAt  time.Time       `json:"occurred_at"`  // 発生日時
+}
+
+// TodoCreatedの内容(作成時の状態)
+type TodoCreatedPayload struct {
+	Description string     `json:"description"`
+	Completed   bool       `json:"completed"`
+	UserId      string     `json:"user_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DueAt       *time.Time `json:"due_at"`
+	Priority    string     `json:"priority"`
+	RemindAt    *time.Time `json:"remind_at"`
+	ListId      *string    `json:"list_id"`
+	ParentId    *string    `json:"parent_id"`
+	Position    *string    `json:"position,omitempty"`
+}
+
+// TodoRenamedの内容
+type TodoRenamedPayload struct {
+	Description string `json:"description"`
+}
+
+// TodoRescheduledの内容
+type TodoRescheduledPayload struct {
+	DueAt    *time.Time `json:"due_at"`
+	Priority string     `json:"priority"`
+	RemindAt *time.Time `json:"remind_at"`
+}
+
+// TodoMovedの内容(インボックスの場合はnil)
+type TodoMovedPayload struct {
+	ListId *string `json:"list_id"`
+}
+
+// TodoReparentedの内容(最上位の場合はnil)
+type TodoReparentedPayload struct {
+	ParentId *string `json:"parent_id"`
+}
+
+// TodoDeletedの内容
+type TodoDeletedPayload struct {
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// TodoReorderedの内容
+// キーの振り直し(Rebalanced)は並び順を変えないため、更新日時を変更しない。
+type TodoReorderedPayload struct {
+	Position   string `json:"position"`
+	Rebalanced bool   `json:"rebalanced,omitempty"`
+}
+
+// TodoRemindedの内容
+type TodoRemindedPayload struct {
+	RemindedAt time.Time `json:"reminded_at"`
+}
+
+// Todoのスナップショット(あるバージョンまでのイベントを適用した状態)
+type TodoSnapshot struct {
+	AggregateId string    `json:"aggregate_id"`
+	Version     int       `json:"version"`
+	State       Todo      `json:"state"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ドメインイベントを作成(バージョンはイベントストアへの追記時に採番する)
+func NewTodoEvent(aggregateId string, eventType string, payload interface{}, occurredAt time.Time) (TodoEvent, error) {
+	data := []byte("{}")
+	if payload != nil {
+		var err error
+		data, err = json.Marshal(payload)
+		if err != nil {
+			return TodoEvent{}, err
+		}
+	}
+	return TodoEvent{
+		AggregateId: aggregateId,
+		Type:        eventType,
+		Payload:     data,
+		OccurredAt:  normalizeEventTime(occurredAt),
+	}, nil
+}
+
+// Todoの状態からTodoCreatedを作成
+func NewTodoCreatedEvent(todo Todo) (TodoEvent, error) {
+	return NewTodoEvent(todo.ID, TodoEventCreated, TodoCreatedPayload{
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		UserId:      todo.UserId,
+		CreatedAt:   normalizeEventTime(todo.CreatedAt),
+		UpdatedAt:   normalizeEventTime(todo.UpdatedAt),
+		DueAt:       normalizeEventTimePtr(todo.DueAt),
+		Priority:    todo.Priority,
+		RemindAt:    normalizeEventTimePtr(todo.RemindAt),
+		ListId:      todo.ListId,
+		ParentId:    todo.ParentId,
+		Position:    todo.Position,
+	}, todo.CreatedAt)
+}
+
+// 2つの状態の差分からドメインイベントを作成(所有者・作成日時の変更はイベントにしない)
+// 復元は最初に、ゴミ箱への移動は最後に並べる。並び順のキー・リマインドの通知日時は設定された場合のみイベントにする。
+func DiffTodoEvents(before Todo, after Todo, occurredAt time.Time) ([]TodoEvent, error) {
+	type change struct {
+		eventType string
+		payload   interface{}
+	}
+	changes := []change{}
+
+	if before.DeletedAt != nil && after.DeletedAt == nil {
+		changes = append(changes, change{TodoEventRestored, nil})
+	}
+	if before.Description != after.Description {
+		changes = append(changes, change{TodoEventRenamed, TodoRenamedPayload{Description: after.Description}})
+	}
+	if !before.Completed && after.Completed {
+		changes = append(changes, change{TodoEventCompleted, nil})
+	}
+	if before.Completed && !after.Completed {
+		changes = append(changes, change{TodoEventReopened, nil})
+	}
+	if !equalTimePtr(before.DueAt, after.DueAt) || before.Priority != after.Priority || !equalTimePtr(before.RemindAt, after.RemindAt) {
+		changes = append(changes, change{TodoEventRescheduled, TodoRescheduledPayload{
+			DueAt:    normalizeEventTimePtr(after.DueAt),
+			Priority: after.Priority,
+			RemindAt: normalizeEventTimePtr(after.RemindAt),
+		}})
+	}
+	if !equalStringPtr(before.ListId, after.ListId) {
+		changes = append(changes, change{TodoEventMoved, TodoMovedPayload{ListId: after.ListId}})
+	}
+	if !equalStringPtr(before.ParentId, after.ParentId) {
+		changes = append(changes, change{TodoEventReparented, TodoReparentedPayload{ParentId: after.ParentId}})
+	}
+	if after.Position != nil && !equalStringPtr(before.Position, after.Position) {
+		changes = append(changes, change{TodoEventReordered, TodoReorderedPayload{Position: *after.Position}})
+	}
+	if after.RemindedAt != nil && !equalTimePtr(before.RemindedAt, after.RemindedAt) {
+		changes = append(changes, change{TodoEventReminded, TodoRemindedPayload{RemindedAt: normalizeEventTime(*after.RemindedAt)}})
+	}
+	if before.DeletedAt == nil && after.DeletedAt != nil {
+		changes = append(changes, change{TodoEventDeleted, TodoDeletedPayload{DeletedAt: normalizeEventTime(*after.DeletedAt)}})
+	}
+
+	events := make([]TodoEvent, 0, len(changes))
+	for _, c := range changes {
+		event, err := NewTodoEvent(after.ID, c.eventType, c.payload, occurredAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ドメインイベントを状態に適用(投影)
+// リマインド日時が変わった場合は未通知に戻す。
+func ApplyTodoEvent(state Todo, event TodoEvent) (Todo, error) {
+	switch event.Type {
+	case TodoEventCreated:
+		var p TodoCreatedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return Todo{}, err
+		}
+		return Todo{
+			ID:          event.AggregateId,
+			Description: p.Description,
+			Completed:   p.Completed,
+			UserId:      p.UserId,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.UpdatedAt,
+			DueAt:       p.DueAt,
+			Priority:    p.Priority,
+			RemindAt:    p.RemindAt,
+			ListId:      p.ListId,
+			ParentId:    p.ParentId,
+			Position:    p.Position,
+		}, nil
+	case TodoEventRenamed:
+		var p TodoRenamedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return Todo{}, err
+		}
+		state.Description = p.Description
+		state.UpdatedAt = event.OccurredAt
+	case TodoEventCompleted:
+		state.Completed = true
+		state.UpdatedAt = event.OccurredAt
+	case TodoEventReopened:
+		state.Completed = false
+		state.UpdatedAt = event.OccurredAt
+	case TodoEventRescheduled:
+		var p TodoRescheduledPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return Todo{}, err
+		}
+		if !equalTimePtr(state.RemindAt, p.RemindAt) {
+			state.RemindedAt = nil
+		}
+		state.DueAt = p.DueAt
+		state.Priority = p.Priority
+		state.RemindAt = p.RemindAt
+		state.UpdatedAt = event.OccurredAt
+	case TodoEventMoved:
+		var p TodoMovedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return Todo{}, err
+		}
+		state.ListId = p.ListId
+		state.UpdatedAt = event.OccurredAt
+	case TodoEventReparented:
+		var p TodoReparentedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return Todo{}, err
+		}
+		state.ParentId = p.ParentId
+		state.UpdatedAt = event.OccurredAt
+	case TodoEventDeleted:
+		var p TodoDeletedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return Todo{}, err
+		}
+		state.DeletedAt = &p.DeletedAt
+	case TodoEventRestored:
+		state.DeletedAt = nil
+	case TodoEventReordered:
+		var p TodoReorderedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return Todo{}, err
+		}
+		state.Position = &p.Position
+		if !p.Rebalanced {
+			state.UpdatedAt = event.OccurredAt
+		}
+	case TodoEventReminded:
+		var p TodoRemindedPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return Todo{}, err
+		}
+		state.RemindedAt = &p.RemindedAt
+	case TodoEventPurged:
+		// 完全に削除された後の状態は投影しない
+	default:
+		return Todo{}, errors.New("unknown todo event type: " + event.Type)
+	}
+	return state, nil
+}
+
+// イベントに記録する日時を正規化(DBの精度に合わせてマイクロ秒に切り捨てる)
+func normalizeEventTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// イベントに記録する日時を正規化(nilの場合はnil)
+func normalizeEventTimePtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	n := normalizeEventTime(*t)
+	return &n
+}
+
+// 日時のポインタが同じ日時を指しているかどうか
+func equalTimePtr(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return normalizeEventTime(*a).Equal(normalizeEventTime(*b))
+}
+
+// 文字列のポインタが同じ値を指しているかどうか
+func equalStringPtr(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,260 @@
+package domain_todo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+// イベントを順に適用した状態
+func replay(t *testing.T, state Todo, events []TodoEvent) Todo {
+	t.Helper()
+	for _, event := range events {
+		var err error
+		state, err = ApplyTodoEvent(state, event)
+		if err != nil {
+			t.Fatalf("ApplyTodoEvent(%s) unexpected error: %v", event.Type, err)
+		}
+	}
+	return state
+}
+
+func eventTypes(events []TodoEvent) []string {
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func equalTypes(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func baseTodo() Todo {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return Todo{
+		ID:          "todo-1",
+		Description: "buy milk",
+		UserId:      "user-1",
+		CreatedAt:   created,
+		UpdatedAt:   created,
+		Priority:    TodoPriorityMedium,
+		ListId:      strPtr("list-1"),
+		Position:    strPtr("a0"),
+	}
+}
+
+// TodoCreatedを適用すると作成時の状態(並び順のキーを含む)になる
+func TestTodoCreatedEventRoundTrip(t *testing.T) {
+	todo := baseTodo()
+	event, err := NewTodoCreatedEvent(todo)
+	if err != nil {
+		t.Fatalf("NewTodoCreatedEvent() unexpected error: %v", err)
+	}
+	got := replay(t, Todo{}, []TodoEvent{event})
+	if got.ID != todo.ID || got.Description != todo.Description || !got.CreatedAt.Equal(todo.CreatedAt) ||
+		!equalStringPtr(got.ListId, todo.ListId) || !equalStringPtr(got.Position, todo.Position) {
+		t.Errorf("state = %+v, want %+v", got, todo)
+	}
+}
+
+// 並び順のキーを記録する前のTodoCreatedはキーを持たない
+func TestTodoCreatedEventWithoutPosition(t *testing.T) {
+	event := TodoEvent{AggregateId: "todo-1", Type: TodoEventCreated, Payload: json.RawMessage(`{"description":"old","user_id":"user-1","priority":"medium"}`)}
+	got := replay(t, Todo{}, []TodoEvent{event})
+	if got.Position != nil || got.Description != "old" {
+		t.Errorf("state = %+v, want description old without position", got)
+	}
+}
+
+func TestDiffTodoEvents(t *testing.T) {
+	at := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	remindAt := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		change func(todo *Todo)
+		want   []string
+	}{
+		{name: "no change", change: func(todo *Todo) {}, want: []string{}},
+		{name: "rename", change: func(todo *Todo) { todo.Description = "buy bread" }, want: []string{TodoEventRenamed}},
+		{name: "complete", change: func(todo *Todo) { todo.Completed = true }, want: []string{TodoEventCompleted}},
+		{name: "reschedule", change: func(todo *Todo) { todo.RemindAt = &remindAt }, want: []string{TodoEventRescheduled}},
+		{name: "move to inbox", change: func(todo *Todo) { todo.ListId = nil }, want: []string{TodoEventMoved}},
+		{name: "reparent", change: func(todo *Todo) { todo.ParentId = strPtr("todo-0") }, want: []string{TodoEventReparented}},
+		{name: "reorder", change: func(todo *Todo) { todo.Position = strPtr("a1") }, want: []string{TodoEventReordered}},
+		{name: "clearing position is ignored", change: func(todo *Todo) { todo.Position = nil }, want: []string{}},
+		{name: "remind", change: func(todo *Todo) { todo.RemindedAt = &at }, want: []string{TodoEventReminded}},
+		{name: "owner and created_at are ignored", change: func(todo *Todo) {
+			todo.UserId = "user-2"
+			todo.CreatedAt = at
+		}, want: []string{}},
+		{name: "delete comes last", change: func(todo *Todo) {
+			todo.DeletedAt = &at
+			todo.ListId = nil
+			todo.Description = "buy bread"
+		}, want: []string{TodoEventRenamed, TodoEventMoved, TodoEventDeleted}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := baseTodo()
+			after := before
+			tt.change(&after)
+
+			events, err := DiffTodoEvents(before, after, at)
+			if err != nil {
+				t.Fatalf("DiffTodoEvents() unexpected error: %v", err)
+			}
+			if got := eventTypes(events); !equalTypes(got, tt.want) {
+				t.Errorf("event types = %v, want %v", got, tt.want)
+			}
+			for _, event := range events {
+				if event.AggregateId != before.ID || !event.OccurredAt.Equal(at) {
+					t.Errorf("event = %+v, want aggregate %s at %v", event, before.ID, at)
+				}
+			}
+		})
+	}
+}
+
+// 復元は他の変更より先に並べる
+func TestDiffTodoEventsRestoreComesFirst(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	before := baseTodo()
+	before.DeletedAt = &deletedAt
+	after := baseTodo()
+	after.Completed = true
+
+	events, err := DiffTodoEvents(before, after, time.Now())
+	if err != nil {
+		t.Fatalf("DiffTodoEvents() unexpected error: %v", err)
+	}
+	if got, want := eventTypes(events), []string{TodoEventRestored, TodoEventCompleted}; !equalTypes(got, want) {
+		t.Errorf("event types = %v, want %v", got, want)
+	}
+}
+
+// 差分のイベントを適用すると変更後の状態になる
+func TestDiffTodoEventsReplaysToAfter(t *testing.T) {
+	at := time.Date(2026, 2, 1, 12, 0, 0, 123456789, time.UTC)
+	dueAt := time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC)
+	before := baseTodo()
+	after := before
+	after.Description = "buy bread"
+	after.Completed = true
+	after.DueAt = &dueAt
+	after.Priority = TodoPriorityHigh
+	after.ListId = nil
+	after.ParentId = strPtr("todo-0")
+	after.Position = strPtr("a5")
+
+	events, err := DiffTodoEvents(before, after, at)
+	if err != nil {
+		t.Fatalf("DiffTodoEvents() unexpected error: %v", err)
+	}
+	got := replay(t, before, events)
+
+	if got.Description != after.Description || got.Completed != after.Completed || !equalTimePtr(got.DueAt, after.DueAt) ||
+		got.Priority != after.Priority || !equalStringPtr(got.ListId, after.ListId) || !equalStringPtr(got.ParentId, after.ParentId) ||
+		!equalStringPtr(got.Position, after.Position) {
+		t.Errorf("replayed state = %+v, want %+v", got, after)
+	}
+	// 更新日時はイベントの発生日時(マイクロ秒に切り捨て)
+	if want := at.Truncate(time.Microsecond); !got.UpdatedAt.Equal(want) {
+		t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, want)
+	}
+}
+
+// 並び順の変更は更新日時を進め、キーの振り直しは進めない
+func TestApplyTodoReorderedEvent(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		rebalanced  bool
+		wantUpdated time.Time
+	}{
+		{name: "reorder", rebalanced: false, wantUpdated: at},
+		{name: "rebalance", rebalanced: true, wantUpdated: baseTodo().UpdatedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := NewTodoEvent("todo-1", TodoEventReordered, TodoReorderedPayload{Position: "b00", Rebalanced: tt.rebalanced}, at)
+			if err != nil {
+				t.Fatalf("NewTodoEvent() unexpected error: %v", err)
+			}
+			got := replay(t, baseTodo(), []TodoEvent{event})
+			if got.Position == nil || *got.Position != "b00" {
+				t.Errorf("Position = %v, want b00", got.Position)
+			}
+			if !got.UpdatedAt.Equal(tt.wantUpdated) {
+				t.Errorf("UpdatedAt = %v, want %v", got.UpdatedAt, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+// リマインドの通知は更新日時を変えず、リマインド日時の変更で未通知に戻る
+func TestApplyTodoRemindedEvent(t *testing.T) {
+	remindAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	remindedAt := time.Date(2026, 3, 1, 9, 0, 30, 0, time.UTC)
+	before := baseTodo()
+	before.RemindAt = &remindAt
+
+	reminded := before
+	reminded.RemindedAt = &remindedAt
+	events, err := DiffTodoEvents(before, reminded, remindedAt)
+	if err != nil {
+		t.Fatalf("DiffTodoEvents() unexpected error: %v", err)
+	}
+	got := replay(t, before, events)
+	if !equalTimePtr(got.RemindedAt, &remindedAt) || !got.UpdatedAt.Equal(before.UpdatedAt) {
+		t.Errorf("after reminded: RemindedAt = %v, UpdatedAt = %v, want %v, %v", got.RemindedAt, got.UpdatedAt, remindedAt, before.UpdatedAt)
+	}
+
+	// 期限・優先度のみの変更では通知済みのまま
+	rescheduled := got
+	rescheduled.Priority = TodoPriorityUrgent
+	events, err = DiffTodoEvents(got, rescheduled, time.Now())
+	if err != nil {
+		t.Fatalf("DiffTodoEvents() unexpected error: %v", err)
+	}
+	if got := replay(t, got, events); got.RemindedAt == nil {
+		t.Error("priority change reset RemindedAt")
+	}
+
+	// リマインド日時を変えると未通知に戻る
+	moved := got
+	moved.RemindAt = timePtr(remindAt.Add(time.Hour))
+	events, err = DiffTodoEvents(got, moved, time.Now())
+	if err != nil {
+		t.Fatalf("DiffTodoEvents() unexpected error: %v", err)
+	}
+	if got := replay(t, got, events); got.RemindedAt != nil {
+		t.Errorf("RemindedAt = %v after changing remind_at, want nil", got.RemindedAt)
+	}
+}
+
+func TestApplyTodoEventUnknownType(t *testing.T) {
+	if _, err := ApplyTodoEvent(baseTodo(), TodoEvent{Type: "TodoArchived", Payload: json.RawMessage(`{}`)}); err == nil {
+		t.Error("ApplyTodoEvent() with unknown type: want error")
+	}
+}
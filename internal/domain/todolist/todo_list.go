@@ -0,0 +1,33 @@
+package domain_todolist
+
+import "time"
+
+// リスト名の最大文字数
+const MaxTodoListNameLength = 100
+
+// リスト削除時のTodoの扱い
+const (
+	TodoListDeleteModeCascade     = "cascade"       // リスト内のTodoもゴミ箱に移動する
+	TodoListDeleteModeMoveToInbox = "move_to_inbox" // リスト内のTodoをインボックスに移動する
+)
+
+// Todoリスト情報
+type TodoList struct {
+	ID        string    `json:"id"         db:"id"`         // UUID型
+	UserId    string    `json:"user_id"    db:"user_id"`    // 所有者のユーザーID
+	Name      string    `json:"name"       db:"name"`       // リスト名
+	Color     string    `json:"color"      db:"color"`      // 表示色(#RRGGBB)
+	Archived  bool      `json:"archived"   db:"archived"`   // アーカイブ済みか
+	Position  int       `json:"position"   db:"position"`   // 表示順(昇順)
+	CreatedAt time.Time `json:"created_at" db:"created_at"` // タイムスタンプ
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"` // タイムスタンプ
+}
+
+// 削除モードが有効か判定
+func IsValidDeleteMode(mode string) bool {
+	switch mode {
+	case TodoListDeleteModeCascade, TodoListDeleteModeMoveToInbox:
+		return true
+	}
+	return false
+}
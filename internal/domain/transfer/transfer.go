@@ -0,0 +1,91 @@
+package domain_transfer
+
+import (
+	domain_todo "backend/internal/domain/todo"
+	"time"
+)
+
+// エクスポート・インポートの形式
+const (
+	TodoTransferFormatJSON = "json"
+	TodoTransferFormatCSV  = "csv"
+	TodoTransferFormatICal = "ics"
+)
+
+// 形式が有効な値かどうか
+func IsValidFormat(format string) bool {
+	switch format {
+	case TodoTransferFormatJSON, TodoTransferFormatCSV, TodoTransferFormatICal:
+		return true
+	}
+	return false
+}
+
+// インポートの上限
+const (
+	MaxImportRows      = 1000            // 1回でインポートできるTodoの最大件数
+	MaxImportDataBytes = 5 * 1024 * 1024 // インポートするデータの最大サイズ
+)
+
+// CSVの列(ヘッダー行の列名、エクスポートはこの順に出力する)
+var TodoRecordCSVColumns = []string{
+	"id", "description", "completed", "priority", "due_at", "remind_at", "list_id", "parent_id", "created_at", "updated_at",
+}
+
+// エクスポート・インポートするTodoの1件
+// IDと親のIDはファイル内の親子関係を表すためのもので、インポート時は新しいIDを採番する。
+// 作成日時・更新日時はエクスポートのみで、インポート時は無視する。
+type TodoRecord struct {
+	ID          string     `json:"id,omitempty"`         // TodoのID
+	Description string     `json:"description"`          // タスクの説明
+	Completed   bool       `json:"completed"`            // タスクが完了しているかどうか
+	Priority    string     `json:"priority"`             // 優先度(low / medium / high / urgent、空の場合はmedium)
+	DueAt       *time.Time `json:"due_at"`               // 期限
+	RemindAt    *time.Time `json:"remind_at"`            // リマインド日時
+	ListId      *string    `json:"list_id"`              // TodoリストID(インボックスの場合はnil)
+	ParentId    *string    `json:"parent_id"`            // 親TodoのID(ファイル内のID、または自分の既存のTodoのID)
+	CreatedAt   *time.Time `json:"created_at,omitempty"` // タイムスタンプ
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"` // タイムスタンプ
+}
+
+// TodoからTodoRecordを作成
+func NewTodoRecord(todo domain_todo.Todo) TodoRecord {
+	createdAt := todo.CreatedAt
+	updatedAt := todo.UpdatedAt
+	return TodoRecord{
+		ID:          todo.ID,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		Priority:    todo.Priority,
+		DueAt:       todo.DueAt,
+		RemindAt:    todo.RemindAt,
+		ListId:      todo.ListId,
+		ParentId:    todo.ParentId,
+		CreatedAt:   &createdAt,
+		UpdatedAt:   &updatedAt,
+	}
+}
+
+// インポートの1行の読み込み結果
+type TodoImportRow struct {
+	Row    int               // 行番号(1始まり、CSVはヘッダー行を除く)
+	Record TodoRecord        // 読み込んだTodo
+	Errors []TodoImportError // 読み込み時のエラー
+}
+
+// インポートの1行のエラー
+type TodoImportError struct {
+	Row     int    `json:"row"`     // 行番号(1始まり、CSVはヘッダー行を除く)
+	Field   string `json:"field"`   // エラーの項目(行全体の場合は空)
+	Message string `json:"message"` // エラーの内容
+}
+
+// インポートの結果
+// エラーが1件でもある場合は何もインポートしない。
+type TodoImportReport struct {
+	DryRun   bool               // 検証のみ行ったかどうか
+	Total    int                // 読み込んだ行数
+	Imported int                // インポートした件数(検証のみ、またはエラーがある場合は0)
+	Errors   []TodoImportError  // 行ごとのエラー
+	Todos    []domain_todo.Todo // インポートしたTodo(ファイルの順)
+}
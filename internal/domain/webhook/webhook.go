@@ -0,0 +1,240 @@
+package domain_webhook
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Webhookのイベントの種類(Todoの監査ログの操作から求める、TodoEventsFromAuditLogを参照)
+const (
+	WebhookEventTodoCreated   = "todo.created"
+	WebhookEventTodoUpdated   = "todo.updated"
+	WebhookEventTodoCompleted = "todo.completed" // 未完了から完了に変わった場合(todo.updatedも配信する)
+	WebhookEventTodoDeleted   = "todo.deleted"
+	WebhookEventTodoRestored  = "todo.restored"
+	WebhookEventTodoPurged    = "todo.purged"
+)
+
+// イベントの種類が有効な値かどうか
+func IsValidEventType(eventType string) bool {
+	switch eventType {
+	case WebhookEventTodoCreated, WebhookEventTodoUpdated, WebhookEventTodoCompleted,
+		WebhookEventTodoDeleted, WebhookEventTodoRestored, WebhookEventTodoPurged:
+		return true
+	}
+	return false
+}
+
+// 配信の状態
+const (
+	WebhookDeliveryStatusPending   = "pending"   // 送信待ち(再送待ちを含む)
+	WebhookDeliveryStatusSucceeded = "succeeded" // 2xxの応答を受け取った
+	WebhookDeliveryStatusFailed    = "failed"    // 最大回数まで送信して失敗した
+)
+
+// 配信の状態が有効な値かどうか
+func IsValidDeliveryStatus(status string) bool {
+	switch status {
+	case WebhookDeliveryStatusPending, WebhookDeliveryStatusSucceeded, WebhookDeliveryStatusFailed:
+		return true
+	}
+	return false
+}
+
+// 1ユーザーあたりのWebhookの最大数
+const MaxWebhooksPerUser = 10
+
+// URLの最大長
+const MaxWebhookURLLength = 2048
+
+// シークレットの最小・最大長
+const (
+	MinWebhookSecretLength = 16
+	MaxWebhookSecretLength = 256
+)
+
+// 送信履歴に記録する応答の本文の最大バイト数
+const MaxWebhookResponseBodyBytes = 1024
+
+// 送信時のヘッダー
+const (
+	WebhookHeaderId        = "X-Webhook-Id"        // 配信ID
+	WebhookHeaderEvent     = "X-Webhook-Event"     // イベントの種類
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp" // 送信日時(UNIX時間、秒)
+	WebhookHeaderSignature = "X-Webhook-Signature" // 署名(sha256=HMAC-SHA256の16進数)
+)
+
+// 署名の接頭辞
+const webhookSignaturePrefix = "sha256="
+
+// 所有者のユーザーIDの形式(UUID)
+var ownerIdPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Webhookの購読情報
+type Webhook struct {
+	ID         string    `json:"id"          db:"id"`          // UUID型
+	UserId     string    `json:"user_id"     db:"user_id"`     // 所有者のユーザーID
+	URL        string    `json:"url"         db:"url"`         // 送信先のURL
+	EventTypes []string  `json:"event_types" db:"event_types"` // 購読するイベントの種類
+	Secret     string    `json:"-"           db:"secret"`      // 署名のシークレット(レスポンスには含めない)
+	Active     bool      `json:"active"      db:"active"`      // 有効かどうか(無効の間は配信を登録・送信しない)
+	CreatedAt  time.Time `json:"created_at"  db:"created_at"`  // タイムスタンプ
+	UpdatedAt  time.Time `json:"updated_at"  db:"updated_at"`  // タイムスタンプ
+}
+
+// Webhookの更新内容(nilの項目は変更しない)
+type WebhookUpdate struct {
+	URL        *string
+	EventTypes []string
+	Secret     *string
+	Active     *bool
+}
+
+// Webhookの配信情報
+type WebhookDelivery struct {
+	ID               string          `json:"id"                 db:"id"`                 // UUID型
+	WebhookId        string          `json:"webhook_id"         db:"webhook_id"`         // WebhookのID
+	EventType        string          `json:"event_type"         db:"event_type"`         // イベントの種類
+	Payload          json.RawMessage `json:"payload"            db:"payload"`            // 送信する本文
+	Status           string          `json:"status"             db:"status"`             // 配信の状態
+	Attempts         int             `json:"attempts"           db:"attempts"`           // 送信した回数
+	NextAttemptAt    *time.Time      `json:"next_attempt_at"    db:"next_attempt_at"`    // 次に送信する日時(送信待ちの場合のみ)
+	LastResponseCode *int            `json:"last_response_code" db:"last_response_code"` // 最後の応答のステータスコード
+	LastError        *string         `json:"last_error"         db:"last_error"`         // 最後の送信のエラー
+	RedeliveryOf     *string         `json:"redelivery_of"      db:"redelivery_of"`      // 手動で再送した元の配信ID
+	CreatedAt        time.Time       `json:"created_at"         db:"created_at"`         // タイムスタンプ
+	UpdatedAt        time.Time       `json:"updated_at"         db:"updated_at"`         // タイムスタンプ
+	CompletedAt      *time.Time      `json:"completed_at"       db:"completed_at"`       // 成功・失敗が確定した日時
+}
+
+// Webhookの送信履歴
+type WebhookDeliveryAttempt struct {
+	ID           string    `json:"id"            db:"id"`            // UUID型
+	DeliveryId   string    `json:"delivery_id"   db:"delivery_id"`   // 配信ID
+	Attempt      int       `json:"attempt"       db:"attempt"`       // 何回目の送信か(1始まり)
+	ResponseCode *int      `json:"response_code" db:"response_code"` // 応答のステータスコード(応答が無い場合はnil)
+	ResponseBody string    `json:"response_body" db:"response_body"` // 応答の本文(先頭のみ)
+	Error        string    `json:"error"         db:"error"`         // 送信のエラー
+	DurationMs   int       `json:"duration_ms"   db:"duration_ms"`   // 応答までの時間(ミリ秒)
+	CreatedAt    time.Time `json:"created_at"    db:"created_at"`    // タイムスタンプ
+}
+
+// Todoのイベントの配信の本文
+type TodoEventPayload struct {
+	ID         string          `json:"id"`          // 監査ログのID(手動の再送でも変わらない)
+	Event      string          `json:"event"`       // イベントの種類
+	OccurredAt time.Time       `json:"occurred_at"` // 操作日時
+	ActorId    string          `json:"actor_id"`    // 操作したユーザーID
+	Todo       json.RawMessage `json:"todo"`        // 変更後のTodo(完全削除の場合は削除前)
+	Previous   json.RawMessage `json:"previous"`    // 変更前のTodo(作成の場合はnull)
+}
+
+// 送信対象として確保した配信(送信先とシークレットを含む)
+type WebhookDispatch struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// 送信する内容
+type WebhookRequest struct {
+	URL        string
+	Secret     string
+	DeliveryId string
+	EventType  string
+	Payload    []byte
+	Timestamp  time.Time
+}
+
+// 送信の結果
+type WebhookResponse struct {
+	StatusCode int           // 応答のステータスコード(応答が無い場合は0)
+	Body       string        // 応答の本文(先頭のみ)
+	Duration   time.Duration // 応答までの時間
+}
+
+// 応答が成功(2xx)かどうか
+func (r WebhookResponse) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// 本文に署名する(送信日時と本文を"."で連結したもののHMAC-SHA256)
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// 署名を検証する(受信側での検証用)
+func VerifyWebhookSignature(secret string, timestamp int64, payload []byte, signature string) bool {
+	expected := SignWebhookPayload(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// Todoの監査ログから、所有者のユーザーIDと配信するイベントの本文を求める
+// 未完了から完了に変わった更新は、todo.updatedに加えてtodo.completedも配信する。所有者が分からない場合は空を返す。
+func TodoEventsFromAuditLog(log domain_audit.AuditLog) (string, []TodoEventPayload) {
+	var before, after struct {
+		UserId    string `json:"user_id"`
+		Completed bool   `json:"completed"`
+	}
+	if len(log.Before) > 0 {
+		if err := json.Unmarshal(log.Before, &before); err != nil {
+			return "", nil
+		}
+	}
+	if len(log.After) > 0 {
+		if err := json.Unmarshal(log.After, &after); err != nil {
+			return "", nil
+		}
+	}
+	ownerId := after.UserId
+	if ownerId == "" {
+		ownerId = before.UserId
+	}
+	if !ownerIdPattern.MatchString(ownerId) {
+		return "", nil
+	}
+
+	var events []string
+	switch log.Action {
+	case domain_audit.AuditActionCreate:
+		events = []string{WebhookEventTodoCreated}
+	case domain_audit.AuditActionUpdate:
+		events = []string{WebhookEventTodoUpdated}
+		if !before.Completed && after.Completed {
+			events = append(events, WebhookEventTodoCompleted)
+		}
+	case domain_audit.AuditActionDelete:
+		events = []string{WebhookEventTodoDeleted}
+	case domain_audit.AuditActionRestore:
+		events = []string{WebhookEventTodoRestored}
+	case domain_audit.AuditActionPurge:
+		events = []string{WebhookEventTodoPurged}
+	}
+
+	todo := log.After
+	if len(todo) == 0 {
+		todo = log.Before
+	}
+	payloads := make([]TodoEventPayload, 0, len(events))
+	for _, event := range events {
+		payloads = append(payloads, TodoEventPayload{
+			ID:         log.ID,
+			Event:      event,
+			OccurredAt: log.CreatedAt,
+			ActorId:    log.ActorId,
+			Todo:       todo,
+			Previous:   log.Before,
+		})
+	}
+	return ownerId, payloads
+}
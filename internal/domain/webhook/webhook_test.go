@@ -0,0 +1,134 @@
+package domain_webhook
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	payload := []byte(`{"id":"1"}`)
+	want := "sha256=d5f5834972cbc6cf5590800c46ccaa0cd6c16f19c0c73dbdf9b4c56390cbc2a3"
+	if got := SignWebhookPayload("0123456789abcdef", 1700000000, payload); got != want {
+		t.Errorf("SignWebhookPayload() = %q, want %q", got, want)
+	}
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "0123456789abcdef"
+	payload := []byte(`{"id":"1"}`)
+	signature := SignWebhookPayload(secret, 1700000000, payload)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		payload   []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, timestamp: 1700000000, payload: payload, signature: signature, want: true},
+		{name: "another secret", secret: "fedcba9876543210", timestamp: 1700000000, payload: payload, signature: signature, want: false},
+		{name: "another timestamp", secret: secret, timestamp: 1700000001, payload: payload, signature: signature, want: false},
+		{name: "tampered payload", secret: secret, timestamp: 1700000000, payload: []byte(`{"id":"2"}`), signature: signature, want: false},
+		{name: "without prefix", secret: secret, timestamp: 1700000000, payload: payload, signature: signature[len(webhookSignaturePrefix):], want: false},
+		{name: "empty", secret: secret, timestamp: 1700000000, payload: payload, signature: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyWebhookSignature(tt.secret, tt.timestamp, tt.payload, tt.signature); got != tt.want {
+				t.Errorf("VerifyWebhookSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoEventsFromAuditLog(t *testing.T) {
+	const owner = "0b9f4c1e-3d2a-4c5b-8e7f-1a2b3c4d5e6f"
+	open := json.RawMessage(`{"id":"t1","user_id":"` + owner + `","completed":false}`)
+	done := json.RawMessage(`{"id":"t1","user_id":"` + owner + `","completed":true}`)
+
+	tests := []struct {
+		name   string
+		action string
+		before json.RawMessage
+		after  json.RawMessage
+		owner  string
+		events []string
+	}{
+		{name: "create", action: domain_audit.AuditActionCreate, after: open, owner: owner, events: []string{WebhookEventTodoCreated}},
+		{name: "update", action: domain_audit.AuditActionUpdate, before: open, after: open, owner: owner, events: []string{WebhookEventTodoUpdated}},
+		{name: "complete", action: domain_audit.AuditActionUpdate, before: open, after: done, owner: owner, events: []string{WebhookEventTodoUpdated, WebhookEventTodoCompleted}},
+		{name: "reopen", action: domain_audit.AuditActionUpdate, before: done, after: open, owner: owner, events: []string{WebhookEventTodoUpdated}},
+		{name: "update completed", action: domain_audit.AuditActionUpdate, before: done, after: done, owner: owner, events: []string{WebhookEventTodoUpdated}},
+		{name: "delete", action: domain_audit.AuditActionDelete, before: open, after: open, owner: owner, events: []string{WebhookEventTodoDeleted}},
+		{name: "restore", action: domain_audit.AuditActionRestore, after: open, owner: owner, events: []string{WebhookEventTodoRestored}},
+		{name: "purge", action: domain_audit.AuditActionPurge, before: open, owner: owner, events: []string{WebhookEventTodoPurged}},
+		{name: "unknown action", action: "archive", before: open, after: open, owner: owner, events: nil},
+		{name: "no owner", action: domain_audit.AuditActionCreate, after: json.RawMessage(`{"id":"t1"}`), events: nil},
+		{name: "invalid owner", action: domain_audit.AuditActionCreate, after: json.RawMessage(`{"user_id":"system"}`), events: nil},
+		{name: "invalid json", action: domain_audit.AuditActionCreate, after: json.RawMessage(`{`), events: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ownerId, payloads := TodoEventsFromAuditLog(domain_audit.AuditLog{
+				ID:         "log-1",
+				ActorId:    "actor-1",
+				Action:     tt.action,
+				EntityType: domain_audit.AuditEntityTodo,
+				EntityId:   "t1",
+				Before:     tt.before,
+				After:      tt.after,
+			})
+			if len(tt.events) > 0 && ownerId != tt.owner {
+				t.Errorf("owner = %q, want %q", ownerId, tt.owner)
+			}
+			if len(payloads) != len(tt.events) {
+				t.Fatalf("got %d events, want %d", len(payloads), len(tt.events))
+			}
+			for i, payload := range payloads {
+				if payload.Event != tt.events[i] {
+					t.Errorf("events[%d] = %s, want %s", i, payload.Event, tt.events[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTodoEventsFromAuditLogPayload(t *testing.T) {
+	const owner = "0b9f4c1e-3d2a-4c5b-8e7f-1a2b3c4d5e6f"
+	before := json.RawMessage(`{"id":"t1","user_id":"` + owner + `"}`)
+	occurredAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// 完全削除の場合は削除前のTodoを本文に含める
+	_, payloads := TodoEventsFromAuditLog(domain_audit.AuditLog{
+		ID:        "log-1",
+		ActorId:   "actor-1",
+		Action:    domain_audit.AuditActionPurge,
+		Before:    before,
+		CreatedAt: occurredAt,
+	})
+	if len(payloads) != 1 {
+		t.Fatalf("got %d events, want 1", len(payloads))
+	}
+	data, err := json.Marshal(payloads[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"id":"log-1","event":"todo.purged","occurred_at":"2026-01-02T03:04:05Z","actor_id":"actor-1","todo":` + string(before) + `,"previous":` + string(before) + `}`
+	if string(data) != want {
+		t.Errorf("payload = %s, want %s", data, want)
+	}
+
+	// 作成の場合は変更前をnullにする
+	_, payloads = TodoEventsFromAuditLog(domain_audit.AuditLog{ID: "log-2", Action: domain_audit.AuditActionCreate, After: before})
+	data, _ = json.Marshal(payloads[0])
+	var decoded map[string]json.RawMessage
+	json.Unmarshal(data, &decoded)
+	if string(decoded["previous"]) != "null" {
+		t.Errorf("previous = %s, want null", decoded["previous"])
+	}
+}
@@ -0,0 +1,199 @@
+package infrastructure_attachment
+
+import (
+	domain_attachment "backend/internal/domain/attachment"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_attachment "backend/internal/repository/attachment"
+)
+
+// 添付ファイルリポジトリ(Impl)
+type AttachmentRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+}
+
+// 添付ファイルリポジトリのインスタンス化
+func NewAttachmentRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) repository_attachment.IAttachmentRepository {
+	return &AttachmentRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+	}
+}
+
+// 特定の添付ファイルを取得
+func (r *AttachmentRepositoryImpl) GetAttachmentById(id string) (domain_attachment.Attachment, error) {
+	r.Logger.InfoLog.Println("GetAttachmentById called")
+
+	query := `
+		SELECT id, todo_id, user_id, file_name, content_type, size, storage_key, created_at
+		FROM todo_attachments
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する添付ファイルを取得
+	var attachment domain_attachment.Attachment
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&attachment.ID,
+			&attachment.TodoId,
+			&attachment.UserId,
+			&attachment.FileName,
+			&attachment.ContentType,
+			&attachment.Size,
+			&attachment.StorageKey,
+			&attachment.CreatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch attachment: %v", err)
+		return domain_attachment.Attachment{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched attachment: %v", attachment.ID)
+	return attachment, nil
+}
+
+// 複数のTodoの添付ファイルを取得
+func (r *AttachmentRepositoryImpl) GetAttachmentsByTodoIds(todoIds []string) ([]domain_attachment.Attachment, error) {
+	r.Logger.InfoLog.Println("GetAttachmentsByTodoIds called")
+
+	query := `
+		SELECT id, todo_id, user_id, file_name, content_type, size, storage_key, created_at
+		FROM todo_attachments
+		WHERE todo_id = ANY($1)
+		ORDER BY created_at
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する添付ファイルを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, todoIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch attachments: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// 添付ファイルのリストを作成
+	attachments := []domain_attachment.Attachment{}
+	for rows.Next() {
+		var attachment domain_attachment.Attachment
+		err = rows.Scan(
+			&attachment.ID,
+			&attachment.TodoId,
+			&attachment.UserId,
+			&attachment.FileName,
+			&attachment.ContentType,
+			&attachment.Size,
+			&attachment.StorageKey,
+			&attachment.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan attachment: %v", err)
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d attachments", len(attachments))
+	return attachments, nil
+}
+
+// 新しい添付ファイルを作成
+func (r *AttachmentRepositoryImpl) CreateAttachment(attachment domain_attachment.Attachment) (domain_attachment.Attachment, error) {
+	r.Logger.InfoLog.Println("CreateAttachment called")
+
+	query := `
+		INSERT INTO todo_attachments (todo_id, user_id, file_name, content_type, size, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, todo_id, user_id, file_name, content_type, size, storage_key, created_at
+	`
+
+	// トランザクション開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_attachment.Attachment{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// Supabaseからクエリを実行し、添付ファイルを作成
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query,
+		attachment.TodoId,
+		attachment.UserId,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+	).Scan(&attachment.ID,
+		&attachment.TodoId,
+		&attachment.UserId,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.StorageKey,
+		&attachment.CreatedAt,
+	)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create attachment: %v", err)
+		return domain_attachment.Attachment{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_attachment.Attachment{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Created attachment: %v", attachment.ID)
+	return attachment, nil
+}
+
+// 特定の添付ファイルを削除
+func (r *AttachmentRepositoryImpl) DeleteAttachment(id string) error {
+	r.Logger.InfoLog.Println("DeleteAttachment called")
+
+	query := `
+		DELETE FROM todo_attachments
+		WHERE id = $1
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// Supabaseからクエリを実行し、添付ファイルを削除
+	_, err = tx.Exec(r.SupabaseClient.Ctx, query, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete attachment: %v", err)
+		return err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Deleted attachment: %v", id)
+	return nil
+}
@@ -0,0 +1,197 @@
+package infrastructure_audit
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_audit "backend/internal/repository/audit"
+	"strconv"
+	"strings"
+)
+
+// 監査ログリポジトリ(Impl)
+type AuditLogRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+}
+
+// 監査ログリポジトリのインスタンス化
+func NewAuditLogRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) repository_audit.IAuditLogRepository {
+	return &AuditLogRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+	}
+}
+
+// 条件に一致する監査ログと総件数を取得
+func (r *AuditLogRepositoryImpl) GetAuditLogs(filter domain_audit.AuditLogFilter) ([]domain_audit.AuditLog, int, error) {
+	r.Logger.InfoLog.Println("GetAuditLogs called")
+
+	// 検索条件を組み立てる
+	conditions := []string{}
+	args := []interface{}{}
+	addCondition := func(column string, operator string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, column+" "+operator+" $"+strconv.Itoa(len(args)))
+	}
+	if filter.ActorId != "" {
+		addCondition("actor_id", "=", filter.ActorId)
+	}
+	if filter.Action != "" {
+		addCondition("action", "=", filter.Action)
+	}
+	if filter.EntityType != "" {
+		addCondition("entity_type", "=", filter.EntityType)
+	}
+	if filter.EntityId != "" {
+		addCondition("entity_id", "=", filter.EntityId)
+	}
+	if filter.Since != nil {
+		addCondition("created_at", ">=", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCondition("created_at", "<", *filter.Until)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// 総件数を取得
+	countQuery := `
+		SELECT COUNT(*)
+		FROM audit_logs
+		` + where
+
+	var total int
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, countQuery, args...).Scan(&total)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to count audit logs: %v", err)
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, actor_id, action, entity_type, entity_id, before, after, request_id, ip_address, created_at
+		FROM audit_logs
+		` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
+
+	// Supabaseからクエリを実行し、条件に一致する監査ログを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch audit logs: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	// 監査ログのリストを作成
+	logs := []domain_audit.AuditLog{}
+	for rows.Next() {
+		var log domain_audit.AuditLog
+		var before, after []byte
+		err = rows.Scan(
+			&log.ID,
+			&log.ActorId,
+			&log.Action,
+			&log.EntityType,
+			&log.EntityId,
+			&before,
+			&after,
+			&log.RequestId,
+			&log.IPAddress,
+			&log.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan audit log: %v", err)
+			return nil, 0, err
+		}
+		log.Before = before
+		log.After = after
+		logs = append(logs, log)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d audit logs (total %d)", len(logs), total)
+	return logs, total, nil
+}
+
+// 複数のエンティティの監査ログを新しい順にエンティティごとにlimit件ずつ、offset件目から取得し、エンティティごとの総件数とあわせて返す
+func (r *AuditLogRepositoryImpl) GetAuditLogsByEntityIds(entityType string, entityIds []string, limit int, offset int) ([]domain_audit.AuditLog, map[string]int, error) {
+	r.Logger.InfoLog.Println("GetAuditLogsByEntityIds called")
+
+	countQuery := `
+		SELECT entity_id, COUNT(*)
+		FROM audit_logs
+		WHERE entity_type = $1 AND entity_id = ANY($2)
+		GROUP BY entity_id
+	`
+	query := `
+		SELECT id, actor_id, action, entity_type, entity_id, before, after, request_id, ip_address, created_at
+		FROM (
+		    SELECT id, actor_id, action, entity_type, entity_id, before, after, request_id, ip_address, created_at,
+		           ROW_NUMBER() OVER (PARTITION BY entity_id ORDER BY created_at DESC, id DESC) AS rn
+		    FROM audit_logs
+		    WHERE entity_type = $1 AND entity_id = ANY($2)
+		) a
+		WHERE rn > $3 AND rn <= $3 + $4
+		ORDER BY entity_id, rn
+	`
+
+	// エンティティごとの総件数を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, countQuery, entityType, entityIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to count audit logs: %v", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	totals := map[string]int{}
+	for rows.Next() {
+		var entityId string
+		var total int
+		err = rows.Scan(&entityId, &total)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan audit log count: %v", err)
+			return nil, nil, err
+		}
+		totals[entityId] = total
+	}
+	rows.Close()
+
+	// Supabaseからクエリを実行し、条件に一致する監査ログを取得
+	rows, err = r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, entityType, entityIds, offset, limit)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch audit logs: %v", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	// 監査ログのリストを作成
+	logs := []domain_audit.AuditLog{}
+	for rows.Next() {
+		var log domain_audit.AuditLog
+		var before, after []byte
+		err = rows.Scan(
+			&log.ID,
+			&log.ActorId,
+			&log.Action,
+			&log.EntityType,
+			&log.EntityId,
+			&before,
+			&after,
+			&log.RequestId,
+			&log.IPAddress,
+			&log.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan audit log: %v", err)
+			return nil, nil, err
+		}
+		log.Before = before
+		log.After = after
+		logs = append(logs, log)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d audit logs of %d entities", len(logs), len(totals))
+	return logs, totals, nil
+}
@@ -0,0 +1,73 @@
+package infrastructure_audit
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	infrastructure_webhook "backend/internal/infrastructure/webhook"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// 監査ログを書き込む
+// 対象エンティティの変更と同じトランザクションで呼び出すこと。before/afterはJSONに変換して保存する(nilの場合はNULL)。
+// Todoの監査ログの場合は、同じトランザクションで所有者のWebhookの配信も登録する。
+func InsertAuditLog(ctx context.Context, tx pgx.Tx, actor domain_audit.AuditActor, action string, entityType string, entityId string, before interface{}, after interface{}) error {
+	query := `
+		INSERT INTO audit_logs (actor_id, action, entity_type, entity_id, before, after, request_id, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	beforeJSON, err := marshalAuditValue(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalAuditValue(after)
+	if err != nil {
+		return err
+	}
+
+	var id string
+	var createdAt time.Time
+	err = tx.QueryRow(ctx, query, actor.UserId, action, entityType, entityId, beforeJSON, afterJSON, actor.RequestId, actor.IPAddress).Scan(&id, &createdAt)
+	if err != nil {
+		return err
+	}
+
+	if entityType != domain_audit.AuditEntityTodo {
+		return nil
+	}
+	return infrastructure_webhook.InsertTodoWebhookDeliveries(ctx, tx, domain_audit.AuditLog{
+		ID:         id,
+		ActorId:    actor.UserId,
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   entityId,
+		Before:     rawAuditValue(beforeJSON),
+		After:      rawAuditValue(afterJSON),
+		CreatedAt:  createdAt,
+	})
+}
+
+// 監査ログの値をJSONに変換(nilの場合はNULL)
+func marshalAuditValue(v interface{}) (*string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	return &s, nil
+}
+
+// JSONに変換した監査ログの値を取り出す(NULLの場合はnil)
+func rawAuditValue(s *string) json.RawMessage {
+	if s == nil {
+		return nil
+	}
+	return json.RawMessage(*s)
+}
@@ -0,0 +1,207 @@
+package infrastructure_auth
+
+import (
+	domain_auth "backend/internal/domain/auth"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_auth "backend/internal/repository/auth"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ログイン試行リポジトリ(Impl)
+type LoginAttemptRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+}
+
+// ログイン試行リポジトリのインスタンス化
+func NewLoginAttemptRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) repository_auth.ILoginAttemptRepository {
+	return &LoginAttemptRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+	}
+}
+
+// ログイン試行を記録
+func (r *LoginAttemptRepositoryImpl) RecordAttempt(attempt domain_auth.LoginAttempt) error {
+	r.Logger.InfoLog.Println("RecordAttempt called")
+
+	query := `
+		INSERT INTO login_attempts (email, ip_address, result)
+		VALUES ($1, $2, $3)
+	`
+
+	// Supabaseからクエリを実行し、ログイン試行を記録
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, attempt.Email, attempt.IPAddress, attempt.Result)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to record login attempt: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Recorded login attempt: %s", attempt.Result)
+	return nil
+}
+
+// ログイン試行を予約(試行回数の加算とバックオフの判定を1文で行う)
+// 最後の試行からwindow以上経過している場合は回数をリセットする。
+func (r *LoginAttemptRepositoryImpl) ReserveAttempt(scope string, subject string, window time.Duration, backoff domain_auth.LoginBackoff) (domain_auth.LoginAttemptReservation, error) {
+	r.Logger.InfoLog.Println("ReserveAttempt called")
+
+	// バックオフ中(前回の試行から待ち時間が経過していない)の場合は更新せず、行を返さない
+	query := `
+		INSERT INTO login_counters AS c (scope, subject, attempts, last_attempt_at)
+		VALUES ($1, $2, 1, now())
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			attempts = CASE WHEN c.last_attempt_at <= now() - make_interval(secs => $3) THEN 1 ELSE c.attempts + 1 END,
+			last_attempt_at = now()
+		WHERE c.last_attempt_at <= now() - make_interval(secs => $3)
+		   OR $5 <= 0
+		   OR c.attempts < $4
+		   OR c.last_attempt_at + make_interval(secs => LEAST($5 * power(2, c.attempts - $4), $6)) <= now()
+		RETURNING c.attempts
+	`
+
+	// 最大待ち時間の指定が無い場合は1日とする
+	backoffMax := backoff.Max
+	if backoffMax <= 0 {
+		backoffMax = 24 * time.Hour
+	}
+
+	// Supabaseからクエリを実行し、ログイン試行を予約
+	var reservation domain_auth.LoginAttemptReservation
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query,
+		scope,
+		subject,
+		window.Seconds(),
+		backoff.Threshold,
+		backoff.Base.Seconds(),
+		backoffMax.Seconds(),
+	).Scan(&reservation.Attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Login attempt throttled: %s", scope)
+		return domain_auth.LoginAttemptReservation{Throttled: true}, nil
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to reserve login attempt: %v", err)
+		return domain_auth.LoginAttemptReservation{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Reserved login attempt: %s (%d attempts)", scope, reservation.Attempts)
+	return reservation, nil
+}
+
+// 予約したログイン試行を取り消す(試行回数を1減らす)
+func (r *LoginAttemptRepositoryImpl) ReleaseAttempt(scope string, subject string) error {
+	r.Logger.InfoLog.Println("ReleaseAttempt called")
+
+	query := `
+		UPDATE login_counters
+		SET attempts = GREATEST(attempts - 1, 0)
+		WHERE scope = $1 AND subject = $2
+	`
+
+	// Supabaseからクエリを実行し、試行回数を減らす
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, scope, subject)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to release login attempt: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Released login attempt: %s", scope)
+	return nil
+}
+
+// ログイン試行の回数をリセット
+func (r *LoginAttemptRepositoryImpl) ResetAttempts(scope string, subject string) error {
+	r.Logger.InfoLog.Println("ResetAttempts called")
+
+	query := `
+		DELETE FROM login_counters
+		WHERE scope = $1 AND subject = $2
+	`
+
+	// Supabaseからクエリを実行し、試行回数を削除
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, scope, subject)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to reset login attempts: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Reset login attempts: %s", scope)
+	return nil
+}
+
+// ロック情報を取得(ロックされていない場合はnil)
+func (r *LoginAttemptRepositoryImpl) GetLockout(scope string, subject string) (*domain_auth.LoginLockout, error) {
+	r.Logger.InfoLog.Println("GetLockout called")
+
+	query := `
+		SELECT scope, subject, locked_until, created_at
+		FROM login_lockouts
+		WHERE scope = $1 AND subject = $2 AND locked_until > now()
+	`
+
+	// Supabaseからクエリを実行し、ロック情報を取得
+	var lockout domain_auth.LoginLockout
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, scope, subject).
+		Scan(&lockout.Scope,
+			&lockout.Subject,
+			&lockout.LockedUntil,
+			&lockout.CreatedAt,
+		)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch lockout: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched lockout: %s until %v", lockout.Scope, lockout.LockedUntil)
+	return &lockout, nil
+}
+
+// ロック
+func (r *LoginAttemptRepositoryImpl) Lock(scope string, subject string, lockedUntil time.Time) error {
+	r.Logger.InfoLog.Println("Lock called")
+
+	query := `
+		INSERT INTO login_lockouts (scope, subject, locked_until)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scope, subject)
+		DO UPDATE SET locked_until = EXCLUDED.locked_until, created_at = now()
+	`
+
+	// Supabaseからクエリを実行し、ロックを作成
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, scope, subject, lockedUntil)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to lock: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Locked %s until %v", scope, lockedUntil)
+	return nil
+}
+
+// ロック解除
+func (r *LoginAttemptRepositoryImpl) Unlock(scope string, subject string) error {
+	r.Logger.InfoLog.Println("Unlock called")
+
+	query := `
+		DELETE FROM login_lockouts
+		WHERE scope = $1 AND subject = $2
+	`
+
+	// Supabaseからクエリを実行し、ロックを削除
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, scope, subject)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to unlock: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Unlocked %s", scope)
+	return nil
+}
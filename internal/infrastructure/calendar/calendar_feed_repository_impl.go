@@ -0,0 +1,125 @@
+package infrastructure_calendar
+
+import (
+	domain_calendar "backend/internal/domain/calendar"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_calendar "backend/internal/repository/calendar"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// カレンダーの購読フィードリポジトリ(Impl)
+type CalendarFeedRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+}
+
+// カレンダーの購読フィードリポジトリのインスタンス化
+func NewCalendarFeedRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) repository_calendar.ICalendarFeedRepository {
+	return &CalendarFeedRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+	}
+}
+
+// 特定のユーザーのフィードを取得
+func (r *CalendarFeedRepositoryImpl) GetFeedByUserId(userId string) (domain_calendar.CalendarFeed, error) {
+	r.Logger.InfoLog.Println("GetFeedByUserId called")
+
+	query := `
+		SELECT user_id, created_at, last_accessed_at
+		FROM calendar_feeds
+		WHERE user_id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するフィードを取得
+	var feed domain_calendar.CalendarFeed
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, userId).
+		Scan(&feed.UserId, &feed.CreatedAt, &feed.LastAccessedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Calendar feed not found: %v", userId)
+		return domain_calendar.CalendarFeed{}, repository_calendar.ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch calendar feed: %v", err)
+		return domain_calendar.CalendarFeed{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched calendar feed: %v", feed.UserId)
+	return feed, nil
+}
+
+// 特定のユーザーのフィードのトークンを設定(既存のトークンは無効になる)
+func (r *CalendarFeedRepositoryImpl) UpsertFeed(userId string, tokenHash string) (domain_calendar.CalendarFeed, error) {
+	r.Logger.InfoLog.Println("UpsertFeed called")
+
+	query := `
+		INSERT INTO calendar_feeds (user_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, created_at = now(), last_accessed_at = NULL
+		RETURNING user_id, created_at, last_accessed_at
+	`
+
+	// Supabaseからクエリを実行し、フィードを作成・更新
+	var feed domain_calendar.CalendarFeed
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, userId, tokenHash).
+		Scan(&feed.UserId, &feed.CreatedAt, &feed.LastAccessedAt)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to upsert calendar feed: %v", err)
+		return domain_calendar.CalendarFeed{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Upserted calendar feed: %v", feed.UserId)
+	return feed, nil
+}
+
+// 特定のユーザーのフィードを削除し、削除したかどうかを返す
+func (r *CalendarFeedRepositoryImpl) DeleteFeed(userId string) (bool, error) {
+	r.Logger.InfoLog.Println("DeleteFeed called")
+
+	query := `
+		DELETE FROM calendar_feeds
+		WHERE user_id = $1
+	`
+
+	// Supabaseからクエリを実行し、フィードを削除
+	tag, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, userId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete calendar feed: %v", err)
+		return false, err
+	}
+
+	r.Logger.InfoLog.Printf("Deleted %d calendar feeds", tag.RowsAffected())
+	return tag.RowsAffected() > 0, nil
+}
+
+// トークンのハッシュに一致するフィードを取得し、最後に取得した日時を記録する
+func (r *CalendarFeedRepositoryImpl) TouchFeedByTokenHash(tokenHash string) (domain_calendar.CalendarFeed, error) {
+	r.Logger.InfoLog.Println("TouchFeedByTokenHash called")
+
+	query := `
+		UPDATE calendar_feeds
+		SET last_accessed_at = now()
+		WHERE token_hash = $1
+		RETURNING user_id, created_at, last_accessed_at
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するフィードを取得
+	var feed domain_calendar.CalendarFeed
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, tokenHash).
+		Scan(&feed.UserId, &feed.CreatedAt, &feed.LastAccessedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Println("Calendar feed not found for token")
+		return domain_calendar.CalendarFeed{}, repository_calendar.ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch calendar feed: %v", err)
+		return domain_calendar.CalendarFeed{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched calendar feed: %v", feed.UserId)
+	return feed, nil
+}
@@ -0,0 +1,207 @@
+package infrastructure_comment
+
+import (
+	domain_comment "backend/internal/domain/comment"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_comment "backend/internal/repository/comment"
+)
+
+// コメントリポジトリ(Impl)
+type CommentRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+}
+
+// コメントリポジトリのインスタンス化
+func NewCommentRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) repository_comment.ICommentRepository {
+	return &CommentRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+	}
+}
+
+// 特定のコメントを取得
+func (r *CommentRepositoryImpl) GetCommentById(id string) (domain_comment.Comment, error) {
+	r.Logger.InfoLog.Println("GetCommentById called")
+
+	query := `
+		SELECT id, todo_id, author_id, body, created_at, edited_at
+		FROM todo_comments
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するコメントを取得
+	var comment domain_comment.Comment
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&comment.ID,
+			&comment.TodoId,
+			&comment.AuthorId,
+			&comment.Body,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch comment: %v", err)
+		return domain_comment.Comment{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched comment: %v", comment.ID)
+	return comment, nil
+}
+
+// 複数のTodoのコメントを投稿順にTodoごとにlimit件ずつ、offset件目から取得
+func (r *CommentRepositoryImpl) GetCommentPagesByTodoIds(todoIds []string, limit int, offset int) ([]domain_comment.CommentPage, error) {
+	r.Logger.InfoLog.Println("GetCommentPagesByTodoIds called")
+
+	countQuery := `
+		SELECT todo_id, COUNT(*)
+		FROM todo_comments
+		WHERE todo_id = ANY($1)
+		GROUP BY todo_id
+	`
+	query := `
+		SELECT id, todo_id, author_id, body, created_at, edited_at
+		FROM (
+		    SELECT id, todo_id, author_id, body, created_at, edited_at,
+		           ROW_NUMBER() OVER (PARTITION BY todo_id ORDER BY created_at, id) AS rn
+		    FROM todo_comments
+		    WHERE todo_id = ANY($1)
+		) c
+		WHERE rn > $2 AND rn <= $2 + $3
+		ORDER BY todo_id, rn
+	`
+
+	// Todoごとの総数を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, countQuery, todoIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to count comments: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	pages := map[string]*domain_comment.CommentPage{}
+	for rows.Next() {
+		page := domain_comment.CommentPage{Items: []domain_comment.Comment{}}
+		err = rows.Scan(&page.TodoId, &page.TotalCount)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan comment count: %v", err)
+			return nil, err
+		}
+		pages[page.TodoId] = &page
+	}
+	rows.Close()
+
+	// Supabaseからクエリを実行し、条件に一致するコメントを取得
+	rows, err = r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, todoIds, offset, limit)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch comments: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var comment domain_comment.Comment
+		err = rows.Scan(
+			&comment.ID,
+			&comment.TodoId,
+			&comment.AuthorId,
+			&comment.Body,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan comment: %v", err)
+			return nil, err
+		}
+		if page, ok := pages[comment.TodoId]; ok {
+			page.Items = append(page.Items, comment)
+		}
+	}
+
+	result := make([]domain_comment.CommentPage, 0, len(pages))
+	for _, page := range pages {
+		result = append(result, *page)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched comments of %d todos", len(result))
+	return result, nil
+}
+
+// 新しいコメントを作成
+func (r *CommentRepositoryImpl) CreateComment(comment domain_comment.Comment) (domain_comment.Comment, error) {
+	r.Logger.InfoLog.Println("CreateComment called")
+
+	query := `
+		INSERT INTO todo_comments (todo_id, author_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, todo_id, author_id, body, created_at, edited_at
+	`
+
+	// Supabaseからクエリを実行し、コメントを作成
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, comment.TodoId, comment.AuthorId, comment.Body).
+		Scan(&comment.ID,
+			&comment.TodoId,
+			&comment.AuthorId,
+			&comment.Body,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create comment: %v", err)
+		return domain_comment.Comment{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Created comment: %v", comment.ID)
+	return comment, nil
+}
+
+// コメントの本文を変更(編集日時を記録する)
+func (r *CommentRepositoryImpl) EditComment(id string, body string) (domain_comment.Comment, error) {
+	r.Logger.InfoLog.Println("EditComment called")
+
+	query := `
+		UPDATE todo_comments
+		SET body = $1, edited_at = now()
+		WHERE id = $2
+		RETURNING id, todo_id, author_id, body, created_at, edited_at
+	`
+
+	// Supabaseからクエリを実行し、コメントを変更
+	var comment domain_comment.Comment
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, body, id).
+		Scan(&comment.ID,
+			&comment.TodoId,
+			&comment.AuthorId,
+			&comment.Body,
+			&comment.CreatedAt,
+			&comment.EditedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to edit comment: %v", err)
+		return domain_comment.Comment{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Edited comment: %v", comment.ID)
+	return comment, nil
+}
+
+// 特定のコメントを削除
+func (r *CommentRepositoryImpl) DeleteComment(id string) error {
+	r.Logger.InfoLog.Println("DeleteComment called")
+
+	query := `
+		DELETE FROM todo_comments
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、コメントを削除
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete comment: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Deleted comment: %v", id)
+	return nil
+}
@@ -0,0 +1,30 @@
+package infrastructure_notification
+
+import (
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_notification "backend/internal/repository/notification"
+	"time"
+)
+
+// ログ出力によるリマインド通知(Impl)
+type LogReminderNotifier struct {
+	Logger *pkg_logger.AppLogger
+}
+
+// ログ出力によるリマインド通知のインスタンス化
+func NewLogReminderNotifier(l *pkg_logger.AppLogger) repository_notification.IReminderNotifier {
+	return &LogReminderNotifier{
+		Logger: l,
+	}
+}
+
+// リマインドのイベントを通知
+func (n *LogReminderNotifier) NotifyReminder(todo domain_todo.Todo) error {
+	dueAt := "-"
+	if todo.DueAt != nil {
+		dueAt = todo.DueAt.Format(time.RFC3339)
+	}
+	n.Logger.InfoLog.Printf("Reminder: todo=%s user=%s due_at=%s", todo.ID, todo.UserId, dueAt)
+	return nil
+}
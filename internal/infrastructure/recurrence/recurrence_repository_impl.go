@@ -0,0 +1,309 @@
+package infrastructure_recurrence
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_recurrence "backend/internal/domain/recurrence"
+	domain_todo "backend/internal/domain/todo"
+	infrastructure_todo "backend/internal/infrastructure/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_recurrence "backend/internal/repository/recurrence"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// 繰り返しリポジトリ(Impl)
+type RecurrenceRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+	TodoWriter     infrastructure_todo.TodoTxWriter
+}
+
+// 繰り返しリポジトリのインスタンス化
+func NewRecurrenceRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient, tw infrastructure_todo.TodoTxWriter) repository_recurrence.IRecurrenceRepository {
+	return &RecurrenceRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+		TodoWriter:     tw,
+	}
+}
+
+// 特定のTodoの繰り返し設定を取得
+func (r *RecurrenceRepositoryImpl) GetRecurrenceByTodoId(todoId string) (domain_recurrence.Recurrence, error) {
+	r.Logger.InfoLog.Println("GetRecurrenceByTodoId called")
+
+	query := `
+		SELECT todo_id, rule, timezone, start_at, exdates, created_at, updated_at
+		FROM todo_recurrences
+		WHERE todo_id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する繰り返し設定を取得
+	var recurrence domain_recurrence.Recurrence
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, todoId).
+		Scan(&recurrence.TodoId,
+			&recurrence.Rule,
+			&recurrence.Timezone,
+			&recurrence.StartAt,
+			&recurrence.ExDates,
+			&recurrence.CreatedAt,
+			&recurrence.UpdatedAt,
+		)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Recurrence not found: %v", todoId)
+		return domain_recurrence.Recurrence{}, repository_recurrence.ErrRecurrenceNotFound
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch recurrence: %v", err)
+		return domain_recurrence.Recurrence{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched recurrence: %v", recurrence.TodoId)
+	return recurrence, nil
+}
+
+// 複数のTodoの繰り返し設定を取得
+func (r *RecurrenceRepositoryImpl) GetRecurrencesByTodoIds(todoIds []string) ([]domain_recurrence.Recurrence, error) {
+	r.Logger.InfoLog.Println("GetRecurrencesByTodoIds called")
+
+	query := `
+		SELECT todo_id, rule, timezone, start_at, exdates, created_at, updated_at
+		FROM todo_recurrences
+		WHERE todo_id = ANY($1)
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する繰り返し設定を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, todoIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch recurrences: %v", err)
+		return nil, err
+	}
+
+	recurrences, err := r.scanRecurrences(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d recurrences", len(recurrences))
+	return recurrences, nil
+}
+
+// 特定のユーザーの未完了のTodoの繰り返し設定を取得
+func (r *RecurrenceRepositoryImpl) GetActiveRecurrencesByUserId(userId string) ([]domain_recurrence.Recurrence, error) {
+	r.Logger.InfoLog.Println("GetActiveRecurrencesByUserId called")
+
+	query := `
+		SELECT rc.todo_id, rc.rule, rc.timezone, rc.start_at, rc.exdates, rc.created_at, rc.updated_at
+		FROM todo_recurrences rc
+		JOIN todos t ON t.id = rc.todo_id
+		WHERE t.user_id = $1 AND t.deleted_at IS NULL AND NOT t.completed
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する繰り返し設定を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch recurrences: %v", err)
+		return nil, err
+	}
+
+	recurrences, err := r.scanRecurrences(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d recurrences", len(recurrences))
+	return recurrences, nil
+}
+
+// 繰り返し設定を登録(既にある場合は置き換え、除外日はリセットする)
+func (r *RecurrenceRepositoryImpl) UpsertRecurrence(recurrence domain_recurrence.Recurrence) (domain_recurrence.Recurrence, error) {
+	r.Logger.InfoLog.Println("UpsertRecurrence called")
+
+	query := `
+		INSERT INTO todo_recurrences (todo_id, rule, timezone, start_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (todo_id) DO UPDATE
+		SET rule = EXCLUDED.rule, timezone = EXCLUDED.timezone, start_at = EXCLUDED.start_at, exdates = '{}', updated_at = now()
+		RETURNING todo_id, rule, timezone, start_at, exdates, created_at, updated_at
+	`
+
+	// Supabaseからクエリを実行し、繰り返し設定を登録
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, recurrence.TodoId, recurrence.Rule, recurrence.Timezone, recurrence.StartAt).
+		Scan(&recurrence.TodoId,
+			&recurrence.Rule,
+			&recurrence.Timezone,
+			&recurrence.StartAt,
+			&recurrence.ExDates,
+			&recurrence.CreatedAt,
+			&recurrence.UpdatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to upsert recurrence: %v", err)
+		return domain_recurrence.Recurrence{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Upserted recurrence: %v", recurrence.TodoId)
+	return recurrence, nil
+}
+
+// 繰り返し設定を削除
+func (r *RecurrenceRepositoryImpl) DeleteRecurrence(todoId string) error {
+	r.Logger.InfoLog.Println("DeleteRecurrence called")
+
+	query := `
+		DELETE FROM todo_recurrences
+		WHERE todo_id = $1
+	`
+
+	// Supabaseからクエリを実行し、繰り返し設定を削除
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, todoId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete recurrence: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Deleted recurrence: %v", todoId)
+	return nil
+}
+
+// 除外日を追加
+func (r *RecurrenceRepositoryImpl) AddExDate(todoId string, at time.Time) (domain_recurrence.Recurrence, error) {
+	r.Logger.InfoLog.Println("AddExDate called")
+
+	query := `
+		UPDATE todo_recurrences
+		SET exdates = CASE WHEN $2 = ANY(exdates) THEN exdates ELSE array_append(exdates, $2) END, updated_at = now()
+		WHERE todo_id = $1
+		RETURNING todo_id, rule, timezone, start_at, exdates, created_at, updated_at
+	`
+
+	// Supabaseからクエリを実行し、除外日を追加
+	var recurrence domain_recurrence.Recurrence
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, todoId, at).
+		Scan(&recurrence.TodoId,
+			&recurrence.Rule,
+			&recurrence.Timezone,
+			&recurrence.StartAt,
+			&recurrence.ExDates,
+			&recurrence.CreatedAt,
+			&recurrence.UpdatedAt,
+		)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Recurrence not found: %v", todoId)
+		return domain_recurrence.Recurrence{}, repository_recurrence.ErrRecurrenceNotFound
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to add exdate: %v", err)
+		return domain_recurrence.Recurrence{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Added exdate: %v", recurrence.TodoId)
+	return recurrence, nil
+}
+
+// 次の発生分のTodoを作成し、繰り返し設定とタグを引き継ぐ(監査ログを同一トランザクションで記録する)
+// 既に引き継ぎ済みの場合はErrRecurrenceNotFoundを返す
+func (r *RecurrenceRepositoryImpl) AdvanceRecurrence(todoId string, next domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("AdvanceRecurrence called")
+
+	lockQuery := `
+		SELECT todo_id
+		FROM todo_recurrences
+		WHERE todo_id = $1
+		FOR UPDATE
+	`
+	moveQuery := `
+		UPDATE todo_recurrences
+		SET todo_id = $1, updated_at = now()
+		WHERE todo_id = $2
+	`
+	tagQuery := `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		SELECT $1, tag_id FROM todo_tags WHERE todo_id = $2
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 繰り返し設定をロック(同時に完了した場合の二重作成を防ぐ)
+	var locked string
+	err = tx.QueryRow(r.SupabaseClient.Ctx, lockQuery, todoId).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Recurrence already advanced: %v", todoId)
+		err = repository_recurrence.ErrRecurrenceNotFound
+		return domain_todo.Todo{}, err
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to lock recurrence: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 次の発生分のTodoを作成(Todoの書き込みを経由し、監査ログも記録する)
+	next.Completed = false
+	todo, err := r.TodoWriter.CreateTodoInTx(tx, next, actor)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// 繰り返し設定とタグを引き継ぐ
+	_, err = tx.Exec(r.SupabaseClient.Ctx, moveQuery, todo.ID, todoId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to move recurrence: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	_, err = tx.Exec(r.SupabaseClient.Ctx, tagQuery, todo.ID, todoId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to copy tags: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Advanced recurrence: %v -> %v", todoId, todo.ID)
+	return todo, nil
+}
+
+// 繰り返し設定の行を読み込む(読み込み後に行を閉じる)
+func (r *RecurrenceRepositoryImpl) scanRecurrences(rows pgx.Rows) ([]domain_recurrence.Recurrence, error) {
+	defer rows.Close()
+
+	recurrences := []domain_recurrence.Recurrence{}
+	for rows.Next() {
+		var recurrence domain_recurrence.Recurrence
+		err := rows.Scan(
+			&recurrence.TodoId,
+			&recurrence.Rule,
+			&recurrence.Timezone,
+			&recurrence.StartAt,
+			&recurrence.ExDates,
+			&recurrence.CreatedAt,
+			&recurrence.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan recurrence: %v", err)
+			return nil, err
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+	return recurrences, rows.Err()
+}
@@ -0,0 +1,216 @@
+package infrastructure_search
+
+import (
+	domain_search "backend/internal/domain/search"
+	infrastructure_todo "backend/internal/infrastructure/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_search "backend/internal/repository/search"
+	"strings"
+)
+
+// 抜粋の生成オプション(ts_headline)
+// 一致した箇所は私用領域の文字で囲み、HTMLエスケープ後にタグに置き換える。
+const headlineOptions = `StartSel="` + domain_search.SnippetStartSel + `", StopSel="` + domain_search.SnippetStopSel + `", ` +
+	`MaxWords=35, MinWords=15, ShortWord=2, MaxFragments=2, FragmentDelimiter=" … "`
+
+// Todoの全文検索リポジトリ(Impl)
+type TodoSearchRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+	TodoWriter     infrastructure_todo.TodoTxWriter
+}
+
+// Todoの全文検索リポジトリのインスタンス化
+func NewTodoSearchRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient, tw infrastructure_todo.TodoTxWriter) repository_search.ITodoSearchRepository {
+	return &TodoSearchRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+		TodoWriter:     tw,
+	}
+}
+
+// 全文検索の言語の設定を変更し、変更した場合は全てのTodoの索引を作り直す
+func (r *TodoSearchRepositoryImpl) SetSearchLanguage(language string) (bool, error) {
+	r.Logger.InfoLog.Println("SetSearchLanguage called")
+
+	lockQuery := `
+		SELECT config::text = $1::regconfig::text
+		FROM todo_search_settings
+		WHERE id = 1
+		FOR UPDATE
+	`
+	updateQuery := `
+		UPDATE todo_search_settings
+		SET config = $1::regconfig, updated_at = now()
+		WHERE id = 1
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 現在の設定と比較(行ロックを取得する、存在しない設定名の場合はエラー)
+	var unchanged bool
+	err = tx.QueryRow(r.SupabaseClient.Ctx, lockQuery, language).Scan(&unchanged)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch search settings: %v", err)
+		return false, err
+	}
+	if unchanged {
+		// 変更が無い場合は何もしない
+		err = tx.Commit(r.SupabaseClient.Ctx)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+			return false, err
+		}
+		r.Logger.InfoLog.Printf("Search language unchanged: %s", language)
+		return false, nil
+	}
+
+	// 設定を変更し、索引を作り直す
+	_, err = tx.Exec(r.SupabaseClient.Ctx, updateQuery, language)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to update search settings: %v", err)
+		return false, err
+	}
+	count, err := r.TodoWriter.ReindexTodoSearchInTx(tx)
+	if err != nil {
+		return false, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return false, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Changed search language to %s and reindexed %d todos", language, count)
+	return true, nil
+}
+
+// 特定のユーザーのTodoを検索し、関連度の高い順にlimit件取得
+func (r *TodoSearchRepositoryImpl) SearchTodos(userId string, query domain_search.TodoSearchQuery, limit int, after *domain_search.TodoSearchCursor) ([]domain_search.TodoSearchResult, error) {
+	r.Logger.InfoLog.Println("SearchTodos called")
+
+	// 日本語などの部分は設定の言語に関わらず、bigramのフレーズとして検索する
+	// 並び順が同じ関連度の場合はIDの降順とし、カーソル(関連度, ID)より後ろのみ取得する。
+	sqlQuery := `
+		WITH parsed AS (
+			SELECT s.config,
+			       CASE WHEN $2 = '' THEN NULL ELSE websearch_to_tsquery(s.config, $2) END AS words,
+			       CASE WHEN $3 = '' THEN NULL ELSE to_tsquery('simple', $3) END AS bigrams
+			FROM todo_search_settings s
+			WHERE s.id = 1
+		), search AS (
+			SELECT config, COALESCE(words && bigrams, words, bigrams) AS tsq
+			FROM parsed
+		), matched AS (
+			SELECT t.id, t.description, t.completed, t.user_id, t.created_at, t.updated_at, t.deleted_at, t.due_at, t.priority, t.remind_at, t.reminded_at, t.list_id, t.parent_id, t.position,
+			       ts_rank_cd(t.search_vector, search.tsq, 32)::real AS rank,
+			       search.config, search.tsq
+			FROM todos t, search
+			WHERE t.user_id = $1 AND t.deleted_at IS NULL AND t.search_vector @@ search.tsq
+		)
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position,
+		       rank, ts_headline(config, translate(description, $4, ''), tsq, $5)
+		FROM matched
+		WHERE $6::real IS NULL OR rank < $6::real OR (rank = $6::real AND id < $7::uuid)
+		ORDER BY rank DESC, id DESC
+		LIMIT $8
+	`
+
+	var afterRank *float32
+	var afterId *string
+	if after != nil {
+		afterRank = &after.Rank
+		afterId = &after.ID
+	}
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, sqlQuery,
+		userId,
+		query.Text,
+		bigramTsquery(query.Runs),
+		domain_search.SnippetStartSel+domain_search.SnippetStopSel,
+		headlineOptions,
+		afterRank,
+		afterId,
+		limit,
+	)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to search todos: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []domain_search.TodoSearchResult{}
+	for rows.Next() {
+		var result domain_search.TodoSearchResult
+		var headline string
+		err = rows.Scan(
+			&result.Todo.ID,
+			&result.Todo.Description,
+			&result.Todo.Completed,
+			&result.Todo.UserId,
+			&result.Todo.CreatedAt,
+			&result.Todo.UpdatedAt,
+			&result.Todo.DeletedAt,
+			&result.Todo.DueAt,
+			&result.Todo.Priority,
+			&result.Todo.RemindAt,
+			&result.Todo.RemindedAt,
+			&result.Todo.ListId,
+			&result.Todo.ParentId,
+			&result.Todo.Position,
+			&result.Rank,
+			&headline,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan search result: %v", err)
+			return nil, err
+		}
+		result.Snippet = domain_search.BuildSnippet(headline, query.Runs)
+		results = append(results, result)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to search todos: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Found %d todos", len(results))
+	return results, nil
+}
+
+// 日本語などの部分からtsqueryの文字列を作成(空の場合は空文字)
+// 2文字以上はbigramのフレーズ('東京' <-> '京都')、1文字の場合はその文字で始まるbigramへの前方一致とし、全てをANDで結合する。
+// 部分に含まれるのはひらがな・カタカナ・漢字のみのため、引用符のエスケープは不要。
+func bigramTsquery(runs []string) string {
+	terms := make([]string, 0, len(runs))
+	for _, run := range runs {
+		bigrams := domain_search.Bigrams(run)
+		if len(bigrams) == 1 && len([]rune(run)) == 1 {
+			terms = append(terms, "'"+run+"':*")
+			continue
+		}
+		phrase := make([]string, 0, len(bigrams))
+		for _, bigram := range bigrams {
+			phrase = append(phrase, "'"+bigram+"'")
+		}
+		terms = append(terms, "("+strings.Join(phrase, " <-> ")+")")
+	}
+	return strings.Join(terms, " & ")
+}
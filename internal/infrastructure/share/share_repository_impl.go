@@ -0,0 +1,272 @@
+package infrastructure_share
+
+import (
+	domain_share "backend/internal/domain/share"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_share "backend/internal/repository/share"
+	"errors"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// 一意制約違反のエラーコード
+const uniqueViolationCode = "23505"
+
+// 共有リポジトリ(Impl)
+type TodoShareRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+}
+
+// 共有リポジトリのインスタンス化
+func NewTodoShareRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) repository_share.ITodoShareRepository {
+	return &TodoShareRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+	}
+}
+
+// 特定の共有を取得
+func (r *TodoShareRepositoryImpl) GetShareById(id string) (domain_share.TodoShare, error) {
+	r.Logger.InfoLog.Println("GetShareById called")
+
+	query := `
+		SELECT id, owner_id, todo_id, member_id, role, status, invited_by, created_at, accepted_at
+		FROM todo_shares
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する共有を取得
+	share, err := scanShare(r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id))
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch share: %v", err)
+		return domain_share.TodoShare{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched share: %v", share.ID)
+	return share, nil
+}
+
+// 特定のユーザーが所有するTodoの共有を取得(todoIdがnilの場合は全て)
+func (r *TodoShareRepositoryImpl) GetSharesByOwnerId(ownerId string, todoId *string) ([]domain_share.TodoShare, error) {
+	r.Logger.InfoLog.Println("GetSharesByOwnerId called")
+
+	query := `
+		SELECT id, owner_id, todo_id, member_id, role, status, invited_by, created_at, accepted_at
+		FROM todo_shares
+		WHERE owner_id = $1 AND ($2::uuid IS NULL OR todo_id = $2)
+		ORDER BY created_at
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する共有を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, ownerId, todoId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch shares: %v", err)
+		return nil, err
+	}
+
+	shares, err := scanShares(rows)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to scan share: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d shares", len(shares))
+	return shares, nil
+}
+
+// 特定のユーザーへの共有を取得(statusが空の場合は全ての状態)
+func (r *TodoShareRepositoryImpl) GetSharesByMemberId(memberId string, status string) ([]domain_share.TodoShare, error) {
+	r.Logger.InfoLog.Println("GetSharesByMemberId called")
+
+	query := `
+		SELECT id, owner_id, todo_id, member_id, role, status, invited_by, created_at, accepted_at
+		FROM todo_shares
+		WHERE member_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する共有を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, memberId, status)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch shares: %v", err)
+		return nil, err
+	}
+
+	shares, err := scanShares(rows)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to scan share: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d shares", len(shares))
+	return shares, nil
+}
+
+// 特定のユーザーが、所有者のTodo(todoIdsのいずれか、または全てのTodo)に対して持つ承諾済みの権限を取得
+func (r *TodoShareRepositoryImpl) GetMemberRoles(memberId string, ownerId string, todoIds []string) ([]string, error) {
+	r.Logger.InfoLog.Println("GetMemberRoles called")
+
+	query := `
+		SELECT role
+		FROM todo_shares
+		WHERE member_id = $1 AND owner_id = $2 AND status = 'accepted'
+		  AND (todo_id IS NULL OR todo_id = ANY($3))
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する権限を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, memberId, ownerId, todoIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch member roles: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		err = rows.Scan(&role)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan member role: %v", err)
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d member roles", len(roles))
+	return roles, nil
+}
+
+// 新しい共有(招待)を作成
+func (r *TodoShareRepositoryImpl) CreateShare(share domain_share.TodoShare) (domain_share.TodoShare, error) {
+	r.Logger.InfoLog.Println("CreateShare called")
+
+	query := `
+		INSERT INTO todo_shares (owner_id, todo_id, member_id, role, invited_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, owner_id, todo_id, member_id, role, status, invited_by, created_at, accepted_at
+	`
+
+	// Supabaseからクエリを実行し、共有を作成
+	created, err := scanShare(r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query,
+		share.OwnerId,
+		share.TodoId,
+		share.MemberId,
+		share.Role,
+		share.InvitedBy,
+	))
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create share: %v", err)
+		return domain_share.TodoShare{}, toShareError(err)
+	}
+
+	r.Logger.InfoLog.Printf("Created share: %v", created.ID)
+	return created, nil
+}
+
+// 共有を承諾
+func (r *TodoShareRepositoryImpl) AcceptShare(id string) (domain_share.TodoShare, error) {
+	r.Logger.InfoLog.Println("AcceptShare called")
+
+	query := `
+		UPDATE todo_shares
+		SET status = 'accepted', accepted_at = now()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING id, owner_id, todo_id, member_id, role, status, invited_by, created_at, accepted_at
+	`
+
+	// Supabaseからクエリを実行し、共有を承諾
+	share, err := scanShare(r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id))
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to accept share: %v", err)
+		return domain_share.TodoShare{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Accepted share: %v", share.ID)
+	return share, nil
+}
+
+// 共有の権限を変更
+func (r *TodoShareRepositoryImpl) UpdateShareRole(id string, role string) (domain_share.TodoShare, error) {
+	r.Logger.InfoLog.Println("UpdateShareRole called")
+
+	query := `
+		UPDATE todo_shares
+		SET role = $1
+		WHERE id = $2
+		RETURNING id, owner_id, todo_id, member_id, role, status, invited_by, created_at, accepted_at
+	`
+
+	// Supabaseからクエリを実行し、権限を変更
+	share, err := scanShare(r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, role, id))
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to update share role: %v", err)
+		return domain_share.TodoShare{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Updated share role: %v", share.ID)
+	return share, nil
+}
+
+// 特定の共有を削除
+func (r *TodoShareRepositoryImpl) DeleteShare(id string) error {
+	r.Logger.InfoLog.Println("DeleteShare called")
+
+	query := `
+		DELETE FROM todo_shares
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、共有を削除
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete share: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Deleted share: %v", id)
+	return nil
+}
+
+// 1行の共有を読み取る
+func scanShare(row pgx.Row) (domain_share.TodoShare, error) {
+	var share domain_share.TodoShare
+	err := row.Scan(
+		&share.ID,
+		&share.OwnerId,
+		&share.TodoId,
+		&share.MemberId,
+		&share.Role,
+		&share.Status,
+		&share.InvitedBy,
+		&share.CreatedAt,
+		&share.AcceptedAt,
+	)
+	return share, err
+}
+
+// 複数行の共有を読み取る(rowsは読み取り後に閉じる)
+func scanShares(rows pgx.Rows) ([]domain_share.TodoShare, error) {
+	defer rows.Close()
+
+	shares := []domain_share.TodoShare{}
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, rows.Err()
+}
+
+// 共有の一意制約違反をリポジトリのエラーに変換
+func toShareError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return repository_share.ErrShareConflict
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package infrastructure_storage
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	repository_storage "backend/internal/repository/storage"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ローカルファイルシステムのBlobストレージ(Impl)
+type LocalBlobStorage struct {
+	Logger  *pkg_logger.AppLogger
+	BaseDir string
+}
+
+// ローカルファイルシステムのBlobストレージのインスタンス化
+func NewLocalBlobStorage(l *pkg_logger.AppLogger, baseDir string) repository_storage.IBlobStorage {
+	return &LocalBlobStorage{
+		Logger:  l,
+		BaseDir: baseDir,
+	}
+}
+
+// ファイルを保存
+func (s *LocalBlobStorage) Put(key string, r io.Reader) error {
+	s.Logger.InfoLog.Printf("Putting blob: %s", key)
+
+	path, err := s.resolvePath(key)
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Invalid blob key: %v", err)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		s.Logger.ErrorLog.Printf("Failed to create directory: %v", err)
+		return err
+	}
+
+	// 書き込み途中のファイルが残らないよう、一時ファイルに書き込んでからリネームする
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Failed to create temp file: %v", err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		s.Logger.ErrorLog.Printf("Failed to write blob: %v", err)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		s.Logger.ErrorLog.Printf("Failed to close blob: %v", err)
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		s.Logger.ErrorLog.Printf("Failed to rename blob: %v", err)
+		return err
+	}
+
+	s.Logger.InfoLog.Printf("Put blob: %s", key)
+	return nil
+}
+
+// ファイルを取得
+func (s *LocalBlobStorage) Get(key string) (io.ReadCloser, error) {
+	s.Logger.InfoLog.Printf("Getting blob: %s", key)
+
+	path, err := s.resolvePath(key)
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Invalid blob key: %v", err)
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Failed to open blob: %v", err)
+		return nil, err
+	}
+	return f, nil
+}
+
+// ファイルを削除
+func (s *LocalBlobStorage) Delete(key string) error {
+	s.Logger.InfoLog.Printf("Deleting blob: %s", key)
+
+	path, err := s.resolvePath(key)
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Invalid blob key: %v", err)
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.Logger.ErrorLog.Printf("Failed to delete blob: %v", err)
+		return err
+	}
+
+	s.Logger.InfoLog.Printf("Deleted blob: %s", key)
+	return nil
+}
+
+// キーから保存先のパスを取得
+// ベースディレクトリの外を指すキーはエラーとする。
+func (s *LocalBlobStorage) resolvePath(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if key == "" || cleaned == "/" {
+		return "", errors.New("blob key is empty")
+	}
+
+	path := filepath.Join(s.BaseDir, cleaned)
+	base := filepath.Clean(s.BaseDir) + string(filepath.Separator)
+	if !strings.HasPrefix(path, base) {
+		return "", errors.New("blob key is outside of base directory")
+	}
+	return path, nil
+}
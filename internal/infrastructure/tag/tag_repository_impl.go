@@ -0,0 +1,296 @@
+package infrastructure_tag
+
+import (
+	domain_tag "backend/internal/domain/tag"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_tag "backend/internal/repository/tag"
+	"errors"
+
+	"github.com/jackc/pgconn"
+)
+
+// 一意制約違反のエラーコード
+const uniqueViolationCode = "23505"
+
+// タグリポジトリ(Impl)
+type TagRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+}
+
+// タグリポジトリのインスタンス化
+func NewTagRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) repository_tag.ITagRepository {
+	return &TagRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+	}
+}
+
+// 特定のタグを取得
+func (r *TagRepositoryImpl) GetTagById(id string) (domain_tag.Tag, error) {
+	r.Logger.InfoLog.Println("GetTagById called")
+
+	query := `
+		SELECT id, user_id, name, created_at
+		FROM tags
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するタグを取得
+	var tag domain_tag.Tag
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&tag.ID,
+			&tag.UserId,
+			&tag.Name,
+			&tag.CreatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch tag: %v", err)
+		return domain_tag.Tag{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched tag: %v", tag.ID)
+	return tag, nil
+}
+
+// 特定のユーザーのタグを取得
+func (r *TagRepositoryImpl) GetTagsByUserId(userId string) ([]domain_tag.Tag, error) {
+	r.Logger.InfoLog.Println("GetTagsByUserId called")
+
+	query := `
+		SELECT id, user_id, name, created_at
+		FROM tags
+		WHERE user_id = $1
+		ORDER BY lower(name)
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するタグを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch tags: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// タグのリストを作成
+	tags := []domain_tag.Tag{}
+	for rows.Next() {
+		var tag domain_tag.Tag
+		err = rows.Scan(
+			&tag.ID,
+			&tag.UserId,
+			&tag.Name,
+			&tag.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan tag: %v", err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d tags", len(tags))
+	return tags, nil
+}
+
+// 複数のTodoに付与されたタグを取得
+func (r *TagRepositoryImpl) GetTagsByTodoIds(todoIds []string) ([]domain_tag.TodoTag, error) {
+	r.Logger.InfoLog.Println("GetTagsByTodoIds called")
+
+	query := `
+		SELECT tt.todo_id, t.id, t.user_id, t.name, t.created_at
+		FROM todo_tags tt
+		JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.todo_id = ANY($1)
+		ORDER BY lower(t.name)
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するタグを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, todoIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo tags: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Todoに付与されたタグのリストを作成
+	todoTags := []domain_tag.TodoTag{}
+	for rows.Next() {
+		var todoTag domain_tag.TodoTag
+		err = rows.Scan(
+			&todoTag.TodoId,
+			&todoTag.Tag.ID,
+			&todoTag.Tag.UserId,
+			&todoTag.Tag.Name,
+			&todoTag.Tag.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo tag: %v", err)
+			return nil, err
+		}
+		todoTags = append(todoTags, todoTag)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d todo tags", len(todoTags))
+	return todoTags, nil
+}
+
+// 特定のユーザーの、指定したタグが全て付与されたTodoのIDを取得
+func (r *TagRepositoryImpl) GetTodoIdsByTagIds(userId string, tagIds []string) ([]string, error) {
+	r.Logger.InfoLog.Println("GetTodoIdsByTagIds called")
+
+	query := `
+		SELECT tt.todo_id
+		FROM todo_tags tt
+		JOIN tags t ON t.id = tt.tag_id
+		WHERE t.user_id = $1 AND tt.tag_id = ANY($2)
+		GROUP BY tt.todo_id
+		HAVING COUNT(DISTINCT tt.tag_id) = $3
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoのIDを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId, tagIds, len(tagIds))
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo ids by tags: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo id: %v", err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d todo ids", len(ids))
+	return ids, nil
+}
+
+// 新しいタグを作成
+func (r *TagRepositoryImpl) CreateTag(tag domain_tag.Tag) (domain_tag.Tag, error) {
+	r.Logger.InfoLog.Println("CreateTag called")
+
+	query := `
+		INSERT INTO tags (user_id, name)
+		VALUES ($1, $2)
+		RETURNING id, user_id, name, created_at
+	`
+
+	// Supabaseからクエリを実行し、タグを作成
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, tag.UserId, tag.Name).
+		Scan(&tag.ID,
+			&tag.UserId,
+			&tag.Name,
+			&tag.CreatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create tag: %v", err)
+		return domain_tag.Tag{}, toTagError(err)
+	}
+
+	r.Logger.InfoLog.Printf("Created tag: %v", tag.ID)
+	return tag, nil
+}
+
+// タグ名を変更
+func (r *TagRepositoryImpl) RenameTag(id string, name string) (domain_tag.Tag, error) {
+	r.Logger.InfoLog.Println("RenameTag called")
+
+	query := `
+		UPDATE tags
+		SET name = $1
+		WHERE id = $2
+		RETURNING id, user_id, name, created_at
+	`
+
+	// Supabaseからクエリを実行し、タグ名を変更
+	var tag domain_tag.Tag
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, name, id).
+		Scan(&tag.ID,
+			&tag.UserId,
+			&tag.Name,
+			&tag.CreatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to rename tag: %v", err)
+		return domain_tag.Tag{}, toTagError(err)
+	}
+
+	r.Logger.InfoLog.Printf("Renamed tag: %v", tag.ID)
+	return tag, nil
+}
+
+// 特定のタグを削除(Todoとの関連も削除される)
+func (r *TagRepositoryImpl) DeleteTag(id string) error {
+	r.Logger.InfoLog.Println("DeleteTag called")
+
+	query := `
+		DELETE FROM tags
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、タグを削除
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete tag: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Deleted tag: %v", id)
+	return nil
+}
+
+// Todoにタグを付与(付与済みの場合は何もしない)
+func (r *TagRepositoryImpl) TagTodo(todoId string, tagId string) error {
+	r.Logger.InfoLog.Println("TagTodo called")
+
+	query := `
+		INSERT INTO todo_tags (todo_id, tag_id)
+		VALUES ($1, $2)
+		ON CONFLICT (todo_id, tag_id) DO NOTHING
+	`
+
+	// Supabaseからクエリを実行し、タグを付与
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, todoId, tagId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to tag todo: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Tagged todo: %v", todoId)
+	return nil
+}
+
+// Todoからタグを外す
+func (r *TagRepositoryImpl) UntagTodo(todoId string, tagId string) error {
+	r.Logger.InfoLog.Println("UntagTodo called")
+
+	query := `
+		DELETE FROM todo_tags
+		WHERE todo_id = $1 AND tag_id = $2
+	`
+
+	// Supabaseからクエリを実行し、タグを外す
+	_, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, todoId, tagId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to untag todo: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Untagged todo: %v", todoId)
+	return nil
+}
+
+// タグ名の一意制約違反をリポジトリのエラーに変換
+func toTagError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return repository_tag.ErrTagNameConflict
+	}
+	return err
+}
@@ -0,0 +1,1566 @@
+package infrastructure_todo
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_todo "backend/internal/domain/todo"
+	infrastructure_audit "backend/internal/infrastructure/audit"
+	pkg_fracindex "backend/internal/pkg/fracindex"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_todo "backend/internal/repository/todo"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// 一意制約違反のエラーコード
+const uniqueViolationCode = "23505"
+
+// イベントソーシングによるTodoリポジトリ(Impl)
+// 書き込みはドメインイベントとしてtodo_eventsに追記し、同じトランザクションで読み取りモデル(todosテーブル)に投影する。
+// 読み込みは読み取りモデルから行うため、TodoRepositoryImplに委譲する。
+// 他のリポジトリからの書き込み(TodoTxWriter)・並び順の変更・リマインドの通知もイベントとして追記する。
+type EventSourcedTodoRepositoryImpl struct {
+	*TodoRepositoryImpl
+	// スナップショットを作成するイベントの間隔(0以下の場合は作成しない)
+	SnapshotInterval int
+}
+
+// Todoの集約(イベントを適用した状態)
+type todoAggregate struct {
+	State   domain_todo.Todo
+	Version int
+	Purged  bool
+}
+
+// クエリを実行できるもの(コネクションプール・トランザクション)
+type todoQuerier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// イベントソーシングによるTodoリポジトリのインスタンス化
+func NewEventSourcedTodoRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient, snapshotInterval int) repository_todo.ITodoRepository {
+	return newEventSourcedTodoRepository(l, sc, snapshotInterval)
+}
+
+// Todoのイベントストアのインスタンス化
+func NewTodoEventStore(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient, snapshotInterval int) repository_todo.ITodoEventStore {
+	return newEventSourcedTodoRepository(l, sc, snapshotInterval)
+}
+
+func newEventSourcedTodoRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient, snapshotInterval int) *EventSourcedTodoRepositoryImpl {
+	return &EventSourcedTodoRepositoryImpl{
+		TodoRepositoryImpl: &TodoRepositoryImpl{
+			Logger:         l,
+			SupabaseClient: sc,
+		},
+		SnapshotInterval: snapshotInterval,
+	}
+}
+
+// 新しいTodoを作成
+func (r *EventSourcedTodoRepositoryImpl) CreateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("CreateTodo called")
+
+	// トランザクション開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// Todoを作成
+	todo, err = r.CreateTodoInTx(tx, todo, actor)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Created todo: %v", todo)
+	return todo, nil
+}
+
+// トランザクション内で新しいTodoを作成し、TodoCreatedを追記(監査ログを記録する)
+// 読み取りモデルに行を作成してIDと作成日時を採番してから、イベントを追記する。
+func (r *EventSourcedTodoRepositoryImpl) CreateTodoInTx(tx pgx.Tx, todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	todo, err := r.insertTodoInTx(tx, todo)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// TodoCreatedを追記
+	event, err := domain_todo.NewTodoCreatedEvent(todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create todo event: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	_, err = r.appendEvents(tx, todoAggregate{}, []domain_todo.TodoEvent{event}, actor.UserId)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionCreate, domain_audit.AuditEntityTodo, todo.ID, nil, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	return todo, nil
+}
+
+// 複数のTodoを1つのトランザクションで作成
+// TodoごとにTodoCreatedを追記する。
+func (r *EventSourcedTodoRepositoryImpl) ImportTodos(todos []domain_todo.Todo, parentIndexes []int, actor domain_audit.AuditActor) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("ImportTodos called")
+
+	// トランザクション開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	created := make([]domain_todo.Todo, 0, len(todos))
+	for i, todo := range todos {
+		// ファイル内の親は先に作成済みのため、採番されたIDを親にする
+		if parentIndexes[i] >= 0 {
+			todo.ParentId = &created[parentIndexes[i]].ID
+		}
+		todo, err = r.CreateTodoInTx(tx, todo, actor)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, todo)
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Imported %d todos", len(created))
+	return created, nil
+}
+
+// 特定のTodoを更新
+func (r *EventSourcedTodoRepositoryImpl) UpdateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("UpdateTodo called")
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// Todoを更新
+	todo, err = r.updateTodoEventsInTx(tx, todo, actor)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Updated todo: %v", todo)
+	return todo, nil
+}
+
+// 特定のTodoを更新し、未完了のサブタスク(子孫)を全て完了にする
+func (r *EventSourcedTodoRepositoryImpl) UpdateTodoAndCompleteSubtasks(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, int, error) {
+	r.Logger.InfoLog.Println("UpdateTodoAndCompleteSubtasks called")
+
+	selectQuery := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM todos WHERE parent_id = $1 AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+		)
+		SELECT id
+		FROM todos
+		WHERE id IN (SELECT id FROM subtree) AND NOT completed
+		ORDER BY id
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, 0, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// Todoを更新
+	todo, err = r.updateTodoEventsInTx(tx, todo, actor)
+	if err != nil {
+		return domain_todo.Todo{}, 0, err
+	}
+
+	// 未完了のサブタスクのIDを取得
+	rows, err := tx.Query(r.SupabaseClient.Ctx, selectQuery, todo.ID)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch subtasks: %v", err)
+		return domain_todo.Todo{}, 0, err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			r.Logger.ErrorLog.Printf("Failed to scan todo id: %v", err)
+			return domain_todo.Todo{}, 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch subtasks: %v", err)
+		return domain_todo.Todo{}, 0, err
+	}
+
+	// サブタスクごとにTodoCompletedを追記
+	completed := 0
+	for _, id := range ids {
+		aggregate, before, loadErr := r.loadAggregateForUpdate(tx, id)
+		if loadErr != nil {
+			err = loadErr
+			return domain_todo.Todo{}, 0, err
+		}
+		// 行ロックを取得するまでの間に完了・削除された場合は対象外
+		if before.Completed || before.DeletedAt != nil {
+			continue
+		}
+		desired := aggregate.State
+		desired.Completed = true
+		_, err = r.changeTodo(tx, aggregate, before, desired, actor, domain_audit.AuditActionUpdate)
+		if err != nil {
+			return domain_todo.Todo{}, 0, err
+		}
+		completed++
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, 0, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Updated todo: %v (%d subtasks completed)", todo, completed)
+	return todo, completed, nil
+}
+
+// トランザクション内でTodoの更新をイベントとして追記し、読み取りモデルに投影
+func (r *EventSourcedTodoRepositoryImpl) updateTodoEventsInTx(tx pgx.Tx, todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	aggregate, before, err := r.loadAggregateForUpdate(tx, todo.ID)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+	if before.DeletedAt != nil {
+		r.Logger.ErrorLog.Printf("Todo is in trash: %v", todo.ID)
+		return domain_todo.Todo{}, pgx.ErrNoRows
+	}
+
+	// 更新できる項目のみ反映する(所有者・作成日時は変更しない)
+	desired := aggregate.State
+	desired.Description = todo.Description
+	desired.Completed = todo.Completed
+	desired.DueAt = todo.DueAt
+	desired.Priority = todo.Priority
+	desired.RemindAt = todo.RemindAt
+
+	return r.changeTodo(tx, aggregate, before, desired, actor, domain_audit.AuditActionUpdate)
+}
+
+// Todoを別のリストに移動(listIdがnilの場合はインボックス)
+func (r *EventSourcedTodoRepositoryImpl) MoveTodoToList(id string, listId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("MoveTodoToList called")
+
+	todo, err := r.changeActiveTodo(id, actor, func(desired *domain_todo.Todo) {
+		desired.ListId = listId
+	})
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Moved todo: %v", todo)
+	return todo, nil
+}
+
+// Todoの親を変更(parentIdがnilの場合は最上位)
+func (r *EventSourcedTodoRepositoryImpl) SetTodoParent(id string, parentId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("SetTodoParent called")
+
+	todo, err := r.changeActiveTodo(id, actor, func(desired *domain_todo.Todo) {
+		desired.ParentId = parentId
+	})
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Set todo parent: %v", todo)
+	return todo, nil
+}
+
+// ゴミ箱にないTodoを変更し、差分をイベントとして追記
+func (r *EventSourcedTodoRepositoryImpl) changeActiveTodo(id string, actor domain_audit.AuditActor, change func(desired *domain_todo.Todo)) (domain_todo.Todo, error) {
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 変更前のTodoを取得(行ロックを取得する)
+	aggregate, before, err := r.loadAggregateForUpdate(tx, id)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+	if before.DeletedAt != nil {
+		r.Logger.ErrorLog.Printf("Todo is in trash: %v", id)
+		err = pgx.ErrNoRows
+		return domain_todo.Todo{}, err
+	}
+
+	// 変更をイベントとして追記
+	desired := aggregate.State
+	change(&desired)
+	todo, err := r.changeTodo(tx, aggregate, before, desired, actor, domain_audit.AuditActionUpdate)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	return todo, nil
+}
+
+// 特定のTodoを削除(ゴミ箱に移動)
+func (r *EventSourcedTodoRepositoryImpl) DeleteTodo(id string, actor domain_audit.AuditActor) error {
+	r.Logger.InfoLog.Println("DeleteTodo called")
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 削除前のTodoを取得(行ロックを取得する)
+	aggregate, before, err := r.loadAggregateForUpdate(tx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && before.DeletedAt != nil) {
+		// 削除対象が無い場合は何もしない(監査ログも記録しない)
+		err = tx.Rollback(r.SupabaseClient.Ctx)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			return err
+		}
+		r.Logger.InfoLog.Printf("Todo not found: %v", id)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// TodoDeletedを追記
+	desired := aggregate.State
+	now := time.Now()
+	desired.DeletedAt = &now
+	_, err = r.changeTodo(tx, aggregate, before, desired, actor, domain_audit.AuditActionDelete)
+	if err != nil {
+		return err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Deleted todo: %v", id)
+	return nil
+}
+
+// 複数のTodoの完了状態を1つのトランザクションで変更し、Todoごとの結果を返す
+// 権限は同じトランザクション内で確認する。
+func (r *EventSourcedTodoRepositoryImpl) BulkSetCompleted(ids []string, completed bool, required string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error) {
+	r.Logger.InfoLog.Println("BulkSetCompleted called")
+
+	results, err := r.changeAuthorizedTodos(ids, required, actor, domain_audit.AuditActionUpdate, func(desired *domain_todo.Todo) bool {
+		desired.Completed = completed
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Set completed of %d todos", len(results))
+	return results, nil
+}
+
+// 複数のTodoを1つのトランザクションで削除(ゴミ箱に移動)し、Todoごとの結果を返す
+// 権限は同じトランザクション内で確認する。
+func (r *EventSourcedTodoRepositoryImpl) BulkDeleteTodos(ids []string, required string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error) {
+	r.Logger.InfoLog.Println("BulkDeleteTodos called")
+
+	now := time.Now()
+	results, err := r.changeAuthorizedTodos(ids, required, actor, domain_audit.AuditActionDelete, func(desired *domain_todo.Todo) bool {
+		desired.DeletedAt = &now
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Deleted %d todos", len(results))
+	return results, nil
+}
+
+// 一括操作の権限を確認し、権限のあるTodoの変更を1つのトランザクションでイベントとして追記(監査ログを記録する)
+// 権限の確認と変更の間に他の操作が割り込まないよう、確認時に行ロックを取得する。
+func (r *EventSourcedTodoRepositoryImpl) changeAuthorizedTodos(ids []string, required string, actor domain_audit.AuditActor, action string, change func(desired *domain_todo.Todo) bool) ([]domain_todo.TodoBulkResult, error) {
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 権限を確認し、変更前のTodoを取得(行ロック)
+	results, befores, err := r.authorizeBulkInTx(tx, ids, actor.UserId, required)
+	if err != nil {
+		return nil, err
+	}
+	authorizedIds := make([]string, 0, len(befores))
+	for _, before := range befores {
+		authorizedIds = append(authorizedIds, before.ID)
+	}
+
+	// Todoごとに変更をイベントとして追記
+	todos, err := r.changeTodosInTx(tx, authorizedIds, actor, action, change)
+	if err != nil {
+		return nil, err
+	}
+	applyBulkResults(results, befores, todos)
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	return results, nil
+}
+
+// 特定のユーザーの完了済みのTodoを1つのトランザクションで削除(ゴミ箱に移動)し、削除したTodoを返す(listIdがnilの場合は全てのリスト)
+func (r *EventSourcedTodoRepositoryImpl) ClearCompletedTodos(userId string, listId *string, actor domain_audit.AuditActor) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("ClearCompletedTodos called")
+
+	selectQuery := `
+		SELECT id
+		FROM todos
+		WHERE user_id = $1 AND completed AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR list_id = $2::uuid)
+		ORDER BY id
+	`
+
+	now := time.Now()
+	todos, err := r.changeTodos(actor, domain_audit.AuditActionDelete, func(desired *domain_todo.Todo) bool {
+		// 行ロックを取得するまでの間に未完了に戻された場合は対象外
+		if !desired.Completed {
+			return false
+		}
+		desired.DeletedAt = &now
+		return true
+	}, selectQuery, userId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Cleared %d completed todos", len(todos))
+	return todos, nil
+}
+
+// selectQueryで取得したゴミ箱にないTodoを1つのトランザクションで変更し、差分をイベントとして追記
+// changeがfalseを返したTodoは変更せず、結果にも含めない。
+func (r *EventSourcedTodoRepositoryImpl) changeTodos(actor domain_audit.AuditActor, action string, change func(desired *domain_todo.Todo) bool, selectQuery string, args ...interface{}) ([]domain_todo.Todo, error) {
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 対象のTodoのIDを取得
+	ids, err := r.queryTodoIds(tx, selectQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Todoごとに変更をイベントとして追記
+	todos, err := r.changeTodosInTx(tx, ids, actor, action, change)
+	if err != nil {
+		return nil, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	return todos, nil
+}
+
+// トランザクション内でTodoごとに変更をイベントとして追記し、変更後のTodo(ゴミ箱にあるものと対象外のものを除く)を返す
+func (r *EventSourcedTodoRepositoryImpl) changeTodosInTx(tx pgx.Tx, ids []string, actor domain_audit.AuditActor, action string, change func(desired *domain_todo.Todo) bool) ([]domain_todo.Todo, error) {
+	todos := make([]domain_todo.Todo, 0, len(ids))
+	for _, id := range ids {
+		aggregate, before, err := r.loadAggregateForUpdate(tx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// 行ロックを取得するまでの間に削除された場合は対象外
+		if before.DeletedAt != nil {
+			continue
+		}
+		desired := aggregate.State
+		if !change(&desired) {
+			continue
+		}
+		todo, err := r.changeTodo(tx, aggregate, before, desired, actor, action)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	return todos, nil
+}
+
+// ゴミ箱のTodoを復元
+func (r *EventSourcedTodoRepositoryImpl) RestoreTodo(id string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("RestoreTodo called")
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 復元前のTodoを取得(行ロックを取得する)
+	aggregate, before, err := r.loadAggregateForUpdate(tx, id)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+	if before.DeletedAt == nil {
+		r.Logger.ErrorLog.Printf("Todo is not in trash: %v", id)
+		err = pgx.ErrNoRows
+		return domain_todo.Todo{}, err
+	}
+
+	// TodoRestoredを追記
+	desired := aggregate.State
+	desired.DeletedAt = nil
+	todo, err := r.changeTodo(tx, aggregate, before, desired, actor, domain_audit.AuditActionRestore)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Restored todo: %v", todo)
+	return todo, nil
+}
+
+// ゴミ箱のTodoを完全に削除
+// 削除した添付ファイルのストレージキーを返す(ストレージからの削除は呼び出し側で行う)。イベントは削除せずに残す。
+func (r *EventSourcedTodoRepositoryImpl) PurgeTodo(id string, actor domain_audit.AuditActor) ([]string, error) {
+	r.Logger.InfoLog.Println("PurgeTodo called")
+
+	attachmentQuery := `
+		DELETE FROM todo_attachments
+		WHERE todo_id = $1
+		RETURNING storage_key
+	`
+	query := `
+		DELETE FROM todos
+		WHERE id = $1
+	`
+	snapshotQuery := `
+		DELETE FROM todo_snapshots
+		WHERE aggregate_id = $1
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 削除前のTodoを取得(行ロックを取得する)
+	aggregate, before, err := r.loadAggregateForUpdate(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.DeletedAt == nil {
+		r.Logger.ErrorLog.Printf("Todo is not in trash: %v", id)
+		err = pgx.ErrNoRows
+		return nil, err
+	}
+
+	// TodoPurgedを追記
+	event, err := domain_todo.NewTodoEvent(id, domain_todo.TodoEventPurged, nil, time.Now())
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create todo event: %v", err)
+		return nil, err
+	}
+	_, err = r.appendEvents(tx, aggregate, []domain_todo.TodoEvent{event}, actor.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 添付ファイル情報を削除し、ストレージキーを取得(Todoの削除でカスケード削除される前に取得する)
+	rows, err := tx.Query(r.SupabaseClient.Ctx, attachmentQuery, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete attachments: %v", err)
+		return nil, err
+	}
+	storageKeys := []string{}
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			rows.Close()
+			r.Logger.ErrorLog.Printf("Failed to scan storage key: %v", err)
+			return nil, err
+		}
+		storageKeys = append(storageKeys, key)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete attachments: %v", err)
+		return nil, err
+	}
+
+	// 読み取りモデルとスナップショットを削除
+	_, err = tx.Exec(r.SupabaseClient.Ctx, query, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to purge todo: %v", err)
+		return nil, err
+	}
+	_, err = tx.Exec(r.SupabaseClient.Ctx, snapshotQuery, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete todo snapshot: %v", err)
+		return nil, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionPurge, domain_audit.AuditEntityTodo, id, before, nil)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return nil, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Purged todo: %v", id)
+	return storageKeys, nil
+}
+
+// Todoの並び順のキーを変更し、TodoReorderedを追記(監査ログを同一トランザクションで記録する)
+func (r *EventSourcedTodoRepositoryImpl) UpdateTodoPosition(id string, position string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("UpdateTodoPosition called")
+
+	todo, err := r.changeActiveTodo(id, actor, func(desired *domain_todo.Todo) {
+		desired.Position = &position
+	})
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Updated todo position: %v", todo)
+	return todo, nil
+}
+
+// 特定のユーザーのゴミ箱にないTodoの並び順のキーを現在の順序のまま等間隔に振り直し、更新した件数を返す
+// キーを変更したTodoごとに振り直しのTodoReorderedをシステムによるイベントとして追記する(監査ログは記録しない)。
+func (r *EventSourcedTodoRepositoryImpl) RebalanceTodoPositions(userId string) (int, error) {
+	r.Logger.InfoLog.Println("RebalanceTodoPositions called")
+
+	selectQuery := `
+		SELECT id
+		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY position, created_at, id
+		FOR UPDATE
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 現在の順序でTodoのIDを取得(行ロックを取得する)
+	ids, err := r.queryTodoIds(tx, selectQuery, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	// 等間隔のキーを振り直す
+	positions := pkg_fracindex.EvenlySpaced(len(ids))
+	now := time.Now()
+	for i, id := range ids {
+		aggregate, _, loadErr := r.loadAggregateForUpdate(tx, id)
+		if loadErr != nil {
+			err = loadErr
+			return 0, err
+		}
+		if aggregate.State.Position != nil && *aggregate.State.Position == positions[i] {
+			continue
+		}
+		event, eventErr := domain_todo.NewTodoEvent(id, domain_todo.TodoEventReordered, domain_todo.TodoReorderedPayload{Position: positions[i], Rebalanced: true}, now)
+		if eventErr != nil {
+			err = eventErr
+			r.Logger.ErrorLog.Printf("Failed to create todo event: %v", err)
+			return 0, err
+		}
+		aggregate, err = r.appendEvents(tx, aggregate, []domain_todo.TodoEvent{event}, domain_audit.SystemActorId)
+		if err != nil {
+			return 0, err
+		}
+		_, err = r.project(tx, aggregate.State, false)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return 0, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Rebalanced %d todo positions", len(ids))
+	return len(ids), nil
+}
+
+// リマインド日時を過ぎたTodoを取得し、通知済みにする(TodoRemindedをシステムによるイベントとして追記する)
+// 複数のインスタンスから同時に呼び出されても、同じTodoを重複して取得しないようにする。
+func (r *EventSourcedTodoRepositoryImpl) ClaimDueReminders(now time.Time, limit int) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("ClaimDueReminders called")
+
+	selectQuery := `
+		SELECT id
+		FROM todos
+		WHERE deleted_at IS NULL
+		  AND completed = false
+		  AND reminded_at IS NULL
+		  AND remind_at <= $1
+		ORDER BY remind_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 通知対象のTodoのIDを取得(行ロックを取得する)
+	ids, err := r.queryTodoIds(tx, selectQuery, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Todoごとに通知済みにする
+	todos := make([]domain_todo.Todo, 0, len(ids))
+	for _, id := range ids {
+		aggregate, _, loadErr := r.loadAggregateForUpdate(tx, id)
+		if loadErr != nil {
+			err = loadErr
+			return nil, err
+		}
+		desired := aggregate.State
+		desired.RemindedAt = &now
+		todo, _, recordErr := r.recordChange(tx, aggregate, desired, domain_audit.SystemActorId)
+		if recordErr != nil {
+			err = recordErr
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Claimed %d due reminders", len(todos))
+	return todos, nil
+}
+
+// トランザクション内で特定のTodoリストのTodoをインボックスに移動し、ゴミ箱にないTodoを返す(監査ログを記録する)
+// trashがtrueの場合は、ゴミ箱にないTodoをゴミ箱に移動する。ゴミ箱のTodoも外部キーに任せずTodoMovedを追記する(監査ログは記録しない)。
+func (r *EventSourcedTodoRepositoryImpl) DetachTodosFromListInTx(tx pgx.Tx, listId string, trash bool, actor domain_audit.AuditActor) ([]domain_todo.Todo, error) {
+	selectQuery := `
+		SELECT id
+		FROM todos
+		WHERE list_id = $1
+		ORDER BY id
+	`
+
+	action := domain_audit.AuditActionUpdate
+	if trash {
+		action = domain_audit.AuditActionDelete
+	}
+
+	// リスト内のTodoのIDを取得
+	ids, err := r.queryTodoIds(tx, selectQuery, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Todoごとに変更をイベントとして追記
+	now := time.Now()
+	todos := []domain_todo.Todo{}
+	for _, id := range ids {
+		aggregate, before, err := r.loadAggregateForUpdate(tx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// 行ロックを取得するまでの間に別のリストに移動された場合は対象外
+		if before.ListId == nil || *before.ListId != listId {
+			continue
+		}
+		desired := aggregate.State
+		desired.ListId = nil
+		if before.DeletedAt != nil {
+			_, _, err = r.recordChange(tx, aggregate, desired, actor.UserId)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if trash {
+			desired.DeletedAt = &now
+		}
+		todo, err := r.changeTodo(tx, aggregate, before, desired, actor, action)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	return todos, nil
+}
+
+// 特定のTodoのイベントをバージョン順に取得(afterVersionより後のバージョンのみ)
+func (r *EventSourcedTodoRepositoryImpl) GetTodoEvents(aggregateId string, afterVersion int) ([]domain_todo.TodoEvent, error) {
+	r.Logger.InfoLog.Println("GetTodoEvents called")
+
+	events, err := r.queryEvents(r.SupabaseClient.Pool, aggregateId, afterVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d todo events", len(events))
+	return events, nil
+}
+
+// イベントを再生して読み取りモデル(todosテーブル)を再構築し、再構築したTodoの件数を返す
+// 読み取りモデルはイベントから作り直すものとして扱い、イベントに記録されていない変更は破棄する。
+// 親Todoが後から作成された場合に備え、全てのTodoを投影した後に親子関係を設定し直す。
+func (r *EventSourcedTodoRepositoryImpl) RebuildReadModel(useSnapshots bool) (int, error) {
+	r.Logger.InfoLog.Println("RebuildReadModel called")
+
+	idsQuery := `
+		SELECT aggregate_id
+		FROM todo_events
+		GROUP BY aggregate_id
+		ORDER BY MIN(seq)
+	`
+	parentQuery := `
+		UPDATE todos
+		SET parent_id = (SELECT p.id FROM todos p WHERE p.id = $2)
+		WHERE id = $1 AND parent_id IS DISTINCT FROM $2
+	`
+
+	// イベントのあるTodoのIDを作成順に取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, idsQuery)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch aggregate ids: %v", err)
+		return 0, err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			r.Logger.ErrorLog.Printf("Failed to scan aggregate id: %v", err)
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch aggregate ids: %v", err)
+		return 0, err
+	}
+
+	// Todoごとにイベントを再生して投影
+	parents := map[string]string{}
+	for i, id := range ids {
+		state, err := r.rebuildTodo(id, useSnapshots)
+		if err != nil {
+			return i, err
+		}
+		if state != nil && state.ParentId != nil {
+			parents[id] = *state.ParentId
+		}
+	}
+
+	// 親子関係を設定し直す(親が存在しない場合は最上位にする)
+	for id, parentId := range parents {
+		_, err = r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, parentQuery, id, parentId)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to restore todo parent: %v", err)
+			return len(ids), err
+		}
+	}
+
+	r.Logger.InfoLog.Printf("Rebuilt %d todos", len(ids))
+	return len(ids), nil
+}
+
+// 1件のTodoのイベントを再生して読み取りモデルに投影(完全に削除された場合はnilを返す)
+// 状態はスナップショットとイベントのみから組み立て、読み取りモデルの行は上書きする(イベントとして取り込まない)。
+func (r *EventSourcedTodoRepositoryImpl) rebuildTodo(id string, useSnapshots bool) (*domain_todo.Todo, error) {
+	positionQuery := `
+		SELECT position
+		FROM todos
+		WHERE id = $1
+		FOR UPDATE
+	`
+	lastPositionQuery := `
+		SELECT MAX(position)
+		FROM todos
+		WHERE user_id = $1
+	`
+	purgeQuery := `
+		DELETE FROM todos
+		WHERE id = $1
+	`
+	deleteSnapshotQuery := `
+		DELETE FROM todo_snapshots
+		WHERE aggregate_id = $1
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 読み取りモデルの行がある場合は行ロックを取得する
+	var rowPosition *string
+	err = tx.QueryRow(r.SupabaseClient.Ctx, positionQuery, id).Scan(&rowPosition)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo position: %v", err)
+		return nil, err
+	}
+	err = nil
+
+	// イベントを再生(読み取りモデルの状態は使わない)
+	aggregate, err := r.loadAggregate(tx, id, useSnapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	// 並び順のキーがイベントに無い場合は既存の行のキーを変更せず、行にも無い場合はユーザーのTodoの末尾に並べる
+	projected := aggregate.State
+	if projected.Position == nil && rowPosition == nil && !aggregate.Purged {
+		var last *string
+		err = tx.QueryRow(r.SupabaseClient.Ctx, lastPositionQuery, aggregate.State.UserId).Scan(&last)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to fetch last todo position: %v", err)
+			return nil, err
+		}
+		lower := ""
+		if last != nil {
+			lower = *last
+		}
+		key, keyErr := pkg_fracindex.KeyBetween(lower, "")
+		if keyErr != nil {
+			// 既存のキーが不正な場合はキーを設定せず、再配置に任せる
+			r.Logger.WarnLog.Printf("Failed to generate todo position: %v", keyErr)
+		} else {
+			projected.Position = &key
+		}
+	}
+
+	// 読み取りモデルに投影
+	var state *domain_todo.Todo
+	if aggregate.Purged {
+		_, err = tx.Exec(r.SupabaseClient.Ctx, purgeQuery, id)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to purge todo: %v", err)
+			return nil, err
+		}
+	} else {
+		_, err = r.project(tx, projected, true)
+		if err != nil {
+			return nil, err
+		}
+		state = &aggregate.State
+	}
+
+	// 最初から再生した場合はスナップショットを作り直す
+	if !useSnapshots {
+		_, err = tx.Exec(r.SupabaseClient.Ctx, deleteSnapshotQuery, id)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to delete todo snapshot: %v", err)
+			return nil, err
+		}
+		if !aggregate.Purged && r.SnapshotInterval > 0 && aggregate.Version >= r.SnapshotInterval {
+			err = r.saveSnapshot(tx, aggregate)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	return state, nil
+}
+
+// トランザクション内でTodoの集約を読み込む(読み取りモデルの行ロックを取得する)
+// 書き込み時のみ使う。イベントソーシング導入前のTodoなど、イベントに記録されていない読み取りモデルの変更は、
+// 上書きして失わないようシステムによるイベントとして先に取り込む(再構築では取り込まない)。
+func (r *EventSourcedTodoRepositoryImpl) loadAggregateForUpdate(tx pgx.Tx, id string) (todoAggregate, domain_todo.Todo, error) {
+	rowQuery := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	// 読み取りモデルの行を取得(行ロック)
+	var row domain_todo.Todo
+	err := tx.QueryRow(r.SupabaseClient.Ctx, rowQuery, id).
+		Scan(&row.ID,
+			&row.Description,
+			&row.Completed,
+			&row.UserId,
+			&row.CreatedAt,
+			&row.UpdatedAt,
+			&row.DeletedAt,
+			&row.DueAt,
+			&row.Priority,
+			&row.RemindAt,
+			&row.RemindedAt,
+			&row.ListId,
+			&row.ParentId,
+			&row.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo: %v", err)
+		return todoAggregate{}, domain_todo.Todo{}, err
+	}
+
+	// イベントを再生
+	aggregate, err := r.loadAggregate(tx, id, true)
+	if err != nil {
+		return todoAggregate{}, domain_todo.Todo{}, err
+	}
+
+	// イベントが無い場合はTodoCreatedとして取り込む
+	if aggregate.Version == 0 {
+		created := row
+		created.DeletedAt = nil
+		event, err := domain_todo.NewTodoCreatedEvent(created)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to create todo event: %v", err)
+			return todoAggregate{}, domain_todo.Todo{}, err
+		}
+		aggregate, err = r.appendEvents(tx, aggregate, []domain_todo.TodoEvent{event}, domain_audit.SystemActorId)
+		if err != nil {
+			return todoAggregate{}, domain_todo.Todo{}, err
+		}
+	}
+
+	// 読み取りモデルとの差分を取り込む
+	events, err := domain_todo.DiffTodoEvents(aggregate.State, row, row.UpdatedAt)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to diff todo: %v", err)
+		return todoAggregate{}, domain_todo.Todo{}, err
+	}
+	if len(events) > 0 {
+		r.Logger.InfoLog.Printf("Adopting %d untracked changes of todo: %v", len(events), id)
+		aggregate, err = r.appendEvents(tx, aggregate, events, domain_audit.SystemActorId)
+		if err != nil {
+			return todoAggregate{}, domain_todo.Todo{}, err
+		}
+	}
+
+	return aggregate, row, nil
+}
+
+// スナップショットとイベントからTodoの集約を読み込む(useSnapshotsがfalseの場合は最初のイベントから再生する)
+func (r *EventSourcedTodoRepositoryImpl) loadAggregate(q todoQuerier, id string, useSnapshots bool) (todoAggregate, error) {
+	snapshotQuery := `
+		SELECT version, state
+		FROM todo_snapshots
+		WHERE aggregate_id = $1
+	`
+
+	// スナップショットを取得
+	aggregate := todoAggregate{}
+	if useSnapshots {
+		var state []byte
+		err := q.QueryRow(r.SupabaseClient.Ctx, snapshotQuery, id).Scan(&aggregate.Version, &state)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			r.Logger.ErrorLog.Printf("Failed to fetch todo snapshot: %v", err)
+			return todoAggregate{}, err
+		}
+		if err == nil {
+			err = json.Unmarshal(state, &aggregate.State)
+			if err != nil {
+				r.Logger.ErrorLog.Printf("Failed to decode todo snapshot: %v", err)
+				return todoAggregate{}, err
+			}
+		}
+	}
+
+	// スナップショット以降のイベントを適用
+	events, err := r.queryEvents(q, id, aggregate.Version)
+	if err != nil {
+		return todoAggregate{}, err
+	}
+	for _, event := range events {
+		aggregate, err = r.applyEvent(aggregate, event)
+		if err != nil {
+			return todoAggregate{}, err
+		}
+	}
+
+	return aggregate, nil
+}
+
+// イベントを集約に適用
+func (r *EventSourcedTodoRepositoryImpl) applyEvent(aggregate todoAggregate, event domain_todo.TodoEvent) (todoAggregate, error) {
+	state, err := domain_todo.ApplyTodoEvent(aggregate.State, event)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to apply todo event: %v", err)
+		return todoAggregate{}, err
+	}
+	return todoAggregate{
+		State:   state,
+		Version: event.Version,
+		Purged:  aggregate.Purged || event.Type == domain_todo.TodoEventPurged,
+	}, nil
+}
+
+// トランザクション内でTodoのIDを取得
+func (r *EventSourcedTodoRepositoryImpl) queryTodoIds(tx pgx.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(r.SupabaseClient.Ctx, query, args...)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo id: %v", err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return nil, err
+	}
+	return ids, nil
+}
+
+// イベントをバージョン順に取得
+func (r *EventSourcedTodoRepositoryImpl) queryEvents(q todoQuerier, id string, afterVersion int) ([]domain_todo.TodoEvent, error) {
+	query := `
+		SELECT seq, aggregate_id, version, type, payload, actor_id, occurred_at
+		FROM todo_events
+		WHERE aggregate_id = $1 AND version > $2
+		ORDER BY version
+	`
+
+	rows, err := q.Query(r.SupabaseClient.Ctx, query, id, afterVersion)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo events: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain_todo.TodoEvent{}
+	for rows.Next() {
+		var event domain_todo.TodoEvent
+		err = rows.Scan(
+			&event.Seq,
+			&event.AggregateId,
+			&event.Version,
+			&event.Type,
+			&event.Payload,
+			&event.ActorId,
+			&event.OccurredAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo event: %v", err)
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo events: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// 状態の差分をイベントとして追記し、読み取りモデルに投影して監査ログを記録
+// 差分が無い場合は何も記録せずに変更前のTodoを返す。
+func (r *EventSourcedTodoRepositoryImpl) changeTodo(tx pgx.Tx, aggregate todoAggregate, before domain_todo.Todo, desired domain_todo.Todo, actor domain_audit.AuditActor, action string) (domain_todo.Todo, error) {
+	todo, changed, err := r.recordChange(tx, aggregate, desired, actor.UserId)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+	if !changed {
+		return before, nil
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, action, domain_audit.AuditEntityTodo, todo.ID, before, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	return todo, nil
+}
+
+// 状態の差分をイベントとして追記し、読み取りモデルに投影(監査ログは記録しない)
+// 差分が無い場合は何も記録せずにfalseを返す。
+func (r *EventSourcedTodoRepositoryImpl) recordChange(tx pgx.Tx, aggregate todoAggregate, desired domain_todo.Todo, actorId string) (domain_todo.Todo, bool, error) {
+	events, err := domain_todo.DiffTodoEvents(aggregate.State, desired, time.Now())
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to diff todo: %v", err)
+		return domain_todo.Todo{}, false, err
+	}
+	if len(events) == 0 {
+		return aggregate.State, false, nil
+	}
+
+	// イベントを追記
+	aggregate, err = r.appendEvents(tx, aggregate, events, actorId)
+	if err != nil {
+		return domain_todo.Todo{}, false, err
+	}
+
+	// 読み取りモデルに投影
+	todo, err := r.project(tx, aggregate.State, false)
+	if err != nil {
+		return domain_todo.Todo{}, false, err
+	}
+	return todo, true, nil
+}
+
+// イベントにバージョンを採番して追記し、適用後の集約を返す
+// スナップショットの間隔を跨いだ場合はスナップショットを保存する。
+func (r *EventSourcedTodoRepositoryImpl) appendEvents(tx pgx.Tx, aggregate todoAggregate, events []domain_todo.TodoEvent, actorId string) (todoAggregate, error) {
+	query := `
+		INSERT INTO todo_events (aggregate_id, version, type, payload, actor_id, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING seq
+	`
+
+	startVersion := aggregate.Version
+	for _, event := range events {
+		event.Version = aggregate.Version + 1
+		event.ActorId = actorId
+
+		var err error
+		aggregate, err = r.applyEvent(aggregate, event)
+		if err != nil {
+			return todoAggregate{}, err
+		}
+
+		err = tx.QueryRow(r.SupabaseClient.Ctx, query, event.AggregateId, event.Version, event.Type, string(event.Payload), event.ActorId, event.OccurredAt).
+			Scan(&event.Seq)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to append todo event: %v", err)
+			return todoAggregate{}, toTodoEventError(err)
+		}
+	}
+
+	// スナップショットを保存
+	if r.SnapshotInterval > 0 && !aggregate.Purged && aggregate.Version/r.SnapshotInterval > startVersion/r.SnapshotInterval {
+		err := r.saveSnapshot(tx, aggregate)
+		if err != nil {
+			return todoAggregate{}, err
+		}
+	}
+
+	return aggregate, nil
+}
+
+// スナップショットを保存(Todoごとに最新の1件のみ保持する)
+func (r *EventSourcedTodoRepositoryImpl) saveSnapshot(tx pgx.Tx, aggregate todoAggregate) error {
+	query := `
+		INSERT INTO todo_snapshots (aggregate_id, version, state)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (aggregate_id) DO UPDATE
+		SET version = EXCLUDED.version, state = EXCLUDED.state, created_at = now()
+	`
+
+	state, err := json.Marshal(aggregate.State)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to encode todo snapshot: %v", err)
+		return err
+	}
+
+	_, err = tx.Exec(r.SupabaseClient.Ctx, query, aggregate.State.ID, aggregate.Version, string(state))
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to save todo snapshot: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Saved todo snapshot: %v (version %d)", aggregate.State.ID, aggregate.Version)
+	return nil
+}
+
+// 集約の状態を読み取りモデル(todosテーブル)に投影
+// markPastRemindersがtrueの場合、通知済みでないTodoのうちリマインド日時を過ぎたものは通知済みにする(再構築時に過去のリマインドを再通知しない)。
+// 参照先のTodoリスト・親Todoが存在しない場合は、外部キーのON DELETE SET NULLと同様にnilにする。
+// 並び順のキーがイベントに無い場合(並び順をイベントにする前のTodo)は、既存の行のキーを変更しない。
+func (r *EventSourcedTodoRepositoryImpl) project(tx pgx.Tx, state domain_todo.Todo, markPastReminders bool) (domain_todo.Todo, error) {
+	query := `
+		INSERT INTO todos (id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+		        COALESCE($15, CASE WHEN $13 AND $10 <= now() THEN now() END),
+		        (SELECT l.id FROM todo_lists l WHERE l.id = $11),
+		        (SELECT p.id FROM todos p WHERE p.id = $12),
+		        $14)
+		ON CONFLICT (id) DO UPDATE
+		SET description = EXCLUDED.description, completed = EXCLUDED.completed, user_id = EXCLUDED.user_id,
+		    created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at, deleted_at = EXCLUDED.deleted_at,
+		    due_at = EXCLUDED.due_at, priority = EXCLUDED.priority, remind_at = EXCLUDED.remind_at,
+		    reminded_at = EXCLUDED.reminded_at,
+		    list_id = EXCLUDED.list_id, parent_id = EXCLUDED.parent_id,
+		    position = COALESCE(EXCLUDED.position, todos.position)
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	var todo domain_todo.Todo
+	err := tx.QueryRow(r.SupabaseClient.Ctx, query,
+		state.ID, state.Description, state.Completed, state.UserId, state.CreatedAt, state.UpdatedAt, state.DeletedAt,
+		state.DueAt, state.Priority, state.RemindAt, state.ListId, state.ParentId, markPastReminders, state.Position, state.RemindedAt).
+		Scan(&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to project todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	return todo, nil
+}
+
+// イベント追記時のDBエラーをリポジトリのエラーに変換
+func toTodoEventError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return repository_todo.ErrTodoVersionConflict
+	}
+	return err
+}
@@ -0,0 +1,238 @@
+package infrastructure_todo
+
+import (
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_todo "backend/internal/repository/todo"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+func newTestEventSourcedRepository() *EventSourcedTodoRepositoryImpl {
+	return newEventSourcedTodoRepository(newTestLogger(), &pkg_supabase.SupabaseClient{Ctx: context.Background()}, 0)
+}
+
+// イベントストアとスナップショットのみを持つクエリの実行先(読み取りモデルは持たない)
+type fakeEventQuerier struct {
+	events   []domain_todo.TodoEvent
+	snapshot *domain_todo.TodoSnapshot
+	queries  []string
+}
+
+func (q *fakeEventQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	q.queries = append(q.queries, sql)
+	if !strings.Contains(sql, "FROM todo_events") {
+		return nil, errors.New("unexpected query: " + sql)
+	}
+	afterVersion := args[1].(int)
+	rows := &fakeEventRows{}
+	for _, event := range q.events {
+		if event.AggregateId == args[0].(string) && event.Version > afterVersion {
+			rows.events = append(rows.events, event)
+		}
+	}
+	return rows, nil
+}
+
+func (q *fakeEventQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	q.queries = append(q.queries, sql)
+	if !strings.Contains(sql, "FROM todo_snapshots") {
+		return fakeRow{err: errors.New("unexpected query: " + sql)}
+	}
+	if q.snapshot == nil || q.snapshot.AggregateId != args[0].(string) {
+		return fakeRow{err: pgx.ErrNoRows}
+	}
+	state, err := json.Marshal(q.snapshot.State)
+	return fakeRow{values: []interface{}{q.snapshot.Version, state}, err: err}
+}
+
+type fakeRow struct {
+	values []interface{}
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*int) = r.values[0].(int)
+	*dest[1].(*[]byte) = r.values[1].([]byte)
+	return nil
+}
+
+type fakeEventRows struct {
+	pgx.Rows
+	events []domain_todo.TodoEvent
+	index  int
+}
+
+func (r *fakeEventRows) Next() bool {
+	r.index++
+	return r.index <= len(r.events)
+}
+
+func (r *fakeEventRows) Scan(dest ...interface{}) error {
+	event := r.events[r.index-1]
+	*dest[0].(*int64) = event.Seq
+	*dest[1].(*string) = event.AggregateId
+	*dest[2].(*int) = event.Version
+	*dest[3].(*string) = event.Type
+	*dest[4].(*json.RawMessage) = event.Payload
+	*dest[5].(*string) = event.ActorId
+	*dest[6].(*time.Time) = event.OccurredAt
+	return nil
+}
+
+func (r *fakeEventRows) Err() error { return nil }
+func (r *fakeEventRows) Close()     {}
+
+// テスト用のイベント列(作成 → 名前の変更 → 完了 → 並び順の変更)
+func testTodoEvents(t *testing.T) []domain_todo.TodoEvent {
+	t.Helper()
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	position := "a0"
+	todo := domain_todo.Todo{ID: "todo-1", Description: "draft", UserId: "user-1", CreatedAt: created, UpdatedAt: created, Priority: domain_todo.TodoPriorityMedium, Position: &position}
+
+	first, err := domain_todo.NewTodoCreatedEvent(todo)
+	if err != nil {
+		t.Fatalf("NewTodoCreatedEvent() unexpected error: %v", err)
+	}
+	events := []domain_todo.TodoEvent{first}
+	next := []struct {
+		eventType string
+		payload   interface{}
+	}{
+		{domain_todo.TodoEventRenamed, domain_todo.TodoRenamedPayload{Description: "final"}},
+		{domain_todo.TodoEventCompleted, nil},
+		{domain_todo.TodoEventReordered, domain_todo.TodoReorderedPayload{Position: "a5"}},
+	}
+	for i, n := range next {
+		event, err := domain_todo.NewTodoEvent(todo.ID, n.eventType, n.payload, created.Add(time.Duration(i+1)*time.Hour))
+		if err != nil {
+			t.Fatalf("NewTodoEvent() unexpected error: %v", err)
+		}
+		events = append(events, event)
+	}
+	for i := range events {
+		events[i].Version = i + 1
+		events[i].Seq = int64(i + 1)
+	}
+	return events
+}
+
+// 読み取りモデルを参照せず、イベントのみから集約を組み立てる
+func TestLoadAggregateReplaysEvents(t *testing.T) {
+	r := newTestEventSourcedRepository()
+	q := &fakeEventQuerier{events: testTodoEvents(t)}
+
+	aggregate, err := r.loadAggregate(q, "todo-1", false)
+	if err != nil {
+		t.Fatalf("loadAggregate() unexpected error: %v", err)
+	}
+	state := aggregate.State
+	if aggregate.Version != 4 || aggregate.Purged {
+		t.Errorf("aggregate version = %d, purged = %v, want 4, false", aggregate.Version, aggregate.Purged)
+	}
+	if state.Description != "final" || !state.Completed || state.Position == nil || *state.Position != "a5" {
+		t.Errorf("state = %+v, want final, completed, position a5", state)
+	}
+	for _, sql := range q.queries {
+		if strings.Contains(sql, "FROM todos") {
+			t.Errorf("loadAggregate read the read model: %s", sql)
+		}
+	}
+}
+
+// スナップショットがある場合はスナップショット以降のイベントのみ適用し、useSnapshotsがfalseの場合は使わない
+func TestLoadAggregateSnapshots(t *testing.T) {
+	events := testTodoEvents(t)
+	snapshotState := domain_todo.Todo{ID: "todo-1", Description: "from snapshot", UserId: "user-1", Priority: domain_todo.TodoPriorityLow}
+	q := &fakeEventQuerier{
+		events:   events,
+		snapshot: &domain_todo.TodoSnapshot{AggregateId: "todo-1", Version: 2, State: snapshotState},
+	}
+	r := newTestEventSourcedRepository()
+
+	tests := []struct {
+		name            string
+		useSnapshots    bool
+		wantDescription string
+		wantPriority    string
+	}{
+		{name: "with snapshots", useSnapshots: true, wantDescription: "from snapshot", wantPriority: domain_todo.TodoPriorityLow},
+		{name: "without snapshots", useSnapshots: false, wantDescription: "final", wantPriority: domain_todo.TodoPriorityMedium},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregate, err := r.loadAggregate(q, "todo-1", tt.useSnapshots)
+			if err != nil {
+				t.Fatalf("loadAggregate() unexpected error: %v", err)
+			}
+			if aggregate.Version != 4 {
+				t.Errorf("Version = %d, want 4", aggregate.Version)
+			}
+			if aggregate.State.Description != tt.wantDescription || aggregate.State.Priority != tt.wantPriority {
+				t.Errorf("state = %+v, want description %q priority %q", aggregate.State, tt.wantDescription, tt.wantPriority)
+			}
+			// スナップショット以降のイベント(完了・並び順の変更)は適用される
+			if !aggregate.State.Completed || aggregate.State.Position == nil || *aggregate.State.Position != "a5" {
+				t.Errorf("state = %+v, want completed with position a5", aggregate.State)
+			}
+		})
+	}
+}
+
+// 完全に削除された後もイベントは残り、集約は削除済みになる
+func TestApplyEventPurged(t *testing.T) {
+	r := newTestEventSourcedRepository()
+	events := testTodoEvents(t)
+	purged, err := domain_todo.NewTodoEvent("todo-1", domain_todo.TodoEventPurged, nil, time.Now())
+	if err != nil {
+		t.Fatalf("NewTodoEvent() unexpected error: %v", err)
+	}
+	purged.Version = len(events) + 1
+	events = append(events, purged)
+
+	aggregate, err := r.loadAggregate(&fakeEventQuerier{events: events}, "todo-1", true)
+	if err != nil {
+		t.Fatalf("loadAggregate() unexpected error: %v", err)
+	}
+	if !aggregate.Purged || aggregate.Version != 5 {
+		t.Errorf("aggregate purged = %v, version = %d, want true, 5", aggregate.Purged, aggregate.Version)
+	}
+}
+
+func TestApplyEventUnknownType(t *testing.T) {
+	r := newTestEventSourcedRepository()
+	_, err := r.applyEvent(todoAggregate{}, domain_todo.TodoEvent{AggregateId: "todo-1", Version: 1, Type: "TodoArchived", Payload: json.RawMessage(`{}`)})
+	if err == nil {
+		t.Error("applyEvent() with unknown type: want error")
+	}
+}
+
+// 同じバージョンの二重追記はバージョンの競合として返す
+func TestToTodoEventError(t *testing.T) {
+	if err := toTodoEventError(&pgconn.PgError{Code: uniqueViolationCode}); !errors.Is(err, repository_todo.ErrTodoVersionConflict) {
+		t.Errorf("unique violation: error = %v, want %v", err, repository_todo.ErrTodoVersionConflict)
+	}
+	other := &pgconn.PgError{Code: "23503"}
+	if err := toTodoEventError(other); err != other {
+		t.Errorf("other error = %v, want %v", err, other)
+	}
+}
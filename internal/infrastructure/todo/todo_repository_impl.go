@@ -1,10 +1,18 @@
 package infrastructure_todo
 
 import (
+	domain_audit "backend/internal/domain/audit"
+	domain_share "backend/internal/domain/share"
 	domain_todo "backend/internal/domain/todo"
+	infrastructure_audit "backend/internal/infrastructure/audit"
+	pkg_fracindex "backend/internal/pkg/fracindex"
 	pkg_logger "backend/internal/pkg/logger"
 	pkg_supabase "backend/internal/pkg/supabase"
 	repository_todo "backend/internal/repository/todo"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
 )
 
 // Todoリポジトリ(Impl)
@@ -21,17 +29,100 @@ func NewTodoRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient)
 	}
 }
 
-// 全てのTodoを取得
-func (r *TodoRepositoryImpl) GetAllTodos() ([]domain_todo.Todo, error) {
-	r.Logger.InfoLog.Println("GetAllTodos called")
+// ユーザーが閲覧できるTodoを抽出するCTE
+// 自分のTodoと、承諾済みの共有(所有者単位、またはTodoとその子孫)で閲覧できるTodoを対象とする。
+const visibleTodosCTE = `
+	WITH RECURSIVE visible (id) AS (
+		SELECT t.id FROM todos t
+		WHERE t.deleted_at IS NULL
+		  AND (t.user_id = $1 OR EXISTS (
+		    SELECT 1 FROM todo_shares s
+		    WHERE s.member_id = $1 AND s.status = 'accepted' AND s.owner_id = t.user_id
+		      AND (s.todo_id IS NULL OR s.todo_id = t.id)
+		  ))
+		UNION
+		SELECT c.id FROM todos c JOIN visible v ON c.parent_id = v.id
+		WHERE c.deleted_at IS NULL
+	)
+`
+
+// ユーザーが閲覧できる(自分の、または共有された)Todoを取得
+func (r *TodoRepositoryImpl) GetVisibleTodos(viewerId string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetVisibleTodos called")
+
+	query := visibleTodosCTE + `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id IN (SELECT id FROM visible)
+		ORDER BY position, created_at, id
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, viewerId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch visible todos: %v", err)
+		return nil, err
+	}
+
+	todos, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d visible todos", len(todos))
+	return todos, nil
+}
+
+// 特定のTodoを取得
+func (r *TodoRepositoryImpl) GetTodoById(id string) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetTodoById called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
+	var todo domain_todo.Todo
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched todo: %v", todo)
+	return todo, nil
+}
+
+// 特定のユーザーのTodoを取得
+func (r *TodoRepositoryImpl) GetTodoByUserId(userId string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetTodoByUserId called")
 
 	query := `
-		SELECT id, description, completed, user_id, created_at, updated_at
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
 		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY position, created_at, id
 	`
 
 	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
-	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query)
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId)
 	if err != nil {
 		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
 		return nil, err
@@ -48,6 +139,14 @@ func (r *TodoRepositoryImpl) GetAllTodos() ([]domain_todo.Todo, error) {
 			&todo.UserId,
 			&todo.CreatedAt,
 			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
 		)
 		if err != nil {
 			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
@@ -60,51 +159,171 @@ func (r *TodoRepositoryImpl) GetAllTodos() ([]domain_todo.Todo, error) {
 	return todos, nil
 }
 
-// 特定のTodoを取得
-func (r *TodoRepositoryImpl) GetTodoById(id string) (domain_todo.Todo, error) {
-	r.Logger.InfoLog.Println("GetTodoById called")
+// 特定のユーザーのTodoを並び順に1件ずつ読み込み、fnを呼び出す(エクスポート用、fnがエラーを返した場合は中断する)
+func (r *TodoRepositoryImpl) ForEachTodoByUserId(userId string, fn func(todo domain_todo.Todo) error) error {
+	r.Logger.InfoLog.Println("ForEachTodoByUserId called")
 
 	query := `
-		SELECT id, description, completed, user_id, created_at, updated_at
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
 		FROM todos
-		WHERE id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY position, created_at, id
 	`
 
-	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
-	var todo domain_todo.Todo
-	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id).
-		Scan(&todo.ID,
+	// Supabaseからクエリを実行し、全件をメモリに載せずに1行ずつ処理する
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var todo domain_todo.Todo
+		err = rows.Scan(
+			&todo.ID,
 			&todo.Description,
 			&todo.Completed,
 			&todo.UserId,
 			&todo.CreatedAt,
 			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
 		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
+			return err
+		}
+		if err = fn(todo); err != nil {
+			r.Logger.ErrorLog.Printf("Stopped reading todos: %v", err)
+			return err
+		}
+		count++
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return err
+	}
+
+	r.Logger.InfoLog.Printf("Read %d todos", count)
+	return nil
+}
+
+// 複数のユーザーのTodoのうち、viewerIdのユーザーが閲覧できるものを取得
+func (r *TodoRepositoryImpl) GetVisibleTodosByUserIds(viewerId string, userIds []string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetVisibleTodosByUserIds called")
+
+	query := visibleTodosCTE + `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE user_id = ANY($2) AND id IN (SELECT id FROM visible)
+		ORDER BY position, created_at, id
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, viewerId, userIds)
 	if err != nil {
-		r.Logger.ErrorLog.Printf("Failed to fetch todo: %v", err)
-		return domain_todo.Todo{}, err
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return nil, err
 	}
 
-	r.Logger.InfoLog.Printf("Fetched todo: %v", todo)
-	return todo, nil
+	todos, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d todos", len(todos))
+	return todos, nil
 }
 
-// 特定のユーザーのTodoを取得
-func (r *TodoRepositoryImpl) GetTodoByUserId(userId string) ([]domain_todo.Todo, error) {
-	r.Logger.InfoLog.Println("GetTodoByUserId called")
+// 複数のTodoのうち、viewerIdのユーザーが閲覧できるもののIDを取得
+func (r *TodoRepositoryImpl) GetVisibleTodoIds(viewerId string, ids []string) ([]string, error) {
+	r.Logger.InfoLog.Println("GetVisibleTodoIds called")
+
+	query := visibleTodosCTE + `
+		SELECT id
+		FROM visible
+		WHERE id = ANY($2)
+	`
+
+	// Supabaseからクエリを実行し、閲覧できるTodoのIDを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, viewerId, ids)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch visible todo ids: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	visibleIds := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo id: %v", err)
+			return nil, err
+		}
+		visibleIds = append(visibleIds, id)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch visible todo ids: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d visible todo ids", len(visibleIds))
+	return visibleIds, nil
+}
+
+// 複数のTodoリストに属するTodoのうち、viewerIdのユーザーが閲覧できるものを取得
+func (r *TodoRepositoryImpl) GetVisibleTodosByListIds(viewerId string, listIds []string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetVisibleTodosByListIds called")
+
+	query := visibleTodosCTE + `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE list_id = ANY($2) AND id IN (SELECT id FROM visible)
+		ORDER BY position, created_at, id
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, viewerId, listIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return nil, err
+	}
+
+	todos, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d todos", len(todos))
+	return todos, nil
+}
+
+// 特定のユーザーのインボックス(どのリストにも属さない)のTodoを取得
+func (r *TodoRepositoryImpl) GetInboxTodosByUserId(userId string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetInboxTodosByUserId called")
 
 	query := `
-		SELECT id, description, completed, user_id, created_at, updated_at
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
 		FROM todos
-		WHERE user_id = $1
+		WHERE user_id = $1 AND list_id IS NULL AND deleted_at IS NULL
+		ORDER BY position, created_at, id
 	`
 
-	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
 	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId)
 	if err != nil {
 		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
 		return nil, err
 	}
+	defer rows.Close()
 
 	// Todosのリストを作成
 	todos := []domain_todo.Todo{}
@@ -117,6 +336,14 @@ func (r *TodoRepositoryImpl) GetTodoByUserId(userId string) ([]domain_todo.Todo,
 			&todo.UserId,
 			&todo.CreatedAt,
 			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
 		)
 		if err != nil {
 			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
@@ -129,17 +356,24 @@ func (r *TodoRepositoryImpl) GetTodoByUserId(userId string) ([]domain_todo.Todo,
 	return todos, nil
 }
 
-// 新しいTodoを作成
-func (r *TodoRepositoryImpl) CreateTodo(todo domain_todo.Todo) (domain_todo.Todo, error) {
-	r.Logger.InfoLog.Println("CreateTodo called")
+// Todoを別のリストに移動(listIdがnilの場合はインボックス、監査ログを同一トランザクションで記録する)
+func (r *TodoRepositoryImpl) MoveTodoToList(id string, listId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("MoveTodoToList called")
 
+	beforeQuery := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
 	query := `
-		INSERT INTO todos (description, completed, user_id)
-		VALUES ($1, $2, $3)
-		RETURNING id, description, completed, user_id, created_at, updated_at
+		UPDATE todos
+		SET list_id = $1, updated_at = now()
+		WHERE id = $2
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
 	`
 
-	// トランザクション開始
+	// トランザクションを開始
 	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
 	if err != nil {
 		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
@@ -152,17 +386,56 @@ func (r *TodoRepositoryImpl) CreateTodo(todo domain_todo.Todo) (domain_todo.Todo
 		}
 	}()
 
-	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
-	err = tx.QueryRow(r.SupabaseClient.Ctx, query, todo.Description, todo.Completed, todo.UserId).
+	// 変更前のTodoを取得(行ロックを取得する)
+	var before domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, beforeQuery, id).
+		Scan(&before.ID,
+			&before.Description,
+			&before.Completed,
+			&before.UserId,
+			&before.CreatedAt,
+			&before.UpdatedAt,
+			&before.DeletedAt,
+			&before.DueAt,
+			&before.Priority,
+			&before.RemindAt,
+			&before.RemindedAt,
+			&before.ListId,
+			&before.ParentId,
+			&before.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// Supabaseからクエリを実行し、移動したTodoを取得
+	var todo domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query, listId, id).
 		Scan(&todo.ID,
 			&todo.Description,
 			&todo.Completed,
 			&todo.UserId,
 			&todo.CreatedAt,
 			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
 		)
 	if err != nil {
-		r.Logger.ErrorLog.Printf("Failed to create todo: %v", err)
+		r.Logger.ErrorLog.Printf("Failed to move todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionUpdate, domain_audit.AuditEntityTodo, todo.ID, before, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
 		return domain_todo.Todo{}, err
 	}
 
@@ -176,22 +449,21 @@ func (r *TodoRepositoryImpl) CreateTodo(todo domain_todo.Todo) (domain_todo.Todo
 	// 正常系にし、ロールバックを防ぐ
 	err = nil
 
-	r.Logger.InfoLog.Printf("Created todo: %v", todo)
+	r.Logger.InfoLog.Printf("Moved todo: %v", todo)
 	return todo, nil
 }
 
-// 特定のTodoを更新
-func (r *TodoRepositoryImpl) UpdateTodo(todo domain_todo.Todo) (domain_todo.Todo, error) {
-	r.Logger.InfoLog.Println("UpdateTodo called")
+// 新しいTodoを作成
+func (r *TodoRepositoryImpl) CreateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("CreateTodo called")
 
 	query := `
-		UPDATE todos
-		SET description = $1, completed = $2, user_id = $3, created_at = $4, updated_at = $5
-		WHERE id = $6
-		RETURNING id, description, completed, user_id, created_at, updated_at
+		INSERT INTO todos (description, completed, user_id, due_at, priority, remind_at, list_id, parent_id, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
 	`
 
-	// トランザクションを開始
+	// トランザクション開始
 	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
 	if err != nil {
 		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
@@ -205,16 +477,31 @@ func (r *TodoRepositoryImpl) UpdateTodo(todo domain_todo.Todo) (domain_todo.Todo
 	}()
 
 	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
-	err = tx.QueryRow(r.SupabaseClient.Ctx, query, todo.Description, todo.Completed, todo.UserId, todo.CreatedAt, todo.UpdatedAt, todo.ID).
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query, todo.Description, todo.Completed, todo.UserId, todo.DueAt, todo.Priority, todo.RemindAt, todo.ListId, todo.ParentId, todo.Position).
 		Scan(&todo.ID,
 			&todo.Description,
 			&todo.Completed,
 			&todo.UserId,
 			&todo.CreatedAt,
 			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
 		)
 	if err != nil {
-		r.Logger.ErrorLog.Printf("Failed to update todo: %v", err)
+		r.Logger.ErrorLog.Printf("Failed to create todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionCreate, domain_audit.AuditEntityTodo, todo.ID, nil, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
 		return domain_todo.Todo{}, err
 	}
 
@@ -228,24 +515,102 @@ func (r *TodoRepositoryImpl) UpdateTodo(todo domain_todo.Todo) (domain_todo.Todo
 	// 正常系にし、ロールバックを防ぐ
 	err = nil
 
-	r.Logger.InfoLog.Printf("Updated todo: %v", todo)
+	r.Logger.InfoLog.Printf("Created todo: %v", todo)
 	return todo, nil
 }
 
-// 特定のTodoを削除
-func (r *TodoRepositoryImpl) DeleteTodo(id string) error {
-	r.Logger.InfoLog.Println("DeleteTodo called")
+// 複数のTodoを1つのトランザクションで作成
+// parentIndexes[i]はtodos[i]の親のtodos内の位置で、親は子より前に並べる(-1の場合はParentIdをそのまま使う)。
+func (r *TodoRepositoryImpl) ImportTodos(todos []domain_todo.Todo, parentIndexes []int, actor domain_audit.AuditActor) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("ImportTodos called")
+
+	// トランザクション開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	created := make([]domain_todo.Todo, 0, len(todos))
+	for i, todo := range todos {
+		// ファイル内の親は先に作成済みのため、採番されたIDを親にする
+		if parentIndexes[i] >= 0 {
+			todo.ParentId = &created[parentIndexes[i]].ID
+		}
+		todo, err = r.insertTodoInTx(tx, todo)
+		if err != nil {
+			return nil, err
+		}
+
+		// 監査ログを記録
+		err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionCreate, domain_audit.AuditEntityTodo, todo.ID, nil, todo)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+			return nil, err
+		}
+		created = append(created, todo)
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
 
+	r.Logger.InfoLog.Printf("Imported %d todos", len(created))
+	return created, nil
+}
+
+// トランザクション内でTodoの行を作成し、IDと作成日時を採番する
+func (r *TodoRepositoryImpl) insertTodoInTx(tx pgx.Tx, todo domain_todo.Todo) (domain_todo.Todo, error) {
 	query := `
-		DELETE FROM todos
-		WHERE id = $1
+		INSERT INTO todos (description, completed, user_id, due_at, priority, remind_at, list_id, parent_id, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
 	`
 
+	err := tx.QueryRow(r.SupabaseClient.Ctx, query, todo.Description, todo.Completed, todo.UserId, todo.DueAt, todo.Priority, todo.RemindAt, todo.ListId, todo.ParentId, todo.Position).
+		Scan(&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	return todo, nil
+}
+
+// 特定のTodoを更新
+func (r *TodoRepositoryImpl) UpdateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("UpdateTodo called")
+
 	// トランザクションを開始
 	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
 	if err != nil {
 		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
-		return err
+		return domain_todo.Todo{}, err
 	}
 	defer func() {
 		if err != nil {
@@ -254,23 +619,1698 @@ func (r *TodoRepositoryImpl) DeleteTodo(id string) error {
 		}
 	}()
 
-	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
-	_, err = tx.Exec(r.SupabaseClient.Ctx, query, id)
+	// Todoを更新
+	todo, err = r.updateTodoInTx(tx, todo, actor)
 	if err != nil {
-		r.Logger.ErrorLog.Printf("Failed to delete todo: %v", err)
-		return err
+		return domain_todo.Todo{}, err
 	}
 
 	// トランザクションをコミット
 	err = tx.Commit(r.SupabaseClient.Ctx)
 	if err != nil {
 		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
-		return err
+		return domain_todo.Todo{}, err
 	}
 
 	// 正常系にし、ロールバックを防ぐ
 	err = nil
 
-	r.Logger.InfoLog.Printf("Deleted todo: %v", id)
-	return nil
+	r.Logger.InfoLog.Printf("Updated todo: %v", todo)
+	return todo, nil
+}
+
+// 特定のTodoを更新し、未完了のサブタスク(子孫)を全て完了にする(監査ログを同一トランザクションで記録する)
+func (r *TodoRepositoryImpl) UpdateTodoAndCompleteSubtasks(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, int, error) {
+	r.Logger.InfoLog.Println("UpdateTodoAndCompleteSubtasks called")
+
+	selectQuery := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM todos WHERE parent_id = $1 AND deleted_at IS NULL
+			UNION
+			SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+		)
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id IN (SELECT id FROM subtree) AND NOT completed
+		FOR UPDATE
+	`
+	query := `
+		UPDATE todos
+		SET completed = true, updated_at = now()
+		WHERE id = ANY($1)
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, 0, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// Todoを更新
+	todo, err = r.updateTodoInTx(tx, todo, actor)
+	if err != nil {
+		return domain_todo.Todo{}, 0, err
+	}
+
+	// 未完了のサブタスクを取得(行ロック)
+	rows, err := tx.Query(r.SupabaseClient.Ctx, selectQuery, todo.ID)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch subtasks: %v", err)
+		return domain_todo.Todo{}, 0, err
+	}
+	befores, err := r.scanTodoRows(rows)
+	if err != nil {
+		return domain_todo.Todo{}, 0, err
+	}
+	beforeById := make(map[string]domain_todo.Todo, len(befores))
+	ids := make([]string, 0, len(befores))
+	for _, t := range befores {
+		beforeById[t.ID] = t
+		ids = append(ids, t.ID)
+	}
+
+	// サブタスクを完了にする
+	rows, err = tx.Query(r.SupabaseClient.Ctx, query, ids)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to complete subtasks: %v", err)
+		return domain_todo.Todo{}, 0, err
+	}
+	afters, err := r.scanTodoRows(rows)
+	if err != nil {
+		return domain_todo.Todo{}, 0, err
+	}
+
+	// 監査ログを記録
+	for _, after := range afters {
+		err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionUpdate, domain_audit.AuditEntityTodo, after.ID, beforeById[after.ID], after)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+			return domain_todo.Todo{}, 0, err
+		}
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, 0, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Updated todo: %v (%d subtasks completed)", todo, len(afters))
+	return todo, len(afters), nil
+}
+
+// トランザクション内でTodoを更新し、監査ログを記録
+func (r *TodoRepositoryImpl) updateTodoInTx(tx pgx.Tx, todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	selectQuery := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	query := `
+		UPDATE todos
+		SET description = $1, completed = $2, user_id = $3, created_at = $4, updated_at = $5,
+		    due_at = $7, priority = $8, remind_at = $9,
+		    reminded_at = CASE WHEN remind_at IS DISTINCT FROM $9 THEN NULL ELSE reminded_at END
+		WHERE id = $6 AND deleted_at IS NULL
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// 変更前のTodoを取得(行ロック)
+	var before domain_todo.Todo
+	err := tx.QueryRow(r.SupabaseClient.Ctx, selectQuery, todo.ID).
+		Scan(&before.ID,
+			&before.Description,
+			&before.Completed,
+			&before.UserId,
+			&before.CreatedAt,
+			&before.UpdatedAt,
+			&before.DeletedAt,
+			&before.DueAt,
+			&before.Priority,
+			&before.RemindAt,
+			&before.RemindedAt,
+			&before.ListId,
+			&before.ParentId,
+			&before.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query, todo.Description, todo.Completed, todo.UserId, todo.CreatedAt, todo.UpdatedAt, todo.ID, todo.DueAt, todo.Priority, todo.RemindAt).
+		Scan(&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to update todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionUpdate, domain_audit.AuditEntityTodo, todo.ID, before, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	return todo, nil
+}
+
+// 特定のTodoを削除(ゴミ箱に移動)
+func (r *TodoRepositoryImpl) DeleteTodo(id string, actor domain_audit.AuditActor) error {
+	r.Logger.InfoLog.Println("DeleteTodo called")
+
+	query := `
+		UPDATE todos
+		SET deleted_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// Supabaseからクエリを実行し、削除したTodoを取得
+	var after domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&after.ID,
+			&after.Description,
+			&after.Completed,
+			&after.UserId,
+			&after.CreatedAt,
+			&after.UpdatedAt,
+			&after.DeletedAt,
+			&after.DueAt,
+			&after.Priority,
+			&after.RemindAt,
+			&after.RemindedAt,
+			&after.ListId,
+			&after.ParentId,
+			&after.Position,
+		)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// 削除対象が無い場合は何もしない(監査ログも記録しない)
+		err = tx.Rollback(r.SupabaseClient.Ctx)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			return err
+		}
+		r.Logger.InfoLog.Printf("Todo not found: %v", id)
+		return nil
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete todo: %v", err)
+		return err
+	}
+
+	// 監査ログを記録
+	before := after
+	before.DeletedAt = nil
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionDelete, domain_audit.AuditEntityTodo, after.ID, before, after)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Deleted todo: %v", id)
+	return nil
+}
+
+// 複数のTodoの完了状態を1つのトランザクションで変更し、Todoごとの結果を返す
+// 権限は同じトランザクション内で確認し、既に指定の完了状態のTodoは変更せず、監査ログも記録しない。
+func (r *TodoRepositoryImpl) BulkSetCompleted(ids []string, completed bool, required string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error) {
+	r.Logger.InfoLog.Println("BulkSetCompleted called")
+
+	query := `
+		UPDATE todos
+		SET completed = $1, updated_at = now()
+		WHERE id = ANY($2) AND deleted_at IS NULL AND completed <> $1
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 権限を確認し、変更前のTodoを取得(行ロック)
+	results, befores, err := r.authorizeBulkInTx(tx, ids, actor.UserId, required)
+	if err != nil {
+		return nil, err
+	}
+	authorizedIds := make([]string, 0, len(befores))
+	for _, before := range befores {
+		authorizedIds = append(authorizedIds, before.ID)
+	}
+
+	// 完了状態を変更
+	rows, err := tx.Query(r.SupabaseClient.Ctx, query, completed, authorizedIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to update todos: %v", err)
+		return nil, err
+	}
+	afters, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	afterById := make(map[string]domain_todo.Todo, len(afters))
+	for _, after := range afters {
+		afterById[after.ID] = after
+	}
+
+	// 監査ログを記録し、変更しなかったTodoは変更前の状態のまま返す
+	todos := make([]domain_todo.Todo, 0, len(befores))
+	for _, before := range befores {
+		after, ok := afterById[before.ID]
+		if !ok {
+			todos = append(todos, before)
+			continue
+		}
+		err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionUpdate, domain_audit.AuditEntityTodo, after.ID, before, after)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+			return nil, err
+		}
+		todos = append(todos, after)
+	}
+	applyBulkResults(results, befores, todos)
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Set completed of %d todos (%d changed)", len(todos), len(afters))
+	return results, nil
+}
+
+// 複数のTodoを1つのトランザクションで削除(ゴミ箱に移動)し、Todoごとの結果を返す
+// 権限は同じトランザクション内で確認する。
+func (r *TodoRepositoryImpl) BulkDeleteTodos(ids []string, required string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error) {
+	r.Logger.InfoLog.Println("BulkDeleteTodos called")
+
+	query := `
+		UPDATE todos
+		SET deleted_at = now()
+		WHERE id = ANY($1) AND deleted_at IS NULL
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 権限を確認し、変更前のTodoを取得(行ロック)
+	results, befores, err := r.authorizeBulkInTx(tx, ids, actor.UserId, required)
+	if err != nil {
+		return nil, err
+	}
+	authorizedIds := make([]string, 0, len(befores))
+	for _, before := range befores {
+		authorizedIds = append(authorizedIds, before.ID)
+	}
+
+	// ゴミ箱に移動
+	todos, err := r.trashTodosInTx(tx, actor, query, authorizedIds)
+	if err != nil {
+		return nil, err
+	}
+	applyBulkResults(results, befores, todos)
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Deleted %d todos", len(todos))
+	return results, nil
+}
+
+// 一括操作の対象のTodoの行ロックを取得し、ユーザーの権限を1回のクエリで確認
+// idの順にTodoごとの結果(見つからないもの・権限が無いものは失敗)と、権限のあるTodoの変更前の状態を返す。
+// 所有者は全ての操作ができ、所有者以外はTodoとその祖先の承諾済みの共有のうち最も高い権限がrequired以上の場合のみ操作できる。
+func (r *TodoRepositoryImpl) authorizeBulkInTx(tx pgx.Tx, ids []string, userId string, required string) ([]domain_todo.TodoBulkResult, []domain_todo.Todo, error) {
+	// 既存データに循環があっても停止するよう、深さで打ち切る
+	query := `
+		WITH RECURSIVE ancestors (todo_id, id, parent_id, depth) AS (
+			SELECT id, id, parent_id, 0 FROM todos WHERE id = ANY($2)
+			UNION ALL
+			SELECT a.todo_id, t.id, t.parent_id, a.depth + 1 FROM todos t JOIN ancestors a ON t.id = a.parent_id WHERE a.depth < $3
+		)
+		SELECT t.id, t.description, t.completed, t.user_id, t.created_at, t.updated_at, t.deleted_at, t.due_at, t.priority, t.remind_at, t.reminded_at, t.list_id, t.parent_id, t.position,
+		  ARRAY(
+		    SELECT s.role FROM todo_shares s
+		    WHERE s.member_id = $1 AND s.owner_id = t.user_id AND s.status = 'accepted'
+		      AND (s.todo_id IS NULL OR s.todo_id IN (SELECT a.id FROM ancestors a WHERE a.todo_id = t.id))
+		  ) AS roles
+		FROM todos t
+		WHERE t.id = ANY($2) AND t.deleted_at IS NULL
+		ORDER BY t.id
+		FOR UPDATE OF t
+	`
+
+	// 対象のTodoと権限を取得(行ロック)
+	rows, err := tx.Query(r.SupabaseClient.Ctx, query, userId, ids, domain_todo.MaxTodoDepth*2)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	todoById := make(map[string]domain_todo.Todo, len(ids))
+	rolesById := make(map[string][]string, len(ids))
+	for rows.Next() {
+		var todo domain_todo.Todo
+		var roles []string
+		err = rows.Scan(
+			&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+			&roles,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
+			return nil, nil, err
+		}
+		todoById[todo.ID] = todo
+		rolesById[todo.ID] = roles
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return nil, nil, err
+	}
+
+	results, befores := authorizeBulk(ids, userId, required, todoById, rolesById)
+	r.Logger.InfoLog.Printf("Authorized %d of %d todos", len(befores), len(ids))
+	return results, befores, nil
+}
+
+// 一括操作の権限をTodoごとに判定し、結果(見つからないもの・権限が無いものは失敗)と権限のあるTodoをidの順で返す
+func authorizeBulk(ids []string, userId string, required string, todoById map[string]domain_todo.Todo, rolesById map[string][]string) ([]domain_todo.TodoBulkResult, []domain_todo.Todo) {
+	results := make([]domain_todo.TodoBulkResult, len(ids))
+	befores := make([]domain_todo.Todo, 0, len(ids))
+	for i, id := range ids {
+		results[i] = domain_todo.TodoBulkResult{ID: id}
+		todo, ok := todoById[id]
+		if !ok {
+			results[i].Message = "todo not found"
+			continue
+		}
+		if todo.UserId != userId {
+			role := domain_share.HighestRole(rolesById[id])
+			if role == "" || !domain_share.RoleAtLeast(role, required) {
+				results[i].Message = "forbidden"
+				continue
+			}
+		}
+		befores = append(befores, todo)
+	}
+	return results, befores
+}
+
+// 操作後のTodoを一括操作の結果に反映(権限のあるTodoのうち、操作後のTodoに含まれないものは失敗にする)
+func applyBulkResults(results []domain_todo.TodoBulkResult, befores []domain_todo.Todo, todos []domain_todo.Todo) {
+	beforeById := make(map[string]domain_todo.Todo, len(befores))
+	for _, before := range befores {
+		beforeById[before.ID] = before
+	}
+	todoById := make(map[string]domain_todo.Todo, len(todos))
+	for _, todo := range todos {
+		todoById[todo.ID] = todo
+	}
+	for i := range results {
+		before, ok := beforeById[results[i].ID]
+		if !ok {
+			continue
+		}
+		todo, ok := todoById[results[i].ID]
+		if !ok {
+			results[i].Message = "todo not found"
+			continue
+		}
+		results[i].Success = true
+		results[i].Changed = before.Completed != todo.Completed || (before.DeletedAt == nil && todo.DeletedAt != nil)
+		results[i].Todo = &todo
+	}
+}
+
+// 特定のユーザーの完了済みのTodoを1つのトランザクションで削除(ゴミ箱に移動)し、削除したTodoを返す(listIdがnilの場合は全てのリスト)
+func (r *TodoRepositoryImpl) ClearCompletedTodos(userId string, listId *string, actor domain_audit.AuditActor) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("ClearCompletedTodos called")
+
+	query := `
+		UPDATE todos
+		SET deleted_at = now()
+		WHERE user_id = $1 AND completed AND deleted_at IS NULL
+		  AND ($2::uuid IS NULL OR list_id = $2::uuid)
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	todos, err := r.trashTodos(actor, query, userId, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Cleared %d completed todos", len(todos))
+	return todos, nil
+}
+
+// Todoをゴミ箱に移動するクエリを1つのトランザクションで実行し、監査ログを記録
+func (r *TodoRepositoryImpl) trashTodos(actor domain_audit.AuditActor, query string, args ...interface{}) ([]domain_todo.Todo, error) {
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// ゴミ箱に移動
+	todos, err := r.trashTodosInTx(tx, actor, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	return todos, nil
+}
+
+// トランザクション内でTodoをゴミ箱に移動するクエリを実行し、監査ログを記録
+func (r *TodoRepositoryImpl) trashTodosInTx(tx pgx.Tx, actor domain_audit.AuditActor, query string, args ...interface{}) ([]domain_todo.Todo, error) {
+	// Supabaseからクエリを実行し、削除したTodoを取得
+	rows, err := tx.Query(r.SupabaseClient.Ctx, query, args...)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete todos: %v", err)
+		return nil, err
+	}
+	todos, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// 監査ログを記録
+	for _, after := range todos {
+		before := after
+		before.DeletedAt = nil
+		err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionDelete, domain_audit.AuditEntityTodo, after.ID, before, after)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+			return nil, err
+		}
+	}
+	return todos, nil
+}
+
+// ゴミ箱の特定のTodoを取得
+func (r *TodoRepositoryImpl) GetTrashedTodoById(id string) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetTrashedTodoById called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	var todo domain_todo.Todo
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch trashed todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched trashed todo: %v", todo)
+	return todo, nil
+}
+
+// 特定のユーザーのゴミ箱のTodoを取得
+func (r *TodoRepositoryImpl) GetTrashedTodosByUserId(userId string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetTrashedTodosByUserId called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch trashed todos: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Todosのリストを作成
+	todos := []domain_todo.Todo{}
+	for rows.Next() {
+		var todo domain_todo.Todo
+		err = rows.Scan(
+			&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d trashed todos", len(todos))
+	return todos, nil
+}
+
+// 保持期間を過ぎたゴミ箱のTodoのIDを取得
+func (r *TodoRepositoryImpl) GetTrashedTodoIdsBefore(before time.Time, limit int) ([]string, error) {
+	r.Logger.InfoLog.Println("GetTrashedTodoIdsBefore called")
+
+	query := `
+		SELECT id
+		FROM todos
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoのIDを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, before, limit)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch expired trashed todos: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo id: %v", err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d expired trashed todos", len(ids))
+	return ids, nil
+}
+
+// ゴミ箱のTodoを復元
+func (r *TodoRepositoryImpl) RestoreTodo(id string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("RestoreTodo called")
+
+	query := `
+		UPDATE todos
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// Supabaseからクエリを実行し、復元したTodoを取得
+	var todo domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to restore todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionRestore, domain_audit.AuditEntityTodo, todo.ID, nil, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Restored todo: %v", todo)
+	return todo, nil
+}
+
+// ゴミ箱のTodoを完全に削除
+// 削除した添付ファイルのストレージキーを返す(ストレージからの削除は呼び出し側で行う)。
+func (r *TodoRepositoryImpl) PurgeTodo(id string, actor domain_audit.AuditActor) ([]string, error) {
+	r.Logger.InfoLog.Println("PurgeTodo called")
+
+	attachmentQuery := `
+		DELETE FROM todo_attachments
+		WHERE todo_id = $1
+		RETURNING storage_key
+	`
+	query := `
+		DELETE FROM todos
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 添付ファイル情報を削除し、ストレージキーを取得(Todoの削除でカスケード削除される前に取得する)
+	rows, err := tx.Query(r.SupabaseClient.Ctx, attachmentQuery, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete attachments: %v", err)
+		return nil, err
+	}
+	storageKeys := []string{}
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			rows.Close()
+			r.Logger.ErrorLog.Printf("Failed to scan storage key: %v", err)
+			return nil, err
+		}
+		storageKeys = append(storageKeys, key)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete attachments: %v", err)
+		return nil, err
+	}
+
+	// Supabaseからクエリを実行し、削除したTodoを取得
+	var before domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&before.ID,
+			&before.Description,
+			&before.Completed,
+			&before.UserId,
+			&before.CreatedAt,
+			&before.UpdatedAt,
+			&before.DeletedAt,
+			&before.DueAt,
+			&before.Priority,
+			&before.RemindAt,
+			&before.RemindedAt,
+			&before.ListId,
+			&before.ParentId,
+			&before.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to purge todo: %v", err)
+		return nil, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionPurge, domain_audit.AuditEntityTodo, before.ID, before, nil)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return nil, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return nil, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Purged todo: %v", id)
+	return storageKeys, nil
+}
+
+// 特定のユーザーの期限切れのTodoを取得(未完了のみ)
+func (r *TodoRepositoryImpl) GetOverdueTodosByUserId(userId string, now time.Time) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetOverdueTodosByUserId called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND completed = false
+		  AND due_at < $2
+		ORDER BY due_at
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId, now)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch overdue todos: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Todosのリストを作成
+	todos := []domain_todo.Todo{}
+	for rows.Next() {
+		var todo domain_todo.Todo
+		err = rows.Scan(
+			&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d overdue todos", len(todos))
+	return todos, nil
+}
+
+// 特定のユーザーの期限が指定期間内のTodoを取得(未完了のみ)
+func (r *TodoRepositoryImpl) GetUpcomingTodosByUserId(userId string, from time.Time, to time.Time) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetUpcomingTodosByUserId called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND completed = false
+		  AND due_at >= $2 AND due_at < $3
+		ORDER BY due_at
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId, from, to)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch upcoming todos: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Todosのリストを作成
+	todos := []domain_todo.Todo{}
+	for rows.Next() {
+		var todo domain_todo.Todo
+		err = rows.Scan(
+			&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d upcoming todos", len(todos))
+	return todos, nil
+}
+
+// 特定のユーザーの期限がsince以降のTodo(完了済みを含む)を期限の早い順にlimit件取得
+func (r *TodoRepositoryImpl) GetDueTodosByUserId(userId string, since time.Time, limit int) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetDueTodosByUserId called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND due_at >= $2
+		ORDER BY due_at, id
+		LIMIT $3
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId, since, limit)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch due todos: %v", err)
+		return nil, err
+	}
+	todos, err := r.scanTodoRows(rows)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch due todos: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d due todos", len(todos))
+	return todos, nil
+}
+
+// リマインド日時を過ぎたTodoを取得し、通知済みにする
+// 複数のインスタンスから同時に呼び出されても、同じTodoを重複して取得しないようにする。
+func (r *TodoRepositoryImpl) ClaimDueReminders(now time.Time, limit int) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("ClaimDueReminders called")
+
+	query := `
+		UPDATE todos
+		SET reminded_at = $1
+		WHERE id IN (
+		    SELECT id
+		    FROM todos
+		    WHERE deleted_at IS NULL
+		      AND completed = false
+		      AND reminded_at IS NULL
+		      AND remind_at <= $1
+		    ORDER BY remind_at
+		    LIMIT $2
+		    FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// Supabaseからクエリを実行し、通知対象のTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, now, limit)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to claim due reminders: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Todosのリストを作成
+	todos := []domain_todo.Todo{}
+	for rows.Next() {
+		var todo domain_todo.Todo
+		err = rows.Scan(
+			&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to claim due reminders: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Claimed %d due reminders", len(todos))
+	return todos, nil
+}
+
+// 複数のTodoを取得
+func (r *TodoRepositoryImpl) GetTodosByIds(ids []string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetTodosByIds called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, ids)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return nil, err
+	}
+
+	todos, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d todos", len(todos))
+	return todos, nil
+}
+
+// 特定のユーザーに共有された(承諾済みの)Todoを取得
+func (r *TodoRepositoryImpl) GetSharedTodosByMemberId(memberId string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetSharedTodosByMemberId called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos t
+		WHERE t.deleted_at IS NULL
+		  AND EXISTS (
+		    SELECT 1 FROM todo_shares s
+		    WHERE s.member_id = $1 AND s.status = 'accepted' AND s.owner_id = t.user_id
+		      AND (s.todo_id IS NULL OR s.todo_id = t.id)
+		  )
+		ORDER BY t.position, t.created_at, t.id
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, memberId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch shared todos: %v", err)
+		return nil, err
+	}
+
+	todos, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d shared todos", len(todos))
+	return todos, nil
+}
+
+// 複数の親Todoのサブタスク(直下の子)を取得
+func (r *TodoRepositoryImpl) GetSubtasksByParentIds(parentIds []string) ([]domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("GetSubtasksByParentIds called")
+
+	query := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE parent_id = ANY($1) AND deleted_at IS NULL
+		ORDER BY position, created_at, id
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, parentIds)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch subtasks: %v", err)
+		return nil, err
+	}
+
+	todos, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d subtasks", len(todos))
+	return todos, nil
+}
+
+// 複数のTodoのサブタスクの進捗を取得(子孫を全て集計する)
+func (r *TodoRepositoryImpl) GetTodoProgressByIds(ids []string) ([]domain_todo.TodoProgress, error) {
+	r.Logger.InfoLog.Println("GetTodoProgressByIds called")
+
+	query := `
+		WITH RECURSIVE subtree (root_id, id, completed) AS (
+			SELECT parent_id, id, completed FROM todos WHERE parent_id = ANY($1) AND deleted_at IS NULL
+			UNION
+			SELECT s.root_id, t.id, t.completed FROM todos t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+		)
+		SELECT root_id, COUNT(*) FILTER (WHERE completed), COUNT(*)
+		FROM subtree
+		GROUP BY root_id
+	`
+
+	// Supabaseからクエリを実行し、サブタスクの件数を集計
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, ids)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo progress: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := []domain_todo.TodoProgress{}
+	for rows.Next() {
+		var p domain_todo.TodoProgress
+		err = rows.Scan(&p.TodoId, &p.Completed, &p.Total)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo progress: %v", err)
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched progress of %d todos", len(progress))
+	return progress, nil
+}
+
+// 特定のTodoの祖先のIDを近い順に取得
+func (r *TodoRepositoryImpl) GetTodoAncestorIds(id string) ([]string, error) {
+	r.Logger.InfoLog.Println("GetTodoAncestorIds called")
+
+	// 既存データに循環があっても停止するよう、深さで打ち切る
+	query := `
+		WITH RECURSIVE ancestors (id, parent_id, depth) AS (
+			SELECT id, parent_id, 0 FROM todos WHERE id = $1
+			UNION ALL
+			SELECT t.id, t.parent_id, a.depth + 1 FROM todos t JOIN ancestors a ON t.id = a.parent_id WHERE a.depth < $2
+		)
+		SELECT id FROM ancestors WHERE depth > 0 ORDER BY depth
+	`
+
+	// Supabaseからクエリを実行し、祖先のIDを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, id, domain_todo.MaxTodoDepth*2)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo ancestors: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var ancestorId string
+		if err = rows.Scan(&ancestorId); err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo id: %v", err)
+			return nil, err
+		}
+		ids = append(ids, ancestorId)
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d ancestors", len(ids))
+	return ids, nil
+}
+
+// 特定のTodoを頂点とする部分木の高さを取得(子がない場合は1)
+func (r *TodoRepositoryImpl) GetSubtreeHeight(id string) (int, error) {
+	r.Logger.InfoLog.Println("GetSubtreeHeight called")
+
+	// ゴミ箱のサブタスクも復元される可能性があるため含める
+	query := `
+		WITH RECURSIVE subtree (id, depth) AS (
+			SELECT id, 1 FROM todos WHERE id = $1
+			UNION ALL
+			SELECT t.id, s.depth + 1 FROM todos t JOIN subtree s ON t.parent_id = s.id WHERE s.depth < $2
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM subtree
+	`
+
+	// Supabaseからクエリを実行し、部分木の高さを取得
+	var height int
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id, domain_todo.MaxTodoDepth*2).Scan(&height)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch subtree height: %v", err)
+		return 0, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched subtree height: %d", height)
+	return height, nil
+}
+
+// Todoの親を変更(parentIdがnilの場合は最上位、監査ログを同一トランザクションで記録する)
+func (r *TodoRepositoryImpl) SetTodoParent(id string, parentId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("SetTodoParent called")
+
+	beforeQuery := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	query := `
+		UPDATE todos
+		SET parent_id = $1, updated_at = now()
+		WHERE id = $2
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 変更前のTodoを取得(行ロックを取得する)
+	var before domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, beforeQuery, id).
+		Scan(&before.ID,
+			&before.Description,
+			&before.Completed,
+			&before.UserId,
+			&before.CreatedAt,
+			&before.UpdatedAt,
+			&before.DeletedAt,
+			&before.DueAt,
+			&before.Priority,
+			&before.RemindAt,
+			&before.RemindedAt,
+			&before.ListId,
+			&before.ParentId,
+			&before.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// Supabaseからクエリを実行し、親を変更したTodoを取得
+	var todo domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query, parentId, id).
+		Scan(&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to set todo parent: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionUpdate, domain_audit.AuditEntityTodo, todo.ID, before, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Set todo parent: %v", todo)
+	return todo, nil
+}
+
+// 特定のユーザーの末尾のTodoの並び順のキーを取得(キーを持つTodoが無い場合はnil、excludeIdのTodoは除く)
+func (r *TodoRepositoryImpl) GetLastTodoPosition(userId string, excludeId string) (*string, error) {
+	r.Logger.InfoLog.Println("GetLastTodoPosition called")
+
+	// インデックスの順に読むため、除外するTodoは条件に含めず、取得した2件から除く
+	query := `
+		SELECT id, position
+		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NULL AND position IS NOT NULL
+		ORDER BY position DESC
+		LIMIT 2
+	`
+
+	// Supabaseからクエリを実行し、末尾のキーを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch last todo position: %v", err)
+		return nil, err
+	}
+	position, err := r.scanFirstPosition(rows, excludeId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch last todo position: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched last todo position: %v", position)
+	return position, nil
+}
+
+// 特定のユーザーのTodoのうち、指定したキーの直後(nextがfalseの場合は直前)のキーを取得
+// 該当するTodoが無い場合はnilを返す(excludeIdのTodoは除く)。
+func (r *TodoRepositoryImpl) GetAdjacentTodoPosition(userId string, position string, next bool, excludeId string) (*string, error) {
+	r.Logger.InfoLog.Println("GetAdjacentTodoPosition called")
+
+	// インデックスの順に読むため、除外するTodoは条件に含めず、取得した2件から除く
+	query := `
+		SELECT id, position
+		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NULL AND position > $2
+		ORDER BY position
+		LIMIT 2
+	`
+	if !next {
+		query = `
+			SELECT id, position
+			FROM todos
+			WHERE user_id = $1 AND deleted_at IS NULL AND position < $2
+			ORDER BY position DESC
+			LIMIT 2
+		`
+	}
+
+	// Supabaseからクエリを実行し、隣のキーを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId, position)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch adjacent todo position: %v", err)
+		return nil, err
+	}
+	adjacent, err := r.scanFirstPosition(rows, excludeId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch adjacent todo position: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched adjacent todo position: %v", adjacent)
+	return adjacent, nil
+}
+
+// idとキーの行から、excludeIdのTodoを除いた最初のキーを取得(該当する行が無い場合はnil)
+func (r *TodoRepositoryImpl) scanFirstPosition(rows pgx.Rows, excludeId string) (*string, error) {
+	defer rows.Close()
+
+	var position *string
+	for rows.Next() {
+		var id string
+		var p string
+		if err := rows.Scan(&id, &p); err != nil {
+			return nil, err
+		}
+		if position == nil && id != excludeId {
+			position = &p
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return position, nil
+}
+
+// Todoの並び順のキーを変更(対象の1行のみ更新し、監査ログを同一トランザクションで記録する)
+func (r *TodoRepositoryImpl) UpdateTodoPosition(id string, position string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.Logger.InfoLog.Println("UpdateTodoPosition called")
+
+	beforeQuery := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	query := `
+		UPDATE todos
+		SET position = $1, updated_at = now()
+		WHERE id = $2
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 変更前のTodoを取得(行ロックを取得する)
+	var before domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, beforeQuery, id).
+		Scan(&before.ID,
+			&before.Description,
+			&before.Completed,
+			&before.UserId,
+			&before.CreatedAt,
+			&before.UpdatedAt,
+			&before.DeletedAt,
+			&before.DueAt,
+			&before.Priority,
+			&before.RemindAt,
+			&before.RemindedAt,
+			&before.ListId,
+			&before.ParentId,
+			&before.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// Supabaseからクエリを実行し、並び順を変更したTodoを取得
+	var todo domain_todo.Todo
+	err = tx.QueryRow(r.SupabaseClient.Ctx, query, position, id).
+		Scan(&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to update todo position: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionUpdate, domain_audit.AuditEntityTodo, todo.ID, before, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Updated todo position: %v", todo)
+	return todo, nil
+}
+
+// 特定のユーザーのゴミ箱にないTodoの並び順のキーを現在の順序のまま等間隔に振り直し、更新した件数を返す
+// キーの振り直しは並び順を変えないため、監査ログは記録しない。ゴミ箱のTodoは復元時に元の位置へ戻すため、キーを変更しない。
+func (r *TodoRepositoryImpl) RebalanceTodoPositions(userId string) (int, error) {
+	r.Logger.InfoLog.Println("RebalanceTodoPositions called")
+
+	selectQuery := `
+		SELECT id
+		FROM todos
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY position, created_at, id
+		FOR UPDATE
+	`
+	updateQuery := `
+		UPDATE todos t
+		SET position = p.position
+		FROM unnest($1::uuid[], $2::text[]) AS p (id, position)
+		WHERE t.id = p.id
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 現在の順序でTodoのIDを取得(行ロックを取得する)
+	rows, err := tx.Query(r.SupabaseClient.Ctx, selectQuery, userId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return 0, err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			r.Logger.ErrorLog.Printf("Failed to scan todo id: %v", err)
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos: %v", err)
+		return 0, err
+	}
+
+	// 等間隔のキーを振り直す
+	positions := pkg_fracindex.EvenlySpaced(len(ids))
+	_, err = tx.Exec(r.SupabaseClient.Ctx, updateQuery, ids, positions)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to rebalance todo positions: %v", err)
+		return 0, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return 0, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Rebalanced %d todo positions", len(ids))
+	return len(ids), nil
+}
+
+// 並び順のキーの振り直しが必要なユーザーのIDを取得(キーが未設定、またはmaxLengthより長いゴミ箱にないTodoを持つユーザー)
+func (r *TodoRepositoryImpl) GetUserIdsNeedingRebalance(maxLength int, limit int) ([]string, error) {
+	r.Logger.InfoLog.Println("GetUserIdsNeedingRebalance called")
+
+	query := `
+		SELECT DISTINCT user_id
+		FROM todos
+		WHERE deleted_at IS NULL AND (position IS NULL OR length(position) > $1)
+		LIMIT $2
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するユーザーのIDを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, maxLength, limit)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch users needing rebalance: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIds := []string{}
+	for rows.Next() {
+		var userId string
+		if err = rows.Scan(&userId); err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan user id: %v", err)
+			return nil, err
+		}
+		userIds = append(userIds, userId)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch users needing rebalance: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d users needing rebalance", len(userIds))
+	return userIds, nil
+}
+
+// Todoの行を読み込む(読み込み後に行を閉じる)
+func (r *TodoRepositoryImpl) scanTodoRows(rows pgx.Rows) ([]domain_todo.Todo, error) {
+	defer rows.Close()
+
+	todos := []domain_todo.Todo{}
+	for rows.Next() {
+		var todo domain_todo.Todo
+		err := rows.Scan(
+			&todo.ID,
+			&todo.Description,
+			&todo.Completed,
+			&todo.UserId,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+			&todo.DeletedAt,
+			&todo.DueAt,
+			&todo.Priority,
+			&todo.RemindAt,
+			&todo.RemindedAt,
+			&todo.ListId,
+			&todo.ParentId,
+			&todo.Position,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo: %v", err)
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	return todos, rows.Err()
 }
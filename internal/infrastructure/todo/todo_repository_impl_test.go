@@ -0,0 +1,102 @@
+package infrastructure_todo
+
+import (
+	domain_share "backend/internal/domain/share"
+	domain_todo "backend/internal/domain/todo"
+	"testing"
+	"time"
+)
+
+// 一括操作の権限は所有者、またはTodoと祖先の共有のうち最も高い権限で判定する
+func TestAuthorizeBulk(t *testing.T) {
+	todoById := map[string]domain_todo.Todo{
+		"own":    {ID: "own", UserId: "u1"},
+		"viewer": {ID: "viewer", UserId: "u2"},
+		"editor": {ID: "editor", UserId: "u2"},
+		"owner":  {ID: "owner", UserId: "u2"},
+		"none":   {ID: "none", UserId: "u2"},
+	}
+	rolesById := map[string][]string{
+		"viewer": {domain_share.ShareRoleViewer},
+		"editor": {domain_share.ShareRoleViewer, domain_share.ShareRoleEditor},
+		"owner":  {domain_share.ShareRoleOwner},
+		"none":   {},
+	}
+	ids := []string{"own", "viewer", "editor", "owner", "none", "missing"}
+
+	tests := []struct {
+		name     string
+		required string
+		want     map[string]string
+	}{
+		{name: "editor required", required: domain_share.ShareRoleEditor, want: map[string]string{
+			"own": "", "viewer": "forbidden", "editor": "", "owner": "", "none": "forbidden", "missing": "todo not found",
+		}},
+		{name: "owner required", required: domain_share.ShareRoleOwner, want: map[string]string{
+			"own": "", "viewer": "forbidden", "editor": "forbidden", "owner": "", "none": "forbidden", "missing": "todo not found",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, befores := authorizeBulk(ids, "u1", tt.required, todoById, rolesById)
+			if len(results) != len(ids) {
+				t.Fatalf("got %d results, want %d", len(results), len(ids))
+			}
+			authorized := map[string]bool{}
+			for _, before := range befores {
+				authorized[before.ID] = true
+			}
+			for i, result := range results {
+				if result.ID != ids[i] {
+					t.Errorf("results[%d].ID = %s, want %s", i, result.ID, ids[i])
+				}
+				if result.Message != tt.want[result.ID] {
+					t.Errorf("%s: message = %q, want %q", result.ID, result.Message, tt.want[result.ID])
+				}
+				if authorized[result.ID] != (tt.want[result.ID] == "") {
+					t.Errorf("%s: authorized = %v, want %v", result.ID, authorized[result.ID], tt.want[result.ID] == "")
+				}
+			}
+		})
+	}
+}
+
+// 操作後のTodoを結果に反映し、変化したかどうかを判定する
+func TestApplyBulkResults(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	befores := []domain_todo.Todo{
+		{ID: "t1"},
+		{ID: "t2", Completed: true},
+		{ID: "t3"},
+		{ID: "t4"},
+	}
+	todos := []domain_todo.Todo{
+		{ID: "t1", Completed: true},
+		{ID: "t2", Completed: true},
+		{ID: "t3", DeletedAt: &deletedAt},
+	}
+	results := []domain_todo.TodoBulkResult{
+		{ID: "t1"}, {ID: "t2"}, {ID: "t3"}, {ID: "t4"}, {ID: "t5", Message: "forbidden"},
+	}
+
+	applyBulkResults(results, befores, todos)
+
+	want := []struct {
+		success bool
+		changed bool
+		message string
+	}{
+		{success: true, changed: true},
+		{success: true, changed: false},
+		{success: true, changed: true},
+		{success: false, message: "todo not found"},
+		{success: false, message: "forbidden"},
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Success != w.success || r.Changed != w.changed || r.Message != w.message || (r.Todo != nil) != w.success {
+			t.Errorf("results[%d] = %+v, want success=%v changed=%v message=%q", i, r, w.success, w.changed, w.message)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package infrastructure_todo
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_todo "backend/internal/domain/todo"
+	infrastructure_audit "backend/internal/infrastructure/audit"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// 他のリポジトリのトランザクション内でTodoを書き込むもの
+// todosテーブルへの書き込みはTodoリポジトリに集め、イベントソーシングが有効な場合はドメインイベントとして追記する。
+type TodoTxWriter interface {
+	// トランザクション内で新しいTodoを作成(監査ログを記録する)
+	CreateTodoInTx(tx pgx.Tx, todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// トランザクション内で特定のTodoリストのTodoをインボックスに移動し、ゴミ箱にないTodoを返す(監査ログを記録する)
+	// trashがtrueの場合は、ゴミ箱にないTodoをゴミ箱に移動する。Todoリストの削除前に呼び出す。
+	DetachTodosFromListInTx(tx pgx.Tx, listId string, trash bool, actor domain_audit.AuditActor) ([]domain_todo.Todo, error)
+	// トランザクション内で全てのTodoの検索用のtsvectorを作り直し、更新した件数を返す
+	ReindexTodoSearchInTx(tx pgx.Tx) (int64, error)
+}
+
+// Todoの書き込み(Impl)のインスタンス化
+func NewTodoTxWriter(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient) TodoTxWriter {
+	return &TodoRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+	}
+}
+
+// イベントソーシングによるTodoの書き込みのインスタンス化
+func NewEventSourcedTodoTxWriter(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient, snapshotInterval int) TodoTxWriter {
+	return newEventSourcedTodoRepository(l, sc, snapshotInterval)
+}
+
+// トランザクション内で新しいTodoを作成(監査ログを記録する)
+func (r *TodoRepositoryImpl) CreateTodoInTx(tx pgx.Tx, todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	todo, err := r.insertTodoInTx(tx, todo)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// 監査ログを記録
+	err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, domain_audit.AuditActionCreate, domain_audit.AuditEntityTodo, todo.ID, nil, todo)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	return todo, nil
+}
+
+// トランザクション内で特定のTodoリストのTodoをインボックスに移動し、ゴミ箱にないTodoを返す(監査ログを記録する)
+// ゴミ箱のTodoはTodoリストの削除時に外部キーによってインボックスに戻る。
+func (r *TodoRepositoryImpl) DetachTodosFromListInTx(tx pgx.Tx, listId string, trash bool, actor domain_audit.AuditActor) ([]domain_todo.Todo, error) {
+	// リスト内のTodoをゴミ箱に移動するか、インボックスに移動するか
+	action := domain_audit.AuditActionUpdate
+	todoQuery := `
+		UPDATE todos
+		SET list_id = NULL, updated_at = now()
+		WHERE list_id = $1 AND deleted_at IS NULL
+		RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+	`
+	if trash {
+		action = domain_audit.AuditActionDelete
+		todoQuery = `
+			UPDATE todos
+			SET deleted_at = now()
+			WHERE list_id = $1 AND deleted_at IS NULL
+			RETURNING id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		`
+	}
+	beforeQuery := `
+		SELECT id, description, completed, user_id, created_at, updated_at, deleted_at, due_at, priority, remind_at, reminded_at, list_id, parent_id, position
+		FROM todos
+		WHERE list_id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	// 変更前のTodoを取得(行ロックを取得する)
+	rows, err := tx.Query(r.SupabaseClient.Ctx, beforeQuery, listId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todos in list: %v", err)
+		return nil, err
+	}
+	befores, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	beforeById := make(map[string]domain_todo.Todo, len(befores))
+	for _, todo := range befores {
+		beforeById[todo.ID] = todo
+	}
+
+	// リスト内のTodoを移動
+	rows, err = tx.Query(r.SupabaseClient.Ctx, todoQuery, listId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to move todos in list: %v", err)
+		return nil, err
+	}
+	afters, err := r.scanTodoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// 監査ログを記録
+	for _, after := range afters {
+		err = infrastructure_audit.InsertAuditLog(r.SupabaseClient.Ctx, tx, actor, action, domain_audit.AuditEntityTodo, after.ID, beforeById[after.ID], after)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to write audit log: %v", err)
+			return nil, err
+		}
+	}
+	return afters, nil
+}
+
+// トランザクション内で全てのTodoの検索用のtsvectorを作り直し、更新した件数を返す
+// tsvectorは説明から導出する索引でTodoの状態に含まれないため、イベントソーシングが有効な場合もイベントにしない
+// (再構築時は説明の投影によってトリガーが作り直す)。
+func (r *TodoRepositoryImpl) ReindexTodoSearchInTx(tx pgx.Tx) (int64, error) {
+	query := `
+		UPDATE todos
+		SET search_vector = todo_search_document(description)
+	`
+
+	tag, err := tx.Exec(r.SupabaseClient.Ctx, query)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to reindex todos: %v", err)
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
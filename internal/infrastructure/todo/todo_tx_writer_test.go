@@ -0,0 +1,128 @@
+package infrastructure_todo
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_todo "backend/internal/domain/todo"
+	pkg_supabase "backend/internal/pkg/supabase"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// 実行したクエリを記録するトランザクション(使用しないメソッドは未実装)
+type fakeTx struct {
+	pgx.Tx
+	queries []string
+	args    [][]interface{}
+	// 監査ログの書き込みを失敗させる場合のエラー
+	auditErr error
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	tx.queries = append(tx.queries, sql)
+	tx.args = append(tx.args, args)
+	switch {
+	case strings.Contains(sql, "INSERT INTO todos"):
+		return fakeScanRow(func(dest ...interface{}) error {
+			*dest[0].(*string) = "todo-1"
+			*dest[1].(*string) = args[0].(string)
+			*dest[2].(*bool) = args[1].(bool)
+			*dest[3].(*string) = args[2].(string)
+			*dest[4].(*time.Time) = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			*dest[5].(*time.Time) = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			*dest[8].(*string) = args[4].(string)
+			return nil
+		})
+	case strings.Contains(sql, "INSERT INTO audit_logs"):
+		if tx.auditErr != nil {
+			return fakeScanRow(func(dest ...interface{}) error { return tx.auditErr })
+		}
+		return fakeScanRow(func(dest ...interface{}) error {
+			*dest[0].(*string) = "audit-1"
+			*dest[1].(*time.Time) = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			return nil
+		})
+	}
+	return fakeScanRow(func(dest ...interface{}) error { return errors.New("unexpected query: " + sql) })
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	tx.queries = append(tx.queries, sql)
+	tx.args = append(tx.args, args)
+	return pgconn.CommandTag("INSERT 0 1"), nil
+}
+
+type fakeScanRow func(dest ...interface{}) error
+
+func (f fakeScanRow) Scan(dest ...interface{}) error { return f(dest...) }
+
+// 実行したクエリのうち、指定したテーブルへのINSERTの番号(無い場合は-1)
+func (tx *fakeTx) insertIndex(table string) int {
+	for i, sql := range tx.queries {
+		if strings.Contains(sql, "INSERT INTO "+table) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Todoの作成と監査ログ・Webhookの配信は同じトランザクションで書き込む
+func TestCreateTodoInTxWritesAuditLogInSameTransaction(t *testing.T) {
+	w := NewTodoTxWriter(newTestLogger(), &pkg_supabase.SupabaseClient{Ctx: context.Background()})
+	tx := &fakeTx{}
+	ownerId := "8d3e4f52-6a1b-4c2d-9e8f-0a1b2c3d4e5f"
+	actor := domain_audit.AuditActor{UserId: ownerId, RequestId: "req-1", IPAddress: "192.0.2.1"}
+
+	todo, err := w.CreateTodoInTx(tx, domain_todo.Todo{Description: "buy milk", UserId: ownerId, Priority: domain_todo.TodoPriorityMedium}, actor)
+	if err != nil {
+		t.Fatalf("CreateTodoInTx() unexpected error: %v", err)
+	}
+	if todo.ID != "todo-1" {
+		t.Errorf("todo.ID = %q, want todo-1", todo.ID)
+	}
+
+	todoIndex, auditIndex, webhookIndex := tx.insertIndex("todos"), tx.insertIndex("audit_logs"), tx.insertIndex("webhook_deliveries")
+	if todoIndex < 0 || auditIndex < todoIndex || webhookIndex < auditIndex {
+		t.Fatalf("queries = %v, want todo, audit log and webhook delivery inserts in order on the same transaction", tx.queries)
+	}
+
+	// 監査ログには操作者・操作・対象と変更後のTodoを記録する
+	args := tx.args[auditIndex]
+	if args[0] != actor.UserId || args[1] != domain_audit.AuditActionCreate || args[2] != domain_audit.AuditEntityTodo || args[3] != "todo-1" {
+		t.Errorf("audit log args = %v, want actor, create, todo, todo-1", args[:4])
+	}
+	if args[6] != actor.RequestId || args[7] != actor.IPAddress {
+		t.Errorf("audit log request = %v, %v, want %s, %s", args[6], args[7], actor.RequestId, actor.IPAddress)
+	}
+	if before := args[4].(*string); before != nil {
+		t.Errorf("audit log before = %s, want NULL", *before)
+	}
+	var after domain_todo.Todo
+	if err := json.Unmarshal([]byte(*args[5].(*string)), &after); err != nil || after.ID != "todo-1" || after.Description != "buy milk" {
+		t.Errorf("audit log after = %s (%v), want the created todo", *args[5].(*string), err)
+	}
+}
+
+// 監査ログの書き込みに失敗した場合はエラーを返し、呼び出し元のトランザクションをロールバックさせる
+func TestCreateTodoInTxFailsWhenAuditLogFails(t *testing.T) {
+	w := NewTodoTxWriter(newTestLogger(), &pkg_supabase.SupabaseClient{Ctx: context.Background()})
+	auditErr := errors.New("audit_logs is unavailable")
+	tx := &fakeTx{auditErr: auditErr}
+
+	todo, err := w.CreateTodoInTx(tx, domain_todo.Todo{Description: "buy milk", UserId: "user-1", Priority: domain_todo.TodoPriorityMedium}, domain_audit.AuditActor{UserId: "user-1"})
+	if !errors.Is(err, auditErr) {
+		t.Fatalf("CreateTodoInTx() error = %v, want %v", err, auditErr)
+	}
+	if todo.ID != "" {
+		t.Errorf("todo = %+v, want zero value", todo)
+	}
+	if tx.insertIndex("webhook_deliveries") >= 0 {
+		t.Errorf("queries = %v, want no webhook delivery after a failed audit log", tx.queries)
+	}
+}
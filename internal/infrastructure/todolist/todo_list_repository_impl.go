@@ -0,0 +1,254 @@
+package infrastructure_todolist
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_todolist "backend/internal/domain/todolist"
+	infrastructure_todo "backend/internal/infrastructure/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_todolist "backend/internal/repository/todolist"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Todoリストリポジトリ(Impl)
+type TodoListRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+	TodoWriter     infrastructure_todo.TodoTxWriter
+}
+
+// Todoリストリポジトリのインスタンス化
+func NewTodoListRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient, tw infrastructure_todo.TodoTxWriter) repository_todolist.ITodoListRepository {
+	return &TodoListRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+		TodoWriter:     tw,
+	}
+}
+
+// 特定のTodoリストを取得
+func (r *TodoListRepositoryImpl) GetTodoListById(id string) (domain_todolist.TodoList, error) {
+	r.Logger.InfoLog.Println("GetTodoListById called")
+
+	query := `
+		SELECT id, user_id, name, color, archived, position, created_at, updated_at
+		FROM todo_lists
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoリストを取得
+	var list domain_todolist.TodoList
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&list.ID,
+			&list.UserId,
+			&list.Name,
+			&list.Color,
+			&list.Archived,
+			&list.Position,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo list: %v", err)
+		return domain_todolist.TodoList{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched todo list: %v", list.ID)
+	return list, nil
+}
+
+// 複数のTodoリストを取得
+func (r *TodoListRepositoryImpl) GetTodoListsByIds(ids []string) ([]domain_todolist.TodoList, error) {
+	r.Logger.InfoLog.Println("GetTodoListsByIds called")
+
+	query := `
+		SELECT id, user_id, name, color, archived, position, created_at, updated_at
+		FROM todo_lists
+		WHERE id = ANY($1)
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoリストを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, ids)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo lists: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists, err := r.scanTodoLists(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d todo lists", len(lists))
+	return lists, nil
+}
+
+// 特定のユーザーのTodoリストを取得(includeArchivedがfalseの場合はアーカイブ済みを除く)
+func (r *TodoListRepositoryImpl) GetTodoListsByUserId(userId string, includeArchived bool) ([]domain_todolist.TodoList, error) {
+	r.Logger.InfoLog.Println("GetTodoListsByUserId called")
+
+	query := `
+		SELECT id, user_id, name, color, archived, position, created_at, updated_at
+		FROM todo_lists
+		WHERE user_id = $1 AND ($2 OR NOT archived)
+		ORDER BY position, created_at
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するTodoリストを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId, includeArchived)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch todo lists: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists, err := r.scanTodoLists(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d todo lists", len(lists))
+	return lists, nil
+}
+
+// 新しいTodoリストを作成(表示順は末尾)
+func (r *TodoListRepositoryImpl) CreateTodoList(list domain_todolist.TodoList) (domain_todolist.TodoList, error) {
+	r.Logger.InfoLog.Println("CreateTodoList called")
+
+	query := `
+		INSERT INTO todo_lists (user_id, name, color, position)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position), -1) + 1 FROM todo_lists WHERE user_id = $1))
+		RETURNING id, user_id, name, color, archived, position, created_at, updated_at
+	`
+
+	// Supabaseからクエリを実行し、Todoリストを作成
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, list.UserId, list.Name, list.Color).
+		Scan(&list.ID,
+			&list.UserId,
+			&list.Name,
+			&list.Color,
+			&list.Archived,
+			&list.Position,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create todo list: %v", err)
+		return domain_todolist.TodoList{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Created todo list: %v", list.ID)
+	return list, nil
+}
+
+// 特定のTodoリストを更新
+func (r *TodoListRepositoryImpl) UpdateTodoList(list domain_todolist.TodoList) (domain_todolist.TodoList, error) {
+	r.Logger.InfoLog.Println("UpdateTodoList called")
+
+	query := `
+		UPDATE todo_lists
+		SET name = $1, color = $2, archived = $3, position = $4, updated_at = now()
+		WHERE id = $5
+		RETURNING id, user_id, name, color, archived, position, created_at, updated_at
+	`
+
+	// Supabaseからクエリを実行し、Todoリストを更新
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, list.Name, list.Color, list.Archived, list.Position, list.ID).
+		Scan(&list.ID,
+			&list.UserId,
+			&list.Name,
+			&list.Color,
+			&list.Archived,
+			&list.Position,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to update todo list: %v", err)
+		return domain_todolist.TodoList{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Updated todo list: %v", list.ID)
+	return list, nil
+}
+
+// 特定のTodoリストを削除し、影響を受けたTodoの件数を返す(監査ログを同一トランザクションで記録する)
+// リスト内のTodoはTodoの書き込みを経由して移動する(イベントソーシングが有効な場合はイベントとして追記する)。
+func (r *TodoListRepositoryImpl) DeleteTodoList(id string, mode string, actor domain_audit.AuditActor) (int, error) {
+	r.Logger.InfoLog.Println("DeleteTodoList called")
+
+	deleteQuery := `
+		DELETE FROM todo_lists
+		WHERE id = $1
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// リスト内のTodoをゴミ箱に移動するか、インボックスに移動する
+	afters, err := r.TodoWriter.DetachTodosFromListInTx(tx, id, mode == domain_todolist.TodoListDeleteModeCascade, actor)
+	if err != nil {
+		return 0, err
+	}
+
+	// Todoリストを削除(ゴミ箱のTodoはインボックスに戻る)
+	tag, err := tx.Exec(r.SupabaseClient.Ctx, deleteQuery, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete todo list: %v", err)
+		return 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		err = pgx.ErrNoRows
+		r.Logger.ErrorLog.Printf("Todo list not found: %v", id)
+		return 0, err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return 0, err
+	}
+
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Deleted todo list: %v (%d todos affected)", id, len(afters))
+	return len(afters), nil
+}
+
+// Todoリストの行を読み込む
+func (r *TodoListRepositoryImpl) scanTodoLists(rows pgx.Rows) ([]domain_todolist.TodoList, error) {
+	lists := []domain_todolist.TodoList{}
+	for rows.Next() {
+		var list domain_todolist.TodoList
+		err := rows.Scan(
+			&list.ID,
+			&list.UserId,
+			&list.Name,
+			&list.Color,
+			&list.Archived,
+			&list.Position,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan todo list: %v", err)
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, rows.Err()
+}
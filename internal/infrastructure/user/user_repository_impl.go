@@ -59,3 +59,44 @@ func (r *UserRepositoryImpl) GetAllUsers() ([]domain_user.Users, error) {
 	r.Logger.InfoLog.Printf("Fetched %d users successfully.", len(users))
 	return users, nil
 }
+
+// 複数のidを指定してユーザーを取得
+func (r *UserRepositoryImpl) GetUsersByIds(ids []string) ([]domain_user.Users, error) {
+	r.Logger.InfoLog.Printf("Fetching %d users by ids from Supabase.", len(ids))
+
+	query := `
+        SELECT id, username, email, created_at, updated_at
+        FROM users
+        WHERE id = ANY($1)
+    `
+
+	// Supabaseからクエリを実行し、条件に一致するユーザーを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, ids)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch users: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// ユーザーのリストを作成
+	users := []domain_user.Users{}
+	for rows.Next() {
+		var user domain_user.Users
+		err = rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan user: %v", err)
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	// ユーザーのリストを返す
+	r.Logger.InfoLog.Printf("Fetched %d users successfully.", len(users))
+	return users, nil
+}
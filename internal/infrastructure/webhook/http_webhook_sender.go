@@ -0,0 +1,140 @@
+package infrastructure_webhook
+
+import (
+	domain_webhook "backend/internal/domain/webhook"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_webhook "backend/internal/repository/webhook"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 送信時のUser-Agent
+const webhookUserAgent = "todo-app-webhook/1.0"
+
+// 内部のアドレスへの送信を拒否した場合のエラー
+var errDestinationNotAllowed = errors.New("destination address is not allowed")
+
+// 共有アドレス空間(RFC 6598、クラウドのメタデータなどに使われる)
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// HTTPによるWebhookの送信(Impl)
+type HTTPWebhookSender struct {
+	Logger *pkg_logger.AppLogger
+	client *http.Client
+}
+
+// HTTPによるWebhookの送信のインスタンス化
+// リダイレクトはたどらず、3xxの応答は失敗として扱う。
+// allowPrivateNetworksがfalseの場合は、名前解決した後の接続先のアドレスを検証し、内部のアドレスへの接続を拒否する
+// (接続時に検証するため、DNSの応答を切り替えて検証を回避することはできない)。
+func NewHTTPWebhookSender(l *pkg_logger.AppLogger, timeout time.Duration, allowPrivateNetworks bool) repository_webhook.IWebhookSender {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+	}
+	if !allowPrivateNetworks {
+		dialer.Control = func(network string, address string, c syscall.RawConn) error {
+			if !isAllowedDestination(address) {
+				l.ErrorLog.Printf("Blocked webhook destination: %s", address)
+				return errDestinationNotAllowed
+			}
+			return nil
+		}
+	}
+
+	return &HTTPWebhookSender{
+		Logger: l,
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				// プロキシを経由すると接続先のアドレスを検証できないため、使わない
+				Proxy:                 nil,
+				DialContext:           dialer.DialContext,
+				ForceAttemptHTTP2:     true,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// 接続先のアドレス(IPアドレス:ポート)に送信してよいかどうか
+// ループバック・プライベート・リンクローカル・未指定・マルチキャスト・共有アドレス空間のアドレスは拒否する。
+func isAllowedDestination(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip))
+}
+
+// Webhookを送信する(応答が無い場合はエラーを返す)
+func (s *HTTPWebhookSender) Send(request domain_webhook.WebhookRequest) (domain_webhook.WebhookResponse, error) {
+	s.Logger.InfoLog.Printf("Sending webhook: %s (%s)", request.DeliveryId, request.EventType)
+
+	req, err := http.NewRequest(http.MethodPost, request.URL, bytes.NewReader(request.Payload))
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Failed to create webhook request: %v", err)
+		return domain_webhook.WebhookResponse{}, err
+	}
+	timestamp := request.Timestamp.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(domain_webhook.WebhookHeaderId, request.DeliveryId)
+	req.Header.Set(domain_webhook.WebhookHeaderEvent, request.EventType)
+	req.Header.Set(domain_webhook.WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(domain_webhook.WebhookHeaderSignature, domain_webhook.SignWebhookPayload(request.Secret, timestamp, request.Payload))
+
+	start := time.Now()
+	res, err := s.client.Do(req)
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Failed to send webhook: %v", err)
+		return domain_webhook.WebhookResponse{Duration: time.Since(start)}, err
+	}
+	defer res.Body.Close()
+
+	// 本文は先頭のみ記録し、残りは読み捨てて接続を再利用できるようにする
+	body, err := io.ReadAll(io.LimitReader(res.Body, domain_webhook.MaxWebhookResponseBodyBytes))
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Failed to read webhook response: %v", err)
+	}
+	io.Copy(io.Discard, res.Body)
+
+	response := domain_webhook.WebhookResponse{
+		StatusCode: res.StatusCode,
+		Body:       sanitizeResponseBody(body),
+		Duration:   time.Since(start),
+	}
+	s.Logger.InfoLog.Printf("Sent webhook: %s (status: %d)", request.DeliveryId, res.StatusCode)
+	return response, nil
+}
+
+// 応答の本文をデータベースに保存できる文字列にする(途中で切った文字とNULを除く)
+func sanitizeResponseBody(body []byte) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", "")
+}
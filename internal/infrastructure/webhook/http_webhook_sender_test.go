@@ -0,0 +1,173 @@
+package infrastructure_webhook
+
+import (
+	domain_webhook "backend/internal/domain/webhook"
+	pkg_logger "backend/internal/pkg/logger"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+func TestHTTPWebhookSenderSignsPayload(t *testing.T) {
+	const secret = "0123456789abcdef"
+	payload := []byte(`{"id":"1","event":"todo.created"}`)
+	timestamp := time.Unix(1700000000, 0)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// httptestのサーバーはループバックのため、内部のアドレスへの送信を許可する
+	sender := NewHTTPWebhookSender(newTestLogger(), 5*time.Second, true)
+	response, err := sender.Send(domain_webhook.WebhookRequest{
+		URL:        server.URL,
+		Secret:     secret,
+		DeliveryId: "delivery-1",
+		EventType:  domain_webhook.WebhookEventTodoCreated,
+		Payload:    payload,
+		Timestamp:  timestamp,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusAccepted || !response.IsSuccess() || response.Body != "ok" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	if received.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", received.Method)
+	}
+	if got := received.Header.Get(domain_webhook.WebhookHeaderId); got != "delivery-1" {
+		t.Errorf("%s = %q, want delivery-1", domain_webhook.WebhookHeaderId, got)
+	}
+	if got := received.Header.Get(domain_webhook.WebhookHeaderEvent); got != domain_webhook.WebhookEventTodoCreated {
+		t.Errorf("%s = %q, want %s", domain_webhook.WebhookHeaderEvent, got, domain_webhook.WebhookEventTodoCreated)
+	}
+	if string(body) != string(payload) {
+		t.Errorf("body = %s, want %s", body, payload)
+	}
+
+	// 受信側と同じ手順で署名を検証できる
+	ts, err := strconv.ParseInt(received.Header.Get(domain_webhook.WebhookHeaderTimestamp), 10, 64)
+	if err != nil || ts != timestamp.Unix() {
+		t.Fatalf("%s = %q, want %d", domain_webhook.WebhookHeaderTimestamp, received.Header.Get(domain_webhook.WebhookHeaderTimestamp), timestamp.Unix())
+	}
+	signature := received.Header.Get(domain_webhook.WebhookHeaderSignature)
+	if !domain_webhook.VerifyWebhookSignature(secret, ts, body, signature) {
+		t.Errorf("signature %q is not valid", signature)
+	}
+	if domain_webhook.VerifyWebhookSignature("another-secret-value", ts, body, signature) {
+		t.Error("signature is valid with another secret")
+	}
+}
+
+func TestHTTPWebhookSenderDoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	sender := NewHTTPWebhookSender(newTestLogger(), 5*time.Second, true)
+	response, err := sender.Send(domain_webhook.WebhookRequest{URL: server.URL, Secret: "0123456789abcdef", DeliveryId: "delivery-1", Payload: []byte(`{}`), Timestamp: time.Now()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.StatusCode != http.StatusTemporaryRedirect || response.IsSuccess() {
+		t.Errorf("status = %d, want %d", response.StatusCode, http.StatusTemporaryRedirect)
+	}
+	if redirected {
+		t.Error("redirect was followed")
+	}
+}
+
+func TestHTTPWebhookSenderTruncatesResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(strings.Repeat("x", domain_webhook.MaxWebhookResponseBodyBytes*4)))
+	}))
+	defer server.Close()
+
+	sender := NewHTTPWebhookSender(newTestLogger(), 5*time.Second, true)
+	response, err := sender.Send(domain_webhook.WebhookRequest{URL: server.URL, Secret: "0123456789abcdef", DeliveryId: "delivery-1", Payload: []byte(`{}`), Timestamp: time.Now()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(response.Body) != domain_webhook.MaxWebhookResponseBodyBytes {
+		t.Errorf("body length = %d, want %d", len(response.Body), domain_webhook.MaxWebhookResponseBodyBytes)
+	}
+}
+
+// 既定では、名前解決した後のアドレスがループバックの場合に接続しない
+func TestHTTPWebhookSenderBlocksPrivateNetworks(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	// ホスト名で指定しても、接続時のアドレスで拒否する
+	byName := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	for _, u := range []string{server.URL, byName} {
+		sender := NewHTTPWebhookSender(newTestLogger(), 5*time.Second, false)
+		_, err := sender.Send(domain_webhook.WebhookRequest{URL: u, Secret: "0123456789abcdef", DeliveryId: "delivery-1", Payload: []byte(`{}`), Timestamp: time.Now()})
+		if !errors.Is(err, errDestinationNotAllowed) {
+			t.Errorf("Send(%s): error = %v, want %v", u, err, errDestinationNotAllowed)
+		}
+	}
+	if requested {
+		t.Error("request reached the private address")
+	}
+}
+
+func TestIsAllowedDestination(t *testing.T) {
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{address: "93.184.216.34:443", want: true},
+		{address: "[2606:2800:220:1:248:1893:25c8:1946]:443", want: true},
+		{address: "127.0.0.1:80", want: false},
+		{address: "[::1]:80", want: false},
+		{address: "10.0.0.1:80", want: false},
+		{address: "172.16.0.1:80", want: false},
+		{address: "192.168.1.1:80", want: false},
+		{address: "169.254.169.254:80", want: false},
+		{address: "100.100.100.200:80", want: false},
+		{address: "0.0.0.0:80", want: false},
+		{address: "[::]:80", want: false},
+		{address: "[fc00::1]:80", want: false},
+		{address: "[fe80::1%eth0]:80", want: false},
+		{address: "[::ffff:127.0.0.1]:80", want: false},
+		{address: "224.0.0.1:80", want: false},
+		{address: "example.com:80", want: false},
+		{address: "invalid", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isAllowedDestination(tt.address); got != tt.want {
+			t.Errorf("isAllowedDestination(%q) = %v, want %v", tt.address, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package infrastructure_webhook
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_webhook "backend/internal/domain/webhook"
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Todoの監査ログに対応する配信を、所有者の有効なWebhookのうちイベントを購読しているものに登録する
+// 監査ログと同じトランザクションで呼び出すこと(ロールバックされた変更は配信されない)。
+func InsertTodoWebhookDeliveries(ctx context.Context, tx pgx.Tx, log domain_audit.AuditLog) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT id, $2, $3
+		FROM webhooks
+		WHERE user_id = $1
+		  AND active
+		  AND $2 = ANY (event_types)
+	`
+
+	ownerId, payloads := domain_webhook.TodoEventsFromAuditLog(log)
+	for _, payload := range payloads {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, query, ownerId, payload.Event, string(data))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,641 @@
+package infrastructure_webhook
+
+import (
+	domain_webhook "backend/internal/domain/webhook"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_secretbox "backend/internal/pkg/secretbox"
+	pkg_supabase "backend/internal/pkg/supabase"
+	repository_webhook "backend/internal/repository/webhook"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// 配信の取得時の列(payloadはJSONの文字列として取得する)
+const deliveryColumns = `id, webhook_id, event_type, payload::text, status, attempts, next_attempt_at, last_response_code, last_error, redelivery_of, created_at, updated_at, completed_at`
+
+// Webhookリポジトリ(Impl)
+type WebhookRepositoryImpl struct {
+	Logger         *pkg_logger.AppLogger
+	SupabaseClient *pkg_supabase.SupabaseClient
+	secretBox      *pkg_secretbox.SecretBox
+}
+
+// Webhookリポジトリのインスタンス化
+// sbがnilでない場合は、シークレットを暗号化して保存する(nilの場合は平文のまま保存する)。
+func NewWebhookRepository(l *pkg_logger.AppLogger, sc *pkg_supabase.SupabaseClient, sb *pkg_secretbox.SecretBox) repository_webhook.IWebhookRepository {
+	return &WebhookRepositoryImpl{
+		Logger:         l,
+		SupabaseClient: sc,
+		secretBox:      sb,
+	}
+}
+
+// 特定のWebhookを取得
+func (r *WebhookRepositoryImpl) GetWebhookById(id string) (domain_webhook.Webhook, error) {
+	r.Logger.InfoLog.Println("GetWebhookById called")
+
+	query := `
+		SELECT id, user_id, url, event_types, secret, active, created_at, updated_at
+		FROM webhooks
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するWebhookを取得
+	var webhook domain_webhook.Webhook
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id).
+		Scan(&webhook.ID,
+			&webhook.UserId,
+			&webhook.URL,
+			&webhook.EventTypes,
+			&webhook.Secret,
+			&webhook.Active,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Webhook not found: %v", id)
+		return domain_webhook.Webhook{}, repository_webhook.ErrWebhookNotFound
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch webhook: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+	webhook.Secret, err = r.openSecret(webhook.Secret)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to open webhook secret: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched webhook: %v", webhook.ID)
+	return webhook, nil
+}
+
+// 特定のユーザーのWebhookを取得
+func (r *WebhookRepositoryImpl) GetWebhooksByUserId(userId string) ([]domain_webhook.Webhook, error) {
+	r.Logger.InfoLog.Println("GetWebhooksByUserId called")
+
+	query := `
+		SELECT id, user_id, url, event_types, secret, active, created_at, updated_at
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	// Supabaseからクエリを実行し、条件に一致するWebhookを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, userId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch webhooks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Webhookのリストを作成
+	webhooks := []domain_webhook.Webhook{}
+	for rows.Next() {
+		var webhook domain_webhook.Webhook
+		err = rows.Scan(
+			&webhook.ID,
+			&webhook.UserId,
+			&webhook.URL,
+			&webhook.EventTypes,
+			&webhook.Secret,
+			&webhook.Active,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan webhook: %v", err)
+			return nil, err
+		}
+		webhook.Secret, err = r.openSecret(webhook.Secret)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to open webhook secret: %v", err)
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch webhooks: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d webhooks", len(webhooks))
+	return webhooks, nil
+}
+
+// 特定のユーザーのWebhookの件数を取得
+func (r *WebhookRepositoryImpl) CountWebhooksByUserId(userId string) (int, error) {
+	r.Logger.InfoLog.Println("CountWebhooksByUserId called")
+
+	query := `
+		SELECT COUNT(*)
+		FROM webhooks
+		WHERE user_id = $1
+	`
+
+	// Supabaseからクエリを実行し、Webhookの件数を取得
+	var count int
+	err := r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, userId).Scan(&count)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to count webhooks: %v", err)
+		return 0, err
+	}
+
+	r.Logger.InfoLog.Printf("Counted %d webhooks", count)
+	return count, nil
+}
+
+// 新しいWebhookを作成
+func (r *WebhookRepositoryImpl) CreateWebhook(webhook domain_webhook.Webhook) (domain_webhook.Webhook, error) {
+	r.Logger.InfoLog.Println("CreateWebhook called")
+
+	query := `
+		INSERT INTO webhooks (user_id, url, event_types, secret, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, url, event_types, secret, active, created_at, updated_at
+	`
+
+	secret, err := r.sealSecret(webhook.Secret)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to seal webhook secret: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+
+	// Supabaseからクエリを実行し、Webhookを作成(シークレットは暗号化前の値を返す)
+	err = r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, webhook.UserId, webhook.URL, webhook.EventTypes, secret, webhook.Active).
+		Scan(&webhook.ID,
+			&webhook.UserId,
+			&webhook.URL,
+			&webhook.EventTypes,
+			&secret,
+			&webhook.Active,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create webhook: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Created webhook: %v", webhook.ID)
+	return webhook, nil
+}
+
+// 特定のWebhookを更新
+func (r *WebhookRepositoryImpl) UpdateWebhook(webhook domain_webhook.Webhook) (domain_webhook.Webhook, error) {
+	r.Logger.InfoLog.Println("UpdateWebhook called")
+
+	query := `
+		UPDATE webhooks
+		SET url = $1, event_types = $2, secret = $3, active = $4, updated_at = now()
+		WHERE id = $5
+		RETURNING id, user_id, url, event_types, secret, active, created_at, updated_at
+	`
+
+	secret, err := r.sealSecret(webhook.Secret)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to seal webhook secret: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+
+	// Supabaseからクエリを実行し、Webhookを更新(シークレットは暗号化前の値を返す)
+	err = r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, webhook.URL, webhook.EventTypes, secret, webhook.Active, webhook.ID).
+		Scan(&webhook.ID,
+			&webhook.UserId,
+			&webhook.URL,
+			&webhook.EventTypes,
+			&secret,
+			&webhook.Active,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Webhook not found: %v", webhook.ID)
+		return domain_webhook.Webhook{}, repository_webhook.ErrWebhookNotFound
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to update webhook: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Updated webhook: %v", webhook.ID)
+	return webhook, nil
+}
+
+// 特定のWebhookを削除(配信と送信履歴も削除する)
+func (r *WebhookRepositoryImpl) DeleteWebhook(id string) error {
+	r.Logger.InfoLog.Println("DeleteWebhook called")
+
+	query := `
+		DELETE FROM webhooks
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、Webhookを削除
+	tag, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, query, id)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to delete webhook: %v", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		r.Logger.InfoLog.Printf("Webhook not found: %v", id)
+		return repository_webhook.ErrWebhookNotFound
+	}
+
+	r.Logger.InfoLog.Printf("Deleted webhook: %v", id)
+	return nil
+}
+
+// 特定の配信を取得
+func (r *WebhookRepositoryImpl) GetDeliveryById(id string) (domain_webhook.WebhookDelivery, error) {
+	r.Logger.InfoLog.Println("GetDeliveryById called")
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE id = $1
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する配信を取得
+	delivery, err := scanDelivery(r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Webhook delivery not found: %v", id)
+		return domain_webhook.WebhookDelivery{}, repository_webhook.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch webhook delivery: %v", err)
+		return domain_webhook.WebhookDelivery{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched webhook delivery: %v", delivery.ID)
+	return delivery, nil
+}
+
+// 特定のWebhookの配信を新しい順に取得(statusが空の場合は全て)
+func (r *WebhookRepositoryImpl) GetDeliveriesByWebhookId(webhookId string, status string, limit int, offset int) ([]domain_webhook.WebhookDelivery, error) {
+	r.Logger.InfoLog.Println("GetDeliveriesByWebhookId called")
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		  AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する配信を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, webhookId, status, limit, offset)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// 配信のリストを作成
+	deliveries := []domain_webhook.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan webhook delivery: %v", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d webhook deliveries", len(deliveries))
+	return deliveries, nil
+}
+
+// 特定の配信の送信履歴を取得
+func (r *WebhookRepositoryImpl) GetDeliveryAttempts(deliveryId string) ([]domain_webhook.WebhookDeliveryAttempt, error) {
+	r.Logger.InfoLog.Println("GetDeliveryAttempts called")
+
+	query := `
+		SELECT id, delivery_id, attempt, response_code, response_body, error, duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempt, created_at
+	`
+
+	// Supabaseからクエリを実行し、条件に一致する送信履歴を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, deliveryId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch webhook delivery attempts: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// 送信履歴のリストを作成
+	attempts := []domain_webhook.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var attempt domain_webhook.WebhookDeliveryAttempt
+		err = rows.Scan(
+			&attempt.ID,
+			&attempt.DeliveryId,
+			&attempt.Attempt,
+			&attempt.ResponseCode,
+			&attempt.ResponseBody,
+			&attempt.Error,
+			&attempt.DurationMs,
+			&attempt.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan webhook delivery attempt: %v", err)
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch webhook delivery attempts: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Fetched %d webhook delivery attempts", len(attempts))
+	return attempts, nil
+}
+
+// 配信と同じ内容の配信を新しく登録する(手動の再送)
+func (r *WebhookRepositoryImpl) CreateRedelivery(deliveryId string) (domain_webhook.WebhookDelivery, error) {
+	r.Logger.InfoLog.Println("CreateRedelivery called")
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload, redelivery_of)
+		SELECT webhook_id, event_type, payload, id
+		FROM webhook_deliveries
+		WHERE id = $1
+		RETURNING ` + deliveryColumns
+
+	// Supabaseからクエリを実行し、配信を登録
+	delivery, err := scanDelivery(r.SupabaseClient.Pool.QueryRow(r.SupabaseClient.Ctx, query, deliveryId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		r.Logger.InfoLog.Printf("Webhook delivery not found: %v", deliveryId)
+		return domain_webhook.WebhookDelivery{}, repository_webhook.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to create webhook redelivery: %v", err)
+		return domain_webhook.WebhookDelivery{}, err
+	}
+
+	r.Logger.InfoLog.Printf("Created webhook redelivery: %v", delivery.ID)
+	return delivery, nil
+}
+
+// 送信日時を過ぎた配信を確保する(送信回数を加算し、leaseの間は他で確保されないようにする)
+// 送信中に停止した場合も、leaseを過ぎると再び確保される。無効なWebhookの配信は有効に戻るまで確保しない。
+func (r *WebhookRepositoryImpl) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]domain_webhook.WebhookDispatch, error) {
+	r.Logger.InfoLog.Println("ClaimDueDeliveries called")
+
+	query := `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, next_attempt_at = $2, updated_at = now()
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+		  AND d.id IN (
+		    SELECT dd.id
+		    FROM webhook_deliveries dd
+		    JOIN webhooks ww ON ww.id = dd.webhook_id
+		    WHERE dd.status = 'pending'
+		      AND dd.next_attempt_at <= $1
+		      AND ww.active
+		    ORDER BY dd.next_attempt_at
+		    LIMIT $3
+		    FOR UPDATE OF dd SKIP LOCKED
+		  )
+		RETURNING d.id, d.webhook_id, d.event_type, d.payload::text, d.status, d.attempts, d.next_attempt_at, d.last_response_code, d.last_error, d.redelivery_of, d.created_at, d.updated_at, d.completed_at, w.url, w.secret
+	`
+
+	// Supabaseからクエリを実行し、送信対象の配信を取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to claim due webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// 送信対象のリストを作成
+	dispatches := []domain_webhook.WebhookDispatch{}
+	for rows.Next() {
+		var dispatch domain_webhook.WebhookDispatch
+		var payload string
+		d := &dispatch.Delivery
+		err = rows.Scan(
+			&d.ID,
+			&d.WebhookId,
+			&d.EventType,
+			&payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastResponseCode,
+			&d.LastError,
+			&d.RedeliveryOf,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+			&d.CompletedAt,
+			&dispatch.URL,
+			&dispatch.Secret,
+		)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to scan webhook delivery: %v", err)
+			return nil, err
+		}
+		// 復号できない場合は送信せず、leaseを過ぎた後に再び確保する
+		dispatch.Secret, err = r.openSecret(dispatch.Secret)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to open webhook secret: %v", err)
+			return nil, err
+		}
+		d.Payload = []byte(payload)
+		dispatches = append(dispatches, dispatch)
+	}
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to claim due webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	r.Logger.InfoLog.Printf("Claimed %d due webhook deliveries", len(dispatches))
+	return dispatches, nil
+}
+
+// 送信の結果を記録する(nextAttemptAtがnilの場合は成功・失敗を確定する)
+func (r *WebhookRepositoryImpl) RecordDeliveryAttempt(attempt domain_webhook.WebhookDeliveryAttempt, status string, nextAttemptAt *time.Time) error {
+	r.Logger.InfoLog.Println("RecordDeliveryAttempt called")
+
+	attemptQuery := `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, response_code, response_body, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	deliveryQuery := `
+		UPDATE webhook_deliveries
+		SET status = $1,
+		    next_attempt_at = $2,
+		    last_response_code = $3,
+		    last_error = NULLIF($4, ''),
+		    updated_at = now(),
+		    completed_at = CASE WHEN $1 = 'pending' THEN NULL ELSE now() END
+		WHERE id = $5
+	`
+
+	// トランザクションを開始
+	tx, err := r.SupabaseClient.Pool.Begin(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to rollback transaction: %v", err)
+			tx.Rollback(r.SupabaseClient.Ctx)
+		}
+	}()
+
+	// 送信履歴を記録
+	_, err = tx.Exec(r.SupabaseClient.Ctx, attemptQuery, attempt.DeliveryId, attempt.Attempt, attempt.ResponseCode, attempt.ResponseBody, attempt.Error, attempt.DurationMs)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to insert webhook delivery attempt: %v", err)
+		return err
+	}
+
+	// 配信の状態を更新
+	tag, err := tx.Exec(r.SupabaseClient.Ctx, deliveryQuery, status, nextAttemptAt, attempt.ResponseCode, attempt.Error, attempt.DeliveryId)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to update webhook delivery: %v", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		err = repository_webhook.ErrWebhookDeliveryNotFound
+		r.Logger.ErrorLog.Printf("Webhook delivery not found: %v", attempt.DeliveryId)
+		return err
+	}
+
+	// トランザクションをコミット
+	err = tx.Commit(r.SupabaseClient.Ctx)
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to commit transaction: %v", err)
+		return err
+	}
+	// 正常系にし、ロールバックを防ぐ
+	err = nil
+
+	r.Logger.InfoLog.Printf("Recorded webhook delivery attempt: %v (%s)", attempt.DeliveryId, status)
+	return nil
+}
+
+// 配信の行を読み込む
+func scanDelivery(row pgx.Row) (domain_webhook.WebhookDelivery, error) {
+	var delivery domain_webhook.WebhookDelivery
+	var payload string
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookId,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastResponseCode,
+		&delivery.LastError,
+		&delivery.RedeliveryOf,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+		&delivery.CompletedAt,
+	)
+	if err != nil {
+		return domain_webhook.WebhookDelivery{}, err
+	}
+	delivery.Payload = []byte(payload)
+	return delivery, nil
+}
+
+// 平文のまま保存されているシークレットを暗号化し、暗号化した件数を返す(鍵が設定されていない場合は何もしない)
+// 暗号化の間に更新されたシークレットは上書きしない。
+func (r *WebhookRepositoryImpl) EncryptPlaintextSecrets() (int, error) {
+	r.Logger.InfoLog.Println("EncryptPlaintextSecrets called")
+
+	if r.secretBox == nil {
+		r.Logger.InfoLog.Println("Webhook secret key is not configured")
+		return 0, nil
+	}
+
+	query := `
+		SELECT id, secret
+		FROM webhooks
+		WHERE secret NOT LIKE $1
+	`
+
+	// Supabaseからクエリを実行し、平文のシークレットを取得
+	rows, err := r.SupabaseClient.Pool.Query(r.SupabaseClient.Ctx, query, pkg_secretbox.SealedPrefix+"%")
+	if err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch plaintext webhook secrets: %v", err)
+		return 0, err
+	}
+	type plaintextSecret struct {
+		id     string
+		secret string
+	}
+	secrets := []plaintextSecret{}
+	for rows.Next() {
+		var s plaintextSecret
+		if err = rows.Scan(&s.id, &s.secret); err != nil {
+			rows.Close()
+			r.Logger.ErrorLog.Printf("Failed to scan webhook secret: %v", err)
+			return 0, err
+		}
+		secrets = append(secrets, s)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.Logger.ErrorLog.Printf("Failed to fetch plaintext webhook secrets: %v", err)
+		return 0, err
+	}
+
+	updateQuery := `
+		UPDATE webhooks
+		SET secret = $1
+		WHERE id = $2 AND secret = $3
+	`
+
+	encrypted := 0
+	for _, s := range secrets {
+		sealed, err := r.secretBox.Seal(s.secret)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to seal webhook secret: %v", err)
+			return encrypted, err
+		}
+		tag, err := r.SupabaseClient.Pool.Exec(r.SupabaseClient.Ctx, updateQuery, sealed, s.id, s.secret)
+		if err != nil {
+			r.Logger.ErrorLog.Printf("Failed to encrypt webhook secret: %v", err)
+			return encrypted, err
+		}
+		encrypted += int(tag.RowsAffected())
+	}
+
+	r.Logger.InfoLog.Printf("Encrypted %d webhook secrets", encrypted)
+	return encrypted, nil
+}
+
+// シークレットを保存する形式にする(鍵が設定されている場合は暗号化する)
+func (r *WebhookRepositoryImpl) sealSecret(secret string) (string, error) {
+	if r.secretBox == nil {
+		return secret, nil
+	}
+	return r.secretBox.Seal(secret)
+}
+
+// 保存されたシークレットを取り出す(暗号化されていない値はそのまま返す)
+func (r *WebhookRepositoryImpl) openSecret(stored string) (string, error) {
+	if !pkg_secretbox.IsSealed(stored) {
+		return stored, nil
+	}
+	if r.secretBox == nil {
+		return "", errors.New("webhook secret key is not configured")
+	}
+	return r.secretBox.Open(stored)
+}
@@ -0,0 +1,91 @@
+package interfaces_attachment
+
+import (
+	interfaces_auth "backend/internal/interfaces/auth"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_timer "backend/internal/pkg/timer"
+	usecase_attachment "backend/internal/usecase/attachment"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 添付ファイルのダウンロードハンドラ(Impl)
+type AttachmentHandler struct {
+	Logger            *pkg_logger.AppLogger
+	timer             *pkg_timer.TimerPkg
+	authHandler       *interfaces_auth.AuthHandler
+	attachmentUsecase usecase_attachment.IAttachmentUsecase
+}
+
+// 添付ファイルのダウンロードハンドラのインスタンス化
+func NewAttachmentHandler(l *pkg_logger.AppLogger, ah *interfaces_auth.AuthHandler, au usecase_attachment.IAttachmentUsecase) *AttachmentHandler {
+	return &AttachmentHandler{
+		Logger:            l,
+		timer:             pkg_timer.NewTimerPkg(),
+		authHandler:       ah,
+		attachmentUsecase: au,
+	}
+}
+
+// 添付ファイルをダウンロード(GET /attachments/:id)
+// 添付したTodoを参照できるユーザーのみ取得できる。
+func (h *AttachmentHandler) DownloadAttachment(c echo.Context) error {
+	h.Logger.InfoLog.Println("Downloading attachment...")
+	h.timer.Start()
+
+	ctx, err := h.authHandler.ParseAndAuthorizeToken(c, h.authHandler.AppConfig.UserRole)
+	if err != nil {
+		h.Logger.ErrorLog.Printf("unauthorized: %v", err)
+		h.Logger.PrintDuration("Downloading attachment", h.timer.GetDuration())
+		return err
+	}
+	userId, ok := ctx.Value(h.authHandler.AppConfig.UserID).(string)
+	if !ok || userId == "" {
+		h.Logger.ErrorLog.Println("unauthorized")
+		h.Logger.PrintDuration("Downloading attachment", h.timer.GetDuration())
+		return echo.NewHTTPError(http.StatusUnauthorized, "unauthorized")
+	}
+
+	attachment, content, err := h.attachmentUsecase.OpenAttachment(userId, c.Param("id"))
+	if err != nil {
+		h.Logger.ErrorLog.Printf("Failed to open attachment: %v", err)
+		h.Logger.PrintDuration("Downloading attachment", h.timer.GetDuration())
+		switch err.Error() {
+		case "id is empty", "attachment not found", "todo not found":
+			return echo.NewHTTPError(http.StatusNotFound, "attachment not found")
+		case "forbidden":
+			return echo.NewHTTPError(http.StatusForbidden, "forbidden")
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to download attachment")
+		}
+	}
+	defer content.Close()
+
+	// ブラウザで開かず保存させ、内容からの種類の推測もさせない
+	res := c.Response()
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	res.Header().Set(echo.HeaderContentType, attachment.ContentType)
+	res.Header().Set(echo.HeaderContentLength, strconv.FormatInt(attachment.Size, 10))
+	res.Header().Set(echo.HeaderContentDisposition, disposition)
+	res.Header().Set(echo.HeaderXContentTypeOptions, "nosniff")
+	res.Header().Set("Cache-Control", "private, no-store")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(res, content); err != nil {
+		// 送信を始めた後はステータスを変えられないため、途中で打ち切る
+		h.Logger.ErrorLog.Printf("Failed to send attachment: %v", err)
+		h.Logger.PrintDuration("Downloading attachment", h.timer.GetDuration())
+		return nil
+	}
+
+	h.Logger.InfoLog.Printf("Downloaded attachment: %s", attachment.ID)
+	h.Logger.PrintDuration("Downloading attachment", h.timer.GetDuration())
+	return nil
+}
@@ -0,0 +1,45 @@
+package interfaces_auth
+
+import (
+	"context"
+	"slices"
+)
+
+// コンテキストキーの型
+type contextKey string
+
+// コンテキストキー
+const (
+	// クライアントのIPアドレス
+	clientIPContextKey contextKey = "client_ip"
+	// リクエストID
+	requestIDContextKey contextKey = "request_id"
+)
+
+// コンテキストにクライアントのIPアドレスを設定
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey, ip)
+}
+
+// コンテキストからクライアントのIPアドレスを取得
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey).(string)
+	return ip
+}
+
+// コンテキストにリクエストIDを設定
+func WithRequestID(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestId)
+}
+
+// コンテキストからリクエストIDを取得
+func RequestIDFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIDContextKey).(string)
+	return requestId
+}
+
+// 管理者かどうか
+// 管理者は環境変数ADMIN_USER_IDSに指定されたユーザーとする。
+func (h *AuthHandler) IsAdmin(userId string) bool {
+	return userId != "" && slices.Contains(h.AppConfig.AdminUserIDs, userId)
+}
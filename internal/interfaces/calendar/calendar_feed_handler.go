@@ -0,0 +1,102 @@
+package interfaces_calendar
+
+import (
+	domain_calendar "backend/internal/domain/calendar"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_timer "backend/internal/pkg/timer"
+	usecase_calendar "backend/internal/usecase/calendar"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// フィードのファイル名の拡張子
+const calendarFeedExtension = ".ics"
+
+// カレンダーの購読フィードハンドラ(Impl)
+type CalendarFeedHandler struct {
+	Logger      *pkg_logger.AppLogger
+	timer       *pkg_timer.TimerPkg
+	feedUsecase usecase_calendar.ICalendarFeedUsecase
+	cacheMaxAge time.Duration
+}
+
+// カレンダーの購読フィードハンドラのインスタンス化
+// cacheMaxAgeはクライアントにキャッシュを許可する時間。
+func NewCalendarFeedHandler(l *pkg_logger.AppLogger, fu usecase_calendar.ICalendarFeedUsecase, cacheMaxAge time.Duration) *CalendarFeedHandler {
+	return &CalendarFeedHandler{
+		Logger:      l,
+		timer:       pkg_timer.NewTimerPkg(),
+		feedUsecase: fu,
+		cacheMaxAge: cacheMaxAge,
+	}
+}
+
+// 秘密のトークンでフィードを取得(GET /calendar/:token.ics?type=event|todo|both)
+// カレンダーアプリはAuthorizationヘッダーを送れないため、URLのトークンで認証する。
+func (h *CalendarFeedHandler) GetFeed(c echo.Context) error {
+	h.Logger.InfoLog.Println("Fetching calendar feed...")
+	h.timer.Start()
+
+	file := c.Param("file")
+	if !strings.HasSuffix(file, calendarFeedExtension) {
+		h.Logger.ErrorLog.Println("feed not found")
+		h.Logger.PrintDuration("Fetching calendar feed", h.timer.GetDuration())
+		return echo.NewHTTPError(http.StatusNotFound, "feed not found")
+	}
+	token := strings.TrimSuffix(file, calendarFeedExtension)
+
+	feedType := c.QueryParam("type")
+	if feedType == "" {
+		feedType = domain_calendar.CalendarFeedTypeEvent
+	}
+
+	body, err := h.feedUsecase.RenderFeed(token, feedType)
+	if err != nil {
+		h.Logger.ErrorLog.Printf("Failed to render calendar feed: %v", err)
+		h.Logger.PrintDuration("Fetching calendar feed", h.timer.GetDuration())
+		switch err.Error() {
+		case "feed not found":
+			return echo.NewHTTPError(http.StatusNotFound, "feed not found")
+		case "invalid feed type":
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid feed type")
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to render calendar feed")
+		}
+	}
+
+	// 本文のハッシュをETagとし、変更がなければ304を返す
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	res := c.Response()
+	res.Header().Set("ETag", etag)
+	res.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(h.cacheMaxAge/time.Second)))
+	// URLが秘密の情報のため、検索エンジンに載せない
+	res.Header().Set("X-Robots-Tag", "noindex")
+	if matchETag(c.Request().Header.Get("If-None-Match"), etag) {
+		h.Logger.InfoLog.Println("Calendar feed not modified")
+		h.Logger.PrintDuration("Fetching calendar feed", h.timer.GetDuration())
+		return c.NoContent(http.StatusNotModified)
+	}
+	res.Header().Set(echo.HeaderContentDisposition, `inline; filename="todo.ics"`)
+
+	h.Logger.InfoLog.Printf("Fetched calendar feed (%d bytes)", len(body))
+	h.Logger.PrintDuration("Fetching calendar feed", h.timer.GetDuration())
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+// If-None-MatchのいずれかのETagが一致するかどうか
+func matchETag(header string, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
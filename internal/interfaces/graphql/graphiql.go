@@ -0,0 +1,16 @@
+package interfaces_graphql
+
+import (
+	_ "embed"
+)
+
+// GraphiQLのページ
+//
+//go:embed graphiql.html
+var graphiQLPage []byte
+
+// GraphiQLのページを取得
+// 開発環境でスキーマを確認するためのもので、本番環境では公開しないこと。
+func GraphiQLPage() []byte {
+	return graphiQLPage
+}
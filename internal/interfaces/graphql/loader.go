@@ -0,0 +1,447 @@
+package interfaces_graphql
+
+import (
+	domain_attachment "backend/internal/domain/attachment"
+	domain_audit "backend/internal/domain/audit"
+	domain_comment "backend/internal/domain/comment"
+	domain_recurrence "backend/internal/domain/recurrence"
+	domain_tag "backend/internal/domain/tag"
+	domain_todo "backend/internal/domain/todo"
+	domain_todolist "backend/internal/domain/todolist"
+	domain_user "backend/internal/domain/user"
+	pkg_dataloader "backend/internal/pkg/dataloader"
+	"context"
+	"errors"
+)
+
+// コンテキストキーの型
+type contextKey string
+
+// DataLoaderを格納するコンテキストキー
+const loadersContextKey contextKey = "graphql_loaders"
+
+// リクエスト単位のDataLoader
+type Loaders struct {
+	// idからユーザーを取得
+	UserByID *pkg_dataloader.Loader[string, domain_user.Users]
+	// ユーザーidからTodoのリストを取得(リクエストしたユーザーが閲覧できるもののみ)
+	TodosByUserID *pkg_dataloader.Loader[string, []domain_todo.Todo]
+	// Todoのidから添付ファイルのリストを取得(閲覧権限がない場合は空)
+	AttachmentsByTodoID *pkg_dataloader.Loader[todoViewerKey, []domain_attachment.Attachment]
+	// Todoのidからタグのリストを取得(閲覧権限がない場合は空)
+	TagsByTodoID *pkg_dataloader.Loader[todoViewerKey, []domain_tag.Tag]
+	// idからTodoリストを取得(リクエストしたユーザーが閲覧できるもののみ)
+	TodoListByID *pkg_dataloader.Loader[string, domain_todolist.TodoList]
+	// TodoリストのidからTodoのリストを取得(リクエストしたユーザーが閲覧できるもののみ)
+	TodosByListID *pkg_dataloader.Loader[string, []domain_todo.Todo]
+	// idからTodoを取得(リクエストしたユーザーが閲覧できるもののみ)
+	TodoByID *pkg_dataloader.Loader[string, domain_todo.Todo]
+	// 親Todoのidからサブタスクのリストを取得(リクエストしたユーザーが閲覧できるもののみ)
+	SubtasksByParentID *pkg_dataloader.Loader[string, []domain_todo.Todo]
+	// Todoのidからサブタスクの進捗を取得(閲覧権限がない場合はnil)
+	ProgressByTodoID *pkg_dataloader.Loader[todoViewerKey, *domain_todo.TodoProgress]
+	// Todoのidから繰り返し設定を取得(閲覧権限がない場合は空)
+	RecurrenceByTodoID *pkg_dataloader.Loader[todoViewerKey, domain_recurrence.Recurrence]
+	// Todoのidと取得範囲からコメントの一覧を取得(閲覧権限がない場合はnil)
+	CommentsByTodoID *pkg_dataloader.Loader[commentPageKey, *domain_comment.CommentPage]
+	// Todoのidと取得範囲から変更履歴の一覧を取得(閲覧権限がない場合はnil)
+	HistoryByTodoID *pkg_dataloader.Loader[historyPageKey, *domain_audit.HistoryPage]
+
+	// リクエストしたユーザーのID(未認証の場合は空文字)
+	ViewerId string
+}
+
+// 閲覧権限を確認してTodoの関連データを取得するDataLoaderのキー
+// ViewerIdは所有者以外が取得する場合のみ設定し、DataLoaderで閲覧権限を確認する。
+type todoViewerKey struct {
+	TodoId   string
+	ViewerId string
+}
+
+// DataLoaderのインスタンス化
+// viewerIdはリクエストしたユーザーのID(未認証の場合は空文字)
+func (h *GraphQLHandler) NewLoaders(viewerId string) *Loaders {
+	return &Loaders{
+		ViewerId: viewerId,
+		UserByID: pkg_dataloader.NewLoader(func(ids []string) (map[string]domain_user.Users, error) {
+			h.Logger.InfoLog.Printf("Batch loading %d users...", len(ids))
+			users, err := h.userUsecase.GetUsersByIds(ids)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load users: %v", err)
+				return nil, err
+			}
+
+			result := make(map[string]domain_user.Users, len(users))
+			for _, u := range users {
+				result[u.ID] = u
+			}
+			return result, nil
+		}),
+		TodosByUserID: pkg_dataloader.NewLoader(func(userIds []string) (map[string][]domain_todo.Todo, error) {
+			h.Logger.InfoLog.Printf("Batch loading todos of %d users...", len(userIds))
+			todos, err := h.todoUsecase.GetVisibleTodosByUserIds(viewerId, userIds)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load todos: %v", err)
+				return nil, err
+			}
+
+			result := make(map[string][]domain_todo.Todo, len(userIds))
+			for _, t := range todos {
+				result[t.UserId] = append(result[t.UserId], t)
+			}
+			return result, nil
+		}),
+		AttachmentsByTodoID: pkg_dataloader.NewLoader(func(keys []todoViewerKey) (map[todoViewerKey][]domain_attachment.Attachment, error) {
+			h.Logger.InfoLog.Printf("Batch loading attachments of %d todos...", len(keys))
+			keysByTodoId, err := viewableTodoKeys(h, keys, func(k todoViewerKey) (string, string) { return k.TodoId, k.ViewerId })
+			if err != nil {
+				return nil, err
+			}
+			todoIds := make([]string, 0, len(keysByTodoId))
+			for todoId := range keysByTodoId {
+				todoIds = append(todoIds, todoId)
+			}
+			attachments, err := h.attachmentUsecase.GetAttachmentsByTodoIds(todoIds)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load attachments: %v", err)
+				return nil, err
+			}
+
+			result := make(map[todoViewerKey][]domain_attachment.Attachment, len(keys))
+			for _, a := range attachments {
+				for _, k := range keysByTodoId[a.TodoId] {
+					result[k] = append(result[k], a)
+				}
+			}
+			return result, nil
+		}),
+		TagsByTodoID: pkg_dataloader.NewLoader(func(keys []todoViewerKey) (map[todoViewerKey][]domain_tag.Tag, error) {
+			h.Logger.InfoLog.Printf("Batch loading tags of %d todos...", len(keys))
+			keysByTodoId, err := viewableTodoKeys(h, keys, func(k todoViewerKey) (string, string) { return k.TodoId, k.ViewerId })
+			if err != nil {
+				return nil, err
+			}
+			todoIds := make([]string, 0, len(keysByTodoId))
+			for todoId := range keysByTodoId {
+				todoIds = append(todoIds, todoId)
+			}
+			todoTags, err := h.tagUsecase.GetTagsByTodoIds(todoIds)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load tags: %v", err)
+				return nil, err
+			}
+
+			result := make(map[todoViewerKey][]domain_tag.Tag, len(keys))
+			for _, t := range todoTags {
+				for _, k := range keysByTodoId[t.TodoId] {
+					result[k] = append(result[k], t.Tag)
+				}
+			}
+			return result, nil
+		}),
+		TodoListByID: pkg_dataloader.NewLoader(func(ids []string) (map[string]domain_todolist.TodoList, error) {
+			h.Logger.InfoLog.Printf("Batch loading %d todo lists...", len(ids))
+			lists, err := h.todoListUsecase.GetVisibleTodoListsByIds(viewerId, ids)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load todo lists: %v", err)
+				return nil, err
+			}
+
+			result := make(map[string]domain_todolist.TodoList, len(lists))
+			for _, l := range lists {
+				result[l.ID] = l
+			}
+			return result, nil
+		}),
+		TodosByListID: pkg_dataloader.NewLoader(func(listIds []string) (map[string][]domain_todo.Todo, error) {
+			h.Logger.InfoLog.Printf("Batch loading todos of %d todo lists...", len(listIds))
+			todos, err := h.todoListUsecase.GetVisibleTodosByListIds(viewerId, listIds)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load todos: %v", err)
+				return nil, err
+			}
+
+			result := make(map[string][]domain_todo.Todo, len(listIds))
+			for _, t := range todos {
+				if t.ListId != nil {
+					result[*t.ListId] = append(result[*t.ListId], t)
+				}
+			}
+			return result, nil
+		}),
+		TodoByID: pkg_dataloader.NewLoader(func(ids []string) (map[string]domain_todo.Todo, error) {
+			h.Logger.InfoLog.Printf("Batch loading %d todos...", len(ids))
+			todos, err := h.todoUsecase.GetTodosByIds(ids)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load todos: %v", err)
+				return nil, err
+			}
+			todos, err = viewableTodos(h, viewerId, todos)
+			if err != nil {
+				return nil, err
+			}
+
+			result := make(map[string]domain_todo.Todo, len(todos))
+			for _, t := range todos {
+				result[t.ID] = t
+			}
+			return result, nil
+		}),
+		SubtasksByParentID: pkg_dataloader.NewLoader(func(parentIds []string) (map[string][]domain_todo.Todo, error) {
+			h.Logger.InfoLog.Printf("Batch loading subtasks of %d todos...", len(parentIds))
+			todos, err := h.todoUsecase.GetSubtasksByParentIds(parentIds)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load subtasks: %v", err)
+				return nil, err
+			}
+			todos, err = viewableTodos(h, viewerId, todos)
+			if err != nil {
+				return nil, err
+			}
+
+			result := make(map[string][]domain_todo.Todo, len(parentIds))
+			for _, t := range todos {
+				if t.ParentId != nil {
+					result[*t.ParentId] = append(result[*t.ParentId], t)
+				}
+			}
+			return result, nil
+		}),
+		ProgressByTodoID: pkg_dataloader.NewLoader(func(keys []todoViewerKey) (map[todoViewerKey]*domain_todo.TodoProgress, error) {
+			h.Logger.InfoLog.Printf("Batch loading progress of %d todos...", len(keys))
+			keysByTodoId, err := viewableTodoKeys(h, keys, func(k todoViewerKey) (string, string) { return k.TodoId, k.ViewerId })
+			if err != nil {
+				return nil, err
+			}
+			todoIds := make([]string, 0, len(keysByTodoId))
+			for todoId := range keysByTodoId {
+				todoIds = append(todoIds, todoId)
+			}
+			progress, err := h.todoUsecase.GetTodoProgressByIds(todoIds)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load progress: %v", err)
+				return nil, err
+			}
+			progressByTodoId := make(map[string]domain_todo.TodoProgress, len(progress))
+			for _, p := range progress {
+				progressByTodoId[p.TodoId] = p
+			}
+
+			// サブタスクがない場合は0件の進捗を返す
+			result := make(map[todoViewerKey]*domain_todo.TodoProgress, len(keys))
+			for todoId, todoKeys := range keysByTodoId {
+				p := progressByTodoId[todoId]
+				p.TodoId = todoId
+				for _, k := range todoKeys {
+					result[k] = &p
+				}
+			}
+			return result, nil
+		}),
+		RecurrenceByTodoID: pkg_dataloader.NewLoader(func(keys []todoViewerKey) (map[todoViewerKey]domain_recurrence.Recurrence, error) {
+			h.Logger.InfoLog.Printf("Batch loading recurrences of %d todos...", len(keys))
+			keysByTodoId, err := viewableTodoKeys(h, keys, func(k todoViewerKey) (string, string) { return k.TodoId, k.ViewerId })
+			if err != nil {
+				return nil, err
+			}
+			todoIds := make([]string, 0, len(keysByTodoId))
+			for todoId := range keysByTodoId {
+				todoIds = append(todoIds, todoId)
+			}
+			recurrences, err := h.recurrenceUsecase.GetRecurrencesByTodoIds(todoIds)
+			if err != nil {
+				h.Logger.ErrorLog.Printf("Failed to batch load recurrences: %v", err)
+				return nil, err
+			}
+
+			result := make(map[todoViewerKey]domain_recurrence.Recurrence, len(keys))
+			for _, r := range recurrences {
+				for _, k := range keysByTodoId[r.TodoId] {
+					result[k] = r
+				}
+			}
+			return result, nil
+		}),
+		CommentsByTodoID: pkg_dataloader.NewLoader(func(keys []commentPageKey) (map[commentPageKey]*domain_comment.CommentPage, error) {
+			h.Logger.InfoLog.Printf("Batch loading comments of %d todos...", len(keys))
+
+			// 所有者以外は閲覧権限を確認し、取得範囲ごとにまとめて取得する
+			keysByTodoId, err := viewableTodoKeys(h, keys, func(k commentPageKey) (string, string) { return k.TodoId, k.ViewerId })
+			if err != nil {
+				return nil, err
+			}
+			keysByRange := map[[2]int][]commentPageKey{}
+			for _, rangeKeys := range keysByTodoId {
+				for _, k := range rangeKeys {
+					r := [2]int{k.Limit, k.Offset}
+					keysByRange[r] = append(keysByRange[r], k)
+				}
+			}
+
+			result := make(map[commentPageKey]*domain_comment.CommentPage, len(keys))
+			for r, rangeKeys := range keysByRange {
+				todoIds := make([]string, 0, len(rangeKeys))
+				for _, k := range rangeKeys {
+					todoIds = append(todoIds, k.TodoId)
+				}
+				pages, err := h.commentUsecase.GetCommentPagesByTodoIds(todoIds, r[0], r[1])
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to batch load comments: %v", err)
+					return nil, err
+				}
+				pagesByTodoId := make(map[string]domain_comment.CommentPage, len(pages))
+				for _, page := range pages {
+					pagesByTodoId[page.TodoId] = page
+				}
+				for _, k := range rangeKeys {
+					page := pagesByTodoId[k.TodoId]
+					result[k] = &page
+				}
+			}
+			return result, nil
+		}),
+		HistoryByTodoID: pkg_dataloader.NewLoader(func(keys []historyPageKey) (map[historyPageKey]*domain_audit.HistoryPage, error) {
+			h.Logger.InfoLog.Printf("Batch loading history of %d todos...", len(keys))
+
+			// 所有者以外は閲覧権限を確認し、取得範囲ごとにまとめて取得する
+			keysByTodoId, err := viewableTodoKeys(h, keys, func(k historyPageKey) (string, string) { return k.TodoId, k.ViewerId })
+			if err != nil {
+				return nil, err
+			}
+			keysByRange := map[[2]int][]historyPageKey{}
+			for _, rangeKeys := range keysByTodoId {
+				for _, k := range rangeKeys {
+					r := [2]int{k.Limit, k.Offset}
+					keysByRange[r] = append(keysByRange[r], k)
+				}
+			}
+
+			result := make(map[historyPageKey]*domain_audit.HistoryPage, len(keys))
+			for r, rangeKeys := range keysByRange {
+				todoIds := make([]string, 0, len(rangeKeys))
+				for _, k := range rangeKeys {
+					todoIds = append(todoIds, k.TodoId)
+				}
+				pages, err := h.auditLogUsecase.GetTodoHistoryPages(todoIds, r[0], r[1])
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to batch load history: %v", err)
+					return nil, err
+				}
+				pagesByTodoId := make(map[string]domain_audit.HistoryPage, len(pages))
+				for _, page := range pages {
+					pagesByTodoId[page.EntityId] = page
+				}
+				for _, k := range rangeKeys {
+					page := pagesByTodoId[k.TodoId]
+					result[k] = &page
+				}
+			}
+			return result, nil
+		}),
+	}
+}
+
+// 閲覧権限のあるキーをTodoのidごとにまとめて返す
+// keyOfはキーからTodoのidと確認するユーザーのIDを取り出す。ユーザーのIDが空のキー(所有者による取得)は確認しない。
+// 閲覧権限はユーザーごとに1回のクエリでまとめて確認する。
+func viewableTodoKeys[K comparable](h *GraphQLHandler, keys []K, keyOf func(K) (string, string)) (map[string][]K, error) {
+	// 確認が必要なTodoのidをユーザーごとにまとめる
+	todoIdsByViewer := map[string][]string{}
+	for _, k := range keys {
+		todoId, viewerId := keyOf(k)
+		if viewerId != "" {
+			todoIdsByViewer[viewerId] = append(todoIdsByViewer[viewerId], todoId)
+		}
+	}
+	allowed := map[[2]string]bool{}
+	for viewerId, todoIds := range todoIdsByViewer {
+		visibleIds, err := h.todoUsecase.GetVisibleTodoIds(viewerId, todoIds)
+		if err != nil {
+			h.Logger.ErrorLog.Printf("Failed to check todo visibility: %v", err)
+			return nil, err
+		}
+		for _, todoId := range visibleIds {
+			allowed[[2]string{todoId, viewerId}] = true
+		}
+	}
+
+	result := map[string][]K{}
+	for _, k := range keys {
+		todoId, viewerId := keyOf(k)
+		if viewerId != "" && !allowed[[2]string{todoId, viewerId}] {
+			continue
+		}
+		result[todoId] = append(result[todoId], k)
+	}
+	return result, nil
+}
+
+// viewerIdのユーザーが閲覧できるTodoのみを返す
+// 自分のTodoはゴミ箱にあっても返し、他のユーザーのTodoは1回のクエリでまとめて閲覧権限を確認する。
+func viewableTodos(h *GraphQLHandler, viewerId string, todos []domain_todo.Todo) ([]domain_todo.Todo, error) {
+	if viewerId == "" {
+		return []domain_todo.Todo{}, nil
+	}
+	otherIds := []string{}
+	for _, t := range todos {
+		if t.UserId != viewerId {
+			otherIds = append(otherIds, t.ID)
+		}
+	}
+	allowed := map[string]bool{}
+	if len(otherIds) > 0 {
+		visibleIds, err := h.todoUsecase.GetVisibleTodoIds(viewerId, otherIds)
+		if err != nil {
+			h.Logger.ErrorLog.Printf("Failed to check todo visibility: %v", err)
+			return nil, err
+		}
+		for _, id := range visibleIds {
+			allowed[id] = true
+		}
+	}
+
+	result := make([]domain_todo.Todo, 0, len(todos))
+	for _, t := range todos {
+		if t.UserId == viewerId || allowed[t.ID] {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// Todoの関連データを取得するキーを作成
+// 未認証の場合と、所有者以外がゴミ箱のTodoを参照する場合はfalseを返す。
+func newTodoViewerKey(todo map[string]interface{}, viewerId string) (todoViewerKey, bool) {
+	key := todoViewerKey{}
+	key.TodoId, _ = todo["id"].(string)
+	if key.TodoId == "" || viewerId == "" {
+		return key, false
+	}
+	if ownerId, _ := todo["userId"].(string); ownerId != viewerId {
+		if todo["deletedAt"] != nil {
+			return key, false
+		}
+		key.ViewerId = viewerId
+	}
+	return key, true
+}
+
+// コンテキストにDataLoaderを設定
+// DataLoaderのキャッシュはリクエスト単位とするため、リクエストごとに呼び出すこと。
+func (h *GraphQLHandler) WithLoaders(ctx context.Context) context.Context {
+	viewerId, _ := ctx.Value(h.authHandler.AppConfig.UserID).(string)
+	loaders := h.NewLoaders(viewerId)
+	return context.WithValue(ctx, loadersContextKey, loaders)
+}
+
+// コンテキストからDataLoaderを取得
+func loadersFromContext(ctx context.Context) (*Loaders, error) {
+	if ctx == nil {
+		return nil, errors.New("loaders not found in context")
+	}
+	loaders, ok := ctx.Value(loadersContextKey).(*Loaders)
+	if !ok || loaders == nil {
+		return nil, errors.New("loaders not found in context")
+	}
+	return loaders, nil
+}
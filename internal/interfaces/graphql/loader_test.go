@@ -0,0 +1,379 @@
+package interfaces_graphql
+
+import (
+	domain_recurrence "backend/internal/domain/recurrence"
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	usecase_recurrence "backend/internal/usecase/recurrence"
+	usecase_todo "backend/internal/usecase/todo"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+// テスト用のロガー(出力しない)
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// ユーザーごとに閲覧できるTodoを持つTodoユースケース(使用しないメソッドは未実装)
+type loaderTestTodoUsecase struct {
+	usecase_todo.ITodoUsecase
+	todos   []domain_todo.Todo
+	visible map[string][]string
+	viewers []string
+	// GetVisibleTodoIdsの呼び出し(ユーザーIDと確認したTodoのid)
+	visibilityCalls map[string][]string
+}
+
+func (u *loaderTestTodoUsecase) GetVisibleTodosByUserIds(viewerId string, userIds []string) ([]domain_todo.Todo, error) {
+	u.viewers = append(u.viewers, viewerId)
+	result := []domain_todo.Todo{}
+	for _, t := range u.todos {
+		for _, userId := range userIds {
+			if t.UserId != userId {
+				continue
+			}
+			for _, id := range u.visible[viewerId] {
+				if t.ID == id {
+					result = append(result, t)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func (u *loaderTestTodoUsecase) GetVisibleTodoIds(viewerId string, ids []string) ([]string, error) {
+	if u.visibilityCalls == nil {
+		u.visibilityCalls = map[string][]string{}
+	}
+	if _, ok := u.visibilityCalls[viewerId]; ok {
+		return nil, errors.New("visibility checked twice for " + viewerId)
+	}
+	u.visibilityCalls[viewerId] = ids
+	result := []string{}
+	for _, id := range ids {
+		for _, visibleId := range u.visible[viewerId] {
+			if id == visibleId {
+				result = append(result, id)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (u *loaderTestTodoUsecase) GetTodosByIds(ids []string) ([]domain_todo.Todo, error) {
+	result := []domain_todo.Todo{}
+	for _, t := range u.todos {
+		for _, id := range ids {
+			if t.ID == id {
+				result = append(result, t)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (u *loaderTestTodoUsecase) GetTodoProgressByIds(ids []string) ([]domain_todo.TodoProgress, error) {
+	result := []domain_todo.TodoProgress{}
+	for _, id := range ids {
+		progress := domain_todo.TodoProgress{TodoId: id}
+		for _, t := range u.todos {
+			if t.ParentId != nil && *t.ParentId == id {
+				progress.Total++
+				if t.Completed {
+					progress.Completed++
+				}
+			}
+		}
+		if progress.Total > 0 {
+			result = append(result, progress)
+		}
+	}
+	return result, nil
+}
+
+// User.todosはリクエストしたユーザーが閲覧できるTodoのみをユーザーごとに返す
+func TestTodosByUserIDLoaderScopesToViewer(t *testing.T) {
+	tests := []struct {
+		name     string
+		viewerId string
+		want     map[string][]string
+	}{
+		{name: "owner", viewerId: "u1", want: map[string][]string{"u1": {"t1", "t2"}, "u2": {}}},
+		{name: "shared member", viewerId: "u2", want: map[string][]string{"u1": {"t2"}, "u2": {"t3"}}},
+		{name: "other user", viewerId: "u3", want: map[string][]string{"u1": {}, "u2": {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &loaderTestTodoUsecase{
+				todos: []domain_todo.Todo{
+					{ID: "t1", UserId: "u1"},
+					{ID: "t2", UserId: "u1"},
+					{ID: "t3", UserId: "u2"},
+				},
+				visible: map[string][]string{"u1": {"t1", "t2"}, "u2": {"t2", "t3"}},
+			}
+			h := &GraphQLHandler{Logger: newTestLogger(), todoUsecase: tu}
+			loaders := h.NewLoaders(tt.viewerId)
+
+			thunks := map[string]func() ([]domain_todo.Todo, error){}
+			for userId := range tt.want {
+				thunks[userId] = loaders.TodosByUserID.Load(userId)
+			}
+			for userId, want := range tt.want {
+				todos, err := thunks[userId]()
+				if err != nil {
+					t.Fatalf("Load(%s): unexpected error: %v", userId, err)
+				}
+				if len(todos) != len(want) {
+					t.Fatalf("Load(%s) returned %d todos, want %v", userId, len(todos), want)
+				}
+				for i, todo := range todos {
+					if todo.ID != want[i] {
+						t.Errorf("Load(%s)[%d] = %s, want %s", userId, i, todo.ID, want[i])
+					}
+				}
+			}
+			if len(tu.viewers) != 1 || tu.viewers[0] != tt.viewerId {
+				t.Errorf("GetVisibleTodosByUserIds called with viewers %v, want [%s] once", tu.viewers, tt.viewerId)
+			}
+		})
+	}
+}
+
+// 閲覧権限はユーザーごとに1回のクエリで確認し、所有者による取得は確認しない
+func TestViewableTodoKeysBatchesVisibility(t *testing.T) {
+	tu := &loaderTestTodoUsecase{visible: map[string][]string{"u2": {"t2", "t3"}, "u3": {}}}
+	h := &GraphQLHandler{Logger: newTestLogger(), todoUsecase: tu}
+	keys := []todoViewerKey{
+		{TodoId: "t1"},
+		{TodoId: "t2", ViewerId: "u2"},
+		{TodoId: "t3", ViewerId: "u2"},
+		{TodoId: "t4", ViewerId: "u2"},
+		{TodoId: "t2", ViewerId: "u3"},
+	}
+
+	got, err := viewableTodoKeys(h, keys, func(k todoViewerKey) (string, string) { return k.TodoId, k.ViewerId })
+	if err != nil {
+		t.Fatalf("viewableTodoKeys() unexpected error: %v", err)
+	}
+	want := map[string][]todoViewerKey{
+		"t1": {{TodoId: "t1"}},
+		"t2": {{TodoId: "t2", ViewerId: "u2"}},
+		"t3": {{TodoId: "t3", ViewerId: "u2"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("viewableTodoKeys() = %v, want %v", got, want)
+	}
+	for todoId, wantKeys := range want {
+		if len(got[todoId]) != len(wantKeys) || got[todoId][0] != wantKeys[0] {
+			t.Errorf("keys of %s = %v, want %v", todoId, got[todoId], wantKeys)
+		}
+	}
+	if len(tu.visibilityCalls) != 2 || len(tu.visibilityCalls["u2"]) != 3 || len(tu.visibilityCalls["u3"]) != 1 {
+		t.Errorf("visibility calls = %v, want u2 with 3 todos and u3 with 1 todo", tu.visibilityCalls)
+	}
+}
+
+// Todoのidから繰り返し設定を返す繰り返しユースケース(使用しないメソッドは未実装)
+type loaderTestRecurrenceUsecase struct {
+	usecase_recurrence.IRecurrenceUsecase
+	recurrences []domain_recurrence.Recurrence
+}
+
+func (u *loaderTestRecurrenceUsecase) GetRecurrencesByTodoIds(todoIds []string) ([]domain_recurrence.Recurrence, error) {
+	result := []domain_recurrence.Recurrence{}
+	for _, r := range u.recurrences {
+		for _, todoId := range todoIds {
+			if r.TodoId == todoId {
+				result = append(result, r)
+			}
+		}
+	}
+	return result, nil
+}
+
+// 繰り返し設定は閲覧権限のあるTodoのもののみ返す
+func TestRecurrenceByTodoIDLoaderScopesToViewer(t *testing.T) {
+	tu := &loaderTestTodoUsecase{visible: map[string][]string{"u2": {"t2"}}}
+	ru := &loaderTestRecurrenceUsecase{recurrences: []domain_recurrence.Recurrence{
+		{TodoId: "t1", Rule: "FREQ=DAILY"},
+		{TodoId: "t2", Rule: "FREQ=WEEKLY"},
+		{TodoId: "t3", Rule: "FREQ=MONTHLY"},
+	}}
+	h := &GraphQLHandler{Logger: newTestLogger(), todoUsecase: tu, recurrenceUsecase: ru}
+	loaders := h.NewLoaders("u2")
+
+	tests := []struct {
+		key  todoViewerKey
+		want string
+	}{
+		{key: todoViewerKey{TodoId: "t1"}, want: "FREQ=DAILY"},
+		{key: todoViewerKey{TodoId: "t2", ViewerId: "u2"}, want: "FREQ=WEEKLY"},
+		{key: todoViewerKey{TodoId: "t3", ViewerId: "u2"}, want: ""},
+	}
+	thunks := make([]func() (domain_recurrence.Recurrence, error), len(tests))
+	for i, tt := range tests {
+		thunks[i] = loaders.RecurrenceByTodoID.Load(tt.key)
+	}
+	for i, tt := range tests {
+		recurrence, err := thunks[i]()
+		if err != nil {
+			t.Fatalf("Load(%v): unexpected error: %v", tt.key, err)
+		}
+		if recurrence.Rule != tt.want {
+			t.Errorf("Load(%v).Rule = %q, want %q", tt.key, recurrence.Rule, tt.want)
+		}
+	}
+}
+
+// 親Todoなどidで取得するTodoは、自分のTodo(ゴミ箱を含む)と閲覧権限のあるTodoのみ返す
+func TestTodoByIDLoaderScopesToViewer(t *testing.T) {
+	deletedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	todos := []domain_todo.Todo{
+		{ID: "t1", UserId: "u1"},
+		{ID: "t2", UserId: "u1", DeletedAt: &deletedAt},
+		{ID: "t3", UserId: "u2"},
+		{ID: "t4", UserId: "u2"},
+	}
+	tests := []struct {
+		name     string
+		viewerId string
+		want     map[string]bool
+	}{
+		{name: "owner", viewerId: "u1", want: map[string]bool{"t1": true, "t2": true, "t3": true, "t4": false}},
+		{name: "unauthenticated", viewerId: "", want: map[string]bool{"t1": false, "t2": false, "t3": false, "t4": false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tu := &loaderTestTodoUsecase{todos: todos, visible: map[string][]string{"u1": {"t1", "t3"}}}
+			h := &GraphQLHandler{Logger: newTestLogger(), todoUsecase: tu}
+			loaders := h.NewLoaders(tt.viewerId)
+
+			thunks := map[string]func() (domain_todo.Todo, error){}
+			for id := range tt.want {
+				thunks[id] = loaders.TodoByID.Load(id)
+			}
+			for id, want := range tt.want {
+				todo, err := thunks[id]()
+				if err != nil {
+					t.Fatalf("Load(%s): unexpected error: %v", id, err)
+				}
+				if got := todo.ID != ""; got != want {
+					t.Errorf("Load(%s) found = %v, want %v", id, got, want)
+				}
+			}
+			// 他のユーザーのTodoのみ閲覧権限を確認する
+			if tt.viewerId != "" && len(tu.visibilityCalls[tt.viewerId]) != 2 {
+				t.Errorf("visibility calls = %v, want t3 and t4 only", tu.visibilityCalls)
+			}
+		})
+	}
+}
+
+// サブタスクの進捗は閲覧権限がない場合はnil、サブタスクがない場合は0件を返す
+func TestProgressByTodoIDLoaderScopesToViewer(t *testing.T) {
+	parentId := "t1"
+	tu := &loaderTestTodoUsecase{
+		todos: []domain_todo.Todo{
+			{ID: "t1", UserId: "u1"},
+			{ID: "t2", UserId: "u1", ParentId: &parentId, Completed: true},
+			{ID: "t3", UserId: "u1", ParentId: &parentId},
+			{ID: "t4", UserId: "u1"},
+		},
+		visible: map[string][]string{"u2": {"t1"}},
+	}
+	h := &GraphQLHandler{Logger: newTestLogger(), todoUsecase: tu}
+	loaders := h.NewLoaders("u2")
+
+	tests := []struct {
+		key  todoViewerKey
+		want *domain_todo.TodoProgress
+	}{
+		{key: todoViewerKey{TodoId: "t1", ViewerId: "u2"}, want: &domain_todo.TodoProgress{TodoId: "t1", Completed: 1, Total: 2}},
+		{key: todoViewerKey{TodoId: "t4", ViewerId: "u2"}, want: nil},
+		{key: todoViewerKey{TodoId: "t4"}, want: &domain_todo.TodoProgress{TodoId: "t4"}},
+	}
+	thunks := make([]func() (*domain_todo.TodoProgress, error), len(tests))
+	for i, tt := range tests {
+		thunks[i] = loaders.ProgressByTodoID.Load(tt.key)
+	}
+	for i, tt := range tests {
+		progress, err := thunks[i]()
+		if err != nil {
+			t.Fatalf("Load(%v): unexpected error: %v", tt.key, err)
+		}
+		if (progress == nil) != (tt.want == nil) || (progress != nil && *progress != *tt.want) {
+			t.Errorf("Load(%v) = %v, want %v", tt.key, progress, tt.want)
+		}
+	}
+}
+
+func TestNewTodoViewerKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		todo     map[string]interface{}
+		viewerId string
+		want     todoViewerKey
+		ok       bool
+	}{
+		{
+			name:     "owner",
+			todo:     map[string]interface{}{"id": "t1", "userId": "u1", "deletedAt": nil},
+			viewerId: "u1",
+			want:     todoViewerKey{TodoId: "t1"},
+			ok:       true,
+		},
+		{
+			name:     "owner can see trashed todo",
+			todo:     map[string]interface{}{"id": "t1", "userId": "u1", "deletedAt": "2026-01-01T00:00:00Z"},
+			viewerId: "u1",
+			want:     todoViewerKey{TodoId: "t1"},
+			ok:       true,
+		},
+		{
+			name:     "other user is checked in loader",
+			todo:     map[string]interface{}{"id": "t1", "userId": "u1", "deletedAt": nil},
+			viewerId: "u2",
+			want:     todoViewerKey{TodoId: "t1", ViewerId: "u2"},
+			ok:       true,
+		},
+		{
+			name:     "other user cannot see trashed todo",
+			todo:     map[string]interface{}{"id": "t1", "userId": "u1", "deletedAt": "2026-01-01T00:00:00Z"},
+			viewerId: "u2",
+			ok:       false,
+		},
+		{
+			name:     "unauthenticated",
+			todo:     map[string]interface{}{"id": "t1", "userId": "u1", "deletedAt": nil},
+			viewerId: "",
+			ok:       false,
+		},
+		{
+			name:     "missing id",
+			todo:     map[string]interface{}{"userId": "u1"},
+			viewerId: "u1",
+			ok:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newTodoViewerKey(tt.todo, tt.viewerId)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && got != tt.want {
+				t.Errorf("key = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// オペレーションの種類を取得
+// operationNameが空の場合、ドキュメント内のオペレーションが1つであればその種類を返す。
+func OperationTypeOf(query string, operationName string) (string, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return "", err
+	}
+
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" {
+			if found != nil {
+				return "", errors.New("must provide operation name if query contains multiple operations")
+			}
+			found = op
+			continue
+		}
+		if op.Name != nil && op.Name.Value == operationName {
+			found = op
+			break
+		}
+	}
+
+	if found == nil {
+		if operationName != "" {
+			return "", errors.New("unknown operation named \"" + operationName + "\"")
+		}
+		return "", errors.New("must provide an operation")
+	}
+	return found.Operation, nil
+}
@@ -0,0 +1,198 @@
+package interfaces_graphql
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 永続化クエリのエラー
+var (
+	// ハッシュに対応するクエリが未登録(クライアントはクエリ本文付きで再送する)
+	ErrPersistedQueryNotFound = errors.New("PersistedQueryNotFound")
+	// ハッシュとクエリ本文が一致しない
+	ErrPersistedQueryHashMismatch = errors.New("provided sha256Hash does not match query")
+	// 未対応のバージョン
+	ErrPersistedQueryVersion = errors.New("unsupported persisted query version")
+	// 許可リストに無いオペレーション
+	ErrOperationNotAllowed = errors.New("operation is not in the allowlist")
+)
+
+// 永続化クエリの拡張情報(extensions.persistedQuery)
+type PersistedQueryExtension struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// 永続化クエリストア
+// Automatic Persisted Queries(APQ)のキャッシュと、本番用の許可リストを管理する。
+type PersistedQueryStore struct {
+	Logger *pkg_logger.AppLogger
+	// trueの場合、許可リストに登録されたオペレーションのみ受け付ける
+	AllowlistOnly bool
+
+	mu        sync.Mutex
+	maxSize   int
+	entries   map[string]*list.Element
+	order     *list.List
+	allowlist map[string]string
+}
+
+// キャッシュのエントリ
+type persistedQueryEntry struct {
+	hash  string
+	query string
+}
+
+// 永続化クエリストアのインスタンス化
+// maxSizeはAPQキャッシュの最大件数(0以下の場合は無制限)。
+func NewPersistedQueryStore(l *pkg_logger.AppLogger, maxSize int) *PersistedQueryStore {
+	return &PersistedQueryStore{
+		Logger:    l,
+		maxSize:   maxSize,
+		entries:   map[string]*list.Element{},
+		order:     list.New(),
+		allowlist: map[string]string{},
+	}
+}
+
+// 許可リストファイルを読み込む
+// ファイルはsha256ハッシュをキー、クエリ本文を値とするJSONオブジェクトとする。
+func (s *PersistedQueryStore) LoadAllowlist(path string) error {
+	s.Logger.InfoLog.Printf("Loading operation allowlist: %s", path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		s.Logger.ErrorLog.Printf("Failed to read allowlist: %v", err)
+		return err
+	}
+
+	operations := map[string]string{}
+	if err := json.Unmarshal(data, &operations); err != nil {
+		s.Logger.ErrorLog.Printf("Failed to parse allowlist: %v", err)
+		return err
+	}
+
+	// ハッシュとクエリ本文の整合性をチェック
+	allowlist := make(map[string]string, len(operations))
+	for hash, query := range operations {
+		hash = strings.ToLower(hash)
+		if HashQuery(query) != hash {
+			s.Logger.ErrorLog.Printf("Allowlist hash mismatch: %s", hash)
+			return fmt.Errorf("allowlist entry %s: %w", hash, ErrPersistedQueryHashMismatch)
+		}
+		allowlist[hash] = query
+	}
+
+	s.mu.Lock()
+	s.allowlist = allowlist
+	s.AllowlistOnly = true
+	s.mu.Unlock()
+
+	s.Logger.InfoLog.Printf("Loaded %d allowed operations", len(allowlist))
+	return nil
+}
+
+// リクエストから実行するクエリ本文を解決する
+// ext が nil の場合は通常のリクエストとして扱う。
+func (s *PersistedQueryStore) Resolve(query string, ext *PersistedQueryExtension) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 通常のリクエスト
+	if ext == nil || ext.Sha256Hash == "" {
+		if s.AllowlistOnly {
+			if _, ok := s.allowlist[HashQuery(query)]; !ok {
+				s.Logger.ErrorLog.Println("Operation not in allowlist")
+				return "", ErrOperationNotAllowed
+			}
+		}
+		return query, nil
+	}
+
+	if ext.Version != 1 {
+		s.Logger.ErrorLog.Printf("Unsupported persisted query version: %d", ext.Version)
+		return "", ErrPersistedQueryVersion
+	}
+	hash := strings.ToLower(ext.Sha256Hash)
+
+	// 許可リストモード: 登録済みのオペレーションのみ
+	if s.AllowlistOnly {
+		allowed, ok := s.allowlist[hash]
+		if !ok {
+			s.Logger.ErrorLog.Printf("Operation not in allowlist: %s", hash)
+			return "", ErrOperationNotAllowed
+		}
+		if query != "" && query != allowed {
+			s.Logger.ErrorLog.Printf("Persisted query hash mismatch: %s", hash)
+			return "", ErrPersistedQueryHashMismatch
+		}
+		return allowed, nil
+	}
+
+	// ハッシュのみの場合はキャッシュから取得
+	if query == "" {
+		elem, ok := s.entries[hash]
+		if !ok {
+			s.Logger.InfoLog.Printf("Persisted query not found: %s", hash)
+			return "", ErrPersistedQueryNotFound
+		}
+		s.order.MoveToFront(elem)
+		return elem.Value.(*persistedQueryEntry).query, nil
+	}
+
+	// クエリ本文付きの場合は検証して登録
+	if HashQuery(query) != hash {
+		s.Logger.ErrorLog.Printf("Persisted query hash mismatch: %s", hash)
+		return "", ErrPersistedQueryHashMismatch
+	}
+	s.register(hash, query)
+
+	return query, nil
+}
+
+// ハッシュに対応する登録済みのクエリ本文を取得(キャッシュの順序は変更しない)
+func (s *PersistedQueryStore) Lookup(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash = strings.ToLower(hash)
+	if query, ok := s.allowlist[hash]; ok {
+		return query, true
+	}
+	if elem, ok := s.entries[hash]; ok {
+		return elem.Value.(*persistedQueryEntry).query, true
+	}
+	return "", false
+}
+
+// キャッシュに登録(ロック取得済みで呼び出すこと)
+func (s *PersistedQueryStore) register(hash string, query string) {
+	if elem, ok := s.entries[hash]; ok {
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	s.entries[hash] = s.order.PushFront(&persistedQueryEntry{hash: hash, query: query})
+	s.Logger.InfoLog.Printf("Registered persisted query: %s", hash)
+
+	// 最大件数を超えた場合は最も古いものを削除
+	if s.maxSize > 0 && s.order.Len() > s.maxSize {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*persistedQueryEntry).hash)
+	}
+}
+
+// クエリ本文のsha256ハッシュを取得
+func HashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
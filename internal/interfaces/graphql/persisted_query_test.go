@@ -0,0 +1,192 @@
+package interfaces_graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashQuery(t *testing.T) {
+	want := "c76152b64fda065a18b9c7e2287c871137f5d00772ad5a6f963c2b614b4b46c6"
+	if got := HashQuery("{ todos { id } }"); got != want {
+		t.Errorf("HashQuery() = %q, want %q", got, want)
+	}
+	if HashQuery("{ todos { id } }") == HashQuery("{ todos { title } }") {
+		t.Error("HashQuery() returned the same hash for different queries")
+	}
+}
+
+// ハッシュのみのリクエストは未登録ならPersistedQueryNotFoundを返し、本文付きで登録した後はキャッシュから解決する
+func TestPersistedQueryStoreAPQFlow(t *testing.T) {
+	store := NewPersistedQueryStore(newTestLogger(), 10)
+	query := "{ todos { id } }"
+	hash := HashQuery(query)
+
+	if _, err := store.Resolve("", &PersistedQueryExtension{Version: 1, Sha256Hash: hash}); !errors.Is(err, ErrPersistedQueryNotFound) {
+		t.Fatalf("hash only before registration: error = %v, want %v", err, ErrPersistedQueryNotFound)
+	}
+
+	got, err := store.Resolve(query, &PersistedQueryExtension{Version: 1, Sha256Hash: hash})
+	if err != nil || got != query {
+		t.Fatalf("register: got (%q, %v), want (%q, nil)", got, err, query)
+	}
+
+	// ハッシュの大文字・小文字は区別しない
+	got, err = store.Resolve("", &PersistedQueryExtension{Version: 1, Sha256Hash: strings.ToUpper(hash)})
+	if err != nil || got != query {
+		t.Errorf("hash only after registration: got (%q, %v), want (%q, nil)", got, err, query)
+	}
+}
+
+func TestPersistedQueryStoreResolveErrors(t *testing.T) {
+	store := NewPersistedQueryStore(newTestLogger(), 10)
+	query := "{ todos { id } }"
+
+	if _, err := store.Resolve(query, &PersistedQueryExtension{Version: 1, Sha256Hash: HashQuery("{ other }")}); !errors.Is(err, ErrPersistedQueryHashMismatch) {
+		t.Errorf("mismatched hash: error = %v, want %v", err, ErrPersistedQueryHashMismatch)
+	}
+	if _, ok := store.entries[HashQuery("{ other }")]; ok {
+		t.Error("mismatched query was registered")
+	}
+	if _, err := store.Resolve(query, &PersistedQueryExtension{Version: 2, Sha256Hash: HashQuery(query)}); !errors.Is(err, ErrPersistedQueryVersion) {
+		t.Errorf("unsupported version: error = %v, want %v", err, ErrPersistedQueryVersion)
+	}
+
+	// 拡張情報が無い、またはハッシュが空の場合は通常のリクエストとして扱う
+	for _, ext := range []*PersistedQueryExtension{nil, {Version: 1}} {
+		if got, err := store.Resolve(query, ext); err != nil || got != query {
+			t.Errorf("Resolve(%v) = (%q, %v), want (%q, nil)", ext, got, err, query)
+		}
+	}
+}
+
+// 最大件数を超えた場合は最も長く使われていないクエリを削除する
+func TestPersistedQueryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewPersistedQueryStore(newTestLogger(), 2)
+	register := func(query string) {
+		t.Helper()
+		if _, err := store.Resolve(query, &PersistedQueryExtension{Version: 1, Sha256Hash: HashQuery(query)}); err != nil {
+			t.Fatalf("register %q: unexpected error: %v", query, err)
+		}
+	}
+	resolve := func(query string) error {
+		_, err := store.Resolve("", &PersistedQueryExtension{Version: 1, Sha256Hash: HashQuery(query)})
+		return err
+	}
+
+	register("{ a }")
+	register("{ b }")
+	// aを使うとbが最も古くなる
+	if err := resolve("{ a }"); err != nil {
+		t.Fatalf("resolve a: unexpected error: %v", err)
+	}
+	register("{ c }")
+
+	if err := resolve("{ b }"); !errors.Is(err, ErrPersistedQueryNotFound) {
+		t.Errorf("resolve b: error = %v, want %v", err, ErrPersistedQueryNotFound)
+	}
+	for _, query := range []string{"{ a }", "{ c }"} {
+		if err := resolve(query); err != nil {
+			t.Errorf("resolve %s: unexpected error: %v", query, err)
+		}
+	}
+
+	// 登録済みのクエリを再登録しても件数は増えない
+	register("{ c }")
+	if store.order.Len() != 2 || len(store.entries) != 2 {
+		t.Errorf("cache size = %d/%d, want 2", store.order.Len(), len(store.entries))
+	}
+}
+
+func TestPersistedQueryStoreUnlimited(t *testing.T) {
+	store := NewPersistedQueryStore(newTestLogger(), 0)
+	for i := 0; i < 100; i++ {
+		query := "{ q" + strings.Repeat("x", i) + " }"
+		if _, err := store.Resolve(query, &PersistedQueryExtension{Version: 1, Sha256Hash: HashQuery(query)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(store.entries) != 100 {
+		t.Errorf("cache size = %d, want 100", len(store.entries))
+	}
+}
+
+// 許可リストモードでは登録済みのオペレーションのみ受け付け、APQで新しく登録しない
+func TestPersistedQueryStoreAllowlist(t *testing.T) {
+	allowed := "{ todos { id } }"
+	path := filepath.Join(t.TempDir(), "allowlist.json")
+	data, _ := json.Marshal(map[string]string{strings.ToUpper(HashQuery(allowed)): allowed})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	store := NewPersistedQueryStore(newTestLogger(), 10)
+	if err := store.LoadAllowlist(path); err != nil {
+		t.Fatalf("LoadAllowlist() unexpected error: %v", err)
+	}
+	if !store.AllowlistOnly {
+		t.Error("AllowlistOnly = false after LoadAllowlist")
+	}
+
+	if got, err := store.Resolve("", &PersistedQueryExtension{Version: 1, Sha256Hash: HashQuery(allowed)}); err != nil || got != allowed {
+		t.Errorf("allowed hash: got (%q, %v), want (%q, nil)", got, err, allowed)
+	}
+	if got, err := store.Resolve(allowed, nil); err != nil || got != allowed {
+		t.Errorf("allowed query: got (%q, %v), want (%q, nil)", got, err, allowed)
+	}
+
+	other := "{ users { id } }"
+	if _, err := store.Resolve(other, nil); !errors.Is(err, ErrOperationNotAllowed) {
+		t.Errorf("other query: error = %v, want %v", err, ErrOperationNotAllowed)
+	}
+	if _, err := store.Resolve(other, &PersistedQueryExtension{Version: 1, Sha256Hash: HashQuery(other)}); !errors.Is(err, ErrOperationNotAllowed) {
+		t.Errorf("other query with hash: error = %v, want %v", err, ErrOperationNotAllowed)
+	}
+	if _, err := store.Resolve(other, &PersistedQueryExtension{Version: 1, Sha256Hash: HashQuery(allowed)}); !errors.Is(err, ErrPersistedQueryHashMismatch) {
+		t.Errorf("other query with allowed hash: error = %v, want %v", err, ErrPersistedQueryHashMismatch)
+	}
+	if _, ok := store.entries[HashQuery(other)]; ok {
+		t.Error("operation outside the allowlist was registered")
+	}
+}
+
+func TestPersistedQueryStoreLoadAllowlistErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return path
+	}
+
+	mismatch, _ := json.Marshal(map[string]string{HashQuery("{ a }"): "{ b }"})
+	tests := []struct {
+		name string
+		path string
+		want error
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing.json")},
+		{name: "invalid json", path: write("invalid.json", "{")},
+		{name: "hash mismatch", path: write("mismatch.json", string(mismatch)), want: ErrPersistedQueryHashMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewPersistedQueryStore(newTestLogger(), 10)
+			err := store.LoadAllowlist(tt.path)
+			if err == nil {
+				t.Fatal("want error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if store.AllowlistOnly {
+				t.Error("AllowlistOnly = true after a failed load")
+			}
+		})
+	}
+}
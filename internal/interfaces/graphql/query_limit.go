@@ -0,0 +1,482 @@
+package interfaces_graphql
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// フィールドのデフォルトコスト
+const defaultFieldCost = 1
+
+// リストを返すフィールドの要素数の見積もり(引数first/limitが無い場合)
+const defaultListSize = 10
+
+// リストを返すフィールドの要素数の見積もりの上限(各リゾルバの取得件数の上限に合わせる)
+// 全件を取得するリストは引数limit(listLimitArg)を持ち、指定しない場合もデフォルト値の件数で切り詰める。
+const maxListSize = 100
+
+// 取得件数が引数limitの範囲外の場合のエラー
+var errListLimitOutOfRange = errors.New("limit must be between 1 and 100")
+
+// 全件を取得するリストの取得件数の引数(デフォルト値・上限はmaxListSize)
+var listLimitArg = &graphql.ArgumentConfig{
+	Type:         graphql.Int,
+	DefaultValue: maxListSize,
+	Description:  "取得する件数(1〜100)",
+}
+
+// 上限が無い場合のコストの上限(int32の範囲で飽和させる)
+const unlimitedCostCeiling = math.MaxInt32
+
+// フィールドごとのコスト("型名.フィールド名"をキーとする)
+// 指定の無いフィールドはdefaultFieldCostとする。
+var fieldCosts = map[string]int{
+	"Query.users":                          10,
+	"Query.todos":                          10,
+	"Query.todoByUserId":                   5,
+	"Query.todo":                           2,
+	"Query.trashedTodos":                   5,
+	"Query.overdueTodos":                   5,
+	"Query.upcomingTodos":                  5,
+	"Query.auditLog":                       20,
+	"User.todos":                           5,
+	"Todo.owner":                           2,
+	"Todo.attachments":                     2,
+	"Todo.tags":                            2,
+	"Query.tags":                           5,
+	"Query.todoLists":                      5,
+	"Query.todoList":                       2,
+	"Query.inboxTodos":                     5,
+	"TodoList.todos":                       5,
+	"Todo.list":                            2,
+	"Todo.parent":                          2,
+	"Todo.subtasks":                        5,
+	"Todo.progress":                        2,
+	"Todo.recurrence":                      2,
+	"Query.upcomingOccurrences":            10,
+	"Query.searchTodos":                    20,
+	"Query.webhooks":                       5,
+	"Query.webhookDeliveries":              10,
+	"Query.webhookDeliveryAttempts":        5,
+	"Query.calendarFeed":                   2,
+	"Occurrence.todo":                      2,
+	"Query.sharedTodos":                    10,
+	"Query.invitations":                    5,
+	"Query.todoShares":                     5,
+	"TodoShare.owner":                      2,
+	"TodoShare.member":                     2,
+	"TodoShare.todo":                       2,
+	"Todo.comments":                        5,
+	"Comment.author":                       2,
+	"Todo.history":                         5,
+	"HistoryEntry.actor":                   2,
+	"Mutation.createTodo":                  10,
+	"Mutation.updateTodo":                  10,
+	"Mutation.deleteTodo":                  10,
+	"Mutation.restoreTodo":                 10,
+	"Mutation.purgeTodo":                   10,
+	"Mutation.bulkUpdateTodos":             50,
+	"Mutation.bulkDeleteTodos":             50,
+	"Mutation.clearCompleted":              50,
+	"Mutation.importTodos":                 50,
+	"Mutation.regenerateCalendarFeedToken": 10,
+	"Mutation.createWebhook":               10,
+	"Mutation.updateWebhook":               10,
+	"Mutation.deleteWebhook":               10,
+	"Mutation.redeliverWebhookDelivery":    10,
+	"Mutation.revokeCalendarFeed":          10,
+	"Mutation.login":                       10,
+	"Mutation.createTag":                   10,
+	"Mutation.renameTag":                   10,
+	"Mutation.deleteTag":                   10,
+	"Mutation.tagTodo":                     10,
+	"Mutation.untagTodo":                   10,
+	"Mutation.setTodoParent":               10,
+	"Mutation.moveTodo":                    10,
+	"Mutation.setTodoRecurrence":           10,
+	"Mutation.clearTodoRecurrence":         10,
+	"Mutation.skipOccurrence":              10,
+	"Mutation.shareTodo":                   10,
+	"Mutation.shareAllTodos":               10,
+	"Mutation.acceptInvitation":            10,
+	"Mutation.declineInvitation":           10,
+	"Mutation.updateShareRole":             10,
+	"Mutation.revokeShare":                 10,
+	"Mutation.addComment":                  10,
+	"Mutation.editComment":                 10,
+	"Mutation.deleteComment":               10,
+	"Mutation.createTodoList":              10,
+	"Mutation.updateTodoList":              10,
+	"Mutation.deleteTodoList":              20,
+	"Mutation.moveTodoToList":              10,
+	"Mutation.addAttachment":               20,
+	"Mutation.removeAttachment":            10,
+	"Mutation.unlockAccount":               10,
+}
+
+// イントロスペクションが無効な場合のエラー
+var ErrIntrospectionDisabled = errors.New("introspection is disabled")
+
+// クエリの解析結果
+type QueryCost struct {
+	Depth   int `json:"depth"`
+	Aliases int `json:"aliases"`
+	Cost    int `json:"cost"`
+}
+
+// クエリ制限(深さ・エイリアス数・コスト)
+type QueryLimiter struct {
+	Logger     *pkg_logger.AppLogger
+	MaxDepth   int
+	MaxAliases int
+	MaxCost    int
+	// イントロスペクション(__schema, __type)を許可するかどうか
+	AllowIntrospection bool
+}
+
+// クエリ制限のインスタンス化
+// 各上限値に0以下を指定した場合、その制限は無効とする。
+func NewQueryLimiter(l *pkg_logger.AppLogger, maxDepth int, maxAliases int, maxCost int, allowIntrospection bool) *QueryLimiter {
+	return &QueryLimiter{
+		Logger:             l,
+		MaxDepth:           maxDepth,
+		MaxAliases:         maxAliases,
+		MaxCost:            maxCost,
+		AllowIntrospection: allowIntrospection,
+	}
+}
+
+// クエリを静的に解析し、制限を超えていないか検証する
+// operationNameが空の場合、ドキュメント内の全オペレーションのうち最大値で判定する。
+func (q *QueryLimiter) Validate(schema graphql.Schema, query string, operationName string, variables map[string]interface{}) (QueryCost, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		q.Logger.ErrorLog.Printf("Failed to parse query: %v", err)
+		return QueryCost{}, err
+	}
+
+	// コストはMaxCostを超えた時点で判定できるため、MaxCost+1で飽和させてオーバーフローを防ぐ
+	ceiling := unlimitedCostCeiling
+	if q.MaxCost > 0 && q.MaxCost < unlimitedCostCeiling {
+		ceiling = q.MaxCost + 1
+	}
+	analyzer := &queryAnalyzer{
+		schema:    schema,
+		variables: variables,
+		fragments: map[string]*ast.FragmentDefinition{},
+		ceiling:   ceiling,
+	}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok && frag.Name != nil {
+			analyzer.fragments[frag.Name.Value] = frag
+		}
+	}
+
+	result := QueryCost{}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName != "" && (op.Name == nil || op.Name.Value != operationName) {
+			continue
+		}
+
+		var rootType *graphql.Object
+		switch op.Operation {
+		case ast.OperationTypeMutation:
+			rootType = schema.MutationType()
+		case ast.OperationTypeSubscription:
+			rootType = schema.SubscriptionType()
+		default:
+			rootType = schema.QueryType()
+		}
+		if rootType == nil {
+			continue
+		}
+
+		cost := analyzer.analyzeOperation(op, rootType)
+		result.Depth = max(result.Depth, cost.Depth)
+		result.Aliases = max(result.Aliases, cost.Aliases)
+		result.Cost = max(result.Cost, cost.Cost)
+	}
+
+	// イントロスペクションのチェック
+	if !q.AllowIntrospection && analyzer.introspection {
+		q.Logger.ErrorLog.Println("Introspection query rejected")
+		return result, ErrIntrospectionDisabled
+	}
+
+	// 制限値のチェック
+	if q.MaxDepth > 0 && result.Depth > q.MaxDepth {
+		q.Logger.ErrorLog.Printf("Query depth %d exceeds limit %d", result.Depth, q.MaxDepth)
+		return result, fmt.Errorf("query depth %d exceeds maximum depth %d", result.Depth, q.MaxDepth)
+	}
+	if q.MaxAliases > 0 && result.Aliases > q.MaxAliases {
+		q.Logger.ErrorLog.Printf("Query aliases %d exceeds limit %d", result.Aliases, q.MaxAliases)
+		return result, fmt.Errorf("query uses %d aliases, exceeding maximum of %d", result.Aliases, q.MaxAliases)
+	}
+	if q.MaxCost > 0 && result.Cost > q.MaxCost {
+		q.Logger.ErrorLog.Printf("Query cost %d exceeds limit %d", result.Cost, q.MaxCost)
+		return result, fmt.Errorf("query cost %d exceeds maximum cost %d", result.Cost, q.MaxCost)
+	}
+
+	return result, nil
+}
+
+// バッチリクエストのコストの合計が上限を超えていないか検証する
+func (q *QueryLimiter) ValidateTotal(total int) error {
+	if q.MaxCost > 0 && total > q.MaxCost {
+		q.Logger.ErrorLog.Printf("Batch cost %d exceeds limit %d", total, q.MaxCost)
+		return fmt.Errorf("batch cost %d exceeds maximum cost %d", total, q.MaxCost)
+	}
+	return nil
+}
+
+// 解析結果をレスポンスのextensionsに設定する形式に変換
+func (q *QueryLimiter) Extensions(cost QueryCost) map[string]interface{} {
+	return map[string]interface{}{
+		"depth":              cost.Depth,
+		"aliases":            cost.Aliases,
+		"requestedQueryCost": cost.Cost,
+		"maximumAvailable":   q.MaxCost,
+	}
+}
+
+// クエリ解析器
+type queryAnalyzer struct {
+	schema    graphql.Schema
+	variables map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+	aliases   int
+	// コストの上限(これ以上は加算・乗算しない)
+	ceiling int
+	// イントロスペクションのフィールドを含むかどうか
+	introspection bool
+}
+
+// オペレーションを解析
+func (a *queryAnalyzer) analyzeOperation(op *ast.OperationDefinition, rootType *graphql.Object) QueryCost {
+	a.aliases = 0
+	cost, depth := a.analyzeSelectionSet(op.SelectionSet, rootType, map[string]bool{})
+	return QueryCost{
+		Depth:   depth,
+		Aliases: a.aliases,
+		Cost:    cost,
+	}
+}
+
+// 選択セットを解析し、コストと深さを返す
+// visitedはフラグメントの循環参照を防ぐために使用する。
+func (a *queryAnalyzer) analyzeSelectionSet(set *ast.SelectionSet, parent graphql.Type, visited map[string]bool) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	cost, depth := 0, 0
+	for _, selection := range set.Selections {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			c, d := a.analyzeField(sel, parent, visited)
+			cost = a.add(cost, c)
+			depth = max(depth, d)
+		case *ast.InlineFragment:
+			fragType := parent
+			if sel.TypeCondition != nil && sel.TypeCondition.Name != nil {
+				fragType = a.schema.Type(sel.TypeCondition.Name.Value)
+			}
+			c, d := a.analyzeSelectionSet(sel.SelectionSet, fragType, visited)
+			cost = a.add(cost, c)
+			depth = max(depth, d)
+		case *ast.FragmentSpread:
+			if sel.Name == nil || visited[sel.Name.Value] {
+				continue
+			}
+			frag, ok := a.fragments[sel.Name.Value]
+			if !ok {
+				continue
+			}
+			fragType := parent
+			if frag.TypeCondition != nil && frag.TypeCondition.Name != nil {
+				fragType = a.schema.Type(frag.TypeCondition.Name.Value)
+			}
+			visited[sel.Name.Value] = true
+			c, d := a.analyzeSelectionSet(frag.SelectionSet, fragType, visited)
+			delete(visited, sel.Name.Value)
+			cost = a.add(cost, c)
+			depth = max(depth, d)
+		}
+	}
+
+	return cost, depth
+}
+
+// フィールドを解析し、コストと深さを返す
+func (a *queryAnalyzer) analyzeField(field *ast.Field, parent graphql.Type, visited map[string]bool) (int, int) {
+	if field.Name == nil {
+		return 0, 0
+	}
+	name := field.Name.Value
+	// イントロスペクションは制限の対象外とする
+	if strings.HasPrefix(name, "__") {
+		if name == "__schema" || name == "__type" {
+			a.introspection = true
+		}
+		return 0, 0
+	}
+	if field.Alias != nil && field.Alias.Value != name {
+		a.aliases++
+	}
+
+	// フィールドの型を解決
+	var fieldType graphql.Type
+	var fieldDef *graphql.FieldDefinition
+	parentName := ""
+	if obj, ok := parent.(*graphql.Object); ok {
+		parentName = obj.Name()
+		if def, ok := obj.Fields()[name]; ok {
+			fieldType = def.Type
+			fieldDef = def
+		}
+	}
+
+	cost, ok := fieldCosts[parentName+"."+name]
+	if !ok {
+		cost = defaultFieldCost
+	}
+
+	// リストの場合は要素数分のコストとする
+	// コネクション(edges)の要素数はコネクションのフィールドで数えるため、edges自体では数えない。
+	multiplier := 1
+	namedType := fieldType
+	countList := !(isConnectionType(parent) && name == "edges")
+	for {
+		switch t := namedType.(type) {
+		case *graphql.NonNull:
+			namedType = t.OfType
+			continue
+		case *graphql.List:
+			if countList {
+				multiplier = a.mul(multiplier, a.listSize(field, fieldDef))
+			}
+			namedType = t.OfType
+			continue
+		}
+		break
+	}
+	// コネクションの場合は引数first/limitの件数分のコストとする
+	if isConnectionType(namedType) {
+		multiplier = a.mul(multiplier, a.listSize(field, fieldDef))
+	}
+
+	childCost, childDepth := a.analyzeSelectionSet(field.SelectionSet, namedType, visited)
+	return a.add(cost, a.mul(multiplier, childCost)), childDepth + 1
+}
+
+// 上限で飽和させた加算
+func (a *queryAnalyzer) add(x int, y int) int {
+	if x >= a.ceiling-y {
+		return a.ceiling
+	}
+	return x + y
+}
+
+// 上限で飽和させた乗算
+func (a *queryAnalyzer) mul(x int, y int) int {
+	if x == 0 || y == 0 {
+		return 0
+	}
+	if x > a.ceiling/y {
+		return a.ceiling
+	}
+	return min(x*y, a.ceiling)
+}
+
+// カーソルによるページネーションのコネクション型かどうか(型名がConnectionで終わり、edgesを持つ)
+func isConnectionType(t graphql.Type) bool {
+	obj, ok := t.(*graphql.Object)
+	if !ok || !strings.HasSuffix(obj.Name(), "Connection") {
+		return false
+	}
+	_, ok = obj.Fields()["edges"]
+	return ok
+}
+
+// リストの要素数を見積もる
+// 引数first/limitが指定されていればその値(maxListSizeまで)、
+// 無ければ引数のデフォルト値、デフォルト値も無ければdefaultListSizeとする。
+func (a *queryAnalyzer) listSize(field *ast.Field, def *graphql.FieldDefinition) int {
+	return min(a.requestedListSize(field, def), maxListSize)
+}
+
+// 引数first/limitで指定された要素数を返す
+func (a *queryAnalyzer) requestedListSize(field *ast.Field, def *graphql.FieldDefinition) int {
+	for _, arg := range field.Arguments {
+		if arg.Name == nil || (arg.Name.Value != "first" && arg.Name.Value != "limit") {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(v.Value)
+			if errors.Is(err, strconv.ErrRange) && !strings.HasPrefix(v.Value, "-") {
+				return maxListSize
+			}
+			if err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			if v.Name == nil {
+				continue
+			}
+			switch n := a.variables[v.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					return int(min(n, maxListSize))
+				}
+			case int:
+				if n > 0 {
+					return n
+				}
+			}
+		}
+	}
+	if def != nil {
+		for _, arg := range def.Args {
+			if arg.Name() != "first" && arg.Name() != "limit" {
+				continue
+			}
+			if n, ok := arg.DefaultValue.(int); ok && n > 0 {
+				return n
+			}
+		}
+	}
+	return defaultListSize
+}
+
+// 引数limitの取得件数を返す(1〜maxListSizeの範囲外の場合はエラー)
+func listLimit(args map[string]interface{}) (int, error) {
+	limit, ok := args["limit"].(int)
+	if !ok {
+		return maxListSize, nil
+	}
+	if limit < 1 || limit > maxListSize {
+		return 0, errListLimitOutOfRange
+	}
+	return limit, nil
+}
+
+// リストを先頭からlimit件までに切り詰める
+func truncateList[T any](items []T, limit int) []T {
+	if len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
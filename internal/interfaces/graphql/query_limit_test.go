@@ -0,0 +1,321 @@
+package interfaces_graphql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// クエリ制限のテスト用スキーマ
+// Query.todos(リスト)、Query.searchTodos(コネクション)、Todo.subtasks(入れ子のリスト)を持つ。
+func newQueryLimitTestSchema(t *testing.T) graphql.Schema {
+	t.Helper()
+
+	var todo *graphql.Object
+	todo = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":    &graphql.Field{Type: graphql.String},
+				"title": &graphql.Field{Type: graphql.String},
+				"subtasks": &graphql.Field{
+					Type: graphql.NewList(todo),
+					Args: graphql.FieldConfigArgument{"first": &graphql.ArgumentConfig{Type: graphql.Int}},
+				},
+			}
+		}),
+	})
+	edge := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoSearchEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.String},
+			"node":   &graphql.Field{Type: todo},
+		},
+	})
+	connection := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoSearchConnection",
+		Fields: graphql.Fields{
+			"edges":    &graphql.Field{Type: graphql.NewList(edge)},
+			"pageInfo": &graphql.Field{Type: pageInfoType},
+		},
+	})
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"todos": &graphql.Field{
+				Type: graphql.NewList(todo),
+				Args: graphql.FieldConfigArgument{"first": &graphql.ArgumentConfig{Type: graphql.Int}},
+			},
+			"todo": &graphql.Field{Type: todo},
+			"searchTodos": &graphql.Field{
+				Type: connection,
+				Args: graphql.FieldConfigArgument{
+					"query": &graphql.ArgumentConfig{Type: graphql.String},
+					"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query})
+	if err != nil {
+		t.Fatalf("failed to build schema: %v", err)
+	}
+	return schema
+}
+
+func TestQueryLimiterCost(t *testing.T) {
+	schema := newQueryLimitTestSchema(t)
+	limiter := NewQueryLimiter(newTestLogger(), 0, 0, 0, true)
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		cost      int
+		depth     int
+	}{
+		{
+			name:  "single object",
+			query: `{ todo { id title } }`,
+			cost:  2 + 2,
+			depth: 2,
+		},
+		{
+			name:  "list without first uses default size",
+			query: `{ todos { id } }`,
+			cost:  10 + defaultListSize*1,
+			depth: 2,
+		},
+		{
+			name:  "list with first literal",
+			query: `{ todos(first: 5) { id title } }`,
+			cost:  10 + 5*2,
+			depth: 2,
+		},
+		{
+			name:      "list with first variable",
+			query:     `query($n: Int) { todos(first: $n) { id } }`,
+			variables: map[string]interface{}{"n": float64(3)},
+			cost:      10 + 3*1,
+			depth:     2,
+		},
+		{
+			name:  "first is capped",
+			query: `{ todos(first: 100000) { id } }`,
+			cost:  10 + maxListSize*1,
+			depth: 2,
+		},
+		{
+			name:      "first variable is capped",
+			query:     `query($n: Int) { todos(first: $n) { id } }`,
+			variables: map[string]interface{}{"n": float64(1e18)},
+			cost:      10 + maxListSize*1,
+			depth:     2,
+		},
+		{
+			name:  "nested lists multiply",
+			query: `{ todos(first: 2) { subtasks(first: 3) { id } } }`,
+			cost:  10 + 2*(5+3*1),
+			depth: 3,
+		},
+		{
+			name:  "connection is multiplied by first",
+			query: `{ searchTodos(query: "a", first: 4) { edges { cursor node { id } } pageInfo { hasNextPage } } }`,
+			cost:  20 + 4*((1+(1+(1+1)))+(1+1)),
+			depth: 4,
+		},
+		{
+			name:  "connection uses argument default",
+			query: `{ searchTodos(query: "a") { edges { cursor } } }`,
+			cost:  20 + 20*(1+1),
+			depth: 3,
+		},
+		{
+			name:  "fragments are expanded",
+			query: `{ todo { ...F } } fragment F on Todo { id title }`,
+			cost:  2 + 2,
+			depth: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := limiter.Validate(schema, tt.query, "", tt.variables)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Cost != tt.cost {
+				t.Errorf("cost = %d, want %d", result.Cost, tt.cost)
+			}
+			if result.Depth != tt.depth {
+				t.Errorf("depth = %d, want %d", result.Depth, tt.depth)
+			}
+		})
+	}
+}
+
+func TestQueryLimiterCostSaturates(t *testing.T) {
+	schema := newQueryLimitTestSchema(t)
+	limiter := NewQueryLimiter(newTestLogger(), 0, 0, 1000, true)
+
+	// 入れ子のリストで大きなfirstを指定しても、オーバーフローせずに上限を超えて拒否される
+	query := `{ todos(first: 9223372036854775807) {
+		subtasks(first: 9223372036854775807) {
+			subtasks(first: 9223372036854775807) {
+				subtasks(first: 9223372036854775807) {
+					subtasks(first: 9223372036854775807) { id }
+				}
+			}
+		}
+	} }`
+	result, err := limiter.Validate(schema, query, "", nil)
+	if err == nil {
+		t.Fatalf("expected cost error, got cost %d", result.Cost)
+	}
+	if result.Cost != 1001 {
+		t.Errorf("cost = %d, want saturated 1001", result.Cost)
+	}
+
+	// 上限が無い場合もint32の範囲で飽和する
+	unlimited := NewQueryLimiter(newTestLogger(), 0, 0, 0, true)
+	result, err = unlimited.Validate(schema, query, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Cost <= 0 || result.Cost > unlimitedCostCeiling {
+		t.Errorf("cost = %d, want within (0, %d]", result.Cost, unlimitedCostCeiling)
+	}
+}
+
+func TestQueryLimiterLimits(t *testing.T) {
+	schema := newQueryLimitTestSchema(t)
+
+	tests := []struct {
+		name    string
+		limiter *QueryLimiter
+		query   string
+		wantErr bool
+	}{
+		{
+			name:    "depth within limit",
+			limiter: NewQueryLimiter(newTestLogger(), 3, 0, 0, true),
+			query:   `{ todos { subtasks { id } } }`,
+		},
+		{
+			name:    "depth exceeds limit",
+			limiter: NewQueryLimiter(newTestLogger(), 2, 0, 0, true),
+			query:   `{ todos { subtasks { id } } }`,
+			wantErr: true,
+		},
+		{
+			name:    "aliases exceed limit",
+			limiter: NewQueryLimiter(newTestLogger(), 0, 1, 0, true),
+			query:   `{ a: todo { id } b: todo { id } }`,
+			wantErr: true,
+		},
+		{
+			name:    "cost exceeds limit",
+			limiter: NewQueryLimiter(newTestLogger(), 0, 0, 15, true),
+			query:   `{ todos { id } }`,
+			wantErr: true,
+		},
+		{
+			name:    "introspection allowed",
+			limiter: NewQueryLimiter(newTestLogger(), 0, 0, 0, true),
+			query:   `{ __schema { queryType { name } } }`,
+		},
+		{
+			name:    "introspection disabled",
+			limiter: NewQueryLimiter(newTestLogger(), 0, 0, 0, false),
+			query:   `{ __type(name: "Todo") { name } }`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.limiter.Validate(schema, tt.query, "", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	_, err := NewQueryLimiter(newTestLogger(), 0, 0, 0, false).Validate(schema, `{ __schema { types { name } } }`, "", nil)
+	if !errors.Is(err, ErrIntrospectionDisabled) {
+		t.Errorf("err = %v, want ErrIntrospectionDisabled", err)
+	}
+}
+
+func TestQueryLimiterValidateTotal(t *testing.T) {
+	limiter := NewQueryLimiter(newTestLogger(), 0, 0, 100, true)
+	if err := limiter.ValidateTotal(100); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := limiter.ValidateTotal(101); err == nil {
+		t.Error("expected error for total over limit")
+	}
+	if err := NewQueryLimiter(newTestLogger(), 0, 0, 0, true).ValidateTotal(1 << 30); err != nil {
+		t.Errorf("unexpected error without limit: %v", err)
+	}
+}
+
+func TestListLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		want    int
+		wantErr error
+	}{
+		{name: "default", args: map[string]interface{}{}, want: maxListSize},
+		{name: "within range", args: map[string]interface{}{"limit": 5}, want: 5},
+		{name: "maximum", args: map[string]interface{}{"limit": maxListSize}, want: maxListSize},
+		{name: "zero", args: map[string]interface{}{"limit": 0}, wantErr: errListLimitOutOfRange},
+		{name: "over maximum", args: map[string]interface{}{"limit": maxListSize + 1}, wantErr: errListLimitOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listLimit(tt.args)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("listLimit() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("listLimit() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// 全件を取得していたリストは引数limitで切り詰め、コストもその件数で見積もる
+func TestUnboundedListsAreCapped(t *testing.T) {
+	query := (&GraphQLHandler{}).BuildRootQuery().Fields()
+	fields := map[string]*graphql.FieldDefinition{
+		"Query.users":        query["users"],
+		"Query.todos":        query["todos"],
+		"Query.todoByUserId": query["todoByUserId"],
+		"User.todos":         userType.Fields()["todos"],
+	}
+
+	for name, field := range fields {
+		if field == nil {
+			t.Errorf("%s not found", name)
+			continue
+		}
+		size := (&queryAnalyzer{}).requestedListSize(&ast.Field{}, field)
+		if size != maxListSize {
+			t.Errorf("%s: estimated list size = %d, want %d", name, size, maxListSize)
+		}
+	}
+
+	items := []int{1, 2, 3}
+	if got := truncateList(items, 2); len(got) != 2 {
+		t.Errorf("truncateList(3 items, 2) returned %d items", len(got))
+	}
+	if got := truncateList(items, 5); len(got) != 3 {
+		t.Errorf("truncateList(3 items, 5) returned %d items", len(got))
+	}
+}
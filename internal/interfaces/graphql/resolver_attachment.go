@@ -0,0 +1,115 @@
+package interfaces_graphql
+
+import (
+	"errors"
+	"mime/multipart"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 添付ファイルのミューテーション
+func (h *GraphQLHandler) attachmentMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"addAttachment": &graphql.Field{
+			Type: attachmentType,
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"file":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(uploadScalar)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Adding attachment...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Adding attachment", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				fileHeader, ok := p.Args["file"].(*multipart.FileHeader)
+				if !ok || fileHeader == nil {
+					h.Logger.ErrorLog.Println("file is required")
+					h.Logger.PrintDuration("Adding attachment", h.timer.GetDuration())
+					return nil, errors.New("file is required")
+				}
+
+				file, err := fileHeader.Open()
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to open uploaded file: %v", err)
+					h.Logger.PrintDuration("Adding attachment", h.timer.GetDuration())
+					return nil, err
+				}
+				defer file.Close()
+
+				attachment, err := h.attachmentUsecase.AddAttachment(userId, todoId, fileHeader.Filename, fileHeader.Size, file)
+				if err != nil {
+					switch err.Error() {
+					case "todo_id is empty", "file name is empty", "file is empty":
+						h.Logger.ErrorLog.Printf("Invalid attachment: %v", err)
+						h.Logger.PrintDuration("Adding attachment", h.timer.GetDuration())
+						return nil, err
+					case "file is too large", "file type is not allowed":
+						h.Logger.ErrorLog.Printf("Attachment rejected: %v", err)
+						h.Logger.PrintDuration("Adding attachment", h.timer.GetDuration())
+						return nil, err
+					case "todo not found", "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Adding attachment", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to add attachment: %v", err)
+						h.Logger.PrintDuration("Adding attachment", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Added attachment: %s", attachment.ID)
+				h.Logger.PrintDuration("Adding attachment", h.timer.GetDuration())
+				return toAttachmentMap(attachment), nil
+			},
+		},
+		"removeAttachment": &graphql.Field{
+			Type: removeAttachmentPayload,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Removing attachment...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Removing attachment", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				err := h.attachmentUsecase.RemoveAttachment(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "id is empty", "attachment not found":
+						h.Logger.ErrorLog.Printf("Attachment not found: %v", err)
+						h.Logger.PrintDuration("Removing attachment", h.timer.GetDuration())
+						return nil, err
+					case "todo not found", "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Removing attachment", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to remove attachment: %v", err)
+						h.Logger.PrintDuration("Removing attachment", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Attachment removed successfully")
+				h.Logger.PrintDuration("Removing attachment", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success": true,
+					"message": "Attachment removed successfully",
+				}, nil
+			},
+		},
+	}
+}
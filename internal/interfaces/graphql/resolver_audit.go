@@ -0,0 +1,92 @@
+package interfaces_graphql
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 監査ログのクエリ
+func (h *GraphQLHandler) auditQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"auditLog": &graphql.Field{
+			Type: auditLogPageType,
+			Args: graphql.FieldConfigArgument{
+				"actorId":    &graphql.ArgumentConfig{Type: graphql.String},
+				"action":     &graphql.ArgumentConfig{Type: graphql.String},
+				"entityType": &graphql.ArgumentConfig{Type: graphql.String},
+				"entityId":   &graphql.ArgumentConfig{Type: graphql.String},
+				"since":      &graphql.ArgumentConfig{Type: graphql.String},
+				"until":      &graphql.ArgumentConfig{Type: graphql.String},
+				"limit":      &graphql.ArgumentConfig{Type: graphql.Int},
+				"offset":     &graphql.ArgumentConfig{Type: graphql.Int},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching audit logs...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching audit logs", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+				if !h.authHandler.IsAdmin(userId) {
+					h.Logger.ErrorLog.Println("forbidden")
+					h.Logger.PrintDuration("Fetching audit logs", h.timer.GetDuration())
+					return nil, errors.New("forbidden")
+				}
+
+				filter := domain_audit.AuditLogFilter{}
+				filter.ActorId, _ = p.Args["actorId"].(string)
+				filter.Action, _ = p.Args["action"].(string)
+				filter.EntityType, _ = p.Args["entityType"].(string)
+				filter.EntityId, _ = p.Args["entityId"].(string)
+				filter.Limit, _ = p.Args["limit"].(int)
+				filter.Offset, _ = p.Args["offset"].(int)
+				since, err := parseTimeArg(p.Args, "since")
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Invalid since: %v", err)
+					h.Logger.PrintDuration("Fetching audit logs", h.timer.GetDuration())
+					return nil, errors.New("since must be RFC3339")
+				}
+				until, err := parseTimeArg(p.Args, "until")
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Invalid until: %v", err)
+					h.Logger.PrintDuration("Fetching audit logs", h.timer.GetDuration())
+					return nil, errors.New("until must be RFC3339")
+				}
+				filter.Since = since
+				filter.Until = until
+
+				logs, total, err := h.auditLogUsecase.GetAuditLogs(filter)
+				if err != nil {
+					switch err.Error() {
+					case "invalid action", "since must be before until", "limit and offset must not be negative":
+						h.Logger.ErrorLog.Printf("Invalid audit log filter: %v", err)
+						h.Logger.PrintDuration("Fetching audit logs", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get audit logs: %v", err)
+						h.Logger.PrintDuration("Fetching audit logs", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				items := make([]map[string]interface{}, 0, len(logs))
+				for _, l := range logs {
+					items = append(items, toAuditLogMap(l))
+				}
+				h.Logger.InfoLog.Printf("Fetched %d audit logs", len(items))
+				h.Logger.PrintDuration("Fetching audit logs", h.timer.GetDuration())
+				return map[string]interface{}{
+					"items":      items,
+					"totalCount": total,
+					"offset":     filter.Offset,
+					"hasNext":    filter.Offset+len(items) < total,
+				}, nil
+			},
+		},
+	}
+}
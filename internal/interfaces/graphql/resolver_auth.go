@@ -0,0 +1,117 @@
+package interfaces_graphql
+
+import (
+	interfaces_auth "backend/internal/interfaces/auth"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 認証のミューテーション
+func (h *GraphQLHandler) authMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"login": &graphql.Field{
+			Type: loginPayload,
+			Args: graphql.FieldConfigArgument{
+				"email":    &graphql.ArgumentConfig{Type: graphql.String},
+				"password": &graphql.ArgumentConfig{Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Logging in...")
+				h.timer.Start()
+
+				email := p.Args["email"].(string)
+				password := p.Args["password"].(string)
+
+				ipAddress := interfaces_auth.ClientIPFromContext(p.Context)
+
+				token, err := h.authUsecase.Login(email, password, ipAddress)
+				if err != nil {
+					switch err.Error() {
+					case "account is temporarily locked":
+						h.Logger.ErrorLog.Printf("Account is locked: %v", err)
+						h.Logger.PrintDuration("Logging in", h.timer.GetDuration())
+						return nil, err
+					case "too many login attempts":
+						h.Logger.ErrorLog.Printf("Too many login attempts: %v", err)
+						h.Logger.PrintDuration("Logging in", h.timer.GetDuration())
+						return nil, err
+					case "invalid email or password":
+						h.Logger.ErrorLog.Printf("Invalid email or password: %v", err)
+						h.Logger.PrintDuration("Logging in", h.timer.GetDuration())
+						return nil, err
+					case "invalid email format":
+						h.Logger.ErrorLog.Printf("Invalid email format: %v", err)
+						h.Logger.PrintDuration("Logging in", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to login: %v", err)
+						h.Logger.PrintDuration("Logging in", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				// JWTトークンを生成
+				tokenString, err := h.authHandler.GenerateToken(token)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to generate token: %v", err)
+					h.Logger.PrintDuration("Logging in", h.timer.GetDuration())
+					return nil, err
+				}
+
+				h.Logger.InfoLog.Printf("Logged in: %v", tokenString != "")
+				h.Logger.PrintDuration("Logging in", h.timer.GetDuration())
+				return map[string]interface{}{
+					"token": tokenString,
+				}, nil
+			},
+		},
+		"unlockAccount": &graphql.Field{
+			Type: unlockAccountPayload,
+			Args: graphql.FieldConfigArgument{
+				"email":     &graphql.ArgumentConfig{Type: graphql.String},
+				"ipAddress": &graphql.ArgumentConfig{Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Unlocking account...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Unlocking account", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+				if !h.authHandler.IsAdmin(userId) {
+					h.Logger.ErrorLog.Println("forbidden")
+					h.Logger.PrintDuration("Unlocking account", h.timer.GetDuration())
+					return nil, errors.New("forbidden")
+				}
+
+				email, _ := p.Args["email"].(string)
+				ipAddress, _ := p.Args["ipAddress"].(string)
+
+				err := h.authUsecase.UnlockAccount(email, ipAddress)
+				if err != nil {
+					switch err.Error() {
+					case "email or ip address is required":
+						h.Logger.ErrorLog.Printf("Invalid unlock request: %v", err)
+						h.Logger.PrintDuration("Unlocking account", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to unlock account: %v", err)
+						h.Logger.PrintDuration("Unlocking account", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Account unlocked successfully")
+				h.Logger.PrintDuration("Unlocking account", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success": true,
+					"message": "Account unlocked successfully",
+				}, nil
+			},
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// カレンダー配信のクエリ
+func (h *GraphQLHandler) calendarQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"calendarFeed": &graphql.Field{
+			Type: calendarFeedType,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching calendar feed...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching calendar feed", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				feed, err := h.calendarFeedUsecase.GetFeed(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching calendar feed", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get calendar feed: %v", err)
+						h.Logger.PrintDuration("Fetching calendar feed", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Fetched calendar feed (enabled: %v)", feed != nil)
+				h.Logger.PrintDuration("Fetching calendar feed", h.timer.GetDuration())
+				return toCalendarFeedMap(feed), nil
+			},
+		},
+	}
+}
+
+// カレンダー配信のミューテーション
+func (h *GraphQLHandler) calendarMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"regenerateCalendarFeedToken": &graphql.Field{
+			Type:        calendarFeedTokenType,
+			Description: "カレンダーの購読フィードのURLを発行し直す(以前のURLは無効になる)",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Regenerating calendar feed token...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Regenerating calendar feed token", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				token, err := h.calendarFeedUsecase.RegenerateToken(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Regenerating calendar feed token", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to regenerate calendar feed token: %v", err)
+						h.Logger.PrintDuration("Regenerating calendar feed token", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Regenerated calendar feed token")
+				h.Logger.PrintDuration("Regenerating calendar feed token", h.timer.GetDuration())
+				return toCalendarFeedTokenMap(token), nil
+			},
+		},
+		"revokeCalendarFeed": &graphql.Field{
+			Type:        graphql.Boolean,
+			Description: "カレンダーの購読フィードのURLを無効にする(発行していなかった場合はfalse)",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Revoking calendar feed...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Revoking calendar feed", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				revoked, err := h.calendarFeedUsecase.RevokeFeed(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Revoking calendar feed", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to revoke calendar feed: %v", err)
+						h.Logger.PrintDuration("Revoking calendar feed", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Revoked calendar feed: %v", revoked)
+				h.Logger.PrintDuration("Revoking calendar feed", h.timer.GetDuration())
+				return revoked, nil
+			},
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package interfaces_graphql
+
+import (
+	domain_share "backend/internal/domain/share"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// コメントのミューテーション
+func (h *GraphQLHandler) commentMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"addComment": &graphql.Field{
+			Type: commentType,
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"body":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Adding comment...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Adding comment", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				body := p.Args["body"].(string)
+
+				// コメントは閲覧権限以上を持つTodoにのみ追加できる
+				_, err := h.todoUsecase.AuthorizeTodo(userId, todoId, domain_share.ShareRoleViewer)
+				if err != nil {
+					switch err.Error() {
+					case "id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Adding comment", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Adding comment", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to authorize todo: %v", err)
+						h.Logger.PrintDuration("Adding comment", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				comment, err := h.commentUsecase.AddComment(userId, todoId, body)
+				if err != nil {
+					switch err.Error() {
+					case "body is empty", "body is too long":
+						h.Logger.ErrorLog.Printf("Invalid comment: %v", err)
+						h.Logger.PrintDuration("Adding comment", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to add comment: %v", err)
+						h.Logger.PrintDuration("Adding comment", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Added comment: %s", comment.ID)
+				h.Logger.PrintDuration("Adding comment", h.timer.GetDuration())
+				return toCommentMap(comment), nil
+			},
+		},
+		"editComment": &graphql.Field{
+			Type: commentType,
+			Args: graphql.FieldConfigArgument{
+				"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"body": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Editing comment...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Editing comment", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				body := p.Args["body"].(string)
+				comment, err := h.commentUsecase.EditComment(userId, id, body)
+				if err != nil {
+					switch err.Error() {
+					case "body is empty", "body is too long":
+						h.Logger.ErrorLog.Printf("Invalid comment: %v", err)
+						h.Logger.PrintDuration("Editing comment", h.timer.GetDuration())
+						return nil, err
+					case "comment_id is empty", "comment not found":
+						h.Logger.ErrorLog.Printf("Comment not found: %v", err)
+						h.Logger.PrintDuration("Editing comment", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Comment not editable: %v", err)
+						h.Logger.PrintDuration("Editing comment", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to edit comment: %v", err)
+						h.Logger.PrintDuration("Editing comment", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Edited comment: %s", comment.ID)
+				h.Logger.PrintDuration("Editing comment", h.timer.GetDuration())
+				return toCommentMap(comment), nil
+			},
+		},
+		"deleteComment": &graphql.Field{
+			Type: deleteCommentPayload,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Deleting comment...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Deleting comment", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				err := h.commentUsecase.DeleteComment(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "comment_id is empty", "comment not found":
+						h.Logger.ErrorLog.Printf("Comment not found: %v", err)
+						h.Logger.PrintDuration("Deleting comment", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Comment not deletable: %v", err)
+						h.Logger.PrintDuration("Deleting comment", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to delete comment: %v", err)
+						h.Logger.PrintDuration("Deleting comment", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Comment deleted successfully")
+				h.Logger.PrintDuration("Deleting comment", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success": true,
+					"message": "Comment deleted successfully",
+				}, nil
+			},
+		},
+	}
+}
@@ -0,0 +1,225 @@
+package interfaces_graphql
+
+import (
+	"errors"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 繰り返しのクエリ
+func (h *GraphQLHandler) recurrenceQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"upcomingOccurrences": &graphql.Field{
+			Type: graphql.NewList(occurrenceType),
+			Args: graphql.FieldConfigArgument{
+				"days":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 30},
+				"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 100},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching upcoming occurrences...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching upcoming occurrences", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				days, _ := p.Args["days"].(int)
+				limit, _ := p.Args["limit"].(int)
+
+				occurrences, err := h.recurrenceUsecase.GetUpcomingOccurrences(userId, time.Duration(days)*24*time.Hour, limit)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty", "period must be positive", "limit must be positive":
+						h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+						h.Logger.PrintDuration("Fetching upcoming occurrences", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get upcoming occurrences: %v", err)
+						h.Logger.PrintDuration("Fetching upcoming occurrences", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(occurrences))
+				for _, o := range occurrences {
+					result = append(result, toOccurrenceMap(o))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d upcoming occurrences", len(result))
+				h.Logger.PrintDuration("Fetching upcoming occurrences", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+	}
+}
+
+// 繰り返しのミューテーション
+func (h *GraphQLHandler) recurrenceMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"setTodoRecurrence": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"rule": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "RFC 5545のRRULE(例: FREQ=WEEKLY;BYDAY=MO,WE)",
+				},
+				"timezone": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "展開に使うタイムゾーン(IANA名、省略時はサーバーの既定値)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Setting todo recurrence...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Setting todo recurrence", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				rule := p.Args["rule"].(string)
+				timezone, _ := p.Args["timezone"].(string)
+				_, err := h.recurrenceUsecase.SetRecurrence(userId, todoId, rule, timezone)
+				if err != nil {
+					switch err.Error() {
+					case "invalid rrule", "invalid timezone", "due date is required", "todo is already completed":
+						h.Logger.ErrorLog.Printf("Invalid recurrence: %v", err)
+						h.Logger.PrintDuration("Setting todo recurrence", h.timer.GetDuration())
+						return nil, err
+					case "todo_id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Setting todo recurrence", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Setting todo recurrence", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to set todo recurrence: %v", err)
+						h.Logger.PrintDuration("Setting todo recurrence", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				todo, err := h.todoUsecase.GetTodoById(todoId)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to get todo by id: %v", err)
+					h.Logger.PrintDuration("Setting todo recurrence", h.timer.GetDuration())
+					return nil, err
+				}
+
+				h.Logger.InfoLog.Printf("Set todo recurrence: %s", todo.ID)
+				h.Logger.PrintDuration("Setting todo recurrence", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+		"clearTodoRecurrence": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{"todoId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Clearing todo recurrence...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Clearing todo recurrence", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				err := h.recurrenceUsecase.ClearRecurrence(userId, todoId)
+				if err != nil {
+					switch err.Error() {
+					case "todo_id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Clearing todo recurrence", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Clearing todo recurrence", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to clear todo recurrence: %v", err)
+						h.Logger.PrintDuration("Clearing todo recurrence", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				todo, err := h.todoUsecase.GetTodoById(todoId)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to get todo by id: %v", err)
+					h.Logger.PrintDuration("Clearing todo recurrence", h.timer.GetDuration())
+					return nil, err
+				}
+
+				h.Logger.InfoLog.Printf("Cleared todo recurrence: %s", todo.ID)
+				h.Logger.PrintDuration("Clearing todo recurrence", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+		"skipOccurrence": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"at": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "スキップする発生日時(RFC3339)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Skipping occurrence...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Skipping occurrence", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				at, err := time.Parse(time.RFC3339, p.Args["at"].(string))
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Invalid occurrence: %v", err)
+					h.Logger.PrintDuration("Skipping occurrence", h.timer.GetDuration())
+					return nil, errors.New("at must be RFC3339")
+				}
+
+				todo, err := h.recurrenceUsecase.SkipOccurrence(userId, todoId, at, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "not an occurrence", "occurrence already passed", "cannot skip the last occurrence":
+						h.Logger.ErrorLog.Printf("Invalid occurrence: %v", err)
+						h.Logger.PrintDuration("Skipping occurrence", h.timer.GetDuration())
+						return nil, err
+					case "todo_id is empty", "todo not found", "recurrence not found":
+						h.Logger.ErrorLog.Printf("Recurrence not found: %v", err)
+						h.Logger.PrintDuration("Skipping occurrence", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Skipping occurrence", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to skip occurrence: %v", err)
+						h.Logger.PrintDuration("Skipping occurrence", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Skipped occurrence: %s", todo.ID)
+				h.Logger.PrintDuration("Skipping occurrence", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 全文検索のクエリ
+func (h *GraphQLHandler) searchQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"searchTodos": &graphql.Field{
+			Type: todoSearchConnectionType,
+			Args: graphql.FieldConfigArgument{
+				"query": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "検索語(空白区切りはAND、\"...\"はフレーズ、orはOR、-は除外)",
+				},
+				"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+				"after": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "前のページのendCursor(省略した場合は先頭から)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Searching todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Searching todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				query := p.Args["query"].(string)
+				first, _ := p.Args["first"].(int)
+				after, _ := p.Args["after"].(string)
+
+				page, err := h.searchUsecase.SearchTodos(userId, query, first, after)
+				if err != nil {
+					switch err.Error() {
+					case "query is empty", "query is too long", "first must not be negative", "invalid cursor":
+						h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+						h.Logger.PrintDuration("Searching todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to search todos: %v", err)
+						h.Logger.PrintDuration("Searching todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Found %d todos", len(page.Results))
+				h.Logger.PrintDuration("Searching todos", h.timer.GetDuration())
+				return toTodoSearchConnection(page), nil
+			},
+		},
+	}
+}
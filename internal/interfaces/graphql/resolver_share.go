@@ -0,0 +1,400 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 共有のクエリ
+func (h *GraphQLHandler) shareQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"sharedTodos": &graphql.Field{
+			Type: graphql.NewList(todoType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching shared todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching shared todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todos, err := h.todoUsecase.GetSharedTodos(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching shared todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get shared todos: %v", err)
+						h.Logger.PrintDuration("Fetching shared todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(todos))
+				for _, t := range todos {
+					result = append(result, toTodoMap(t))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d shared todos", len(result))
+				h.Logger.PrintDuration("Fetching shared todos", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"invitations": &graphql.Field{
+			Type: graphql.NewList(todoShareType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching invitations...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching invitations", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				shares, err := h.shareUsecase.GetInvitations(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching invitations", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get invitations: %v", err)
+						h.Logger.PrintDuration("Fetching invitations", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(shares))
+				for _, s := range shares {
+					result = append(result, toTodoShareMap(s))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d invitations", len(result))
+				h.Logger.PrintDuration("Fetching invitations", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"todoShares": &graphql.Field{
+			Type: graphql.NewList(todoShareType),
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "TodoのID(省略時は自分が所有する全ての共有)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching todo shares...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching todo shares", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				var todoId *string
+				if v, ok := p.Args["todoId"].(string); ok && v != "" {
+					todoId = &v
+				}
+				shares, err := h.shareUsecase.GetShares(userId, todoId)
+				if err != nil {
+					switch err.Error() {
+					case "todo_id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Fetching todo shares", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Fetching todo shares", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get todo shares: %v", err)
+						h.Logger.PrintDuration("Fetching todo shares", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(shares))
+				for _, s := range shares {
+					result = append(result, toTodoShareMap(s))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d todo shares", len(result))
+				h.Logger.PrintDuration("Fetching todo shares", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+	}
+}
+
+// 共有のミューテーション
+func (h *GraphQLHandler) shareMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"shareTodo": &graphql.Field{
+			Type: todoShareType,
+			Args: graphql.FieldConfigArgument{
+				"todoId":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"memberId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"role":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(shareRoleEnum)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Sharing todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Sharing todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				memberId := p.Args["memberId"].(string)
+				role, _ := p.Args["role"].(string)
+				share, err := h.shareUsecase.ShareTodo(userId, todoId, memberId, role)
+				if err != nil {
+					switch err.Error() {
+					case "member_id is empty", "invalid role", "cannot share with owner", "already shared":
+						h.Logger.ErrorLog.Printf("Invalid share: %v", err)
+						h.Logger.PrintDuration("Sharing todo", h.timer.GetDuration())
+						return nil, err
+					case "todo_id is empty", "todo not found", "member not found":
+						h.Logger.ErrorLog.Printf("Share target not found: %v", err)
+						h.Logger.PrintDuration("Sharing todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Sharing todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to share todo: %v", err)
+						h.Logger.PrintDuration("Sharing todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Shared todo: %s", share.ID)
+				h.Logger.PrintDuration("Sharing todo", h.timer.GetDuration())
+				return toTodoShareMap(share), nil
+			},
+		},
+		"shareAllTodos": &graphql.Field{
+			Type: todoShareType,
+			Args: graphql.FieldConfigArgument{
+				"memberId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"role":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(shareRoleEnum)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Sharing all todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Sharing all todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				memberId := p.Args["memberId"].(string)
+				role, _ := p.Args["role"].(string)
+				share, err := h.shareUsecase.ShareAllTodos(userId, memberId, role)
+				if err != nil {
+					switch err.Error() {
+					case "member_id is empty", "invalid role", "cannot share with owner", "already shared":
+						h.Logger.ErrorLog.Printf("Invalid share: %v", err)
+						h.Logger.PrintDuration("Sharing all todos", h.timer.GetDuration())
+						return nil, err
+					case "member not found":
+						h.Logger.ErrorLog.Printf("Share target not found: %v", err)
+						h.Logger.PrintDuration("Sharing all todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to share all todos: %v", err)
+						h.Logger.PrintDuration("Sharing all todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Shared all todos: %s", share.ID)
+				h.Logger.PrintDuration("Sharing all todos", h.timer.GetDuration())
+				return toTodoShareMap(share), nil
+			},
+		},
+		"acceptInvitation": &graphql.Field{
+			Type: todoShareType,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Accepting invitation...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Accepting invitation", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				share, err := h.shareUsecase.AcceptInvitation(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "share_id is empty", "invitation not found":
+						h.Logger.ErrorLog.Printf("Invitation not found: %v", err)
+						h.Logger.PrintDuration("Accepting invitation", h.timer.GetDuration())
+						return nil, err
+					case "invitation already accepted":
+						h.Logger.ErrorLog.Printf("Invalid invitation: %v", err)
+						h.Logger.PrintDuration("Accepting invitation", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to accept invitation: %v", err)
+						h.Logger.PrintDuration("Accepting invitation", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Accepted invitation: %s", share.ID)
+				h.Logger.PrintDuration("Accepting invitation", h.timer.GetDuration())
+				return toTodoShareMap(share), nil
+			},
+		},
+		"declineInvitation": &graphql.Field{
+			Type: deleteSharePayload,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Declining invitation...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Declining invitation", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				err := h.shareUsecase.DeclineInvitation(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "share_id is empty", "invitation not found":
+						h.Logger.ErrorLog.Printf("Invitation not found: %v", err)
+						h.Logger.PrintDuration("Declining invitation", h.timer.GetDuration())
+						return nil, err
+					case "invitation already accepted":
+						h.Logger.ErrorLog.Printf("Invalid invitation: %v", err)
+						h.Logger.PrintDuration("Declining invitation", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to decline invitation: %v", err)
+						h.Logger.PrintDuration("Declining invitation", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Invitation declined successfully")
+				h.Logger.PrintDuration("Declining invitation", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success": true,
+					"message": "Invitation declined successfully",
+				}, nil
+			},
+		},
+		"updateShareRole": &graphql.Field{
+			Type: todoShareType,
+			Args: graphql.FieldConfigArgument{
+				"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"role": &graphql.ArgumentConfig{Type: graphql.NewNonNull(shareRoleEnum)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Updating share role...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Updating share role", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				role, _ := p.Args["role"].(string)
+				share, err := h.shareUsecase.UpdateShareRole(userId, id, role)
+				if err != nil {
+					switch err.Error() {
+					case "invalid role":
+						h.Logger.ErrorLog.Printf("Invalid share: %v", err)
+						h.Logger.PrintDuration("Updating share role", h.timer.GetDuration())
+						return nil, err
+					case "share_id is empty", "share not found", "todo not found":
+						h.Logger.ErrorLog.Printf("Share not found: %v", err)
+						h.Logger.PrintDuration("Updating share role", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Share not accessible: %v", err)
+						h.Logger.PrintDuration("Updating share role", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to update share role: %v", err)
+						h.Logger.PrintDuration("Updating share role", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Updated share role: %s", share.ID)
+				h.Logger.PrintDuration("Updating share role", h.timer.GetDuration())
+				return toTodoShareMap(share), nil
+			},
+		},
+		"revokeShare": &graphql.Field{
+			Type: deleteSharePayload,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Revoking share...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Revoking share", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				err := h.shareUsecase.RevokeShare(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "share_id is empty", "share not found", "todo not found":
+						h.Logger.ErrorLog.Printf("Share not found: %v", err)
+						h.Logger.PrintDuration("Revoking share", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Share not accessible: %v", err)
+						h.Logger.PrintDuration("Revoking share", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to revoke share: %v", err)
+						h.Logger.PrintDuration("Revoking share", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Share revoked successfully")
+				h.Logger.PrintDuration("Revoking share", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success": true,
+					"message": "Share revoked successfully",
+				}, nil
+			},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// サブタスクのミューテーション
+func (h *GraphQLHandler) subtaskMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"setTodoParent": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"parentId": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "親TodoのID(省略またはnullの場合は最上位のTodoにする)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Setting todo parent...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Setting todo parent", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				var parentId *string
+				if v, ok := p.Args["parentId"].(string); ok && v != "" {
+					parentId = &v
+				}
+				todo, err := h.todoUsecase.SetTodoParent(userId, id, parentId, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Setting todo parent", h.timer.GetDuration())
+						return nil, err
+					case "parent_id is empty", "parent not found":
+						h.Logger.ErrorLog.Printf("Parent todo not found: %v", err)
+						h.Logger.PrintDuration("Setting todo parent", h.timer.GetDuration())
+						return nil, err
+					case "parent would create a cycle", "max depth exceeded":
+						h.Logger.ErrorLog.Printf("Invalid parent todo: %v", err)
+						h.Logger.PrintDuration("Setting todo parent", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Setting todo parent", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to set todo parent: %v", err)
+						h.Logger.PrintDuration("Setting todo parent", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Set todo parent: %s", todo.ID)
+				h.Logger.PrintDuration("Setting todo parent", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+	}
+}
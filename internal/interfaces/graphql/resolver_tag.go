@@ -0,0 +1,291 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// タグのクエリ
+func (h *GraphQLHandler) tagQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"tags": &graphql.Field{
+			Type: graphql.NewList(tagType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching tags...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching tags", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				tags, err := h.tagUsecase.GetTagsByUserId(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching tags", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get tags: %v", err)
+						h.Logger.PrintDuration("Fetching tags", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(tags))
+				for _, t := range tags {
+					result = append(result, toTagMap(t))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d tags", len(result))
+				h.Logger.PrintDuration("Fetching tags", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+	}
+}
+
+// タグのミューテーション
+func (h *GraphQLHandler) tagMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"createTag": &graphql.Field{
+			Type: tagType,
+			Args: graphql.FieldConfigArgument{"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Creating tag...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Creating tag", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				name := p.Args["name"].(string)
+				tag, err := h.tagUsecase.CreateTag(userId, name)
+				if err != nil {
+					switch err.Error() {
+					case "name is empty", "name is too long":
+						h.Logger.ErrorLog.Printf("Invalid tag name: %v", err)
+						h.Logger.PrintDuration("Creating tag", h.timer.GetDuration())
+						return nil, err
+					case "tag already exists":
+						h.Logger.ErrorLog.Printf("Tag already exists: %v", err)
+						h.Logger.PrintDuration("Creating tag", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to create tag: %v", err)
+						h.Logger.PrintDuration("Creating tag", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Created tag: %s", tag.ID)
+				h.Logger.PrintDuration("Creating tag", h.timer.GetDuration())
+				return toTagMap(tag), nil
+			},
+		},
+		"renameTag": &graphql.Field{
+			Type: tagType,
+			Args: graphql.FieldConfigArgument{
+				"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Renaming tag...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Renaming tag", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				name := p.Args["name"].(string)
+				tag, err := h.tagUsecase.RenameTag(userId, id, name)
+				if err != nil {
+					switch err.Error() {
+					case "name is empty", "name is too long":
+						h.Logger.ErrorLog.Printf("Invalid tag name: %v", err)
+						h.Logger.PrintDuration("Renaming tag", h.timer.GetDuration())
+						return nil, err
+					case "tag already exists":
+						h.Logger.ErrorLog.Printf("Tag already exists: %v", err)
+						h.Logger.PrintDuration("Renaming tag", h.timer.GetDuration())
+						return nil, err
+					case "tag_id is empty", "tag not found":
+						h.Logger.ErrorLog.Printf("Tag not found: %v", err)
+						h.Logger.PrintDuration("Renaming tag", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Tag not accessible: %v", err)
+						h.Logger.PrintDuration("Renaming tag", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to rename tag: %v", err)
+						h.Logger.PrintDuration("Renaming tag", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Renamed tag: %s", tag.ID)
+				h.Logger.PrintDuration("Renaming tag", h.timer.GetDuration())
+				return toTagMap(tag), nil
+			},
+		},
+		"deleteTag": &graphql.Field{
+			Type: deleteTagPayload,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Deleting tag...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Deleting tag", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				err := h.tagUsecase.DeleteTag(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "tag_id is empty", "tag not found":
+						h.Logger.ErrorLog.Printf("Tag not found: %v", err)
+						h.Logger.PrintDuration("Deleting tag", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Tag not accessible: %v", err)
+						h.Logger.PrintDuration("Deleting tag", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to delete tag: %v", err)
+						h.Logger.PrintDuration("Deleting tag", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Tag deleted successfully")
+				h.Logger.PrintDuration("Deleting tag", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success": true,
+					"message": "Tag deleted successfully",
+				}, nil
+			},
+		},
+		"tagTodo": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"tagId":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Tagging todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Tagging todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				tagId := p.Args["tagId"].(string)
+				err := h.tagUsecase.TagTodo(userId, todoId, tagId)
+				if err != nil {
+					switch err.Error() {
+					case "todo_id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Tagging todo", h.timer.GetDuration())
+						return nil, err
+					case "tag_id is empty", "tag not found":
+						h.Logger.ErrorLog.Printf("Tag not found: %v", err)
+						h.Logger.PrintDuration("Tagging todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Tag not accessible: %v", err)
+						h.Logger.PrintDuration("Tagging todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to tag todo: %v", err)
+						h.Logger.PrintDuration("Tagging todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				todo, err := h.todoUsecase.GetTodoById(todoId)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to get todo by id: %v", err)
+					h.Logger.PrintDuration("Tagging todo", h.timer.GetDuration())
+					return nil, err
+				}
+
+				h.Logger.InfoLog.Printf("Tagging todo completed: %s", todo.ID)
+				h.Logger.PrintDuration("Tagging todo", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+		"untagTodo": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"tagId":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Untagging todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Untagging todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				tagId := p.Args["tagId"].(string)
+				err := h.tagUsecase.UntagTodo(userId, todoId, tagId)
+				if err != nil {
+					switch err.Error() {
+					case "todo_id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Untagging todo", h.timer.GetDuration())
+						return nil, err
+					case "tag_id is empty", "tag not found":
+						h.Logger.ErrorLog.Printf("Tag not found: %v", err)
+						h.Logger.PrintDuration("Untagging todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Tag not accessible: %v", err)
+						h.Logger.PrintDuration("Untagging todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to untag todo: %v", err)
+						h.Logger.PrintDuration("Untagging todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				todo, err := h.todoUsecase.GetTodoById(todoId)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to get todo by id: %v", err)
+					h.Logger.PrintDuration("Untagging todo", h.timer.GetDuration())
+					return nil, err
+				}
+
+				h.Logger.InfoLog.Printf("Untagging todo completed: %s", todo.ID)
+				h.Logger.PrintDuration("Untagging todo", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,682 @@
+package interfaces_graphql
+
+import (
+	domain_share "backend/internal/domain/share"
+	domain_todo "backend/internal/domain/todo"
+	"errors"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Todoのクエリ
+func (h *GraphQLHandler) todoQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"todos": &graphql.Field{
+			Type: graphql.NewList(todoType),
+			Args: graphql.FieldConfigArgument{"limit": listLimitArg},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+				limit, err := listLimit(p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+					h.Logger.PrintDuration("Fetching todos", h.timer.GetDuration())
+					return nil, err
+				}
+
+				todos, err := h.todoUsecase.GetVisibleTodos(userId)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to get visible todos: %v", err)
+					h.Logger.PrintDuration("Fetching todos", h.timer.GetDuration())
+					return nil, err
+				}
+				todos = truncateList(todos, limit)
+
+				result := make([]map[string]interface{}, 0, len(todos))
+				for _, t := range todos {
+					result = append(result, toTodoMap(t))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d todos", len(result))
+				h.Logger.PrintDuration("Fetching todos", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"todo": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.String}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching todo by id...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching todo by id", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				h.Logger.InfoLog.Printf("Fetching todo by id: %s", id)
+				todo, err := h.todoUsecase.AuthorizeTodo(userId, id, domain_share.ShareRoleViewer)
+				if err != nil {
+					switch err.Error() {
+					case "id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Fetching todo by id", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Fetching todo by id", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get todo by id: %v", err)
+						h.Logger.PrintDuration("Fetching todo by id", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := toTodoMap(todo)
+
+				h.Logger.InfoLog.Printf("Fetched todo: %v", result != nil)
+				h.Logger.PrintDuration("Fetching todo by id", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"todoByUserId": &graphql.Field{
+			Type: graphql.NewList(todoType),
+			Args: graphql.FieldConfigArgument{
+				"tagIds": tagFilterArgs["tagIds"],
+				"limit":  listLimitArg,
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching todo by user id...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching todo by user id", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+				limit, err := listLimit(p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+					h.Logger.PrintDuration("Fetching todo by user id", h.timer.GetDuration())
+					return nil, err
+				}
+
+				todos, err := h.todoUsecase.GetTodoByUserId(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching todo by user id", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get todo by user id: %v", err)
+						h.Logger.PrintDuration("Fetching todo by user id", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				// タグによる絞り込み
+				todos, err = h.filterTodosByTags(userId, todos, p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to filter todos by tags: %v", err)
+					h.Logger.PrintDuration("Fetching todo by user id", h.timer.GetDuration())
+					return nil, err
+				}
+				todos = truncateList(todos, limit)
+
+				result := make([]map[string]interface{}, 0, len(todos))
+				for _, t := range todos {
+					result = append(result, toTodoMap(t))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d todos", len(result))
+				h.Logger.PrintDuration("Fetching todo by user id", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"overdueTodos": &graphql.Field{
+			Type: graphql.NewList(todoType),
+			Args: tagFilterArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching overdue todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching overdue todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todos, err := h.todoUsecase.GetOverdueTodos(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+						h.Logger.PrintDuration("Fetching overdue todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get overdue todos: %v", err)
+						h.Logger.PrintDuration("Fetching overdue todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				// タグによる絞り込み
+				todos, err = h.filterTodosByTags(userId, todos, p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to filter todos by tags: %v", err)
+					h.Logger.PrintDuration("Fetching overdue todos", h.timer.GetDuration())
+					return nil, err
+				}
+
+				result := make([]map[string]interface{}, 0, len(todos))
+				for _, t := range todos {
+					result = append(result, toTodoMap(t))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d overdue todos", len(result))
+				h.Logger.PrintDuration("Fetching overdue todos", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"upcomingTodos": &graphql.Field{
+			Type: graphql.NewList(todoType),
+			Args: graphql.FieldConfigArgument{
+				"days":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 7},
+				"tagIds": tagFilterArgs["tagIds"],
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching upcoming todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching upcoming todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				days, _ := p.Args["days"].(int)
+
+				todos, err := h.todoUsecase.GetUpcomingTodos(userId, time.Duration(days)*24*time.Hour)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty", "period must be positive":
+						h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+						h.Logger.PrintDuration("Fetching upcoming todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get upcoming todos: %v", err)
+						h.Logger.PrintDuration("Fetching upcoming todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				// タグによる絞り込み
+				todos, err = h.filterTodosByTags(userId, todos, p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to filter todos by tags: %v", err)
+					h.Logger.PrintDuration("Fetching upcoming todos", h.timer.GetDuration())
+					return nil, err
+				}
+
+				result := make([]map[string]interface{}, 0, len(todos))
+				for _, t := range todos {
+					result = append(result, toTodoMap(t))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d upcoming todos", len(result))
+				h.Logger.PrintDuration("Fetching upcoming todos", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+	}
+}
+
+// Todoのミューテーション
+func (h *GraphQLHandler) todoMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"createTodo": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"description": &graphql.ArgumentConfig{Type: graphql.String},
+				"completed":   &graphql.ArgumentConfig{Type: graphql.Boolean},
+				"dueAt":       &graphql.ArgumentConfig{Type: graphql.String},
+				"priority":    &graphql.ArgumentConfig{Type: todoPriorityEnum},
+				"remindAt":    &graphql.ArgumentConfig{Type: graphql.String},
+				"listId":      &graphql.ArgumentConfig{Type: graphql.String},
+				"parentId":    &graphql.ArgumentConfig{Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Creating todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				description := p.Args["description"].(string)
+				completed := p.Args["completed"].(bool)
+
+				todo := domain_todo.Todo{
+					Description: description,
+					Completed:   completed,
+					UserId:      userId,
+				}
+				err := applyScheduleArgs(&todo, p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Invalid schedule: %v", err)
+					h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+					return nil, err
+				}
+
+				// 追加先のリストは自分のアーカイブされていないリストのみ(省略時はインボックス)
+				if listId, ok := p.Args["listId"].(string); ok && listId != "" {
+					list, err := h.todoListUsecase.GetTodoList(userId, listId)
+					if err != nil {
+						h.Logger.ErrorLog.Printf("Todo list not accessible: %v", err)
+						h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+						return nil, err
+					}
+					if list.Archived {
+						h.Logger.ErrorLog.Println("todo list is archived")
+						h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+						return nil, errors.New("todo list is archived")
+					}
+					todo.ListId = &list.ID
+				}
+				// サブタスクとして作成する場合は親と同じリストに作成される
+				if parentId, ok := p.Args["parentId"].(string); ok && parentId != "" {
+					todo.ParentId = &parentId
+				}
+
+				createdTodo, err := h.todoUsecase.CreateTodo(todo, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "description is empty":
+						h.Logger.ErrorLog.Printf("Description is empty: %v", err)
+						return nil, err
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+						return nil, err
+					case "invalid priority":
+						h.Logger.ErrorLog.Printf("Invalid priority: %v", err)
+						h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+						return nil, err
+					case "parent_id is empty", "parent not found":
+						h.Logger.ErrorLog.Printf("Parent todo not found: %v", err)
+						h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+						return nil, err
+					case "parent would create a cycle", "max depth exceeded":
+						h.Logger.ErrorLog.Printf("Invalid parent todo: %v", err)
+						h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Parent todo not accessible: %v", err)
+						h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to create todo: %v", err)
+						h.Logger.PrintDuration("Creating todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				return toTodoMap(createdTodo), nil
+			},
+		},
+		"updateTodo": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"id":          &graphql.ArgumentConfig{Type: graphql.String},
+				"description": &graphql.ArgumentConfig{Type: graphql.String},
+				"completed":   &graphql.ArgumentConfig{Type: graphql.Boolean},
+				"dueAt":       &graphql.ArgumentConfig{Type: graphql.String},
+				"priority":    &graphql.ArgumentConfig{Type: todoPriorityEnum},
+				"remindAt":    &graphql.ArgumentConfig{Type: graphql.String},
+				"completeSubtasks": &graphql.ArgumentConfig{
+					Type:         graphql.Boolean,
+					DefaultValue: false,
+					Description:  "completedがtrueの場合、未完了のサブタスクも全て完了にする",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Updating todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				description := p.Args["description"].(string)
+				completed := p.Args["completed"].(bool)
+
+				h.Logger.InfoLog.Printf("Updating todo by id: %s", id)
+
+				// 指定されていない期限・優先度・リマインド日時は現在の値を引き継ぐ
+				current, err := h.todoUsecase.GetTodoById(id)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+					h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+					return nil, err
+				}
+				input := domain_todo.Todo{
+					ID:          id,
+					Description: description,
+					Completed:   completed,
+					UserId:      userId,
+					DueAt:       current.DueAt,
+					Priority:    current.Priority,
+					RemindAt:    current.RemindAt,
+				}
+				err = applyScheduleArgs(&input, p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Invalid schedule: %v", err)
+					h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+					return nil, err
+				}
+
+				var todo domain_todo.Todo
+				if completeSubtasks, _ := p.Args["completeSubtasks"].(bool); completeSubtasks && completed {
+					todo, err = h.todoUsecase.UpdateTodoAndCompleteSubtasks(input, auditActorFromContext(p.Context, userId))
+				} else {
+					todo, err = h.todoUsecase.UpdateTodo(input, auditActorFromContext(p.Context, userId))
+				}
+
+				if err != nil {
+					switch err.Error() {
+					case "id is empty":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+						return nil, err
+					case "description is empty":
+						h.Logger.ErrorLog.Printf("Description is empty: %v", err)
+						h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+						return nil, err
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+						return nil, err
+					case "invalid priority":
+						h.Logger.ErrorLog.Printf("Invalid priority: %v", err)
+						h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+						return nil, err
+					case "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get todo by id: %v", err)
+						h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				// 繰り返しのTodoを完了した場合は次の発生分を作成(更新自体は確定しているため、失敗はログのみ)
+				if !current.Completed && todo.Completed {
+					next, err := h.recurrenceUsecase.GenerateNextOccurrence(todo, auditActorFromContext(p.Context, userId))
+					if err != nil {
+						h.Logger.ErrorLog.Printf("Failed to generate next occurrence: %v", err)
+					} else if next != nil {
+						h.Logger.InfoLog.Printf("Generated next occurrence: %s", next.ID)
+					}
+				}
+
+				result := toTodoMap(todo)
+
+				h.Logger.InfoLog.Printf("Updated todo: %v", result != nil)
+				h.Logger.PrintDuration("Updating todo", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"deleteTodo": &graphql.Field{
+			Type: deleteTodoPayload,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.String}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Deleting todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Deleting todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				err := h.todoUsecase.DeleteTodo(id, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Deleting todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Deleting todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to delete todo: %v", err)
+						h.Logger.PrintDuration("Deleting todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Todo deleted successfully")
+				h.Logger.PrintDuration("Deleting todo", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success": true,
+					"message": "Todo deleted successfully",
+				}, nil
+			},
+		},
+		"bulkUpdateTodos": &graphql.Field{
+			Type: bulkTodoPayload,
+			Args: graphql.FieldConfigArgument{
+				"ids":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+				"completed": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Boolean)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Bulk updating todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Bulk updating todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				ids := stringListArg(p.Args, "ids")
+				completed := p.Args["completed"].(bool)
+				actor := auditActorFromContext(p.Context, userId)
+				results, err := h.todoUsecase.BulkUpdateTodos(ids, completed, actor)
+				if err != nil {
+					switch err.Error() {
+					case "ids is empty", "too many ids":
+						h.Logger.ErrorLog.Printf("Invalid ids: %v", err)
+						h.Logger.PrintDuration("Bulk updating todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to bulk update todos: %v", err)
+						h.Logger.PrintDuration("Bulk updating todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				// 繰り返しのTodoを完了した場合は次の発生分を作成(更新自体は確定しているため、失敗はログのみ)
+				for _, r := range results {
+					if !r.Changed || r.Todo == nil || !r.Todo.Completed {
+						continue
+					}
+					next, err := h.recurrenceUsecase.GenerateNextOccurrence(*r.Todo, actor)
+					if err != nil {
+						h.Logger.ErrorLog.Printf("Failed to generate next occurrence: %v", err)
+					} else if next != nil {
+						h.Logger.InfoLog.Printf("Generated next occurrence: %s", next.ID)
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Bulk updated %d todos", len(results))
+				h.Logger.PrintDuration("Bulk updating todos", h.timer.GetDuration())
+				return toBulkTodoPayload(results), nil
+			},
+		},
+		"bulkDeleteTodos": &graphql.Field{
+			Type: bulkTodoPayload,
+			Args: graphql.FieldConfigArgument{
+				"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Bulk deleting todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Bulk deleting todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				ids := stringListArg(p.Args, "ids")
+				results, err := h.todoUsecase.BulkDeleteTodos(ids, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "ids is empty", "too many ids":
+						h.Logger.ErrorLog.Printf("Invalid ids: %v", err)
+						h.Logger.PrintDuration("Bulk deleting todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to bulk delete todos: %v", err)
+						h.Logger.PrintDuration("Bulk deleting todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Bulk deleted %d todos", len(results))
+				h.Logger.PrintDuration("Bulk deleting todos", h.timer.GetDuration())
+				return toBulkTodoPayload(results), nil
+			},
+		},
+		"clearCompleted": &graphql.Field{
+			Type: bulkTodoPayload,
+			Args: graphql.FieldConfigArgument{
+				"listId": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "指定したリストの完了済みのTodoのみ削除する(未指定の場合は全てのリスト)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Clearing completed todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Clearing completed todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				var listId *string
+				if v, ok := p.Args["listId"].(string); ok && v != "" {
+					listId = &v
+				}
+				results, err := h.todoUsecase.ClearCompleted(userId, listId, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to clear completed todos: %v", err)
+					h.Logger.PrintDuration("Clearing completed todos", h.timer.GetDuration())
+					return nil, err
+				}
+
+				h.Logger.InfoLog.Printf("Cleared %d completed todos", len(results))
+				h.Logger.PrintDuration("Clearing completed todos", h.timer.GetDuration())
+				return toBulkTodoPayload(results), nil
+			},
+		},
+		"moveTodo": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"before": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "移動先の直前に並ぶTodoのID(省略した場合はafterの直前に移動する)",
+				},
+				"after": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "移動先の直後に並ぶTodoのID(省略した場合はbeforeの直後に移動する、両方省略した場合は末尾)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Moving todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Moving todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				var beforeId, afterId *string
+				if v, ok := p.Args["before"].(string); ok && v != "" {
+					beforeId = &v
+				}
+				if v, ok := p.Args["after"].(string); ok && v != "" {
+					afterId = &v
+				}
+				todo, err := h.todoUsecase.MoveTodo(userId, id, beforeId, afterId, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Moving todo", h.timer.GetDuration())
+						return nil, err
+					case "invalid position":
+						h.Logger.ErrorLog.Printf("Invalid position: %v", err)
+						h.Logger.PrintDuration("Moving todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Moving todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to move todo: %v", err)
+						h.Logger.PrintDuration("Moving todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Moved todo: %s", todo.ID)
+				h.Logger.PrintDuration("Moving todo", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+	}
+}
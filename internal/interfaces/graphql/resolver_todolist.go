@@ -0,0 +1,356 @@
+package interfaces_graphql
+
+import (
+	domain_todolist "backend/internal/domain/todolist"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Todoリストのクエリ
+func (h *GraphQLHandler) todoListQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"todoLists": &graphql.Field{
+			Type: graphql.NewList(todoListType),
+			Args: graphql.FieldConfigArgument{
+				"includeArchived": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching todo lists...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching todo lists", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				includeArchived, _ := p.Args["includeArchived"].(bool)
+				lists, err := h.todoListUsecase.GetTodoListsByUserId(userId, includeArchived)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching todo lists", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get todo lists: %v", err)
+						h.Logger.PrintDuration("Fetching todo lists", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(lists))
+				for _, l := range lists {
+					result = append(result, toTodoListMap(l))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d todo lists", len(result))
+				h.Logger.PrintDuration("Fetching todo lists", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"todoList": &graphql.Field{
+			Type: todoListType,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching todo list...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching todo list", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				list, err := h.todoListUsecase.GetTodoList(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "list_id is empty", "todo list not found":
+						h.Logger.ErrorLog.Printf("Todo list not found: %v", err)
+						h.Logger.PrintDuration("Fetching todo list", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo list not accessible: %v", err)
+						h.Logger.PrintDuration("Fetching todo list", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get todo list: %v", err)
+						h.Logger.PrintDuration("Fetching todo list", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Fetched todo list: %s", list.ID)
+				h.Logger.PrintDuration("Fetching todo list", h.timer.GetDuration())
+				return toTodoListMap(list), nil
+			},
+		},
+		"inboxTodos": &graphql.Field{
+			Type: graphql.NewList(todoType),
+			Args: tagFilterArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching inbox todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching inbox todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todos, err := h.todoListUsecase.GetInboxTodos(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching inbox todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get inbox todos: %v", err)
+						h.Logger.PrintDuration("Fetching inbox todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				todos, err = h.filterTodosByTags(userId, todos, p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to filter todos by tags: %v", err)
+					h.Logger.PrintDuration("Fetching inbox todos", h.timer.GetDuration())
+					return nil, err
+				}
+
+				result := make([]map[string]interface{}, 0, len(todos))
+				for _, t := range todos {
+					result = append(result, toTodoMap(t))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d inbox todos", len(result))
+				h.Logger.PrintDuration("Fetching inbox todos", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+	}
+}
+
+// Todoリストのミューテーション
+func (h *GraphQLHandler) todoListMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"createTodoList": &graphql.Field{
+			Type: todoListType,
+			Args: graphql.FieldConfigArgument{
+				"name":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"color": &graphql.ArgumentConfig{Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Creating todo list...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Creating todo list", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				name := p.Args["name"].(string)
+				color, _ := p.Args["color"].(string)
+				list, err := h.todoListUsecase.CreateTodoList(userId, name, color)
+				if err != nil {
+					switch err.Error() {
+					case "name is empty", "name is too long", "invalid color":
+						h.Logger.ErrorLog.Printf("Invalid todo list: %v", err)
+						h.Logger.PrintDuration("Creating todo list", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to create todo list: %v", err)
+						h.Logger.PrintDuration("Creating todo list", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Created todo list: %s", list.ID)
+				h.Logger.PrintDuration("Creating todo list", h.timer.GetDuration())
+				return toTodoListMap(list), nil
+			},
+		},
+		"updateTodoList": &graphql.Field{
+			Type: todoListType,
+			Args: graphql.FieldConfigArgument{
+				"id":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"name":     &graphql.ArgumentConfig{Type: graphql.String},
+				"color":    &graphql.ArgumentConfig{Type: graphql.String},
+				"archived": &graphql.ArgumentConfig{Type: graphql.Boolean},
+				"position": &graphql.ArgumentConfig{Type: graphql.Int},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Updating todo list...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Updating todo list", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				// 指定されていない項目は現在の値を引き継ぐ
+				id := p.Args["id"].(string)
+				list, err := h.todoListUsecase.GetTodoList(userId, id)
+				if err == nil {
+					if v, ok := p.Args["name"].(string); ok {
+						list.Name = v
+					}
+					if v, ok := p.Args["color"].(string); ok {
+						list.Color = v
+					}
+					if v, ok := p.Args["archived"].(bool); ok {
+						list.Archived = v
+					}
+					if v, ok := p.Args["position"].(int); ok {
+						list.Position = v
+					}
+					list, err = h.todoListUsecase.UpdateTodoList(userId, list)
+				}
+				if err != nil {
+					switch err.Error() {
+					case "name is empty", "name is too long", "invalid color", "position must not be negative":
+						h.Logger.ErrorLog.Printf("Invalid todo list: %v", err)
+						h.Logger.PrintDuration("Updating todo list", h.timer.GetDuration())
+						return nil, err
+					case "list_id is empty", "todo list not found":
+						h.Logger.ErrorLog.Printf("Todo list not found: %v", err)
+						h.Logger.PrintDuration("Updating todo list", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo list not accessible: %v", err)
+						h.Logger.PrintDuration("Updating todo list", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to update todo list: %v", err)
+						h.Logger.PrintDuration("Updating todo list", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Updated todo list: %s", list.ID)
+				h.Logger.PrintDuration("Updating todo list", h.timer.GetDuration())
+				return toTodoListMap(list), nil
+			},
+		},
+		"deleteTodoList": &graphql.Field{
+			Type: deleteTodoListPayload,
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"mode": &graphql.ArgumentConfig{
+					Type:         todoListDeleteModeEnum,
+					DefaultValue: domain_todolist.TodoListDeleteModeMoveToInbox,
+					Description:  "リスト内のTodoの扱い(CASCADE: ゴミ箱に移動, MOVE_TO_INBOX: インボックスに移動)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Deleting todo list...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Deleting todo list", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				mode, _ := p.Args["mode"].(string)
+				affected, err := h.todoListUsecase.DeleteTodoList(userId, id, mode, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "invalid delete mode":
+						h.Logger.ErrorLog.Printf("Invalid delete mode: %v", err)
+						h.Logger.PrintDuration("Deleting todo list", h.timer.GetDuration())
+						return nil, err
+					case "list_id is empty", "todo list not found":
+						h.Logger.ErrorLog.Printf("Todo list not found: %v", err)
+						h.Logger.PrintDuration("Deleting todo list", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo list not accessible: %v", err)
+						h.Logger.PrintDuration("Deleting todo list", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to delete todo list: %v", err)
+						h.Logger.PrintDuration("Deleting todo list", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Todo list deleted successfully")
+				h.Logger.PrintDuration("Deleting todo list", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success":       true,
+					"message":       "Todo list deleted successfully",
+					"affectedTodos": affected,
+				}, nil
+			},
+		},
+		"moveTodoToList": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"listId": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "移動先のTodoリストID(省略またはnullの場合はインボックス)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Moving todo to list...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Moving todo to list", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todoId := p.Args["todoId"].(string)
+				var listId *string
+				if v, ok := p.Args["listId"].(string); ok && v != "" {
+					listId = &v
+				}
+				todo, err := h.todoListUsecase.MoveTodoToList(userId, todoId, listId, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "todo_id is empty", "todo not found":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Moving todo to list", h.timer.GetDuration())
+						return nil, err
+					case "list_id is empty", "todo list not found":
+						h.Logger.ErrorLog.Printf("Todo list not found: %v", err)
+						h.Logger.PrintDuration("Moving todo to list", h.timer.GetDuration())
+						return nil, err
+					case "todo list is archived":
+						h.Logger.ErrorLog.Printf("Todo list is archived: %v", err)
+						h.Logger.PrintDuration("Moving todo to list", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo or todo list not accessible: %v", err)
+						h.Logger.PrintDuration("Moving todo to list", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to move todo: %v", err)
+						h.Logger.PrintDuration("Moving todo to list", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Moved todo: %s", todo.ID)
+				h.Logger.PrintDuration("Moving todo to list", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+	}
+}
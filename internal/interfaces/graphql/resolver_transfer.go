@@ -0,0 +1,60 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// インポートのミューテーション
+func (h *GraphQLHandler) transferMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"importTodos": &graphql.Field{
+			Type: todoImportPayloadType,
+			Args: graphql.FieldConfigArgument{
+				"format": &graphql.ArgumentConfig{Type: graphql.NewNonNull(todoTransferFormatEnum)},
+				"data": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "インポートするデータ(エクスポートと同じ形式)",
+				},
+				"dryRun": &graphql.ArgumentConfig{
+					Type:         graphql.Boolean,
+					DefaultValue: false,
+					Description:  "trueの場合は検証のみ行い、インポートしない",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Importing todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Importing todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				format := p.Args["format"].(string)
+				data := p.Args["data"].(string)
+				dryRun, _ := p.Args["dryRun"].(bool)
+				report, err := h.transferUsecase.ImportTodos(userId, format, data, dryRun, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "invalid format", "data is empty", "data is too large", "invalid import data", "too many rows":
+						h.Logger.ErrorLog.Printf("Invalid import data: %v", err)
+						h.Logger.PrintDuration("Importing todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to import todos: %v", err)
+						h.Logger.PrintDuration("Importing todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Imported %d of %d todos (errors: %d)", report.Imported, report.Total, len(report.Errors))
+				h.Logger.PrintDuration("Importing todos", h.timer.GetDuration())
+				return toTodoImportPayload(report), nil
+			},
+		},
+	}
+}
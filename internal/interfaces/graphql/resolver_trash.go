@@ -0,0 +1,135 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ゴミ箱のクエリ
+func (h *GraphQLHandler) trashQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"trashedTodos": &graphql.Field{
+			Type: graphql.NewList(todoType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching trashed todos...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching trashed todos", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				todos, err := h.todoUsecase.GetTrashedTodosByUserId(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching trashed todos", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get trashed todos: %v", err)
+						h.Logger.PrintDuration("Fetching trashed todos", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(todos))
+				for _, t := range todos {
+					result = append(result, toTodoMap(t))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d trashed todos", len(result))
+				h.Logger.PrintDuration("Fetching trashed todos", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+	}
+}
+
+// ゴミ箱のミューテーション
+func (h *GraphQLHandler) trashMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"restoreTodo": &graphql.Field{
+			Type: todoType,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Restoring todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Restoring todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				todo, err := h.todoUsecase.RestoreTodo(id, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "id is empty", "todo not found in trash":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Restoring todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Restoring todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to restore todo: %v", err)
+						h.Logger.PrintDuration("Restoring todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Restored todo: %s", todo.ID)
+				h.Logger.PrintDuration("Restoring todo", h.timer.GetDuration())
+				return toTodoMap(todo), nil
+			},
+		},
+		"purgeTodo": &graphql.Field{
+			Type: purgeTodoPayload,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Purging todo...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Purging todo", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				err := h.todoUsecase.PurgeTodo(id, auditActorFromContext(p.Context, userId))
+				if err != nil {
+					switch err.Error() {
+					case "id is empty", "todo not found in trash":
+						h.Logger.ErrorLog.Printf("Todo not found: %v", err)
+						h.Logger.PrintDuration("Purging todo", h.timer.GetDuration())
+						return nil, err
+					case "forbidden":
+						h.Logger.ErrorLog.Printf("Todo not accessible: %v", err)
+						h.Logger.PrintDuration("Purging todo", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to purge todo: %v", err)
+						h.Logger.PrintDuration("Purging todo", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Println("Todo purged successfully")
+				h.Logger.PrintDuration("Purging todo", h.timer.GetDuration())
+				return map[string]interface{}{
+					"success": true,
+					"message": "Todo purged successfully",
+				}, nil
+			},
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package interfaces_graphql
+
+import (
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ユーザーのクエリ
+func (h *GraphQLHandler) userQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"users": &graphql.Field{
+			Type: graphql.NewList(userType),
+			Args: graphql.FieldConfigArgument{"limit": listLimitArg},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching users...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching users", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+				limit, err := listLimit(p.Args)
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+					h.Logger.PrintDuration("Fetching users", h.timer.GetDuration())
+					return nil, err
+				}
+
+				users, err := h.userUsecase.GetAllUsers()
+				if err != nil {
+					h.Logger.ErrorLog.Printf("Failed to get all users: %v", err)
+					h.Logger.PrintDuration("Fetching users", h.timer.GetDuration())
+					return nil, err
+				}
+				users = truncateList(users, limit)
+
+				result := make([]map[string]interface{}, 0, len(users))
+				for _, u := range users {
+					result = append(result, toUserMap(u))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d users", len(result))
+				h.Logger.PrintDuration("Fetching users", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+	}
+}
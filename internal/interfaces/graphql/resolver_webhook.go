@@ -0,0 +1,337 @@
+package interfaces_graphql
+
+import (
+	domain_webhook "backend/internal/domain/webhook"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Webhookのクエリ
+func (h *GraphQLHandler) webhookQueryFields() graphql.Fields {
+	return graphql.Fields{
+		"webhooks": &graphql.Field{
+			Type: graphql.NewList(webhookType),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching webhooks...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching webhooks", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				webhooks, err := h.webhookUsecase.GetWebhooks(userId)
+				if err != nil {
+					switch err.Error() {
+					case "user_id is empty":
+						h.Logger.ErrorLog.Printf("User id is empty: %v", err)
+						h.Logger.PrintDuration("Fetching webhooks", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get webhooks: %v", err)
+						h.Logger.PrintDuration("Fetching webhooks", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(webhooks))
+				for _, w := range webhooks {
+					result = append(result, toWebhookMap(w))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d webhooks", len(result))
+				h.Logger.PrintDuration("Fetching webhooks", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"webhookDeliveries": &graphql.Field{
+			Type: graphql.NewList(webhookDeliveryType),
+			Args: graphql.FieldConfigArgument{
+				"webhookId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"status": &graphql.ArgumentConfig{
+					Type:        webhookDeliveryStatusEnum,
+					Description: "配信の状態で絞り込む(省略した場合は全て)",
+				},
+				"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+				"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching webhook deliveries...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching webhook deliveries", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				webhookId := p.Args["webhookId"].(string)
+				status, _ := p.Args["status"].(string)
+				limit, _ := p.Args["limit"].(int)
+				offset, _ := p.Args["offset"].(int)
+
+				deliveries, err := h.webhookUsecase.GetDeliveries(userId, webhookId, status, limit, offset)
+				if err != nil {
+					switch err.Error() {
+					case "invalid status", "offset must not be negative":
+						h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+						h.Logger.PrintDuration("Fetching webhook deliveries", h.timer.GetDuration())
+						return nil, err
+					case "webhook_id is empty", "webhook not found", "forbidden":
+						h.Logger.ErrorLog.Printf("Webhook not accessible: %v", err)
+						h.Logger.PrintDuration("Fetching webhook deliveries", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get webhook deliveries: %v", err)
+						h.Logger.PrintDuration("Fetching webhook deliveries", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(deliveries))
+				for _, d := range deliveries {
+					result = append(result, toWebhookDeliveryMap(d))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d webhook deliveries", len(result))
+				h.Logger.PrintDuration("Fetching webhook deliveries", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+		"webhookDeliveryAttempts": &graphql.Field{
+			Type: graphql.NewList(webhookDeliveryAttemptType),
+			Args: graphql.FieldConfigArgument{
+				"deliveryId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Fetching webhook delivery attempts...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Fetching webhook delivery attempts", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				deliveryId := p.Args["deliveryId"].(string)
+				attempts, err := h.webhookUsecase.GetDeliveryAttempts(userId, deliveryId)
+				if err != nil {
+					switch err.Error() {
+					case "delivery_id is empty", "delivery not found", "webhook not found", "forbidden":
+						h.Logger.ErrorLog.Printf("Webhook delivery not accessible: %v", err)
+						h.Logger.PrintDuration("Fetching webhook delivery attempts", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to get webhook delivery attempts: %v", err)
+						h.Logger.PrintDuration("Fetching webhook delivery attempts", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				result := make([]map[string]interface{}, 0, len(attempts))
+				for _, a := range attempts {
+					result = append(result, toWebhookDeliveryAttemptMap(a))
+				}
+
+				h.Logger.InfoLog.Printf("Fetched %d webhook delivery attempts", len(result))
+				h.Logger.PrintDuration("Fetching webhook delivery attempts", h.timer.GetDuration())
+				return result, nil
+			},
+		},
+	}
+}
+
+// Webhookのミューテーション
+func (h *GraphQLHandler) webhookMutationFields() graphql.Fields {
+	return graphql.Fields{
+		"createWebhook": &graphql.Field{
+			Type: webhookType,
+			Args: graphql.FieldConfigArgument{
+				"url": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "送信先のURL(http/https)",
+				},
+				"eventTypes": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(webhookEventTypeEnum)))},
+				"secret": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "署名のシークレット(16〜256文字、作成後は取得できない)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Creating webhook...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Creating webhook", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				url := p.Args["url"].(string)
+				eventTypes := stringListArg(p.Args, "eventTypes")
+				secret := p.Args["secret"].(string)
+				webhook, err := h.webhookUsecase.CreateWebhook(userId, url, eventTypes, secret)
+				if err != nil {
+					switch err.Error() {
+					case "url is empty", "url is too long", "invalid url", "event_types is empty", "invalid event type", "secret is too short", "secret is too long", "too many webhooks":
+						h.Logger.ErrorLog.Printf("Invalid webhook: %v", err)
+						h.Logger.PrintDuration("Creating webhook", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to create webhook: %v", err)
+						h.Logger.PrintDuration("Creating webhook", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Created webhook: %s", webhook.ID)
+				h.Logger.PrintDuration("Creating webhook", h.timer.GetDuration())
+				return toWebhookMap(webhook), nil
+			},
+		},
+		"updateWebhook": &graphql.Field{
+			Type: webhookType,
+			Args: graphql.FieldConfigArgument{
+				"id":         &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"url":        &graphql.ArgumentConfig{Type: graphql.String},
+				"eventTypes": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(webhookEventTypeEnum))},
+				"secret":     &graphql.ArgumentConfig{Type: graphql.String},
+				"active": &graphql.ArgumentConfig{
+					Type:        graphql.Boolean,
+					Description: "falseの間は配信を登録・送信しない(送信待ちの配信は有効に戻すと送信する)",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Updating webhook...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Updating webhook", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				// 指定されていない項目は現在の値を引き継ぐ
+				id := p.Args["id"].(string)
+				var update domain_webhook.WebhookUpdate
+				if v, ok := p.Args["url"].(string); ok {
+					update.URL = &v
+				}
+				if _, ok := p.Args["eventTypes"]; ok {
+					update.EventTypes = stringListArg(p.Args, "eventTypes")
+				}
+				if v, ok := p.Args["secret"].(string); ok {
+					update.Secret = &v
+				}
+				if v, ok := p.Args["active"].(bool); ok {
+					update.Active = &v
+				}
+				webhook, err := h.webhookUsecase.UpdateWebhook(userId, id, update)
+				if err != nil {
+					switch err.Error() {
+					case "url is empty", "url is too long", "invalid url", "event_types is empty", "invalid event type", "secret is too short", "secret is too long":
+						h.Logger.ErrorLog.Printf("Invalid webhook: %v", err)
+						h.Logger.PrintDuration("Updating webhook", h.timer.GetDuration())
+						return nil, err
+					case "webhook_id is empty", "webhook not found", "forbidden":
+						h.Logger.ErrorLog.Printf("Webhook not accessible: %v", err)
+						h.Logger.PrintDuration("Updating webhook", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to update webhook: %v", err)
+						h.Logger.PrintDuration("Updating webhook", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Updated webhook: %s", webhook.ID)
+				h.Logger.PrintDuration("Updating webhook", h.timer.GetDuration())
+				return toWebhookMap(webhook), nil
+			},
+		},
+		"deleteWebhook": &graphql.Field{
+			Type: graphql.Boolean,
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Deleting webhook...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Deleting webhook", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				err := h.webhookUsecase.DeleteWebhook(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "webhook_id is empty", "webhook not found", "forbidden":
+						h.Logger.ErrorLog.Printf("Webhook not accessible: %v", err)
+						h.Logger.PrintDuration("Deleting webhook", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to delete webhook: %v", err)
+						h.Logger.PrintDuration("Deleting webhook", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Deleted webhook: %s", id)
+				h.Logger.PrintDuration("Deleting webhook", h.timer.GetDuration())
+				return true, nil
+			},
+		},
+		"redeliverWebhookDelivery": &graphql.Field{
+			Type:        webhookDeliveryType,
+			Description: "配信を手動で再送する(同じ本文の配信を新しく登録し、次回のジョブで送信する)",
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				h.Logger.InfoLog.Println("Redelivering webhook delivery...")
+				h.timer.Start()
+
+				userId, ok := p.Context.Value(h.authHandler.AppConfig.UserID).(string)
+				if !ok || userId == "" {
+					h.Logger.ErrorLog.Println("unauthorized")
+					h.Logger.PrintDuration("Redelivering webhook delivery", h.timer.GetDuration())
+					return nil, errors.New("unauthorized")
+				}
+
+				id := p.Args["id"].(string)
+				delivery, err := h.webhookUsecase.RedeliverDelivery(userId, id)
+				if err != nil {
+					switch err.Error() {
+					case "delivery is pending":
+						h.Logger.ErrorLog.Printf("Invalid request: %v", err)
+						h.Logger.PrintDuration("Redelivering webhook delivery", h.timer.GetDuration())
+						return nil, err
+					case "delivery_id is empty", "delivery not found", "webhook not found", "forbidden":
+						h.Logger.ErrorLog.Printf("Webhook delivery not accessible: %v", err)
+						h.Logger.PrintDuration("Redelivering webhook delivery", h.timer.GetDuration())
+						return nil, err
+					default:
+						h.Logger.ErrorLog.Printf("Failed to redeliver webhook delivery: %v", err)
+						h.Logger.PrintDuration("Redelivering webhook delivery", h.timer.GetDuration())
+						return nil, err
+					}
+				}
+
+				h.Logger.InfoLog.Printf("Redelivered webhook delivery: %s", delivery.ID)
+				h.Logger.PrintDuration("Redelivering webhook delivery", h.timer.GetDuration())
+				return toWebhookDeliveryMap(delivery), nil
+			},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package interfaces_graphql
+
+import (
+	domain_attachment "backend/internal/domain/attachment"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 添付ファイル型
+var attachmentType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Attachment",
+	Fields: graphql.Fields{
+		"id":          &graphql.Field{Type: graphql.String},
+		"todoId":      &graphql.Field{Type: graphql.String},
+		"fileName":    &graphql.Field{Type: graphql.String},
+		"contentType": &graphql.Field{Type: graphql.String},
+		"size":        &graphql.Field{Type: graphql.Int},
+		"downloadUrl": &graphql.Field{Type: graphql.String, Description: "ダウンロードのURLのパス(Authorizationヘッダーを付けて取得する)"},
+		"createdAt":   &graphql.Field{Type: graphql.String},
+	},
+})
+
+// RemoveAttachmentPayload型
+var removeAttachmentPayload = graphql.NewObject(graphql.ObjectConfig{
+	Name: "RemoveAttachmentPayload",
+	Fields: graphql.Fields{
+		"success": &graphql.Field{Type: graphql.Boolean},
+		"message": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// Todo型に添付ファイルのフィールドを追加
+func init() {
+	todoType.AddFieldConfig("attachments", &graphql.Field{
+		Type:    graphql.NewList(attachmentType),
+		Resolve: resolveTodoAttachments,
+	})
+}
+
+// 添付ファイルをGraphQLのレスポンス形式に変換
+func toAttachmentMap(a domain_attachment.Attachment) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          a.ID,
+		"todoId":      a.TodoId,
+		"fileName":    a.FileName,
+		"contentType": a.ContentType,
+		"size":        a.Size,
+		"downloadUrl": "/attachments/" + a.ID,
+		"createdAt":   a.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// Todoの添付ファイルを取得(DataLoader経由)
+// 所有者以外は、閲覧権限のある削除されていないTodoのみ取得できる。
+func resolveTodoAttachments(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := newTodoViewerKey(todo, loaders.ViewerId)
+	if !ok {
+		return []map[string]interface{}{}, nil
+	}
+
+	thunk := loaders.AttachmentsByTodoID.Load(key)
+	return func() (interface{}, error) {
+		attachments, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(attachments))
+		for _, a := range attachments {
+			result = append(result, toAttachmentMap(a))
+		}
+		return result, nil
+	}, nil
+}
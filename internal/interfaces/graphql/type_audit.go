@@ -0,0 +1,76 @@
+package interfaces_graphql
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	interfaces_auth "backend/internal/interfaces/auth"
+	"context"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 監査ログ型
+var auditLogType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "AuditLog",
+	Fields: graphql.Fields{
+		"id":         &graphql.Field{Type: graphql.String},
+		"actorId":    &graphql.Field{Type: graphql.String},
+		"action":     &graphql.Field{Type: graphql.String},
+		"entityType": &graphql.Field{Type: graphql.String},
+		"entityId":   &graphql.Field{Type: graphql.String},
+		"before":     &graphql.Field{Type: graphql.String, Description: "変更前の値(JSON文字列)"},
+		"after":      &graphql.Field{Type: graphql.String, Description: "変更後の値(JSON文字列)"},
+		"requestId":  &graphql.Field{Type: graphql.String},
+		"ipAddress":  &graphql.Field{Type: graphql.String},
+		"createdAt":  &graphql.Field{Type: graphql.String},
+	},
+})
+
+// 監査ログの一覧型(ページネーション)
+var auditLogPageType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "AuditLogPage",
+	Fields: graphql.Fields{
+		"items":      &graphql.Field{Type: graphql.NewList(auditLogType)},
+		"totalCount": &graphql.Field{Type: graphql.Int},
+		"offset":     &graphql.Field{Type: graphql.Int},
+		"hasNext":    &graphql.Field{Type: graphql.Boolean},
+	},
+})
+
+// 監査ログをGraphQLのレスポンス形式に変換
+func toAuditLogMap(a domain_audit.AuditLog) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":         a.ID,
+		"actorId":    a.ActorId,
+		"action":     a.Action,
+		"entityType": a.EntityType,
+		"entityId":   a.EntityId,
+		"before":     nil,
+		"after":      nil,
+		"requestId":  a.RequestId,
+		"ipAddress":  a.IPAddress,
+		"createdAt":  a.CreatedAt.Format(time.RFC3339),
+	}
+	if len(a.Before) > 0 {
+		result["before"] = string(a.Before)
+	}
+	if len(a.After) > 0 {
+		result["after"] = string(a.After)
+	}
+	return result
+}
+
+// 監査ログに記録する操作者の情報をコンテキストから取得
+func auditActorFromContext(ctx context.Context, userId string) domain_audit.AuditActor {
+	return domain_audit.AuditActor{
+		UserId:    userId,
+		RequestId: interfaces_auth.RequestIDFromContext(ctx),
+		IPAddress: interfaces_auth.ClientIPFromContext(ctx),
+	}
+}
+
+// RFC3339形式の日時引数を解析(未指定の場合はnil)
+func parseTimeArg(args map[string]interface{}, name string) (*time.Time, error) {
+	v, _ := args[name].(string)
+	return parseOptionalTime(v)
+}
@@ -9,3 +9,12 @@ var loginPayload = graphql.NewObject(graphql.ObjectConfig{
 		"token": &graphql.Field{Type: graphql.String},
 	},
 })
+
+// UnlockAccountPayload型
+var unlockAccountPayload = graphql.NewObject(graphql.ObjectConfig{
+	Name: "UnlockAccountPayload",
+	Fields: graphql.Fields{
+		"success": &graphql.Field{Type: graphql.Boolean},
+		"message": &graphql.Field{Type: graphql.String},
+	},
+})
@@ -0,0 +1,58 @@
+package interfaces_graphql
+
+import (
+	domain_calendar "backend/internal/domain/calendar"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// カレンダーの購読フィード型
+var calendarFeedType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "CalendarFeed",
+	Fields: graphql.Fields{
+		"enabled":        &graphql.Field{Type: graphql.Boolean, Description: "フィードのURLを発行しているかどうか"},
+		"createdAt":      &graphql.Field{Type: graphql.String, Description: "URLを発行した日時"},
+		"lastAccessedAt": &graphql.Field{Type: graphql.String, Description: "最後にフィードが取得された日時"},
+	},
+})
+
+// 発行したフィードのURL型(URLとトークンは発行時のみ取得できる)
+var calendarFeedTokenType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "CalendarFeedToken",
+	Fields: graphql.Fields{
+		"url":       &graphql.Field{Type: graphql.String, Description: "カレンダーアプリに登録するURL(?type=todo|bothで種類を指定)"},
+		"token":     &graphql.Field{Type: graphql.String},
+		"createdAt": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// フィードをGraphQLのレスポンス形式に変換(発行していない場合はnil)
+func toCalendarFeedMap(feed *domain_calendar.CalendarFeed) map[string]interface{} {
+	if feed == nil {
+		return map[string]interface{}{
+			"enabled":        false,
+			"createdAt":      nil,
+			"lastAccessedAt": nil,
+		}
+	}
+	var lastAccessedAt *string
+	if feed.LastAccessedAt != nil {
+		v := feed.LastAccessedAt.Format(time.RFC3339)
+		lastAccessedAt = &v
+	}
+	return map[string]interface{}{
+		"enabled":        true,
+		"createdAt":      feed.CreatedAt.Format(time.RFC3339),
+		"lastAccessedAt": lastAccessedAt,
+	}
+}
+
+// 発行したフィードのURLをGraphQLのレスポンス形式に変換
+func toCalendarFeedTokenMap(token domain_calendar.CalendarFeedToken) map[string]interface{} {
+	return map[string]interface{}{
+		"url":       token.URL,
+		"token":     token.Token,
+		"createdAt": token.Feed.CreatedAt.Format(time.RFC3339),
+	}
+}
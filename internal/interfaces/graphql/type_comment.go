@@ -0,0 +1,155 @@
+package interfaces_graphql
+
+import (
+	domain_comment "backend/internal/domain/comment"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// コメント型
+var commentType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Comment",
+	Fields: graphql.Fields{
+		"id":        &graphql.Field{Type: graphql.String},
+		"todoId":    &graphql.Field{Type: graphql.String},
+		"authorId":  &graphql.Field{Type: graphql.String},
+		"body":      &graphql.Field{Type: graphql.String},
+		"createdAt": &graphql.Field{Type: graphql.String},
+		"editedAt":  &graphql.Field{Type: graphql.String, Description: "最終編集日時(未編集の場合はnull)"},
+		"author": &graphql.Field{
+			Type:    userType,
+			Resolve: resolveCommentAuthor,
+		},
+	},
+})
+
+// コメントの一覧型(ページネーション)
+var commentPageType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "CommentPage",
+	Fields: graphql.Fields{
+		"items":      &graphql.Field{Type: graphql.NewList(commentType)},
+		"totalCount": &graphql.Field{Type: graphql.Int},
+		"offset":     &graphql.Field{Type: graphql.Int},
+		"hasNext":    &graphql.Field{Type: graphql.Boolean},
+	},
+})
+
+// DeleteCommentPayload型
+var deleteCommentPayload = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DeleteCommentPayload",
+	Fields: graphql.Fields{
+		"success": &graphql.Field{Type: graphql.Boolean},
+		"message": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// コメントの一覧を取得するDataLoaderのキー
+// 同じリクエスト内でもTodoごとに取得範囲が異なる場合があるため、範囲もキーに含める。
+// ViewerIdは所有者以外が取得する場合のみ設定し、DataLoaderで閲覧権限を確認する。
+type commentPageKey struct {
+	TodoId   string
+	ViewerId string
+	Limit    int
+	Offset   int
+}
+
+// Todo型にコメントのフィールドを追加
+func init() {
+	todoType.AddFieldConfig("comments", &graphql.Field{
+		Type: commentPageType,
+		Args: graphql.FieldConfigArgument{
+			"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+			"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		},
+		Resolve: resolveTodoComments,
+	})
+}
+
+// コメントをGraphQLのレスポンス形式に変換
+func toCommentMap(c domain_comment.Comment) map[string]interface{} {
+	var editedAt *string
+	if c.EditedAt != nil {
+		v := c.EditedAt.Format(time.RFC3339)
+		editedAt = &v
+	}
+	return map[string]interface{}{
+		"id":        c.ID,
+		"todoId":    c.TodoId,
+		"authorId":  c.AuthorId,
+		"body":      c.Body,
+		"createdAt": c.CreatedAt.Format(time.RFC3339),
+		"editedAt":  editedAt,
+	}
+}
+
+// Todoのコメントを取得(DataLoader経由)
+// 所有者以外は、閲覧権限のある削除されていないTodoのみ取得できる。
+func resolveTodoComments(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	viewerKey, ok := newTodoViewerKey(todo, loaders.ViewerId)
+	if !ok {
+		return nil, nil
+	}
+
+	key := commentPageKey{TodoId: viewerKey.TodoId, ViewerId: viewerKey.ViewerId}
+	key.Limit, _ = p.Args["limit"].(int)
+	key.Offset, _ = p.Args["offset"].(int)
+	thunk := loaders.CommentsByTodoID.Load(key)
+	return func() (interface{}, error) {
+		page, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			return nil, nil
+		}
+		items := make([]map[string]interface{}, 0, len(page.Items))
+		for _, c := range page.Items {
+			items = append(items, toCommentMap(c))
+		}
+		return map[string]interface{}{
+			"items":      items,
+			"totalCount": page.TotalCount,
+			"offset":     key.Offset,
+			"hasNext":    key.Offset+len(items) < page.TotalCount,
+		}, nil
+	}, nil
+}
+
+// コメントの投稿者を取得(DataLoader経由)
+func resolveCommentAuthor(p graphql.ResolveParams) (interface{}, error) {
+	comment, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	authorId, _ := comment["authorId"].(string)
+	if authorId == "" {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.UserByID.Load(authorId)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if user.ID == "" {
+			return nil, nil
+		}
+		return toUserMap(user), nil
+	}, nil
+}
@@ -0,0 +1,176 @@
+package interfaces_graphql
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 変更された項目型
+var fieldChangeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "FieldChange",
+	Fields: graphql.Fields{
+		"field":  &graphql.Field{Type: graphql.String},
+		"before": &graphql.Field{Type: graphql.String, Description: "変更前の値(JSON文字列、値がない場合はnull)"},
+		"after":  &graphql.Field{Type: graphql.String, Description: "変更後の値(JSON文字列、値がない場合はnull)"},
+	},
+})
+
+// 変更履歴型
+var historyEntryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "HistoryEntry",
+	Fields: graphql.Fields{
+		"id":        &graphql.Field{Type: graphql.String},
+		"actorId":   &graphql.Field{Type: graphql.String},
+		"action":    &graphql.Field{Type: graphql.String},
+		"changes":   &graphql.Field{Type: graphql.NewList(fieldChangeType)},
+		"createdAt": &graphql.Field{Type: graphql.String},
+		"actor": &graphql.Field{
+			Type:    userType,
+			Resolve: resolveHistoryActor,
+		},
+	},
+})
+
+// 変更履歴の一覧型(ページネーション)
+var historyPageType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "HistoryPage",
+	Fields: graphql.Fields{
+		"items":      &graphql.Field{Type: graphql.NewList(historyEntryType)},
+		"totalCount": &graphql.Field{Type: graphql.Int},
+		"offset":     &graphql.Field{Type: graphql.Int},
+		"hasNext":    &graphql.Field{Type: graphql.Boolean},
+	},
+})
+
+// 変更履歴の一覧を取得するDataLoaderのキー
+// ViewerIdは所有者以外が取得する場合のみ設定し、閲覧権限を確認する。
+type historyPageKey struct {
+	TodoId   string
+	ViewerId string
+	Limit    int
+	Offset   int
+}
+
+// Todo型に変更履歴のフィールドを追加
+func init() {
+	todoType.AddFieldConfig("history", &graphql.Field{
+		Type: historyPageType,
+		Args: graphql.FieldConfigArgument{
+			"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 20},
+			"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		},
+		Resolve: resolveTodoHistory,
+	})
+}
+
+// 変更履歴をGraphQLのレスポンス形式に変換
+func toHistoryEntryMap(e domain_audit.HistoryEntry) map[string]interface{} {
+	changes := make([]map[string]interface{}, 0, len(e.Changes))
+	for _, c := range e.Changes {
+		change := map[string]interface{}{
+			"field":  snakeToCamel(c.Field),
+			"before": nil,
+			"after":  nil,
+		}
+		if len(c.Before) > 0 {
+			change["before"] = string(c.Before)
+		}
+		if len(c.After) > 0 {
+			change["after"] = string(c.After)
+		}
+		changes = append(changes, change)
+	}
+	return map[string]interface{}{
+		"id":        e.ID,
+		"actorId":   e.ActorId,
+		"action":    e.Action,
+		"changes":   changes,
+		"createdAt": e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// スナップショットの項目名(スネークケース)をGraphQLのフィールド名(キャメルケース)に変換
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// Todoの変更履歴を取得(DataLoader経由)
+// 所有者はゴミ箱のTodoも含めて取得でき、共有メンバーは閲覧権限のある削除されていないTodoのみ取得できる。
+func resolveTodoHistory(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	viewerKey, ok := newTodoViewerKey(todo, loaders.ViewerId)
+	if !ok {
+		return nil, nil
+	}
+
+	key := historyPageKey{TodoId: viewerKey.TodoId, ViewerId: viewerKey.ViewerId}
+	key.Limit, _ = p.Args["limit"].(int)
+	key.Offset, _ = p.Args["offset"].(int)
+
+	thunk := loaders.HistoryByTodoID.Load(key)
+	return func() (interface{}, error) {
+		page, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			return nil, nil
+		}
+		items := make([]map[string]interface{}, 0, len(page.Items))
+		for _, e := range page.Items {
+			items = append(items, toHistoryEntryMap(e))
+		}
+		return map[string]interface{}{
+			"items":      items,
+			"totalCount": page.TotalCount,
+			"offset":     key.Offset,
+			"hasNext":    key.Offset+len(items) < page.TotalCount,
+		}, nil
+	}, nil
+}
+
+// 変更履歴の操作者を取得(DataLoader経由、システムによる操作の場合はnil)
+func resolveHistoryActor(p graphql.ResolveParams) (interface{}, error) {
+	entry, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	actorId, _ := entry["actorId"].(string)
+	if actorId == "" || actorId == domain_audit.SystemActorId {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.UserByID.Load(actorId)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if user.ID == "" {
+			return nil, nil
+		}
+		return toUserMap(user), nil
+	}, nil
+}
@@ -0,0 +1,125 @@
+package interfaces_graphql
+
+import (
+	domain_recurrence "backend/internal/domain/recurrence"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Recurrence型(繰り返し設定)
+var recurrenceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Recurrence",
+	Fields: graphql.Fields{
+		"rule":     &graphql.Field{Type: graphql.String},
+		"timezone": &graphql.Field{Type: graphql.String},
+		"startAt":  &graphql.Field{Type: graphql.String},
+		"exDates":  &graphql.Field{Type: graphql.NewList(graphql.String)},
+	},
+})
+
+// Occurrence型(繰り返しの発生)
+var occurrenceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Occurrence",
+	Fields: graphql.Fields{
+		"todoId": &graphql.Field{Type: graphql.String},
+		"at":     &graphql.Field{Type: graphql.String},
+		"todo": &graphql.Field{
+			Type:    todoType,
+			Resolve: resolveOccurrenceTodo,
+		},
+	},
+})
+
+// Todo型に繰り返し設定のフィールドを追加
+func init() {
+	todoType.AddFieldConfig("recurrence", &graphql.Field{
+		Type:    recurrenceType,
+		Resolve: resolveTodoRecurrence,
+	})
+}
+
+// 繰り返し設定をGraphQLのレスポンス形式に変換(日時は設定のタイムゾーンで表す)
+func toRecurrenceMap(r domain_recurrence.Recurrence) map[string]interface{} {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	exDates := make([]string, 0, len(r.ExDates))
+	for _, t := range r.ExDates {
+		exDates = append(exDates, t.In(loc).Format(time.RFC3339))
+	}
+	return map[string]interface{}{
+		"rule":     r.Rule,
+		"timezone": r.Timezone,
+		"startAt":  r.StartAt.In(loc).Format(time.RFC3339),
+		"exDates":  exDates,
+	}
+}
+
+// 繰り返しの発生をGraphQLのレスポンス形式に変換
+func toOccurrenceMap(o domain_recurrence.Occurrence) map[string]interface{} {
+	return map[string]interface{}{
+		"todoId": o.TodoId,
+		"at":     o.At.Format(time.RFC3339),
+	}
+}
+
+// Todoの繰り返し設定を取得(DataLoader経由、繰り返しがない場合はnil)
+// 所有者以外は、閲覧権限のある削除されていないTodoのみ取得できる。
+func resolveTodoRecurrence(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := newTodoViewerKey(todo, loaders.ViewerId)
+	if !ok {
+		return nil, nil
+	}
+
+	thunk := loaders.RecurrenceByTodoID.Load(key)
+	return func() (interface{}, error) {
+		recurrence, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if recurrence.TodoId == "" {
+			return nil, nil
+		}
+		return toRecurrenceMap(recurrence), nil
+	}, nil
+}
+
+// 発生元のTodoを取得(DataLoader経由)
+func resolveOccurrenceTodo(p graphql.ResolveParams) (interface{}, error) {
+	occurrence, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	todoId, _ := occurrence["todoId"].(string)
+	if todoId == "" {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.TodoByID.Load(todoId)
+	return func() (interface{}, error) {
+		todo, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if todo.ID == "" {
+			return nil, nil
+		}
+		return toTodoMap(todo), nil
+	}, nil
+}
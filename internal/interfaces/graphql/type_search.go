@@ -0,0 +1,60 @@
+package interfaces_graphql
+
+import (
+	domain_search "backend/internal/domain/search"
+
+	"github.com/graphql-go/graphql"
+)
+
+// ページ情報型(カーソルによるページネーション)
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{Type: graphql.Boolean},
+		"endCursor":   &graphql.Field{Type: graphql.String, Description: "次のページを取得する際にafterに指定するカーソル(結果が無い場合はnull)"},
+	},
+})
+
+// Todoの検索結果型
+var todoSearchEdgeType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TodoSearchEdge",
+	Fields: graphql.Fields{
+		"cursor":  &graphql.Field{Type: graphql.String},
+		"rank":    &graphql.Field{Type: graphql.Float, Description: "関連度(大きいほど関連が高い)"},
+		"snippet": &graphql.Field{Type: graphql.String, Description: "説明の抜粋(HTMLエスケープ済み、一致した箇所を<mark>で囲む)"},
+		"node":    &graphql.Field{Type: todoType},
+	},
+})
+
+// Todoの検索結果の一覧型
+var todoSearchConnectionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TodoSearchConnection",
+	Fields: graphql.Fields{
+		"edges":    &graphql.Field{Type: graphql.NewList(todoSearchEdgeType)},
+		"pageInfo": &graphql.Field{Type: pageInfoType},
+	},
+})
+
+// 検索結果をGraphQLのレスポンス形式に変換
+func toTodoSearchConnection(page domain_search.TodoSearchPage) map[string]interface{} {
+	edges := make([]map[string]interface{}, 0, len(page.Results))
+	for _, r := range page.Results {
+		edges = append(edges, map[string]interface{}{
+			"cursor":  domain_search.EncodeTodoSearchCursor(domain_search.TodoSearchCursor{Rank: r.Rank, ID: r.Todo.ID}),
+			"rank":    float64(r.Rank),
+			"snippet": r.Snippet,
+			"node":    toTodoMap(r.Todo),
+		})
+	}
+	var endCursor interface{}
+	if page.EndCursor != "" {
+		endCursor = page.EndCursor
+	}
+	return map[string]interface{}{
+		"edges": edges,
+		"pageInfo": map[string]interface{}{
+			"hasNextPage": page.HasNextPage,
+			"endCursor":   endCursor,
+		},
+	}
+}
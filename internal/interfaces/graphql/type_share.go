@@ -0,0 +1,144 @@
+package interfaces_graphql
+
+import (
+	domain_share "backend/internal/domain/share"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// 共有の権限型
+var shareRoleEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "ShareRole",
+	Values: graphql.EnumValueConfigMap{
+		"VIEWER": &graphql.EnumValueConfig{Value: domain_share.ShareRoleViewer},
+		"EDITOR": &graphql.EnumValueConfig{Value: domain_share.ShareRoleEditor},
+		"OWNER":  &graphql.EnumValueConfig{Value: domain_share.ShareRoleOwner},
+	},
+})
+
+// 共有の状態型
+var shareStatusEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "ShareStatus",
+	Values: graphql.EnumValueConfigMap{
+		"PENDING":  &graphql.EnumValueConfig{Value: domain_share.ShareStatusPending},
+		"ACCEPTED": &graphql.EnumValueConfig{Value: domain_share.ShareStatusAccepted},
+	},
+})
+
+// 共有型(todoIdがnullの場合は所有者の全てのTodoの共有)
+var todoShareType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TodoShare",
+	Fields: graphql.Fields{
+		"id":         &graphql.Field{Type: graphql.String},
+		"ownerId":    &graphql.Field{Type: graphql.String},
+		"todoId":     &graphql.Field{Type: graphql.String},
+		"memberId":   &graphql.Field{Type: graphql.String},
+		"role":       &graphql.Field{Type: shareRoleEnum},
+		"status":     &graphql.Field{Type: shareStatusEnum},
+		"invitedBy":  &graphql.Field{Type: graphql.String},
+		"createdAt":  &graphql.Field{Type: graphql.String},
+		"acceptedAt": &graphql.Field{Type: graphql.String},
+		"owner": &graphql.Field{
+			Type:    userType,
+			Resolve: resolveShareUser("ownerId"),
+		},
+		"member": &graphql.Field{
+			Type:    userType,
+			Resolve: resolveShareUser("memberId"),
+		},
+		"todo": &graphql.Field{
+			Type:    todoType,
+			Resolve: resolveShareTodo,
+		},
+	},
+})
+
+// DeleteSharePayload型
+var deleteSharePayload = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DeleteSharePayload",
+	Fields: graphql.Fields{
+		"success": &graphql.Field{Type: graphql.Boolean},
+		"message": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// 共有をGraphQLのレスポンス形式に変換
+func toTodoShareMap(s domain_share.TodoShare) map[string]interface{} {
+	var acceptedAt *string
+	if s.AcceptedAt != nil {
+		v := s.AcceptedAt.Format(time.RFC3339)
+		acceptedAt = &v
+	}
+	return map[string]interface{}{
+		"id":         s.ID,
+		"ownerId":    s.OwnerId,
+		"todoId":     s.TodoId,
+		"memberId":   s.MemberId,
+		"role":       s.Role,
+		"status":     s.Status,
+		"invitedBy":  s.InvitedBy,
+		"createdAt":  s.CreatedAt.Format(time.RFC3339),
+		"acceptedAt": acceptedAt,
+	}
+}
+
+// 共有の所有者・共有先のユーザーを取得(DataLoader経由)
+func resolveShareUser(key string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		share, ok := p.Source.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		userId, _ := share[key].(string)
+		if userId == "" {
+			return nil, nil
+		}
+
+		loaders, err := loadersFromContext(p.Context)
+		if err != nil {
+			return nil, err
+		}
+
+		thunk := loaders.UserByID.Load(userId)
+		return func() (interface{}, error) {
+			user, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			if user.ID == "" {
+				return nil, nil
+			}
+			return toUserMap(user), nil
+		}, nil
+	}
+}
+
+// 共有されたTodoを取得(DataLoader経由、全てのTodoの共有の場合はnil)
+func resolveShareTodo(p graphql.ResolveParams) (interface{}, error) {
+	share, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	todoId, _ := share["todoId"].(*string)
+	if todoId == nil || *todoId == "" {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.TodoByID.Load(*todoId)
+	return func() (interface{}, error) {
+		todo, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if todo.ID == "" {
+			return nil, nil
+		}
+		return toTodoMap(todo), nil
+	}, nil
+}
@@ -0,0 +1,123 @@
+package interfaces_graphql
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// TodoProgress型(サブタスクの進捗)
+var todoProgressType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TodoProgress",
+	Fields: graphql.Fields{
+		"completed": &graphql.Field{Type: graphql.Int},
+		"total":     &graphql.Field{Type: graphql.Int},
+	},
+})
+
+// Todo型に親子関係のフィールドを追加
+func init() {
+	todoType.AddFieldConfig("parentId", &graphql.Field{Type: graphql.String})
+	todoType.AddFieldConfig("parent", &graphql.Field{
+		Type:    todoType,
+		Resolve: resolveTodoParent,
+	})
+	todoType.AddFieldConfig("subtasks", &graphql.Field{
+		Type:    graphql.NewList(todoType),
+		Resolve: resolveTodoSubtasks,
+	})
+	todoType.AddFieldConfig("progress", &graphql.Field{
+		Type:    todoProgressType,
+		Resolve: resolveTodoProgress,
+	})
+}
+
+// 親Todoを取得(DataLoader経由、最上位または閲覧権限がない場合はnil)
+func resolveTodoParent(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	parentId, _ := todo["parentId"].(*string)
+	if parentId == nil || *parentId == "" {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.TodoByID.Load(*parentId)
+	return func() (interface{}, error) {
+		parent, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if parent.ID == "" {
+			return nil, nil
+		}
+		return toTodoMap(parent), nil
+	}, nil
+}
+
+// サブタスクを取得(DataLoader経由)
+func resolveTodoSubtasks(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	todoId, _ := todo["id"].(string)
+	if todoId == "" {
+		return []map[string]interface{}{}, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.SubtasksByParentID.Load(todoId)
+	return func() (interface{}, error) {
+		subtasks, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(subtasks))
+		for _, t := range subtasks {
+			result = append(result, toTodoMap(t))
+		}
+		return result, nil
+	}, nil
+}
+
+// サブタスクの進捗を取得(DataLoader経由、サブタスクがない場合は0件)
+// 所有者以外は、閲覧権限のある削除されていないTodoのみ取得できる。
+func resolveTodoProgress(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := newTodoViewerKey(todo, loaders.ViewerId)
+	if !ok {
+		return nil, nil
+	}
+
+	thunk := loaders.ProgressByTodoID.Load(key)
+	return func() (interface{}, error) {
+		progress, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if progress == nil {
+			return nil, nil
+		}
+		return map[string]interface{}{
+			"completed": progress.Completed,
+			"total":     progress.Total,
+		}, nil
+	}, nil
+}
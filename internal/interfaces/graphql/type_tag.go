@@ -0,0 +1,115 @@
+package interfaces_graphql
+
+import (
+	domain_tag "backend/internal/domain/tag"
+	domain_todo "backend/internal/domain/todo"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// タグ型
+var tagType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Tag",
+	Fields: graphql.Fields{
+		"id":        &graphql.Field{Type: graphql.String},
+		"name":      &graphql.Field{Type: graphql.String},
+		"createdAt": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// DeleteTagPayload型
+var deleteTagPayload = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DeleteTagPayload",
+	Fields: graphql.Fields{
+		"success": &graphql.Field{Type: graphql.Boolean},
+		"message": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// Todo型にタグのフィールドを追加
+func init() {
+	todoType.AddFieldConfig("tags", &graphql.Field{
+		Type:    graphql.NewList(tagType),
+		Resolve: resolveTodoTags,
+	})
+}
+
+// タグをGraphQLのレスポンス形式に変換
+func toTagMap(t domain_tag.Tag) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        t.ID,
+		"name":      t.Name,
+		"createdAt": t.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// Todoのタグを取得(DataLoader経由)
+// 所有者以外は、閲覧権限のある削除されていないTodoのみ取得できる。
+func resolveTodoTags(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := newTodoViewerKey(todo, loaders.ViewerId)
+	if !ok {
+		return []map[string]interface{}{}, nil
+	}
+
+	thunk := loaders.TagsByTodoID.Load(key)
+	return func() (interface{}, error) {
+		tags, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(tags))
+		for _, t := range tags {
+			result = append(result, toTagMap(t))
+		}
+		return result, nil
+	}, nil
+}
+
+// タグによる絞り込みの引数
+var tagFilterArgs = graphql.FieldConfigArgument{
+	"tagIds": &graphql.ArgumentConfig{
+		Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+		Description: "指定したタグが全て付与されたTodoのみ取得する",
+	},
+}
+
+// 引数のタグでTodoを絞り込む(タグが指定されていない場合はそのまま返す)
+func (h *GraphQLHandler) filterTodosByTags(userId string, todos []domain_todo.Todo, args map[string]interface{}) ([]domain_todo.Todo, error) {
+	values, _ := args["tagIds"].([]interface{})
+	if len(values) == 0 {
+		return todos, nil
+	}
+	tagIds := make([]string, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(string); ok {
+			tagIds = append(tagIds, id)
+		}
+	}
+
+	ids, err := h.tagUsecase.GetTodoIdsByTagIds(userId, tagIds)
+	if err != nil {
+		return nil, err
+	}
+	matched := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		matched[id] = true
+	}
+
+	filtered := make([]domain_todo.Todo, 0, len(ids))
+	for _, t := range todos {
+		if matched[t.ID] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered, nil
+}
@@ -1,6 +1,23 @@
 package interfaces_graphql
 
-import "github.com/graphql-go/graphql"
+import (
+	domain_todo "backend/internal/domain/todo"
+	"errors"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Todoの優先度型
+var todoPriorityEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TodoPriority",
+	Values: graphql.EnumValueConfigMap{
+		"LOW":    &graphql.EnumValueConfig{Value: domain_todo.TodoPriorityLow},
+		"MEDIUM": &graphql.EnumValueConfig{Value: domain_todo.TodoPriorityMedium},
+		"HIGH":   &graphql.EnumValueConfig{Value: domain_todo.TodoPriorityHigh},
+		"URGENT": &graphql.EnumValueConfig{Value: domain_todo.TodoPriorityUrgent},
+	},
+})
 
 // Todo型
 var todoType = graphql.NewObject(graphql.ObjectConfig{
@@ -10,6 +27,11 @@ var todoType = graphql.NewObject(graphql.ObjectConfig{
 		"description": &graphql.Field{Type: graphql.String},
 		"completed":   &graphql.Field{Type: graphql.Boolean},
 		"userId":      &graphql.Field{Type: graphql.String},
+		"deletedAt":   &graphql.Field{Type: graphql.String},
+		"dueAt":       &graphql.Field{Type: graphql.String},
+		"priority":    &graphql.Field{Type: todoPriorityEnum},
+		"remindAt":    &graphql.Field{Type: graphql.String},
+		"position":    &graphql.Field{Type: graphql.String},
 	},
 })
 
@@ -21,3 +43,172 @@ var deleteTodoPayload = graphql.NewObject(graphql.ObjectConfig{
 		"message": &graphql.Field{Type: graphql.String},
 	},
 })
+
+// PurgeTodoPayload型
+var purgeTodoPayload = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PurgeTodoPayload",
+	Fields: graphql.Fields{
+		"success": &graphql.Field{Type: graphql.Boolean},
+		"message": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// TodoBulkResult型(一括操作の1件ごとの結果)
+var todoBulkResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TodoBulkResult",
+	Fields: graphql.Fields{
+		"id":      &graphql.Field{Type: graphql.String},
+		"success": &graphql.Field{Type: graphql.Boolean},
+		"message": &graphql.Field{Type: graphql.String},
+		"changed": &graphql.Field{Type: graphql.Boolean},
+		"todo":    &graphql.Field{Type: todoType},
+	},
+})
+
+// BulkTodoPayload型
+var bulkTodoPayload = graphql.NewObject(graphql.ObjectConfig{
+	Name: "BulkTodoPayload",
+	Fields: graphql.Fields{
+		"results":   &graphql.Field{Type: graphql.NewList(todoBulkResultType)},
+		"succeeded": &graphql.Field{Type: graphql.Int},
+		"failed":    &graphql.Field{Type: graphql.Int},
+	},
+})
+
+// 相互参照するフィールドの追加
+// Todo型とUser型は互いを参照するため、初期化後にフィールドを追加する。
+func init() {
+	todoType.AddFieldConfig("owner", &graphql.Field{
+		Type:    userType,
+		Resolve: resolveTodoOwner,
+	})
+}
+
+// TodoをGraphQLのレスポンス形式に変換
+func toTodoMap(t domain_todo.Todo) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":          t.ID,
+		"description": t.Description,
+		"completed":   t.Completed,
+		"userId":      t.UserId,
+		"deletedAt":   formatOptionalTime(t.DeletedAt),
+		"dueAt":       formatOptionalTime(t.DueAt),
+		"priority":    t.Priority,
+		"remindAt":    formatOptionalTime(t.RemindAt),
+		"listId":      t.ListId,
+		"parentId":    t.ParentId,
+		"position":    t.Position,
+	}
+	return result
+}
+
+// 日時をRFC3339形式に変換(nilの場合はnil)
+func formatOptionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+// 期限・優先度・リマインド日時の引数をTodoに反映
+// 指定されていない引数は変更しない。dueAt・remindAtに空文字を指定した場合は解除する。
+func applyScheduleArgs(todo *domain_todo.Todo, args map[string]interface{}) error {
+	if v, ok := args["dueAt"].(string); ok {
+		dueAt, err := parseOptionalTime(v)
+		if err != nil {
+			return errors.New("dueAt must be RFC3339")
+		}
+		todo.DueAt = dueAt
+	}
+	if v, ok := args["remindAt"].(string); ok {
+		remindAt, err := parseOptionalTime(v)
+		if err != nil {
+			return errors.New("remindAt must be RFC3339")
+		}
+		todo.RemindAt = remindAt
+	}
+	if v, ok := args["priority"].(string); ok {
+		todo.Priority = v
+	}
+	return nil
+}
+
+// RFC3339形式の日時を解析(空文字の場合はnil)
+func parseOptionalTime(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Todoの所有者を取得(DataLoader経由)
+func resolveTodoOwner(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	userId, _ := todo["userId"].(string)
+	if userId == "" {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.UserByID.Load(userId)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if user.ID == "" {
+			return nil, nil
+		}
+		return toUserMap(user), nil
+	}, nil
+}
+
+// 一括操作の結果をGraphQLのレスポンス形式に変換
+func toBulkTodoPayload(results []domain_todo.TodoBulkResult) map[string]interface{} {
+	items := make([]interface{}, 0, len(results))
+	succeeded := 0
+	for _, r := range results {
+		item := map[string]interface{}{
+			"id":      r.ID,
+			"success": r.Success,
+			"message": r.Message,
+			"changed": r.Changed,
+			"todo":    nil,
+		}
+		if r.Todo != nil {
+			item["todo"] = toTodoMap(*r.Todo)
+		}
+		if r.Success {
+			succeeded++
+		}
+		items = append(items, item)
+	}
+	return map[string]interface{}{
+		"results":   items,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	}
+}
+
+// 文字列のリストの引数を取得
+func stringListArg(args map[string]interface{}, name string) []string {
+	values, _ := args[name].([]interface{})
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,127 @@
+package interfaces_graphql
+
+import (
+	domain_todolist "backend/internal/domain/todolist"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Todoリスト削除時のTodoの扱い型
+var todoListDeleteModeEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TodoListDeleteMode",
+	Values: graphql.EnumValueConfigMap{
+		"CASCADE":       &graphql.EnumValueConfig{Value: domain_todolist.TodoListDeleteModeCascade},
+		"MOVE_TO_INBOX": &graphql.EnumValueConfig{Value: domain_todolist.TodoListDeleteModeMoveToInbox},
+	},
+})
+
+// Todoリスト型
+var todoListType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TodoList",
+	Fields: graphql.Fields{
+		"id":        &graphql.Field{Type: graphql.String},
+		"name":      &graphql.Field{Type: graphql.String},
+		"color":     &graphql.Field{Type: graphql.String},
+		"archived":  &graphql.Field{Type: graphql.Boolean},
+		"position":  &graphql.Field{Type: graphql.Int},
+		"createdAt": &graphql.Field{Type: graphql.String},
+		"updatedAt": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// DeleteTodoListPayload型
+var deleteTodoListPayload = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DeleteTodoListPayload",
+	Fields: graphql.Fields{
+		"success":       &graphql.Field{Type: graphql.Boolean},
+		"message":       &graphql.Field{Type: graphql.String},
+		"affectedTodos": &graphql.Field{Type: graphql.Int},
+	},
+})
+
+// 相互参照するフィールドの追加
+// Todo型とTodoリスト型は互いを参照するため、初期化後にフィールドを追加する。
+func init() {
+	todoType.AddFieldConfig("listId", &graphql.Field{Type: graphql.String})
+	todoType.AddFieldConfig("list", &graphql.Field{
+		Type:    todoListType,
+		Resolve: resolveTodoList,
+	})
+	todoListType.AddFieldConfig("todos", &graphql.Field{
+		Type:    graphql.NewList(todoType),
+		Resolve: resolveTodoListTodos,
+	})
+}
+
+// TodoリストをGraphQLのレスポンス形式に変換
+func toTodoListMap(l domain_todolist.TodoList) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        l.ID,
+		"name":      l.Name,
+		"color":     l.Color,
+		"archived":  l.Archived,
+		"position":  l.Position,
+		"createdAt": l.CreatedAt.Format(time.RFC3339),
+		"updatedAt": l.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// Todoが属するリストを取得(DataLoader経由、インボックスの場合はnil)
+func resolveTodoList(p graphql.ResolveParams) (interface{}, error) {
+	todo, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	listId, _ := todo["listId"].(*string)
+	if listId == nil || *listId == "" {
+		return nil, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.TodoListByID.Load(*listId)
+	return func() (interface{}, error) {
+		list, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		if list.ID == "" {
+			return nil, nil
+		}
+		return toTodoListMap(list), nil
+	}, nil
+}
+
+// Todoリストに属するTodoを取得(DataLoader経由)
+func resolveTodoListTodos(p graphql.ResolveParams) (interface{}, error) {
+	list, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	listId, _ := list["id"].(string)
+	if listId == "" {
+		return []map[string]interface{}{}, nil
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.TodosByListID.Load(listId)
+	return func() (interface{}, error) {
+		todos, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		result := make([]map[string]interface{}, 0, len(todos))
+		for _, t := range todos {
+			result = append(result, toTodoMap(t))
+		}
+		return result, nil
+	}, nil
+}
@@ -0,0 +1,66 @@
+package interfaces_graphql
+
+import (
+	domain_transfer "backend/internal/domain/transfer"
+
+	"github.com/graphql-go/graphql"
+)
+
+// エクスポート・インポートの形式型
+var todoTransferFormatEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "TodoTransferFormat",
+	Values: graphql.EnumValueConfigMap{
+		"JSON": &graphql.EnumValueConfig{Value: domain_transfer.TodoTransferFormatJSON},
+		"CSV":  &graphql.EnumValueConfig{Value: domain_transfer.TodoTransferFormatCSV},
+		"ICS":  &graphql.EnumValueConfig{Value: domain_transfer.TodoTransferFormatICal},
+	},
+})
+
+// インポートの1行のエラー型
+var todoImportErrorType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TodoImportError",
+	Fields: graphql.Fields{
+		"row":     &graphql.Field{Type: graphql.Int, Description: "行番号(1始まり、CSVはヘッダー行を除く)"},
+		"field":   &graphql.Field{Type: graphql.String, Description: "エラーの項目(行全体の場合はnull)"},
+		"message": &graphql.Field{Type: graphql.String},
+	},
+})
+
+// インポートの結果型
+var todoImportPayloadType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TodoImportPayload",
+	Fields: graphql.Fields{
+		"dryRun":   &graphql.Field{Type: graphql.Boolean},
+		"total":    &graphql.Field{Type: graphql.Int, Description: "読み込んだ行数"},
+		"imported": &graphql.Field{Type: graphql.Int, Description: "インポートした件数(検証のみ、またはエラーがある場合は0)"},
+		"errors":   &graphql.Field{Type: graphql.NewList(todoImportErrorType)},
+		"todos":    &graphql.Field{Type: graphql.NewList(todoType)},
+	},
+})
+
+// インポートの結果をGraphQLのレスポンス形式に変換
+func toTodoImportPayload(report domain_transfer.TodoImportReport) map[string]interface{} {
+	errs := make([]map[string]interface{}, 0, len(report.Errors))
+	for _, e := range report.Errors {
+		var field interface{}
+		if e.Field != "" {
+			field = e.Field
+		}
+		errs = append(errs, map[string]interface{}{
+			"row":     e.Row,
+			"field":   field,
+			"message": e.Message,
+		})
+	}
+	todos := make([]map[string]interface{}, 0, len(report.Todos))
+	for _, t := range report.Todos {
+		todos = append(todos, toTodoMap(t))
+	}
+	return map[string]interface{}{
+		"dryRun":   report.DryRun,
+		"total":    report.Total,
+		"imported": report.Imported,
+		"errors":   errs,
+		"todos":    todos,
+	}
+}
@@ -0,0 +1,27 @@
+package interfaces_graphql
+
+import (
+	"mime/multipart"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Upload型(GraphQL multipart request仕様)
+// multipartリクエストのファイルが変数に割り当てられる。クエリ内のリテラルとしては指定できない。
+var uploadScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Upload",
+	Description: "The `Upload` scalar represents a file sent with a GraphQL multipart request.",
+	Serialize: func(value interface{}) interface{} {
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if file, ok := value.(*multipart.FileHeader); ok {
+			return file
+		}
+		return nil
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return nil
+	},
+})
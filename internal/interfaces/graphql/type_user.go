@@ -1,6 +1,10 @@
 package interfaces_graphql
 
-import "github.com/graphql-go/graphql"
+import (
+	domain_user "backend/internal/domain/user"
+
+	"github.com/graphql-go/graphql"
+)
 
 // ユーザー型
 var userType = graphql.NewObject(graphql.ObjectConfig{
@@ -11,3 +15,58 @@ var userType = graphql.NewObject(graphql.ObjectConfig{
 		"email":    &graphql.Field{Type: graphql.String},
 	},
 })
+
+// 相互参照するフィールドの追加
+// User型とTodo型は互いを参照するため、初期化後にフィールドを追加する。
+func init() {
+	userType.AddFieldConfig("todos", &graphql.Field{
+		Type:    graphql.NewList(todoType),
+		Args:    graphql.FieldConfigArgument{"limit": listLimitArg},
+		Resolve: resolveUserTodos,
+	})
+}
+
+// ユーザーをGraphQLのレスポンス形式に変換
+func toUserMap(u domain_user.Users) map[string]interface{} {
+	return map[string]interface{}{
+		"id":       u.ID,
+		"username": u.Username,
+		"email":    u.Email,
+	}
+}
+
+// ユーザーのTodoを取得(DataLoader経由)
+// 本人以外のTodoは、リクエストしたユーザーに共有されたもののみ返す(引数limitの件数まで)。
+func resolveUserTodos(p graphql.ResolveParams) (interface{}, error) {
+	user, ok := p.Source.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	userId, _ := user["id"].(string)
+	if userId == "" {
+		return []map[string]interface{}{}, nil
+	}
+	limit, err := listLimit(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	loaders, err := loadersFromContext(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	thunk := loaders.TodosByUserID.Load(userId)
+	return func() (interface{}, error) {
+		todos, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		todos = truncateList(todos, limit)
+		result := make([]map[string]interface{}, 0, len(todos))
+		for _, t := range todos {
+			result = append(result, toTodoMap(t))
+		}
+		return result, nil
+	}, nil
+}
@@ -0,0 +1,122 @@
+package interfaces_graphql
+
+import (
+	domain_webhook "backend/internal/domain/webhook"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Webhookのイベントの種類型
+var webhookEventTypeEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "WebhookEventType",
+	Values: graphql.EnumValueConfigMap{
+		"TODO_CREATED":   &graphql.EnumValueConfig{Value: domain_webhook.WebhookEventTodoCreated},
+		"TODO_UPDATED":   &graphql.EnumValueConfig{Value: domain_webhook.WebhookEventTodoUpdated},
+		"TODO_COMPLETED": &graphql.EnumValueConfig{Value: domain_webhook.WebhookEventTodoCompleted},
+		"TODO_DELETED":   &graphql.EnumValueConfig{Value: domain_webhook.WebhookEventTodoDeleted},
+		"TODO_RESTORED":  &graphql.EnumValueConfig{Value: domain_webhook.WebhookEventTodoRestored},
+		"TODO_PURGED":    &graphql.EnumValueConfig{Value: domain_webhook.WebhookEventTodoPurged},
+	},
+})
+
+// Webhookの配信の状態型
+var webhookDeliveryStatusEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "WebhookDeliveryStatus",
+	Values: graphql.EnumValueConfigMap{
+		"PENDING":   &graphql.EnumValueConfig{Value: domain_webhook.WebhookDeliveryStatusPending},
+		"SUCCEEDED": &graphql.EnumValueConfig{Value: domain_webhook.WebhookDeliveryStatusSucceeded},
+		"FAILED":    &graphql.EnumValueConfig{Value: domain_webhook.WebhookDeliveryStatusFailed},
+	},
+})
+
+// Webhook型(シークレットは返さない)
+var webhookType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Webhook",
+	Fields: graphql.Fields{
+		"id":         &graphql.Field{Type: graphql.String},
+		"url":        &graphql.Field{Type: graphql.String},
+		"eventTypes": &graphql.Field{Type: graphql.NewList(webhookEventTypeEnum)},
+		"active":     &graphql.Field{Type: graphql.Boolean},
+		"createdAt":  &graphql.Field{Type: graphql.String},
+		"updatedAt":  &graphql.Field{Type: graphql.String},
+	},
+})
+
+// Webhookの配信型
+var webhookDeliveryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "WebhookDelivery",
+	Fields: graphql.Fields{
+		"id":               &graphql.Field{Type: graphql.String},
+		"webhookId":        &graphql.Field{Type: graphql.String},
+		"eventType":        &graphql.Field{Type: webhookEventTypeEnum},
+		"payload":          &graphql.Field{Type: graphql.String, Description: "送信する本文(JSON)"},
+		"status":           &graphql.Field{Type: webhookDeliveryStatusEnum},
+		"attempts":         &graphql.Field{Type: graphql.Int, Description: "送信した回数"},
+		"nextAttemptAt":    &graphql.Field{Type: graphql.String, Description: "次に送信する日時(送信待ちの場合のみ)"},
+		"lastResponseCode": &graphql.Field{Type: graphql.Int},
+		"lastError":        &graphql.Field{Type: graphql.String},
+		"redeliveryOf":     &graphql.Field{Type: graphql.String, Description: "手動で再送した元の配信ID"},
+		"createdAt":        &graphql.Field{Type: graphql.String},
+		"completedAt":      &graphql.Field{Type: graphql.String},
+	},
+})
+
+// Webhookの送信履歴型
+var webhookDeliveryAttemptType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "WebhookDeliveryAttempt",
+	Fields: graphql.Fields{
+		"attempt":      &graphql.Field{Type: graphql.Int},
+		"responseCode": &graphql.Field{Type: graphql.Int, Description: "応答のステータスコード(応答が無い場合はnull)"},
+		"responseBody": &graphql.Field{Type: graphql.String, Description: "応答の本文(先頭1KBのみ)"},
+		"error":        &graphql.Field{Type: graphql.String},
+		"durationMs":   &graphql.Field{Type: graphql.Int},
+		"createdAt":    &graphql.Field{Type: graphql.String},
+	},
+})
+
+// WebhookをGraphQLのレスポンス形式に変換
+func toWebhookMap(w domain_webhook.Webhook) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         w.ID,
+		"url":        w.URL,
+		"eventTypes": w.EventTypes,
+		"active":     w.Active,
+		"createdAt":  w.CreatedAt.Format(time.RFC3339),
+		"updatedAt":  w.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// Webhookの配信をGraphQLのレスポンス形式に変換
+func toWebhookDeliveryMap(d domain_webhook.WebhookDelivery) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               d.ID,
+		"webhookId":        d.WebhookId,
+		"eventType":        d.EventType,
+		"payload":          string(d.Payload),
+		"status":           d.Status,
+		"attempts":         d.Attempts,
+		"nextAttemptAt":    formatOptionalTime(d.NextAttemptAt),
+		"lastResponseCode": d.LastResponseCode,
+		"lastError":        d.LastError,
+		"redeliveryOf":     d.RedeliveryOf,
+		"createdAt":        d.CreatedAt.Format(time.RFC3339),
+		"completedAt":      formatOptionalTime(d.CompletedAt),
+	}
+}
+
+// Webhookの送信履歴をGraphQLのレスポンス形式に変換
+func toWebhookDeliveryAttemptMap(a domain_webhook.WebhookDeliveryAttempt) map[string]interface{} {
+	var errorMessage *string
+	if a.Error != "" {
+		errorMessage = &a.Error
+	}
+	return map[string]interface{}{
+		"attempt":      a.Attempt,
+		"responseCode": a.ResponseCode,
+		"responseBody": a.ResponseBody,
+		"error":        errorMessage,
+		"durationMs":   a.DurationMs,
+		"createdAt":    a.CreatedAt.Format(time.RFC3339),
+	}
+}
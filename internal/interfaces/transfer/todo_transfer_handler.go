@@ -0,0 +1,100 @@
+package interfaces_transfer
+
+import (
+	domain_transfer "backend/internal/domain/transfer"
+	interfaces_auth "backend/internal/interfaces/auth"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_timer "backend/internal/pkg/timer"
+	usecase_transfer "backend/internal/usecase/transfer"
+	"bufio"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// エクスポートの書き込みバッファのサイズ
+// バッファを書き出す前に失敗した場合は、エラーのステータスコードを返せる。
+const exportBufferSize = 32 * 1024
+
+// 形式ごとのContent-Type
+var exportContentTypes = map[string]string{
+	domain_transfer.TodoTransferFormatJSON: "application/json; charset=utf-8",
+	domain_transfer.TodoTransferFormatCSV:  "text/csv; charset=utf-8",
+	domain_transfer.TodoTransferFormatICal: "text/calendar; charset=utf-8",
+}
+
+// Todoのエクスポートハンドラ(Impl)
+type TodoTransferHandler struct {
+	Logger          *pkg_logger.AppLogger
+	timer           *pkg_timer.TimerPkg
+	authHandler     *interfaces_auth.AuthHandler
+	transferUsecase usecase_transfer.ITodoTransferUsecase
+}
+
+// Todoのエクスポートハンドラのインスタンス化
+func NewTodoTransferHandler(l *pkg_logger.AppLogger, ah *interfaces_auth.AuthHandler, tu usecase_transfer.ITodoTransferUsecase) *TodoTransferHandler {
+	return &TodoTransferHandler{
+		Logger:          l,
+		timer:           pkg_timer.NewTimerPkg(),
+		authHandler:     ah,
+		transferUsecase: tu,
+	}
+}
+
+// 自分のTodoをエクスポート(GET /todos/export?format=json|csv|ics)
+// 全件をメモリに載せず、読み込んだ順にレスポンスへ書き出す。
+func (h *TodoTransferHandler) ExportTodos(c echo.Context) error {
+	h.Logger.InfoLog.Println("Exporting todos...")
+	h.timer.Start()
+
+	ctx, err := h.authHandler.ParseAndAuthorizeToken(c, h.authHandler.AppConfig.UserRole)
+	if err != nil {
+		h.Logger.ErrorLog.Printf("unauthorized: %v", err)
+		h.Logger.PrintDuration("Exporting todos", h.timer.GetDuration())
+		return err
+	}
+	userId, ok := ctx.Value(h.authHandler.AppConfig.UserID).(string)
+	if !ok || userId == "" {
+		h.Logger.ErrorLog.Println("unauthorized")
+		h.Logger.PrintDuration("Exporting todos", h.timer.GetDuration())
+		return echo.NewHTTPError(http.StatusUnauthorized, "unauthorized")
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = domain_transfer.TodoTransferFormatJSON
+	}
+	if !domain_transfer.IsValidFormat(format) {
+		h.Logger.ErrorLog.Printf("Invalid format: %s", format)
+		h.Logger.PrintDuration("Exporting todos", h.timer.GetDuration())
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid format")
+	}
+
+	res := c.Response()
+	filename := "todos-" + time.Now().UTC().Format("20060102") + "." + format
+	res.Header().Set(echo.HeaderContentType, exportContentTypes[format])
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	res.Header().Set("Cache-Control", "no-store")
+
+	w := bufio.NewWriterSize(res, exportBufferSize)
+	err = h.transferUsecase.ExportTodos(userId, format, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		h.Logger.ErrorLog.Printf("Failed to export todos: %v", err)
+		h.Logger.PrintDuration("Exporting todos", h.timer.GetDuration())
+		if !res.Committed {
+			// まだ何も送っていない場合はエラーを返す
+			res.Header().Del(echo.HeaderContentDisposition)
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to export todos")
+		}
+		// 送信を始めた後はステータスを変えられないため、途中で打ち切る
+		return nil
+	}
+
+	h.Logger.InfoLog.Printf("Exported todos as %s", format)
+	h.Logger.PrintDuration("Exporting todos", h.timer.GetDuration())
+	return nil
+}
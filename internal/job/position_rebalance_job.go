@@ -0,0 +1,48 @@
+package job
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	usecase_todo "backend/internal/usecase/todo"
+	"context"
+	"time"
+)
+
+// 並び順のキーの振り直しジョブ
+// 一定間隔で、並び順のキーが長くなった(または未設定の)ユーザーのキーを等間隔に振り直す。
+type PositionRebalanceJob struct {
+	Logger      *pkg_logger.AppLogger
+	todoUsecase usecase_todo.ITodoUsecase
+	interval    time.Duration
+	maxLength   int
+}
+
+// 並び順のキーの振り直しジョブのインスタンス化
+func NewPositionRebalanceJob(l *pkg_logger.AppLogger, tu usecase_todo.ITodoUsecase, interval time.Duration, maxLength int) *PositionRebalanceJob {
+	return &PositionRebalanceJob{
+		Logger:      l,
+		todoUsecase: tu,
+		interval:    interval,
+		maxLength:   maxLength,
+	}
+}
+
+// ジョブを開始(ctxがキャンセルされるまで実行する)
+func (j *PositionRebalanceJob) Start(ctx context.Context) {
+	if j.interval <= 0 {
+		j.Logger.InfoLog.Println("Position rebalance job is disabled")
+		return
+	}
+
+	j.Logger.InfoLog.Printf("Starting position rebalance job (interval: %v, max length: %d)", j.interval, j.maxLength)
+	runPeriodically(ctx, j.Logger, "Position rebalance job", j.interval, j.run)
+}
+
+// 並び順のキーを振り直す
+func (j *PositionRebalanceJob) run() {
+	rebalanced, err := j.todoUsecase.RebalancePositions(j.maxLength)
+	if err != nil {
+		j.Logger.ErrorLog.Printf("Failed to rebalance todo positions: %v", err)
+		return
+	}
+	j.Logger.InfoLog.Printf("Position rebalance job rebalanced %d users", rebalanced)
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	usecase_reminder "backend/internal/usecase/reminder"
+	"context"
+	"time"
+)
+
+// リマインドのスケジューラ
+// 一定間隔で、リマインド日時を過ぎたTodoの通知を行う。
+type ReminderJob struct {
+	Logger          *pkg_logger.AppLogger
+	reminderUsecase usecase_reminder.IReminderUsecase
+	interval        time.Duration
+}
+
+// リマインドのスケジューラのインスタンス化
+func NewReminderJob(l *pkg_logger.AppLogger, ru usecase_reminder.IReminderUsecase, interval time.Duration) *ReminderJob {
+	return &ReminderJob{
+		Logger:          l,
+		reminderUsecase: ru,
+		interval:        interval,
+	}
+}
+
+// ジョブを開始(ctxがキャンセルされるまで実行する)
+func (j *ReminderJob) Start(ctx context.Context) {
+	if j.interval <= 0 {
+		j.Logger.InfoLog.Println("Reminder job is disabled")
+		return
+	}
+
+	j.Logger.InfoLog.Printf("Starting reminder job (interval: %v)", j.interval)
+	runPeriodically(ctx, j.Logger, "Reminder job", j.interval, j.run)
+}
+
+// リマインド日時を過ぎたTodoの通知
+func (j *ReminderJob) run() {
+	dispatched, err := j.reminderUsecase.DispatchDueReminders()
+	if err != nil {
+		j.Logger.ErrorLog.Printf("Failed to dispatch reminders: %v", err)
+		return
+	}
+	if dispatched > 0 {
+		j.Logger.InfoLog.Printf("Reminder job dispatched %d reminders", dispatched)
+	}
+}
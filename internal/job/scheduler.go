@@ -0,0 +1,26 @@
+package job
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	"context"
+	"time"
+)
+
+// 処理を一定間隔で実行する(起動時に一度実行し、ctxがキャンセルされるまで繰り返す)
+func runPeriodically(ctx context.Context, l *pkg_logger.AppLogger, name string, interval time.Duration, fn func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		fn()
+		for {
+			select {
+			case <-ctx.Done():
+				l.InfoLog.Printf("%s stopped", name)
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	usecase_todo "backend/internal/usecase/todo"
+	"context"
+	"time"
+)
+
+// ゴミ箱の自動削除ジョブ
+// 一定間隔で、保持期間を過ぎたゴミ箱のTodoを完全に削除する。
+type TrashPurgeJob struct {
+	Logger      *pkg_logger.AppLogger
+	todoUsecase usecase_todo.ITodoUsecase
+	interval    time.Duration
+	retention   time.Duration
+}
+
+// ゴミ箱の自動削除ジョブのインスタンス化
+func NewTrashPurgeJob(l *pkg_logger.AppLogger, tu usecase_todo.ITodoUsecase, interval time.Duration, retention time.Duration) *TrashPurgeJob {
+	return &TrashPurgeJob{
+		Logger:      l,
+		todoUsecase: tu,
+		interval:    interval,
+		retention:   retention,
+	}
+}
+
+// ジョブを開始(ctxがキャンセルされるまで実行する)
+func (j *TrashPurgeJob) Start(ctx context.Context) {
+	if j.interval <= 0 {
+		j.Logger.InfoLog.Println("Trash purge job is disabled")
+		return
+	}
+
+	j.Logger.InfoLog.Printf("Starting trash purge job (interval: %v, retention: %v)", j.interval, j.retention)
+	runPeriodically(ctx, j.Logger, "Trash purge job", j.interval, j.run)
+}
+
+// 保持期間を過ぎたTodoを完全に削除
+func (j *TrashPurgeJob) run() {
+	purged, err := j.todoUsecase.PurgeExpiredTodos(j.retention)
+	if err != nil {
+		j.Logger.ErrorLog.Printf("Failed to purge expired todos: %v", err)
+		return
+	}
+	j.Logger.InfoLog.Printf("Trash purge job purged %d todos", purged)
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	usecase_webhook "backend/internal/usecase/webhook"
+	"context"
+	"time"
+)
+
+// Webhookの配信のスケジューラ
+// 一定間隔で、送信日時を過ぎた配信(再送を含む)を送信する。
+type WebhookDeliveryJob struct {
+	Logger         *pkg_logger.AppLogger
+	webhookUsecase usecase_webhook.IWebhookUsecase
+	interval       time.Duration
+}
+
+// Webhookの配信のスケジューラのインスタンス化
+func NewWebhookDeliveryJob(l *pkg_logger.AppLogger, wu usecase_webhook.IWebhookUsecase, interval time.Duration) *WebhookDeliveryJob {
+	return &WebhookDeliveryJob{
+		Logger:         l,
+		webhookUsecase: wu,
+		interval:       interval,
+	}
+}
+
+// ジョブを開始(ctxがキャンセルされるまで実行する)
+func (j *WebhookDeliveryJob) Start(ctx context.Context) {
+	if j.interval <= 0 {
+		j.Logger.InfoLog.Println("Webhook delivery job is disabled")
+		return
+	}
+
+	j.Logger.InfoLog.Printf("Starting webhook delivery job (interval: %v)", j.interval)
+	runPeriodically(ctx, j.Logger, "Webhook delivery job", j.interval, j.run)
+}
+
+// 送信日時を過ぎた配信の送信
+func (j *WebhookDeliveryJob) run() {
+	dispatched, err := j.webhookUsecase.DispatchPendingDeliveries()
+	if err != nil {
+		j.Logger.ErrorLog.Printf("Failed to dispatch webhook deliveries: %v", err)
+		return
+	}
+	if dispatched > 0 {
+		j.Logger.InfoLog.Printf("Webhook delivery job dispatched %d deliveries", dispatched)
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"backend/config"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_ratelimit "backend/internal/pkg/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/labstack/echo/v4"
+)
+
+// トークンを消費するオペレーションの種類(バケットをこの順序でロックする)
+var rateLimitedOperationTypes = []string{ast.OperationTypeQuery, ast.OperationTypeMutation}
+
+// GraphQLのレート制限
+// 認証済みの場合はユーザーID、未認証の場合はIPアドレスごとに、オペレーションの種類別のトークンバケットで制限する。
+// リクエストの解析と認証はエンドポイントで1度だけ行い、その結果を受け取って判定する。
+type GraphQLRateLimiter struct {
+	Logger   *pkg_logger.AppLogger
+	limiters map[string]*pkg_ratelimit.TokenBucketLimiter
+}
+
+// GraphQLのレート制限のインスタンス化
+func NewGraphQLRateLimiter(l *pkg_logger.AppLogger, conf *config.AppConfig) *GraphQLRateLimiter {
+	return &GraphQLRateLimiter{
+		Logger: l,
+		limiters: map[string]*pkg_ratelimit.TokenBucketLimiter{
+			ast.OperationTypeQuery:    pkg_ratelimit.NewTokenBucketLimiter(conf.RateLimitQueryPerMinute, conf.RateLimitQueryBurst),
+			ast.OperationTypeMutation: pkg_ratelimit.NewTokenBucketLimiter(conf.RateLimitMutationPerMinute, conf.RateLimitMutationBurst),
+		},
+	}
+}
+
+// レート制限のキーを取得(認証済みの場合はユーザーID、未認証の場合はIPアドレス)
+func ClientKey(userId string, ipAddress string) string {
+	if userId != "" {
+		return "user:" + userId
+	}
+	return "ip:" + ipAddress
+}
+
+// オペレーションの種類ごとの数だけトークンを消費できるか判定し、できる場合は消費する
+// 全ての種類で消費できる場合のみ消費し、拒否した場合はいずれのトークンも消費しない。
+// レート制限のヘッダーを設定し、拒否した場合は429のレスポンスを返す(戻り値はfalse)。
+func (m *GraphQLRateLimiter) Allow(c echo.Context, key string, counts map[string]int) (bool, error) {
+	charges := []pkg_ratelimit.Charge{}
+	operationTypes := []string{}
+	for _, operationType := range rateLimitedOperationTypes {
+		if n := counts[operationType]; n > 0 {
+			charges = append(charges, pkg_ratelimit.Charge{Limiter: m.limiters[operationType], Key: operationType + ":" + key, N: n})
+			operationTypes = append(operationTypes, operationType)
+		}
+	}
+	if len(charges) == 0 {
+		return true, nil
+	}
+
+	results, allowed := pkg_ratelimit.AllowAll(charges)
+	if allowed {
+		// 残りが最も少ない種類をヘッダーに設定する
+		header := results[0]
+		for _, result := range results[1:] {
+			if result.Remaining < header.Remaining {
+				header = result
+			}
+		}
+		setRateLimitHeaders(c, header)
+		return true, nil
+	}
+
+	// 不足した種類のうち、最も待ち時間が長いものをヘッダーに設定する
+	var rejected pkg_ratelimit.Result
+	for i, result := range results {
+		if result.RetryAfter > 0 {
+			m.Logger.WarnLog.Printf("Rate limit exceeded: %s (%s)", key, operationTypes[i])
+		}
+		if result.RetryAfter > rejected.RetryAfter {
+			rejected = result
+		}
+	}
+	setRateLimitHeaders(c, rejected)
+	retryAfter := int(math.Ceil(rejected.RetryAfter.Seconds()))
+	c.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	return false, c.JSON(http.StatusTooManyRequests, map[string]interface{}{
+		"errors": []map[string]interface{}{
+			{
+				"message":    "rate limit exceeded",
+				"extensions": map[string]interface{}{"code": "RATE_LIMITED", "retryAfter": retryAfter},
+			},
+		},
+	})
+}
+
+// レート制限のヘッダーを設定
+func setRateLimitHeaders(c echo.Context, result pkg_ratelimit.Result) {
+	header := c.Response().Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.Reset.Seconds()))))
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"backend/config"
+	pkg_logger "backend/internal/pkg/logger"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/labstack/echo/v4"
+)
+
+// テスト用のロガー(出力しない)
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+func TestGraphQLRateLimiterAllow(t *testing.T) {
+	m := NewGraphQLRateLimiter(newTestLogger(), &config.AppConfig{
+		RateLimitQueryPerMinute:    60,
+		RateLimitQueryBurst:        5,
+		RateLimitMutationPerMinute: 60,
+		RateLimitMutationBurst:     1,
+	})
+	key := ClientKey("user-1", "192.0.2.1")
+
+	allow := func(counts map[string]int) (bool, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/graphql", nil), rec)
+		allowed, err := m.Allow(c, key, counts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return allowed, rec
+	}
+
+	// ミューテーションが不足する場合は、クエリのトークンも消費しない
+	allowed, rec := allow(map[string]int{ast.OperationTypeQuery: 4, ast.OperationTypeMutation: 2})
+	if allowed {
+		t.Fatal("expected rejected")
+	}
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("got status %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	allowed, rec = allow(map[string]int{ast.OperationTypeQuery: 5})
+	if !allowed {
+		t.Fatal("expected query budget to be untouched by rejected request")
+	}
+	if rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("RateLimit-Remaining = %q, want 0", rec.Header().Get("RateLimit-Remaining"))
+	}
+
+	// オペレーションが無い場合は消費しない
+	if allowed, _ := allow(map[string]int{}); !allowed {
+		t.Error("expected empty request to be allowed")
+	}
+
+	// ユーザーごとに独立している
+	if ClientKey("", "192.0.2.1") != "ip:192.0.2.1" || key != "user:user-1" {
+		t.Errorf("unexpected client keys: %q, %q", ClientKey("", "192.0.2.1"), key)
+	}
+}
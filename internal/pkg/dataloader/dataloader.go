@@ -0,0 +1,78 @@
+package pkg_dataloader
+
+import (
+	"sync"
+)
+
+// バッチ取得関数
+// 渡されたキーに対応する値をまとめて取得し、キーごとのマップで返す。
+type BatchFunc[K comparable, V any] func(keys []K) (map[K]V, error)
+
+// データローダー
+// 1リクエスト内で要求されたキーを溜めておき、最初に値が必要になった時点で
+// バッチ取得関数を1回だけ呼び出す。取得済みの値はキャッシュする。
+type Loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	batchFn BatchFunc[K, V]
+	pending []K
+	queued  map[K]struct{}
+	cache   map[K]V
+	errs    map[K]error
+}
+
+// データローダーのインスタンス化
+func NewLoader[K comparable, V any](fn BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		batchFn: fn,
+		queued:  map[K]struct{}{},
+		cache:   map[K]V{},
+		errs:    map[K]error{},
+	}
+}
+
+// キーを登録し、値を取得するサンク(遅延評価関数)を返す
+// graphql-goはリゾルバが返したサンクを幅優先で評価するため、
+// 同じ階層で登録されたキーは1回のバッチ取得にまとめられる。
+func (l *Loader[K, V]) Load(key K) func() (V, error) {
+	l.mu.Lock()
+	_, cached := l.cache[key]
+	_, failed := l.errs[key]
+	_, queued := l.queued[key]
+	if !cached && !failed && !queued {
+		l.pending = append(l.pending, key)
+		l.queued[key] = struct{}{}
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, ok := l.queued[key]; ok {
+			l.dispatch()
+		}
+		if err, ok := l.errs[key]; ok {
+			var zero V
+			return zero, err
+		}
+		return l.cache[key], nil
+	}
+}
+
+// 溜まっているキーをまとめて取得する(ロック取得済みで呼び出すこと)
+func (l *Loader[K, V]) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	for _, k := range keys {
+		delete(l.queued, k)
+	}
+
+	values, err := l.batchFn(keys)
+	for _, k := range keys {
+		if err != nil {
+			l.errs[k] = err
+			continue
+		}
+		l.cache[k] = values[k]
+	}
+}
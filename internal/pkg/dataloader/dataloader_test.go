@@ -0,0 +1,144 @@
+package pkg_dataloader
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// 呼び出しを記録するバッチ取得関数
+type recordingBatch struct {
+	mu    sync.Mutex
+	calls [][]string
+	err   error
+}
+
+func (b *recordingBatch) fetch(keys []string) (map[string]int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, append([]string{}, keys...))
+	if b.err != nil {
+		return nil, b.err
+	}
+	values := map[string]int{}
+	for _, k := range keys {
+		if k == "missing" {
+			continue
+		}
+		values[k] = len(k)
+	}
+	return values, nil
+}
+
+// 同じ階層で登録したキーは1回のバッチ取得にまとめる
+func TestLoaderBatchesKeys(t *testing.T) {
+	batch := &recordingBatch{}
+	loader := NewLoader(batch.fetch)
+
+	thunks := []func() (int, error){
+		loader.Load("a"),
+		loader.Load("bb"),
+		loader.Load("a"),
+		loader.Load("ccc"),
+	}
+	want := []int{1, 2, 1, 3}
+	for i, thunk := range thunks {
+		v, err := thunk()
+		if err != nil || v != want[i] {
+			t.Errorf("thunks[%d]() = (%d, %v), want (%d, nil)", i, v, err, want[i])
+		}
+	}
+
+	if len(batch.calls) != 1 {
+		t.Fatalf("batch called %d times, want 1", len(batch.calls))
+	}
+	if got := batch.calls[0]; len(got) != 3 || got[0] != "a" || got[1] != "bb" || got[2] != "ccc" {
+		t.Errorf("batch keys = %v, want [a bb ccc] without duplicates", got)
+	}
+}
+
+// 取得済みの値はキャッシュし、新しいキーのみ次のバッチで取得する
+func TestLoaderCachesValues(t *testing.T) {
+	batch := &recordingBatch{}
+	loader := NewLoader(batch.fetch)
+
+	if v, _ := loader.Load("a")(); v != 1 {
+		t.Fatalf("Load(a) = %d, want 1", v)
+	}
+	first := loader.Load("a")
+	second := loader.Load("dddd")
+	if v, _ := first(); v != 1 {
+		t.Errorf("Load(a) = %d, want 1", v)
+	}
+	if v, _ := second(); v != 4 {
+		t.Errorf("Load(dddd) = %d, want 4", v)
+	}
+
+	if len(batch.calls) != 2 {
+		t.Fatalf("batch called %d times, want 2", len(batch.calls))
+	}
+	if got := batch.calls[1]; len(got) != 1 || got[0] != "dddd" {
+		t.Errorf("second batch keys = %v, want [dddd]", got)
+	}
+}
+
+// バッチ取得の結果に含まれないキーはゼロ値を返す
+func TestLoaderMissingKeyReturnsZero(t *testing.T) {
+	batch := &recordingBatch{}
+	loader := NewLoader(batch.fetch)
+
+	v, err := loader.Load("missing")()
+	if err != nil || v != 0 {
+		t.Errorf("Load(missing) = (%d, %v), want (0, nil)", v, err)
+	}
+}
+
+// バッチ取得のエラーは同じバッチの全てのキーに返し、再取得しない
+func TestLoaderPropagatesErrors(t *testing.T) {
+	batch := &recordingBatch{err: errors.New("batch failed")}
+	loader := NewLoader(batch.fetch)
+
+	first := loader.Load("a")
+	second := loader.Load("bb")
+	for _, thunk := range []func() (int, error){first, second} {
+		if _, err := thunk(); err == nil || err.Error() != "batch failed" {
+			t.Errorf("error = %v, want batch failed", err)
+		}
+	}
+	if _, err := loader.Load("a")(); err == nil {
+		t.Error("Load(a) after failure: want error")
+	}
+	if len(batch.calls) != 1 {
+		t.Errorf("batch called %d times, want 1", len(batch.calls))
+	}
+}
+
+// 複数のゴルーチンから同時に呼び出しても、各キーを1回だけ取得する
+func TestLoaderConcurrentLoads(t *testing.T) {
+	batch := &recordingBatch{}
+	loader := NewLoader(batch.fetch)
+
+	keys := []string{"a", "bb", "ccc", "dddd"}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := keys[i%len(keys)]
+			if v, err := loader.Load(key)(); err != nil || v != len(key) {
+				t.Errorf("Load(%s) = (%d, %v), want (%d, nil)", key, v, err, len(key))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	fetched := []string{}
+	for _, call := range batch.calls {
+		fetched = append(fetched, call...)
+	}
+	sort.Strings(fetched)
+	if len(fetched) != len(keys) {
+		t.Errorf("fetched keys = %v, want each of %v once", fetched, keys)
+	}
+}
@@ -0,0 +1,330 @@
+package pkg_fracindex
+
+import (
+	"errors"
+	"strings"
+)
+
+// キーに使う文字(バイト順に並べたbase62)
+// キーは整数部と小数部からなり、文字列の比較で大小を判定する。
+//   - 整数部: 先頭の1文字で桁数を表す可変長の整数('a'〜'z'は0以上で2〜27文字、'A'〜'Z'は負で27〜2文字)
+//   - 小数部: 整数部の間に挿入する場合のみ付く0以上1未満の小数(base62)。同じ値が複数の表現にならないよう、末尾は'0'にしない。
+//
+// 末尾に追加する場合は整数部を1つ増やすだけのため、キーの長さはキーの数の対数程度に収まる。
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// 最初のキー(整数部の0)
+const zeroKey = "a0"
+
+// 整数部の最小値(これより前に挿入できなくなるため、キーとしては使わない)
+var smallestInteger = "A" + strings.Repeat(digits[:1], 26)
+
+// キーが有効かどうかを検証
+func Validate(key string) error {
+	if key == "" {
+		return errors.New("position key is empty")
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return errors.New("invalid position key")
+		}
+	}
+	if key == smallestInteger {
+		return errors.New("invalid position key")
+	}
+	integer, err := integerPart(key)
+	if err != nil {
+		return err
+	}
+	if len(key) > len(integer) && key[len(key)-1] == digits[0] {
+		return errors.New("invalid position key")
+	}
+	return nil
+}
+
+// 2つのキーの間に並ぶキーを生成
+// aが空の場合は先頭、bが空の場合は末尾として扱う(両方空の場合は最初のキーを返す)。
+func KeyBetween(a string, b string) (string, error) {
+	if a != "" {
+		if err := Validate(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if err := Validate(b); err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", errors.New("position keys out of order")
+	}
+
+	switch {
+	case a == "" && b == "":
+		return zeroKey, nil
+
+	case a == "":
+		// 先頭に追加する場合は整数部を1つ減らす
+		integerB, _ := integerPart(b)
+		fractionB := b[len(integerB):]
+		if integerB == smallestInteger {
+			return integerB + midpoint("", fractionB), nil
+		}
+		if integerB < b {
+			return integerB, nil
+		}
+		decremented, ok := decrementInteger(integerB)
+		if !ok {
+			return "", errors.New("position key out of range")
+		}
+		return decremented, nil
+
+	case b == "":
+		// 末尾に追加する場合は整数部を1つ増やす
+		integerA, _ := integerPart(a)
+		fractionA := a[len(integerA):]
+		incremented, ok := incrementInteger(integerA)
+		if !ok {
+			return integerA + midpoint(fractionA, ""), nil
+		}
+		return incremented, nil
+	}
+
+	// 整数部が同じ場合は小数部の中間にする
+	integerA, _ := integerPart(a)
+	fractionA := a[len(integerA):]
+	integerB, _ := integerPart(b)
+	fractionB := b[len(integerB):]
+	if integerA == integerB {
+		return integerA + midpoint(fractionA, fractionB), nil
+	}
+
+	// 整数部が異なる場合は、aの次の整数がbより前ならそれを使う
+	incremented, ok := incrementInteger(integerA)
+	if !ok {
+		return "", errors.New("position key out of range")
+	}
+	if incremented < b {
+		return incremented, nil
+	}
+	return integerA + midpoint(fractionA, ""), nil
+}
+
+// 2つのキーの間に昇順に並ぶn個のキーを生成(一括で追加する場合に使う)
+// 末尾・先頭に追加する場合は整数部を順に増減し、それ以外は中間のキーで再帰的に区間を分割する。
+func KeysBetween(a string, b string, n int) ([]string, error) {
+	if n <= 0 {
+		return []string{}, nil
+	}
+	if n == 1 {
+		key, err := KeyBetween(a, b)
+		if err != nil {
+			return nil, err
+		}
+		return []string{key}, nil
+	}
+
+	// 末尾に追加する
+	if b == "" {
+		keys := make([]string, 0, n)
+		key := a
+		for i := 0; i < n; i++ {
+			next, err := KeyBetween(key, "")
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, next)
+			key = next
+		}
+		return keys, nil
+	}
+
+	// 先頭に追加する
+	if a == "" {
+		keys := make([]string, n)
+		key := b
+		for i := n - 1; i >= 0; i-- {
+			prev, err := KeyBetween("", key)
+			if err != nil {
+				return nil, err
+			}
+			keys[i] = prev
+			key = prev
+		}
+		return keys, nil
+	}
+
+	mid, err := KeyBetween(a, b)
+	if err != nil {
+		return nil, err
+	}
+	left, err := KeysBetween(a, mid, (n-1)/2)
+	if err != nil {
+		return nil, err
+	}
+	right, err := KeysBetween(mid, b, n-1-(n-1)/2)
+	if err != nil {
+		return nil, err
+	}
+	keys := append(left, mid)
+	return append(keys, right...), nil
+}
+
+// n個のキーを等間隔に生成(再配置用、最初のキーから連続する整数にする)
+func EvenlySpaced(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	keys, err := KeysBetween("", "", n)
+	if err != nil {
+		return nil
+	}
+	return keys
+}
+
+// キーの整数部を取得
+func integerPart(key string) (string, error) {
+	length, err := integerLength(key[0])
+	if err != nil {
+		return "", err
+	}
+	if length > len(key) {
+		return "", errors.New("invalid position key")
+	}
+	return key[:length], nil
+}
+
+// 整数部の先頭の文字から整数部の文字数を求める
+func integerLength(head byte) (int, error) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, nil
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, nil
+	default:
+		return 0, errors.New("invalid position key")
+	}
+}
+
+// 整数部を1つ増やす(最大値の場合はfalse)
+func incrementInteger(integer string) (string, bool) {
+	head := integer[0]
+	rest := []byte(integer[1:])
+
+	// 下の桁から繰り上げる
+	carry := true
+	for i := len(rest) - 1; carry && i >= 0; i-- {
+		d := strings.IndexByte(digits, rest[i]) + 1
+		if d == base {
+			rest[i] = digits[0]
+		} else {
+			rest[i] = digits[d]
+			carry = false
+		}
+	}
+	if !carry {
+		return string(head) + string(rest), true
+	}
+
+	// 桁数が変わる場合は先頭の文字を進める
+	switch head {
+	case 'Z':
+		return zeroKey, true
+	case 'z':
+		return "", false
+	}
+	head++
+	if head > 'a' {
+		rest = append(rest, digits[0])
+	} else {
+		rest = rest[:len(rest)-1]
+	}
+	return string(head) + string(rest), true
+}
+
+// 整数部を1つ減らす(最小値の場合はfalse)
+func decrementInteger(integer string) (string, bool) {
+	head := integer[0]
+	rest := []byte(integer[1:])
+
+	// 下の桁から繰り下げる
+	borrow := true
+	for i := len(rest) - 1; borrow && i >= 0; i-- {
+		d := strings.IndexByte(digits, rest[i]) - 1
+		if d == -1 {
+			rest[i] = digits[base-1]
+		} else {
+			rest[i] = digits[d]
+			borrow = false
+		}
+	}
+	if !borrow {
+		return string(head) + string(rest), true
+	}
+
+	// 桁数が変わる場合は先頭の文字を戻す
+	switch head {
+	case 'a':
+		return "Z" + digits[base-1:], true
+	case 'A':
+		return "", false
+	}
+	head--
+	if head < 'Z' {
+		rest = append(rest, digits[base-1])
+	} else {
+		rest = rest[:len(rest)-1]
+	}
+	return string(head) + string(rest), true
+}
+
+// a < bを満たす2つの小数部の中間の小数部を生成(bが空の場合は1として扱う)
+func midpoint(a string, b string) string {
+	if b != "" {
+		// 共通の接頭辞はそのまま残す(aが短い場合は'0'で埋めて比較する)
+		n := 0
+		for n < len(b) && digitAt(a, n) == strings.IndexByte(digits, b[n]) {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	digitA := digitAt(a, 0)
+	digitB := base
+	if b != "" {
+		digitB = strings.IndexByte(digits, b[0])
+	}
+
+	// 先頭の桁の間に隙間がある場合は中間の1桁にする
+	if digitB-digitA > 1 {
+		return string(digits[(digitA+digitB)/2])
+	}
+
+	// 先頭の桁が隣り合っていて、bが2桁以上の場合はbの先頭の桁だけにする
+	if b != "" && len(b) > 1 {
+		return b[:1]
+	}
+
+	// aの先頭の桁を残し、次の桁以降でaと末尾の間を求める
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[digitA]) + midpoint(rest, "")
+}
+
+// キーのi桁目の値(キーより長い位置は0)
+func digitAt(key string, i int) int {
+	if i >= len(key) {
+		return 0
+	}
+	return strings.IndexByte(digits, key[i])
+}
@@ -0,0 +1,214 @@
+package pkg_fracindex
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestKeyBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{a: "", b: "", want: "a0"},
+		{a: "a0", b: "", want: "a1"},
+		{a: "a1", b: "", want: "a2"},
+		{a: "az", b: "", want: "b00"},
+		{a: "Zz", b: "", want: "a0"},
+		{a: "a0V", b: "", want: "a1"},
+		{a: "", b: "a0", want: "Zz"},
+		{a: "", b: "Zz", want: "Zy"},
+		{a: "", b: "b00", want: "az"},
+		{a: "", b: "a0V", want: "a0"},
+		{a: "a0", b: "a1", want: "a0V"},
+		{a: "a1", b: "a2", want: "a1V"},
+		{a: "a0V", b: "a1", want: "a0k"},
+		{a: "Zz", b: "a0", want: "ZzV"},
+		{a: "Zz", b: "a01", want: "a0"},
+		{a: "a0", b: "a0V", want: "a0F"},
+		{a: "b125", b: "b129", want: "b127"},
+		{a: "a0", b: "a0001", want: "a0000V"},
+	}
+
+	for _, tt := range tests {
+		got, err := KeyBetween(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("KeyBetween(%q, %q): unexpected error: %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("KeyBetween(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+		if err := Validate(got); err != nil {
+			t.Errorf("KeyBetween(%q, %q) = %q is invalid: %v", tt.a, tt.b, got, err)
+		}
+	}
+}
+
+func TestKeyBetweenErrors(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{a: "a1", b: "a0"},
+		{a: "a0", b: "a0"},
+		{a: "a00", b: ""},
+		{a: "", b: "a0V0"},
+		{a: "a", b: ""},
+		{a: "0", b: ""},
+		{a: "a0-", b: ""},
+		{a: "", b: "A00000000000000000000000000"},
+	}
+
+	for _, tt := range tests {
+		if got, err := KeyBetween(tt.a, tt.b); err == nil {
+			t.Errorf("KeyBetween(%q, %q) = %q, want error", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{"a0", "a1", "Zz", "b00", "a0V", "zzzzzzzzzzzzzzzzzzzzzzzzzzz", "A00000000000000000000000001"}
+	for _, key := range valid {
+		if err := Validate(key); err != nil {
+			t.Errorf("Validate(%q): unexpected error: %v", key, err)
+		}
+	}
+
+	invalid := []string{"", "a", "b0", "a00", "a0V0", "0", "V", "a0_", "A00000000000000000000000000"}
+	for _, key := range invalid {
+		if err := Validate(key); err == nil {
+			t.Errorf("Validate(%q): want error", key)
+		}
+	}
+}
+
+// 末尾への追加を繰り返しても、キーの長さはキーの数の対数程度に収まる
+func TestKeyBetweenAppendStaysShort(t *testing.T) {
+	key := ""
+	for i := 0; i < 100000; i++ {
+		next, err := KeyBetween(key, "")
+		if err != nil {
+			t.Fatalf("append %d: unexpected error: %v", i, err)
+		}
+		if key != "" && next <= key {
+			t.Fatalf("append %d: %q is not after %q", i, next, key)
+		}
+		key = next
+	}
+	// 62^3 > 100000のため、整数部は4文字に収まる
+	if len(key) > 4 {
+		t.Errorf("key after 100000 appends = %q (length %d), want at most 4", key, len(key))
+	}
+
+	key = ""
+	for i := 0; i < 100000; i++ {
+		prev, err := KeyBetween("", key)
+		if err != nil {
+			t.Fatalf("prepend %d: unexpected error: %v", i, err)
+		}
+		if key != "" && prev >= key {
+			t.Fatalf("prepend %d: %q is not before %q", i, prev, key)
+		}
+		key = prev
+	}
+	if len(key) > 4 {
+		t.Errorf("key after 100000 prepends = %q (length %d), want at most 4", key, len(key))
+	}
+}
+
+// ランダムな位置への挿入を繰り返しても順序が保たれる
+func TestKeyBetweenRandomInserts(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	keys := []string{}
+	for i := 0; i < 2000; i++ {
+		at := r.Intn(len(keys) + 1)
+		lower, upper := "", ""
+		if at > 0 {
+			lower = keys[at-1]
+		}
+		if at < len(keys) {
+			upper = keys[at]
+		}
+		key, err := KeyBetween(lower, upper)
+		if err != nil {
+			t.Fatalf("KeyBetween(%q, %q): unexpected error: %v", lower, upper, err)
+		}
+		if (lower != "" && key <= lower) || (upper != "" && key >= upper) {
+			t.Fatalf("KeyBetween(%q, %q) = %q is out of range", lower, upper, key)
+		}
+		if err := Validate(key); err != nil {
+			t.Fatalf("KeyBetween(%q, %q) = %q is invalid: %v", lower, upper, key, err)
+		}
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Error("keys are not sorted")
+	}
+}
+
+func TestKeysBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		n    int
+	}{
+		{name: "append", a: "a5", b: "", n: 100},
+		{name: "prepend", a: "", b: "a0", n: 100},
+		{name: "between", a: "a0", b: "a1", n: 100},
+		{name: "from empty", a: "", b: "", n: 10},
+		{name: "none", a: "a0", b: "a1", n: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := KeysBetween(tt.a, tt.b, tt.n)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(keys) != tt.n {
+				t.Fatalf("got %d keys, want %d", len(keys), tt.n)
+			}
+			for i, key := range keys {
+				if err := Validate(key); err != nil {
+					t.Errorf("keys[%d] = %q is invalid: %v", i, key, err)
+				}
+				if (tt.a != "" && key <= tt.a) || (tt.b != "" && key >= tt.b) {
+					t.Errorf("keys[%d] = %q is out of range", i, key)
+				}
+				if i > 0 && key <= keys[i-1] {
+					t.Errorf("keys[%d] = %q is not after %q", i, key, keys[i-1])
+				}
+			}
+		})
+	}
+
+	// 末尾への一括追加は連続する整数になる
+	keys, _ := KeysBetween("a5", "", 3)
+	if want := []string{"a6", "a7", "a8"}; keys[0] != want[0] || keys[1] != want[1] || keys[2] != want[2] {
+		t.Errorf("KeysBetween(a5, \"\", 3) = %v, want %v", keys, want)
+	}
+}
+
+func TestEvenlySpaced(t *testing.T) {
+	if keys := EvenlySpaced(0); keys != nil {
+		t.Errorf("EvenlySpaced(0) = %v, want nil", keys)
+	}
+
+	// 2文字のキーが62個、3文字のキーが62^2個
+	keys := EvenlySpaced(3906)
+	if len(keys) != 3906 {
+		t.Fatalf("got %d keys, want 3906", len(keys))
+	}
+	if keys[0] != "a0" || keys[61] != "az" || keys[62] != "b00" || keys[3905] != "bzz" {
+		t.Errorf("unexpected keys: %q, %q, %q, %q", keys[0], keys[61], keys[62], keys[3905])
+	}
+	for i, key := range keys {
+		if len(key) > 3 {
+			t.Errorf("keys[%d] = %q is longer than 3", i, key)
+		}
+		if i > 0 && key <= keys[i-1] {
+			t.Fatalf("keys[%d] = %q is not after %q", i, key, keys[i-1])
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package pkg_ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 1行の最大オクテット数(これを超える行は折り返す)
+const maxLineOctets = 75
+
+// 日時の形式
+const (
+	dateTimeFormat    = "20060102T150405Z"
+	localDateTimeForm = "20060102T150405"
+	dateFormat        = "20060102"
+)
+
+// プロパティ
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// コンポーネント(VCALENDAR / VTODO / VEVENT / VALARMなど)
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []Component
+}
+
+// 最初に一致するプロパティを取得(存在しない場合はnil)
+func (c Component) Get(name string) *Property {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// RFC 5545のiCalendar形式の書き込み
+// 行はCRLFで区切り、75オクテットを超える行は折り返す。
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// 書き込みのインスタンス化
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// コンポーネントを開始
+func (w *Writer) Begin(name string) {
+	w.writeLine("BEGIN:" + name)
+}
+
+// コンポーネントを終了
+func (w *Writer) End(name string) {
+	w.writeLine("END:" + name)
+}
+
+// プロパティを書き込む(値はエスケープ済みのものを渡す、パラメータは"KEY=VALUE"の形式)
+func (w *Writer) Property(name string, value string, params ...string) {
+	line := name
+	for _, p := range params {
+		line += ";" + p
+	}
+	w.writeLine(line + ":" + value)
+}
+
+// テキストのプロパティを書き込む(値をエスケープする)
+func (w *Writer) Text(name string, value string) {
+	w.Property(name, EscapeText(value))
+}
+
+// 日時のプロパティをUTCで書き込む
+func (w *Writer) DateTime(name string, t time.Time) {
+	w.Property(name, FormatDateTime(t))
+}
+
+// バッファを書き出し、書き込み中に発生した最初のエラーを返す
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.w.Flush()
+	return w.err
+}
+
+// 1行を書き込む(75オクテットを超える場合は、文字の途中で切らないように折り返す)
+func (w *Writer) writeLine(line string) {
+	if w.err != nil {
+		return
+	}
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, w.err = w.w.WriteString(line[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		line = line[cut:]
+		// 継続行は先頭の空白を含めて75オクテットにする
+		limit = maxLineOctets - 1
+	}
+	_, w.err = w.w.WriteString(line + "\r\n")
+}
+
+// テキストの値をエスケープ
+func EscapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return r.Replace(s)
+}
+
+// テキストの値のエスケープを戻す
+func UnescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// 日時をUTCの形式に変換
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// 日付の形式に変換
+func FormatDate(t time.Time) string {
+	return t.Format(dateFormat)
+}
+
+// 日時のプロパティを解析
+// UTC(末尾がZ)、TZIDを指定した現地時刻、タイムゾーンなしの現地時刻(locで解釈)、VALUE=DATEの日付に対応する。
+func ParseDateTime(p Property, loc *time.Location) (time.Time, error) {
+	value := strings.TrimSpace(p.Value)
+	if p.Params["VALUE"] == "DATE" || len(value) == len(dateFormat) {
+		return time.ParseInLocation(dateFormat, value, loc)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(dateTimeFormat, value)
+	}
+	if tzid := p.Params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(strings.Trim(tzid, `"`))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown TZID: %s", tzid)
+		}
+		loc = l
+	}
+	return time.ParseInLocation(localDateTimeForm, value, loc)
+}
+
+// 期間の値を解析(例: -PT15M, P1DT2H, P1W)
+func ParseDuration(value string) (time.Duration, error) {
+	s := strings.TrimSpace(value)
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	// Tの後に時・分・秒が1つも無い場合(PT、P1DTなど)は不正とする
+	timeUnits := 0
+	n := -1
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(r-'0')
+			continue
+		case r == 'T' && !inTime && n < 0:
+			inTime = true
+			continue
+		}
+		if n < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		unit := time.Duration(0)
+		switch {
+		case r == 'W' && !inTime:
+			unit = 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration: %s", value)
+		}
+		d += time.Duration(n) * unit
+		n = -1
+		if inTime {
+			timeUnits++
+		}
+	}
+	if n >= 0 || (inTime && timeUnits == 0) {
+		return 0, fmt.Errorf("invalid duration: %s", value)
+	}
+	return sign * d, nil
+}
+
+// iCalendar形式を解析し、最上位のコンポーネントを返す
+func Parse(r io.Reader) ([]Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := []Component{}
+	stack := []*Component{}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		switch p.Name {
+		case "BEGIN":
+			stack = append(stack, &Component{Name: strings.ToUpper(p.Value)})
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("unexpected END:%s", p.Value)
+			}
+			c := *stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				roots = append(roots, c)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, c)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, errors.New("property outside of component")
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, p)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	return roots, nil
+}
+
+// 折り返された行を戻す(CRLF・LFのどちらの改行にも対応する)
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lines := []string{}
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// 1行をプロパティに分解(名前;パラメータ:値)
+func parseLine(line string) (Property, error) {
+	// 値の区切りのコロンを探す(引用符内のコロン・セミコロンは区切りとみなさない)
+	quoted := false
+	colon := -1
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+		if colon >= 0 {
+			break
+		}
+	}
+	if colon < 0 {
+		return Property{}, fmt.Errorf("invalid content line: %q", line)
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := splitParams(head)
+	p := Property{Name: strings.ToUpper(parts[0]), Params: map[string]string{}, Value: value}
+	if p.Name == "" {
+		return Property{}, fmt.Errorf("invalid content line: %q", line)
+	}
+	for _, param := range parts[1:] {
+		key, v, ok := strings.Cut(param, "=")
+		if !ok {
+			return Property{}, fmt.Errorf("invalid parameter: %q", param)
+		}
+		p.Params[strings.ToUpper(key)] = strings.Trim(v, `"`)
+	}
+	return p, nil
+}
+
+// 名前とパラメータをセミコロンで分割(引用符内は分割しない)
+func splitParams(head string) []string {
+	parts := []string{}
+	quoted := false
+	start := 0
+	for i := 0; i < len(head); i++ {
+		switch head[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				parts = append(parts, head[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, head[start:])
+}
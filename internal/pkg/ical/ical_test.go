@@ -0,0 +1,303 @@
+package pkg_ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWriterFoldsLongLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "short", value: "短い"},
+		{name: "ascii", value: strings.Repeat("a", 200)},
+		{name: "multibyte", value: strings.Repeat("あ", 100)},
+		{name: "mixed", value: strings.Repeat("a", 70) + strings.Repeat("😀", 30)},
+		{name: "exact", value: strings.Repeat("b", maxLineOctets-len("SUMMARY:"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(&buf)
+			w.Text("SUMMARY", tt.value)
+			if err := w.Flush(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+			physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range physical {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d has %d octets, want at most %d", i, len(line), maxLineOctets)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a character: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+			}
+			if tt.name == "exact" && len(physical) != 1 {
+				t.Errorf("a line of exactly %d octets was folded into %d lines", maxLineOctets, len(physical))
+			}
+
+			// 折り返しを戻すと元の値になる
+			components, err := Parse(strings.NewReader("BEGIN:VTODO\r\n" + out + "END:VTODO\r\n"))
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if got := UnescapeText(components[0].Get("SUMMARY").Value); got != tt.value {
+				t.Errorf("unfolded value = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "plain", want: "plain"},
+		{in: `a\b`, want: `a\\b`},
+		{in: "a;b,c", want: `a\;b\,c`},
+		{in: "line1\nline2", want: `line1\nline2`},
+		{in: "line1\r\nline2", want: `line1\nline2`},
+		{in: "line1\rline2", want: `line1\nline2`},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeText(tt.in); got != tt.want {
+			t.Errorf("EscapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnescapeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: `a\\b`, want: `a\b`},
+		{in: `a\;b\,c`, want: "a;b,c"},
+		{in: `line1\nline2\Nline3`, want: "line1\nline2\nline3"},
+		{in: `trailing\`, want: `trailing\`},
+	}
+
+	for _, tt := range tests {
+		if got := UnescapeText(tt.in); got != tt.want {
+			t.Errorf("UnescapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	// エスケープして戻すと元の値になる(CRLFはLFになる)
+	for _, s := range []string{"", "メモ; 1, 2\\3\n次の行", `\n`, "a,b;c"} {
+		if got := UnescapeText(EscapeText(s)); got != s {
+			t.Errorf("UnescapeText(EscapeText(%q)) = %q", s, got)
+		}
+	}
+}
+
+func TestWriterAndParseRoundTrip(t *testing.T) {
+	due := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("JST", 9*60*60))
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Begin("VCALENDAR")
+	w.Property("VERSION", "2.0")
+	w.Begin("VTODO")
+	w.Text("SUMMARY", "買い物; 牛乳, 卵")
+	w.DateTime("DUE", due)
+	w.Property("DTSTART", FormatDate(due), "VALUE=DATE")
+	w.Begin("VALARM")
+	w.Property("TRIGGER", "-PT15M")
+	w.End("VALARM")
+	w.End("VTODO")
+	w.End("VCALENDAR")
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	components, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if len(components) != 1 || components[0].Name != "VCALENDAR" {
+		t.Fatalf("unexpected root components: %+v", components)
+	}
+	calendar := components[0]
+	if v := calendar.Get("VERSION"); v == nil || v.Value != "2.0" {
+		t.Errorf("VERSION = %+v, want 2.0", v)
+	}
+	if len(calendar.Components) != 1 || calendar.Components[0].Name != "VTODO" {
+		t.Fatalf("unexpected calendar components: %+v", calendar.Components)
+	}
+
+	todo := calendar.Components[0]
+	if got := UnescapeText(todo.Get("SUMMARY").Value); got != "買い物; 牛乳, 卵" {
+		t.Errorf("SUMMARY = %q", got)
+	}
+	if got := todo.Get("DUE").Value; got != "20260303T200607Z" {
+		t.Errorf("DUE = %q, want 20260303T200607Z", got)
+	}
+	parsed, err := ParseDateTime(*todo.Get("DUE"), time.UTC)
+	if err != nil || !parsed.Equal(due) {
+		t.Errorf("ParseDateTime(DUE) = (%v, %v), want %v", parsed, err, due)
+	}
+	if start := todo.Get("DTSTART"); start == nil || start.Params["VALUE"] != "DATE" || start.Value != "20260304" {
+		t.Errorf("DTSTART = %+v", start)
+	}
+	if len(todo.Components) != 1 || todo.Components[0].Get("TRIGGER").Value != "-PT15M" {
+		t.Errorf("unexpected VALARM: %+v", todo.Components)
+	}
+	if todo.Get("MISSING") != nil {
+		t.Error("Get(MISSING) != nil")
+	}
+}
+
+func TestParseContentLines(t *testing.T) {
+	input := "begin:vtodo\n" +
+		"summary;LANGUAGE=ja:a:b\n" +
+		"DTSTART;TZID=\"Asia/Tokyo\";X-NOTE=\"a;b:c\":20260101T090000\n" +
+		"DESCRIPTION:folded \n" +
+		"\tvalue\n" +
+		"\n" +
+		"END:VTODO\n"
+
+	components, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	todo := components[0]
+	if todo.Name != "VTODO" {
+		t.Errorf("Name = %q, want VTODO", todo.Name)
+	}
+	// 値の中のコロンは区切りとみなさない
+	if p := todo.Get("SUMMARY"); p == nil || p.Value != "a:b" || p.Params["LANGUAGE"] != "ja" {
+		t.Errorf("SUMMARY = %+v", p)
+	}
+	// 引用符内のセミコロン・コロンは区切りとみなさない
+	if p := todo.Get("DTSTART"); p == nil || p.Params["TZID"] != "Asia/Tokyo" || p.Params["X-NOTE"] != "a;b:c" || p.Value != "20260101T090000" {
+		t.Errorf("DTSTART = %+v", p)
+	}
+	if p := todo.Get("DESCRIPTION"); p == nil || p.Value != "folded value" {
+		t.Errorf("DESCRIPTION = %+v", p)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "property outside component", input: "SUMMARY:a\r\n"},
+		{name: "missing end", input: "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VTODO\r\n"},
+		{name: "mismatched end", input: "BEGIN:VTODO\r\nEND:VEVENT\r\n"},
+		{name: "unexpected end", input: "END:VTODO\r\n"},
+		{name: "no colon", input: "BEGIN:VTODO\r\nSUMMARY\r\nEND:VTODO\r\n"},
+		{name: "empty name", input: "BEGIN:VTODO\r\n:value\r\nEND:VTODO\r\n"},
+		{name: "invalid parameter", input: "BEGIN:VTODO\r\nDUE;VALUE:20260101\r\nEND:VTODO\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(tt.input)); err == nil {
+				t.Error("want error")
+			}
+		})
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data is not available: %v", err)
+	}
+	newYork := time.FixedZone("EST", -5*60*60)
+
+	tests := []struct {
+		name string
+		p    Property
+		loc  *time.Location
+		want time.Time
+	}{
+		{name: "utc", p: Property{Value: "20260101T090000Z"}, loc: newYork, want: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{name: "tzid", p: Property{Params: map[string]string{"TZID": "Asia/Tokyo"}, Value: "20260101T090000"}, loc: newYork, want: time.Date(2026, 1, 1, 9, 0, 0, 0, tokyo)},
+		{name: "floating", p: Property{Value: "20260101T090000"}, loc: newYork, want: time.Date(2026, 1, 1, 9, 0, 0, 0, newYork)},
+		{name: "date", p: Property{Params: map[string]string{"VALUE": "DATE"}, Value: "20260101"}, loc: tokyo, want: time.Date(2026, 1, 1, 0, 0, 0, 0, tokyo)},
+		{name: "date without value parameter", p: Property{Value: "20260101"}, loc: time.UTC, want: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDateTime(tt.p, tt.loc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDateTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, p := range []Property{
+		{Params: map[string]string{"TZID": "Mars/Olympus"}, Value: "20260101T090000"},
+		{Value: "2026-01-01T09:00:00Z"},
+		{Value: "tomorrow"},
+	} {
+		if _, err := ParseDateTime(p, time.UTC); err == nil {
+			t.Errorf("ParseDateTime(%+v): want error", p)
+		}
+	}
+}
+
+func TestFormatDateTime(t *testing.T) {
+	at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	if got := FormatDateTime(at); got != "20260101T000000Z" {
+		t.Errorf("FormatDateTime() = %q, want 20260101T000000Z", got)
+	}
+	if got := FormatDate(at); got != "20260101" {
+		t.Errorf("FormatDate() = %q, want 20260101", got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{in: "PT15M", want: 15 * time.Minute},
+		{in: "-PT15M", want: -15 * time.Minute},
+		{in: "+PT1H", want: time.Hour},
+		{in: "P1D", want: 24 * time.Hour},
+		{in: "P1DT2H30M", want: 26*time.Hour + 30*time.Minute},
+		{in: "P2W", want: 14 * 24 * time.Hour},
+		{in: "PT90S", want: 90 * time.Second},
+		{in: " -P1DT1S ", want: -(24*time.Hour + time.Second)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if err != nil {
+			t.Errorf("ParseDuration(%q): unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "P", "15M", "PT", "PT15", "P1H", "PT1D", "PTT1H", "P1X", "PM", "P1DT"} {
+		if got, err := ParseDuration(in); err == nil {
+			t.Errorf("ParseDuration(%q) = %v, want error", in, got)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package pkg_ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// 使われなくなったバケットを削除する間隔
+const sweepInterval = 5 * time.Minute
+
+// レート制限の判定結果
+type Result struct {
+	// 許可されたかどうか
+	Allowed bool
+	// バケットの容量
+	Limit int
+	// 残りトークン数
+	Remaining int
+	// 次にリクエストできるまでの時間(許可された場合は0)
+	RetryAfter time.Duration
+	// バケットが満タンに戻るまでの時間
+	Reset time.Duration
+}
+
+// トークンバケット
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// トークンバケット方式のレート制限
+// キー(ユーザーID、IPアドレス等)ごとにバケットを持ち、一定の速度でトークンを補充する。
+type TokenBucketLimiter struct {
+	mu        sync.Mutex
+	capacity  float64
+	rate      float64 // 1秒あたりの補充数
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// トークンバケット方式のレート制限のインスタンス化
+// perMinuteは1分あたりの補充数、burstはバケットの容量(一度に許可する最大数)。
+func NewTokenBucketLimiter(perMinute int, burst int) *TokenBucketLimiter {
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &TokenBucketLimiter{
+		capacity:  float64(burst),
+		rate:      float64(perMinute) / 60,
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// n個のトークンを消費できるか判定し、できる場合は消費する
+func (l *TokenBucketLimiter) AllowN(key string, n int) Result {
+	results, _ := AllowAll([]Charge{{Limiter: l, Key: key, N: n}})
+	return results[0]
+}
+
+// トークンの消費要求
+type Charge struct {
+	Limiter *TokenBucketLimiter
+	Key     string
+	N       int
+}
+
+// 複数のバケットからまとめてトークンを消費する
+// 全てのバケットで消費できる場合のみ消費し、1つでも不足する場合はいずれも消費しない。
+// デッドロックを防ぐため、呼び出し側は常に同じ順序で異なるリミッターを指定すること。
+func AllowAll(charges []Charge) ([]Result, bool) {
+	for _, charge := range charges {
+		charge.Limiter.mu.Lock()
+		defer charge.Limiter.mu.Unlock()
+	}
+
+	// 全てのバケットを補充してから判定する
+	buckets := make([]*bucket, len(charges))
+	allowed := true
+	for i, charge := range charges {
+		buckets[i] = charge.Limiter.refill(charge.Key)
+		if buckets[i].tokens < float64(charge.N) {
+			allowed = false
+		}
+	}
+
+	results := make([]Result, len(charges))
+	for i, charge := range charges {
+		l, b := charge.Limiter, buckets[i]
+		result := Result{Limit: int(l.capacity)}
+		if allowed {
+			b.tokens -= float64(charge.N)
+			result.Allowed = true
+		} else if b.tokens < float64(charge.N) {
+			result.RetryAfter = l.durationFor(float64(charge.N) - b.tokens)
+		}
+		result.Remaining = int(math.Floor(b.tokens))
+		result.Reset = l.durationFor(l.capacity - b.tokens)
+		results[i] = result
+	}
+	return results, allowed
+}
+
+// バケットを取得し、経過時間分のトークンを補充する(ロック取得済みで呼び出すこと)
+func (l *TokenBucketLimiter) refill(key string) *bucket {
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.capacity, lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(l.capacity, b.tokens+elapsed*l.rate)
+	b.lastSeen = now
+	return b
+}
+
+// 指定したトークン数が補充されるまでの時間
+func (l *TokenBucketLimiter) durationFor(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	if l.rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(math.Ceil(tokens / l.rate * float64(time.Second)))
+}
+
+// 満タンに戻ったバケットを削除(ロック取得済みで呼び出すこと)
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.lastSeen).Seconds()*l.rate >= l.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package pkg_ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// 時刻を操作できるリミッターを生成
+func newTestLimiter(perMinute int, burst int, now *time.Time) *TokenBucketLimiter {
+	l := NewTokenBucketLimiter(perMinute, burst)
+	l.now = func() time.Time { return *now }
+	l.lastSweep = *now
+	return l
+}
+
+func TestTokenBucketAllowN(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newTestLimiter(60, 3, &now)
+
+	// バースト分は連続で許可される
+	for i := 0; i < 3; i++ {
+		if result := l.AllowN("a", 1); !result.Allowed {
+			t.Fatalf("request %d: expected allowed", i)
+		}
+	}
+	result := l.AllowN("a", 1)
+	if result.Allowed {
+		t.Fatal("expected rejected after burst")
+	}
+	if result.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", result.RetryAfter)
+	}
+	if result.Remaining != 0 || result.Limit != 3 {
+		t.Errorf("Remaining = %d, Limit = %d; want 0, 3", result.Remaining, result.Limit)
+	}
+
+	// キーごとに独立している
+	if result := l.AllowN("b", 3); !result.Allowed {
+		t.Error("expected other key to be allowed")
+	}
+
+	// 1秒で1トークン補充される
+	now = now.Add(time.Second)
+	if result := l.AllowN("a", 1); !result.Allowed {
+		t.Error("expected allowed after refill")
+	}
+	if result := l.AllowN("a", 1); result.Allowed {
+		t.Error("expected rejected before next refill")
+	}
+
+	// 容量を超えては補充されない
+	now = now.Add(time.Hour)
+	if result := l.AllowN("a", 4); result.Allowed {
+		t.Error("expected request larger than capacity to be rejected")
+	}
+	if result := l.AllowN("a", 3); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("expected full bucket, got %+v", result)
+	}
+}
+
+func TestAllowAllIsAllOrNothing(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	queries := newTestLimiter(60, 10, &now)
+	mutations := newTestLimiter(60, 1, &now)
+
+	charges := []Charge{
+		{Limiter: queries, Key: "u", N: 3},
+		{Limiter: mutations, Key: "u", N: 2},
+	}
+	results, allowed := AllowAll(charges)
+	if allowed {
+		t.Fatal("expected rejected when one bucket is short")
+	}
+	if results[0].RetryAfter != 0 || results[1].RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, %v; want 0, 1s", results[0].RetryAfter, results[1].RetryAfter)
+	}
+	// 拒否した場合はいずれのバケットも消費しない
+	if results[0].Remaining != 10 || results[1].Remaining != 1 {
+		t.Errorf("Remaining = %d, %d; want 10, 1", results[0].Remaining, results[1].Remaining)
+	}
+
+	charges[1].N = 1
+	results, allowed = AllowAll(charges)
+	if !allowed {
+		t.Fatal("expected allowed")
+	}
+	if results[0].Remaining != 7 || results[1].Remaining != 0 {
+		t.Errorf("Remaining = %d, %d; want 7, 0", results[0].Remaining, results[1].Remaining)
+	}
+}
+
+func TestTokenBucketSweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newTestLimiter(60, 1, &now)
+
+	l.AllowN("a", 1)
+	now = now.Add(sweepInterval)
+	l.AllowN("b", 1)
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("expected refilled bucket to be swept")
+	}
+	if _, ok := l.buckets["b"]; !ok {
+		t.Error("expected active bucket to remain")
+	}
+}
+
+func TestTokenBucketConcurrent(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewTokenBucketLimiter(0, 50)
+	l.now = func() time.Time { return now }
+
+	allowed := make(chan bool, 200)
+	done := make(chan struct{})
+	for i := 0; i < 200; i++ {
+		go func() {
+			allowed <- l.AllowN("a", 1).Allowed
+			done <- struct{}{}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		<-done
+	}
+	close(allowed)
+
+	count := 0
+	for ok := range allowed {
+		if ok {
+			count++
+		}
+	}
+	if count != 50 {
+		t.Errorf("allowed %d requests, want 50", count)
+	}
+}
@@ -0,0 +1,439 @@
+package pkg_rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 展開する周期の上限(条件に一致する日が無いルールで無限ループしないため)
+const maxPeriods = 50000
+
+// 繰り返しの頻度
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// 曜日の指定(Nが0以外の場合は、月内の第N週 / 負の場合は末尾から数える)
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// RFC 5545の繰り返しルール(RRULE)
+// FREQ / INTERVAL / COUNT / UNTIL / BYDAY / BYMONTHDAY / BYMONTH / WKST(MOのみ)に対応する。
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+// 曜日の略称
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RRULE文字列を解析("RRULE:"の接頭辞は省略可)
+func Parse(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.ToUpper(s), "RRULE:")
+	if s == "" {
+		return Rule{}, errors.New("rrule is empty")
+	}
+
+	rule := Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("invalid rrule part: %q", part)
+		}
+		if seen[key] {
+			return Rule{}, fmt.Errorf("duplicate rrule part: %s", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Frequency(value)
+			default:
+				return Rule{}, fmt.Errorf("unsupported FREQ: %s", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("invalid INTERVAL: %s", value)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("invalid COUNT: %s", value)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(v)
+				if err != nil {
+					return Rule{}, err
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return Rule{}, fmt.Errorf("invalid BYMONTHDAY: %s", v)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 || n > 12 {
+					return Rule{}, fmt.Errorf("invalid BYMONTH: %s", v)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			if value != "MO" {
+				return Rule{}, fmt.Errorf("unsupported WKST: %s", value)
+			}
+		default:
+			return Rule{}, fmt.Errorf("unsupported rrule part: %s", key)
+		}
+	}
+
+	// 組み合わせの検証
+	if rule.Freq == "" {
+		return Rule{}, errors.New("FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return Rule{}, errors.New("COUNT and UNTIL must not be used together")
+	}
+	for _, wd := range rule.ByDay {
+		if wd.N == 0 {
+			continue
+		}
+		if rule.Freq != Monthly && rule.Freq != Yearly {
+			return Rule{}, errors.New("numeric BYDAY is only allowed with MONTHLY or YEARLY")
+		}
+		if rule.Freq == Yearly && len(rule.ByMonth) == 0 {
+			return Rule{}, errors.New("numeric BYDAY with YEARLY requires BYMONTH")
+		}
+	}
+	if rule.Freq == Weekly && len(rule.ByMonthDay) > 0 {
+		return Rule{}, errors.New("BYMONTHDAY is not allowed with WEEKLY")
+	}
+
+	return rule, nil
+}
+
+// UNTILを解析(UTC・ローカル日時・日付の形式に対応し、ローカル日時と日付はUTCとして扱う)
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		t, err := time.ParseInLocation(layout, value, time.UTC)
+		if err == nil {
+			if layout == "20060102" {
+				// 日付のみの場合はその日の終わりまでを含める
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL: %s", value)
+}
+
+// BYDAYの要素を解析(例: MO, 1MO, -1FR)
+func parseWeekdayNum(v string) (WeekdayNum, error) {
+	if len(v) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY: %s", v)
+	}
+	wd, ok := weekdays[v[len(v)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY: %s", v)
+	}
+	n := 0
+	if prefix := v[:len(v)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY: %s", v)
+		}
+	}
+	return WeekdayNum{N: n, Weekday: wd}, nil
+}
+
+// ルールを正規化した文字列に変換
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		names := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			name := strings.ToUpper(wd.Weekday.String()[:2])
+			if wd.N != 0 {
+				name = strconv.Itoa(wd.N) + name
+			}
+			names = append(names, name)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(names, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, 0, len(r.ByMonth))
+		for _, m := range r.ByMonth {
+			months = append(months, strconv.Itoa(int(m)))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// 発生日時を順に列挙する(fnがfalseを返すか、ルールの終わりに達するまで)
+// dtstartのタイムゾーンの壁時計時刻で展開するため、夏時間の切り替えをまたいでも同じ時刻になる。
+// COUNTは除外日を含めて数える(RFC 5545のEXDATEと同じ扱い)。
+func (r Rule) Iterate(dtstart time.Time, fn func(time.Time) bool) {
+	count := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(dtstart, period) {
+			if t.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return
+			}
+			count++
+			if !fn(t) {
+				return
+			}
+			if r.Count > 0 && count >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// afterより後の最初の発生日時を取得(除外日は飛ばす)
+func (r Rule) Next(dtstart time.Time, after time.Time, exdates []time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.Iterate(dtstart, func(t time.Time) bool {
+		if !t.After(after) || isExcluded(t, exdates) {
+			return true
+		}
+		next = t
+		found = true
+		return false
+	})
+	return next, found
+}
+
+// from以上to未満の発生日時を最大limit件取得(除外日は飛ばす)
+func (r Rule) Between(dtstart time.Time, from time.Time, to time.Time, exdates []time.Time, limit int) []time.Time {
+	result := []time.Time{}
+	r.Iterate(dtstart, func(t time.Time) bool {
+		if !t.Before(to) || len(result) >= limit {
+			return false
+		}
+		if t.Before(from) || isExcluded(t, exdates) {
+			return true
+		}
+		result = append(result, t)
+		return true
+	})
+	return result
+}
+
+// 除外日に含まれるかどうか
+func isExcluded(t time.Time, exdates []time.Time) bool {
+	for _, ex := range exdates {
+		if t.Equal(ex) {
+			return true
+		}
+	}
+	return false
+}
+
+// 周期ごとの発生日時の候補を昇順で取得
+func (r Rule) candidates(dtstart time.Time, period int) []time.Time {
+	loc := dtstart.Location()
+	y, m, d := dtstart.Date()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, loc)
+	}
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := at(y, m, d+period*r.Interval)
+		if r.matchesMonth(day.Month()) && r.matchesMonthDay(day) && r.matchesWeekday(day) {
+			days = append(days, day)
+		}
+	case Weekly:
+		// 週の始まりは月曜日
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := at(y, m, d-offset+period*r.Interval*7)
+		if len(r.ByDay) == 0 {
+			days = append(days, monday.AddDate(0, 0, offset))
+		} else {
+			for i := 0; i < 7; i++ {
+				day := monday.AddDate(0, 0, i)
+				if r.matchesWeekday(day) {
+					days = append(days, day)
+				}
+			}
+		}
+		filtered := days[:0]
+		for _, day := range days {
+			if r.matchesMonth(day.Month()) {
+				filtered = append(filtered, day)
+			}
+		}
+		days = filtered
+	case Monthly:
+		first := at(y, m+time.Month(period*r.Interval), 1)
+		if r.matchesMonth(first.Month()) {
+			days = r.daysInMonth(first, d)
+		}
+	case Yearly:
+		year := y + period*r.Interval
+		months := r.ByMonth
+		if len(months) == 0 {
+			if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+				// BYMONTHが無い場合、BYDAY・BYMONTHDAYは年内の全ての月に適用する
+				months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			} else {
+				months = []time.Month{m}
+			}
+		}
+		for _, month := range months {
+			days = append(days, r.daysInMonth(at(year, month, 1), d)...)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// 月内の候補日を取得(firstはその月の1日、defaultDayはBYMONTHDAY・BYDAYがない場合の日)
+func (r Rule) daysInMonth(first time.Time, defaultDay int) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	var days []time.Time
+	for day := 1; day <= last; day++ {
+		t := first.AddDate(0, 0, day-1)
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			// 存在しない日(例: 31日)の月は飛ばす
+			if day == defaultDay {
+				days = append(days, t)
+			}
+			continue
+		}
+		if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(t) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !r.matchesWeekdayInMonth(t, day, last) {
+			continue
+		}
+		days = append(days, t)
+	}
+	return days
+}
+
+// BYMONTHに一致するかどうか(指定がない場合は常に一致)
+func (r Rule) matchesMonth(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+// BYMONTHDAYに一致するかどうか(指定がない場合は常に一致)
+func (r Rule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, d := range r.ByMonthDay {
+		if d == t.Day() || (d < 0 && last+d+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// BYDAYの曜日に一致するかどうか(指定がない場合は常に一致、序数は無視する)
+func (r Rule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// BYDAYに月内の序数を含めて一致するかどうか
+func (r Rule) matchesWeekdayInMonth(t time.Time, day int, last int) bool {
+	for _, wd := range r.ByDay {
+		if wd.Weekday != t.Weekday() {
+			continue
+		}
+		if wd.N == 0 {
+			return true
+		}
+		nth := (day-1)/7 + 1
+		nthFromEnd := -((last-day)/7 + 1)
+		if wd.N == nth || wd.N == nthFromEnd {
+			return true
+		}
+	}
+	return false
+}
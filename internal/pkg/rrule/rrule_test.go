@@ -0,0 +1,330 @@
+package pkg_rrule
+
+import (
+	"testing"
+	"time"
+)
+
+// ルールを展開し、最大limit件の発生日時を取得
+func expand(t *testing.T, rrule string, dtstart time.Time, limit int) []time.Time {
+	t.Helper()
+	rule, err := Parse(rrule)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", rrule, err)
+	}
+	result := []time.Time{}
+	rule.Iterate(dtstart, func(occurrence time.Time) bool {
+		result = append(result, occurrence)
+		return len(result) < limit
+	})
+	return result
+}
+
+// 日付(YYYY-MM-DD)の一覧に変換
+func dates(times []time.Time) []string {
+	result := make([]string, 0, len(times))
+	for _, t := range times {
+		result = append(result, t.Format("2006-01-02"))
+	}
+	return result
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIterate(t *testing.T) {
+	utc := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		rrule   string
+		dtstart time.Time
+		limit   int
+		want    []string
+	}{
+		{
+			name:    "daily with interval",
+			rrule:   "FREQ=DAILY;INTERVAL=2",
+			dtstart: utc(2024, 1, 30),
+			limit:   3,
+			want:    []string{"2024-01-30", "2024-02-01", "2024-02-03"},
+		},
+		{
+			name:    "weekly by day",
+			rrule:   "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			dtstart: utc(2024, 1, 3), // 水曜日
+			limit:   4,
+			want:    []string{"2024-01-03", "2024-01-05", "2024-01-08", "2024-01-10"},
+		},
+		{
+			name:    "monthly second tuesday",
+			rrule:   "FREQ=MONTHLY;BYDAY=2TU",
+			dtstart: utc(2024, 1, 1),
+			limit:   3,
+			want:    []string{"2024-01-09", "2024-02-13", "2024-03-12"},
+		},
+		{
+			name:    "monthly last friday",
+			rrule:   "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: utc(2024, 1, 1),
+			limit:   3,
+			want:    []string{"2024-01-26", "2024-02-23", "2024-03-29"},
+		},
+		{
+			name:    "monthly second to last monday",
+			rrule:   "FREQ=MONTHLY;BYDAY=-2MO",
+			dtstart: utc(2024, 4, 1),
+			limit:   2,
+			want:    []string{"2024-04-22", "2024-05-20"},
+		},
+		{
+			name:    "yearly fourth thursday of november",
+			rrule:   "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			dtstart: utc(2024, 1, 1),
+			limit:   3,
+			want:    []string{"2024-11-28", "2025-11-27", "2026-11-26"},
+		},
+		{
+			name:    "monthly last day",
+			rrule:   "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: utc(2024, 1, 15),
+			limit:   4,
+			want:    []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name:    "monthly first and second to last day",
+			rrule:   "FREQ=MONTHLY;BYMONTHDAY=1,-2",
+			dtstart: utc(2023, 2, 1),
+			limit:   4,
+			want:    []string{"2023-02-01", "2023-02-27", "2023-03-01", "2023-03-30"},
+		},
+		{
+			name:    "monthly skips months without the day",
+			rrule:   "FREQ=MONTHLY",
+			dtstart: utc(2024, 1, 31),
+			limit:   3,
+			want:    []string{"2024-01-31", "2024-03-31", "2024-05-31"},
+		},
+		{
+			name:    "yearly by month day without by month applies to every month",
+			rrule:   "FREQ=YEARLY;BYMONTHDAY=15",
+			dtstart: utc(2024, 10, 1),
+			limit:   4,
+			want:    []string{"2024-10-15", "2024-11-15", "2024-12-15", "2025-01-15"},
+		},
+		{
+			name:    "yearly negative month day without by month",
+			rrule:   "FREQ=YEARLY;BYMONTHDAY=-1",
+			dtstart: utc(2024, 11, 1),
+			limit:   4,
+			want:    []string{"2024-11-30", "2024-12-31", "2025-01-31", "2025-02-28"},
+		},
+		{
+			name:    "yearly by month day and by day without by month",
+			rrule:   "FREQ=YEARLY;BYMONTHDAY=13;BYDAY=FR",
+			dtstart: utc(2024, 1, 1),
+			limit:   3,
+			want:    []string{"2024-09-13", "2024-12-13", "2025-06-13"},
+		},
+		{
+			name:    "yearly on dtstart date",
+			rrule:   "FREQ=YEARLY",
+			dtstart: utc(2024, 2, 29),
+			limit:   2,
+			want:    []string{"2024-02-29", "2028-02-29"},
+		},
+		{
+			name:    "yearly by month",
+			rrule:   "FREQ=YEARLY;BYMONTH=1,7",
+			dtstart: utc(2024, 3, 10),
+			limit:   3,
+			want:    []string{"2024-07-10", "2025-01-10", "2025-07-10"},
+		},
+		{
+			name:    "count",
+			rrule:   "FREQ=DAILY;COUNT=3",
+			dtstart: utc(2024, 1, 1),
+			limit:   10,
+			want:    []string{"2024-01-01", "2024-01-02", "2024-01-03"},
+		},
+		{
+			name:    "count counts only matching occurrences",
+			rrule:   "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=3",
+			dtstart: utc(2024, 1, 1), // 月曜日(dtstart自体は一致しない)
+			limit:   10,
+			want:    []string{"2024-01-02", "2024-01-04", "2024-01-09"},
+		},
+		{
+			name:    "until inclusive",
+			rrule:   "FREQ=DAILY;UNTIL=20240103T090000Z",
+			dtstart: utc(2024, 1, 1),
+			limit:   10,
+			want:    []string{"2024-01-01", "2024-01-02", "2024-01-03"},
+		},
+		{
+			name:    "until date includes the whole day",
+			rrule:   "FREQ=WEEKLY;UNTIL=20240115",
+			dtstart: utc(2024, 1, 1),
+			limit:   10,
+			want:    []string{"2024-01-01", "2024-01-08", "2024-01-15"},
+		},
+		{
+			name:    "until before dtstart",
+			rrule:   "FREQ=DAILY;UNTIL=20231231T000000Z",
+			dtstart: utc(2024, 1, 1),
+			limit:   10,
+			want:    []string{},
+		},
+		{
+			name:    "rule without matching days terminates",
+			rrule:   "FREQ=MONTHLY;BYMONTHDAY=31;BYMONTH=2",
+			dtstart: utc(2024, 1, 1),
+			limit:   10,
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dates(expand(t, tt.rrule, tt.dtstart, tt.limit))
+			if !equalStrings(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIterateKeepsWallClockAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data is not available: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rrule   string
+		dtstart time.Time
+		want    []string
+	}{
+		{
+			name:    "daily across spring forward",
+			rrule:   "FREQ=DAILY;COUNT=3",
+			dtstart: time.Date(2024, 3, 9, 9, 0, 0, 0, ny),
+			want:    []string{"2024-03-09T14:00:00Z", "2024-03-10T13:00:00Z", "2024-03-11T13:00:00Z"},
+		},
+		{
+			name:    "weekly across fall back",
+			rrule:   "FREQ=WEEKLY;COUNT=2",
+			dtstart: time.Date(2024, 10, 27, 9, 0, 0, 0, ny),
+			want:    []string{"2024-10-27T13:00:00Z", "2024-11-03T14:00:00Z"},
+		},
+		{
+			name:    "monthly across spring forward",
+			rrule:   "FREQ=MONTHLY;BYDAY=2SU;COUNT=2",
+			dtstart: time.Date(2024, 2, 11, 9, 0, 0, 0, ny),
+			want:    []string{"2024-02-11T14:00:00Z", "2024-03-10T13:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences := expand(t, tt.rrule, tt.dtstart, 10)
+			got := make([]string, 0, len(occurrences))
+			for _, o := range occurrences {
+				if o.Hour() != 9 || o.Location() != ny {
+					t.Errorf("occurrence %v is not 09:00 in America/New_York", o)
+				}
+				got = append(got, o.UTC().Format(time.RFC3339))
+			}
+			if !equalStrings(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextAndBetweenSkipExdates(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	exdates := []time.Time{dtstart.AddDate(0, 0, 1)}
+
+	next, ok := rule.Next(dtstart, dtstart, exdates)
+	if !ok || !next.Equal(dtstart.AddDate(0, 0, 2)) {
+		t.Errorf("Next = %v, %v; want %v", next, ok, dtstart.AddDate(0, 0, 2))
+	}
+
+	got := dates(rule.Between(dtstart, dtstart, dtstart.AddDate(0, 0, 5), exdates, 3))
+	want := []string{"2024-01-01", "2024-01-03", "2024-01-04"}
+	if !equalStrings(got, want) {
+		t.Errorf("Between = %v, want %v", got, want)
+	}
+
+	// COUNTは除外日を含めて数える
+	counted, err := Parse("FREQ=DAILY;COUNT=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := counted.Next(dtstart, dtstart, exdates); ok {
+		t.Error("expected no next occurrence when the remaining one is excluded")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rrule   string
+		want    string
+		wantErr bool
+	}{
+		{rrule: "RRULE:FREQ=WEEKLY;BYDAY=MO,FR", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{rrule: "freq=monthly;interval=2;byday=-1fr", want: "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR"},
+		{rrule: "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;COUNT=5", want: "FREQ=YEARLY;COUNT=5;BYDAY=4TH;BYMONTH=11"},
+		{rrule: "FREQ=DAILY;INTERVAL=1;UNTIL=20240131", want: "FREQ=DAILY;UNTIL=20240131T235959Z"},
+		{rrule: "FREQ=MONTHLY;BYMONTHDAY=1,-1;WKST=MO", want: "FREQ=MONTHLY;BYMONTHDAY=1,-1"},
+		{rrule: "", wantErr: true},
+		{rrule: "INTERVAL=2", wantErr: true},
+		{rrule: "FREQ=HOURLY", wantErr: true},
+		{rrule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{rrule: "FREQ=DAILY;COUNT=2;UNTIL=20240101", wantErr: true},
+		{rrule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{rrule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{rrule: "FREQ=YEARLY;BYDAY=1MO", wantErr: true},
+		{rrule: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{rrule: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: true},
+		{rrule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{rrule: "FREQ=YEARLY;BYMONTH=13", wantErr: true},
+		{rrule: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{rrule: "FREQ=WEEKLY;WKST=SU", wantErr: true},
+		{rrule: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.rrule)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) = %v, want error", tt.rrule, rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.rrule, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.rrule, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package pkg_secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// 暗号化した値の接頭辞(形式のバージョンを含む)
+const SealedPrefix = "enc:v1:"
+
+// 鍵のバイト数(AES-256)
+const KeySize = 32
+
+// 保存する値の暗号化(AES-256-GCM)
+// 暗号化した値は接頭辞とbase64(nonce + 暗号文)からなる文字列で、暗号化前の値と区別できる。
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// 保存する値の暗号化のインスタンス化
+// 鍵はbase64で符号化した32バイトの値。
+func NewSecretBox(encodedKey string) (*SecretBox, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, errors.New("invalid secret key encoding")
+	}
+	if len(key) != KeySize {
+		return nil, errors.New("invalid secret key length")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// 値を暗号化する(同じ値でも毎回異なる結果になる)
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return SealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// 暗号化した値を復号する(鍵が異なる場合や改ざんされた場合はエラー)
+func (b *SecretBox) Open(sealed string) (string, error) {
+	if !IsSealed(sealed) {
+		return "", errors.New("value is not sealed")
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed[len(SealedPrefix):])
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", errors.New("invalid sealed value")
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("failed to open sealed value")
+	}
+	return string(plaintext), nil
+}
+
+// 暗号化した値かどうか(接頭辞で判定する)
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, SealedPrefix)
+}
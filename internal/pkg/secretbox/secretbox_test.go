@@ -0,0 +1,78 @@
+package pkg_secretbox
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), KeySize)))
+}
+
+func TestSecretBoxSealAndOpen(t *testing.T) {
+	box, err := NewSecretBox(testKey('k'))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sealed, err := box.Seal("0123456789abcdef")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "0123456789abcdef") {
+		t.Errorf("sealed value %q is not sealed", sealed)
+	}
+	opened, err := box.Open(sealed)
+	if err != nil || opened != "0123456789abcdef" {
+		t.Errorf("Open() = (%q, %v), want 0123456789abcdef", opened, err)
+	}
+
+	// 同じ値でも毎回異なる結果になる
+	again, _ := box.Seal("0123456789abcdef")
+	if again == sealed {
+		t.Error("sealing the same value twice produced the same result")
+	}
+}
+
+func TestSecretBoxOpenErrors(t *testing.T) {
+	box, _ := NewSecretBox(testKey('k'))
+	other, _ := NewSecretBox(testKey('o'))
+	sealed, _ := box.Seal("0123456789abcdef")
+
+	// 末尾の1文字を書き換える
+	last := sealed[len(sealed)-2]
+	replacement := byte('A')
+	if last == 'A' {
+		replacement = 'B'
+	}
+	tampered := sealed[:len(sealed)-2] + string(replacement) + sealed[len(sealed)-1:]
+
+	tests := []struct {
+		name  string
+		box   *SecretBox
+		value string
+	}{
+		{name: "another key", box: other, value: sealed},
+		{name: "tampered", box: box, value: tampered},
+		{name: "plaintext", box: box, value: "0123456789abcdef"},
+		{name: "invalid base64", box: box, value: SealedPrefix + "!!!"},
+		{name: "too short", box: box, value: SealedPrefix + base64.StdEncoding.EncodeToString([]byte("short"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.box.Open(tt.value); err == nil {
+				t.Errorf("Open(%q) = %q, want error", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestNewSecretBoxInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := NewSecretBox(key); err == nil {
+			t.Errorf("NewSecretBox(%q): want error", key)
+		}
+	}
+}
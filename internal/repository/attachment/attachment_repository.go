@@ -0,0 +1,17 @@
+package repository_attachment
+
+import (
+	domain_attachment "backend/internal/domain/attachment"
+)
+
+// 添付ファイルリポジトリ(IF)
+type IAttachmentRepository interface {
+	// 特定の添付ファイルを取得
+	GetAttachmentById(id string) (domain_attachment.Attachment, error)
+	// 複数のTodoの添付ファイルを取得
+	GetAttachmentsByTodoIds(todoIds []string) ([]domain_attachment.Attachment, error)
+	// 新しい添付ファイルを作成
+	CreateAttachment(attachment domain_attachment.Attachment) (domain_attachment.Attachment, error)
+	// 特定の添付ファイルを削除
+	DeleteAttachment(id string) error
+}
@@ -0,0 +1,14 @@
+package repository_audit
+
+import (
+	domain_audit "backend/internal/domain/audit"
+)
+
+// 監査ログリポジトリ(IF)
+// 監査ログの書き込みは対象エンティティのリポジトリが同一トランザクション内で行う。
+type IAuditLogRepository interface {
+	// 条件に一致する監査ログと総件数を取得
+	GetAuditLogs(filter domain_audit.AuditLogFilter) ([]domain_audit.AuditLog, int, error)
+	// 複数のエンティティの監査ログを新しい順にエンティティごとにlimit件ずつ、offset件目から取得し、エンティティごとの総件数とあわせて返す
+	GetAuditLogsByEntityIds(entityType string, entityIds []string, limit int, offset int) ([]domain_audit.AuditLog, map[string]int, error)
+}
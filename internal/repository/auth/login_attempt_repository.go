@@ -0,0 +1,25 @@
+package repository_auth
+
+import (
+	domain_auth "backend/internal/domain/auth"
+	"time"
+)
+
+// ログイン試行リポジトリ(IF)
+type ILoginAttemptRepository interface {
+	// ログイン試行を記録
+	RecordAttempt(attempt domain_auth.LoginAttempt) error
+	// ログイン試行を予約(試行回数の加算とバックオフの判定を1文で行う)
+	// 最後の試行からwindow以上経過している場合は回数をリセットする。
+	ReserveAttempt(scope string, subject string, window time.Duration, backoff domain_auth.LoginBackoff) (domain_auth.LoginAttemptReservation, error)
+	// 予約したログイン試行を取り消す(試行回数を1減らす)
+	ReleaseAttempt(scope string, subject string) error
+	// ログイン試行の回数をリセット
+	ResetAttempts(scope string, subject string) error
+	// ロック情報を取得(ロックされていない場合はnil)
+	GetLockout(scope string, subject string) (*domain_auth.LoginLockout, error)
+	// ロック
+	Lock(scope string, subject string, lockedUntil time.Time) error
+	// ロック解除
+	Unlock(scope string, subject string) error
+}
@@ -0,0 +1,21 @@
+package repository_calendar
+
+import (
+	domain_calendar "backend/internal/domain/calendar"
+	"errors"
+)
+
+// フィードが存在しない場合のエラー
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// カレンダーの購読フィードリポジトリ(IF)
+type ICalendarFeedRepository interface {
+	// 特定のユーザーのフィードを取得
+	GetFeedByUserId(userId string) (domain_calendar.CalendarFeed, error)
+	// 特定のユーザーのフィードのトークンを設定(既存のトークンは無効になる)
+	UpsertFeed(userId string, tokenHash string) (domain_calendar.CalendarFeed, error)
+	// 特定のユーザーのフィードを削除し、削除したかどうかを返す
+	DeleteFeed(userId string) (bool, error)
+	// トークンのハッシュに一致するフィードを取得し、最後に取得した日時を記録する
+	TouchFeedByTokenHash(tokenHash string) (domain_calendar.CalendarFeed, error)
+}
@@ -0,0 +1,19 @@
+package repository_comment
+
+import (
+	domain_comment "backend/internal/domain/comment"
+)
+
+// コメントリポジトリ(IF)
+type ICommentRepository interface {
+	// 特定のコメントを取得
+	GetCommentById(id string) (domain_comment.Comment, error)
+	// 複数のTodoのコメントを投稿順にTodoごとにlimit件ずつ、offset件目から取得
+	GetCommentPagesByTodoIds(todoIds []string, limit int, offset int) ([]domain_comment.CommentPage, error)
+	// 新しいコメントを作成
+	CreateComment(comment domain_comment.Comment) (domain_comment.Comment, error)
+	// コメントの本文を変更(編集日時を記録する)
+	EditComment(id string, body string) (domain_comment.Comment, error)
+	// 特定のコメントを削除
+	DeleteComment(id string) error
+}
@@ -0,0 +1,11 @@
+package repository_notification
+
+import (
+	domain_todo "backend/internal/domain/todo"
+)
+
+// リマインド通知(IF)
+type IReminderNotifier interface {
+	// リマインドのイベントを通知
+	NotifyReminder(todo domain_todo.Todo) error
+}
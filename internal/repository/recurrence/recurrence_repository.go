@@ -0,0 +1,31 @@
+package repository_recurrence
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_recurrence "backend/internal/domain/recurrence"
+	domain_todo "backend/internal/domain/todo"
+	"errors"
+	"time"
+)
+
+// 繰り返し設定が存在しない場合のエラー
+var ErrRecurrenceNotFound = errors.New("recurrence not found")
+
+// 繰り返しリポジトリ(IF)
+type IRecurrenceRepository interface {
+	// 特定のTodoの繰り返し設定を取得
+	GetRecurrenceByTodoId(todoId string) (domain_recurrence.Recurrence, error)
+	// 複数のTodoの繰り返し設定を取得
+	GetRecurrencesByTodoIds(todoIds []string) ([]domain_recurrence.Recurrence, error)
+	// 特定のユーザーの未完了のTodoの繰り返し設定を取得
+	GetActiveRecurrencesByUserId(userId string) ([]domain_recurrence.Recurrence, error)
+	// 繰り返し設定を登録(既にある場合は置き換え、除外日はリセットする)
+	UpsertRecurrence(recurrence domain_recurrence.Recurrence) (domain_recurrence.Recurrence, error)
+	// 繰り返し設定を削除
+	DeleteRecurrence(todoId string) error
+	// 除外日を追加
+	AddExDate(todoId string, at time.Time) (domain_recurrence.Recurrence, error)
+	// 次の発生分のTodoを作成し、繰り返し設定とタグを引き継ぐ(監査ログを同一トランザクションで記録する)
+	// 既に引き継ぎ済みの場合はErrRecurrenceNotFoundを返す
+	AdvanceRecurrence(todoId string, next domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+}
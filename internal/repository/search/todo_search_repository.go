@@ -0,0 +1,14 @@
+package repository_search
+
+import (
+	domain_search "backend/internal/domain/search"
+)
+
+// Todoの全文検索リポジトリ(IF)
+type ITodoSearchRepository interface {
+	// 全文検索の言語の設定(PostgreSQLのテキスト検索設定名)を変更し、変更した場合は全てのTodoの索引を作り直す
+	// 索引を作り直した場合はtrueを返す。
+	SetSearchLanguage(language string) (bool, error)
+	// 特定のユーザーのTodo(ゴミ箱にないもの)を検索し、関連度の高い順にlimit件取得(afterがnilの場合は先頭から)
+	SearchTodos(userId string, query domain_search.TodoSearchQuery, limit int, after *domain_search.TodoSearchCursor) ([]domain_search.TodoSearchResult, error)
+}
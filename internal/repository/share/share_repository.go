@@ -0,0 +1,29 @@
+package repository_share
+
+import (
+	domain_share "backend/internal/domain/share"
+	"errors"
+)
+
+// 同じ対象が既に同じユーザーに共有されている場合のエラー
+var ErrShareConflict = errors.New("share conflict")
+
+// 共有リポジトリ(IF)
+type ITodoShareRepository interface {
+	// 特定の共有を取得
+	GetShareById(id string) (domain_share.TodoShare, error)
+	// 特定のユーザーが所有するTodoの共有を取得(todoIdがnilの場合は全て)
+	GetSharesByOwnerId(ownerId string, todoId *string) ([]domain_share.TodoShare, error)
+	// 特定のユーザーへの共有を取得(statusが空の場合は全ての状態)
+	GetSharesByMemberId(memberId string, status string) ([]domain_share.TodoShare, error)
+	// 特定のユーザーが、所有者のTodo(todoIdsのいずれか、または全てのTodo)に対して持つ承諾済みの権限を取得
+	GetMemberRoles(memberId string, ownerId string, todoIds []string) ([]string, error)
+	// 新しい共有(招待)を作成
+	CreateShare(share domain_share.TodoShare) (domain_share.TodoShare, error)
+	// 共有を承諾
+	AcceptShare(id string) (domain_share.TodoShare, error)
+	// 共有の権限を変更
+	UpdateShareRole(id string, role string) (domain_share.TodoShare, error)
+	// 特定の共有を削除
+	DeleteShare(id string) error
+}
@@ -0,0 +1,14 @@
+package repository_storage
+
+import "io"
+
+// Blobストレージ(IF)
+// ファイルの実体を保存する。保存先(ローカル、オブジェクトストレージ等)は実装で切り替える。
+type IBlobStorage interface {
+	// ファイルを保存
+	Put(key string, r io.Reader) error
+	// ファイルを取得(呼び出し側でCloseすること)
+	Get(key string) (io.ReadCloser, error)
+	// ファイルを削除
+	Delete(key string) error
+}
@@ -0,0 +1,31 @@
+package repository_tag
+
+import (
+	domain_tag "backend/internal/domain/tag"
+	"errors"
+)
+
+// 同じ名前のタグが既に存在する場合のエラー
+var ErrTagNameConflict = errors.New("tag name conflict")
+
+// タグリポジトリ(IF)
+type ITagRepository interface {
+	// 特定のタグを取得
+	GetTagById(id string) (domain_tag.Tag, error)
+	// 特定のユーザーのタグを取得
+	GetTagsByUserId(userId string) ([]domain_tag.Tag, error)
+	// 複数のTodoに付与されたタグを取得
+	GetTagsByTodoIds(todoIds []string) ([]domain_tag.TodoTag, error)
+	// 特定のユーザーの、指定したタグが全て付与されたTodoのIDを取得
+	GetTodoIdsByTagIds(userId string, tagIds []string) ([]string, error)
+	// 新しいタグを作成
+	CreateTag(tag domain_tag.Tag) (domain_tag.Tag, error)
+	// タグ名を変更
+	RenameTag(id string, name string) (domain_tag.Tag, error)
+	// 特定のタグを削除
+	DeleteTag(id string) error
+	// Todoにタグを付与
+	TagTodo(todoId string, tagId string) error
+	// Todoからタグを外す
+	UntagTodo(todoId string, tagId string) error
+}
@@ -0,0 +1,18 @@
+package repository_todo
+
+import (
+	domain_todo "backend/internal/domain/todo"
+	"errors"
+)
+
+// 同じTodoが同時に更新され、イベントのバージョンが競合した場合のエラー
+var ErrTodoVersionConflict = errors.New("todo version conflict")
+
+// Todoのイベントストア(IF)
+type ITodoEventStore interface {
+	// 特定のTodoのイベントをバージョン順に取得(afterVersionより後のバージョンのみ)
+	GetTodoEvents(aggregateId string, afterVersion int) ([]domain_todo.TodoEvent, error)
+	// イベントを再生して読み取りモデル(todosテーブル)を再構築し、再構築したTodoの件数を返す
+	// useSnapshotsがfalseの場合は最初のイベントから再生し、スナップショットも作り直す。
+	RebuildReadModel(useSnapshots bool) (int, error)
+}
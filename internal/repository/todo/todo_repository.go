@@ -1,21 +1,90 @@
 package repository_todo
 
 import (
+	domain_audit "backend/internal/domain/audit"
 	domain_todo "backend/internal/domain/todo"
+	"time"
 )
 
 // Todoリポジトリ(IF)
 type ITodoRepository interface {
-	// 全てのTodoを取得
-	GetAllTodos() ([]domain_todo.Todo, error)
+	// ユーザーが閲覧できる(自分の、または共有された)Todoを取得
+	GetVisibleTodos(viewerId string) ([]domain_todo.Todo, error)
 	// 特定のTodoを取得
 	GetTodoById(id string) (domain_todo.Todo, error)
 	// 特定のユーザーのTodoを取得
 	GetTodoByUserId(userId string) ([]domain_todo.Todo, error)
-	// 新しいTodoを作成
-	CreateTodo(todo domain_todo.Todo) (domain_todo.Todo, error)
-	// 特定のTodoを更新
-	UpdateTodo(todo domain_todo.Todo) (domain_todo.Todo, error)
-	// 特定のTodoを削除
-	DeleteTodo(id string) error
+	// 特定のユーザーのTodoを並び順に1件ずつ読み込み、fnを呼び出す(エクスポート用、fnがエラーを返した場合は中断する)
+	ForEachTodoByUserId(userId string, fn func(todo domain_todo.Todo) error) error
+	// 複数のユーザーのTodoのうち、viewerIdのユーザーが閲覧できるものを取得
+	GetVisibleTodosByUserIds(viewerId string, userIds []string) ([]domain_todo.Todo, error)
+	// 複数のTodoのうち、viewerIdのユーザーが閲覧できるもののIDを取得
+	GetVisibleTodoIds(viewerId string, ids []string) ([]string, error)
+	// 複数のTodoを取得
+	GetTodosByIds(ids []string) ([]domain_todo.Todo, error)
+	// 特定のユーザーに共有された(承諾済みの)Todoを取得
+	GetSharedTodosByMemberId(memberId string) ([]domain_todo.Todo, error)
+	// 複数の親Todoのサブタスク(直下の子)を取得
+	GetSubtasksByParentIds(parentIds []string) ([]domain_todo.Todo, error)
+	// 複数のTodoのサブタスクの進捗を取得(子孫を全て集計する)
+	GetTodoProgressByIds(ids []string) ([]domain_todo.TodoProgress, error)
+	// 特定のTodoの祖先のIDを近い順に取得
+	GetTodoAncestorIds(id string) ([]string, error)
+	// 特定のTodoを頂点とする部分木の高さを取得(子がない場合は1)
+	GetSubtreeHeight(id string) (int, error)
+	// Todoの親を変更(parentIdがnilの場合は最上位、監査ログを同一トランザクションで記録する)
+	SetTodoParent(id string, parentId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// 複数のTodoリストに属するTodoのうち、viewerIdのユーザーが閲覧できるものを取得
+	GetVisibleTodosByListIds(viewerId string, listIds []string) ([]domain_todo.Todo, error)
+	// 特定のユーザーのインボックス(どのリストにも属さない)のTodoを取得
+	GetInboxTodosByUserId(userId string) ([]domain_todo.Todo, error)
+	// Todoを別のリストに移動(listIdがnilの場合はインボックス、監査ログを同一トランザクションで記録する)
+	MoveTodoToList(id string, listId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// 特定のユーザーの末尾のTodoの並び順のキーを取得(キーを持つTodoが無い場合はnil、excludeIdのTodoは除く)
+	GetLastTodoPosition(userId string, excludeId string) (*string, error)
+	// 特定のユーザーのTodoのうち、指定したキーの直後(nextがfalseの場合は直前)のキーを取得(無い場合はnil、excludeIdのTodoは除く)
+	GetAdjacentTodoPosition(userId string, position string, next bool, excludeId string) (*string, error)
+	// Todoの並び順のキーを変更(対象の1行のみ更新し、監査ログを同一トランザクションで記録する)
+	UpdateTodoPosition(id string, position string, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// 特定のユーザーのゴミ箱にないTodoの並び順のキーを現在の順序のまま等間隔に振り直し、更新した件数を返す
+	RebalanceTodoPositions(userId string) (int, error)
+	// 並び順のキーの振り直しが必要なユーザーのIDを取得(キーが未設定、またはmaxLengthより長いゴミ箱にないTodoを持つユーザー)
+	GetUserIdsNeedingRebalance(maxLength int, limit int) ([]string, error)
+	// 特定のユーザーの期限切れのTodoを取得(未完了のみ)
+	GetOverdueTodosByUserId(userId string, now time.Time) ([]domain_todo.Todo, error)
+	// 特定のユーザーの期限が指定期間内のTodoを取得(未完了のみ)
+	GetUpcomingTodosByUserId(userId string, from time.Time, to time.Time) ([]domain_todo.Todo, error)
+	// 特定のユーザーの期限がsince以降のTodo(完了済みを含む)を期限の早い順にlimit件取得
+	GetDueTodosByUserId(userId string, since time.Time, limit int) ([]domain_todo.Todo, error)
+	// リマインド日時を過ぎたTodoを取得し、通知済みにする
+	ClaimDueReminders(now time.Time, limit int) ([]domain_todo.Todo, error)
+	// 新しいTodoを作成(監査ログを同一トランザクションで記録する)
+	CreateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// 複数のTodoを1つのトランザクションで作成(監査ログを同一トランザクションで記録する)
+	// parentIndexes[i]はtodos[i]の親のtodos内の位置で、親は子より前に並べる(-1の場合はParentIdをそのまま使う)。
+	ImportTodos(todos []domain_todo.Todo, parentIndexes []int, actor domain_audit.AuditActor) ([]domain_todo.Todo, error)
+	// 特定のTodoを更新(監査ログを同一トランザクションで記録する)
+	UpdateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// 特定のTodoを更新し、未完了のサブタスク(子孫)を全て完了にする(監査ログを同一トランザクションで記録する)
+	UpdateTodoAndCompleteSubtasks(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, int, error)
+	// 特定のTodoを削除(ゴミ箱に移動し、監査ログを同一トランザクションで記録する)
+	DeleteTodo(id string, actor domain_audit.AuditActor) error
+	// 複数のTodoの完了状態を1つのトランザクションで変更し、Todoごとの結果を返す(監査ログを同一トランザクションで記録する)
+	// 権限(所有者、またはrequired以上の共有)は同一トランザクションで確認し、権限が無いTodoは失敗にする。
+	BulkSetCompleted(ids []string, completed bool, required string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error)
+	// 複数のTodoを1つのトランザクションで削除(ゴミ箱に移動)し、Todoごとの結果を返す(監査ログを同一トランザクションで記録する)
+	// 権限(所有者、またはrequired以上の共有)は同一トランザクションで確認し、権限が無いTodoは失敗にする。
+	BulkDeleteTodos(ids []string, required string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error)
+	// 特定のユーザーの完了済みのTodoを1つのトランザクションで削除(ゴミ箱に移動)し、削除したTodoを返す(listIdがnilの場合は全てのリスト)
+	ClearCompletedTodos(userId string, listId *string, actor domain_audit.AuditActor) ([]domain_todo.Todo, error)
+	// ゴミ箱の特定のTodoを取得
+	GetTrashedTodoById(id string) (domain_todo.Todo, error)
+	// 特定のユーザーのゴミ箱のTodoを取得
+	GetTrashedTodosByUserId(userId string) ([]domain_todo.Todo, error)
+	// 保持期間を過ぎたゴミ箱のTodoのIDを取得
+	GetTrashedTodoIdsBefore(before time.Time, limit int) ([]string, error)
+	// ゴミ箱のTodoを復元(監査ログを同一トランザクションで記録する)
+	RestoreTodo(id string, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// ゴミ箱のTodoを完全に削除し、削除した添付ファイルのストレージキーを返す(監査ログを同一トランザクションで記録する)
+	PurgeTodo(id string, actor domain_audit.AuditActor) ([]string, error)
 }
@@ -0,0 +1,22 @@
+package repository_todolist
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_todolist "backend/internal/domain/todolist"
+)
+
+// Todoリストリポジトリ(IF)
+type ITodoListRepository interface {
+	// 特定のTodoリストを取得
+	GetTodoListById(id string) (domain_todolist.TodoList, error)
+	// 複数のTodoリストを取得
+	GetTodoListsByIds(ids []string) ([]domain_todolist.TodoList, error)
+	// 特定のユーザーのTodoリストを取得(includeArchivedがfalseの場合はアーカイブ済みを除く)
+	GetTodoListsByUserId(userId string, includeArchived bool) ([]domain_todolist.TodoList, error)
+	// 新しいTodoリストを作成(表示順は末尾)
+	CreateTodoList(list domain_todolist.TodoList) (domain_todolist.TodoList, error)
+	// 特定のTodoリストを更新
+	UpdateTodoList(list domain_todolist.TodoList) (domain_todolist.TodoList, error)
+	// 特定のTodoリストを削除し、影響を受けたTodoの件数を返す(監査ログを同一トランザクションで記録する)
+	DeleteTodoList(id string, mode string, actor domain_audit.AuditActor) (int, error)
+}
@@ -8,4 +8,6 @@ import (
 type IUserRepository interface {
 	// 全ユーザー取得
 	GetAllUsers() ([]domain_user.Users, error)
+	// 複数のidを指定してユーザーを取得
+	GetUsersByIds(ids []string) ([]domain_user.Users, error)
 }
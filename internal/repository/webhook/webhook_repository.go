@@ -0,0 +1,49 @@
+package repository_webhook
+
+import (
+	domain_webhook "backend/internal/domain/webhook"
+	"errors"
+	"time"
+)
+
+// Webhookが存在しない場合のエラー
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// 配信が存在しない場合のエラー
+var ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+// Webhookリポジトリ(IF)
+type IWebhookRepository interface {
+	// 特定のWebhookを取得
+	GetWebhookById(id string) (domain_webhook.Webhook, error)
+	// 特定のユーザーのWebhookを取得
+	GetWebhooksByUserId(userId string) ([]domain_webhook.Webhook, error)
+	// 特定のユーザーのWebhookの件数を取得
+	CountWebhooksByUserId(userId string) (int, error)
+	// 新しいWebhookを作成
+	CreateWebhook(webhook domain_webhook.Webhook) (domain_webhook.Webhook, error)
+	// 特定のWebhookを更新
+	UpdateWebhook(webhook domain_webhook.Webhook) (domain_webhook.Webhook, error)
+	// 特定のWebhookを削除(配信と送信履歴も削除する)
+	DeleteWebhook(id string) error
+	// 特定の配信を取得
+	GetDeliveryById(id string) (domain_webhook.WebhookDelivery, error)
+	// 特定のWebhookの配信を新しい順に取得(statusが空の場合は全て)
+	GetDeliveriesByWebhookId(webhookId string, status string, limit int, offset int) ([]domain_webhook.WebhookDelivery, error)
+	// 特定の配信の送信履歴を取得
+	GetDeliveryAttempts(deliveryId string) ([]domain_webhook.WebhookDeliveryAttempt, error)
+	// 配信と同じ内容の配信を新しく登録する(手動の再送)
+	CreateRedelivery(deliveryId string) (domain_webhook.WebhookDelivery, error)
+	// 送信日時を過ぎた配信を確保する(送信回数を加算し、leaseの間は他で確保されないようにする)
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]domain_webhook.WebhookDispatch, error)
+	// 送信の結果を記録する(nextAttemptAtがnilの場合は成功・失敗を確定する)
+	RecordDeliveryAttempt(attempt domain_webhook.WebhookDeliveryAttempt, status string, nextAttemptAt *time.Time) error
+	// 平文のまま保存されているシークレットを暗号化し、暗号化した件数を返す
+	EncryptPlaintextSecrets() (int, error)
+}
+
+// Webhookの送信(IF)
+type IWebhookSender interface {
+	// Webhookを送信する(応答が無い場合はエラーを返す)
+	Send(request domain_webhook.WebhookRequest) (domain_webhook.WebhookResponse, error)
+}
@@ -0,0 +1,471 @@
+package router
+
+import (
+	"backend/config"
+	interfaces_auth "backend/internal/interfaces/auth"
+	interfaces_graphql "backend/internal/interfaces/graphql"
+	"backend/internal/middleware"
+	pkg_logger "backend/internal/pkg/logger"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/labstack/echo/v4"
+)
+
+// GraphQL over HTTPのメディアタイプ
+const (
+	mediaTypeGraphQLResponse = "application/graphql-response+json"
+	mediaTypeJSON            = "application/json"
+	mediaTypeMultipart       = "multipart/form-data"
+)
+
+// リクエストボディが大きすぎる場合のエラー
+var errRequestBodyTooLarge = errors.New("request body is too large")
+
+// GraphQLリクエスト
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *interfaces_graphql.PersistedQueryExtension `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// GraphQLレスポンス
+// リクエストエラーで実行されなかった場合はdataを含めない。
+type graphQLResponse struct {
+	Data       interface{}                `json:"data,omitempty"`
+	Errors     []gqlerrors.FormattedError `json:"errors,omitempty"`
+	Extensions map[string]interface{}     `json:"extensions,omitempty"`
+}
+
+// GraphQLエンドポイント
+// GraphQL over HTTP仕様に従い、GET/POST・バッチリクエスト・コンテンツネゴシエーションを扱う。
+type graphQLEndpoint struct {
+	logger    *pkg_logger.AppLogger
+	conf      *config.AppConfig
+	schema    graphql.Schema
+	graphql   *interfaces_graphql.GraphQLHandler
+	auth      *interfaces_auth.AuthHandler
+	limiter   *interfaces_graphql.QueryLimiter
+	persisted *interfaces_graphql.PersistedQueryStore
+	rateLimit *middleware.GraphQLRateLimiter
+}
+
+// GETリクエスト(クエリのみ)
+func (g *graphQLEndpoint) handleGet(c echo.Context) error {
+	mediaType, ok := negotiateMediaType(c.Request().Header.Get(echo.HeaderAccept))
+	if !ok {
+		g.logger.ErrorLog.Println("Not acceptable media type")
+		return c.NoContent(http.StatusNotAcceptable)
+	}
+	ctx := g.authorize(c)
+
+	// クエリパラメータからリクエストを組み立てる
+	params := c.QueryParams()
+	req := graphQLRequest{
+		Query:         params.Get("query"),
+		OperationName: params.Get("operationName"),
+	}
+	var err error
+	if v := params.Get("variables"); v != "" {
+		if json.Unmarshal([]byte(v), &req.Variables) != nil {
+			err = errors.New("variables must be a JSON object")
+		}
+	}
+	if v := params.Get("extensions"); v != "" && err == nil {
+		if json.Unmarshal([]byte(v), &req.Extensions) != nil {
+			err = errors.New("extensions must be a JSON object")
+		}
+	}
+	if err != nil {
+		g.logger.ErrorLog.Println("Invalid GraphQL request", err)
+		if allowed, limitErr := g.allowRequests(c, ctx, nil); !allowed {
+			return limitErr
+		}
+		return g.respond(c, mediaType, http.StatusBadRequest, newErrorResult(err, "BAD_REQUEST"))
+	}
+
+	// レート制限
+	if allowed, limitErr := g.allowRequests(c, ctx, []graphQLRequest{req}); !allowed {
+		return limitErr
+	}
+
+	status, result := g.execute(c, ctx, req, mediaType, http.MethodGet)
+	return g.respond(c, mediaType, status, result)
+}
+
+// POSTリクエスト(単一・バッチ・multipart)
+func (g *graphQLEndpoint) handlePost(c echo.Context) error {
+	mediaType, ok := negotiateMediaType(c.Request().Header.Get(echo.HeaderAccept))
+	if !ok {
+		g.logger.ErrorLog.Println("Not acceptable media type")
+		return c.NoContent(http.StatusNotAcceptable)
+	}
+
+	ctx := g.authorize(c)
+
+	// Content-Typeに応じてリクエストを解析
+	var reqs []graphQLRequest
+	var batch bool
+	var err error
+	contentType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	switch contentType {
+	case mediaTypeJSON:
+		reqs, batch, err = g.parseJSONBody(c)
+	case mediaTypeMultipart:
+		reqs, batch, err = g.parseMultipartBody(c)
+		if form := c.Request().MultipartForm; form != nil {
+			defer form.RemoveAll()
+		}
+	default:
+		g.logger.ErrorLog.Printf("Unsupported content type: %s", contentType)
+		if allowed, limitErr := g.allowRequests(c, ctx, nil); !allowed {
+			return limitErr
+		}
+		return g.respond(c, mediaType, http.StatusUnsupportedMediaType, newErrorResult(errors.New("content type must be application/json or multipart/form-data"), "BAD_REQUEST"))
+	}
+	if err != nil {
+		g.logger.ErrorLog.Println("Invalid GraphQL request", err)
+		if allowed, limitErr := g.allowRequests(c, ctx, nil); !allowed {
+			return limitErr
+		}
+		if errors.Is(err, errRequestBodyTooLarge) {
+			return g.respond(c, mediaType, http.StatusRequestEntityTooLarge, newErrorResult(err, "BAD_REQUEST"))
+		}
+		return g.respond(c, mediaType, http.StatusBadRequest, newErrorResult(err, "BAD_REQUEST"))
+	}
+
+	// レート制限(解析したオペレーションの種類ごとに、全て消費できる場合のみ消費する)
+	if allowed, limitErr := g.allowRequests(c, ctx, reqs); !allowed {
+		return limitErr
+	}
+
+	// バッチリクエスト
+	if batch {
+		// 全オペレーションを検証し、コストの合計が上限を超える場合はいずれも実行しない
+		prepared := make([]preparedRequest, 0, len(reqs))
+		total := 0
+		for _, req := range reqs {
+			p := g.prepare(c, req, mediaType, http.MethodPost)
+			if p.errResult == nil {
+				total += p.cost.Cost
+			}
+			prepared = append(prepared, p)
+		}
+		if err := g.limiter.ValidateTotal(total); err != nil {
+			result := newErrorResult(err, "GRAPHQL_VALIDATION_FAILED")
+			result.Extensions = map[string]interface{}{"cost": g.limiter.Extensions(interfaces_graphql.QueryCost{Cost: total})}
+			return g.respond(c, mediaType, requestErrorStatus(mediaType), result)
+		}
+
+		results := make([]graphQLResponse, 0, len(prepared))
+		for _, p := range prepared {
+			_, result := g.run(ctx, p, mediaType)
+			results = append(results, toGraphQLResponse(result))
+		}
+		return g.respond(c, mediaType, http.StatusOK, results)
+	}
+
+	// 単一リクエスト
+	status, result := g.execute(c, ctx, reqs[0], mediaType, http.MethodPost)
+	return g.respond(c, mediaType, status, result)
+}
+
+// JSONのリクエストボディを解析
+func (g *graphQLEndpoint) parseJSONBody(c echo.Context) ([]graphQLRequest, bool, error) {
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, g.conf.GraphQLMaxBodyBytes)
+	raw, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, false, errRequestBodyTooLarge
+		}
+		return nil, false, errors.New("failed to read request body")
+	}
+	raw = bytes.TrimSpace(raw)
+
+	// バッチリクエスト
+	if len(raw) > 0 && raw[0] == '[' {
+		var reqs []graphQLRequest
+		if err := json.Unmarshal(raw, &reqs); err != nil || len(reqs) == 0 {
+			return nil, false, errors.New("invalid GraphQL batch request")
+		}
+		if err := g.checkBatchSize(len(reqs)); err != nil {
+			return nil, false, err
+		}
+		return reqs, true, nil
+	}
+
+	// 単一リクエスト
+	var req graphQLRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, false, errors.New("invalid GraphQL request body")
+	}
+	return []graphQLRequest{req}, false, nil
+}
+
+// オペレーションの種類ごとの数を数え、レート制限のトークンを消費する
+// 判定できないオペレーション(解析できないリクエストを含む)はクエリとして扱う。
+// 消費できない場合は429のレスポンスを返す(戻り値はfalse)。
+func (g *graphQLEndpoint) allowRequests(c echo.Context, ctx context.Context, reqs []graphQLRequest) (bool, error) {
+	counts := map[string]int{}
+	for _, req := range reqs {
+		query := req.Query
+		if query == "" && req.Extensions.PersistedQuery != nil {
+			query, _ = g.persisted.Lookup(req.Extensions.PersistedQuery.Sha256Hash)
+		}
+		operationType, err := interfaces_graphql.OperationTypeOf(query, req.OperationName)
+		if err != nil || operationType != ast.OperationTypeMutation {
+			operationType = ast.OperationTypeQuery
+		}
+		counts[operationType]++
+	}
+	if len(reqs) == 0 {
+		counts[ast.OperationTypeQuery] = 1
+	}
+
+	userId, _ := ctx.Value(g.conf.UserID).(string)
+	return g.rateLimit.Allow(c, middleware.ClientKey(userId, c.RealIP()), counts)
+}
+
+// バッチリクエストのオペレーション数を検証
+func (g *graphQLEndpoint) checkBatchSize(n int) error {
+	if g.conf.GraphQLMaxBatchSize > 0 && n > g.conf.GraphQLMaxBatchSize {
+		return fmt.Errorf("batch contains %d operations, exceeding maximum of %d", n, g.conf.GraphQLMaxBatchSize)
+	}
+	return nil
+}
+
+// トークンを検証し、認証情報・クライアントのIPアドレス・リクエストIDを設定したコンテキストを返す
+func (g *graphQLEndpoint) authorize(c echo.Context) context.Context {
+	ctx, _ := g.auth.ParseAndAuthorizeToken(c, g.conf.UserRole)
+	if ctx == nil {
+		ctx = c.Request().Context()
+	}
+	ctx = interfaces_auth.WithClientIP(ctx, c.RealIP())
+	return interfaces_auth.WithRequestID(ctx, requestID(c))
+}
+
+// リクエストIDを取得(X-Request-IDヘッダーが無い場合は生成する)
+// レスポンスヘッダーにも設定し、クライアントが監査ログと突き合わせられるようにする。
+func requestID(c echo.Context) string {
+	id := strings.TrimSpace(c.Request().Header.Get(echo.HeaderXRequestID))
+	if id == "" || len(id) > 128 {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err == nil {
+			id = hex.EncodeToString(b)
+		}
+	}
+	c.Response().Header().Set(echo.HeaderXRequestID, id)
+	return id
+}
+
+// 実行前の検証を終えたGraphQLリクエスト
+type preparedRequest struct {
+	req   graphQLRequest
+	query string
+	cost  interfaces_graphql.QueryCost
+	// 検証で拒否した場合のステータスコードと結果(nilの場合は実行できる)
+	errStatus int
+	errResult *graphql.Result
+}
+
+// GraphQLリクエストを実行し、ステータスコードと結果を返す
+func (g *graphQLEndpoint) execute(c echo.Context, ctx context.Context, req graphQLRequest, mediaType string, method string) (int, *graphql.Result) {
+	return g.run(ctx, g.prepare(c, req, mediaType, method), mediaType)
+}
+
+// 永続化クエリを解決し、実行前の検証(GETでのミューテーション・深さ・エイリアス数・コスト)を行う
+func (g *graphQLEndpoint) prepare(c echo.Context, req graphQLRequest, mediaType string, method string) preparedRequest {
+	p := preparedRequest{req: req}
+
+	// 永続化クエリ(APQ・許可リスト)の解決
+	query, err := g.persisted.Resolve(req.Query, req.Extensions.PersistedQuery)
+	if err != nil {
+		switch {
+		case errors.Is(err, interfaces_graphql.ErrPersistedQueryNotFound):
+			// クライアントにクエリ本文付きでの再送を促す
+			return p.reject(http.StatusOK, newErrorResult(err, "PERSISTED_QUERY_NOT_FOUND"))
+		case errors.Is(err, interfaces_graphql.ErrOperationNotAllowed):
+			return p.reject(http.StatusForbidden, newErrorResult(err, "OPERATION_NOT_ALLOWED"))
+		default:
+			return p.reject(http.StatusBadRequest, newErrorResult(err, "PERSISTED_QUERY_INVALID"))
+		}
+	}
+	if query == "" {
+		g.logger.ErrorLog.Println("Invalid GraphQL query")
+		return p.reject(http.StatusBadRequest, newErrorResult(errors.New("query is required"), "BAD_REQUEST"))
+	}
+	p.query = query
+
+	// GETではミューテーションを受け付けない
+	if method == http.MethodGet {
+		operationType, err := interfaces_graphql.OperationTypeOf(query, req.OperationName)
+		if err == nil && operationType != ast.OperationTypeQuery {
+			g.logger.ErrorLog.Printf("%s is not allowed with GET", operationType)
+			c.Response().Header().Set(echo.HeaderAllow, http.MethodPost)
+			return p.reject(http.StatusMethodNotAllowed, newErrorResult(errors.New("only query operations can be executed with GET"), "METHOD_NOT_ALLOWED"))
+		}
+	}
+
+	// クエリの深さ・エイリアス数・コストを検証
+	cost, err := g.limiter.Validate(g.schema, query, req.OperationName, req.Variables)
+	p.cost = cost
+	if err != nil {
+		g.logger.ErrorLog.Println("GraphQL query rejected", err)
+		result := newErrorResult(err, "GRAPHQL_VALIDATION_FAILED")
+		result.Extensions = map[string]interface{}{"cost": g.limiter.Extensions(cost)}
+		return p.reject(requestErrorStatus(mediaType), result)
+	}
+
+	return p
+}
+
+// 検証で拒否したリクエストとする
+func (p preparedRequest) reject(status int, result *graphql.Result) preparedRequest {
+	p.errStatus = status
+	p.errResult = result
+	return p
+}
+
+// 検証済みのGraphQLリクエストを実行し、ステータスコードと結果を返す
+func (g *graphQLEndpoint) run(ctx context.Context, p preparedRequest, mediaType string) (int, *graphql.Result) {
+	if p.errResult != nil {
+		return p.errStatus, p.errResult
+	}
+	req, query, cost := p.req, p.query, p.cost
+
+	// GraphQLの実行(DataLoaderはオペレーション単位とする)
+	result := graphql.Do(graphql.Params{
+		Schema:         g.schema,
+		RequestString:  query,
+		OperationName:  req.OperationName,
+		Context:        g.graphql.WithLoaders(ctx),
+		VariableValues: req.Variables,
+	})
+
+	// 計算したコストをextensionsに設定
+	if result.Extensions == nil {
+		result.Extensions = map[string]interface{}{}
+	}
+	result.Extensions["cost"] = g.limiter.Extensions(cost)
+
+	if len(result.Errors) > 0 {
+		g.logger.ErrorLog.Println("GraphQL errors", result.Errors)
+		// 構文・検証エラーで実行されなかった場合はリクエストエラー
+		if result.Data == nil {
+			return requestErrorStatus(mediaType), result
+		}
+	}
+
+	return http.StatusOK, result
+}
+
+// レスポンスを返す
+func (g *graphQLEndpoint) respond(c echo.Context, mediaType string, status int, body interface{}) error {
+	if result, ok := body.(*graphql.Result); ok {
+		body = toGraphQLResponse(result)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		g.logger.ErrorLog.Printf("Failed to encode response: %v", err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	return c.Blob(status, mediaType+"; charset=utf-8", data)
+}
+
+// 実行結果をレスポンスに変換
+func toGraphQLResponse(result *graphql.Result) graphQLResponse {
+	return graphQLResponse{
+		Data:       result.Data,
+		Errors:     result.Errors,
+		Extensions: result.Extensions,
+	}
+}
+
+// リクエストエラー時のステータスコード
+// application/jsonの場合は互換性のため200を返す。
+func requestErrorStatus(mediaType string) int {
+	if mediaType == mediaTypeGraphQLResponse {
+		return http.StatusBadRequest
+	}
+	return http.StatusOK
+}
+
+// Acceptヘッダーからレスポンスのメディアタイプを決定する
+// Acceptヘッダーが無い場合はapplication/jsonとする。
+func negotiateMediaType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return mediaTypeJSON, true
+	}
+
+	type candidate struct {
+		mediaType string
+		q         float64
+		order     int
+	}
+	candidates := []candidate{}
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		switch mediaType {
+		case mediaTypeGraphQLResponse, mediaTypeJSON:
+		case "*/*", "application/*":
+			mediaType = mediaTypeJSON
+		default:
+			continue
+		}
+		candidates = append(candidates, candidate{mediaType: mediaType, q: q, order: i})
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	// q値が高いもの、同じ場合はgraphql-response+json、次に記載順を優先
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].q != candidates[j].q {
+			return candidates[i].q > candidates[j].q
+		}
+		if candidates[i].mediaType != candidates[j].mediaType {
+			return candidates[i].mediaType == mediaTypeGraphQLResponse
+		}
+		return candidates[i].order < candidates[j].order
+	})
+	return candidates[0].mediaType, true
+}
+
+// エラーコード付きのGraphQLエラーレスポンスを生成
+func newErrorResult(err error, code string) *graphql.Result {
+	formatted := gqlerrors.FormatError(err)
+	formatted.Extensions = map[string]interface{}{"code": code}
+	return &graphql.Result{Errors: []gqlerrors.FormattedError{formatted}}
+}
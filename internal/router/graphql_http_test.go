@@ -0,0 +1,74 @@
+package router
+
+import (
+	"backend/config"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestParseJSONBody(t *testing.T) {
+	endpoint := &graphQLEndpoint{conf: &config.AppConfig{GraphQLMaxBodyBytes: 64, GraphQLMaxBatchSize: 2}}
+
+	tests := []struct {
+		name    string
+		body    string
+		count   int
+		batch   bool
+		wantErr error
+	}{
+		{name: "single", body: `{"query":"{ a }"}`, count: 1},
+		{name: "batch", body: `[{"query":"{ a }"},{"query":"{ b }"}]`, count: 2, batch: true},
+		{name: "batch too large", body: `[{"query":"{a}"},{"query":"{b}"},{"query":"{c}"}]`, wantErr: errors.New("batch contains 3 operations, exceeding maximum of 2")},
+		{name: "empty batch", body: `[]`, wantErr: errors.New("invalid GraphQL batch request")},
+		{name: "body too large", body: `{"query":"` + strings.Repeat("a", 100) + `"}`, wantErr: errRequestBodyTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(tt.body))
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			reqs, batch, err := endpoint.parseJSONBody(c)
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(reqs) != tt.count || batch != tt.batch {
+				t.Errorf("got %d requests (batch %v), want %d (batch %v)", len(reqs), batch, tt.count, tt.batch)
+			}
+		})
+	}
+}
+
+func TestNegotiateMediaType(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{accept: "", want: mediaTypeJSON, ok: true},
+		{accept: "application/json", want: mediaTypeJSON, ok: true},
+		{accept: "application/graphql-response+json, application/json", want: mediaTypeGraphQLResponse, ok: true},
+		{accept: "application/json, application/graphql-response+json", want: mediaTypeGraphQLResponse, ok: true},
+		{accept: "application/json;q=1, application/graphql-response+json;q=0.5", want: mediaTypeJSON, ok: true},
+		{accept: "*/*", want: mediaTypeJSON, ok: true},
+		{accept: "text/html", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := negotiateMediaType(tt.accept)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("negotiateMediaType(%q) = %q, %v; want %q, %v", tt.accept, got, ok, tt.want, tt.ok)
+		}
+	}
+}
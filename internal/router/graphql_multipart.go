@@ -0,0 +1,170 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// multipartフォームをメモリに保持する最大サイズ(超過分は一時ファイル)
+const multipartMaxMemory = 8 << 20
+
+// multipartのリクエストボディを解析(GraphQL multipart request仕様)
+// operationsフィールドの操作に、mapフィールドの指定に従ってファイルを割り当てる。
+func (g *graphQLEndpoint) parseMultipartBody(c echo.Context) ([]graphQLRequest, bool, error) {
+	// リクエストサイズの上限(ファイル数×最大サイズ + operations等の余裕分)
+	maxBytes := g.conf.AttachmentMaxSize*int64(g.conf.AttachmentMaxFiles) + 1<<20
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxBytes)
+
+	if err := c.Request().ParseMultipartForm(multipartMaxMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, false, errRequestBodyTooLarge
+		}
+		return nil, false, errors.New("invalid multipart request")
+	}
+	form := c.Request().MultipartForm
+
+	// operationsの解析
+	operationsField := form.Value["operations"]
+	if len(operationsField) != 1 {
+		return nil, false, errors.New("multipart request must have exactly one operations field")
+	}
+	var operations interface{}
+	if err := json.Unmarshal([]byte(operationsField[0]), &operations); err != nil {
+		return nil, false, errors.New("operations must be valid JSON")
+	}
+
+	// mapの解析とファイルの割り当て
+	mapField := form.Value["map"]
+	if len(mapField) != 1 {
+		return nil, false, errors.New("multipart request must have exactly one map field")
+	}
+	fileMap := map[string][]string{}
+	if err := json.Unmarshal([]byte(mapField[0]), &fileMap); err != nil {
+		return nil, false, errors.New("map must be a JSON object of string arrays")
+	}
+	if g.conf.AttachmentMaxFiles > 0 && len(fileMap) > g.conf.AttachmentMaxFiles {
+		return nil, false, fmt.Errorf("too many files: maximum is %d", g.conf.AttachmentMaxFiles)
+	}
+	for key, paths := range fileMap {
+		files := form.File[key]
+		if len(files) != 1 {
+			return nil, false, fmt.Errorf("file %q is missing", key)
+		}
+		for _, path := range paths {
+			if err := setOperationPath(operations, path, files[0]); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	// リクエストに変換
+	switch ops := operations.(type) {
+	case map[string]interface{}:
+		req, err := requestFromOperation(ops)
+		if err != nil {
+			return nil, false, err
+		}
+		return []graphQLRequest{req}, false, nil
+	case []interface{}:
+		if len(ops) == 0 {
+			return nil, false, errors.New("invalid GraphQL batch request")
+		}
+		if err := g.checkBatchSize(len(ops)); err != nil {
+			return nil, false, err
+		}
+		reqs := make([]graphQLRequest, 0, len(ops))
+		for _, op := range ops {
+			m, ok := op.(map[string]interface{})
+			if !ok {
+				return nil, false, errors.New("invalid GraphQL batch request")
+			}
+			req, err := requestFromOperation(m)
+			if err != nil {
+				return nil, false, err
+			}
+			reqs = append(reqs, req)
+		}
+		return reqs, true, nil
+	default:
+		return nil, false, errors.New("operations must be an object or an array")
+	}
+}
+
+// operationsのパス(例: "variables.file", "0.variables.files.1")に値を設定
+func setOperationPath(root interface{}, path string, value interface{}) error {
+	segments := strings.Split(path, ".")
+	current := root
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if last {
+				if _, ok := node[segment]; !ok {
+					return fmt.Errorf("invalid map path %q", path)
+				}
+				node[segment] = value
+				return nil
+			}
+			current = node[segment]
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return fmt.Errorf("invalid map path %q", path)
+			}
+			if last {
+				node[index] = value
+				return nil
+			}
+			current = node[index]
+		default:
+			return fmt.Errorf("invalid map path %q", path)
+		}
+	}
+	return fmt.Errorf("invalid map path %q", path)
+}
+
+// operationsの1操作をリクエストに変換
+func requestFromOperation(op map[string]interface{}) (graphQLRequest, error) {
+	req := graphQLRequest{}
+
+	if v, ok := op["query"]; ok && v != nil {
+		query, ok := v.(string)
+		if !ok {
+			return req, errors.New("query must be a string")
+		}
+		req.Query = query
+	}
+	if v, ok := op["operationName"]; ok && v != nil {
+		name, ok := v.(string)
+		if !ok {
+			return req, errors.New("operationName must be a string")
+		}
+		req.OperationName = name
+	}
+	if v, ok := op["variables"]; ok && v != nil {
+		variables, ok := v.(map[string]interface{})
+		if !ok {
+			return req, errors.New("variables must be an object")
+		}
+		req.Variables = variables
+	}
+	if v, ok := op["extensions"]; ok && v != nil {
+		// extensionsにファイルは含まれないため、JSONを経由して変換する
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return req, errors.New("extensions must be an object")
+		}
+		if err := json.Unmarshal(raw, &req.Extensions); err != nil {
+			return req, errors.New("extensions must be an object")
+		}
+	}
+
+	return req, nil
+}
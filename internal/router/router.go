@@ -2,50 +2,55 @@ package router
 
 import (
 	"backend/config"
+	interfaces_attachment "backend/internal/interfaces/attachment"
 	interfaces_auth "backend/internal/interfaces/auth"
+	interfaces_calendar "backend/internal/interfaces/calendar"
 	interfaces_graphql "backend/internal/interfaces/graphql"
+	interfaces_transfer "backend/internal/interfaces/transfer"
+	"backend/internal/middleware"
 	pkg_logger "backend/internal/pkg/logger"
 	"net/http"
 
-	"github.com/graphql-go/graphql"
 	"github.com/labstack/echo/v4"
 )
 
 // ルーティングの設定
-func SetUpRouter(e *echo.Echo, l *pkg_logger.AppLogger, conf *config.AppConfig, gh *interfaces_graphql.GraphQLHandler, ah *interfaces_auth.AuthHandler) {
+func SetUpRouter(e *echo.Echo, l *pkg_logger.AppLogger, conf *config.AppConfig, gh *interfaces_graphql.GraphQLHandler, ah *interfaces_auth.AuthHandler, ql *interfaces_graphql.QueryLimiter, pq *interfaces_graphql.PersistedQueryStore, rl *middleware.GraphQLRateLimiter, th *interfaces_transfer.TodoTransferHandler, ch *interfaces_calendar.CalendarFeedHandler, ath *interfaces_attachment.AttachmentHandler) {
 	l.InfoLog.Println("Setting up router...")
 
+	// GraphQLエンドポイント
+	endpoint := &graphQLEndpoint{
+		logger:    l,
+		conf:      conf,
+		schema:    gh.GetSchema(),
+		graphql:   gh,
+		auth:      ah,
+		limiter:   ql,
+		persisted: pq,
+		rateLimit: rl,
+	}
+
 	// GraphQLのルーティング
-	e.POST("/graphql", func(c echo.Context) error {
-		// JSON ボディから `query` を取り出す
-		var body struct {
-			Query     string                 `json:"query"`
-			Variables map[string]interface{} `json:"variables"`
-		}
-		err := c.Bind(&body)
-		if err != nil || body.Query == "" {
-			l.ErrorLog.Println("Invalid GraphQL query", err)
-			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid GraphQL query"})
-		}
-
-		// トークンを取得
-		changedCtx, _ := ah.ParseAndAuthorizeToken(c, conf.UserRole)
-
-		// GraphQLの実行
-		result := graphql.Do(graphql.Params{
-			Schema:         gh.GetSchema(),
-			RequestString:  body.Query,
-			Context:        changedCtx,
-			VariableValues: body.Variables,
-		})
+	// レート制限はリクエストの解析・認証の後にエンドポイント内で行う
+	e.GET("/graphql", endpoint.handleGet)
+	e.POST("/graphql", endpoint.handlePost)
+
+	// Todoのエクスポート
+	e.GET("/todos/export", th.ExportTodos)
 
-		if len(result.Errors) > 0 {
-			l.ErrorLog.Println("GraphQL errors", result.Errors)
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "GraphQL errors"})
-		}
+	// 添付ファイルのダウンロード
+	e.GET("/attachments/:id", ath.DownloadAttachment)
 
-		return c.JSON(http.StatusOK, result)
-	})
+	// カレンダーの購読フィード(URLの秘密のトークンで認証する)
+	e.GET("/calendar/:file", ch.GetFeed)
+
+	// GraphiQL(開発環境のみ)
+	if conf.IsDevelopment() {
+		l.InfoLog.Println("GraphiQL is enabled at /graphiql")
+		e.GET("/graphiql", func(c echo.Context) error {
+			return c.HTMLBlob(http.StatusOK, interfaces_graphql.GraphiQLPage())
+		})
+	}
 
 	l.InfoLog.Println("Router setup complete")
 }
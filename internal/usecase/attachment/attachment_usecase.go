@@ -0,0 +1,252 @@
+package usecase_attachment
+
+import (
+	domain_attachment "backend/internal/domain/attachment"
+	domain_share "backend/internal/domain/share"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_attachment "backend/internal/repository/attachment"
+	repository_storage "backend/internal/repository/storage"
+	usecase_todo "backend/internal/usecase/todo"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// 添付ファイルユースケース(IF)
+type IAttachmentUsecase interface {
+	// 複数のTodoの添付ファイルを取得
+	GetAttachmentsByTodoIds(todoIds []string) ([]domain_attachment.Attachment, error)
+	// Todoにファイルを添付
+	AddAttachment(userId string, todoId string, fileName string, size int64, content io.Reader) (domain_attachment.Attachment, error)
+	// 添付ファイルを削除
+	RemoveAttachment(userId string, id string) error
+	// 添付ファイルの内容を取得(呼び出し側で内容をCloseすること)
+	OpenAttachment(userId string, id string) (domain_attachment.Attachment, io.ReadCloser, error)
+}
+
+// 添付ファイルユースケース(Impl)
+type AttachmentUsecase struct {
+	Logger               *pkg_logger.AppLogger
+	attachmentRepository repository_attachment.IAttachmentRepository
+	todoUsecase          usecase_todo.ITodoUsecase
+	blobStorage          repository_storage.IBlobStorage
+	maxSize              int64
+	allowedTypes         map[string]bool
+}
+
+// 添付ファイルユースケースのインスタンス化
+// Todoへの権限はtuで確認する。maxSizeは1ファイルの最大サイズ(byte)、allowedTypesは許可するMIMEタイプ。
+func NewAttachmentUsecase(l *pkg_logger.AppLogger, ar repository_attachment.IAttachmentRepository, tu usecase_todo.ITodoUsecase, bs repository_storage.IBlobStorage, maxSize int64, allowedTypes []string) IAttachmentUsecase {
+	types := make(map[string]bool, len(allowedTypes))
+	for _, t := range allowedTypes {
+		types[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+
+	return &AttachmentUsecase{
+		Logger:               l,
+		attachmentRepository: ar,
+		todoUsecase:          tu,
+		blobStorage:          bs,
+		maxSize:              maxSize,
+		allowedTypes:         types,
+	}
+}
+
+// 複数のTodoの添付ファイルを取得
+func (u *AttachmentUsecase) GetAttachmentsByTodoIds(todoIds []string) ([]domain_attachment.Attachment, error) {
+	u.Logger.InfoLog.Println("GetAttachmentsByTodoIds called")
+
+	// バリデーション
+	if len(todoIds) == 0 {
+		return []domain_attachment.Attachment{}, nil
+	}
+
+	// 添付ファイルリポジトリから取得(repository層)
+	attachments, err := u.attachmentRepository.GetAttachmentsByTodoIds(todoIds)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get attachments by todo_ids: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d attachments", len(attachments))
+	return attachments, nil
+}
+
+// Todoにファイルを添付
+func (u *AttachmentUsecase) AddAttachment(userId string, todoId string, fileName string, size int64, content io.Reader) (domain_attachment.Attachment, error) {
+	u.Logger.InfoLog.Println("AddAttachment called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_attachment.Attachment{}, errors.New("user_id is empty")
+	}
+	if todoId == "" {
+		u.Logger.ErrorLog.Println("todo_id is empty")
+		return domain_attachment.Attachment{}, errors.New("todo_id is empty")
+	}
+	fileName = filepath.Base(strings.TrimSpace(fileName))
+	if fileName == "" || fileName == "." || fileName == "/" {
+		u.Logger.ErrorLog.Println("file name is empty")
+		return domain_attachment.Attachment{}, errors.New("file name is empty")
+	}
+	if size <= 0 {
+		u.Logger.ErrorLog.Println("file is empty")
+		return domain_attachment.Attachment{}, errors.New("file is empty")
+	}
+	if u.maxSize > 0 && size > u.maxSize {
+		u.Logger.ErrorLog.Printf("File is too large: %d bytes", size)
+		return domain_attachment.Attachment{}, errors.New("file is too large")
+	}
+
+	// Todoの編集権限チェック
+	_, err := u.todoUsecase.AuthorizeTodo(userId, todoId, domain_share.ShareRoleEditor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to authorize todo: %v", err)
+		return domain_attachment.Attachment{}, err
+	}
+
+	// 先頭のバイト列からファイルの種類を判定する(クライアントの申告は信用しない)
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		u.Logger.ErrorLog.Printf("Failed to read file: %v", err)
+		return domain_attachment.Attachment{}, err
+	}
+	head = head[:n]
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !u.allowedTypes[contentType] {
+		u.Logger.ErrorLog.Printf("File type is not allowed: %s", contentType)
+		return domain_attachment.Attachment{}, errors.New("file type is not allowed")
+	}
+
+	// ストレージに保存
+	storageKey, err := newStorageKey(todoId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to generate storage key: %v", err)
+		return domain_attachment.Attachment{}, err
+	}
+	// 申告されたサイズを超えて書き込まないよう制限する
+	reader := io.LimitReader(io.MultiReader(bytes.NewReader(head), content), size)
+	err = u.blobStorage.Put(storageKey, reader)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to store file: %v", err)
+		return domain_attachment.Attachment{}, err
+	}
+
+	// 添付ファイル情報を作成(repository層)
+	attachment, err := u.attachmentRepository.CreateAttachment(domain_attachment.Attachment{
+		TodoId:      todoId,
+		UserId:      userId,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  storageKey,
+	})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to create attachment: %v", err)
+		// 保存したファイルを削除
+		if delErr := u.blobStorage.Delete(storageKey); delErr != nil {
+			u.Logger.ErrorLog.Printf("Failed to delete orphan file: %v", delErr)
+		}
+		return domain_attachment.Attachment{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Added attachment: %v", attachment.ID)
+	return attachment, nil
+}
+
+// 添付ファイルを削除
+func (u *AttachmentUsecase) RemoveAttachment(userId string, id string) error {
+	u.Logger.InfoLog.Println("RemoveAttachment called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("id is empty")
+		return errors.New("id is empty")
+	}
+
+	// 添付したTodoの編集権限チェック
+	attachment, err := u.attachmentRepository.GetAttachmentById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get attachment: %v", err)
+		return errors.New("attachment not found")
+	}
+	_, err = u.todoUsecase.AuthorizeTodo(userId, attachment.TodoId, domain_share.ShareRoleEditor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to authorize todo: %v", err)
+		return err
+	}
+
+	// 添付ファイル情報を削除(repository層)
+	err = u.attachmentRepository.DeleteAttachment(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to delete attachment: %v", err)
+		return err
+	}
+
+	// ストレージから削除(失敗してもメタデータは削除済みのため、ログのみ出力する)
+	err = u.blobStorage.Delete(attachment.StorageKey)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to delete file: %v", err)
+	}
+
+	u.Logger.InfoLog.Printf("Removed attachment: %v", id)
+	return nil
+}
+
+// 添付ファイルの内容を取得(呼び出し側で内容をCloseすること)
+func (u *AttachmentUsecase) OpenAttachment(userId string, id string) (domain_attachment.Attachment, io.ReadCloser, error) {
+	u.Logger.InfoLog.Println("OpenAttachment called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_attachment.Attachment{}, nil, errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("id is empty")
+		return domain_attachment.Attachment{}, nil, errors.New("id is empty")
+	}
+
+	// 添付したTodoの閲覧権限チェック
+	attachment, err := u.attachmentRepository.GetAttachmentById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get attachment: %v", err)
+		return domain_attachment.Attachment{}, nil, errors.New("attachment not found")
+	}
+	_, err = u.todoUsecase.AuthorizeTodo(userId, attachment.TodoId, domain_share.ShareRoleViewer)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to authorize todo: %v", err)
+		return domain_attachment.Attachment{}, nil, err
+	}
+
+	// ストレージから取得
+	content, err := u.blobStorage.Get(attachment.StorageKey)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to open file: %v", err)
+		return domain_attachment.Attachment{}, nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Opened attachment: %v", id)
+	return attachment, content, nil
+}
+
+// ストレージのキーを生成
+func newStorageKey(todoId string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "todos/" + todoId + "/" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,204 @@
+package usecase_audit
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_audit "backend/internal/repository/audit"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// 監査ログの取得件数
+const (
+	defaultAuditLogLimit = 20
+	maxAuditLogLimit     = 100
+)
+
+// 変更履歴の差分に含めない項目(操作のたびに変わるため)
+var historyIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// 監査ログユースケース(IF)
+type IAuditLogUsecase interface {
+	// 条件に一致する監査ログと総件数を取得
+	GetAuditLogs(filter domain_audit.AuditLogFilter) ([]domain_audit.AuditLog, int, error)
+	// 複数のTodoの変更履歴を取得(limitが0の場合は既定の件数)
+	GetTodoHistoryPages(todoIds []string, limit int, offset int) ([]domain_audit.HistoryPage, error)
+}
+
+// 監査ログユースケース(Impl)
+type AuditLogUsecase struct {
+	Logger             *pkg_logger.AppLogger
+	auditLogRepository repository_audit.IAuditLogRepository
+}
+
+// 監査ログユースケースのインスタンス化
+func NewAuditLogUsecase(l *pkg_logger.AppLogger, alr repository_audit.IAuditLogRepository) IAuditLogUsecase {
+	return &AuditLogUsecase{
+		Logger:             l,
+		auditLogRepository: alr,
+	}
+}
+
+// 条件に一致する監査ログと総件数を取得
+func (u *AuditLogUsecase) GetAuditLogs(filter domain_audit.AuditLogFilter) ([]domain_audit.AuditLog, int, error) {
+	u.Logger.InfoLog.Println("GetAuditLogs called")
+
+	// バリデーション
+	switch filter.Action {
+	case "", domain_audit.AuditActionCreate, domain_audit.AuditActionUpdate, domain_audit.AuditActionDelete,
+		domain_audit.AuditActionRestore, domain_audit.AuditActionPurge:
+	default:
+		u.Logger.ErrorLog.Printf("Invalid action: %s", filter.Action)
+		return nil, 0, errors.New("invalid action")
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Since.Before(*filter.Until) {
+		u.Logger.ErrorLog.Println("since must be before until")
+		return nil, 0, errors.New("since must be before until")
+	}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		u.Logger.ErrorLog.Println("limit and offset must not be negative")
+		return nil, 0, errors.New("limit and offset must not be negative")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLogLimit
+	}
+	if filter.Limit > maxAuditLogLimit {
+		filter.Limit = maxAuditLogLimit
+	}
+
+	// 監査ログリポジトリから取得(repository層)
+	logs, total, err := u.auditLogRepository.GetAuditLogs(filter)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get audit logs: %v", err)
+		return nil, 0, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d audit logs", len(logs))
+	return logs, total, nil
+}
+
+// 複数のTodoの変更履歴を取得(limitが0の場合は既定の件数)
+func (u *AuditLogUsecase) GetTodoHistoryPages(todoIds []string, limit int, offset int) ([]domain_audit.HistoryPage, error) {
+	u.Logger.InfoLog.Println("GetTodoHistoryPages called")
+
+	// バリデーション
+	if limit < 0 || offset < 0 {
+		u.Logger.ErrorLog.Println("limit and offset must not be negative")
+		return nil, errors.New("limit and offset must not be negative")
+	}
+	if limit == 0 {
+		limit = defaultAuditLogLimit
+	}
+	if limit > maxAuditLogLimit {
+		limit = maxAuditLogLimit
+	}
+	if len(todoIds) == 0 {
+		return []domain_audit.HistoryPage{}, nil
+	}
+
+	// 監査ログリポジトリから取得(repository層)
+	logs, totals, err := u.auditLogRepository.GetAuditLogsByEntityIds(domain_audit.AuditEntityTodo, todoIds, limit, offset)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo history: %v", err)
+		return nil, err
+	}
+
+	// 監査ログの変更前後の値から、変更された項目を求める
+	pages := make(map[string]*domain_audit.HistoryPage, len(totals))
+	for entityId, total := range totals {
+		pages[entityId] = &domain_audit.HistoryPage{EntityId: entityId, Items: []domain_audit.HistoryEntry{}, TotalCount: total}
+	}
+	for _, log := range logs {
+		changes, err := diffSnapshots(log.Before, log.After)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to diff audit log %s: %v", log.ID, err)
+			return nil, err
+		}
+		page, ok := pages[log.EntityId]
+		if !ok {
+			continue
+		}
+		page.Items = append(page.Items, domain_audit.HistoryEntry{
+			ID:        log.ID,
+			EntityId:  log.EntityId,
+			ActorId:   log.ActorId,
+			Action:    log.Action,
+			Changes:   changes,
+			CreatedAt: log.CreatedAt,
+		})
+	}
+
+	result := make([]domain_audit.HistoryPage, 0, len(pages))
+	for _, page := range pages {
+		result = append(result, *page)
+	}
+
+	u.Logger.InfoLog.Printf("Fetched history of %d todos", len(result))
+	return result, nil
+}
+
+// 変更前後のスナップショット(JSONオブジェクト)を比較し、値が異なる項目を項目名順に返す
+// 作成時(変更前がnull)は値のある項目を、完全削除時(変更後がnull)は値のあった項目を返す。
+func diffSnapshots(before json.RawMessage, after json.RawMessage) ([]domain_audit.FieldChange, error) {
+	beforeFields, err := snapshotFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := snapshotFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(beforeFields)+len(afterFields))
+	for field := range beforeFields {
+		fields = append(fields, field)
+	}
+	for field := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []domain_audit.FieldChange{}
+	for _, field := range fields {
+		if historyIgnoredFields[field] {
+			continue
+		}
+		b, a := beforeFields[field], afterFields[field]
+		if bytes.Equal(b, a) {
+			continue
+		}
+		changes = append(changes, domain_audit.FieldChange{Field: field, Before: b, After: a})
+	}
+	return changes, nil
+}
+
+// スナップショットを項目ごとの値に分解(nullの項目は含めない、値は空白を除いた形に揃える)
+func snapshotFields(snapshot json.RawMessage) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if len(snapshot) == 0 {
+		return fields, nil
+	}
+	raw := map[string]json.RawMessage{}
+	err := json.Unmarshal(snapshot, &raw)
+	if err != nil {
+		return nil, err
+	}
+	for field, value := range raw {
+		var compacted bytes.Buffer
+		err = json.Compact(&compacted, value)
+		if err != nil {
+			return nil, err
+		}
+		if compacted.String() == "null" {
+			continue
+		}
+		fields[field] = compacted.Bytes()
+	}
+	return fields, nil
+}
@@ -0,0 +1,193 @@
+package usecase_audit
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_audit "backend/internal/repository/audit"
+	"encoding/json"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// 渡された検索条件を記録する監査ログリポジトリ
+type fakeAuditLogRepository struct {
+	repository_audit.IAuditLogRepository
+	filters []domain_audit.AuditLogFilter
+	logs    []domain_audit.AuditLog
+	totals  map[string]int
+}
+
+func (r *fakeAuditLogRepository) GetAuditLogs(filter domain_audit.AuditLogFilter) ([]domain_audit.AuditLog, int, error) {
+	r.filters = append(r.filters, filter)
+	return r.logs, len(r.logs), nil
+}
+
+func (r *fakeAuditLogRepository) GetAuditLogsByEntityIds(entityType string, entityIds []string, limit int, offset int) ([]domain_audit.AuditLog, map[string]int, error) {
+	r.filters = append(r.filters, domain_audit.AuditLogFilter{EntityType: entityType, Limit: limit, Offset: offset})
+	return r.logs, r.totals, nil
+}
+
+// 検索条件の検証と取得件数の既定値・上限
+func TestGetAuditLogsValidatesFilter(t *testing.T) {
+	since := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	until := since.Add(24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		filter    domain_audit.AuditLogFilter
+		wantErr   string
+		wantLimit int
+	}{
+		{name: "default limit", filter: domain_audit.AuditLogFilter{}, wantLimit: defaultAuditLogLimit},
+		{name: "limit is capped", filter: domain_audit.AuditLogFilter{Limit: 1000}, wantLimit: maxAuditLogLimit},
+		{name: "create", filter: domain_audit.AuditLogFilter{Action: domain_audit.AuditActionCreate, Limit: 5}, wantLimit: 5},
+		{name: "update", filter: domain_audit.AuditLogFilter{Action: domain_audit.AuditActionUpdate}, wantLimit: defaultAuditLogLimit},
+		{name: "delete", filter: domain_audit.AuditLogFilter{Action: domain_audit.AuditActionDelete}, wantLimit: defaultAuditLogLimit},
+		{name: "restore", filter: domain_audit.AuditLogFilter{Action: domain_audit.AuditActionRestore}, wantLimit: defaultAuditLogLimit},
+		{name: "purge", filter: domain_audit.AuditLogFilter{Action: domain_audit.AuditActionPurge}, wantLimit: defaultAuditLogLimit},
+		{name: "period", filter: domain_audit.AuditLogFilter{Since: &since, Until: &until}, wantLimit: defaultAuditLogLimit},
+		{name: "unknown action", filter: domain_audit.AuditLogFilter{Action: "drop"}, wantErr: "invalid action"},
+		{name: "since after until", filter: domain_audit.AuditLogFilter{Since: &until, Until: &since}, wantErr: "since must be before until"},
+		{name: "empty period", filter: domain_audit.AuditLogFilter{Since: &since, Until: &since}, wantErr: "since must be before until"},
+		{name: "negative offset", filter: domain_audit.AuditLogFilter{Offset: -1}, wantErr: "limit and offset must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeAuditLogRepository{}
+			u := NewAuditLogUsecase(newTestLogger(), r)
+
+			_, _, err := u.GetAuditLogs(tt.filter)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("GetAuditLogs() error = %v, want %s", err, tt.wantErr)
+				}
+				if len(r.filters) != 0 {
+					t.Errorf("repository called with %v for an invalid filter", r.filters)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetAuditLogs() unexpected error: %v", err)
+			}
+			if len(r.filters) != 1 || r.filters[0].Limit != tt.wantLimit || r.filters[0].Action != tt.filter.Action {
+				t.Errorf("repository filters = %+v, want action %q with limit %d", r.filters, tt.filter.Action, tt.wantLimit)
+			}
+		})
+	}
+}
+
+// 変更前後のスナップショットから、値が変わった項目だけを項目名順に返す
+func TestDiffSnapshots(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []domain_audit.FieldChange
+	}{
+		{
+			name:   "update",
+			before: `{"description": "old", "completed": false, "priority": "medium", "updated_at": "2025-01-01T00:00:00Z"}`,
+			after:  `{"description":"new","completed":true,"priority":"medium","updated_at":"2025-01-02T00:00:00Z"}`,
+			want: []domain_audit.FieldChange{
+				{Field: "completed", Before: json.RawMessage(`false`), After: json.RawMessage(`true`)},
+				{Field: "description", Before: json.RawMessage(`"old"`), After: json.RawMessage(`"new"`)},
+			},
+		},
+		{
+			name:   "create",
+			before: ``,
+			after:  `{"description": "new", "due_at": null}`,
+			want:   []domain_audit.FieldChange{{Field: "description", After: json.RawMessage(`"new"`)}},
+		},
+		{
+			name:   "purge",
+			before: `{"description": "old", "list_id": null}`,
+			after:  `null`,
+			want:   []domain_audit.FieldChange{{Field: "description", Before: json.RawMessage(`"old"`)}},
+		},
+		{
+			name:   "field cleared",
+			before: `{"due_at": "2025-01-01T00:00:00Z"}`,
+			after:  `{"due_at": null}`,
+			want:   []domain_audit.FieldChange{{Field: "due_at", Before: json.RawMessage(`"2025-01-01T00:00:00Z"`)}},
+		},
+		{
+			name:   "only updated_at",
+			before: `{"description": "same", "updated_at": "2025-01-01T00:00:00Z"}`,
+			after:  `{"description": "same", "updated_at": "2025-01-02T00:00:00Z"}`,
+			want:   []domain_audit.FieldChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diffSnapshots(json.RawMessage(tt.before), json.RawMessage(tt.after))
+			if err != nil {
+				t.Fatalf("diffSnapshots() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffSnapshots() = %s, want %s", formatChanges(got), formatChanges(tt.want))
+			}
+		})
+	}
+
+	if _, err := diffSnapshots(json.RawMessage(`{"description":`), nil); err == nil {
+		t.Error("diffSnapshots() accepted a broken snapshot")
+	}
+}
+
+func formatChanges(changes []domain_audit.FieldChange) string {
+	s := "["
+	for _, c := range changes {
+		s += " " + c.Field + ":" + string(c.Before) + "->" + string(c.After)
+	}
+	return s + " ]"
+}
+
+// 変更履歴はTodoごとにまとめ、履歴が無いTodoも総件数0のページとして返す
+func TestGetTodoHistoryPages(t *testing.T) {
+	r := &fakeAuditLogRepository{
+		logs: []domain_audit.AuditLog{
+			{ID: "a2", EntityId: "t1", ActorId: "u1", Action: domain_audit.AuditActionUpdate, Before: json.RawMessage(`{"completed": false}`), After: json.RawMessage(`{"completed": true}`)},
+			{ID: "a1", EntityId: "t1", ActorId: "u1", Action: domain_audit.AuditActionCreate, After: json.RawMessage(`{"completed": false}`)},
+		},
+		totals: map[string]int{"t1": 5, "t2": 0},
+	}
+	u := NewAuditLogUsecase(newTestLogger(), r)
+
+	pages, err := u.GetTodoHistoryPages([]string{"t1", "t2"}, 0, 3)
+	if err != nil {
+		t.Fatalf("GetTodoHistoryPages() unexpected error: %v", err)
+	}
+	byId := map[string]domain_audit.HistoryPage{}
+	for _, p := range pages {
+		byId[p.EntityId] = p
+	}
+	if len(byId) != 2 || byId["t1"].TotalCount != 5 || len(byId["t2"].Items) != 0 {
+		t.Fatalf("pages = %+v", pages)
+	}
+	items := byId["t1"].Items
+	if len(items) != 2 || items[0].ID != "a2" || items[1].ID != "a1" {
+		t.Fatalf("t1 items = %+v, want a2, a1 in order", items)
+	}
+	if len(items[0].Changes) != 1 || items[0].Changes[0].Field != "completed" || string(items[0].Changes[0].After) != "true" {
+		t.Errorf("a2 changes = %s", formatChanges(items[0].Changes))
+	}
+	want := []domain_audit.AuditLogFilter{{EntityType: domain_audit.AuditEntityTodo, Limit: defaultAuditLogLimit, Offset: 3}}
+	if !reflect.DeepEqual(r.filters, want) {
+		t.Errorf("repository calls = %+v, want %+v", r.filters, want)
+	}
+
+	if _, err := u.GetTodoHistoryPages([]string{"t1"}, -1, 0); err == nil {
+		t.Error("GetTodoHistoryPages() accepted a negative limit")
+	}
+}
@@ -1,34 +1,63 @@
 package usecase_auth
 
 import (
+	domain_auth "backend/internal/domain/auth"
 	pkg_logger "backend/internal/pkg/logger"
 	repository_auth "backend/internal/repository/auth"
 	"errors"
 	"regexp"
+	"strings"
+	"time"
 )
 
 // 認証ユースケース(IF)
 type IAuthUsecase interface {
 	// ログイン
-	Login(email string, password string) (string, error)
+	Login(email string, password string, ipAddress string) (string, error)
+	// アカウント・IPアドレスのロック解除
+	UnlockAccount(email string, ipAddress string) error
+}
+
+// ログイン試行の制限ポリシー
+type LoginPolicy struct {
+	// 集計対象とする期間
+	FailureWindow time.Duration
+	// アカウントをロックする失敗回数
+	MaxAccountFailures int
+	// IPアドレスをロックする失敗回数
+	MaxIPFailures int
+	// ロック期間
+	LockoutDuration time.Duration
+	// バックオフを開始する失敗回数
+	BackoffThreshold int
+	// バックオフの初期待ち時間(失敗ごとに2倍になる)
+	BackoffBase time.Duration
+	// バックオフの最大待ち時間
+	BackoffMax time.Duration
 }
 
 // 認証ユースケース(Impl)
 type AuthUsecase struct {
-	Logger         *pkg_logger.AppLogger
-	authRepository repository_auth.IAuthRepository
+	Logger                 *pkg_logger.AppLogger
+	authRepository         repository_auth.IAuthRepository
+	loginAttemptRepository repository_auth.ILoginAttemptRepository
+	policy                 LoginPolicy
+	now                    func() time.Time
 }
 
 // 認証ユースケースのインスタンス化
-func NewAuthUsecase(l *pkg_logger.AppLogger, ar repository_auth.IAuthRepository) IAuthUsecase {
+func NewAuthUsecase(l *pkg_logger.AppLogger, ar repository_auth.IAuthRepository, lar repository_auth.ILoginAttemptRepository, policy LoginPolicy) IAuthUsecase {
 	return &AuthUsecase{
-		Logger:         l,
-		authRepository: ar,
+		Logger:                 l,
+		authRepository:         ar,
+		loginAttemptRepository: lar,
+		policy:                 policy,
+		now:                    time.Now,
 	}
 }
 
 // ログイン
-func (u *AuthUsecase) Login(email string, password string) (string, error) {
+func (u *AuthUsecase) Login(email string, password string, ipAddress string) (string, error) {
 	u.Logger.InfoLog.Println("Login called")
 
 	// バリデーション
@@ -42,14 +71,188 @@ func (u *AuthUsecase) Login(email string, password string) (string, error) {
 		u.Logger.ErrorLog.Println("Invalid email format")
 		return "", errors.New("invalid email format")
 	}
+	// ロック・失敗回数はメールアドレスの大文字小文字を区別せずに扱う
+	key := strings.ToLower(email)
+	now := u.now()
+
+	// ロック中かどうかのチェック
+	locked, err := u.isLocked(key, ipAddress)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to check lockout: %v", err)
+		return "", errors.New("failed to login")
+	}
+	if locked {
+		u.recordAttempt(key, ipAddress, domain_auth.LoginResultLocked)
+		u.Logger.ErrorLog.Println("Account is temporarily locked")
+		return "", errors.New("account is temporarily locked")
+	}
+
+	// パスワードの検証前に試行回数を加算する(同時に試行されても上限を超えて検証させない)
+	backoff := domain_auth.LoginBackoff{
+		Threshold: u.policy.BackoffThreshold,
+		Base:      u.policy.BackoffBase,
+		Max:       u.policy.BackoffMax,
+	}
+	account, err := u.loginAttemptRepository.ReserveAttempt(domain_auth.LockoutScopeAccount, key, u.policy.FailureWindow, backoff)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to reserve login attempt: %v", err)
+		return "", errors.New("failed to login")
+	}
+	ip, err := u.loginAttemptRepository.ReserveAttempt(domain_auth.LockoutScopeIP, ipAddress, u.policy.FailureWindow, backoff)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to reserve login attempt: %v", err)
+		return "", errors.New("failed to login")
+	}
+
+	// 指数バックオフ中かどうかのチェック
+	if account.Throttled || ip.Throttled {
+		// 予約できた方は試行しないため取り消す
+		if !account.Throttled {
+			u.releaseAttempt(domain_auth.LockoutScopeAccount, key)
+		}
+		if !ip.Throttled {
+			u.releaseAttempt(domain_auth.LockoutScopeIP, ipAddress)
+		}
+		u.recordAttempt(key, ipAddress, domain_auth.LoginResultThrottled)
+		u.Logger.ErrorLog.Println("Too many login attempts")
+		return "", errors.New("too many login attempts")
+	}
+
+	// 試行回数が上限を超えた場合はロック(上限回数の失敗で既にロック済みのため、同時の試行のみ該当する)
+	lockedUntil := now.Add(u.policy.LockoutDuration)
+	accountExceeded := u.policy.MaxAccountFailures > 0 && account.Attempts > u.policy.MaxAccountFailures
+	ipExceeded := u.policy.MaxIPFailures > 0 && ip.Attempts > u.policy.MaxIPFailures
+	if accountExceeded || ipExceeded {
+		u.lock(accountExceeded, key, ipExceeded, ipAddress, lockedUntil)
+		u.recordAttempt(key, ipAddress, domain_auth.LoginResultLocked)
+		u.Logger.ErrorLog.Println("Account is temporarily locked")
+		return "", errors.New("account is temporarily locked")
+	}
 
 	// 認証リポジトリからログイン(repository層)
 	id, err := u.authRepository.Login(email, password)
 	if err != nil {
 		u.Logger.ErrorLog.Printf("Failed to login: %v", err)
+		u.recordAttempt(key, ipAddress, domain_auth.LoginResultFailure)
+
+		// 失敗回数が上限に達した場合はロック
+		u.lock(
+			u.policy.MaxAccountFailures > 0 && account.Attempts >= u.policy.MaxAccountFailures, key,
+			u.policy.MaxIPFailures > 0 && ip.Attempts >= u.policy.MaxIPFailures, ipAddress,
+			lockedUntil,
+		)
 		return "", errors.New("failed to login")
 	}
 
+	// 成功した場合、アカウントの試行回数はリセットし、IPアドレスは今回の試行のみ取り消す
+	if err := u.loginAttemptRepository.ResetAttempts(domain_auth.LockoutScopeAccount, key); err != nil {
+		u.Logger.ErrorLog.Printf("Failed to reset login attempts: %v", err)
+	}
+	u.releaseAttempt(domain_auth.LockoutScopeIP, ipAddress)
+	u.recordAttempt(key, ipAddress, domain_auth.LoginResultSuccess)
+
 	u.Logger.InfoLog.Println("Login successful. 1 user found")
 	return id, nil
 }
+
+// アカウント・IPアドレスのロック解除
+func (u *AuthUsecase) UnlockAccount(email string, ipAddress string) error {
+	u.Logger.InfoLog.Println("UnlockAccount called")
+
+	// バリデーション
+	if email == "" && ipAddress == "" {
+		u.Logger.ErrorLog.Println("email or ip address is required")
+		return errors.New("email or ip address is required")
+	}
+	email = strings.ToLower(email)
+
+	// ロックを解除し、失敗回数をリセットするために解除を記録する
+	if email != "" {
+		err := u.loginAttemptRepository.Unlock(domain_auth.LockoutScopeAccount, email)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to unlock account: %v", err)
+			return err
+		}
+		err = u.loginAttemptRepository.ResetAttempts(domain_auth.LockoutScopeAccount, email)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to reset login attempts: %v", err)
+			return err
+		}
+	}
+	if ipAddress != "" {
+		err := u.loginAttemptRepository.Unlock(domain_auth.LockoutScopeIP, ipAddress)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to unlock ip address: %v", err)
+			return err
+		}
+		err = u.loginAttemptRepository.ResetAttempts(domain_auth.LockoutScopeIP, ipAddress)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to reset login attempts: %v", err)
+			return err
+		}
+	}
+	err := u.loginAttemptRepository.RecordAttempt(domain_auth.LoginAttempt{
+		Email:     email,
+		IPAddress: ipAddress,
+		Result:    domain_auth.LoginResultUnlocked,
+	})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to record unlock: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Println("Unlocked successfully")
+	return nil
+}
+
+// アカウントまたはIPアドレスがロック中かどうか
+func (u *AuthUsecase) isLocked(email string, ipAddress string) (bool, error) {
+	lockout, err := u.loginAttemptRepository.GetLockout(domain_auth.LockoutScopeAccount, email)
+	if err != nil {
+		return false, err
+	}
+	if lockout != nil {
+		return true, nil
+	}
+
+	lockout, err = u.loginAttemptRepository.GetLockout(domain_auth.LockoutScopeIP, ipAddress)
+	if err != nil {
+		return false, err
+	}
+	return lockout != nil, nil
+}
+
+// アカウント・IPアドレスをロック(ロックの失敗はログインの結果に影響させない)
+func (u *AuthUsecase) lock(lockAccount bool, email string, lockIP bool, ipAddress string, lockedUntil time.Time) {
+	if lockAccount {
+		u.Logger.WarnLog.Println("Locking account due to repeated login failures")
+		if err := u.loginAttemptRepository.Lock(domain_auth.LockoutScopeAccount, email, lockedUntil); err != nil {
+			u.Logger.ErrorLog.Printf("Failed to lock account: %v", err)
+		}
+	}
+	if lockIP {
+		u.Logger.WarnLog.Println("Locking ip address due to repeated login failures")
+		if err := u.loginAttemptRepository.Lock(domain_auth.LockoutScopeIP, ipAddress, lockedUntil); err != nil {
+			u.Logger.ErrorLog.Printf("Failed to lock ip address: %v", err)
+		}
+	}
+}
+
+// 予約したログイン試行を取り消す(取り消しの失敗はログインの結果に影響させない)
+func (u *AuthUsecase) releaseAttempt(scope string, subject string) {
+	if err := u.loginAttemptRepository.ReleaseAttempt(scope, subject); err != nil {
+		u.Logger.ErrorLog.Printf("Failed to release login attempt: %v", err)
+	}
+}
+
+// ログイン試行を記録(記録の失敗はログインの結果に影響させない)
+func (u *AuthUsecase) recordAttempt(email string, ipAddress string, result string) {
+	err := u.loginAttemptRepository.RecordAttempt(domain_auth.LoginAttempt{
+		Email:     email,
+		IPAddress: ipAddress,
+		Result:    result,
+	})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to record login attempt: %v", err)
+	}
+}
@@ -0,0 +1,463 @@
+package usecase_auth
+
+import (
+	domain_auth "backend/internal/domain/auth"
+	pkg_logger "backend/internal/pkg/logger"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// テスト用の時計
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// テスト用の認証リポジトリ(メールアドレスとパスワードが一致した場合にidを返す)
+type fakeAuthRepository struct {
+	passwords map[string]string
+	calls     int
+}
+
+func (r *fakeAuthRepository) Login(email string, password string) (string, error) {
+	r.calls++
+	if p, ok := r.passwords[email]; ok && p == password {
+		return "id-" + email, nil
+	}
+	return "", errors.New("invalid credentials")
+}
+
+// テスト用のログイン試行カウンタ
+type fakeLoginCounter struct {
+	attempts      int
+	lastAttemptAt time.Time
+}
+
+// テスト用のログイン試行リポジトリ(login_counters・login_lockoutsの判定をメモリ上で再現する)
+type fakeLoginAttemptRepository struct {
+	clock    *testClock
+	counters map[string]*fakeLoginCounter
+	lockouts map[string]time.Time
+	attempts []domain_auth.LoginAttempt
+	backoffs []domain_auth.LoginBackoff
+}
+
+func newFakeLoginAttemptRepository(clock *testClock) *fakeLoginAttemptRepository {
+	return &fakeLoginAttemptRepository{
+		clock:    clock,
+		counters: map[string]*fakeLoginCounter{},
+		lockouts: map[string]time.Time{},
+	}
+}
+
+func (r *fakeLoginAttemptRepository) RecordAttempt(attempt domain_auth.LoginAttempt) error {
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+func (r *fakeLoginAttemptRepository) ReserveAttempt(scope string, subject string, window time.Duration, backoff domain_auth.LoginBackoff) (domain_auth.LoginAttemptReservation, error) {
+	r.backoffs = append(r.backoffs, backoff)
+	now := r.clock.Now()
+	c, ok := r.counters[scope+":"+subject]
+	if !ok {
+		r.counters[scope+":"+subject] = &fakeLoginCounter{attempts: 1, lastAttemptAt: now}
+		return domain_auth.LoginAttemptReservation{Attempts: 1}, nil
+	}
+
+	expired := !c.lastAttemptAt.After(now.Add(-window))
+	if !expired && backoff.Base > 0 && c.attempts >= backoff.Threshold {
+		wait := time.Duration(float64(backoff.Base) * math.Pow(2, float64(c.attempts-backoff.Threshold)))
+		if wait > backoff.Max {
+			wait = backoff.Max
+		}
+		if c.lastAttemptAt.Add(wait).After(now) {
+			return domain_auth.LoginAttemptReservation{Throttled: true}, nil
+		}
+	}
+
+	if expired {
+		c.attempts = 1
+	} else {
+		c.attempts++
+	}
+	c.lastAttemptAt = now
+	return domain_auth.LoginAttemptReservation{Attempts: c.attempts}, nil
+}
+
+func (r *fakeLoginAttemptRepository) ReleaseAttempt(scope string, subject string) error {
+	if c, ok := r.counters[scope+":"+subject]; ok && c.attempts > 0 {
+		c.attempts--
+	}
+	return nil
+}
+
+func (r *fakeLoginAttemptRepository) ResetAttempts(scope string, subject string) error {
+	delete(r.counters, scope+":"+subject)
+	return nil
+}
+
+func (r *fakeLoginAttemptRepository) GetLockout(scope string, subject string) (*domain_auth.LoginLockout, error) {
+	lockedUntil, ok := r.lockouts[scope+":"+subject]
+	if !ok || !lockedUntil.After(r.clock.Now()) {
+		return nil, nil
+	}
+	return &domain_auth.LoginLockout{Scope: scope, Subject: subject, LockedUntil: lockedUntil}, nil
+}
+
+func (r *fakeLoginAttemptRepository) Lock(scope string, subject string, lockedUntil time.Time) error {
+	r.lockouts[scope+":"+subject] = lockedUntil
+	return nil
+}
+
+func (r *fakeLoginAttemptRepository) Unlock(scope string, subject string) error {
+	delete(r.lockouts, scope+":"+subject)
+	return nil
+}
+
+// 試行回数(カウンタが無い場合は0)
+func (r *fakeLoginAttemptRepository) count(scope string, subject string) int {
+	if c, ok := r.counters[scope+":"+subject]; ok {
+		return c.attempts
+	}
+	return 0
+}
+
+func (r *fakeLoginAttemptRepository) locked(scope string, subject string) bool {
+	lockout, _ := r.GetLockout(scope, subject)
+	return lockout != nil
+}
+
+func (r *fakeLoginAttemptRepository) lastResult() string {
+	if len(r.attempts) == 0 {
+		return ""
+	}
+	return r.attempts[len(r.attempts)-1].Result
+}
+
+// テスト用の認証ユースケース
+func newTestAuthUsecase(policy LoginPolicy) (*AuthUsecase, *fakeAuthRepository, *fakeLoginAttemptRepository, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	ar := &fakeAuthRepository{passwords: map[string]string{
+		"alice@example.com": "correct",
+		"bob@example.com":   "correct",
+	}}
+	lar := newFakeLoginAttemptRepository(clock)
+	u := NewAuthUsecase(newTestLogger(), ar, lar, policy).(*AuthUsecase)
+	u.now = clock.Now
+	return u, ar, lar, clock
+}
+
+// バックオフを使わないポリシー
+func lockoutOnlyPolicy() LoginPolicy {
+	return LoginPolicy{
+		FailureWindow:      15 * time.Minute,
+		MaxAccountFailures: 3,
+		MaxIPFailures:      5,
+		LockoutDuration:    10 * time.Minute,
+	}
+}
+
+func TestLoginValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		want     string
+	}{
+		{name: "empty email", email: "", password: "correct", want: "invalid email or password"},
+		{name: "empty password", email: "alice@example.com", password: "", want: "invalid email or password"},
+		{name: "invalid email", email: "alice", password: "correct", want: "invalid email format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, ar, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+			if _, err := u.Login(tt.email, tt.password, "10.0.0.1"); err == nil || err.Error() != tt.want {
+				t.Errorf("error = %v, want %s", err, tt.want)
+			}
+			if ar.calls != 0 || len(lar.counters) != 0 {
+				t.Error("invalid input must not count as a login attempt")
+			}
+		})
+	}
+}
+
+// 失敗回数が上限に達するとアカウントをロックし、ロック期間が過ぎるまで検証しない
+func TestLoginLocksAccountAtThreshold(t *testing.T) {
+	u, ar, lar, clock := newTestAuthUsecase(lockoutOnlyPolicy())
+
+	for i := 1; i <= 3; i++ {
+		if _, err := u.Login("alice@example.com", "wrong", "10.0.0.1"); err == nil || err.Error() != "failed to login" {
+			t.Fatalf("attempt %d: error = %v, want failed to login", i, err)
+		}
+		if got := lar.locked(domain_auth.LockoutScopeAccount, "alice@example.com"); got != (i == 3) {
+			t.Fatalf("attempt %d: account locked = %v", i, got)
+		}
+	}
+
+	calls := ar.calls
+	if _, err := u.Login("alice@example.com", "correct", "10.0.0.2"); err == nil || err.Error() != "account is temporarily locked" {
+		t.Fatalf("locked account: error = %v, want account is temporarily locked", err)
+	}
+	if ar.calls != calls {
+		t.Error("password was verified while the account was locked")
+	}
+	if got := lar.lastResult(); got != domain_auth.LoginResultLocked {
+		t.Errorf("recorded result = %s, want %s", got, domain_auth.LoginResultLocked)
+	}
+
+	// ロック期間が過ぎると、集計期間を過ぎた失敗回数はリセットされ、ログインできる
+	clock.Advance(16 * time.Minute)
+	if id, err := u.Login("alice@example.com", "correct", "10.0.0.1"); err != nil || id != "id-alice@example.com" {
+		t.Errorf("after lockout: got (%q, %v), want (id-alice@example.com, nil)", id, err)
+	}
+}
+
+// 大文字小文字の違うメールアドレスも同じアカウントとして数える
+func TestLoginCountsEmailCaseInsensitively(t *testing.T) {
+	u, _, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+
+	for _, email := range []string{"alice@example.com", "Alice@Example.com", "ALICE@EXAMPLE.COM"} {
+		u.Login(email, "wrong", "10.0.0.1")
+	}
+	if !lar.locked(domain_auth.LockoutScopeAccount, "alice@example.com") {
+		t.Error("account was not locked after failures with different letter cases")
+	}
+}
+
+// IPアドレスは複数のアカウントへの失敗を合算してロックし、アカウントのロックは別のIPアドレスにも適用する
+func TestLoginLockScopes(t *testing.T) {
+	t.Run("ip address", func(t *testing.T) {
+		u, _, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+
+		for i := 0; i < 5; i++ {
+			email := "user" + string(rune('a'+i)) + "@example.com"
+			u.Login(email, "wrong", "10.0.0.1")
+			if lar.locked(domain_auth.LockoutScopeAccount, email) {
+				t.Fatalf("account %s locked after a single failure", email)
+			}
+		}
+		if !lar.locked(domain_auth.LockoutScopeIP, "10.0.0.1") {
+			t.Fatal("ip address was not locked after 5 failures")
+		}
+
+		if _, err := u.Login("alice@example.com", "correct", "10.0.0.1"); err == nil || err.Error() != "account is temporarily locked" {
+			t.Errorf("locked ip address: error = %v, want account is temporarily locked", err)
+		}
+		if _, err := u.Login("alice@example.com", "correct", "10.0.0.2"); err != nil {
+			t.Errorf("other ip address: unexpected error: %v", err)
+		}
+	})
+
+	t.Run("account", func(t *testing.T) {
+		u, _, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+
+		for i := 0; i < 3; i++ {
+			u.Login("alice@example.com", "wrong", "10.0.0."+string(rune('1'+i)))
+		}
+		if !lar.locked(domain_auth.LockoutScopeAccount, "alice@example.com") {
+			t.Fatal("account was not locked after failures from different ip addresses")
+		}
+		for i := 0; i < 3; i++ {
+			if lar.locked(domain_auth.LockoutScopeIP, "10.0.0."+string(rune('1'+i))) {
+				t.Errorf("ip address 10.0.0.%c locked after a single failure", '1'+i)
+			}
+		}
+
+		if _, err := u.Login("alice@example.com", "correct", "10.0.0.9"); err == nil || err.Error() != "account is temporarily locked" {
+			t.Errorf("locked account from another ip address: error = %v, want account is temporarily locked", err)
+		}
+		if _, err := u.Login("bob@example.com", "correct", "10.0.0.1"); err != nil {
+			t.Errorf("other account: unexpected error: %v", err)
+		}
+	})
+}
+
+// 同時の試行で上限を超えた場合は、パスワードを検証せずにロックする
+func TestLoginLocksWhenReservationExceedsLimit(t *testing.T) {
+	u, ar, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+	// 検証中の試行が上限まで予約済みの状態
+	lar.counters[domain_auth.LockoutScopeAccount+":alice@example.com"] = &fakeLoginCounter{attempts: 3, lastAttemptAt: u.now()}
+
+	if _, err := u.Login("alice@example.com", "correct", "10.0.0.1"); err == nil || err.Error() != "account is temporarily locked" {
+		t.Fatalf("error = %v, want account is temporarily locked", err)
+	}
+	if ar.calls != 0 {
+		t.Error("password was verified beyond the limit")
+	}
+	if !lar.locked(domain_auth.LockoutScopeAccount, "alice@example.com") {
+		t.Error("account was not locked")
+	}
+}
+
+// 成功するとアカウントの失敗回数はリセットし、IPアドレスは成功した試行のみ取り消す
+func TestLoginResetsOnSuccess(t *testing.T) {
+	u, _, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+
+	u.Login("alice@example.com", "wrong", "10.0.0.1")
+	u.Login("alice@example.com", "wrong", "10.0.0.1")
+	if id, err := u.Login("alice@example.com", "correct", "10.0.0.1"); err != nil || id != "id-alice@example.com" {
+		t.Fatalf("got (%q, %v), want (id-alice@example.com, nil)", id, err)
+	}
+
+	if got := lar.count(domain_auth.LockoutScopeAccount, "alice@example.com"); got != 0 {
+		t.Errorf("account attempts = %d, want 0", got)
+	}
+	if got := lar.count(domain_auth.LockoutScopeIP, "10.0.0.1"); got != 2 {
+		t.Errorf("ip address attempts = %d, want 2", got)
+	}
+	if got := lar.lastResult(); got != domain_auth.LoginResultSuccess {
+		t.Errorf("recorded result = %s, want %s", got, domain_auth.LoginResultSuccess)
+	}
+
+	// リセット後は再び上限回数まで失敗できる
+	u.Login("alice@example.com", "wrong", "10.0.0.1")
+	u.Login("alice@example.com", "wrong", "10.0.0.1")
+	if lar.locked(domain_auth.LockoutScopeAccount, "alice@example.com") {
+		t.Error("account locked although the failures were reset by the successful login")
+	}
+}
+
+// バックオフの閾値以降は待ち時間が倍になり、最大待ち時間で頭打ちになる
+func TestLoginBackoff(t *testing.T) {
+	policy := LoginPolicy{
+		FailureWindow:    time.Hour,
+		BackoffThreshold: 2,
+		BackoffBase:      time.Second,
+		BackoffMax:       4 * time.Second,
+	}
+	u, ar, lar, clock := newTestAuthUsecase(policy)
+
+	// 閾値までは待たずに試行できる
+	for i := 0; i < 2; i++ {
+		if _, err := u.Login("alice@example.com", "wrong", "10.0.0.1"); err == nil || err.Error() != "failed to login" {
+			t.Fatalf("attempt %d: error = %v, want failed to login", i+1, err)
+		}
+	}
+	if got := lar.backoffs[0]; got.Threshold != 2 || got.Base != time.Second || got.Max != 4*time.Second {
+		t.Errorf("backoff = %+v, want the policy values", got)
+	}
+
+	// 待ち時間は1秒、2秒、4秒、4秒(最大)と増える
+	for _, wait := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		calls := ar.calls
+		clock.Advance(wait - time.Millisecond)
+		if _, err := u.Login("alice@example.com", "wrong", "10.0.0.1"); err == nil || err.Error() != "too many login attempts" {
+			t.Fatalf("before %v: error = %v, want too many login attempts", wait, err)
+		}
+		if ar.calls != calls {
+			t.Fatalf("before %v: password was verified during backoff", wait)
+		}
+		if got := lar.lastResult(); got != domain_auth.LoginResultThrottled {
+			t.Errorf("before %v: recorded result = %s, want %s", wait, got, domain_auth.LoginResultThrottled)
+		}
+
+		clock.Advance(time.Millisecond)
+		if _, err := u.Login("alice@example.com", "wrong", "10.0.0.1"); err == nil || err.Error() != "failed to login" {
+			t.Fatalf("after %v: error = %v, want failed to login", wait, err)
+		}
+	}
+}
+
+// 片方のみバックオフ中の場合は、予約できた方の試行を取り消す
+func TestLoginReleasesReservationWhenThrottled(t *testing.T) {
+	policy := LoginPolicy{
+		FailureWindow:    time.Hour,
+		BackoffThreshold: 1,
+		BackoffBase:      time.Minute,
+		BackoffMax:       time.Hour,
+	}
+	u, _, lar, _ := newTestAuthUsecase(policy)
+
+	u.Login("alice@example.com", "wrong", "10.0.0.1")
+	// 別のIPアドレスからの試行はアカウントのバックオフで拒否される
+	if _, err := u.Login("alice@example.com", "correct", "10.0.0.2"); err == nil || err.Error() != "too many login attempts" {
+		t.Fatalf("error = %v, want too many login attempts", err)
+	}
+	if got := lar.count(domain_auth.LockoutScopeIP, "10.0.0.2"); got != 0 {
+		t.Errorf("ip address attempts = %d, want 0 after release", got)
+	}
+	if got := lar.count(domain_auth.LockoutScopeAccount, "alice@example.com"); got != 1 {
+		t.Errorf("account attempts = %d, want 1", got)
+	}
+}
+
+// aliceのアカウント(3回)とIPアドレス(5回)をロックする
+func lockAliceAndIP(u *AuthUsecase) {
+	for i := 0; i < 3; i++ {
+		u.Login("alice@example.com", "wrong", "10.0.0.1")
+	}
+	for i := 0; i < 2; i++ {
+		u.Login("bob@example.com", "wrong", "10.0.0.1")
+	}
+}
+
+// ロック解除はロックと失敗回数を削除し、解除を記録する
+func TestUnlockAccount(t *testing.T) {
+	t.Run("account and ip address", func(t *testing.T) {
+		u, _, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+		lockAliceAndIP(u)
+		if !lar.locked(domain_auth.LockoutScopeAccount, "alice@example.com") || !lar.locked(domain_auth.LockoutScopeIP, "10.0.0.1") {
+			t.Fatal("account and ip address should be locked")
+		}
+
+		if err := u.UnlockAccount("Alice@Example.com", "10.0.0.1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if lar.locked(domain_auth.LockoutScopeAccount, "alice@example.com") || lar.locked(domain_auth.LockoutScopeIP, "10.0.0.1") {
+			t.Error("lock remains after unlock")
+		}
+		if lar.count(domain_auth.LockoutScopeAccount, "alice@example.com") != 0 || lar.count(domain_auth.LockoutScopeIP, "10.0.0.1") != 0 {
+			t.Error("attempts remain after unlock")
+		}
+		last := lar.attempts[len(lar.attempts)-1]
+		if last.Result != domain_auth.LoginResultUnlocked || last.Email != "alice@example.com" || last.IPAddress != "10.0.0.1" {
+			t.Errorf("recorded attempt = %+v, want unlocked for alice@example.com and 10.0.0.1", last)
+		}
+
+		if _, err := u.Login("alice@example.com", "correct", "10.0.0.1"); err != nil {
+			t.Errorf("login after unlock: unexpected error: %v", err)
+		}
+	})
+
+	t.Run("account only", func(t *testing.T) {
+		u, _, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+		lockAliceAndIP(u)
+
+		if err := u.UnlockAccount("alice@example.com", ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if lar.locked(domain_auth.LockoutScopeAccount, "alice@example.com") {
+			t.Error("account lock remains after unlock")
+		}
+		if !lar.locked(domain_auth.LockoutScopeIP, "10.0.0.1") {
+			t.Error("ip address lock was removed")
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		u, _, lar, _ := newTestAuthUsecase(lockoutOnlyPolicy())
+		if err := u.UnlockAccount("", ""); err == nil || err.Error() != "email or ip address is required" {
+			t.Errorf("error = %v, want email or ip address is required", err)
+		}
+		if len(lar.attempts) != 0 {
+			t.Error("unlock without a target was recorded")
+		}
+	})
+}
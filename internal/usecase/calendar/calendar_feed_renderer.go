@@ -0,0 +1,116 @@
+package usecase_calendar
+
+import (
+	domain_calendar "backend/internal/domain/calendar"
+	domain_todo "backend/internal/domain/todo"
+	pkg_ical "backend/internal/pkg/ical"
+	"bytes"
+	"strconv"
+	"time"
+)
+
+// iCalendarの製品識別子
+const icalProductId = "-//todo-app//Todo Calendar Feed//JA"
+
+// VEVENTのUIDの接尾辞(同じフィードにVTODOと並べてもUIDが重ならないようにする)
+const eventUIDSuffix = "-due"
+
+// 完了済みのTodoの予定の件名の接頭辞
+const completedSummaryPrefix = "✓ "
+
+// 期限付きのTodoをiCalendarのフィードに変換
+// 同じ内容であれば同じ出力になるよう、DTSTAMPには更新日時を使う(ETagの比較に使うため)。
+func renderCalendarFeed(todos []domain_todo.Todo, feedType string, refresh time.Duration) ([]byte, error) {
+	var b bytes.Buffer
+	w := pkg_ical.NewWriter(&b)
+
+	w.Begin("VCALENDAR")
+	w.Property("VERSION", "2.0")
+	w.Property("PRODID", icalProductId)
+	w.Property("CALSCALE", "GREGORIAN")
+	w.Property("METHOD", "PUBLISH")
+	w.Text("X-WR-CALNAME", "Todo")
+	if refresh > 0 {
+		w.Property("REFRESH-INTERVAL", formatDuration(refresh), "VALUE=DURATION")
+		w.Property("X-PUBLISHED-TTL", formatDuration(refresh))
+	}
+	for _, todo := range todos {
+		if todo.DueAt == nil {
+			continue
+		}
+		if feedType == domain_calendar.CalendarFeedTypeEvent || feedType == domain_calendar.CalendarFeedTypeBoth {
+			writeEvent(w, todo)
+		}
+		if feedType == domain_calendar.CalendarFeedTypeTodo || feedType == domain_calendar.CalendarFeedTypeBoth {
+			writeTodo(w, todo)
+		}
+	}
+	w.End("VCALENDAR")
+
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// 期限の日時の予定(VEVENT)を書き込む(終了日時は指定せず、期限の時点の予定とする)
+func writeEvent(w *pkg_ical.Writer, todo domain_todo.Todo) {
+	summary := todo.Description
+	if todo.Completed {
+		summary = completedSummaryPrefix + summary
+	}
+
+	w.Begin("VEVENT")
+	w.Text("UID", todo.ID+eventUIDSuffix)
+	w.DateTime("DTSTAMP", todo.UpdatedAt)
+	w.DateTime("CREATED", todo.CreatedAt)
+	w.DateTime("LAST-MODIFIED", todo.UpdatedAt)
+	w.DateTime("DTSTART", *todo.DueAt)
+	w.Text("SUMMARY", summary)
+	w.Property("TRANSP", "TRANSPARENT")
+	if !todo.Completed {
+		writeAlarm(w, todo)
+	}
+	w.End("VEVENT")
+}
+
+// タスク(VTODO)を書き込む
+func writeTodo(w *pkg_ical.Writer, todo domain_todo.Todo) {
+	w.Begin("VTODO")
+	w.Text("UID", todo.ID)
+	w.DateTime("DTSTAMP", todo.UpdatedAt)
+	w.DateTime("CREATED", todo.CreatedAt)
+	w.DateTime("LAST-MODIFIED", todo.UpdatedAt)
+	w.Text("SUMMARY", todo.Description)
+	if todo.Completed {
+		w.Property("STATUS", "COMPLETED")
+	} else {
+		w.Property("STATUS", "NEEDS-ACTION")
+	}
+	w.Property("PRIORITY", strconv.Itoa(domain_calendar.ToICalPriority(todo.Priority)))
+	w.DateTime("DUE", *todo.DueAt)
+	if todo.ParentId != nil {
+		w.Property("RELATED-TO", pkg_ical.EscapeText(*todo.ParentId), "RELTYPE=PARENT")
+	}
+	if !todo.Completed {
+		writeAlarm(w, todo)
+	}
+	w.End("VTODO")
+}
+
+// リマインド日時がある場合は通知(VALARM)を書き込む
+func writeAlarm(w *pkg_ical.Writer, todo domain_todo.Todo) {
+	if todo.RemindAt == nil {
+		return
+	}
+	w.Begin("VALARM")
+	w.Property("ACTION", "DISPLAY")
+	w.Text("DESCRIPTION", todo.Description)
+	w.Property("TRIGGER", pkg_ical.FormatDateTime(*todo.RemindAt), "VALUE=DATE-TIME")
+	w.End("VALARM")
+}
+
+// 期間をiCalendarの形式に変換(分単位、例: PT15M)
+func formatDuration(d time.Duration) string {
+	return "PT" + strconv.Itoa(int(d/time.Minute)) + "M"
+}
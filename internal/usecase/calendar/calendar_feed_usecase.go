@@ -0,0 +1,172 @@
+package usecase_calendar
+
+import (
+	domain_calendar "backend/internal/domain/calendar"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_calendar "backend/internal/repository/calendar"
+	repository_todo "backend/internal/repository/todo"
+	"errors"
+	"strings"
+	"time"
+)
+
+// カレンダーの購読フィードユースケース(IF)
+type ICalendarFeedUsecase interface {
+	// 自分のフィードを取得(発行していない場合はnil)
+	GetFeed(userId string) (*domain_calendar.CalendarFeed, error)
+	// 自分のフィードのトークンを発行し直す(既存のURLは無効になる)
+	RegenerateToken(userId string) (domain_calendar.CalendarFeedToken, error)
+	// 自分のフィードを無効にし、無効にしたかどうかを返す
+	RevokeFeed(userId string) (bool, error)
+	// トークンに対応するユーザーの期限付きのTodoをiCalendar形式で出力
+	RenderFeed(token string, feedType string) ([]byte, error)
+}
+
+// カレンダーの購読フィードユースケース(Impl)
+type CalendarFeedUsecase struct {
+	Logger         *pkg_logger.AppLogger
+	feedRepository repository_calendar.ICalendarFeedRepository
+	todoRepository repository_todo.ITodoRepository
+	baseURL        string
+	pastDays       int
+	refresh        time.Duration
+	now            func() time.Time
+}
+
+// カレンダーの購読フィードユースケースのインスタンス化
+// baseURLはフィードのURLの先頭(空の場合はパスのみ)、pastDaysは期限が過去何日までのTodoを含めるか、refreshはクライアントに推奨する更新間隔。
+func NewCalendarFeedUsecase(l *pkg_logger.AppLogger, fr repository_calendar.ICalendarFeedRepository, tr repository_todo.ITodoRepository, baseURL string, pastDays int, refresh time.Duration) ICalendarFeedUsecase {
+	return &CalendarFeedUsecase{
+		Logger:         l,
+		feedRepository: fr,
+		todoRepository: tr,
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		pastDays:       pastDays,
+		refresh:        refresh,
+		now:            time.Now,
+	}
+}
+
+// 自分のフィードを取得(発行していない場合はnil)
+func (u *CalendarFeedUsecase) GetFeed(userId string) (*domain_calendar.CalendarFeed, error) {
+	u.Logger.InfoLog.Println("GetFeed called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// フィードリポジトリから取得(repository層)
+	feed, err := u.feedRepository.GetFeedByUserId(userId)
+	if errors.Is(err, repository_calendar.ErrCalendarFeedNotFound) {
+		u.Logger.InfoLog.Println("Calendar feed is not issued")
+		return nil, nil
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get calendar feed: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched calendar feed: %s", feed.UserId)
+	return &feed, nil
+}
+
+// 自分のフィードのトークンを発行し直す(既存のURLは無効になる)
+func (u *CalendarFeedUsecase) RegenerateToken(userId string) (domain_calendar.CalendarFeedToken, error) {
+	u.Logger.InfoLog.Println("RegenerateToken called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_calendar.CalendarFeedToken{}, errors.New("user_id is empty")
+	}
+
+	token, err := domain_calendar.GenerateCalendarFeedToken()
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to generate token: %v", err)
+		return domain_calendar.CalendarFeedToken{}, err
+	}
+
+	// フィードリポジトリにトークンのハッシュを保存(repository層)
+	feed, err := u.feedRepository.UpsertFeed(userId, domain_calendar.HashCalendarFeedToken(token))
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to save calendar feed: %v", err)
+		return domain_calendar.CalendarFeedToken{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Regenerated calendar feed token: %s", userId)
+	return domain_calendar.CalendarFeedToken{
+		Token: token,
+		URL:   u.baseURL + "/calendar/" + token + ".ics",
+		Feed:  feed,
+	}, nil
+}
+
+// 自分のフィードを無効にし、無効にしたかどうかを返す
+func (u *CalendarFeedUsecase) RevokeFeed(userId string) (bool, error) {
+	u.Logger.InfoLog.Println("RevokeFeed called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return false, errors.New("user_id is empty")
+	}
+
+	// フィードリポジトリから削除(repository層)
+	revoked, err := u.feedRepository.DeleteFeed(userId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to revoke calendar feed: %v", err)
+		return false, err
+	}
+
+	u.Logger.InfoLog.Printf("Revoked calendar feed: %s (%v)", userId, revoked)
+	return revoked, nil
+}
+
+// トークンに対応するユーザーの期限付きのTodoをiCalendar形式で出力
+// 無効なトークンと発行されていないトークンは区別せず、どちらも"feed not found"とする。
+func (u *CalendarFeedUsecase) RenderFeed(token string, feedType string) ([]byte, error) {
+	u.Logger.InfoLog.Println("RenderFeed called")
+
+	// バリデーション
+	if feedType == "" {
+		feedType = domain_calendar.CalendarFeedTypeEvent
+	}
+	if !domain_calendar.IsValidFeedType(feedType) {
+		u.Logger.ErrorLog.Printf("Invalid feed type: %s", feedType)
+		return nil, errors.New("invalid feed type")
+	}
+	if token == "" {
+		u.Logger.ErrorLog.Println("token is empty")
+		return nil, errors.New("feed not found")
+	}
+
+	// フィードリポジトリからトークンのハッシュで検索(repository層)
+	feed, err := u.feedRepository.TouchFeedByTokenHash(domain_calendar.HashCalendarFeedToken(token))
+	if errors.Is(err, repository_calendar.ErrCalendarFeedNotFound) {
+		u.Logger.ErrorLog.Println("feed not found")
+		return nil, errors.New("feed not found")
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get calendar feed: %v", err)
+		return nil, err
+	}
+
+	// Todoリポジトリから期限付きのTodoを取得(repository層)
+	since := u.now().AddDate(0, 0, -u.pastDays)
+	todos, err := u.todoRepository.GetDueTodosByUserId(feed.UserId, since, domain_calendar.MaxCalendarFeedTodos)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get due todos: %v", err)
+		return nil, err
+	}
+
+	body, err := renderCalendarFeed(todos, feedType, u.refresh)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to render calendar feed: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Rendered calendar feed with %d todos", len(todos))
+	return body, nil
+}
@@ -0,0 +1,190 @@
+package usecase_comment
+
+import (
+	domain_comment "backend/internal/domain/comment"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_comment "backend/internal/repository/comment"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// コメントの取得件数
+const (
+	defaultCommentLimit = 20
+	maxCommentLimit     = 100
+)
+
+// コメントユースケース(IF)
+type ICommentUsecase interface {
+	// 複数のTodoのコメントを取得(limitが0の場合は既定の件数)
+	GetCommentPagesByTodoIds(todoIds []string, limit int, offset int) ([]domain_comment.CommentPage, error)
+	// Todoにコメントを追加
+	AddComment(userId string, todoId string, body string) (domain_comment.Comment, error)
+	// コメントを編集(投稿者のみ)
+	EditComment(userId string, id string, body string) (domain_comment.Comment, error)
+	// コメントを削除(投稿者のみ)
+	DeleteComment(userId string, id string) error
+}
+
+// コメントユースケース(Impl)
+type CommentUsecase struct {
+	Logger            *pkg_logger.AppLogger
+	commentRepository repository_comment.ICommentRepository
+}
+
+// コメントユースケースのインスタンス化
+func NewCommentUsecase(l *pkg_logger.AppLogger, cr repository_comment.ICommentRepository) ICommentUsecase {
+	return &CommentUsecase{
+		Logger:            l,
+		commentRepository: cr,
+	}
+}
+
+// 複数のTodoのコメントを取得(limitが0の場合は既定の件数)
+func (u *CommentUsecase) GetCommentPagesByTodoIds(todoIds []string, limit int, offset int) ([]domain_comment.CommentPage, error) {
+	u.Logger.InfoLog.Println("GetCommentPagesByTodoIds called")
+
+	// バリデーション
+	if limit < 0 || offset < 0 {
+		u.Logger.ErrorLog.Println("limit and offset must not be negative")
+		return nil, errors.New("limit and offset must not be negative")
+	}
+	if limit == 0 {
+		limit = defaultCommentLimit
+	}
+	if limit > maxCommentLimit {
+		limit = maxCommentLimit
+	}
+	if len(todoIds) == 0 {
+		return []domain_comment.CommentPage{}, nil
+	}
+
+	// コメントリポジトリから取得(repository層)
+	pages, err := u.commentRepository.GetCommentPagesByTodoIds(todoIds, limit, offset)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get comments: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched comments of %d todos", len(pages))
+	return pages, nil
+}
+
+// Todoにコメントを追加
+// Todoへのアクセス権はインターフェース層で確認する
+func (u *CommentUsecase) AddComment(userId string, todoId string, body string) (domain_comment.Comment, error) {
+	u.Logger.InfoLog.Println("AddComment called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_comment.Comment{}, errors.New("user_id is empty")
+	}
+	if todoId == "" {
+		u.Logger.ErrorLog.Println("todo_id is empty")
+		return domain_comment.Comment{}, errors.New("todo_id is empty")
+	}
+	body, err := u.validateBody(body)
+	if err != nil {
+		return domain_comment.Comment{}, err
+	}
+
+	// コメントリポジトリから作成(repository層)
+	comment, err := u.commentRepository.CreateComment(domain_comment.Comment{
+		TodoId:   todoId,
+		AuthorId: userId,
+		Body:     body,
+	})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to add comment: %v", err)
+		return domain_comment.Comment{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Added comment: %v", comment.ID)
+	return comment, nil
+}
+
+// コメントを編集(投稿者のみ)
+func (u *CommentUsecase) EditComment(userId string, id string, body string) (domain_comment.Comment, error) {
+	u.Logger.InfoLog.Println("EditComment called")
+
+	// バリデーション
+	body, err := u.validateBody(body)
+	if err != nil {
+		return domain_comment.Comment{}, err
+	}
+
+	// 投稿者チェック
+	err = u.checkCommentAuthor(userId, id)
+	if err != nil {
+		return domain_comment.Comment{}, err
+	}
+
+	// コメントリポジトリから編集(repository層)
+	comment, err := u.commentRepository.EditComment(id, body)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to edit comment: %v", err)
+		return domain_comment.Comment{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Edited comment: %v", comment.ID)
+	return comment, nil
+}
+
+// コメントを削除(投稿者のみ)
+func (u *CommentUsecase) DeleteComment(userId string, id string) error {
+	u.Logger.InfoLog.Println("DeleteComment called")
+
+	// 投稿者チェック
+	err := u.checkCommentAuthor(userId, id)
+	if err != nil {
+		return err
+	}
+
+	// コメントリポジトリから削除(repository層)
+	err = u.commentRepository.DeleteComment(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to delete comment: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Deleted comment: %v", id)
+	return nil
+}
+
+// コメント本文の検証(前後の空白は取り除く)
+func (u *CommentUsecase) validateBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		u.Logger.ErrorLog.Println("body is empty")
+		return "", errors.New("body is empty")
+	}
+	if utf8.RuneCountInString(body) > domain_comment.MaxCommentBodyLength {
+		u.Logger.ErrorLog.Println("body is too long")
+		return "", errors.New("body is too long")
+	}
+	return body, nil
+}
+
+// コメントの投稿者チェック
+func (u *CommentUsecase) checkCommentAuthor(userId string, id string) error {
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("comment_id is empty")
+		return errors.New("comment_id is empty")
+	}
+	comment, err := u.commentRepository.GetCommentById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get comment: %v", err)
+		return errors.New("comment not found")
+	}
+	if comment.AuthorId != userId {
+		u.Logger.ErrorLog.Println("forbidden")
+		return errors.New("forbidden")
+	}
+	return nil
+}
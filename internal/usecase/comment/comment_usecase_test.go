@@ -0,0 +1,141 @@
+package usecase_comment
+
+import (
+	domain_comment "backend/internal/domain/comment"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_comment "backend/internal/repository/comment"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// コメントをメモリ上に保持し、変更を記録するコメントリポジトリ
+type fakeCommentRepository struct {
+	repository_comment.ICommentRepository
+	comments map[string]domain_comment.Comment
+	changes  []string
+	// GetCommentPagesByTodoIdsに渡されたlimitとoffset
+	pages [][2]int
+}
+
+func (r *fakeCommentRepository) GetCommentById(id string) (domain_comment.Comment, error) {
+	comment, ok := r.comments[id]
+	if !ok {
+		return domain_comment.Comment{}, errors.New("no rows in result set")
+	}
+	return comment, nil
+}
+
+func (r *fakeCommentRepository) CreateComment(comment domain_comment.Comment) (domain_comment.Comment, error) {
+	comment.ID = "new"
+	r.changes = append(r.changes, "create "+comment.Body)
+	return comment, nil
+}
+
+func (r *fakeCommentRepository) EditComment(id string, body string) (domain_comment.Comment, error) {
+	comment := r.comments[id]
+	editedAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	comment.Body = body
+	comment.EditedAt = &editedAt
+	r.changes = append(r.changes, "edit "+id+" "+body)
+	return comment, nil
+}
+
+func (r *fakeCommentRepository) DeleteComment(id string) error {
+	r.changes = append(r.changes, "delete "+id)
+	return nil
+}
+
+func (r *fakeCommentRepository) GetCommentPagesByTodoIds(todoIds []string, limit int, offset int) ([]domain_comment.CommentPage, error) {
+	r.pages = append(r.pages, [2]int{limit, offset})
+	return []domain_comment.CommentPage{}, nil
+}
+
+func newTestCommentUsecase() (ICommentUsecase, *fakeCommentRepository) {
+	cr := &fakeCommentRepository{comments: map[string]domain_comment.Comment{
+		"c1": {ID: "c1", TodoId: "t1", AuthorId: "alice", Body: "hello"},
+	}}
+	return NewCommentUsecase(newTestLogger(), cr), cr
+}
+
+// コメントの編集・削除は投稿者のみ
+func TestCommentMutationsRequireAuthor(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(u ICommentUsecase) error
+		wantErr    string
+		wantChange string
+	}{
+		{name: "edit by author", call: func(u ICommentUsecase) error {
+			c, err := u.EditComment("alice", "c1", "  updated  ")
+			if err == nil && c.EditedAt == nil {
+				return errors.New("edited_at is not set")
+			}
+			return err
+		}, wantChange: "edit c1 updated"},
+		{name: "edit by other user", call: func(u ICommentUsecase) error { _, err := u.EditComment("bob", "c1", "updated"); return err }, wantErr: "forbidden"},
+		{name: "edit missing comment", call: func(u ICommentUsecase) error { _, err := u.EditComment("alice", "cx", "updated"); return err }, wantErr: "comment not found"},
+		{name: "edit to blank", call: func(u ICommentUsecase) error { _, err := u.EditComment("alice", "c1", "  "); return err }, wantErr: "body is empty"},
+		{name: "delete by author", call: func(u ICommentUsecase) error { return u.DeleteComment("alice", "c1") }, wantChange: "delete c1"},
+		{name: "delete by other user", call: func(u ICommentUsecase) error { return u.DeleteComment("bob", "c1") }, wantErr: "forbidden"},
+		{name: "delete unauthenticated", call: func(u ICommentUsecase) error { return u.DeleteComment("", "c1") }, wantErr: "user_id is empty"},
+		{name: "add", call: func(u ICommentUsecase) error { _, err := u.AddComment("bob", "t1", " reply "); return err }, wantChange: "create reply"},
+		{name: "add too long", call: func(u ICommentUsecase) error {
+			_, err := u.AddComment("bob", "t1", strings.Repeat("a", domain_comment.MaxCommentBodyLength+1))
+			return err
+		}, wantErr: "body is too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, cr := newTestCommentUsecase()
+			err := tt.call(u)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(cr.changes) != 0 {
+					t.Errorf("changes = %v, want none", cr.changes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned error: %v", err)
+			}
+			if !reflect.DeepEqual(cr.changes, []string{tt.wantChange}) {
+				t.Errorf("changes = %v, want [%s]", cr.changes, tt.wantChange)
+			}
+		})
+	}
+}
+
+// コメントの取得件数の既定値・上限と負の値の検証
+func TestGetCommentPagesByTodoIdsLimit(t *testing.T) {
+	u, cr := newTestCommentUsecase()
+
+	for _, page := range [][2]int{{0, 0}, {5, 10}, {maxCommentLimit + 1, 0}} {
+		if _, err := u.GetCommentPagesByTodoIds([]string{"t1"}, page[0], page[1]); err != nil {
+			t.Fatalf("GetCommentPagesByTodoIds(%v) returned error: %v", page, err)
+		}
+	}
+	want := [][2]int{{defaultCommentLimit, 0}, {5, 10}, {maxCommentLimit, 0}}
+	if !reflect.DeepEqual(cr.pages, want) {
+		t.Errorf("repository pages = %v, want %v", cr.pages, want)
+	}
+
+	if _, err := u.GetCommentPagesByTodoIds([]string{"t1"}, 10, -1); err == nil {
+		t.Error("negative offset was accepted")
+	}
+	if pages, err := u.GetCommentPagesByTodoIds(nil, 10, 0); err != nil || len(pages) != 0 || len(cr.pages) != len(want) {
+		t.Errorf("GetCommentPagesByTodoIds(nil) = %v, %v", pages, err)
+	}
+}
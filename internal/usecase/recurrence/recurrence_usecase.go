@@ -0,0 +1,385 @@
+package usecase_recurrence
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_recurrence "backend/internal/domain/recurrence"
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	pkg_rrule "backend/internal/pkg/rrule"
+	repository_recurrence "backend/internal/repository/recurrence"
+	repository_todo "backend/internal/repository/todo"
+	"errors"
+	"sort"
+	"time"
+
+	// 実行環境にタイムゾーンデータベースがない場合に備えて埋め込む
+	_ "time/tzdata"
+)
+
+// 繰り返しユースケース(IF)
+type IRecurrenceUsecase interface {
+	// 複数のTodoの繰り返し設定を取得
+	GetRecurrencesByTodoIds(todoIds []string) ([]domain_recurrence.Recurrence, error)
+	// Todoに繰り返しを設定(期限を最初の発生日時とする)
+	SetRecurrence(userId string, todoId string, rule string, timezone string) (domain_recurrence.Recurrence, error)
+	// Todoの繰り返しを解除
+	ClearRecurrence(userId string, todoId string) error
+	// 発生を1回スキップ(現在の発生分をスキップした場合は、Todoの期限を次の発生日時に移す)
+	SkipOccurrence(userId string, todoId string, at time.Time, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// 完了したTodoの次の発生分を作成(繰り返しがない・終了した場合はnil)
+	GenerateNextOccurrence(todo domain_todo.Todo, actor domain_audit.AuditActor) (*domain_todo.Todo, error)
+	// 特定のユーザーの指定期間内の発生を取得
+	GetUpcomingOccurrences(userId string, within time.Duration, limit int) ([]domain_recurrence.Occurrence, error)
+}
+
+// 繰り返しユースケース(Impl)
+type RecurrenceUsecase struct {
+	Logger               *pkg_logger.AppLogger
+	recurrenceRepository repository_recurrence.IRecurrenceRepository
+	todoRepository       repository_todo.ITodoRepository
+	defaultTimezone      string
+	now                  func() time.Time
+}
+
+// 繰り返しユースケースのインスタンス化
+func NewRecurrenceUsecase(l *pkg_logger.AppLogger, rr repository_recurrence.IRecurrenceRepository, tr repository_todo.ITodoRepository, defaultTimezone string) IRecurrenceUsecase {
+	return &RecurrenceUsecase{
+		Logger:               l,
+		recurrenceRepository: rr,
+		todoRepository:       tr,
+		defaultTimezone:      defaultTimezone,
+		now:                  time.Now,
+	}
+}
+
+// 複数のTodoの繰り返し設定を取得
+func (u *RecurrenceUsecase) GetRecurrencesByTodoIds(todoIds []string) ([]domain_recurrence.Recurrence, error) {
+	u.Logger.InfoLog.Println("GetRecurrencesByTodoIds called")
+
+	// バリデーション
+	if len(todoIds) == 0 {
+		return []domain_recurrence.Recurrence{}, nil
+	}
+
+	// 繰り返しリポジトリから取得(repository層)
+	recurrences, err := u.recurrenceRepository.GetRecurrencesByTodoIds(todoIds)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get recurrences: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d recurrences", len(recurrences))
+	return recurrences, nil
+}
+
+// Todoに繰り返しを設定(期限を最初の発生日時とする)
+func (u *RecurrenceUsecase) SetRecurrence(userId string, todoId string, rule string, timezone string) (domain_recurrence.Recurrence, error) {
+	u.Logger.InfoLog.Println("SetRecurrence called")
+
+	// バリデーション
+	parsed, err := pkg_rrule.Parse(rule)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Invalid rrule: %v", err)
+		return domain_recurrence.Recurrence{}, errors.New("invalid rrule")
+	}
+	if timezone == "" {
+		timezone = u.defaultTimezone
+	}
+	if _, err = time.LoadLocation(timezone); err != nil {
+		u.Logger.ErrorLog.Printf("Invalid timezone: %v", err)
+		return domain_recurrence.Recurrence{}, errors.New("invalid timezone")
+	}
+
+	// 所有者チェック
+	todo, err := u.checkTodoOwner(userId, todoId)
+	if err != nil {
+		return domain_recurrence.Recurrence{}, err
+	}
+	if todo.DueAt == nil {
+		u.Logger.ErrorLog.Println("due date is required")
+		return domain_recurrence.Recurrence{}, errors.New("due date is required")
+	}
+	if todo.Completed {
+		u.Logger.ErrorLog.Println("todo is already completed")
+		return domain_recurrence.Recurrence{}, errors.New("todo is already completed")
+	}
+
+	// 繰り返しリポジトリに登録(repository層)
+	recurrence, err := u.recurrenceRepository.UpsertRecurrence(domain_recurrence.Recurrence{
+		TodoId:   todo.ID,
+		Rule:     parsed.String(),
+		Timezone: timezone,
+		StartAt:  *todo.DueAt,
+	})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to set recurrence: %v", err)
+		return domain_recurrence.Recurrence{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Set recurrence: %v", recurrence.TodoId)
+	return recurrence, nil
+}
+
+// Todoの繰り返しを解除
+func (u *RecurrenceUsecase) ClearRecurrence(userId string, todoId string) error {
+	u.Logger.InfoLog.Println("ClearRecurrence called")
+
+	// 所有者チェック
+	_, err := u.checkTodoOwner(userId, todoId)
+	if err != nil {
+		return err
+	}
+
+	// 繰り返しリポジトリから削除(repository層)
+	err = u.recurrenceRepository.DeleteRecurrence(todoId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to clear recurrence: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Cleared recurrence: %v", todoId)
+	return nil
+}
+
+// 発生を1回スキップ(現在の発生分をスキップした場合は、Todoの期限を次の発生日時に移す)
+func (u *RecurrenceUsecase) SkipOccurrence(userId string, todoId string, at time.Time, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("SkipOccurrence called")
+
+	// 所有者チェック
+	todo, err := u.checkTodoOwner(userId, todoId)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+	recurrence, rule, loc, err := u.loadRecurrence(todoId)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// 繰り返しの発生日時であること、現在の発生分以降であることを確認
+	dtstart := recurrence.StartAt.In(loc)
+	matched := rule.Between(dtstart, at, at.Add(time.Second), nil, 1)
+	if len(matched) == 0 || !matched[0].Equal(at) {
+		u.Logger.ErrorLog.Printf("Not an occurrence: %v", at)
+		return domain_todo.Todo{}, errors.New("not an occurrence")
+	}
+	if todo.DueAt != nil && at.Before(*todo.DueAt) {
+		u.Logger.ErrorLog.Printf("Occurrence already passed: %v", at)
+		return domain_todo.Todo{}, errors.New("occurrence already passed")
+	}
+
+	// 現在の発生分をスキップする場合は、次の発生日時を求める
+	isCurrent := todo.DueAt != nil && at.Equal(*todo.DueAt)
+	var next time.Time
+	if isCurrent {
+		var ok bool
+		next, ok = rule.Next(dtstart, at, recurrence.ExDates)
+		if !ok {
+			u.Logger.ErrorLog.Println("cannot skip the last occurrence")
+			return domain_todo.Todo{}, errors.New("cannot skip the last occurrence")
+		}
+	}
+
+	// 繰り返しリポジトリに除外日を追加(repository層)
+	_, err = u.recurrenceRepository.AddExDate(todoId, at)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to add exdate: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	if !isCurrent {
+		u.Logger.InfoLog.Printf("Skipped occurrence: %v %v", todoId, at)
+		return todo, nil
+	}
+
+	// Todoの期限(とリマインド日時)を次の発生日時に移す
+	todo.RemindAt = shiftRemindAt(todo, next)
+	todo.DueAt = &next
+	updated, err := u.todoRepository.UpdateTodo(todo, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to move todo to next occurrence: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Skipped occurrence: %v %v", todoId, at)
+	return updated, nil
+}
+
+// 完了したTodoの次の発生分を作成(繰り返しがない・終了した場合はnil)
+func (u *RecurrenceUsecase) GenerateNextOccurrence(todo domain_todo.Todo, actor domain_audit.AuditActor) (*domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GenerateNextOccurrence called")
+
+	if !todo.Completed || todo.DueAt == nil {
+		return nil, nil
+	}
+	recurrence, rule, loc, err := u.loadRecurrence(todo.ID)
+	if err != nil {
+		if err.Error() == "recurrence not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// 次の発生日時を求める
+	next, ok := rule.Next(recurrence.StartAt.In(loc), *todo.DueAt, recurrence.ExDates)
+	if !ok {
+		u.Logger.InfoLog.Printf("Recurrence ended: %v", todo.ID)
+		return nil, nil
+	}
+
+	// 繰り返しリポジトリから次の発生分を作成(repository層)
+	created, err := u.recurrenceRepository.AdvanceRecurrence(todo.ID, domain_todo.Todo{
+		Description: todo.Description,
+		UserId:      todo.UserId,
+		DueAt:       &next,
+		Priority:    todo.Priority,
+		RemindAt:    shiftRemindAt(todo, next),
+		ListId:      todo.ListId,
+		ParentId:    todo.ParentId,
+		Position:    todo.Position,
+	}, actor)
+	if errors.Is(err, repository_recurrence.ErrRecurrenceNotFound) {
+		// 同時に完了され、既に作成済み
+		return nil, nil
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to generate next occurrence: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Generated next occurrence: %v", created.ID)
+	return &created, nil
+}
+
+// 特定のユーザーの指定期間内の発生を取得
+func (u *RecurrenceUsecase) GetUpcomingOccurrences(userId string, within time.Duration, limit int) ([]domain_recurrence.Occurrence, error) {
+	u.Logger.InfoLog.Println("GetUpcomingOccurrences called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+	if within <= 0 {
+		u.Logger.ErrorLog.Println("period must be positive")
+		return nil, errors.New("period must be positive")
+	}
+	if limit <= 0 {
+		u.Logger.ErrorLog.Println("limit must be positive")
+		return nil, errors.New("limit must be positive")
+	}
+
+	// 繰り返しリポジトリから取得(repository層)
+	recurrences, err := u.recurrenceRepository.GetActiveRecurrencesByUserId(userId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get recurrences: %v", err)
+		return nil, err
+	}
+	if len(recurrences) == 0 {
+		return []domain_recurrence.Occurrence{}, nil
+	}
+
+	// 現在の発生分(Todoの期限)を取得
+	todoIds := make([]string, 0, len(recurrences))
+	for _, rc := range recurrences {
+		todoIds = append(todoIds, rc.TodoId)
+	}
+	todos, err := u.todoRepository.GetTodosByIds(todoIds)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todos: %v", err)
+		return nil, err
+	}
+	dueById := make(map[string]*time.Time, len(todos))
+	for _, t := range todos {
+		dueById[t.ID] = t.DueAt
+	}
+
+	// 現在の発生分以降、期間内の発生を展開
+	now := u.now()
+	to := now.Add(within)
+	occurrences := []domain_recurrence.Occurrence{}
+	for _, rc := range recurrences {
+		due := dueById[rc.TodoId]
+		if due == nil {
+			continue
+		}
+		rule, err := pkg_rrule.Parse(rc.Rule)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Invalid stored rrule %v: %v", rc.TodoId, err)
+			continue
+		}
+		loc, err := time.LoadLocation(rc.Timezone)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Invalid stored timezone %v: %v", rc.TodoId, err)
+			continue
+		}
+		from := now
+		if due.After(from) {
+			from = *due
+		}
+		for _, at := range rule.Between(rc.StartAt.In(loc), from, to, rc.ExDates, limit) {
+			occurrences = append(occurrences, domain_recurrence.Occurrence{TodoId: rc.TodoId, At: at})
+		}
+	}
+
+	// 発生日時順に並べ、件数を制限
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].At.Before(occurrences[j].At) })
+	if len(occurrences) > limit {
+		occurrences = occurrences[:limit]
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d occurrences", len(occurrences))
+	return occurrences, nil
+}
+
+// 繰り返し設定を取得し、ルールとタイムゾーンを解析
+func (u *RecurrenceUsecase) loadRecurrence(todoId string) (domain_recurrence.Recurrence, pkg_rrule.Rule, *time.Location, error) {
+	recurrence, err := u.recurrenceRepository.GetRecurrenceByTodoId(todoId)
+	if errors.Is(err, repository_recurrence.ErrRecurrenceNotFound) {
+		return domain_recurrence.Recurrence{}, pkg_rrule.Rule{}, nil, errors.New("recurrence not found")
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get recurrence: %v", err)
+		return domain_recurrence.Recurrence{}, pkg_rrule.Rule{}, nil, err
+	}
+	rule, err := pkg_rrule.Parse(recurrence.Rule)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Invalid stored rrule: %v", err)
+		return domain_recurrence.Recurrence{}, pkg_rrule.Rule{}, nil, errors.New("invalid rrule")
+	}
+	loc, err := time.LoadLocation(recurrence.Timezone)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Invalid stored timezone: %v", err)
+		return domain_recurrence.Recurrence{}, pkg_rrule.Rule{}, nil, errors.New("invalid timezone")
+	}
+	return recurrence, rule, loc, nil
+}
+
+// Todoの所有者チェック
+func (u *RecurrenceUsecase) checkTodoOwner(userId string, todoId string) (domain_todo.Todo, error) {
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_todo.Todo{}, errors.New("user_id is empty")
+	}
+	if todoId == "" {
+		u.Logger.ErrorLog.Println("todo_id is empty")
+		return domain_todo.Todo{}, errors.New("todo_id is empty")
+	}
+	todo, err := u.todoRepository.GetTodoById(todoId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo: %v", err)
+		return domain_todo.Todo{}, errors.New("todo not found")
+	}
+	if todo.UserId != userId {
+		u.Logger.ErrorLog.Println("forbidden")
+		return domain_todo.Todo{}, errors.New("forbidden")
+	}
+	return todo, nil
+}
+
+// リマインド日時を、期限との差を保ったまま次の発生日時に合わせる
+func shiftRemindAt(todo domain_todo.Todo, next time.Time) *time.Time {
+	if todo.RemindAt == nil || todo.DueAt == nil {
+		return nil
+	}
+	remindAt := next.Add(todo.RemindAt.Sub(*todo.DueAt))
+	return &remindAt
+}
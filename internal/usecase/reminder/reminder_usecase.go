@@ -0,0 +1,67 @@
+package usecase_reminder
+
+import (
+	pkg_logger "backend/internal/pkg/logger"
+	repository_notification "backend/internal/repository/notification"
+	repository_todo "backend/internal/repository/todo"
+	"time"
+)
+
+// 一度に通知するリマインドの件数
+const reminderBatchSize = 100
+
+// リマインドユースケース(IF)
+type IReminderUsecase interface {
+	// リマインド日時を過ぎたTodoの通知
+	DispatchDueReminders() (int, error)
+}
+
+// リマインドユースケース(Impl)
+type ReminderUsecase struct {
+	Logger           *pkg_logger.AppLogger
+	todoRepository   repository_todo.ITodoRepository
+	reminderNotifier repository_notification.IReminderNotifier
+	now              func() time.Time
+}
+
+// リマインドユースケースのインスタンス化
+func NewReminderUsecase(l *pkg_logger.AppLogger, tr repository_todo.ITodoRepository, rn repository_notification.IReminderNotifier) IReminderUsecase {
+	return &ReminderUsecase{
+		Logger:           l,
+		todoRepository:   tr,
+		reminderNotifier: rn,
+		now:              time.Now,
+	}
+}
+
+// リマインド日時を過ぎたTodoの通知
+// 通知対象は通知前に通知済みとするため、通知に失敗した場合も再通知はしない。
+func (u *ReminderUsecase) DispatchDueReminders() (int, error) {
+	u.Logger.InfoLog.Println("DispatchDueReminders called")
+
+	dispatched := 0
+	for {
+		// Todoリポジトリから通知対象のTodoを取得(repository層)
+		todos, err := u.todoRepository.ClaimDueReminders(u.now(), reminderBatchSize)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to claim due reminders: %v", err)
+			return dispatched, err
+		}
+
+		for _, todo := range todos {
+			err := u.reminderNotifier.NotifyReminder(todo)
+			if err != nil {
+				u.Logger.ErrorLog.Printf("Failed to notify reminder: %v", err)
+				continue
+			}
+			dispatched++
+		}
+
+		if len(todos) < reminderBatchSize {
+			break
+		}
+	}
+
+	u.Logger.InfoLog.Printf("Dispatched %d reminders", dispatched)
+	return dispatched, nil
+}
@@ -0,0 +1,120 @@
+package usecase_reminder
+
+import (
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_notification "backend/internal/repository/notification"
+	repository_todo "backend/internal/repository/todo"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// 通知対象のTodoを一度だけ返すTodoリポジトリ
+type fakeTodoRepository struct {
+	repository_todo.ITodoRepository
+	due      []domain_todo.Todo
+	claimed  map[string]bool
+	nows     []time.Time
+	claimErr error
+}
+
+func (r *fakeTodoRepository) ClaimDueReminders(now time.Time, limit int) ([]domain_todo.Todo, error) {
+	r.nows = append(r.nows, now)
+	if r.claimErr != nil {
+		return nil, r.claimErr
+	}
+	todos := []domain_todo.Todo{}
+	for _, todo := range r.due {
+		if len(todos) == limit {
+			break
+		}
+		if r.claimed[todo.ID] || todo.RemindAt.After(now) {
+			continue
+		}
+		r.claimed[todo.ID] = true
+		todos = append(todos, todo)
+	}
+	return todos, nil
+}
+
+// 通知したTodoを記録するリマインド通知
+type fakeReminderNotifier struct {
+	repository_notification.IReminderNotifier
+	notified map[string]int
+	failId   string
+}
+
+func (n *fakeReminderNotifier) NotifyReminder(todo domain_todo.Todo) error {
+	n.notified[todo.ID]++
+	if todo.ID == n.failId {
+		return errors.New("notifier unavailable")
+	}
+	return nil
+}
+
+// リマインド日時を過ぎたTodoを全てのバッチで一度ずつ通知し、失敗しても再通知しない
+func TestDispatchDueReminders(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	due := []domain_todo.Todo{}
+	for i := 0; i < reminderBatchSize+3; i++ {
+		due = append(due, domain_todo.Todo{ID: fmt.Sprintf("todo-%03d", i), RemindAt: &past})
+	}
+	due = append(due, domain_todo.Todo{ID: "later", RemindAt: &future})
+
+	tr := &fakeTodoRepository{due: due, claimed: map[string]bool{}}
+	rn := &fakeReminderNotifier{notified: map[string]int{}, failId: "todo-001"}
+	u := NewReminderUsecase(newTestLogger(), tr, rn).(*ReminderUsecase)
+	u.now = func() time.Time { return now }
+
+	dispatched, err := u.DispatchDueReminders()
+	if err != nil {
+		t.Fatalf("DispatchDueReminders returned error: %v", err)
+	}
+	if dispatched != reminderBatchSize+2 {
+		t.Errorf("dispatched = %d, want %d", dispatched, reminderBatchSize+2)
+	}
+	if len(tr.nows) != 2 {
+		t.Errorf("claims = %d, want 2", len(tr.nows))
+	}
+	for id, count := range rn.notified {
+		if count != 1 {
+			t.Errorf("todo %s notified %d times, want once", id, count)
+		}
+	}
+	if rn.notified["later"] != 0 {
+		t.Error("todo with a future remindAt was notified")
+	}
+
+	// 通知済みのTodoは、通知に失敗したものも含めて次の実行で再通知しない
+	dispatched, err = u.DispatchDueReminders()
+	if err != nil {
+		t.Fatalf("second DispatchDueReminders returned error: %v", err)
+	}
+	if dispatched != 0 || rn.notified["todo-001"] != 1 {
+		t.Errorf("second run dispatched %d, failed todo notified %d times", dispatched, rn.notified["todo-001"])
+	}
+}
+
+// 通知対象の取得に失敗した場合はエラーを返す
+func TestDispatchDueRemindersClaimError(t *testing.T) {
+	tr := &fakeTodoRepository{claimed: map[string]bool{}, claimErr: errors.New("db unavailable")}
+	rn := &fakeReminderNotifier{notified: map[string]int{}}
+	u := NewReminderUsecase(newTestLogger(), tr, rn)
+
+	dispatched, err := u.DispatchDueReminders()
+	if err == nil || dispatched != 0 {
+		t.Errorf("DispatchDueReminders = %d, %v, want error", dispatched, err)
+	}
+}
@@ -0,0 +1,120 @@
+package usecase_search
+
+import (
+	domain_search "backend/internal/domain/search"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_search "backend/internal/repository/search"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// 検索結果の取得件数
+const (
+	defaultTodoSearchLimit = 20
+	maxTodoSearchLimit     = 100
+)
+
+// Todoの全文検索ユースケース(IF)
+type ITodoSearchUsecase interface {
+	// 全文検索の言語を設定(変更した場合は索引を作り直す)
+	ConfigureLanguage(language string) error
+	// 自分のTodoを検索(firstが0の場合は既定の件数、afterが空の場合は先頭から)
+	SearchTodos(userId string, query string, first int, after string) (domain_search.TodoSearchPage, error)
+}
+
+// Todoの全文検索ユースケース(Impl)
+type TodoSearchUsecase struct {
+	Logger           *pkg_logger.AppLogger
+	searchRepository repository_search.ITodoSearchRepository
+}
+
+// Todoの全文検索ユースケースのインスタンス化
+func NewTodoSearchUsecase(l *pkg_logger.AppLogger, sr repository_search.ITodoSearchRepository) ITodoSearchUsecase {
+	return &TodoSearchUsecase{
+		Logger:           l,
+		searchRepository: sr,
+	}
+}
+
+// 全文検索の言語を設定(変更した場合は索引を作り直す)
+func (u *TodoSearchUsecase) ConfigureLanguage(language string) error {
+	u.Logger.InfoLog.Println("ConfigureLanguage called")
+
+	// バリデーション
+	language = strings.TrimSpace(language)
+	if language == "" {
+		u.Logger.ErrorLog.Println("search language is empty")
+		return errors.New("search language is empty")
+	}
+
+	// 全文検索リポジトリから言語を設定(repository層)
+	reindexed, err := u.searchRepository.SetSearchLanguage(language)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to set search language: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Configured search language: %s (reindexed: %v)", language, reindexed)
+	return nil
+}
+
+// 自分のTodoを検索(firstが0の場合は既定の件数、afterが空の場合は先頭から)
+func (u *TodoSearchUsecase) SearchTodos(userId string, query string, first int, after string) (domain_search.TodoSearchPage, error) {
+	u.Logger.InfoLog.Println("SearchTodos called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_search.TodoSearchPage{}, errors.New("user_id is empty")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		u.Logger.ErrorLog.Println("query is empty")
+		return domain_search.TodoSearchPage{}, errors.New("query is empty")
+	}
+	if utf8.RuneCountInString(query) > domain_search.MaxTodoSearchQueryLength {
+		u.Logger.ErrorLog.Println("query is too long")
+		return domain_search.TodoSearchPage{}, errors.New("query is too long")
+	}
+	if first < 0 {
+		u.Logger.ErrorLog.Println("first must not be negative")
+		return domain_search.TodoSearchPage{}, errors.New("first must not be negative")
+	}
+	if first == 0 {
+		first = defaultTodoSearchLimit
+	}
+	if first > maxTodoSearchLimit {
+		first = maxTodoSearchLimit
+	}
+	var cursor *domain_search.TodoSearchCursor
+	if after != "" {
+		c, err := domain_search.DecodeTodoSearchCursor(after)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Invalid cursor: %v", err)
+			return domain_search.TodoSearchPage{}, err
+		}
+		cursor = &c
+	}
+
+	// 全文検索リポジトリから検索(repository層)
+	// 次のページがあるかを判定するため、1件多く取得する。
+	results, err := u.searchRepository.SearchTodos(userId, domain_search.ParseTodoSearchQuery(query), first+1, cursor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to search todos: %v", err)
+		return domain_search.TodoSearchPage{}, err
+	}
+
+	page := domain_search.TodoSearchPage{Results: results}
+	if len(results) > first {
+		page.Results = results[:first]
+		page.HasNextPage = true
+	}
+	if len(page.Results) > 0 {
+		last := page.Results[len(page.Results)-1]
+		page.EndCursor = domain_search.EncodeTodoSearchCursor(domain_search.TodoSearchCursor{Rank: last.Rank, ID: last.Todo.ID})
+	}
+
+	u.Logger.InfoLog.Printf("Found %d todos (has next page: %v)", len(page.Results), page.HasNextPage)
+	return page, nil
+}
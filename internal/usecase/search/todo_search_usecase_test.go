@@ -0,0 +1,152 @@
+package usecase_search
+
+import (
+	domain_search "backend/internal/domain/search"
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_search "backend/internal/repository/search"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// 関連度の高い順の検索結果を保持し、渡された条件を記録する全文検索リポジトリ
+type fakeTodoSearchRepository struct {
+	repository_search.ITodoSearchRepository
+	results []domain_search.TodoSearchResult
+	queries []domain_search.TodoSearchQuery
+	limits  []int
+	afters  []*domain_search.TodoSearchCursor
+}
+
+func (r *fakeTodoSearchRepository) SearchTodos(userId string, query domain_search.TodoSearchQuery, limit int, after *domain_search.TodoSearchCursor) ([]domain_search.TodoSearchResult, error) {
+	r.queries = append(r.queries, query)
+	r.limits = append(r.limits, limit)
+	r.afters = append(r.afters, after)
+
+	// (関連度の降順, IDの昇順)でカーソルより後の結果を返す
+	results := []domain_search.TodoSearchResult{}
+	for _, result := range r.results {
+		if after != nil && (result.Rank > after.Rank || (result.Rank == after.Rank && result.Todo.ID <= after.ID)) {
+			continue
+		}
+		if len(results) == limit {
+			break
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func newSearchResults(ranks ...float32) []domain_search.TodoSearchResult {
+	results := make([]domain_search.TodoSearchResult, 0, len(ranks))
+	for i, rank := range ranks {
+		results = append(results, domain_search.TodoSearchResult{Todo: domain_todo.Todo{ID: fmt.Sprintf("t%d", i)}, Rank: rank})
+	}
+	return results
+}
+
+// カーソルで続きを取得すると、全ての結果を関連度順に重複なく返す
+func TestSearchTodosPaginates(t *testing.T) {
+	r := &fakeTodoSearchRepository{results: newSearchResults(0.9, 0.5, 0.5, 0.5, 0.1)}
+	u := NewTodoSearchUsecase(newTestLogger(), r)
+
+	ids := []string{}
+	after := ""
+	for pages := 0; ; pages++ {
+		if pages > len(r.results) {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := u.SearchTodos("u1", "milk", 2, after)
+		if err != nil {
+			t.Fatalf("SearchTodos() unexpected error: %v", err)
+		}
+		for _, result := range page.Results {
+			ids = append(ids, result.Todo.ID)
+		}
+		if !page.HasNextPage {
+			break
+		}
+		after = page.EndCursor
+	}
+
+	if !reflect.DeepEqual(ids, []string{"t0", "t1", "t2", "t3", "t4"}) {
+		t.Errorf("paginated ids = %v", ids)
+	}
+	// 次のページの有無を判定するため、1件多く取得する
+	for _, limit := range r.limits {
+		if limit != 3 {
+			t.Errorf("repository limit = %d, want 3", limit)
+		}
+	}
+	if r.afters[0] != nil || r.afters[1] == nil || *r.afters[1] != (domain_search.TodoSearchCursor{Rank: 0.5, ID: "t1"}) {
+		t.Errorf("repository cursors = %v, %v", r.afters[0], r.afters[1])
+	}
+}
+
+// 結果が無い場合はカーソルを返さない
+func TestSearchTodosEmpty(t *testing.T) {
+	u := NewTodoSearchUsecase(newTestLogger(), &fakeTodoSearchRepository{})
+
+	page, err := u.SearchTodos("u1", "milk", 0, "")
+	if err != nil {
+		t.Fatalf("SearchTodos() unexpected error: %v", err)
+	}
+	if len(page.Results) != 0 || page.HasNextPage || page.EndCursor != "" {
+		t.Errorf("SearchTodos() = %+v, want an empty page", page)
+	}
+}
+
+// 検索語は前後の空白を除いて分割し、件数は既定値と上限に揃える
+func TestSearchTodosValidates(t *testing.T) {
+	tests := []struct {
+		name      string
+		userId    string
+		query     string
+		first     int
+		after     string
+		wantErr   string
+		wantLimit int
+		wantQuery domain_search.TodoSearchQuery
+	}{
+		{name: "default first", userId: "u1", query: "  会議 notes ", wantLimit: defaultTodoSearchLimit + 1, wantQuery: domain_search.TodoSearchQuery{Text: "notes", Runs: []string{"会議"}}},
+		{name: "max first", userId: "u1", query: "milk", first: maxTodoSearchLimit + 50, wantLimit: maxTodoSearchLimit + 1, wantQuery: domain_search.TodoSearchQuery{Text: "milk", Runs: []string{}}},
+		{name: "unauthenticated", userId: "", query: "milk", wantErr: "user_id is empty"},
+		{name: "blank query", userId: "u1", query: "   ", wantErr: "query is empty"},
+		{name: "too long query", userId: "u1", query: strings.Repeat("a", domain_search.MaxTodoSearchQueryLength+1), wantErr: "query is too long"},
+		{name: "negative first", userId: "u1", query: "milk", first: -1, wantErr: "first must not be negative"},
+		{name: "broken cursor", userId: "u1", query: "milk", after: "!!", wantErr: "invalid cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeTodoSearchRepository{}
+			u := NewTodoSearchUsecase(newTestLogger(), r)
+
+			_, err := u.SearchTodos(tt.userId, tt.query, tt.first, tt.after)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("SearchTodos() error = %v, want %s", err, tt.wantErr)
+				}
+				if len(r.limits) != 0 {
+					t.Errorf("repository called for an invalid search")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SearchTodos() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(r.limits, []int{tt.wantLimit}) || !reflect.DeepEqual(r.queries, []domain_search.TodoSearchQuery{tt.wantQuery}) {
+				t.Errorf("repository called with %+v limit %v, want %+v limit %d", r.queries, r.limits, tt.wantQuery, tt.wantLimit)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package usecase_share
+
+import (
+	domain_share "backend/internal/domain/share"
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_share "backend/internal/repository/share"
+	repository_todo "backend/internal/repository/todo"
+	repository_user "backend/internal/repository/user"
+	"errors"
+)
+
+// 共有ユースケース(IF)
+type ITodoShareUsecase interface {
+	// 特定のユーザーへの招待中の共有を取得
+	GetInvitations(userId string) ([]domain_share.TodoShare, error)
+	// 共有を取得(todoIdがnilの場合は自分が所有する全ての共有、指定した場合はそのTodoの共有)
+	GetShares(userId string, todoId *string) ([]domain_share.TodoShare, error)
+	// Todoを他のユーザーに共有(招待)
+	ShareTodo(userId string, todoId string, memberId string, role string) (domain_share.TodoShare, error)
+	// 自分の全てのTodoを他のユーザーに共有(招待)
+	ShareAllTodos(userId string, memberId string, role string) (domain_share.TodoShare, error)
+	// 招待を承諾
+	AcceptInvitation(userId string, id string) (domain_share.TodoShare, error)
+	// 招待を辞退
+	DeclineInvitation(userId string, id string) error
+	// 共有の権限を変更
+	UpdateShareRole(userId string, id string, role string) (domain_share.TodoShare, error)
+	// 共有を解除(共有先のユーザー自身も解除できる)
+	RevokeShare(userId string, id string) error
+}
+
+// 共有ユースケース(Impl)
+type TodoShareUsecase struct {
+	Logger          *pkg_logger.AppLogger
+	shareRepository repository_share.ITodoShareRepository
+	todoRepository  repository_todo.ITodoRepository
+	userRepository  repository_user.IUserRepository
+}
+
+// 共有ユースケースのインスタンス化
+func NewTodoShareUsecase(l *pkg_logger.AppLogger, sr repository_share.ITodoShareRepository, tr repository_todo.ITodoRepository, ur repository_user.IUserRepository) ITodoShareUsecase {
+	return &TodoShareUsecase{
+		Logger:          l,
+		shareRepository: sr,
+		todoRepository:  tr,
+		userRepository:  ur,
+	}
+}
+
+// 特定のユーザーへの招待中の共有を取得
+func (u *TodoShareUsecase) GetInvitations(userId string) ([]domain_share.TodoShare, error) {
+	u.Logger.InfoLog.Println("GetInvitations called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// 共有リポジトリから招待中の共有を取得(repository層)
+	shares, err := u.shareRepository.GetSharesByMemberId(userId, domain_share.ShareStatusPending)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get invitations: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d invitations", len(shares))
+	return shares, nil
+}
+
+// 共有を取得(todoIdがnilの場合は自分が所有する全ての共有、指定した場合はそのTodoの共有)
+func (u *TodoShareUsecase) GetShares(userId string, todoId *string) ([]domain_share.TodoShare, error) {
+	u.Logger.InfoLog.Println("GetShares called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Todoを指定した場合は、所有者権限で共有されたメンバーも取得できる
+	ownerId := userId
+	if todoId != nil {
+		todo, err := u.authorizeTodo(userId, *todoId)
+		if err != nil {
+			return nil, err
+		}
+		ownerId = todo.UserId
+	}
+
+	// 共有リポジトリから共有を取得(repository層)
+	shares, err := u.shareRepository.GetSharesByOwnerId(ownerId, todoId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get shares: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d shares", len(shares))
+	return shares, nil
+}
+
+// Todoを他のユーザーに共有(招待)
+func (u *TodoShareUsecase) ShareTodo(userId string, todoId string, memberId string, role string) (domain_share.TodoShare, error) {
+	u.Logger.InfoLog.Println("ShareTodo called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_share.TodoShare{}, errors.New("user_id is empty")
+	}
+
+	// 権限チェック(共有の管理は所有者権限が必要)
+	todo, err := u.authorizeTodo(userId, todoId)
+	if err != nil {
+		return domain_share.TodoShare{}, err
+	}
+
+	return u.createShare(domain_share.TodoShare{
+		OwnerId:   todo.UserId,
+		TodoId:    &todo.ID,
+		MemberId:  memberId,
+		Role:      role,
+		InvitedBy: userId,
+	})
+}
+
+// 自分の全てのTodoを他のユーザーに共有(招待)
+func (u *TodoShareUsecase) ShareAllTodos(userId string, memberId string, role string) (domain_share.TodoShare, error) {
+	u.Logger.InfoLog.Println("ShareAllTodos called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_share.TodoShare{}, errors.New("user_id is empty")
+	}
+
+	return u.createShare(domain_share.TodoShare{
+		OwnerId:   userId,
+		MemberId:  memberId,
+		Role:      role,
+		InvitedBy: userId,
+	})
+}
+
+// 招待を承諾
+func (u *TodoShareUsecase) AcceptInvitation(userId string, id string) (domain_share.TodoShare, error) {
+	u.Logger.InfoLog.Println("AcceptInvitation called")
+
+	// 招待先のチェック
+	_, err := u.getInvitation(userId, id)
+	if err != nil {
+		return domain_share.TodoShare{}, err
+	}
+
+	// 共有リポジトリから承諾(repository層)
+	share, err := u.shareRepository.AcceptShare(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to accept invitation: %v", err)
+		return domain_share.TodoShare{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Accepted invitation: %v", share.ID)
+	return share, nil
+}
+
+// 招待を辞退
+func (u *TodoShareUsecase) DeclineInvitation(userId string, id string) error {
+	u.Logger.InfoLog.Println("DeclineInvitation called")
+
+	// 招待先のチェック
+	_, err := u.getInvitation(userId, id)
+	if err != nil {
+		return err
+	}
+
+	// 共有リポジトリから削除(repository層)
+	err = u.shareRepository.DeleteShare(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to decline invitation: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Declined invitation: %v", id)
+	return nil
+}
+
+// 共有の権限を変更
+func (u *TodoShareUsecase) UpdateShareRole(userId string, id string, role string) (domain_share.TodoShare, error) {
+	u.Logger.InfoLog.Println("UpdateShareRole called")
+
+	// バリデーション
+	if !domain_share.IsValidRole(role) {
+		u.Logger.ErrorLog.Printf("Invalid role: %s", role)
+		return domain_share.TodoShare{}, errors.New("invalid role")
+	}
+
+	// 権限チェック
+	share, err := u.getShare(userId, id)
+	if err != nil {
+		return domain_share.TodoShare{}, err
+	}
+	err = u.checkManageable(userId, share)
+	if err != nil {
+		return domain_share.TodoShare{}, err
+	}
+
+	// 共有リポジトリから権限を変更(repository層)
+	updated, err := u.shareRepository.UpdateShareRole(id, role)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to update share role: %v", err)
+		return domain_share.TodoShare{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Updated share role: %v", updated.ID)
+	return updated, nil
+}
+
+// 共有を解除(共有先のユーザー自身も解除できる)
+func (u *TodoShareUsecase) RevokeShare(userId string, id string) error {
+	u.Logger.InfoLog.Println("RevokeShare called")
+
+	// 権限チェック
+	share, err := u.getShare(userId, id)
+	if err != nil {
+		return err
+	}
+	if share.MemberId != userId {
+		err = u.checkManageable(userId, share)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 共有リポジトリから削除(repository層)
+	err = u.shareRepository.DeleteShare(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to revoke share: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Revoked share: %v", id)
+	return nil
+}
+
+// 共有(招待)を作成
+func (u *TodoShareUsecase) createShare(share domain_share.TodoShare) (domain_share.TodoShare, error) {
+	// バリデーション
+	if share.MemberId == "" {
+		u.Logger.ErrorLog.Println("member_id is empty")
+		return domain_share.TodoShare{}, errors.New("member_id is empty")
+	}
+	if !domain_share.IsValidRole(share.Role) {
+		u.Logger.ErrorLog.Printf("Invalid role: %s", share.Role)
+		return domain_share.TodoShare{}, errors.New("invalid role")
+	}
+	if share.MemberId == share.OwnerId {
+		u.Logger.ErrorLog.Println("cannot share with owner")
+		return domain_share.TodoShare{}, errors.New("cannot share with owner")
+	}
+
+	// 共有先のユーザーの存在チェック
+	users, err := u.userRepository.GetUsersByIds([]string{share.MemberId})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get member: %v", err)
+		return domain_share.TodoShare{}, err
+	}
+	if len(users) == 0 {
+		u.Logger.ErrorLog.Println("member not found")
+		return domain_share.TodoShare{}, errors.New("member not found")
+	}
+
+	// 共有リポジトリから作成(repository層)
+	created, err := u.shareRepository.CreateShare(share)
+	if err != nil {
+		if errors.Is(err, repository_share.ErrShareConflict) {
+			u.Logger.ErrorLog.Println("already shared")
+			return domain_share.TodoShare{}, errors.New("already shared")
+		}
+		u.Logger.ErrorLog.Printf("Failed to create share: %v", err)
+		return domain_share.TodoShare{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Created share: %v", created.ID)
+	return created, nil
+}
+
+// 共有を取得
+func (u *TodoShareUsecase) getShare(userId string, id string) (domain_share.TodoShare, error) {
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_share.TodoShare{}, errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("share_id is empty")
+		return domain_share.TodoShare{}, errors.New("share_id is empty")
+	}
+	share, err := u.shareRepository.GetShareById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get share: %v", err)
+		return domain_share.TodoShare{}, errors.New("share not found")
+	}
+	return share, nil
+}
+
+// 自分宛ての招待中の共有を取得
+// 他のユーザー宛ての招待は存在を明かさないため、見つからない扱いにする
+func (u *TodoShareUsecase) getInvitation(userId string, id string) (domain_share.TodoShare, error) {
+	share, err := u.getShare(userId, id)
+	if err != nil {
+		if err.Error() == "share not found" {
+			return domain_share.TodoShare{}, errors.New("invitation not found")
+		}
+		return domain_share.TodoShare{}, err
+	}
+	if share.MemberId != userId {
+		u.Logger.ErrorLog.Println("invitation not found")
+		return domain_share.TodoShare{}, errors.New("invitation not found")
+	}
+	if share.Status != domain_share.ShareStatusPending {
+		u.Logger.ErrorLog.Println("invitation already accepted")
+		return domain_share.TodoShare{}, errors.New("invitation already accepted")
+	}
+	return share, nil
+}
+
+// 共有を管理できるかのチェック
+// 所有者は全ての共有を、所有者権限で共有されたメンバーは共有されたTodoの共有を管理できる
+func (u *TodoShareUsecase) checkManageable(userId string, share domain_share.TodoShare) error {
+	if share.OwnerId == userId {
+		return nil
+	}
+	if share.TodoId == nil {
+		u.Logger.ErrorLog.Println("forbidden")
+		return errors.New("forbidden")
+	}
+	_, err := u.authorizeTodo(userId, *share.TodoId)
+	return err
+}
+
+// Todoに対して所有者権限を持つことを確認し、Todoを取得
+func (u *TodoShareUsecase) authorizeTodo(userId string, todoId string) (domain_todo.Todo, error) {
+	if todoId == "" {
+		u.Logger.ErrorLog.Println("todo_id is empty")
+		return domain_todo.Todo{}, errors.New("todo_id is empty")
+	}
+	todo, err := u.todoRepository.GetTodoById(todoId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo: %v", err)
+		return domain_todo.Todo{}, errors.New("todo not found")
+	}
+	if todo.UserId == userId {
+		return todo, nil
+	}
+
+	// 共有メンバーの権限は、Todo自身・祖先のTodo・所有者の全てのTodoへの共有のうち最も強いもの
+	ancestorIds, err := u.todoRepository.GetTodoAncestorIds(todo.ID)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get ancestors: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	roles, err := u.shareRepository.GetMemberRoles(userId, todo.UserId, append([]string{todo.ID}, ancestorIds...))
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get member roles: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	if !domain_share.RoleAtLeast(domain_share.HighestRole(roles), domain_share.ShareRoleOwner) {
+		u.Logger.ErrorLog.Println("forbidden")
+		return domain_todo.Todo{}, errors.New("forbidden")
+	}
+	return todo, nil
+}
@@ -0,0 +1,305 @@
+package usecase_tag
+
+import (
+	domain_tag "backend/internal/domain/tag"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_tag "backend/internal/repository/tag"
+	repository_todo "backend/internal/repository/todo"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// タグユースケース(IF)
+type ITagUsecase interface {
+	// 特定のユーザーのタグを取得
+	GetTagsByUserId(userId string) ([]domain_tag.Tag, error)
+	// 複数のTodoに付与されたタグを取得
+	GetTagsByTodoIds(todoIds []string) ([]domain_tag.TodoTag, error)
+	// 特定のユーザーの、指定したタグが全て付与されたTodoのIDを取得
+	GetTodoIdsByTagIds(userId string, tagIds []string) ([]string, error)
+	// 新しいタグを作成
+	CreateTag(userId string, name string) (domain_tag.Tag, error)
+	// タグ名を変更
+	RenameTag(userId string, id string, name string) (domain_tag.Tag, error)
+	// タグを削除
+	DeleteTag(userId string, id string) error
+	// Todoにタグを付与
+	TagTodo(userId string, todoId string, tagId string) error
+	// Todoからタグを外す
+	UntagTodo(userId string, todoId string, tagId string) error
+}
+
+// タグユースケース(Impl)
+type TagUsecase struct {
+	Logger         *pkg_logger.AppLogger
+	tagRepository  repository_tag.ITagRepository
+	todoRepository repository_todo.ITodoRepository
+}
+
+// タグユースケースのインスタンス化
+func NewTagUsecase(l *pkg_logger.AppLogger, tgr repository_tag.ITagRepository, tr repository_todo.ITodoRepository) ITagUsecase {
+	return &TagUsecase{
+		Logger:         l,
+		tagRepository:  tgr,
+		todoRepository: tr,
+	}
+}
+
+// 特定のユーザーのタグを取得
+func (u *TagUsecase) GetTagsByUserId(userId string) ([]domain_tag.Tag, error) {
+	u.Logger.InfoLog.Println("GetTagsByUserId called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// タグリポジトリから取得(repository層)
+	tags, err := u.tagRepository.GetTagsByUserId(userId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get tags: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d tags", len(tags))
+	return tags, nil
+}
+
+// 複数のTodoに付与されたタグを取得
+func (u *TagUsecase) GetTagsByTodoIds(todoIds []string) ([]domain_tag.TodoTag, error) {
+	u.Logger.InfoLog.Println("GetTagsByTodoIds called")
+
+	// バリデーション
+	if len(todoIds) == 0 {
+		return []domain_tag.TodoTag{}, nil
+	}
+
+	// タグリポジトリから取得(repository層)
+	todoTags, err := u.tagRepository.GetTagsByTodoIds(todoIds)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get tags by todo_ids: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d todo tags", len(todoTags))
+	return todoTags, nil
+}
+
+// 特定のユーザーの、指定したタグが全て付与されたTodoのIDを取得
+func (u *TagUsecase) GetTodoIdsByTagIds(userId string, tagIds []string) ([]string, error) {
+	u.Logger.InfoLog.Println("GetTodoIdsByTagIds called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+	// 重複を除く
+	unique := make([]string, 0, len(tagIds))
+	seen := make(map[string]bool, len(tagIds))
+	for _, id := range tagIds {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) == 0 {
+		return []string{}, nil
+	}
+
+	// タグリポジトリから取得(repository層)
+	ids, err := u.tagRepository.GetTodoIdsByTagIds(userId, unique)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo ids by tags: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d todo ids", len(ids))
+	return ids, nil
+}
+
+// 新しいタグを作成
+func (u *TagUsecase) CreateTag(userId string, name string) (domain_tag.Tag, error) {
+	u.Logger.InfoLog.Println("CreateTag called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_tag.Tag{}, errors.New("user_id is empty")
+	}
+	name, err := u.validateName(name)
+	if err != nil {
+		return domain_tag.Tag{}, err
+	}
+
+	// タグリポジトリから作成(repository層)
+	tag, err := u.tagRepository.CreateTag(domain_tag.Tag{UserId: userId, Name: name})
+	if err != nil {
+		if errors.Is(err, repository_tag.ErrTagNameConflict) {
+			u.Logger.ErrorLog.Println("tag already exists")
+			return domain_tag.Tag{}, errors.New("tag already exists")
+		}
+		u.Logger.ErrorLog.Printf("Failed to create tag: %v", err)
+		return domain_tag.Tag{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Created tag: %v", tag.ID)
+	return tag, nil
+}
+
+// タグ名を変更
+func (u *TagUsecase) RenameTag(userId string, id string, name string) (domain_tag.Tag, error) {
+	u.Logger.InfoLog.Println("RenameTag called")
+
+	// バリデーション
+	name, err := u.validateName(name)
+	if err != nil {
+		return domain_tag.Tag{}, err
+	}
+	err = u.checkTagOwner(userId, id)
+	if err != nil {
+		return domain_tag.Tag{}, err
+	}
+
+	// タグリポジトリから変更(repository層)
+	tag, err := u.tagRepository.RenameTag(id, name)
+	if err != nil {
+		if errors.Is(err, repository_tag.ErrTagNameConflict) {
+			u.Logger.ErrorLog.Println("tag already exists")
+			return domain_tag.Tag{}, errors.New("tag already exists")
+		}
+		u.Logger.ErrorLog.Printf("Failed to rename tag: %v", err)
+		return domain_tag.Tag{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Renamed tag: %v", tag.ID)
+	return tag, nil
+}
+
+// タグを削除
+func (u *TagUsecase) DeleteTag(userId string, id string) error {
+	u.Logger.InfoLog.Println("DeleteTag called")
+
+	// 所有者チェック
+	err := u.checkTagOwner(userId, id)
+	if err != nil {
+		return err
+	}
+
+	// タグリポジトリから削除(repository層)
+	err = u.tagRepository.DeleteTag(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to delete tag: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Deleted tag: %v", id)
+	return nil
+}
+
+// Todoにタグを付与
+func (u *TagUsecase) TagTodo(userId string, todoId string, tagId string) error {
+	u.Logger.InfoLog.Println("TagTodo called")
+
+	// 所有者チェック(Todo・タグとも自分のもののみ)
+	err := u.checkTodoOwner(userId, todoId)
+	if err != nil {
+		return err
+	}
+	err = u.checkTagOwner(userId, tagId)
+	if err != nil {
+		return err
+	}
+
+	// タグリポジトリから付与(repository層)
+	err = u.tagRepository.TagTodo(todoId, tagId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to tag todo: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Tagged todo: %v", todoId)
+	return nil
+}
+
+// Todoからタグを外す
+func (u *TagUsecase) UntagTodo(userId string, todoId string, tagId string) error {
+	u.Logger.InfoLog.Println("UntagTodo called")
+
+	// 所有者チェック(Todo・タグとも自分のもののみ)
+	err := u.checkTodoOwner(userId, todoId)
+	if err != nil {
+		return err
+	}
+	err = u.checkTagOwner(userId, tagId)
+	if err != nil {
+		return err
+	}
+
+	// タグリポジトリから外す(repository層)
+	err = u.tagRepository.UntagTodo(todoId, tagId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to untag todo: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Untagged todo: %v", todoId)
+	return nil
+}
+
+// タグ名のバリデーション(前後の空白を除いた名前を返す)
+func (u *TagUsecase) validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		u.Logger.ErrorLog.Println("name is empty")
+		return "", errors.New("name is empty")
+	}
+	if utf8.RuneCountInString(name) > domain_tag.MaxTagNameLength {
+		u.Logger.ErrorLog.Println("name is too long")
+		return "", errors.New("name is too long")
+	}
+	return name, nil
+}
+
+// タグの所有者チェック
+func (u *TagUsecase) checkTagOwner(userId string, id string) error {
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("tag_id is empty")
+		return errors.New("tag_id is empty")
+	}
+	tag, err := u.tagRepository.GetTagById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get tag: %v", err)
+		return errors.New("tag not found")
+	}
+	if tag.UserId != userId {
+		u.Logger.ErrorLog.Println("forbidden")
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+// Todoの所有者チェック
+func (u *TagUsecase) checkTodoOwner(userId string, todoId string) error {
+	if todoId == "" {
+		u.Logger.ErrorLog.Println("todo_id is empty")
+		return errors.New("todo_id is empty")
+	}
+	todo, err := u.todoRepository.GetTodoById(todoId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo: %v", err)
+		return errors.New("todo not found")
+	}
+	if todo.UserId != userId {
+		u.Logger.ErrorLog.Println("forbidden")
+		return errors.New("forbidden")
+	}
+	return nil
+}
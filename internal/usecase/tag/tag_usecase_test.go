@@ -0,0 +1,203 @@
+package usecase_tag
+
+import (
+	domain_tag "backend/internal/domain/tag"
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_tag "backend/internal/repository/tag"
+	repository_todo "backend/internal/repository/todo"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// タグをメモリ上に保持し、変更を記録するタグリポジトリ
+type fakeTagRepository struct {
+	repository_tag.ITagRepository
+	tags    map[string]domain_tag.Tag
+	changes []string
+	tagIds  [][]string
+}
+
+func (r *fakeTagRepository) GetTagById(id string) (domain_tag.Tag, error) {
+	tag, ok := r.tags[id]
+	if !ok {
+		return domain_tag.Tag{}, errors.New("no rows in result set")
+	}
+	return tag, nil
+}
+
+func (r *fakeTagRepository) CreateTag(tag domain_tag.Tag) (domain_tag.Tag, error) {
+	for _, t := range r.tags {
+		if t.UserId == tag.UserId && t.Name == tag.Name {
+			return domain_tag.Tag{}, repository_tag.ErrTagNameConflict
+		}
+	}
+	tag.ID = "new"
+	r.changes = append(r.changes, "create "+tag.Name)
+	return tag, nil
+}
+
+func (r *fakeTagRepository) RenameTag(id string, name string) (domain_tag.Tag, error) {
+	tag := r.tags[id]
+	tag.Name = name
+	r.changes = append(r.changes, "rename "+id)
+	return tag, nil
+}
+
+func (r *fakeTagRepository) DeleteTag(id string) error {
+	r.changes = append(r.changes, "delete "+id)
+	return nil
+}
+
+func (r *fakeTagRepository) TagTodo(todoId string, tagId string) error {
+	r.changes = append(r.changes, "tag "+todoId+" "+tagId)
+	return nil
+}
+
+func (r *fakeTagRepository) UntagTodo(todoId string, tagId string) error {
+	r.changes = append(r.changes, "untag "+todoId+" "+tagId)
+	return nil
+}
+
+func (r *fakeTagRepository) GetTodoIdsByTagIds(userId string, tagIds []string) ([]string, error) {
+	r.tagIds = append(r.tagIds, tagIds)
+	return []string{"todo-1"}, nil
+}
+
+// Todoをメモリ上に保持するTodoリポジトリ
+type fakeTodoRepository struct {
+	repository_todo.ITodoRepository
+	todos map[string]domain_todo.Todo
+}
+
+func (r *fakeTodoRepository) GetTodoById(id string) (domain_todo.Todo, error) {
+	todo, ok := r.todos[id]
+	if !ok {
+		return domain_todo.Todo{}, errors.New("no rows in result set")
+	}
+	return todo, nil
+}
+
+func newTestTagUsecase() (ITagUsecase, *fakeTagRepository) {
+	tgr := &fakeTagRepository{tags: map[string]domain_tag.Tag{
+		"tag-alice": {ID: "tag-alice", UserId: "alice", Name: "work"},
+		"tag-bob":   {ID: "tag-bob", UserId: "bob", Name: "home"},
+	}}
+	tr := &fakeTodoRepository{todos: map[string]domain_todo.Todo{
+		"todo-alice": {ID: "todo-alice", UserId: "alice"},
+		"todo-bob":   {ID: "todo-bob", UserId: "bob"},
+	}}
+	return NewTagUsecase(newTestLogger(), tgr, tr), tgr
+}
+
+// タグの変更とTodoへの付与は、タグとTodoの両方の所有者のみ
+func TestTagMutationsRequireOwner(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(u ITagUsecase) error
+		wantErr    string
+		wantChange string
+	}{
+		{name: "rename own tag", call: func(u ITagUsecase) error { _, err := u.RenameTag("alice", "tag-alice", " urgent "); return err }, wantChange: "rename tag-alice"},
+		{name: "rename other's tag", call: func(u ITagUsecase) error { _, err := u.RenameTag("alice", "tag-bob", "urgent"); return err }, wantErr: "forbidden"},
+		{name: "rename missing tag", call: func(u ITagUsecase) error { _, err := u.RenameTag("alice", "tag-x", "urgent"); return err }, wantErr: "tag not found"},
+		{name: "delete own tag", call: func(u ITagUsecase) error { return u.DeleteTag("alice", "tag-alice") }, wantChange: "delete tag-alice"},
+		{name: "delete other's tag", call: func(u ITagUsecase) error { return u.DeleteTag("alice", "tag-bob") }, wantErr: "forbidden"},
+		{name: "delete unauthenticated", call: func(u ITagUsecase) error { return u.DeleteTag("", "tag-alice") }, wantErr: "user_id is empty"},
+		{name: "tag own todo", call: func(u ITagUsecase) error { return u.TagTodo("alice", "todo-alice", "tag-alice") }, wantChange: "tag todo-alice tag-alice"},
+		{name: "tag other's todo", call: func(u ITagUsecase) error { return u.TagTodo("alice", "todo-bob", "tag-alice") }, wantErr: "forbidden"},
+		{name: "tag with other's tag", call: func(u ITagUsecase) error { return u.TagTodo("alice", "todo-alice", "tag-bob") }, wantErr: "forbidden"},
+		{name: "tag missing todo", call: func(u ITagUsecase) error { return u.TagTodo("alice", "todo-x", "tag-alice") }, wantErr: "todo not found"},
+		{name: "untag own todo", call: func(u ITagUsecase) error { return u.UntagTodo("alice", "todo-alice", "tag-alice") }, wantChange: "untag todo-alice tag-alice"},
+		{name: "untag other's todo", call: func(u ITagUsecase) error { return u.UntagTodo("bob", "todo-alice", "tag-bob") }, wantErr: "forbidden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, tgr := newTestTagUsecase()
+			err := tt.call(u)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(tgr.changes) != 0 {
+					t.Errorf("changes = %v, want none", tgr.changes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned error: %v", err)
+			}
+			if !reflect.DeepEqual(tgr.changes, []string{tt.wantChange}) {
+				t.Errorf("changes = %v, want [%s]", tgr.changes, tt.wantChange)
+			}
+		})
+	}
+}
+
+// タグ名は前後の空白を除き、空・長すぎる名前・同じユーザーの重複はエラーにする
+func TestCreateTagValidatesName(t *testing.T) {
+	tests := []struct {
+		name     string
+		userId   string
+		tagName  string
+		wantName string
+		wantErr  string
+	}{
+		{name: "trimmed", userId: "alice", tagName: "  errand ", wantName: "errand"},
+		{name: "same name as other user's tag", userId: "alice", tagName: "home", wantName: "home"},
+		{name: "duplicate", userId: "alice", tagName: "work", wantErr: "tag already exists"},
+		{name: "blank", userId: "alice", tagName: "   ", wantErr: "name is empty"},
+		{name: "too long", userId: "alice", tagName: strings.Repeat("あ", domain_tag.MaxTagNameLength+1), wantErr: "name is too long"},
+		{name: "unauthenticated", userId: "", tagName: "work", wantErr: "user_id is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := newTestTagUsecase()
+			tag, err := u.CreateTag(tt.userId, tt.tagName)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("CreateTag error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateTag returned error: %v", err)
+			}
+			if tag.Name != tt.wantName || tag.UserId != tt.userId {
+				t.Errorf("CreateTag = %+v, want name %q for %s", tag, tt.wantName, tt.userId)
+			}
+		})
+	}
+}
+
+// タグでの絞り込みは重複・空のIDを除き、タグが無ければリポジトリを呼ばない
+func TestGetTodoIdsByTagIds(t *testing.T) {
+	u, tgr := newTestTagUsecase()
+
+	ids, err := u.GetTodoIdsByTagIds("alice", []string{"tag-alice", "", "tag-alice", "tag-bob"})
+	if err != nil {
+		t.Fatalf("GetTodoIdsByTagIds returned error: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"todo-1"}) {
+		t.Errorf("ids = %v", ids)
+	}
+	if !reflect.DeepEqual(tgr.tagIds, [][]string{{"tag-alice", "tag-bob"}}) {
+		t.Errorf("repository tag ids = %v, want [[tag-alice tag-bob]]", tgr.tagIds)
+	}
+
+	ids, err = u.GetTodoIdsByTagIds("alice", []string{""})
+	if err != nil || len(ids) != 0 || len(tgr.tagIds) != 1 {
+		t.Errorf("GetTodoIdsByTagIds with no tags = %v, %v (repository calls %d)", ids, err, len(tgr.tagIds))
+	}
+}
@@ -1,50 +1,114 @@
 package usecase_todo
 
 import (
+	domain_audit "backend/internal/domain/audit"
+	domain_share "backend/internal/domain/share"
 	domain_todo "backend/internal/domain/todo"
+	pkg_fracindex "backend/internal/pkg/fracindex"
 	pkg_logger "backend/internal/pkg/logger"
+	repository_share "backend/internal/repository/share"
+	repository_storage "backend/internal/repository/storage"
 	repository_todo "backend/internal/repository/todo"
 	"errors"
+	"time"
 )
 
+// 保持期間を過ぎたTodoを一度に完全削除する件数
+const purgeBatchSize = 100
+
+// 並び順のキーを一度に振り直すユーザーの件数
+const rebalanceBatchSize = 100
+
 // Todoユースケース(IF)
 type ITodoUsecase interface {
-	// 全てのTodoを取得
-	GetAllTodos() ([]domain_todo.Todo, error)
+	// ユーザーが閲覧できる(自分の、または共有された)Todoを取得
+	GetVisibleTodos(userId string) ([]domain_todo.Todo, error)
 	// idを指定してTodoを取得
 	GetTodoById(id string) (domain_todo.Todo, error)
+	// ユーザーがTodoに対してrequired以上の権限を持つことを確認し、Todoを取得
+	AuthorizeTodo(userId string, id string, required string) (domain_todo.Todo, error)
+	// 特定のユーザーに共有されたTodoを取得
+	GetSharedTodos(userId string) ([]domain_todo.Todo, error)
 	// 特定のユーザーのTodoを取得
 	GetTodoByUserId(userId string) ([]domain_todo.Todo, error)
+	// 複数のユーザーのTodoのうち、viewerIdのユーザーが閲覧できるものを取得
+	GetVisibleTodosByUserIds(viewerId string, userIds []string) ([]domain_todo.Todo, error)
+	// 複数のTodoのうち、viewerIdのユーザーが閲覧できるもののIDを取得
+	GetVisibleTodoIds(viewerId string, ids []string) ([]string, error)
+	// 複数のTodoを取得
+	GetTodosByIds(ids []string) ([]domain_todo.Todo, error)
+	// 複数の親Todoのサブタスクを取得
+	GetSubtasksByParentIds(parentIds []string) ([]domain_todo.Todo, error)
+	// 複数のTodoのサブタスクの進捗を取得
+	GetTodoProgressByIds(ids []string) ([]domain_todo.TodoProgress, error)
+	// 特定のユーザーの期限切れのTodoを取得
+	GetOverdueTodos(userId string) ([]domain_todo.Todo, error)
+	// 特定のユーザーの期限が近いTodoを取得
+	GetUpcomingTodos(userId string, within time.Duration) ([]domain_todo.Todo, error)
 	// 新しいTodoを作成
-	CreateTodo(todo domain_todo.Todo) (domain_todo.Todo, error)
+	CreateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error)
 	// Todoを更新
-	UpdateTodo(todo domain_todo.Todo) (domain_todo.Todo, error)
-	// Todoを削除
-	DeleteTodo(id string) error
+	UpdateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// Todoを更新し、未完了のサブタスクを全て完了にする
+	UpdateTodoAndCompleteSubtasks(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// Todoの親を変更(parentIdがnilの場合は最上位)
+	SetTodoParent(userId string, id string, parentId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// Todoの並び順を変更(beforeIdのTodoの直後、afterIdのTodoの直前に移動する)
+	MoveTodo(userId string, id string, beforeId *string, afterId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// Todoを削除(ゴミ箱に移動)
+	DeleteTodo(id string, actor domain_audit.AuditActor) error
+	// 複数のTodoの完了状態を一括で変更
+	BulkUpdateTodos(ids []string, completed bool, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error)
+	// 複数のTodoを一括で削除(ゴミ箱に移動)
+	BulkDeleteTodos(ids []string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error)
+	// 自分の完了済みのTodoを一括で削除(ゴミ箱に移動)
+	ClearCompleted(userId string, listId *string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error)
+	// 特定のユーザーのゴミ箱のTodoを取得
+	GetTrashedTodosByUserId(userId string) ([]domain_todo.Todo, error)
+	// ゴミ箱のTodoを復元
+	RestoreTodo(id string, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+	// ゴミ箱のTodoを完全に削除
+	PurgeTodo(id string, actor domain_audit.AuditActor) error
+	// 保持期間を過ぎたゴミ箱のTodoを完全に削除
+	PurgeExpiredTodos(retention time.Duration) (int, error)
+	// 並び順のキーが長くなったユーザーのキーを振り直す
+	RebalancePositions(maxLength int) (int, error)
 }
 
 // Todoユースケース(Impl)
 type TodoUsecase struct {
-	Logger         *pkg_logger.AppLogger
-	todoRepository repository_todo.ITodoRepository
+	Logger          *pkg_logger.AppLogger
+	todoRepository  repository_todo.ITodoRepository
+	blobStorage     repository_storage.IBlobStorage
+	shareRepository repository_share.ITodoShareRepository
+	now             func() time.Time
 }
 
 // Todoユースケースのインスタンス化
-func NewTodoUsecase(l *pkg_logger.AppLogger, tr repository_todo.ITodoRepository) ITodoUsecase {
+func NewTodoUsecase(l *pkg_logger.AppLogger, tr repository_todo.ITodoRepository, bs repository_storage.IBlobStorage, sr repository_share.ITodoShareRepository) ITodoUsecase {
 	return &TodoUsecase{
-		Logger:         l,
-		todoRepository: tr,
+		Logger:          l,
+		todoRepository:  tr,
+		blobStorage:     bs,
+		shareRepository: sr,
+		now:             time.Now,
 	}
 }
 
-// 全てのTodoを取得
-func (u *TodoUsecase) GetAllTodos() ([]domain_todo.Todo, error) {
-	u.Logger.InfoLog.Println("GetAllTodos called")
+// ユーザーが閲覧できる(自分の、または共有された)Todoを取得
+func (u *TodoUsecase) GetVisibleTodos(userId string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetVisibleTodos called")
 
-	// Todoリポジトリから全てのTodoを取得(repository層)
-	todos, err := u.todoRepository.GetAllTodos()
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Todoリポジトリから閲覧できるTodoを取得(repository層)
+	todos, err := u.todoRepository.GetVisibleTodos(userId)
 	if err != nil {
-		u.Logger.ErrorLog.Printf("Failed to get all todos: %v", err)
+		u.Logger.ErrorLog.Printf("Failed to get visible todos: %v", err)
 		return nil, err
 	}
 
@@ -73,6 +137,58 @@ func (u *TodoUsecase) GetTodoById(id string) (domain_todo.Todo, error) {
 	return todo, nil
 }
 
+// ユーザーがTodoに対してrequired以上の権限を持つことを確認し、Todoを取得
+func (u *TodoUsecase) AuthorizeTodo(userId string, id string, required string) (domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("AuthorizeTodo called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_todo.Todo{}, errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("id is empty")
+		return domain_todo.Todo{}, errors.New("id is empty")
+	}
+
+	// Todoリポジトリから指定されたidのTodoを取得(repository層)
+	todo, err := u.todoRepository.GetTodoById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo: %v", err)
+		return domain_todo.Todo{}, errors.New("todo not found")
+	}
+
+	// 権限チェック
+	err = u.checkTodoRole(userId, todo, required)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Authorized todo: %v", todo.ID)
+	return todo, nil
+}
+
+// 特定のユーザーに共有されたTodoを取得
+func (u *TodoUsecase) GetSharedTodos(userId string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetSharedTodos called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Todoリポジトリから共有されたTodoを取得(repository層)
+	todos, err := u.todoRepository.GetSharedTodosByMemberId(userId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get shared todos: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d shared todos", len(todos))
+	return todos, nil
+}
+
 // 特定のユーザーのTodoを取得
 func (u *TodoUsecase) GetTodoByUserId(userId string) ([]domain_todo.Todo, error) {
 	u.Logger.InfoLog.Println("GetTodoByUserId called")
@@ -94,8 +210,155 @@ func (u *TodoUsecase) GetTodoByUserId(userId string) ([]domain_todo.Todo, error)
 	return todos, nil
 }
 
+// 複数のユーザーのTodoのうち、viewerIdのユーザーが閲覧できるものを取得
+func (u *TodoUsecase) GetVisibleTodosByUserIds(viewerId string, userIds []string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetVisibleTodosByUserIds called")
+
+	// バリデーション(未認証の場合は何も閲覧できない)
+	if viewerId == "" || len(userIds) == 0 {
+		return []domain_todo.Todo{}, nil
+	}
+
+	// Todoリポジトリから閲覧できるTodoを取得(repository層)
+	todos, err := u.todoRepository.GetVisibleTodosByUserIds(viewerId, userIds)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todos by user_ids: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d todos", len(todos))
+	return todos, nil
+}
+
+// 複数のTodoのうち、viewerIdのユーザーが閲覧できるもののIDを取得
+func (u *TodoUsecase) GetVisibleTodoIds(viewerId string, ids []string) ([]string, error) {
+	u.Logger.InfoLog.Println("GetVisibleTodoIds called")
+
+	// バリデーション(未認証の場合は何も閲覧できない)
+	if viewerId == "" || len(ids) == 0 {
+		return []string{}, nil
+	}
+
+	// Todoリポジトリから閲覧できるTodoのIDを取得(repository層)
+	visibleIds, err := u.todoRepository.GetVisibleTodoIds(viewerId, ids)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get visible todo ids: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d visible todo ids", len(visibleIds))
+	return visibleIds, nil
+}
+
+// 複数のTodoを取得
+func (u *TodoUsecase) GetTodosByIds(ids []string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetTodosByIds called")
+
+	// バリデーション
+	if len(ids) == 0 {
+		return []domain_todo.Todo{}, nil
+	}
+
+	// Todoリポジトリから複数のTodoを取得(repository層)
+	todos, err := u.todoRepository.GetTodosByIds(ids)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todos by ids: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d todos", len(todos))
+	return todos, nil
+}
+
+// 複数の親Todoのサブタスクを取得
+func (u *TodoUsecase) GetSubtasksByParentIds(parentIds []string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetSubtasksByParentIds called")
+
+	// バリデーション
+	if len(parentIds) == 0 {
+		return []domain_todo.Todo{}, nil
+	}
+
+	// Todoリポジトリからサブタスクを取得(repository層)
+	todos, err := u.todoRepository.GetSubtasksByParentIds(parentIds)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get subtasks: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d subtasks", len(todos))
+	return todos, nil
+}
+
+// 複数のTodoのサブタスクの進捗を取得
+func (u *TodoUsecase) GetTodoProgressByIds(ids []string) ([]domain_todo.TodoProgress, error) {
+	u.Logger.InfoLog.Println("GetTodoProgressByIds called")
+
+	// バリデーション
+	if len(ids) == 0 {
+		return []domain_todo.TodoProgress{}, nil
+	}
+
+	// Todoリポジトリから進捗を取得(repository層)
+	progress, err := u.todoRepository.GetTodoProgressByIds(ids)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo progress: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched progress of %d todos", len(progress))
+	return progress, nil
+}
+
+// 特定のユーザーの期限切れのTodoを取得
+func (u *TodoUsecase) GetOverdueTodos(userId string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetOverdueTodos called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Todoリポジトリから期限切れのTodoを取得(repository層)
+	todos, err := u.todoRepository.GetOverdueTodosByUserId(userId, u.now())
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get overdue todos: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d overdue todos", len(todos))
+	return todos, nil
+}
+
+// 特定のユーザーの期限が近いTodoを取得
+func (u *TodoUsecase) GetUpcomingTodos(userId string, within time.Duration) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetUpcomingTodos called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+	if within <= 0 {
+		u.Logger.ErrorLog.Println("period must be positive")
+		return nil, errors.New("period must be positive")
+	}
+
+	// Todoリポジトリから期限が近いTodoを取得(repository層)
+	now := u.now()
+	todos, err := u.todoRepository.GetUpcomingTodosByUserId(userId, now, now.Add(within))
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get upcoming todos: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d upcoming todos", len(todos))
+	return todos, nil
+}
+
 // 新しいTodoを作成
-func (u *TodoUsecase) CreateTodo(todo domain_todo.Todo) (domain_todo.Todo, error) {
+func (u *TodoUsecase) CreateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
 	u.Logger.InfoLog.Println("CreateTodo called")
 
 	// バリデーション
@@ -108,8 +371,50 @@ func (u *TodoUsecase) CreateTodo(todo domain_todo.Todo) (domain_todo.Todo, error
 		return domain_todo.Todo{}, errors.New("user_id is empty")
 	}
 
+	if todo.Priority == "" {
+		todo.Priority = domain_todo.TodoPriorityMedium
+	}
+	if !domain_todo.IsValidPriority(todo.Priority) {
+		u.Logger.ErrorLog.Printf("Invalid priority: %s", todo.Priority)
+		return domain_todo.Todo{}, errors.New("invalid priority")
+	}
+
+	// サブタスクの場合は親を検証し、親の所有者のTodoとして親と同じリストに作成する
+	if todo.ParentId != nil {
+		parent, err := u.validateParent(todo.UserId, "", *todo.ParentId)
+		if err != nil {
+			return domain_todo.Todo{}, err
+		}
+		todo.UserId = parent.UserId
+		todo.ListId = parent.ListId
+	}
+
+	// 並び順のキーを指定しない場合は、所有者のTodoの末尾に並べる
+	if todo.Position != nil {
+		if err := pkg_fracindex.Validate(*todo.Position); err != nil {
+			u.Logger.ErrorLog.Printf("Invalid position: %v", err)
+			return domain_todo.Todo{}, errors.New("invalid position")
+		}
+	} else {
+		last, err := u.todoRepository.GetLastTodoPosition(todo.UserId, "")
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to get last todo position: %v", err)
+			return domain_todo.Todo{}, err
+		}
+		lower := ""
+		if last != nil {
+			lower = *last
+		}
+		position, err := pkg_fracindex.KeyBetween(lower, "")
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to generate position: %v", err)
+			return domain_todo.Todo{}, err
+		}
+		todo.Position = &position
+	}
+
 	// Todoリポジトリから新しいTodoを作成(repository層)
-	createdTodo, err := u.todoRepository.CreateTodo(todo)
+	createdTodo, err := u.todoRepository.CreateTodo(todo, actor)
 	if err != nil {
 		u.Logger.ErrorLog.Printf("Failed to create todo: %v", err)
 		return domain_todo.Todo{}, err
@@ -120,7 +425,7 @@ func (u *TodoUsecase) CreateTodo(todo domain_todo.Todo) (domain_todo.Todo, error
 }
 
 // Todoを更新
-func (u *TodoUsecase) UpdateTodo(todo domain_todo.Todo) (domain_todo.Todo, error) {
+func (u *TodoUsecase) UpdateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
 	u.Logger.InfoLog.Println("UpdateTodo called")
 
 	// バリデーション
@@ -137,8 +442,23 @@ func (u *TodoUsecase) UpdateTodo(todo domain_todo.Todo) (domain_todo.Todo, error
 		return domain_todo.Todo{}, errors.New("user_id is empty")
 	}
 
+	if todo.Priority == "" {
+		todo.Priority = domain_todo.TodoPriorityMedium
+	}
+	if !domain_todo.IsValidPriority(todo.Priority) {
+		u.Logger.ErrorLog.Printf("Invalid priority: %s", todo.Priority)
+		return domain_todo.Todo{}, errors.New("invalid priority")
+	}
+
+	// 権限チェック(共有メンバーが更新しても所有者は変更しない)
+	current, err := u.AuthorizeTodo(actor.UserId, todo.ID, domain_share.ShareRoleEditor)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+	todo.UserId = current.UserId
+
 	// Todoリポジトリから指定されたidのTodoを更新(repository層)
-	updatedTodo, err := u.todoRepository.UpdateTodo(todo)
+	updatedTodo, err := u.todoRepository.UpdateTodo(todo, actor)
 	if err != nil {
 		u.Logger.ErrorLog.Printf("Failed to update todo: %v", err)
 		return domain_todo.Todo{}, err
@@ -148,8 +468,285 @@ func (u *TodoUsecase) UpdateTodo(todo domain_todo.Todo) (domain_todo.Todo, error
 	return updatedTodo, nil
 }
 
-// Todoを削除
-func (u *TodoUsecase) DeleteTodo(id string) error {
+// Todoを更新し、未完了のサブタスクを全て完了にする
+func (u *TodoUsecase) UpdateTodoAndCompleteSubtasks(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("UpdateTodoAndCompleteSubtasks called")
+
+	// バリデーション
+	if todo.ID == "" {
+		u.Logger.ErrorLog.Println("id is empty")
+		return domain_todo.Todo{}, errors.New("id is empty")
+	}
+	if todo.Description == "" {
+		u.Logger.ErrorLog.Println("description is empty")
+		return domain_todo.Todo{}, errors.New("description is empty")
+	}
+	if todo.UserId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_todo.Todo{}, errors.New("user_id is empty")
+	}
+	if !todo.Completed {
+		u.Logger.ErrorLog.Println("todo must be completed to complete subtasks")
+		return domain_todo.Todo{}, errors.New("todo must be completed to complete subtasks")
+	}
+
+	if todo.Priority == "" {
+		todo.Priority = domain_todo.TodoPriorityMedium
+	}
+	if !domain_todo.IsValidPriority(todo.Priority) {
+		u.Logger.ErrorLog.Printf("Invalid priority: %s", todo.Priority)
+		return domain_todo.Todo{}, errors.New("invalid priority")
+	}
+
+	// 権限チェック(共有メンバーが更新しても所有者は変更しない)
+	current, err := u.AuthorizeTodo(actor.UserId, todo.ID, domain_share.ShareRoleEditor)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+	todo.UserId = current.UserId
+
+	// Todoリポジトリから更新し、サブタスクを完了にする(repository層)
+	updatedTodo, completed, err := u.todoRepository.UpdateTodoAndCompleteSubtasks(todo, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to update todo and subtasks: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Updated todo: %v (%d subtasks completed)", updatedTodo, completed)
+	return updatedTodo, nil
+}
+
+// Todoの親を変更(parentIdがnilの場合は最上位)
+func (u *TodoUsecase) SetTodoParent(userId string, id string, parentId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("SetTodoParent called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_todo.Todo{}, errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("id is empty")
+		return domain_todo.Todo{}, errors.New("id is empty")
+	}
+
+	// 権限チェック
+	todo, err := u.AuthorizeTodo(userId, id, domain_share.ShareRoleEditor)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// 親の検証(循環・深さ)、親は同じ所有者のTodoに限る
+	if parentId != nil {
+		parent, err := u.validateParent(userId, id, *parentId)
+		if err != nil {
+			return domain_todo.Todo{}, err
+		}
+		if parent.UserId != todo.UserId {
+			u.Logger.ErrorLog.Println("forbidden")
+			return domain_todo.Todo{}, errors.New("forbidden")
+		}
+	}
+
+	// Todoリポジトリから親を変更(repository層)
+	updatedTodo, err := u.todoRepository.SetTodoParent(id, parentId, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to set todo parent: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Set todo parent: %v", updatedTodo)
+	return updatedTodo, nil
+}
+
+// 親Todoの検証
+// 親を編集する権限があること、子(idが空の場合は新規作成)を付け替えても循環せず、
+// 階層の深さが上限を超えないことを確認する。
+func (u *TodoUsecase) validateParent(userId string, id string, parentId string) (domain_todo.Todo, error) {
+	if parentId == "" {
+		u.Logger.ErrorLog.Println("parent_id is empty")
+		return domain_todo.Todo{}, errors.New("parent_id is empty")
+	}
+	if parentId == id {
+		u.Logger.ErrorLog.Println("parent would create a cycle")
+		return domain_todo.Todo{}, errors.New("parent would create a cycle")
+	}
+
+	parent, err := u.todoRepository.GetTodoById(parentId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get parent todo: %v", err)
+		return domain_todo.Todo{}, errors.New("parent not found")
+	}
+	err = u.checkTodoRole(userId, parent, domain_share.ShareRoleEditor)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// 親の祖先に自分が含まれる場合は循環する
+	ancestorIds, err := u.todoRepository.GetTodoAncestorIds(parentId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get ancestors: %v", err)
+		return domain_todo.Todo{}, err
+	}
+	for _, ancestorId := range ancestorIds {
+		if ancestorId == id {
+			u.Logger.ErrorLog.Println("parent would create a cycle")
+			return domain_todo.Todo{}, errors.New("parent would create a cycle")
+		}
+	}
+
+	// 親の深さ + 付け替える部分木の高さが上限を超えないこと
+	height := 1
+	if id != "" {
+		height, err = u.todoRepository.GetSubtreeHeight(id)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to get subtree height: %v", err)
+			return domain_todo.Todo{}, err
+		}
+	}
+	parentDepth := len(ancestorIds) + 1
+	if parentDepth+height > domain_todo.MaxTodoDepth {
+		u.Logger.ErrorLog.Printf("Max depth exceeded: %d", parentDepth+height)
+		return domain_todo.Todo{}, errors.New("max depth exceeded")
+	}
+
+	return parent, nil
+}
+
+// Todoの並び順を変更(beforeIdのTodoの直後、afterIdのTodoの直前に移動する)
+// 片方のみ指定した場合はそのTodoの隣に、両方省略した場合は末尾に移動する。
+// 並び順は所有者のTodo全体で1つのため、隣のTodoは同じ所有者のTodoに限る。更新するのは移動するTodoの1行のみ。
+func (u *TodoUsecase) MoveTodo(userId string, id string, beforeId *string, afterId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("MoveTodo called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_todo.Todo{}, errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("id is empty")
+		return domain_todo.Todo{}, errors.New("id is empty")
+	}
+	if (beforeId != nil && *beforeId == id) || (afterId != nil && *afterId == id) {
+		u.Logger.ErrorLog.Println("invalid position")
+		return domain_todo.Todo{}, errors.New("invalid position")
+	}
+
+	// 権限チェック
+	todo, err := u.AuthorizeTodo(userId, id, domain_share.ShareRoleEditor)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// 隣のTodoのキーが未設定・重複している場合は、キーを振り直してからやり直す
+	position, ok, err := u.positionBetween(todo.UserId, id, beforeId, afterId)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+	if !ok {
+		_, err = u.todoRepository.RebalanceTodoPositions(todo.UserId)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to rebalance todo positions: %v", err)
+			return domain_todo.Todo{}, err
+		}
+		position, ok, err = u.positionBetween(todo.UserId, id, beforeId, afterId)
+		if err != nil {
+			return domain_todo.Todo{}, err
+		}
+		if !ok {
+			// 振り直した後も順序が逆の場合は、指定が誤っている
+			u.Logger.ErrorLog.Println("invalid position")
+			return domain_todo.Todo{}, errors.New("invalid position")
+		}
+	}
+
+	// Todoリポジトリから並び順を変更(repository層)
+	movedTodo, err := u.todoRepository.UpdateTodoPosition(id, position, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to move todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Moved todo: %v", movedTodo)
+	return movedTodo, nil
+}
+
+// 隣のTodoのキーの間のキーを生成
+// 隣のTodoのキーが未設定、または順序が逆(重複を含む)の場合はokをfalseで返す。
+func (u *TodoUsecase) positionBetween(ownerId string, id string, beforeId *string, afterId *string) (string, bool, error) {
+	lower, upper := "", ""
+	if beforeId != nil {
+		before, err := u.neighborPosition(ownerId, *beforeId)
+		if err != nil {
+			return "", false, err
+		}
+		if before == nil {
+			return "", false, nil
+		}
+		lower = *before
+	}
+	if afterId != nil {
+		after, err := u.neighborPosition(ownerId, *afterId)
+		if err != nil {
+			return "", false, err
+		}
+		if after == nil {
+			return "", false, nil
+		}
+		upper = *after
+	}
+
+	// 片方のみ指定した場合は、もう片方の隣のキーを境界にする
+	var adjacent *string
+	var err error
+	switch {
+	case beforeId != nil && afterId == nil:
+		adjacent, err = u.todoRepository.GetAdjacentTodoPosition(ownerId, lower, true, id)
+		if adjacent != nil {
+			upper = *adjacent
+		}
+	case beforeId == nil && afterId != nil:
+		adjacent, err = u.todoRepository.GetAdjacentTodoPosition(ownerId, upper, false, id)
+		if adjacent != nil {
+			lower = *adjacent
+		}
+	case beforeId == nil && afterId == nil:
+		adjacent, err = u.todoRepository.GetLastTodoPosition(ownerId, id)
+		if adjacent != nil {
+			lower = *adjacent
+		}
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get adjacent todo position: %v", err)
+		return "", false, err
+	}
+
+	position, err := pkg_fracindex.KeyBetween(lower, upper)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to generate position: %v", err)
+		return "", false, nil
+	}
+	return position, true, nil
+}
+
+// 隣に指定されたTodoのキーを取得(キーが未設定の場合はnil)
+// 隣のTodoは移動するTodoと同じ所有者のTodoに限る。
+func (u *TodoUsecase) neighborPosition(ownerId string, id string) (*string, error) {
+	neighbor, err := u.todoRepository.GetTodoById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get neighbor todo: %v", err)
+		return nil, errors.New("invalid position")
+	}
+	if neighbor.UserId != ownerId {
+		u.Logger.ErrorLog.Println("invalid position")
+		return nil, errors.New("invalid position")
+	}
+	return neighbor.Position, nil
+}
+
+// Todoを削除(ゴミ箱に移動)
+func (u *TodoUsecase) DeleteTodo(id string, actor domain_audit.AuditActor) error {
 	u.Logger.InfoLog.Println("DeleteTodo called")
 
 	// バリデーション
@@ -158,8 +755,14 @@ func (u *TodoUsecase) DeleteTodo(id string) error {
 		return errors.New("id is empty")
 	}
 
+	// 権限チェック(削除は所有者権限が必要)
+	_, err := u.AuthorizeTodo(actor.UserId, id, domain_share.ShareRoleOwner)
+	if err != nil {
+		return err
+	}
+
 	// Todoリポジトリから指定されたidのTodoを削除(repository層)
-	err := u.todoRepository.DeleteTodo(id)
+	err = u.todoRepository.DeleteTodo(id, actor)
 	if err != nil {
 		u.Logger.ErrorLog.Printf("Failed to delete todo: %v", err)
 		return err
@@ -168,3 +771,295 @@ func (u *TodoUsecase) DeleteTodo(id string) error {
 	u.Logger.InfoLog.Printf("Deleted todo: %v", id)
 	return nil
 }
+
+// 複数のTodoの完了状態を一括で変更(1件ごとに編集者以上の権限を確認する)
+func (u *TodoUsecase) BulkUpdateTodos(ids []string, completed bool, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error) {
+	u.Logger.InfoLog.Println("BulkUpdateTodos called")
+
+	// バリデーション
+	ids, err := u.validateBulkIds(ids, actor.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Todoリポジトリから権限を確認して一括で変更(repository層)
+	results, err := u.todoRepository.BulkSetCompleted(ids, completed, domain_share.ShareRoleEditor, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to bulk update todos: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Bulk updated %d todos", len(results))
+	return results, nil
+}
+
+// 複数のTodoを一括で削除(ゴミ箱に移動、1件ごとに所有者権限を確認する)
+func (u *TodoUsecase) BulkDeleteTodos(ids []string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error) {
+	u.Logger.InfoLog.Println("BulkDeleteTodos called")
+
+	// バリデーション
+	ids, err := u.validateBulkIds(ids, actor.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Todoリポジトリから権限を確認して一括で削除(repository層)
+	results, err := u.todoRepository.BulkDeleteTodos(ids, domain_share.ShareRoleOwner, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to bulk delete todos: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Bulk deleted %d todos", len(results))
+	return results, nil
+}
+
+// 自分の完了済みのTodoを一括で削除(ゴミ箱に移動、listIdがnilの場合は全てのリスト)
+func (u *TodoUsecase) ClearCompleted(userId string, listId *string, actor domain_audit.AuditActor) ([]domain_todo.TodoBulkResult, error) {
+	u.Logger.InfoLog.Println("ClearCompleted called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Todoリポジトリから完了済みのTodoを削除(repository層)
+	todos, err := u.todoRepository.ClearCompletedTodos(userId, listId, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to clear completed todos: %v", err)
+		return nil, err
+	}
+
+	results := make([]domain_todo.TodoBulkResult, 0, len(todos))
+	for i := range todos {
+		results = append(results, domain_todo.TodoBulkResult{ID: todos[i].ID, Success: true, Changed: true, Todo: &todos[i]})
+	}
+
+	u.Logger.InfoLog.Printf("Cleared %d completed todos", len(results))
+	return results, nil
+}
+
+// 一括操作のIDの検証(重複は取り除く)
+func (u *TodoUsecase) validateBulkIds(ids []string, userId string) ([]string, error) {
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+	if len(ids) == 0 {
+		u.Logger.ErrorLog.Println("ids is empty")
+		return nil, errors.New("ids is empty")
+	}
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) > domain_todo.MaxBulkTodoIds {
+		u.Logger.ErrorLog.Printf("Too many ids: %d", len(unique))
+		return nil, errors.New("too many ids")
+	}
+	return unique, nil
+}
+
+// 特定のユーザーのゴミ箱のTodoを取得
+func (u *TodoUsecase) GetTrashedTodosByUserId(userId string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetTrashedTodosByUserId called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Todoリポジトリからゴミ箱のTodoを取得(repository層)
+	todos, err := u.todoRepository.GetTrashedTodosByUserId(userId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get trashed todos: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d trashed todos", len(todos))
+	return todos, nil
+}
+
+// ゴミ箱のTodoを復元
+func (u *TodoUsecase) RestoreTodo(id string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("RestoreTodo called")
+
+	// バリデーション
+	if id == "" {
+		u.Logger.ErrorLog.Println("id is empty")
+		return domain_todo.Todo{}, errors.New("id is empty")
+	}
+
+	// 所有者チェック
+	err := u.checkTrashedTodoOwner(id, actor.UserId)
+	if err != nil {
+		return domain_todo.Todo{}, err
+	}
+
+	// Todoリポジトリから指定されたidのTodoを復元(repository層)
+	todo, err := u.todoRepository.RestoreTodo(id, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to restore todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Restored todo: %v", todo)
+	return todo, nil
+}
+
+// ゴミ箱のTodoを完全に削除
+func (u *TodoUsecase) PurgeTodo(id string, actor domain_audit.AuditActor) error {
+	u.Logger.InfoLog.Println("PurgeTodo called")
+
+	// バリデーション
+	if id == "" {
+		u.Logger.ErrorLog.Println("id is empty")
+		return errors.New("id is empty")
+	}
+
+	// 所有者チェック
+	err := u.checkTrashedTodoOwner(id, actor.UserId)
+	if err != nil {
+		return err
+	}
+
+	err = u.purge(id, actor)
+	if err != nil {
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Purged todo: %v", id)
+	return nil
+}
+
+// 保持期間を過ぎたゴミ箱のTodoを完全に削除
+func (u *TodoUsecase) PurgeExpiredTodos(retention time.Duration) (int, error) {
+	u.Logger.InfoLog.Println("PurgeExpiredTodos called")
+
+	// バリデーション
+	if retention < 0 {
+		u.Logger.ErrorLog.Println("retention must not be negative")
+		return 0, errors.New("retention must not be negative")
+	}
+
+	before := u.now().Add(-retention)
+	actor := domain_audit.AuditActor{UserId: domain_audit.SystemActorId}
+	purged := 0
+	for {
+		// Todoリポジトリから保持期間を過ぎたTodoを取得(repository層)
+		ids, err := u.todoRepository.GetTrashedTodoIdsBefore(before, purgeBatchSize)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to get expired trashed todos: %v", err)
+			return purged, err
+		}
+
+		for _, id := range ids {
+			err := u.purge(id, actor)
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	u.Logger.InfoLog.Printf("Purged %d expired todos", purged)
+	return purged, nil
+}
+
+// 並び順のキーが長くなったユーザーのキーを振り直し、振り直したユーザーの件数を返す
+// キーが未設定のTodoを持つユーザーも対象にする。1回の実行で振り直すのはrebalanceBatchSize人までとする。
+func (u *TodoUsecase) RebalancePositions(maxLength int) (int, error) {
+	u.Logger.InfoLog.Println("RebalancePositions called")
+
+	// バリデーション
+	if maxLength <= 0 {
+		u.Logger.ErrorLog.Println("max length must be positive")
+		return 0, errors.New("max length must be positive")
+	}
+
+	// Todoリポジトリから振り直しが必要なユーザーを取得(repository層)
+	userIds, err := u.todoRepository.GetUserIdsNeedingRebalance(maxLength, rebalanceBatchSize)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get users needing rebalance: %v", err)
+		return 0, err
+	}
+
+	rebalanced := 0
+	for _, userId := range userIds {
+		_, err := u.todoRepository.RebalanceTodoPositions(userId)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to rebalance todo positions: %v", err)
+			return rebalanced, err
+		}
+		rebalanced++
+	}
+
+	u.Logger.InfoLog.Printf("Rebalanced todo positions of %d users", rebalanced)
+	return rebalanced, nil
+}
+
+// ゴミ箱のTodoの所有者チェック(所有者権限で共有されたメンバーも含む)
+func (u *TodoUsecase) checkTrashedTodoOwner(id string, userId string) error {
+	todo, err := u.todoRepository.GetTrashedTodoById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get trashed todo: %v", err)
+		return errors.New("todo not found in trash")
+	}
+	return u.checkTodoRole(userId, todo, domain_share.ShareRoleOwner)
+}
+
+// ユーザーがTodoに対してrequired以上の権限を持つことを確認
+// 所有者は常に所有者権限を持つ。共有メンバーの権限は、Todo自身・祖先のTodo・所有者の全てのTodoへの共有のうち最も強いものとする。
+func (u *TodoUsecase) checkTodoRole(userId string, todo domain_todo.Todo, required string) error {
+	if todo.UserId == userId {
+		return nil
+	}
+
+	ancestorIds, err := u.todoRepository.GetTodoAncestorIds(todo.ID)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get ancestors: %v", err)
+		return err
+	}
+	roles, err := u.shareRepository.GetMemberRoles(userId, todo.UserId, append([]string{todo.ID}, ancestorIds...))
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get member roles: %v", err)
+		return err
+	}
+
+	role := domain_share.HighestRole(roles)
+	if role == "" || !domain_share.RoleAtLeast(role, required) {
+		u.Logger.ErrorLog.Printf("forbidden: role=%q required=%q", role, required)
+		return errors.New("forbidden")
+	}
+	return nil
+}
+
+// Todoを完全に削除し、添付ファイルをストレージから削除
+func (u *TodoUsecase) purge(id string, actor domain_audit.AuditActor) error {
+	// Todoリポジトリから指定されたidのTodoを完全に削除(repository層)
+	storageKeys, err := u.todoRepository.PurgeTodo(id, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to purge todo: %v", err)
+		return err
+	}
+
+	// ストレージから削除(失敗してもメタデータは削除済みのため、ログのみ出力する)
+	for _, key := range storageKeys {
+		if err := u.blobStorage.Delete(key); err != nil {
+			u.Logger.ErrorLog.Printf("Failed to delete file: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,335 @@
+package usecase_todo
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_share "backend/internal/domain/share"
+	domain_todo "backend/internal/domain/todo"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_share "backend/internal/repository/share"
+	repository_storage "backend/internal/repository/storage"
+	repository_todo "backend/internal/repository/todo"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// ゴミ箱のTodoをメモリ上に保持するTodoリポジトリ
+type fakeTodoRepository struct {
+	repository_todo.ITodoRepository
+	trashed     map[string]domain_todo.Todo
+	storageKeys map[string][]string
+	restored    []string
+	purged      []string
+	befores     []time.Time
+	windows     [][2]time.Time
+	created     []domain_todo.Todo
+}
+
+func (r *fakeTodoRepository) GetTrashedTodoById(id string) (domain_todo.Todo, error) {
+	todo, ok := r.trashed[id]
+	if !ok {
+		return domain_todo.Todo{}, errors.New("todo not found")
+	}
+	return todo, nil
+}
+
+func (r *fakeTodoRepository) GetTodoAncestorIds(id string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeTodoRepository) RestoreTodo(id string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	todo := r.trashed[id]
+	delete(r.trashed, id)
+	todo.DeletedAt = nil
+	r.restored = append(r.restored, id)
+	return todo, nil
+}
+
+func (r *fakeTodoRepository) PurgeTodo(id string, actor domain_audit.AuditActor) ([]string, error) {
+	delete(r.trashed, id)
+	r.purged = append(r.purged, id)
+	return r.storageKeys[id], nil
+}
+
+// before以前にゴミ箱に移動したTodoを、IDの昇順に最大limit件返す
+func (r *fakeTodoRepository) GetTrashedTodoIdsBefore(before time.Time, limit int) ([]string, error) {
+	r.befores = append(r.befores, before)
+	ids := []string{}
+	for id, todo := range r.trashed {
+		if !todo.DeletedAt.After(before) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (r *fakeTodoRepository) GetOverdueTodosByUserId(userId string, now time.Time) ([]domain_todo.Todo, error) {
+	r.windows = append(r.windows, [2]time.Time{{}, now})
+	return nil, nil
+}
+
+func (r *fakeTodoRepository) GetUpcomingTodosByUserId(userId string, from time.Time, to time.Time) ([]domain_todo.Todo, error) {
+	r.windows = append(r.windows, [2]time.Time{from, to})
+	return nil, nil
+}
+
+func (r *fakeTodoRepository) GetLastTodoPosition(userId string, excludeId string) (*string, error) {
+	return nil, nil
+}
+
+func (r *fakeTodoRepository) CreateTodo(todo domain_todo.Todo, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	r.created = append(r.created, todo)
+	return todo, nil
+}
+
+// 共有メンバーの権限を返す共有リポジトリ
+type fakeShareRepository struct {
+	repository_share.ITodoShareRepository
+	roles map[string]string
+}
+
+func (r *fakeShareRepository) GetMemberRoles(memberId string, ownerId string, todoIds []string) ([]string, error) {
+	role, ok := r.roles[memberId]
+	if !ok {
+		return nil, nil
+	}
+	return []string{role}, nil
+}
+
+// 削除したキーを記録するBlobストレージ
+type fakeBlobStorage struct {
+	repository_storage.IBlobStorage
+	deleted []string
+	failKey string
+}
+
+func (s *fakeBlobStorage) Delete(key string) error {
+	if key == s.failKey {
+		return errors.New("storage unavailable")
+	}
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func newTestTodoUsecase(trashed map[string]domain_todo.Todo, roles map[string]string) (*TodoUsecase, *fakeTodoRepository, *fakeBlobStorage) {
+	tr := &fakeTodoRepository{trashed: trashed, storageKeys: map[string][]string{}}
+	bs := &fakeBlobStorage{}
+	u := NewTodoUsecase(newTestLogger(), tr, bs, &fakeShareRepository{roles: roles}).(*TodoUsecase)
+	return u, tr, bs
+}
+
+// 復元と完全削除は所有者(所有者権限で共有されたメンバーを含む)のみ
+func TestRestoreAndPurgeRequireOwner(t *testing.T) {
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	roles := map[string]string{
+		"co-owner": domain_share.ShareRoleOwner,
+		"editor":   domain_share.ShareRoleEditor,
+		"viewer":   domain_share.ShareRoleViewer,
+	}
+
+	tests := []struct {
+		name    string
+		userId  string
+		id      string
+		wantErr string
+	}{
+		{name: "owner", userId: "owner", id: "todo-000"},
+		{name: "shared owner", userId: "co-owner", id: "todo-000"},
+		{name: "editor", userId: "editor", id: "todo-000", wantErr: "forbidden"},
+		{name: "viewer", userId: "viewer", id: "todo-000", wantErr: "forbidden"},
+		{name: "stranger", userId: "stranger", id: "todo-000", wantErr: "forbidden"},
+		{name: "not in trash", userId: "owner", id: "todo-999", wantErr: "todo not found in trash"},
+		{name: "empty id", userId: "owner", id: "", wantErr: "id is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run("restore/"+tt.name, func(t *testing.T) {
+			u, tr, _ := newTestTodoUsecase(map[string]domain_todo.Todo{
+				"todo-000": {ID: "todo-000", UserId: "owner", DeletedAt: &deletedAt},
+			}, roles)
+
+			todo, err := u.RestoreTodo(tt.id, domain_audit.AuditActor{UserId: tt.userId})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("RestoreTodo error = %v, want %q", err, tt.wantErr)
+				}
+				if len(tr.restored) != 0 {
+					t.Errorf("restored = %v, want none", tr.restored)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RestoreTodo returned error: %v", err)
+			}
+			if todo.ID != tt.id || todo.DeletedAt != nil {
+				t.Errorf("RestoreTodo = %+v, want %s restored", todo, tt.id)
+			}
+		})
+
+		t.Run("purge/"+tt.name, func(t *testing.T) {
+			u, tr, _ := newTestTodoUsecase(map[string]domain_todo.Todo{
+				"todo-000": {ID: "todo-000", UserId: "owner", DeletedAt: &deletedAt},
+			}, roles)
+
+			err := u.PurgeTodo(tt.id, domain_audit.AuditActor{UserId: tt.userId})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("PurgeTodo error = %v, want %q", err, tt.wantErr)
+				}
+				if len(tr.purged) != 0 {
+					t.Errorf("purged = %v, want none", tr.purged)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PurgeTodo returned error: %v", err)
+			}
+			if !reflect.DeepEqual(tr.purged, []string{tt.id}) {
+				t.Errorf("purged = %v, want [%s]", tr.purged, tt.id)
+			}
+		})
+	}
+}
+
+// 完全削除すると添付ファイルをストレージから削除し、ストレージの失敗は無視する
+func TestPurgeTodoDeletesStoredFiles(t *testing.T) {
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	u, tr, bs := newTestTodoUsecase(map[string]domain_todo.Todo{
+		"todo-000": {ID: "todo-000", UserId: "owner", DeletedAt: &deletedAt},
+	}, nil)
+	tr.storageKeys["todo-000"] = []string{"a.png", "broken.pdf", "c.txt"}
+	bs.failKey = "broken.pdf"
+
+	err := u.PurgeTodo("todo-000", domain_audit.AuditActor{UserId: "owner"})
+	if err != nil {
+		t.Fatalf("PurgeTodo returned error: %v", err)
+	}
+	if !reflect.DeepEqual(bs.deleted, []string{"a.png", "c.txt"}) {
+		t.Errorf("deleted files = %v, want [a.png c.txt]", bs.deleted)
+	}
+}
+
+// 保持期間を過ぎたTodoだけを、バッチに分けて全件完全削除する
+func TestPurgeExpiredTodos(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+	expired := now.Add(-retention - time.Hour)
+	recent := now.Add(-time.Hour)
+
+	trashed := map[string]domain_todo.Todo{}
+	wantPurged := []string{}
+	for i := 0; i < purgeBatchSize+5; i++ {
+		id := fmt.Sprintf("todo-%03d", i)
+		trashed[id] = domain_todo.Todo{ID: id, UserId: "owner", DeletedAt: &expired}
+		wantPurged = append(wantPurged, id)
+	}
+	trashed["todo-500"] = domain_todo.Todo{ID: "todo-500", UserId: "owner", DeletedAt: &recent}
+
+	u, tr, _ := newTestTodoUsecase(trashed, nil)
+	u.now = func() time.Time { return now }
+
+	purged, err := u.PurgeExpiredTodos(retention)
+	if err != nil {
+		t.Fatalf("PurgeExpiredTodos returned error: %v", err)
+	}
+	if purged != len(wantPurged) {
+		t.Errorf("purged count = %d, want %d", purged, len(wantPurged))
+	}
+	if !reflect.DeepEqual(tr.purged, wantPurged) {
+		t.Errorf("purged = %v, want %d expired todos", tr.purged, len(wantPurged))
+	}
+	if _, ok := tr.trashed["todo-500"]; !ok {
+		t.Error("todo trashed within the retention was purged")
+	}
+	for _, before := range tr.befores {
+		if !before.Equal(now.Add(-retention)) {
+			t.Errorf("before = %v, want %v", before, now.Add(-retention))
+		}
+	}
+	if len(tr.befores) != 2 {
+		t.Errorf("batches = %d, want 2", len(tr.befores))
+	}
+
+	_, err = u.PurgeExpiredTodos(-time.Hour)
+	if err == nil || err.Error() != "retention must not be negative" {
+		t.Errorf("negative retention error = %v", err)
+	}
+}
+
+// 期限切れ・期限が近いTodoは現在時刻を基準に取得する
+func TestDueTodoWindows(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	u, tr, _ := newTestTodoUsecase(nil, nil)
+	u.now = func() time.Time { return now }
+
+	if _, err := u.GetOverdueTodos("owner"); err != nil {
+		t.Fatalf("GetOverdueTodos returned error: %v", err)
+	}
+	if _, err := u.GetUpcomingTodos("owner", 48*time.Hour); err != nil {
+		t.Fatalf("GetUpcomingTodos returned error: %v", err)
+	}
+	want := [][2]time.Time{{{}, now}, {now, now.Add(48 * time.Hour)}}
+	if !reflect.DeepEqual(tr.windows, want) {
+		t.Errorf("windows = %v, want %v", tr.windows, want)
+	}
+
+	for _, within := range []time.Duration{0, -time.Hour} {
+		_, err := u.GetUpcomingTodos("owner", within)
+		if err == nil || err.Error() != "period must be positive" {
+			t.Errorf("GetUpcomingTodos(%v) error = %v", within, err)
+		}
+	}
+	if _, err := u.GetOverdueTodos(""); err == nil || err.Error() != "user_id is empty" {
+		t.Errorf("GetOverdueTodos(\"\") error = %v", err)
+	}
+}
+
+// 優先度は未指定ならmedium、定義外の値はエラーにする
+func TestCreateTodoPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority string
+		want     string
+		wantErr  string
+	}{
+		{name: "default", priority: "", want: domain_todo.TodoPriorityMedium},
+		{name: "urgent", priority: domain_todo.TodoPriorityUrgent, want: domain_todo.TodoPriorityUrgent},
+		{name: "invalid", priority: "critical", wantErr: "invalid priority"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, tr, _ := newTestTodoUsecase(nil, nil)
+			todo, err := u.CreateTodo(domain_todo.Todo{Description: "task", UserId: "owner", Priority: tt.priority}, domain_audit.AuditActor{UserId: "owner"})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("CreateTodo error = %v, want %q", err, tt.wantErr)
+				}
+				if len(tr.created) != 0 {
+					t.Errorf("created = %v, want none", tr.created)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateTodo returned error: %v", err)
+			}
+			if todo.Priority != tt.want {
+				t.Errorf("priority = %q, want %q", todo.Priority, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package usecase_todolist
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_todo "backend/internal/domain/todo"
+	domain_todolist "backend/internal/domain/todolist"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_todo "backend/internal/repository/todo"
+	repository_todolist "backend/internal/repository/todolist"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 色を指定しなかった場合の表示色
+const defaultColor = "#808080"
+
+// 表示色の形式(#RRGGBB)
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Todoリストユースケース(IF)
+type ITodoListUsecase interface {
+	// 自分のTodoリストを取得
+	GetTodoList(userId string, id string) (domain_todolist.TodoList, error)
+	// 特定のユーザーのTodoリストを取得
+	GetTodoListsByUserId(userId string, includeArchived bool) ([]domain_todolist.TodoList, error)
+	// 複数のTodoリストのうち、viewerIdのユーザーが閲覧できるものを取得
+	GetVisibleTodoListsByIds(viewerId string, ids []string) ([]domain_todolist.TodoList, error)
+	// 複数のTodoリストに属するTodoのうち、viewerIdのユーザーが閲覧できるものを取得
+	GetVisibleTodosByListIds(viewerId string, listIds []string) ([]domain_todo.Todo, error)
+	// 特定のユーザーのインボックスのTodoを取得
+	GetInboxTodos(userId string) ([]domain_todo.Todo, error)
+	// 新しいTodoリストを作成
+	CreateTodoList(userId string, name string, color string) (domain_todolist.TodoList, error)
+	// Todoリストを更新
+	UpdateTodoList(userId string, list domain_todolist.TodoList) (domain_todolist.TodoList, error)
+	// Todoリストを削除し、影響を受けたTodoの件数を返す
+	DeleteTodoList(userId string, id string, mode string, actor domain_audit.AuditActor) (int, error)
+	// Todoを別のリストに移動(listIdがnilの場合はインボックス)
+	MoveTodoToList(userId string, todoId string, listId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error)
+}
+
+// Todoリストユースケース(Impl)
+type TodoListUsecase struct {
+	Logger             *pkg_logger.AppLogger
+	todoListRepository repository_todolist.ITodoListRepository
+	todoRepository     repository_todo.ITodoRepository
+}
+
+// Todoリストユースケースのインスタンス化
+func NewTodoListUsecase(l *pkg_logger.AppLogger, tlr repository_todolist.ITodoListRepository, tr repository_todo.ITodoRepository) ITodoListUsecase {
+	return &TodoListUsecase{
+		Logger:             l,
+		todoListRepository: tlr,
+		todoRepository:     tr,
+	}
+}
+
+// 自分のTodoリストを取得
+func (u *TodoListUsecase) GetTodoList(userId string, id string) (domain_todolist.TodoList, error) {
+	u.Logger.InfoLog.Println("GetTodoList called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_todolist.TodoList{}, errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("list_id is empty")
+		return domain_todolist.TodoList{}, errors.New("list_id is empty")
+	}
+
+	// Todoリストリポジトリから取得(repository層)
+	list, err := u.todoListRepository.GetTodoListById(id)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo list: %v", err)
+		return domain_todolist.TodoList{}, errors.New("todo list not found")
+	}
+
+	// 所有者チェック
+	if list.UserId != userId {
+		u.Logger.ErrorLog.Println("forbidden")
+		return domain_todolist.TodoList{}, errors.New("forbidden")
+	}
+
+	u.Logger.InfoLog.Printf("Fetched todo list: %v", list.ID)
+	return list, nil
+}
+
+// 特定のユーザーのTodoリストを取得
+func (u *TodoListUsecase) GetTodoListsByUserId(userId string, includeArchived bool) ([]domain_todolist.TodoList, error) {
+	u.Logger.InfoLog.Println("GetTodoListsByUserId called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Todoリストリポジトリから取得(repository層)
+	lists, err := u.todoListRepository.GetTodoListsByUserId(userId, includeArchived)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo lists: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d todo lists", len(lists))
+	return lists, nil
+}
+
+// 複数のTodoリストのうち、viewerIdのユーザーが閲覧できるものを取得
+// 自分のTodoリストと、閲覧できるTodoが属する他のユーザーのTodoリストを対象とする。
+func (u *TodoListUsecase) GetVisibleTodoListsByIds(viewerId string, ids []string) ([]domain_todolist.TodoList, error) {
+	u.Logger.InfoLog.Println("GetVisibleTodoListsByIds called")
+
+	// バリデーション(未認証の場合は何も閲覧できない)
+	if viewerId == "" || len(ids) == 0 {
+		return []domain_todolist.TodoList{}, nil
+	}
+
+	// Todoリストリポジトリから取得(repository層)
+	lists, err := u.todoListRepository.GetTodoListsByIds(ids)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo lists by ids: %v", err)
+		return nil, err
+	}
+
+	// 他のユーザーのTodoリストは、閲覧できるTodoが属するもののみ返す
+	otherIds := []string{}
+	for _, l := range lists {
+		if l.UserId != viewerId {
+			otherIds = append(otherIds, l.ID)
+		}
+	}
+	sharedIds := map[string]bool{}
+	if len(otherIds) > 0 {
+		todos, err := u.todoRepository.GetVisibleTodosByListIds(viewerId, otherIds)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to get todos by list_ids: %v", err)
+			return nil, err
+		}
+		for _, t := range todos {
+			if t.ListId != nil {
+				sharedIds[*t.ListId] = true
+			}
+		}
+	}
+	visible := make([]domain_todolist.TodoList, 0, len(lists))
+	for _, l := range lists {
+		if l.UserId == viewerId || sharedIds[l.ID] {
+			visible = append(visible, l)
+		}
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d todo lists", len(visible))
+	return visible, nil
+}
+
+// 複数のTodoリストに属するTodoのうち、viewerIdのユーザーが閲覧できるものを取得
+func (u *TodoListUsecase) GetVisibleTodosByListIds(viewerId string, listIds []string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetVisibleTodosByListIds called")
+
+	// バリデーション(未認証の場合は何も閲覧できない)
+	if viewerId == "" || len(listIds) == 0 {
+		return []domain_todo.Todo{}, nil
+	}
+
+	// Todoリポジトリから閲覧できるTodoを取得(repository層)
+	todos, err := u.todoRepository.GetVisibleTodosByListIds(viewerId, listIds)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todos by list_ids: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d todos", len(todos))
+	return todos, nil
+}
+
+// 特定のユーザーのインボックスのTodoを取得
+func (u *TodoListUsecase) GetInboxTodos(userId string) ([]domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("GetInboxTodos called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Todoリポジトリから取得(repository層)
+	todos, err := u.todoRepository.GetInboxTodosByUserId(userId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get inbox todos: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d todos", len(todos))
+	return todos, nil
+}
+
+// 新しいTodoリストを作成
+func (u *TodoListUsecase) CreateTodoList(userId string, name string, color string) (domain_todolist.TodoList, error) {
+	u.Logger.InfoLog.Println("CreateTodoList called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_todolist.TodoList{}, errors.New("user_id is empty")
+	}
+	name, err := u.validateName(name)
+	if err != nil {
+		return domain_todolist.TodoList{}, err
+	}
+	if color == "" {
+		color = defaultColor
+	}
+	if !colorPattern.MatchString(color) {
+		u.Logger.ErrorLog.Println("invalid color")
+		return domain_todolist.TodoList{}, errors.New("invalid color")
+	}
+
+	// Todoリストリポジトリから作成(repository層)
+	list, err := u.todoListRepository.CreateTodoList(domain_todolist.TodoList{UserId: userId, Name: name, Color: color})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to create todo list: %v", err)
+		return domain_todolist.TodoList{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Created todo list: %v", list.ID)
+	return list, nil
+}
+
+// Todoリストを更新
+func (u *TodoListUsecase) UpdateTodoList(userId string, list domain_todolist.TodoList) (domain_todolist.TodoList, error) {
+	u.Logger.InfoLog.Println("UpdateTodoList called")
+
+	// バリデーション
+	name, err := u.validateName(list.Name)
+	if err != nil {
+		return domain_todolist.TodoList{}, err
+	}
+	list.Name = name
+	if !colorPattern.MatchString(list.Color) {
+		u.Logger.ErrorLog.Println("invalid color")
+		return domain_todolist.TodoList{}, errors.New("invalid color")
+	}
+	if list.Position < 0 {
+		u.Logger.ErrorLog.Println("position must not be negative")
+		return domain_todolist.TodoList{}, errors.New("position must not be negative")
+	}
+
+	// 所有者チェック
+	_, err = u.GetTodoList(userId, list.ID)
+	if err != nil {
+		return domain_todolist.TodoList{}, err
+	}
+
+	// Todoリストリポジトリから更新(repository層)
+	updated, err := u.todoListRepository.UpdateTodoList(list)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to update todo list: %v", err)
+		return domain_todolist.TodoList{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Updated todo list: %v", updated.ID)
+	return updated, nil
+}
+
+// Todoリストを削除し、影響を受けたTodoの件数を返す
+func (u *TodoListUsecase) DeleteTodoList(userId string, id string, mode string, actor domain_audit.AuditActor) (int, error) {
+	u.Logger.InfoLog.Println("DeleteTodoList called")
+
+	// バリデーション
+	if !domain_todolist.IsValidDeleteMode(mode) {
+		u.Logger.ErrorLog.Println("invalid delete mode")
+		return 0, errors.New("invalid delete mode")
+	}
+
+	// 所有者チェック
+	_, err := u.GetTodoList(userId, id)
+	if err != nil {
+		return 0, err
+	}
+
+	// Todoリストリポジトリから削除(repository層)
+	affected, err := u.todoListRepository.DeleteTodoList(id, mode, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to delete todo list: %v", err)
+		return 0, err
+	}
+
+	u.Logger.InfoLog.Printf("Deleted todo list: %v", id)
+	return affected, nil
+}
+
+// Todoを別のリストに移動(listIdがnilの場合はインボックス)
+func (u *TodoListUsecase) MoveTodoToList(userId string, todoId string, listId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	u.Logger.InfoLog.Println("MoveTodoToList called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_todo.Todo{}, errors.New("user_id is empty")
+	}
+	if todoId == "" {
+		u.Logger.ErrorLog.Println("todo_id is empty")
+		return domain_todo.Todo{}, errors.New("todo_id is empty")
+	}
+
+	// Todoの所有者チェック
+	todo, err := u.todoRepository.GetTodoById(todoId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo: %v", err)
+		return domain_todo.Todo{}, errors.New("todo not found")
+	}
+	if todo.UserId != userId {
+		u.Logger.ErrorLog.Println("forbidden")
+		return domain_todo.Todo{}, errors.New("forbidden")
+	}
+
+	// 移動先リストの所有者チェック(アーカイブ済みのリストには移動できない)
+	if listId != nil {
+		list, err := u.GetTodoList(userId, *listId)
+		if err != nil {
+			return domain_todo.Todo{}, err
+		}
+		if list.Archived {
+			u.Logger.ErrorLog.Println("todo list is archived")
+			return domain_todo.Todo{}, errors.New("todo list is archived")
+		}
+	}
+
+	// Todoリポジトリから移動(repository層)
+	moved, err := u.todoRepository.MoveTodoToList(todoId, listId, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to move todo: %v", err)
+		return domain_todo.Todo{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Moved todo: %v", moved.ID)
+	return moved, nil
+}
+
+// リスト名のバリデーション(前後の空白を除いた名前を返す)
+func (u *TodoListUsecase) validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		u.Logger.ErrorLog.Println("name is empty")
+		return "", errors.New("name is empty")
+	}
+	if utf8.RuneCountInString(name) > domain_todolist.MaxTodoListNameLength {
+		u.Logger.ErrorLog.Println("name is too long")
+		return "", errors.New("name is too long")
+	}
+	return name, nil
+}
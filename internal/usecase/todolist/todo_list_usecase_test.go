@@ -0,0 +1,297 @@
+package usecase_todolist
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_todo "backend/internal/domain/todo"
+	domain_todolist "backend/internal/domain/todolist"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_todo "backend/internal/repository/todo"
+	repository_todolist "backend/internal/repository/todolist"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// idでTodoリストを返すTodoリストリポジトリ(使用しないメソッドは未実装)
+type fakeTodoListRepository struct {
+	repository_todolist.ITodoListRepository
+	lists []domain_todolist.TodoList
+	// 更新・削除の呼び出し
+	changes []string
+}
+
+func (r *fakeTodoListRepository) GetTodoListById(id string) (domain_todolist.TodoList, error) {
+	for _, l := range r.lists {
+		if l.ID == id {
+			return l, nil
+		}
+	}
+	return domain_todolist.TodoList{}, errors.New("no rows in result set")
+}
+
+func (r *fakeTodoListRepository) UpdateTodoList(list domain_todolist.TodoList) (domain_todolist.TodoList, error) {
+	r.changes = append(r.changes, "update "+list.ID)
+	return list, nil
+}
+
+func (r *fakeTodoListRepository) DeleteTodoList(id string, mode string, actor domain_audit.AuditActor) (int, error) {
+	r.changes = append(r.changes, "delete "+id+" "+mode)
+	return 2, nil
+}
+
+func (r *fakeTodoListRepository) GetTodoListsByIds(ids []string) ([]domain_todolist.TodoList, error) {
+	result := []domain_todolist.TodoList{}
+	for _, l := range r.lists {
+		for _, id := range ids {
+			if l.ID == id {
+				result = append(result, l)
+			}
+		}
+	}
+	return result, nil
+}
+
+// ユーザーごとに閲覧できるTodoを持つTodoリポジトリ(使用しないメソッドは未実装)
+type fakeTodoRepository struct {
+	repository_todo.ITodoRepository
+	todos   []domain_todo.Todo
+	visible map[string][]string
+	// GetVisibleTodosByListIdsに渡されたTodoリストのid
+	listIdCalls [][]string
+	// MoveTodoToListの呼び出し
+	moves []string
+}
+
+func (r *fakeTodoRepository) GetTodoById(id string) (domain_todo.Todo, error) {
+	for _, t := range r.todos {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return domain_todo.Todo{}, errors.New("no rows in result set")
+}
+
+func (r *fakeTodoRepository) MoveTodoToList(id string, listId *string, actor domain_audit.AuditActor) (domain_todo.Todo, error) {
+	to := "inbox"
+	if listId != nil {
+		to = *listId
+	}
+	r.moves = append(r.moves, id+" -> "+to)
+	return domain_todo.Todo{ID: id, ListId: listId}, nil
+}
+
+func (r *fakeTodoRepository) GetVisibleTodosByListIds(viewerId string, listIds []string) ([]domain_todo.Todo, error) {
+	r.listIdCalls = append(r.listIdCalls, listIds)
+	result := []domain_todo.Todo{}
+	for _, t := range r.todos {
+		for _, listId := range listIds {
+			if t.ListId == nil || *t.ListId != listId {
+				continue
+			}
+			for _, id := range r.visible[viewerId] {
+				if t.ID == id {
+					result = append(result, t)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+func listIdPtr(id string) *string {
+	return &id
+}
+
+// 自分のTodoリストと、閲覧できるTodoが属する他のユーザーのTodoリストのみ返す
+func TestGetVisibleTodoListsByIds(t *testing.T) {
+	lr := &fakeTodoListRepository{lists: []domain_todolist.TodoList{
+		{ID: "l1", UserId: "u1"},
+		{ID: "l2", UserId: "u2"},
+		{ID: "l3", UserId: "u2"},
+	}}
+	tests := []struct {
+		name         string
+		viewerId     string
+		want         []string
+		wantListIds  []string
+		wantNoLookup bool
+	}{
+		{name: "owner of every list", viewerId: "u2", want: []string{"l2", "l3"}, wantNoLookup: true},
+		{name: "shared member", viewerId: "u1", want: []string{"l1", "l2"}, wantListIds: []string{"l2", "l3"}},
+		{name: "other user", viewerId: "u3", want: []string{}, wantListIds: []string{"l1", "l2", "l3"}},
+		{name: "unauthenticated", viewerId: "", want: []string{}, wantNoLookup: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &fakeTodoRepository{
+				todos: []domain_todo.Todo{
+					{ID: "t1", UserId: "u1", ListId: listIdPtr("l1")},
+					{ID: "t2", UserId: "u2", ListId: listIdPtr("l2")},
+					{ID: "t3", UserId: "u2", ListId: listIdPtr("l3")},
+				},
+				visible: map[string][]string{"u1": {"t1", "t2"}, "u2": {"t2", "t3"}},
+			}
+			u := NewTodoListUsecase(newTestLogger(), lr, tr)
+			ids := []string{"l1", "l2", "l3"}
+			if tt.viewerId == "u2" {
+				ids = []string{"l2", "l3"}
+			}
+
+			lists, err := u.GetVisibleTodoListsByIds(tt.viewerId, ids)
+			if err != nil {
+				t.Fatalf("GetVisibleTodoListsByIds() unexpected error: %v", err)
+			}
+			if len(lists) != len(tt.want) {
+				t.Fatalf("GetVisibleTodoListsByIds() returned %v, want %v", lists, tt.want)
+			}
+			for i, l := range lists {
+				if l.ID != tt.want[i] {
+					t.Errorf("lists[%d] = %s, want %s", i, l.ID, tt.want[i])
+				}
+			}
+			// 他のユーザーのTodoリストのみ、1回のクエリでまとめて確認する
+			if tt.wantNoLookup {
+				if len(tr.listIdCalls) != 0 {
+					t.Errorf("GetVisibleTodosByListIds called with %v, want no call", tr.listIdCalls)
+				}
+				return
+			}
+			if len(tr.listIdCalls) != 1 || len(tr.listIdCalls[0]) != len(tt.wantListIds) {
+				t.Errorf("GetVisibleTodosByListIds called with %v, want [%v] once", tr.listIdCalls, tt.wantListIds)
+			}
+		})
+	}
+}
+
+func newListPermissionUsecase() (ITodoListUsecase, *fakeTodoListRepository, *fakeTodoRepository) {
+	lr := &fakeTodoListRepository{lists: []domain_todolist.TodoList{
+		{ID: "l1", UserId: "u1", Name: "Work", Color: "#FF0000"},
+		{ID: "l2", UserId: "u1", Name: "Old", Color: "#00FF00", Archived: true},
+		{ID: "l3", UserId: "u2", Name: "Home", Color: "#0000FF"},
+	}}
+	tr := &fakeTodoRepository{todos: []domain_todo.Todo{
+		{ID: "t1", UserId: "u1", ListId: listIdPtr("l1")},
+		{ID: "t3", UserId: "u2", ListId: listIdPtr("l3")},
+	}}
+	return NewTodoListUsecase(newTestLogger(), lr, tr), lr, tr
+}
+
+// Todoリストの更新・削除は所有者のみで、削除モードは定義済みのもののみ
+func TestTodoListMutationsRequireOwner(t *testing.T) {
+	actor := domain_audit.AuditActor{UserId: "u1"}
+	tests := []struct {
+		name       string
+		call       func(u ITodoListUsecase) error
+		wantErr    string
+		wantChange string
+	}{
+		{name: "delete cascade", call: func(u ITodoListUsecase) error {
+			_, err := u.DeleteTodoList("u1", "l1", domain_todolist.TodoListDeleteModeCascade, actor)
+			return err
+		}, wantChange: "delete l1 cascade"},
+		{name: "delete move to inbox", call: func(u ITodoListUsecase) error {
+			_, err := u.DeleteTodoList("u1", "l1", domain_todolist.TodoListDeleteModeMoveToInbox, actor)
+			return err
+		}, wantChange: "delete l1 move_to_inbox"},
+		{name: "delete with unknown mode", call: func(u ITodoListUsecase) error {
+			_, err := u.DeleteTodoList("u1", "l1", "archive", actor)
+			return err
+		}, wantErr: "invalid delete mode"},
+		{name: "delete other's list", call: func(u ITodoListUsecase) error {
+			_, err := u.DeleteTodoList("u1", "l3", domain_todolist.TodoListDeleteModeCascade, actor)
+			return err
+		}, wantErr: "forbidden"},
+		{name: "delete missing list", call: func(u ITodoListUsecase) error {
+			_, err := u.DeleteTodoList("u1", "lx", domain_todolist.TodoListDeleteModeCascade, actor)
+			return err
+		}, wantErr: "todo list not found"},
+		{name: "update own list", call: func(u ITodoListUsecase) error {
+			_, err := u.UpdateTodoList("u1", domain_todolist.TodoList{ID: "l1", Name: " Work ", Color: "#123456"})
+			return err
+		}, wantChange: "update l1"},
+		{name: "update other's list", call: func(u ITodoListUsecase) error {
+			_, err := u.UpdateTodoList("u1", domain_todolist.TodoList{ID: "l3", Name: "Mine", Color: "#123456"})
+			return err
+		}, wantErr: "forbidden"},
+		{name: "update with invalid color", call: func(u ITodoListUsecase) error {
+			_, err := u.UpdateTodoList("u1", domain_todolist.TodoList{ID: "l1", Name: "Work", Color: "red"})
+			return err
+		}, wantErr: "invalid color"},
+		{name: "update with negative position", call: func(u ITodoListUsecase) error {
+			_, err := u.UpdateTodoList("u1", domain_todolist.TodoList{ID: "l1", Name: "Work", Color: "#123456", Position: -1})
+			return err
+		}, wantErr: "position must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, lr, _ := newListPermissionUsecase()
+			err := tt.call(u)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if len(lr.changes) != 0 {
+					t.Errorf("changes = %v, want none", lr.changes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("returned error: %v", err)
+			}
+			if !reflect.DeepEqual(lr.changes, []string{tt.wantChange}) {
+				t.Errorf("changes = %v, want [%s]", lr.changes, tt.wantChange)
+			}
+		})
+	}
+}
+
+// Todoは自分のTodoを、自分のアーカイブされていないリストかインボックスにのみ移動できる
+func TestMoveTodoToList(t *testing.T) {
+	tests := []struct {
+		name     string
+		userId   string
+		todoId   string
+		listId   *string
+		wantErr  string
+		wantMove string
+	}{
+		{name: "to own list", userId: "u1", todoId: "t1", listId: listIdPtr("l1"), wantMove: "t1 -> l1"},
+		{name: "to inbox", userId: "u1", todoId: "t1", listId: nil, wantMove: "t1 -> inbox"},
+		{name: "to archived list", userId: "u1", todoId: "t1", listId: listIdPtr("l2"), wantErr: "todo list is archived"},
+		{name: "to other's list", userId: "u1", todoId: "t1", listId: listIdPtr("l3"), wantErr: "forbidden"},
+		{name: "other's todo", userId: "u1", todoId: "t3", listId: listIdPtr("l1"), wantErr: "forbidden"},
+		{name: "missing todo", userId: "u1", todoId: "tx", listId: nil, wantErr: "todo not found"},
+		{name: "unauthenticated", userId: "", todoId: "t1", listId: nil, wantErr: "user_id is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _, tr := newListPermissionUsecase()
+			_, err := u.MoveTodoToList(tt.userId, tt.todoId, tt.listId, domain_audit.AuditActor{UserId: tt.userId})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("MoveTodoToList error = %v, want %q", err, tt.wantErr)
+				}
+				if len(tr.moves) != 0 {
+					t.Errorf("moves = %v, want none", tr.moves)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MoveTodoToList returned error: %v", err)
+			}
+			if !reflect.DeepEqual(tr.moves, []string{tt.wantMove}) {
+				t.Errorf("moves = %v, want [%s]", tr.moves, tt.wantMove)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package usecase_transfer
+
+import (
+	domain_calendar "backend/internal/domain/calendar"
+	domain_transfer "backend/internal/domain/transfer"
+	pkg_ical "backend/internal/pkg/ical"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iCalendarの製品識別子
+const icalProductId = "-//todo-app//Todo Export//JA"
+
+// iCalendarでTodoリストのIDを表す独自のプロパティ
+const icalListIdProperty = "X-TODO-LIST-ID"
+
+// エクスポートの書き込み
+type todoEncoder interface {
+	// 先頭部分を書き込む
+	Begin() error
+	// 1件を書き込む
+	Encode(record domain_transfer.TodoRecord) error
+	// 末尾部分を書き込み、バッファを書き出す
+	End() error
+}
+
+// 形式に応じたエクスポートの書き込みを作成
+func newTodoEncoder(format string, w io.Writer) todoEncoder {
+	switch format {
+	case domain_transfer.TodoTransferFormatCSV:
+		return &csvTodoEncoder{w: csv.NewWriter(w)}
+	case domain_transfer.TodoTransferFormatICal:
+		return &icalTodoEncoder{w: pkg_ical.NewWriter(w), now: time.Now()}
+	default:
+		return &jsonTodoEncoder{w: w}
+	}
+}
+
+// 形式に応じてインポートするデータを読み込む
+// データ全体を解析できない場合はエラーを返し、行ごとの不備は各行のErrorsに記録する。
+func decodeTodoRows(format string, data string) ([]domain_transfer.TodoImportRow, error) {
+	switch format {
+	case domain_transfer.TodoTransferFormatCSV:
+		return decodeCSVTodoRows(data)
+	case domain_transfer.TodoTransferFormatICal:
+		return decodeICalTodoRows(data)
+	default:
+		return decodeJSONTodoRows(data)
+	}
+}
+
+// JSON形式(Todoの配列)の書き込み
+type jsonTodoEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonTodoEncoder) Begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonTodoEncoder) Encode(record domain_transfer.TodoRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	sep := "\n"
+	if e.count > 0 {
+		sep = ",\n"
+	}
+	e.count++
+	_, err = io.WriteString(e.w, sep+string(b))
+	return err
+}
+
+func (e *jsonTodoEncoder) End() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
+
+// CSV形式(ヘッダー行あり、日時はRFC 3339)の書き込み
+type csvTodoEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvTodoEncoder) Begin() error {
+	return e.w.Write(domain_transfer.TodoRecordCSVColumns)
+}
+
+func (e *csvTodoEncoder) Encode(record domain_transfer.TodoRecord) error {
+	return e.w.Write([]string{
+		record.ID,
+		record.Description,
+		strconv.FormatBool(record.Completed),
+		record.Priority,
+		formatTimePtr(record.DueAt),
+		formatTimePtr(record.RemindAt),
+		stringValue(record.ListId),
+		stringValue(record.ParentId),
+		formatTimePtr(record.CreatedAt),
+		formatTimePtr(record.UpdatedAt),
+	})
+}
+
+func (e *csvTodoEncoder) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// iCalendar形式(VTODO)の書き込み
+type icalTodoEncoder struct {
+	w   *pkg_ical.Writer
+	now time.Time
+}
+
+func (e *icalTodoEncoder) Begin() error {
+	e.w.Begin("VCALENDAR")
+	e.w.Property("VERSION", "2.0")
+	e.w.Property("PRODID", icalProductId)
+	e.w.Property("CALSCALE", "GREGORIAN")
+	return nil
+}
+
+func (e *icalTodoEncoder) Encode(record domain_transfer.TodoRecord) error {
+	e.w.Begin("VTODO")
+	e.w.Text("UID", record.ID)
+	e.w.DateTime("DTSTAMP", e.now)
+	if record.CreatedAt != nil {
+		e.w.DateTime("CREATED", *record.CreatedAt)
+	}
+	if record.UpdatedAt != nil {
+		e.w.DateTime("LAST-MODIFIED", *record.UpdatedAt)
+	}
+	e.w.Text("SUMMARY", record.Description)
+	if record.Completed {
+		e.w.Property("STATUS", "COMPLETED")
+	} else {
+		e.w.Property("STATUS", "NEEDS-ACTION")
+	}
+	e.w.Property("PRIORITY", strconv.Itoa(domain_calendar.ToICalPriority(record.Priority)))
+	if record.DueAt != nil {
+		e.w.DateTime("DUE", *record.DueAt)
+	}
+	if record.ParentId != nil {
+		e.w.Property("RELATED-TO", pkg_ical.EscapeText(*record.ParentId), "RELTYPE=PARENT")
+	}
+	if record.ListId != nil {
+		e.w.Text(icalListIdProperty, *record.ListId)
+	}
+	if record.RemindAt != nil {
+		e.w.Begin("VALARM")
+		e.w.Property("ACTION", "DISPLAY")
+		e.w.Text("DESCRIPTION", record.Description)
+		e.w.Property("TRIGGER", pkg_ical.FormatDateTime(*record.RemindAt), "VALUE=DATE-TIME")
+		e.w.End("VALARM")
+	}
+	e.w.End("VTODO")
+	return e.w.Flush()
+}
+
+func (e *icalTodoEncoder) End() error {
+	e.w.End("VCALENDAR")
+	return e.w.Flush()
+}
+
+// JSONの1件(日時は項目ごとのエラーにするため文字列で受け取る)
+type jsonTodoRow struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Completed   bool    `json:"completed"`
+	Priority    string  `json:"priority"`
+	DueAt       *string `json:"due_at"`
+	RemindAt    *string `json:"remind_at"`
+	ListId      *string `json:"list_id"`
+	ParentId    *string `json:"parent_id"`
+}
+
+// JSON形式(Todoの配列)を読み込む
+func decodeJSONTodoRows(data string) ([]domain_transfer.TodoImportRow, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raws); err != nil {
+		return nil, errors.New("invalid import data")
+	}
+	if len(raws) > domain_transfer.MaxImportRows {
+		return nil, errors.New("too many rows")
+	}
+
+	rows := make([]domain_transfer.TodoImportRow, 0, len(raws))
+	for i, raw := range raws {
+		row := domain_transfer.TodoImportRow{Row: i + 1}
+		var v jsonTodoRow
+		if err := json.Unmarshal(raw, &v); err != nil {
+			field := ""
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				field = typeErr.Field
+			}
+			row.Errors = append(row.Errors, domain_transfer.TodoImportError{Row: row.Row, Field: field, Message: "invalid value"})
+			rows = append(rows, row)
+			continue
+		}
+		row.Record = domain_transfer.TodoRecord{
+			ID:          strings.TrimSpace(v.ID),
+			Description: v.Description,
+			Completed:   v.Completed,
+			Priority:    v.Priority,
+			ListId:      nonEmpty(v.ListId),
+			ParentId:    nonEmpty(v.ParentId),
+		}
+		row.Record.DueAt = parseTimeField(&row, "due_at", stringValue(v.DueAt))
+		row.Record.RemindAt = parseTimeField(&row, "remind_at", stringValue(v.RemindAt))
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// CSV形式(ヘッダー行あり、description列は必須、不明な列は無視する)を読み込む
+func decodeCSVTodoRows(data string) ([]domain_transfer.TodoImportRow, error) {
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(data, "\ufeff")))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.New("invalid import data")
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["description"]; !ok {
+		return nil, errors.New("invalid import data")
+	}
+
+	rows := []domain_transfer.TodoImportRow{}
+	for {
+		fields, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("invalid import data")
+		}
+		if len(rows) == domain_transfer.MaxImportRows {
+			return nil, errors.New("too many rows")
+		}
+
+		row := domain_transfer.TodoImportRow{Row: len(rows) + 1}
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+		row.Record = domain_transfer.TodoRecord{
+			ID:          get("id"),
+			Description: get("description"),
+			Priority:    get("priority"),
+			ListId:      nonEmpty(stringPtr(get("list_id"))),
+			ParentId:    nonEmpty(stringPtr(get("parent_id"))),
+		}
+		if v := get("completed"); v != "" {
+			completed, err := strconv.ParseBool(v)
+			if err != nil {
+				row.Errors = append(row.Errors, domain_transfer.TodoImportError{Row: row.Row, Field: "completed", Message: "invalid value"})
+			}
+			row.Record.Completed = completed
+		}
+		row.Record.DueAt = parseTimeField(&row, "due_at", get("due_at"))
+		row.Record.RemindAt = parseTimeField(&row, "remind_at", get("remind_at"))
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// iCalendar形式(VCALENDAR内のVTODO)を読み込む
+// タイムゾーンの無い日時はUTCとして扱い、VALARMは日時指定と期限からの相対指定に対応する。
+func decodeICalTodoRows(data string) ([]domain_transfer.TodoImportRow, error) {
+	roots, err := pkg_ical.Parse(strings.NewReader(data))
+	if err != nil || len(roots) == 0 {
+		return nil, errors.New("invalid import data")
+	}
+
+	rows := []domain_transfer.TodoImportRow{}
+	for _, root := range roots {
+		if root.Name != "VCALENDAR" {
+			return nil, errors.New("invalid import data")
+		}
+		for _, c := range root.Components {
+			if c.Name != "VTODO" {
+				continue
+			}
+			if len(rows) == domain_transfer.MaxImportRows {
+				return nil, errors.New("too many rows")
+			}
+			rows = append(rows, decodeVTodo(c, len(rows)+1))
+		}
+	}
+	return rows, nil
+}
+
+// VTODOを1件読み込む
+func decodeVTodo(c pkg_ical.Component, n int) domain_transfer.TodoImportRow {
+	row := domain_transfer.TodoImportRow{Row: n}
+	text := func(name string) string {
+		if p := c.Get(name); p != nil {
+			return strings.TrimSpace(pkg_ical.UnescapeText(p.Value))
+		}
+		return ""
+	}
+	invalid := func(field string) {
+		row.Errors = append(row.Errors, domain_transfer.TodoImportError{Row: n, Field: field, Message: "invalid value"})
+	}
+
+	row.Record.ID = text("UID")
+	row.Record.Description = text("SUMMARY")
+	row.Record.Completed = strings.EqualFold(text("STATUS"), "COMPLETED")
+	row.Record.ListId = nonEmpty(stringPtr(text(icalListIdProperty)))
+	for _, p := range c.Properties {
+		if p.Name == "RELATED-TO" && (p.Params["RELTYPE"] == "" || strings.EqualFold(p.Params["RELTYPE"], "PARENT")) {
+			row.Record.ParentId = nonEmpty(stringPtr(strings.TrimSpace(pkg_ical.UnescapeText(p.Value))))
+			break
+		}
+	}
+
+	if p := c.Get("PRIORITY"); p != nil {
+		value, err := strconv.Atoi(strings.TrimSpace(p.Value))
+		priority, ok := domain_calendar.PriorityFromICal(value)
+		if err != nil || !ok {
+			invalid("priority")
+		}
+		row.Record.Priority = priority
+	}
+	if p := c.Get("DUE"); p != nil {
+		due, err := pkg_ical.ParseDateTime(*p, time.UTC)
+		if err != nil {
+			invalid("due_at")
+		} else {
+			row.Record.DueAt = &due
+		}
+	}
+
+	// 最初のVALARMをリマインド日時とする
+	for _, alarm := range c.Components {
+		if alarm.Name != "VALARM" {
+			continue
+		}
+		trigger := alarm.Get("TRIGGER")
+		if trigger == nil {
+			break
+		}
+		if trigger.Params["VALUE"] == "DATE-TIME" {
+			remindAt, err := pkg_ical.ParseDateTime(*trigger, time.UTC)
+			if err != nil {
+				invalid("remind_at")
+			} else {
+				row.Record.RemindAt = &remindAt
+			}
+			break
+		}
+		offset, err := pkg_ical.ParseDuration(trigger.Value)
+		if err != nil || row.Record.DueAt == nil {
+			// 相対指定は期限が無いと日時を決められない
+			invalid("remind_at")
+			break
+		}
+		remindAt := row.Record.DueAt.Add(offset)
+		row.Record.RemindAt = &remindAt
+		break
+	}
+	return row
+}
+
+// 日時の項目を解析(空の場合はnil、不正な場合は行のエラーに記録してnil)
+func parseTimeField(row *domain_transfer.TodoImportRow, field string, value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		row.Errors = append(row.Errors, domain_transfer.TodoImportError{Row: row.Row, Field: field, Message: "invalid value"})
+		return nil
+	}
+	return &t
+}
+
+// 日時をRFC 3339の文字列に変換(nilの場合は空)
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// 文字列のポインタの値(nilの場合は空)
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// 文字列のポインタ
+func stringPtr(s string) *string {
+	return &s
+}
+
+// 空白を除いて空の場合はnil
+func nonEmpty(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
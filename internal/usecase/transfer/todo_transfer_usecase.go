@@ -0,0 +1,350 @@
+package usecase_transfer
+
+import (
+	domain_audit "backend/internal/domain/audit"
+	domain_todo "backend/internal/domain/todo"
+	domain_transfer "backend/internal/domain/transfer"
+	pkg_fracindex "backend/internal/pkg/fracindex"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_todo "backend/internal/repository/todo"
+	repository_todolist "backend/internal/repository/todolist"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Todoのエクスポート・インポートユースケース(IF)
+type ITodoTransferUsecase interface {
+	// 自分のTodo(ゴミ箱を除く)を指定の形式で並び順にwへ書き出す
+	ExportTodos(userId string, format string, w io.Writer) error
+	// 指定の形式のデータからTodoをインポート(dryRunがtrueの場合は検証のみ、エラーがある場合は何もインポートしない)
+	ImportTodos(userId string, format string, data string, dryRun bool, actor domain_audit.AuditActor) (domain_transfer.TodoImportReport, error)
+}
+
+// Todoのエクスポート・インポートユースケース(Impl)
+type TodoTransferUsecase struct {
+	Logger             *pkg_logger.AppLogger
+	todoRepository     repository_todo.ITodoRepository
+	todoListRepository repository_todolist.ITodoListRepository
+}
+
+// Todoのエクスポート・インポートユースケースのインスタンス化
+func NewTodoTransferUsecase(l *pkg_logger.AppLogger, tr repository_todo.ITodoRepository, tlr repository_todolist.ITodoListRepository) ITodoTransferUsecase {
+	return &TodoTransferUsecase{
+		Logger:             l,
+		todoRepository:     tr,
+		todoListRepository: tlr,
+	}
+}
+
+// 自分のTodo(ゴミ箱を除く)を指定の形式で並び順にwへ書き出す
+func (u *TodoTransferUsecase) ExportTodos(userId string, format string, w io.Writer) error {
+	u.Logger.InfoLog.Println("ExportTodos called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return errors.New("user_id is empty")
+	}
+	if !domain_transfer.IsValidFormat(format) {
+		u.Logger.ErrorLog.Printf("Invalid format: %s", format)
+		return errors.New("invalid format")
+	}
+
+	// Todoリポジトリから1件ずつ読み込み、そのまま書き出す(repository層)
+	encoder := newTodoEncoder(format, w)
+	if err := encoder.Begin(); err != nil {
+		u.Logger.ErrorLog.Printf("Failed to write export: %v", err)
+		return err
+	}
+	count := 0
+	err := u.todoRepository.ForEachTodoByUserId(userId, func(todo domain_todo.Todo) error {
+		count++
+		return encoder.Encode(domain_transfer.NewTodoRecord(todo))
+	})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to export todos: %v", err)
+		return err
+	}
+	if err := encoder.End(); err != nil {
+		u.Logger.ErrorLog.Printf("Failed to write export: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Exported %d todos as %s", count, format)
+	return nil
+}
+
+// 指定の形式のデータからTodoをインポート(dryRunがtrueの場合は検証のみ、エラーがある場合は何もインポートしない)
+func (u *TodoTransferUsecase) ImportTodos(userId string, format string, data string, dryRun bool, actor domain_audit.AuditActor) (domain_transfer.TodoImportReport, error) {
+	u.Logger.InfoLog.Println("ImportTodos called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_transfer.TodoImportReport{}, errors.New("user_id is empty")
+	}
+	if !domain_transfer.IsValidFormat(format) {
+		u.Logger.ErrorLog.Printf("Invalid format: %s", format)
+		return domain_transfer.TodoImportReport{}, errors.New("invalid format")
+	}
+	if strings.TrimSpace(data) == "" {
+		u.Logger.ErrorLog.Println("data is empty")
+		return domain_transfer.TodoImportReport{}, errors.New("data is empty")
+	}
+	if len(data) > domain_transfer.MaxImportDataBytes {
+		u.Logger.ErrorLog.Printf("Data is too large: %d bytes", len(data))
+		return domain_transfer.TodoImportReport{}, errors.New("data is too large")
+	}
+
+	// データを読み込む
+	rows, err := decodeTodoRows(format, data)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to decode import data: %v", err)
+		return domain_transfer.TodoImportReport{}, err
+	}
+
+	// 1行ずつ検証し、作成するTodoと親子関係を組み立てる
+	plan, err := u.planImport(userId, rows)
+	if err != nil {
+		return domain_transfer.TodoImportReport{}, err
+	}
+
+	report := domain_transfer.TodoImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Errors: plan.errors,
+		Todos:  []domain_todo.Todo{},
+	}
+	if len(report.Errors) > 0 || dryRun || len(rows) == 0 {
+		u.Logger.InfoLog.Printf("Validated %d rows (errors: %d, dry run: %v)", report.Total, len(report.Errors), dryRun)
+		return report, nil
+	}
+
+	// 自分のTodoの末尾に、ファイルの順で並べる
+	last, err := u.todoRepository.GetLastTodoPosition(userId, "")
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get last todo position: %v", err)
+		return domain_transfer.TodoImportReport{}, err
+	}
+	lower := ""
+	if last != nil {
+		lower = *last
+	}
+	positions, err := pkg_fracindex.KeysBetween(lower, "", len(rows))
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to generate positions: %v", err)
+		return domain_transfer.TodoImportReport{}, err
+	}
+
+	// 親が子より前になるように並べ替える(parentIndexesは並べ替え後の位置)
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return plan.depths[order[a]] < plan.depths[order[b]]
+	})
+	orderIndexes := make([]int, len(rows))
+	for k, i := range order {
+		orderIndexes[i] = k
+	}
+	todos := make([]domain_todo.Todo, len(rows))
+	parentIndexes := make([]int, len(rows))
+	for k, i := range order {
+		todo := plan.todos[i]
+		todo.Position = &positions[i]
+		todos[k] = todo
+		parentIndexes[k] = -1
+		if plan.parentRows[i] >= 0 {
+			parentIndexes[k] = orderIndexes[plan.parentRows[i]]
+		}
+	}
+
+	// Todoリポジトリから1つのトランザクションで作成(repository層)
+	created, err := u.todoRepository.ImportTodos(todos, parentIndexes, actor)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to import todos: %v", err)
+		return domain_transfer.TodoImportReport{}, err
+	}
+
+	// ファイルの順に戻す
+	report.Todos = make([]domain_todo.Todo, len(rows))
+	for i := range rows {
+		report.Todos[i] = created[orderIndexes[i]]
+	}
+	report.Imported = len(created)
+
+	u.Logger.InfoLog.Printf("Imported %d todos as %s", report.Imported, format)
+	return report, nil
+}
+
+// インポートの計画(行ごとの作成するTodo・ファイル内の親の行・深さ)
+type importPlan struct {
+	todos      []domain_todo.Todo
+	parentRows []int // ファイル内の親の位置(ファイル外、または親が無い場合は-1)
+	depths     []int // 階層の深さ(最上位を1とする)
+	errors     []domain_transfer.TodoImportError
+}
+
+// 1行ずつ検証し、作成するTodoと親子関係を組み立てる
+// 親はファイル内のID、または自分の既存のTodoのIDを指定できる。
+func (u *TodoTransferUsecase) planImport(userId string, rows []domain_transfer.TodoImportRow) (importPlan, error) {
+	plan := importPlan{
+		todos:      make([]domain_todo.Todo, len(rows)),
+		parentRows: make([]int, len(rows)),
+		depths:     make([]int, len(rows)),
+		errors:     []domain_transfer.TodoImportError{},
+	}
+	addError := func(i int, field string, message string) {
+		plan.errors = append(plan.errors, domain_transfer.TodoImportError{Row: rows[i].Row, Field: field, Message: message})
+	}
+
+	// 自分のTodoリスト(アーカイブ済みを含む)
+	lists, err := u.todoListRepository.GetTodoListsByUserId(userId, true)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get todo lists: %v", err)
+		return importPlan{}, err
+	}
+	listIds := map[string]bool{}
+	for _, l := range lists {
+		listIds[l.ID] = true
+	}
+
+	// ファイル内のID
+	rowsById := map[string]int{}
+	for i, row := range rows {
+		if row.Record.ID == "" {
+			continue
+		}
+		if _, ok := rowsById[row.Record.ID]; ok {
+			addError(i, "id", "duplicate id")
+			continue
+		}
+		rowsById[row.Record.ID] = i
+	}
+
+	// 既存の親(IDごとに1回だけ取得する)
+	type existingParent struct {
+		todo  domain_todo.Todo
+		depth int
+		found bool
+	}
+	existingParents := map[string]existingParent{}
+	getExistingParent := func(id string) (existingParent, error) {
+		if p, ok := existingParents[id]; ok {
+			return p, nil
+		}
+		p := existingParent{}
+		parent, err := u.todoRepository.GetTodoById(id)
+		if err == nil && parent.UserId == userId {
+			ancestorIds, err := u.todoRepository.GetTodoAncestorIds(id)
+			if err != nil {
+				u.Logger.ErrorLog.Printf("Failed to get ancestors: %v", err)
+				return existingParent{}, err
+			}
+			p = existingParent{todo: parent, depth: len(ancestorIds) + 1, found: true}
+		}
+		existingParents[id] = p
+		return p, nil
+	}
+
+	for i, row := range rows {
+		plan.errors = append(plan.errors, row.Errors...)
+		plan.parentRows[i] = -1
+
+		record := row.Record
+		description := strings.TrimSpace(record.Description)
+		if description == "" {
+			addError(i, "description", "description is empty")
+		}
+		priority := record.Priority
+		if priority == "" {
+			priority = domain_todo.TodoPriorityMedium
+		}
+		if !domain_todo.IsValidPriority(priority) {
+			addError(i, "priority", "invalid priority")
+		}
+		if record.ListId != nil && !listIds[*record.ListId] {
+			addError(i, "list_id", "list not found")
+		}
+		plan.todos[i] = domain_todo.Todo{
+			Description: description,
+			Completed:   record.Completed,
+			UserId:      userId,
+			DueAt:       record.DueAt,
+			Priority:    priority,
+			RemindAt:    record.RemindAt,
+			ListId:      record.ListId,
+		}
+
+		if record.ParentId == nil {
+			continue
+		}
+		if p, ok := rowsById[*record.ParentId]; ok {
+			plan.parentRows[i] = p
+			continue
+		}
+		parent, err := getExistingParent(*record.ParentId)
+		if err != nil {
+			return importPlan{}, err
+		}
+		if !parent.found {
+			addError(i, "parent_id", "parent not found")
+			continue
+		}
+		plan.todos[i].ParentId = &parent.todo.ID
+		plan.todos[i].ListId = parent.todo.ListId
+		plan.depths[i] = parent.depth + 1
+	}
+
+	// ファイル内の親子関係をたどり、循環と深さを検証する
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(rows))
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		switch states[i] {
+		case visiting:
+			return false
+		case visited:
+			return plan.depths[i] > 0
+		}
+		states[i] = visiting
+		ok := true
+		if p := plan.parentRows[i]; p >= 0 {
+			ok = visit(p)
+			if ok {
+				// サブタスクは親と同じリストに作成する
+				plan.depths[i] = plan.depths[p] + 1
+				plan.todos[i].ListId = plan.todos[p].ListId
+			}
+		} else if plan.depths[i] == 0 {
+			plan.depths[i] = 1
+		}
+		states[i] = visited
+		if !ok {
+			plan.depths[i] = 0
+		}
+		return ok
+	}
+	for i := range rows {
+		if !visit(i) {
+			addError(i, "parent_id", "parent would create a cycle")
+			continue
+		}
+		if plan.depths[i] > domain_todo.MaxTodoDepth {
+			addError(i, "parent_id", "max depth exceeded")
+		}
+	}
+
+	// 行番号順に並べる
+	sort.SliceStable(plan.errors, func(a, b int) bool {
+		return plan.errors[a].Row < plan.errors[b].Row
+	})
+	return plan, nil
+}
@@ -10,6 +10,8 @@ import (
 type IUserUsecase interface {
 	// 全てのユーザーを取得
 	GetAllUsers() ([]domain_user.Users, error)
+	// 複数のidを指定してユーザーを取得
+	GetUsersByIds(ids []string) ([]domain_user.Users, error)
 }
 
 // ユーザーユースケース(Impl)
@@ -40,3 +42,23 @@ func (u *UserUsecase) GetAllUsers() ([]domain_user.Users, error) {
 	u.Logger.InfoLog.Printf("Fetched %d users", len(users))
 	return users, nil
 }
+
+// 複数のidを指定してユーザーを取得
+func (u *UserUsecase) GetUsersByIds(ids []string) ([]domain_user.Users, error) {
+	u.Logger.InfoLog.Println("GetUsersByIds called")
+
+	// バリデーション
+	if len(ids) == 0 {
+		return []domain_user.Users{}, nil
+	}
+
+	// ユーザーリポジトリから指定されたidのユーザーを取得(repository層)
+	users, err := u.userRepository.GetUsersByIds(ids)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get users by ids: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d users", len(users))
+	return users, nil
+}
@@ -0,0 +1,533 @@
+package usecase_webhook
+
+import (
+	domain_webhook "backend/internal/domain/webhook"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_webhook "backend/internal/repository/webhook"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 一度に送信する配信の件数
+const deliveryBatchSize = 50
+
+// 同時に送信する配信の件数
+const deliveryConcurrency = 4
+
+// 配信の一覧の既定・最大件数
+const (
+	defaultDeliveryLimit = 20
+	maxDeliveryLimit     = 100
+)
+
+// 配信の再送の方針
+type DeliveryPolicy struct {
+	// 最大の送信回数(これを超えて失敗した場合は失敗として確定する)
+	MaxAttempts int
+	// 再送までの初期待ち時間(失敗ごとに2倍になる)
+	BackoffBase time.Duration
+	// 再送までの最大待ち時間
+	BackoffMax time.Duration
+	// 送信のタイムアウト(送信中の配信を他で確保しない時間の算出に使う)
+	Timeout time.Duration
+}
+
+// Webhookユースケース(IF)
+type IWebhookUsecase interface {
+	// 自分のWebhookを取得
+	GetWebhooks(userId string) ([]domain_webhook.Webhook, error)
+	// 新しいWebhookを作成
+	CreateWebhook(userId string, rawURL string, eventTypes []string, secret string) (domain_webhook.Webhook, error)
+	// 自分のWebhookを更新
+	UpdateWebhook(userId string, id string, update domain_webhook.WebhookUpdate) (domain_webhook.Webhook, error)
+	// 自分のWebhookを削除
+	DeleteWebhook(userId string, id string) error
+	// 自分のWebhookの配信を新しい順に取得
+	GetDeliveries(userId string, webhookId string, status string, limit int, offset int) ([]domain_webhook.WebhookDelivery, error)
+	// 自分のWebhookの配信の送信履歴を取得
+	GetDeliveryAttempts(userId string, deliveryId string) ([]domain_webhook.WebhookDeliveryAttempt, error)
+	// 自分のWebhookの配信を手動で再送する(同じ内容の配信を新しく登録する)
+	RedeliverDelivery(userId string, deliveryId string) (domain_webhook.WebhookDelivery, error)
+	// 送信日時を過ぎた配信を送信し、送信した件数を返す
+	DispatchPendingDeliveries() (int, error)
+	// 平文のまま保存されているシークレットを暗号化し、暗号化した件数を返す(起動時に呼び出す)
+	EncryptStoredSecrets() (int, error)
+}
+
+// Webhookユースケース(Impl)
+type WebhookUsecase struct {
+	Logger            *pkg_logger.AppLogger
+	webhookRepository repository_webhook.IWebhookRepository
+	webhookSender     repository_webhook.IWebhookSender
+	policy            DeliveryPolicy
+	now               func() time.Time
+}
+
+// Webhookユースケースのインスタンス化
+func NewWebhookUsecase(l *pkg_logger.AppLogger, wr repository_webhook.IWebhookRepository, ws repository_webhook.IWebhookSender, policy DeliveryPolicy) IWebhookUsecase {
+	return &WebhookUsecase{
+		Logger:            l,
+		webhookRepository: wr,
+		webhookSender:     ws,
+		policy:            policy,
+		now:               time.Now,
+	}
+}
+
+// 自分のWebhookを取得
+func (u *WebhookUsecase) GetWebhooks(userId string) ([]domain_webhook.Webhook, error) {
+	u.Logger.InfoLog.Println("GetWebhooks called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return nil, errors.New("user_id is empty")
+	}
+
+	// Webhookリポジトリから取得(repository層)
+	webhooks, err := u.webhookRepository.GetWebhooksByUserId(userId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get webhooks: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d webhooks", len(webhooks))
+	return webhooks, nil
+}
+
+// 新しいWebhookを作成
+func (u *WebhookUsecase) CreateWebhook(userId string, rawURL string, eventTypes []string, secret string) (domain_webhook.Webhook, error) {
+	u.Logger.InfoLog.Println("CreateWebhook called")
+
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_webhook.Webhook{}, errors.New("user_id is empty")
+	}
+	rawURL, err := u.validateURL(rawURL)
+	if err != nil {
+		return domain_webhook.Webhook{}, err
+	}
+	eventTypes, err = u.validateEventTypes(eventTypes)
+	if err != nil {
+		return domain_webhook.Webhook{}, err
+	}
+	if err = u.validateSecret(secret); err != nil {
+		return domain_webhook.Webhook{}, err
+	}
+
+	// Webhookリポジトリから件数を取得(repository層)
+	count, err := u.webhookRepository.CountWebhooksByUserId(userId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to count webhooks: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+	if count >= domain_webhook.MaxWebhooksPerUser {
+		u.Logger.ErrorLog.Println("too many webhooks")
+		return domain_webhook.Webhook{}, errors.New("too many webhooks")
+	}
+
+	// Webhookリポジトリから作成(repository層)
+	webhook, err := u.webhookRepository.CreateWebhook(domain_webhook.Webhook{
+		UserId:     userId,
+		URL:        rawURL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Active:     true,
+	})
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to create webhook: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Created webhook: %v", webhook.ID)
+	return webhook, nil
+}
+
+// 自分のWebhookを更新
+func (u *WebhookUsecase) UpdateWebhook(userId string, id string, update domain_webhook.WebhookUpdate) (domain_webhook.Webhook, error) {
+	u.Logger.InfoLog.Println("UpdateWebhook called")
+
+	webhook, err := u.getOwnWebhook(userId, id)
+	if err != nil {
+		return domain_webhook.Webhook{}, err
+	}
+
+	// バリデーション
+	if update.URL != nil {
+		webhook.URL, err = u.validateURL(*update.URL)
+		if err != nil {
+			return domain_webhook.Webhook{}, err
+		}
+	}
+	if update.EventTypes != nil {
+		webhook.EventTypes, err = u.validateEventTypes(update.EventTypes)
+		if err != nil {
+			return domain_webhook.Webhook{}, err
+		}
+	}
+	if update.Secret != nil {
+		if err = u.validateSecret(*update.Secret); err != nil {
+			return domain_webhook.Webhook{}, err
+		}
+		webhook.Secret = *update.Secret
+	}
+	if update.Active != nil {
+		webhook.Active = *update.Active
+	}
+
+	// Webhookリポジトリから更新(repository層)
+	webhook, err = u.webhookRepository.UpdateWebhook(webhook)
+	if errors.Is(err, repository_webhook.ErrWebhookNotFound) {
+		u.Logger.ErrorLog.Println("webhook not found")
+		return domain_webhook.Webhook{}, errors.New("webhook not found")
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to update webhook: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Updated webhook: %v", webhook.ID)
+	return webhook, nil
+}
+
+// 自分のWebhookを削除
+func (u *WebhookUsecase) DeleteWebhook(userId string, id string) error {
+	u.Logger.InfoLog.Println("DeleteWebhook called")
+
+	if _, err := u.getOwnWebhook(userId, id); err != nil {
+		return err
+	}
+
+	// Webhookリポジトリから削除(repository層)
+	err := u.webhookRepository.DeleteWebhook(id)
+	if errors.Is(err, repository_webhook.ErrWebhookNotFound) {
+		u.Logger.ErrorLog.Println("webhook not found")
+		return errors.New("webhook not found")
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to delete webhook: %v", err)
+		return err
+	}
+
+	u.Logger.InfoLog.Printf("Deleted webhook: %v", id)
+	return nil
+}
+
+// 自分のWebhookの配信を新しい順に取得
+func (u *WebhookUsecase) GetDeliveries(userId string, webhookId string, status string, limit int, offset int) ([]domain_webhook.WebhookDelivery, error) {
+	u.Logger.InfoLog.Println("GetDeliveries called")
+
+	// バリデーション
+	if status != "" && !domain_webhook.IsValidDeliveryStatus(status) {
+		u.Logger.ErrorLog.Printf("Invalid status: %s", status)
+		return nil, errors.New("invalid status")
+	}
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	if limit > maxDeliveryLimit {
+		limit = maxDeliveryLimit
+	}
+	if offset < 0 {
+		u.Logger.ErrorLog.Println("offset must not be negative")
+		return nil, errors.New("offset must not be negative")
+	}
+	if _, err := u.getOwnWebhook(userId, webhookId); err != nil {
+		return nil, err
+	}
+
+	// Webhookリポジトリから取得(repository層)
+	deliveries, err := u.webhookRepository.GetDeliveriesByWebhookId(webhookId, status, limit, offset)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get webhook deliveries: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d webhook deliveries", len(deliveries))
+	return deliveries, nil
+}
+
+// 自分のWebhookの配信の送信履歴を取得
+func (u *WebhookUsecase) GetDeliveryAttempts(userId string, deliveryId string) ([]domain_webhook.WebhookDeliveryAttempt, error) {
+	u.Logger.InfoLog.Println("GetDeliveryAttempts called")
+
+	if _, err := u.getOwnDelivery(userId, deliveryId); err != nil {
+		return nil, err
+	}
+
+	// Webhookリポジトリから取得(repository層)
+	attempts, err := u.webhookRepository.GetDeliveryAttempts(deliveryId)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get webhook delivery attempts: %v", err)
+		return nil, err
+	}
+
+	u.Logger.InfoLog.Printf("Fetched %d webhook delivery attempts", len(attempts))
+	return attempts, nil
+}
+
+// 自分のWebhookの配信を手動で再送する(同じ内容の配信を新しく登録する)
+// 本文のidは元の配信と同じため、受信側はidで重複を判定できる。
+func (u *WebhookUsecase) RedeliverDelivery(userId string, deliveryId string) (domain_webhook.WebhookDelivery, error) {
+	u.Logger.InfoLog.Println("RedeliverDelivery called")
+
+	delivery, err := u.getOwnDelivery(userId, deliveryId)
+	if err != nil {
+		return domain_webhook.WebhookDelivery{}, err
+	}
+	if delivery.Status == domain_webhook.WebhookDeliveryStatusPending {
+		u.Logger.ErrorLog.Println("delivery is pending")
+		return domain_webhook.WebhookDelivery{}, errors.New("delivery is pending")
+	}
+
+	// Webhookリポジトリから再送の配信を登録(repository層)
+	redelivery, err := u.webhookRepository.CreateRedelivery(deliveryId)
+	if errors.Is(err, repository_webhook.ErrWebhookDeliveryNotFound) {
+		u.Logger.ErrorLog.Println("delivery not found")
+		return domain_webhook.WebhookDelivery{}, errors.New("delivery not found")
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to redeliver webhook delivery: %v", err)
+		return domain_webhook.WebhookDelivery{}, err
+	}
+
+	u.Logger.InfoLog.Printf("Redelivered webhook delivery: %v -> %v", deliveryId, redelivery.ID)
+	return redelivery, nil
+}
+
+// 送信日時を過ぎた配信を送信し、送信した件数を返す
+// 2xx以外の応答や通信エラーの場合は、待ち時間を倍にしながら最大回数まで再送する。
+func (u *WebhookUsecase) DispatchPendingDeliveries() (int, error) {
+	u.Logger.InfoLog.Println("DispatchPendingDeliveries called")
+
+	// 送信のタイムアウトより長く確保し、送信中の配信が他で重ねて送信されないようにする
+	lease := u.policy.Timeout + time.Minute
+	dispatched := 0
+	for {
+		// Webhookリポジトリから送信対象の配信を確保(repository層)
+		dispatches, err := u.webhookRepository.ClaimDueDeliveries(u.now(), lease, deliveryBatchSize)
+		if err != nil {
+			u.Logger.ErrorLog.Printf("Failed to claim due webhook deliveries: %v", err)
+			return dispatched, err
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, deliveryConcurrency)
+		for _, dispatch := range dispatches {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(dispatch domain_webhook.WebhookDispatch) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				u.deliver(dispatch)
+			}(dispatch)
+		}
+		wg.Wait()
+		dispatched += len(dispatches)
+
+		if len(dispatches) < deliveryBatchSize {
+			break
+		}
+	}
+
+	u.Logger.InfoLog.Printf("Dispatched %d webhook deliveries", dispatched)
+	return dispatched, nil
+}
+
+// 平文のまま保存されているシークレットを暗号化し、暗号化した件数を返す(起動時に呼び出す)
+func (u *WebhookUsecase) EncryptStoredSecrets() (int, error) {
+	u.Logger.InfoLog.Println("EncryptStoredSecrets called")
+
+	// Webhookリポジトリで暗号化(repository層)
+	encrypted, err := u.webhookRepository.EncryptPlaintextSecrets()
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to encrypt webhook secrets: %v", err)
+		return encrypted, err
+	}
+
+	u.Logger.InfoLog.Printf("Encrypted %d webhook secrets", encrypted)
+	return encrypted, nil
+}
+
+// 配信を送信し、結果を記録する
+func (u *WebhookUsecase) deliver(dispatch domain_webhook.WebhookDispatch) {
+	delivery := dispatch.Delivery
+	response, sendErr := u.webhookSender.Send(domain_webhook.WebhookRequest{
+		URL:        dispatch.URL,
+		Secret:     dispatch.Secret,
+		DeliveryId: delivery.ID,
+		EventType:  delivery.EventType,
+		Payload:    delivery.Payload,
+		Timestamp:  u.now(),
+	})
+
+	attempt := domain_webhook.WebhookDeliveryAttempt{
+		DeliveryId:   delivery.ID,
+		Attempt:      delivery.Attempts,
+		ResponseBody: response.Body,
+		DurationMs:   int(response.Duration / time.Millisecond),
+	}
+	if response.StatusCode != 0 {
+		code := response.StatusCode
+		attempt.ResponseCode = &code
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	} else if !response.IsSuccess() {
+		attempt.Error = "unexpected status code"
+	}
+
+	// 成功・失敗を確定するか、次の送信日時を決める
+	status := domain_webhook.WebhookDeliveryStatusSucceeded
+	var nextAttemptAt *time.Time
+	if attempt.Error != "" {
+		status = domain_webhook.WebhookDeliveryStatusFailed
+		if delivery.Attempts < u.policy.MaxAttempts {
+			status = domain_webhook.WebhookDeliveryStatusPending
+			next := u.now().Add(u.retryDelay(delivery.Attempts))
+			nextAttemptAt = &next
+		}
+	}
+
+	// Webhookリポジトリに結果を記録(repository層)
+	err := u.webhookRepository.RecordDeliveryAttempt(attempt, status, nextAttemptAt)
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to record webhook delivery attempt: %v", err)
+		return
+	}
+	u.Logger.InfoLog.Printf("Webhook delivery %s: %s (attempt %d)", delivery.ID, status, delivery.Attempts)
+}
+
+// n回目の送信に失敗した後の再送までの待ち時間
+func (u *WebhookUsecase) retryDelay(attempts int) time.Duration {
+	delay := u.policy.BackoffBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= u.policy.BackoffMax {
+			return u.policy.BackoffMax
+		}
+	}
+	if delay > u.policy.BackoffMax {
+		return u.policy.BackoffMax
+	}
+	return delay
+}
+
+// 自分のWebhookを取得
+func (u *WebhookUsecase) getOwnWebhook(userId string, id string) (domain_webhook.Webhook, error) {
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_webhook.Webhook{}, errors.New("user_id is empty")
+	}
+	if id == "" {
+		u.Logger.ErrorLog.Println("webhook_id is empty")
+		return domain_webhook.Webhook{}, errors.New("webhook_id is empty")
+	}
+
+	// Webhookリポジトリから取得(repository層)
+	webhook, err := u.webhookRepository.GetWebhookById(id)
+	if errors.Is(err, repository_webhook.ErrWebhookNotFound) {
+		u.Logger.ErrorLog.Println("webhook not found")
+		return domain_webhook.Webhook{}, errors.New("webhook not found")
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get webhook: %v", err)
+		return domain_webhook.Webhook{}, err
+	}
+
+	// 所有者チェック
+	if webhook.UserId != userId {
+		u.Logger.ErrorLog.Println("forbidden")
+		return domain_webhook.Webhook{}, errors.New("forbidden")
+	}
+	return webhook, nil
+}
+
+// 自分のWebhookの配信を取得
+func (u *WebhookUsecase) getOwnDelivery(userId string, deliveryId string) (domain_webhook.WebhookDelivery, error) {
+	// バリデーション
+	if userId == "" {
+		u.Logger.ErrorLog.Println("user_id is empty")
+		return domain_webhook.WebhookDelivery{}, errors.New("user_id is empty")
+	}
+	if deliveryId == "" {
+		u.Logger.ErrorLog.Println("delivery_id is empty")
+		return domain_webhook.WebhookDelivery{}, errors.New("delivery_id is empty")
+	}
+
+	// Webhookリポジトリから取得(repository層)
+	delivery, err := u.webhookRepository.GetDeliveryById(deliveryId)
+	if errors.Is(err, repository_webhook.ErrWebhookDeliveryNotFound) {
+		u.Logger.ErrorLog.Println("delivery not found")
+		return domain_webhook.WebhookDelivery{}, errors.New("delivery not found")
+	}
+	if err != nil {
+		u.Logger.ErrorLog.Printf("Failed to get webhook delivery: %v", err)
+		return domain_webhook.WebhookDelivery{}, err
+	}
+
+	// 所有者チェック(配信のWebhookの所有者)
+	if _, err = u.getOwnWebhook(userId, delivery.WebhookId); err != nil {
+		return domain_webhook.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// URLの検証(http/httpsの絶対URLのみ)
+func (u *WebhookUsecase) validateURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		u.Logger.ErrorLog.Println("url is empty")
+		return "", errors.New("url is empty")
+	}
+	if len(rawURL) > domain_webhook.MaxWebhookURLLength {
+		u.Logger.ErrorLog.Println("url is too long")
+		return "", errors.New("url is too long")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.User != nil {
+		u.Logger.ErrorLog.Printf("Invalid url: %s", rawURL)
+		return "", errors.New("invalid url")
+	}
+	return rawURL, nil
+}
+
+// イベントの種類の検証(重複は除く)
+func (u *WebhookUsecase) validateEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		u.Logger.ErrorLog.Println("event_types is empty")
+		return nil, errors.New("event_types is empty")
+	}
+	seen := make(map[string]bool, len(eventTypes))
+	result := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !domain_webhook.IsValidEventType(eventType) {
+			u.Logger.ErrorLog.Printf("Invalid event type: %s", eventType)
+			return nil, errors.New("invalid event type")
+		}
+		if seen[eventType] {
+			continue
+		}
+		seen[eventType] = true
+		result = append(result, eventType)
+	}
+	return result, nil
+}
+
+// シークレットの検証
+func (u *WebhookUsecase) validateSecret(secret string) error {
+	if len(secret) < domain_webhook.MinWebhookSecretLength {
+		u.Logger.ErrorLog.Println("secret is too short")
+		return errors.New("secret is too short")
+	}
+	if len(secret) > domain_webhook.MaxWebhookSecretLength {
+		u.Logger.ErrorLog.Println("secret is too long")
+		return errors.New("secret is too long")
+	}
+	return nil
+}
@@ -0,0 +1,452 @@
+package usecase_webhook
+
+import (
+	domain_webhook "backend/internal/domain/webhook"
+	infrastructure_webhook "backend/internal/infrastructure/webhook"
+	pkg_logger "backend/internal/pkg/logger"
+	repository_webhook "backend/internal/repository/webhook"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *pkg_logger.AppLogger {
+	discard := log.New(io.Discard, "", 0)
+	return &pkg_logger.AppLogger{InfoLog: discard, ErrorLog: discard, WarnLog: discard, DebugLog: discard, TestLog: discard}
+}
+
+// テスト用のWebhookリポジトリ(メモリ上に保持する)
+type fakeWebhookRepository struct {
+	mu         sync.Mutex
+	webhooks   map[string]domain_webhook.Webhook
+	deliveries map[string]*domain_webhook.WebhookDelivery
+	attempts   map[string][]domain_webhook.WebhookDeliveryAttempt
+	nextId     int
+}
+
+func newFakeWebhookRepository() *fakeWebhookRepository {
+	return &fakeWebhookRepository{
+		webhooks:   map[string]domain_webhook.Webhook{},
+		deliveries: map[string]*domain_webhook.WebhookDelivery{},
+		attempts:   map[string][]domain_webhook.WebhookDeliveryAttempt{},
+	}
+}
+
+func (r *fakeWebhookRepository) newId(prefix string) string {
+	r.nextId++
+	return prefix + "-" + strconv.Itoa(r.nextId)
+}
+
+// 送信待ちの配信を登録する
+func (r *fakeWebhookRepository) enqueue(webhookId string, eventType string, payload string, at time.Time) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.newId("delivery")
+	r.deliveries[id] = &domain_webhook.WebhookDelivery{
+		ID:            id,
+		WebhookId:     webhookId,
+		EventType:     eventType,
+		Payload:       json.RawMessage(payload),
+		Status:        domain_webhook.WebhookDeliveryStatusPending,
+		NextAttemptAt: &at,
+		CreatedAt:     at,
+	}
+	return id
+}
+
+func (r *fakeWebhookRepository) delivery(id string) domain_webhook.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.deliveries[id]
+}
+
+func (r *fakeWebhookRepository) GetWebhookById(id string) (domain_webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return domain_webhook.Webhook{}, repository_webhook.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (r *fakeWebhookRepository) GetWebhooksByUserId(userId string) ([]domain_webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhooks := []domain_webhook.Webhook{}
+	for _, webhook := range r.webhooks {
+		if webhook.UserId == userId {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (r *fakeWebhookRepository) CountWebhooksByUserId(userId string) (int, error) {
+	webhooks, err := r.GetWebhooksByUserId(userId)
+	return len(webhooks), err
+}
+
+func (r *fakeWebhookRepository) CreateWebhook(webhook domain_webhook.Webhook) (domain_webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook.ID = r.newId("webhook")
+	r.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (r *fakeWebhookRepository) UpdateWebhook(webhook domain_webhook.Webhook) (domain_webhook.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[webhook.ID]; !ok {
+		return domain_webhook.Webhook{}, repository_webhook.ErrWebhookNotFound
+	}
+	r.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (r *fakeWebhookRepository) DeleteWebhook(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[id]; !ok {
+		return repository_webhook.ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+	return nil
+}
+
+func (r *fakeWebhookRepository) GetDeliveryById(id string) (domain_webhook.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return domain_webhook.WebhookDelivery{}, repository_webhook.ErrWebhookDeliveryNotFound
+	}
+	return *delivery, nil
+}
+
+func (r *fakeWebhookRepository) GetDeliveriesByWebhookId(webhookId string, status string, limit int, offset int) ([]domain_webhook.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deliveries := []domain_webhook.WebhookDelivery{}
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookId == webhookId && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *fakeWebhookRepository) GetDeliveryAttempts(deliveryId string) ([]domain_webhook.WebhookDeliveryAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain_webhook.WebhookDeliveryAttempt{}, r.attempts[deliveryId]...), nil
+}
+
+func (r *fakeWebhookRepository) CreateRedelivery(deliveryId string) (domain_webhook.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	original, ok := r.deliveries[deliveryId]
+	if !ok {
+		return domain_webhook.WebhookDelivery{}, repository_webhook.ErrWebhookDeliveryNotFound
+	}
+	id := r.newId("delivery")
+	next := time.Time{}
+	r.deliveries[id] = &domain_webhook.WebhookDelivery{
+		ID:            id,
+		WebhookId:     original.WebhookId,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        domain_webhook.WebhookDeliveryStatusPending,
+		NextAttemptAt: &next,
+		RedeliveryOf:  &original.ID,
+	}
+	return *r.deliveries[id], nil
+}
+
+func (r *fakeWebhookRepository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]domain_webhook.WebhookDispatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dispatches := []domain_webhook.WebhookDispatch{}
+	for _, delivery := range r.deliveries {
+		webhook := r.webhooks[delivery.WebhookId]
+		if len(dispatches) >= limit || delivery.Status != domain_webhook.WebhookDeliveryStatusPending || !webhook.Active || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.Attempts++
+		next := now.Add(lease)
+		delivery.NextAttemptAt = &next
+		dispatches = append(dispatches, domain_webhook.WebhookDispatch{Delivery: *delivery, URL: webhook.URL, Secret: webhook.Secret})
+	}
+	return dispatches, nil
+}
+
+func (r *fakeWebhookRepository) RecordDeliveryAttempt(attempt domain_webhook.WebhookDeliveryAttempt, status string, nextAttemptAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[attempt.DeliveryId]
+	if !ok {
+		return repository_webhook.ErrWebhookDeliveryNotFound
+	}
+	r.attempts[attempt.DeliveryId] = append(r.attempts[attempt.DeliveryId], attempt)
+	delivery.Status = status
+	delivery.NextAttemptAt = nextAttemptAt
+	delivery.LastResponseCode = attempt.ResponseCode
+	if attempt.Error != "" {
+		delivery.LastError = &attempt.Error
+	} else {
+		delivery.LastError = nil
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepository) EncryptPlaintextSecrets() (int, error) {
+	return 0, nil
+}
+
+// テスト用の受信側(応答のステータスコードを順に返し、署名を検証する)
+type testReceiver struct {
+	t        *testing.T
+	secret   string
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+}
+
+func (rc *testReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	timestamp, _ := strconv.ParseInt(r.Header.Get(domain_webhook.WebhookHeaderTimestamp), 10, 64)
+	if !domain_webhook.VerifyWebhookSignature(rc.secret, timestamp, body, r.Header.Get(domain_webhook.WebhookHeaderSignature)) {
+		rc.t.Errorf("invalid signature for delivery %s", r.Header.Get(domain_webhook.WebhookHeaderId))
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.bodies = append(rc.bodies, string(body))
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status = rc.statuses[0]
+		rc.statuses = rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *testReceiver) received() []string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]string{}, rc.bodies...)
+}
+
+// テスト用のWebhookユースケース(時刻はclockで進める)
+func newTestWebhookUsecase(t *testing.T, statuses []int, policy DeliveryPolicy) (*WebhookUsecase, *fakeWebhookRepository, *testReceiver, *time.Time, domain_webhook.Webhook) {
+	t.Helper()
+
+	const secret = "0123456789abcdef"
+	receiver := &testReceiver{t: t, secret: secret, statuses: statuses}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	repo := newFakeWebhookRepository()
+	webhook, _ := repo.CreateWebhook(domain_webhook.Webhook{
+		UserId:     "user-1",
+		URL:        server.URL,
+		EventTypes: []string{domain_webhook.WebhookEventTodoCreated},
+		Secret:     secret,
+		Active:     true,
+	})
+
+	l := newTestLogger()
+	// httptestのサーバーはループバックのため、内部のアドレスへの送信を許可する
+	sender := infrastructure_webhook.NewHTTPWebhookSender(l, 5*time.Second, true)
+	u := NewWebhookUsecase(l, repo, sender, policy).(*WebhookUsecase)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	u.now = func() time.Time { return clock }
+	return u, repo, receiver, &clock, webhook
+}
+
+func TestRetryDelay(t *testing.T) {
+	u := &WebhookUsecase{policy: DeliveryPolicy{BackoffBase: 30 * time.Second, BackoffMax: time.Hour}}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 3, want: 2 * time.Minute},
+		{attempts: 7, want: 32 * time.Minute},
+		{attempts: 8, want: time.Hour},
+		{attempts: 100, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := u.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+
+	// 初期待ち時間が最大を超える場合は最大にする
+	u = &WebhookUsecase{policy: DeliveryPolicy{BackoffBase: 2 * time.Hour, BackoffMax: time.Hour}}
+	if got := u.retryDelay(1); got != time.Hour {
+		t.Errorf("retryDelay(1) = %v, want %v", got, time.Hour)
+	}
+}
+
+// 失敗した配信は待ち時間を倍にしながら再送し、成功した時点で確定する
+func TestDispatchPendingDeliveriesRetriesWithBackoff(t *testing.T) {
+	policy := DeliveryPolicy{MaxAttempts: 5, BackoffBase: time.Minute, BackoffMax: time.Hour, Timeout: 5 * time.Second}
+	u, repo, receiver, clock, webhook := newTestWebhookUsecase(t, []int{http.StatusInternalServerError, http.StatusServiceUnavailable}, policy)
+	id := repo.enqueue(webhook.ID, domain_webhook.WebhookEventTodoCreated, `{"id":"log-1"}`, *clock)
+
+	// 1回目: 失敗し、1分後に再送する
+	if n, err := u.DispatchPendingDeliveries(); err != nil || n != 1 {
+		t.Fatalf("dispatch 1: got (%d, %v), want (1, nil)", n, err)
+	}
+	delivery := repo.delivery(id)
+	if delivery.Status != domain_webhook.WebhookDeliveryStatusPending || delivery.Attempts != 1 {
+		t.Fatalf("after attempt 1: status %s, attempts %d", delivery.Status, delivery.Attempts)
+	}
+	if want := clock.Add(time.Minute); delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.Equal(want) {
+		t.Errorf("after attempt 1: next_attempt_at = %v, want %v", delivery.NextAttemptAt, want)
+	}
+	if delivery.LastResponseCode == nil || *delivery.LastResponseCode != http.StatusInternalServerError {
+		t.Errorf("after attempt 1: last_response_code = %v, want 500", delivery.LastResponseCode)
+	}
+
+	// 再送の日時より前は送信しない
+	*clock = clock.Add(30 * time.Second)
+	if n, err := u.DispatchPendingDeliveries(); err != nil || n != 0 {
+		t.Fatalf("dispatch before retry: got (%d, %v), want (0, nil)", n, err)
+	}
+
+	// 2回目: 失敗し、2分後に再送する
+	*clock = clock.Add(30 * time.Second)
+	if n, err := u.DispatchPendingDeliveries(); err != nil || n != 1 {
+		t.Fatalf("dispatch 2: got (%d, %v), want (1, nil)", n, err)
+	}
+	delivery = repo.delivery(id)
+	if want := clock.Add(2 * time.Minute); delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.Equal(want) {
+		t.Errorf("after attempt 2: next_attempt_at = %v, want %v", delivery.NextAttemptAt, want)
+	}
+
+	// 3回目: 成功して確定する
+	*clock = clock.Add(2 * time.Minute)
+	if n, err := u.DispatchPendingDeliveries(); err != nil || n != 1 {
+		t.Fatalf("dispatch 3: got (%d, %v), want (1, nil)", n, err)
+	}
+	delivery = repo.delivery(id)
+	if delivery.Status != domain_webhook.WebhookDeliveryStatusSucceeded || delivery.Attempts != 3 || delivery.NextAttemptAt != nil || delivery.LastError != nil {
+		t.Errorf("after attempt 3: %+v", delivery)
+	}
+
+	attempts, _ := repo.GetDeliveryAttempts(id)
+	if len(attempts) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(attempts))
+	}
+	for i, attempt := range attempts {
+		if attempt.Attempt != i+1 {
+			t.Errorf("attempts[%d].Attempt = %d, want %d", i, attempt.Attempt, i+1)
+		}
+	}
+	if attempts[0].Error != "unexpected status code" || attempts[2].Error != "" {
+		t.Errorf("unexpected attempt errors: %q, %q", attempts[0].Error, attempts[2].Error)
+	}
+	if got := receiver.received(); len(got) != 3 || got[0] != `{"id":"log-1"}` {
+		t.Errorf("receiver got %v", got)
+	}
+}
+
+// 最大回数まで失敗した配信は失敗として確定する
+func TestDispatchPendingDeliveriesGivesUpAfterMaxAttempts(t *testing.T) {
+	policy := DeliveryPolicy{MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Hour, Timeout: 5 * time.Second}
+	statuses := []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}
+	u, repo, receiver, clock, webhook := newTestWebhookUsecase(t, statuses, policy)
+	id := repo.enqueue(webhook.ID, domain_webhook.WebhookEventTodoCreated, `{"id":"log-1"}`, *clock)
+
+	for i := 0; i < 5; i++ {
+		if _, err := u.DispatchPendingDeliveries(); err != nil {
+			t.Fatalf("dispatch %d: unexpected error: %v", i+1, err)
+		}
+		*clock = clock.Add(time.Hour)
+	}
+
+	delivery := repo.delivery(id)
+	if delivery.Status != domain_webhook.WebhookDeliveryStatusFailed || delivery.Attempts != 3 || delivery.NextAttemptAt != nil {
+		t.Errorf("unexpected delivery: status %s, attempts %d, next_attempt_at %v", delivery.Status, delivery.Attempts, delivery.NextAttemptAt)
+	}
+	if got := len(receiver.received()); got != 3 {
+		t.Errorf("receiver got %d requests, want 3", got)
+	}
+}
+
+// 無効なWebhookの配信は有効に戻るまで送信しない
+func TestDispatchPendingDeliveriesSkipsInactiveWebhooks(t *testing.T) {
+	policy := DeliveryPolicy{MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Hour, Timeout: 5 * time.Second}
+	u, repo, receiver, clock, webhook := newTestWebhookUsecase(t, nil, policy)
+	repo.enqueue(webhook.ID, domain_webhook.WebhookEventTodoCreated, `{"id":"log-1"}`, *clock)
+
+	webhook.Active = false
+	repo.UpdateWebhook(webhook)
+	if n, err := u.DispatchPendingDeliveries(); err != nil || n != 0 {
+		t.Fatalf("got (%d, %v), want (0, nil)", n, err)
+	}
+	if got := len(receiver.received()); got != 0 {
+		t.Errorf("receiver got %d requests, want 0", got)
+	}
+}
+
+func TestRedeliverDelivery(t *testing.T) {
+	policy := DeliveryPolicy{MaxAttempts: 1, BackoffBase: time.Minute, BackoffMax: time.Hour, Timeout: 5 * time.Second}
+	u, repo, receiver, clock, webhook := newTestWebhookUsecase(t, []int{http.StatusInternalServerError}, policy)
+	id := repo.enqueue(webhook.ID, domain_webhook.WebhookEventTodoCreated, `{"id":"log-1"}`, *clock)
+
+	// 送信待ちの配信は再送できない
+	if _, err := u.RedeliverDelivery("user-1", id); err == nil || err.Error() != "delivery is pending" {
+		t.Errorf("redeliver pending: error = %v, want delivery is pending", err)
+	}
+
+	// 1回で失敗として確定する
+	if _, err := u.DispatchPendingDeliveries(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status := repo.delivery(id).Status; status != domain_webhook.WebhookDeliveryStatusFailed {
+		t.Fatalf("status = %s, want failed", status)
+	}
+
+	// 他のユーザーの配信や存在しない配信は再送できない
+	if _, err := u.RedeliverDelivery("user-2", id); err == nil || err.Error() != "forbidden" {
+		t.Errorf("redeliver by another user: error = %v, want forbidden", err)
+	}
+	if _, err := u.RedeliverDelivery("user-1", "missing"); err == nil || err.Error() != "delivery not found" {
+		t.Errorf("redeliver missing: error = %v, want delivery not found", err)
+	}
+
+	// 同じ内容の配信を新しく登録し、次の送信で送る
+	redelivery, err := u.RedeliverDelivery("user-1", id)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if redelivery.ID == id || redelivery.RedeliveryOf == nil || *redelivery.RedeliveryOf != id || redelivery.Status != domain_webhook.WebhookDeliveryStatusPending {
+		t.Errorf("unexpected redelivery: %+v", redelivery)
+	}
+	if n, err := u.DispatchPendingDeliveries(); err != nil || n != 1 {
+		t.Fatalf("dispatch redelivery: got (%d, %v), want (1, nil)", n, err)
+	}
+	if status := repo.delivery(redelivery.ID).Status; status != domain_webhook.WebhookDeliveryStatusSucceeded {
+		t.Errorf("redelivery status = %s, want succeeded", status)
+	}
+	if status := repo.delivery(id).Status; status != domain_webhook.WebhookDeliveryStatusFailed {
+		t.Errorf("original status = %s, want failed", status)
+	}
+
+	// 受信側は本文のidで重複を判定できる
+	got := receiver.received()
+	if len(got) != 2 || got[0] != got[1] {
+		t.Errorf("receiver got %v, want the same payload twice", got)
+	}
+}
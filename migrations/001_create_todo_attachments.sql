@@ -0,0 +1,13 @@
+-- Todoの添付ファイル
+CREATE TABLE IF NOT EXISTS todo_attachments (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    todo_id      UUID        NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    file_name    TEXT        NOT NULL,
+    content_type TEXT        NOT NULL,
+    size         BIGINT      NOT NULL CHECK (size >= 0),
+    storage_key  TEXT        NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_attachments_todo_id ON todo_attachments (todo_id);
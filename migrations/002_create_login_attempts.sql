@@ -0,0 +1,31 @@
+-- ログイン試行の履歴
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    email        TEXT        NOT NULL,
+    ip_address   TEXT        NOT NULL,
+    result       TEXT        NOT NULL CHECK (result IN ('success', 'failure', 'locked', 'throttled', 'unlocked')),
+    attempted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts (email, attempted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip_address ON login_attempts (ip_address, attempted_at DESC);
+
+-- アカウント・IPアドレスのロック
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    scope        TEXT        NOT NULL CHECK (scope IN ('account', 'ip')),
+    subject      TEXT        NOT NULL,
+    locked_until TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (scope, subject)
+);
+
+-- ログイン試行の回数(アカウント・IPアドレスごと)
+-- パスワードの検証前に1文で加算・判定し、同時に試行されても上限を超えて検証させない。
+CREATE TABLE IF NOT EXISTS login_counters (
+    scope           TEXT        NOT NULL CHECK (scope IN ('account', 'ip')),
+    subject         TEXT        NOT NULL,
+    -- 最後の成功・ロック解除以降の試行回数(検証中の試行を含む)
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    last_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (scope, subject)
+);
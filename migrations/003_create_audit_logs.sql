@@ -0,0 +1,29 @@
+-- 監査ログ(追記のみ)
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id    TEXT        NOT NULL,
+    action      TEXT        NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    entity_type TEXT        NOT NULL,
+    entity_id   TEXT        NOT NULL,
+    before      JSONB,
+    after       JSONB,
+    request_id  TEXT        NOT NULL DEFAULT '',
+    ip_address  TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id, created_at DESC);
+
+-- 更新・削除を禁止する
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+CREATE TRIGGER trg_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
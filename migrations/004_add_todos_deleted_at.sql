@@ -0,0 +1,10 @@
+-- Todoの論理削除(ゴミ箱)
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_todos_user_id_active ON todos (user_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- 監査ログに復元・完全削除の操作を追加
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_action_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge'));
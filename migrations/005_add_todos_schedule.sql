@@ -0,0 +1,14 @@
+-- Todoの期限・優先度・リマインド
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS priority TEXT NOT NULL DEFAULT 'medium';
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS remind_at TIMESTAMPTZ;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMPTZ;
+
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_priority_check;
+ALTER TABLE todos ADD CONSTRAINT todos_priority_check
+    CHECK (priority IN ('low', 'medium', 'high', 'urgent'));
+
+CREATE INDEX IF NOT EXISTS idx_todos_user_id_due_at ON todos (user_id, due_at)
+    WHERE deleted_at IS NULL AND completed = false AND due_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_todos_pending_reminders ON todos (remind_at)
+    WHERE deleted_at IS NULL AND completed = false AND reminded_at IS NULL AND remind_at IS NOT NULL;
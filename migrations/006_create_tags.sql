@@ -0,0 +1,20 @@
+-- タグ(ユーザーごと)
+CREATE TABLE IF NOT EXISTS tags (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- 同じユーザー内でタグ名(大文字小文字を区別しない)は重複させない
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_id_name ON tags (user_id, lower(name));
+
+-- Todoとタグの関連
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id    UUID        NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    tag_id     UUID        NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags (tag_id);
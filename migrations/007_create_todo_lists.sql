@@ -0,0 +1,19 @@
+-- Todoリスト(ユーザーごと)
+CREATE TABLE IF NOT EXISTS todo_lists (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    color      TEXT        NOT NULL DEFAULT '#808080',
+    archived   BOOLEAN     NOT NULL DEFAULT false,
+    position   INTEGER     NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_lists_user_id_position ON todo_lists (user_id, position);
+
+-- Todoの所属リスト(NULLはインボックス)
+-- リストが物理削除された場合、ゴミ箱のTodoはインボックスに戻る
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS list_id UUID REFERENCES todo_lists (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todos_list_id ON todos (list_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,9 @@
+-- 親Todo(NULLは最上位のTodo)
+-- 親が完全削除された場合、サブタスクは最上位のTodoになる
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES todos (id) ON DELETE SET NULL;
+
+-- 自分自身を親にすることはできない(それ以外の循環はアプリケーションで防ぐ)
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_parent_id_not_self;
+ALTER TABLE todos ADD CONSTRAINT todos_parent_id_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_todos_parent_id ON todos (parent_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,11 @@
+-- Todoの繰り返し設定(RFC 5545のRRULE)
+-- 現在の発生分(未完了)のTodoに紐づき、完了時に次の発生分のTodoへ付け替える
+CREATE TABLE IF NOT EXISTS todo_recurrences (
+    todo_id    UUID          PRIMARY KEY REFERENCES todos (id) ON DELETE CASCADE,
+    rule       TEXT          NOT NULL,
+    timezone   TEXT          NOT NULL,
+    start_at   TIMESTAMPTZ   NOT NULL,
+    exdates    TIMESTAMPTZ[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ   NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ   NOT NULL DEFAULT now()
+);
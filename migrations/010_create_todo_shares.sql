@@ -0,0 +1,19 @@
+-- Todoの共有(todo_idがNULLの場合は所有者の全てのTodoを共有する)
+CREATE TABLE IF NOT EXISTS todo_shares (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    todo_id     UUID        REFERENCES todos (id) ON DELETE CASCADE,
+    member_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role        TEXT        NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    status      TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted')),
+    invited_by  UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    accepted_at TIMESTAMPTZ,
+    CHECK (owner_id <> member_id)
+);
+
+-- 同じ対象を同じユーザーに重複して共有しない
+CREATE UNIQUE INDEX IF NOT EXISTS uq_todo_shares_todo_member ON todo_shares (todo_id, member_id) WHERE todo_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_todo_shares_owner_member ON todo_shares (owner_id, member_id) WHERE todo_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todo_shares_member_id ON todo_shares (member_id, status);
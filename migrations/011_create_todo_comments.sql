@@ -0,0 +1,11 @@
+-- Todoのコメント
+CREATE TABLE IF NOT EXISTS todo_comments (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    todo_id    UUID        NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    author_id  UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    body       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    edited_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_comments_todo_id_created_at ON todo_comments (todo_id, created_at);
@@ -0,0 +1,34 @@
+-- Todoのイベントストア(追記のみ)
+-- 完全に削除されたTodoのイベントも残すため、todosへの外部キーは張らない
+CREATE TABLE IF NOT EXISTS todo_events (
+    seq          BIGSERIAL   PRIMARY KEY,
+    aggregate_id UUID        NOT NULL,
+    version      INTEGER     NOT NULL CHECK (version > 0),
+    type         TEXT        NOT NULL,
+    payload      JSONB       NOT NULL DEFAULT '{}',
+    actor_id     TEXT        NOT NULL,
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    recorded_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- 同じバージョンの二重追記(同時更新)を防ぐ
+    UNIQUE (aggregate_id, version)
+);
+
+-- 更新・削除を禁止する
+CREATE OR REPLACE FUNCTION todo_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'todo_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_todo_events_append_only ON todo_events;
+CREATE TRIGGER trg_todo_events_append_only
+    BEFORE UPDATE OR DELETE ON todo_events
+    FOR EACH ROW EXECUTE FUNCTION todo_events_append_only();
+
+-- Todoのスナップショット(Todoごとに最新の1件のみ保持する)
+CREATE TABLE IF NOT EXISTS todo_snapshots (
+    aggregate_id UUID        PRIMARY KEY,
+    version      INTEGER     NOT NULL,
+    state        JSONB       NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,40 @@
+-- Todoの並び順(フラクショナルインデックスのキー、文字列のバイト順で並べる)
+-- キーは可変長の整数部と小数部からなる。整数部は先頭の1文字で桁数を表し('a'〜'z'は0以上、'A'〜'Z'は負)、
+-- 続く小数部は末尾を'0'にしない(アプリケーションで生成する)
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS position TEXT COLLATE "C";
+
+-- 0以上の整数を整数部のキーにする(0は'a0'、61は'az'、62は'b00')
+CREATE OR REPLACE FUNCTION pg_temp.todo_position_key(n BIGINT) RETURNS TEXT AS $$
+DECLARE
+    digits CONSTANT TEXT := '0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz';
+    len    INTEGER := 1;
+    cap    BIGINT := 62;
+    rest   TEXT := '';
+BEGIN
+    WHILE n >= cap LOOP
+        n := n - cap;
+        len := len + 1;
+        cap := cap * 62;
+    END LOOP;
+    FOR i IN 1..len LOOP
+        rest := substr(digits, (n % 62)::INTEGER + 1, 1) || rest;
+        n := n / 62;
+    END LOOP;
+    RETURN chr(ascii('a') + len - 1) || rest;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- 既存のTodoはユーザーごとに作成日時の順で並べる
+UPDATE todos t
+SET position = pg_temp.todo_position_key(o.rn - 1)
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY created_at, id) AS rn
+    FROM todos
+) o
+WHERE t.id = o.id AND t.position IS NULL;
+
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_position_check;
+ALTER TABLE todos ADD CONSTRAINT todos_position_check
+    CHECK (position IS NULL OR position ~ '^[A-Za-z][0-9A-Za-z]+$');
+
+CREATE INDEX IF NOT EXISTS idx_todos_user_id_position ON todos (user_id, position);
@@ -0,0 +1,47 @@
+-- Todoの全文検索の設定(1行のみ、言語の設定はアプリケーションの起動時に反映する)
+CREATE TABLE IF NOT EXISTS todo_search_settings (
+    id         INTEGER     PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    config     REGCONFIG   NOT NULL DEFAULT 'simple',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO todo_search_settings (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
+
+-- ひらがな・カタカナ・漢字の連続した部分をbigramに分割し、空白区切りで返す(1文字の場合はそのまま)
+-- 日本語は単語の区切りが無く、標準のパーサーでは分割されないため、bigramで部分一致を検索できるようにする。
+-- 文字の範囲はアプリケーション(domain_search.IsBigramRune)と揃えること。
+CREATE OR REPLACE FUNCTION todo_search_bigrams(body TEXT) RETURNS TEXT AS $$
+    SELECT COALESCE(string_agg(substr(r.run, i, 2), ' ' ORDER BY r.n, i), '')
+    FROM (
+        SELECT m[1] AS run, row_number() OVER () AS n
+        FROM regexp_matches(body, '[ぁ-ゖァ-ヺー々〆㐀-䶿一-鿿ｦ-ﾝ]+', 'g') AS m
+    ) r
+    CROSS JOIN LATERAL generate_series(1, GREATEST(length(r.run) - 1, 1)) AS i
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Todoの説明から検索用のtsvectorを作成(設定の言語で分割した単語 + 日本語などのbigram)
+CREATE OR REPLACE FUNCTION todo_search_document(body TEXT) RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector(s.config, body), 'A') || to_tsvector('simple', todo_search_bigrams(body))
+    FROM todo_search_settings s
+    WHERE s.id = 1
+$$ LANGUAGE sql STABLE;
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- 作成時・説明の変更時に検索用のtsvectorを更新する
+CREATE OR REPLACE FUNCTION todos_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := todo_search_document(NEW.description);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_todos_search_vector ON todos;
+CREATE TRIGGER trg_todos_search_vector
+    BEFORE INSERT OR UPDATE OF description ON todos
+    FOR EACH ROW EXECUTE FUNCTION todos_search_vector_update();
+
+-- 既存のTodo
+UPDATE todos SET search_vector = todo_search_document(description) WHERE search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todos_search_vector ON todos USING GIN (search_vector);
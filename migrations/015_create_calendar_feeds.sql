@@ -0,0 +1,9 @@
+-- カレンダーの購読フィード(ユーザーごとに1件)
+-- URLに含める秘密のトークンはSHA-256のハッシュのみ保存する
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id          UUID        PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    token_hash       TEXT        NOT NULL UNIQUE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_accessed_at TIMESTAMPTZ
+);
+
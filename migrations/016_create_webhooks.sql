@@ -0,0 +1,48 @@
+-- Webhookの購読(ユーザーごと)
+-- 署名に使うため、シークレットはハッシュ化せずに保存する(WEBHOOK_SECRET_KEYが設定されている場合はアプリケーションで暗号化する)
+CREATE TABLE IF NOT EXISTS webhooks (
+    id          UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url         TEXT        NOT NULL,
+    event_types TEXT[]      NOT NULL,
+    secret      TEXT        NOT NULL,
+    active      BOOLEAN     NOT NULL DEFAULT true,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id, created_at);
+
+-- Webhookの配信(イベント・Webhookごとに1件)
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id                 UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id         UUID        NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_type         TEXT        NOT NULL,
+    payload            JSONB       NOT NULL,
+    status             TEXT        NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts           INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at    TIMESTAMPTZ DEFAULT now(),
+    last_response_code INTEGER,
+    last_error         TEXT,
+    redelivery_of      UUID        REFERENCES webhook_deliveries (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, created_at DESC);
+
+-- Webhookの送信履歴(送信ごとに1件)
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id            UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id   UUID        NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempt       INTEGER     NOT NULL,
+    response_code INTEGER,
+    response_body TEXT        NOT NULL DEFAULT '',
+    error         TEXT        NOT NULL DEFAULT '',
+    duration_ms   INTEGER     NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery_id ON webhook_delivery_attempts (delivery_id, attempt);